@@ -47,6 +47,7 @@ func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.Topology != nil {
 		dst.Spec.Topology = restored.Spec.Topology
 	}
+	dst.Status.Topology = restored.Status.Topology
 
 	return nil
 }
@@ -97,6 +98,9 @@ func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Status.NodeInfo = restored.Status.NodeInfo
+	dst.Spec.NodeVolumeDetachTimeout = restored.Spec.NodeVolumeDetachTimeout
+	dst.Spec.NodeDeletionTimeout = restored.Spec.NodeDeletionTimeout
+	dst.Status.CertificatesExpiryDate = restored.Status.CertificatesExpiryDate
 	return nil
 }
 
@@ -138,6 +142,9 @@ func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
 		return err
 	}
+	dst.Spec.Template.Spec.NodeVolumeDetachTimeout = restored.Spec.Template.Spec.NodeVolumeDetachTimeout
+	dst.Spec.Template.Spec.NodeDeletionTimeout = restored.Spec.Template.Spec.NodeDeletionTimeout
+	dst.Spec.SpreadFailureDomains = restored.Spec.SpreadFailureDomains
 	dst.Status.Conditions = restored.Status.Conditions
 	return nil
 }
@@ -191,6 +198,9 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 		dst.Spec.Strategy.RollingUpdate.DeletePolicy = restored.Spec.Strategy.RollingUpdate.DeletePolicy
 	}
 
+	dst.Spec.Template.Spec.NodeVolumeDetachTimeout = restored.Spec.Template.Spec.NodeVolumeDetachTimeout
+	dst.Spec.Template.Spec.NodeDeletionTimeout = restored.Spec.Template.Spec.NodeDeletionTimeout
+	dst.Spec.SpreadFailureDomains = restored.Spec.SpreadFailureDomains
 	dst.Status.Conditions = restored.Status.Conditions
 	return nil
 }
@@ -238,6 +248,12 @@ func (src *MachineHealthCheck) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.UnhealthyRange != nil {
 		dst.Spec.UnhealthyRange = restored.Spec.UnhealthyRange
 	}
+	dst.Spec.RemediationLimits = restored.Spec.RemediationLimits
+	dst.Spec.UnhealthyMachineConditions = restored.Spec.UnhealthyMachineConditions
+	dst.Spec.UnhealthyNodeTaints = restored.Spec.UnhealthyNodeTaints
+	dst.Spec.DrainedNodeNotReadyTimeout = restored.Spec.DrainedNodeNotReadyTimeout
+	dst.Spec.RemediationStrategy = restored.Spec.RemediationStrategy
+	dst.Status.Remediations = restored.Status.Remediations
 
 	return nil
 }
@@ -291,6 +307,11 @@ func Convert_v1beta1_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(i
 	return autoConvert_v1beta1_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(in, out, s)
 }
 
+func Convert_v1beta1_ClusterStatus_To_v1alpha3_ClusterStatus(in *v1beta1.ClusterStatus, out *ClusterStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.Topology does not exists in v1alpha3
+	return autoConvert_v1beta1_ClusterStatus_To_v1alpha3_ClusterStatus(in, out, s)
+}
+
 func Convert_v1alpha3_ClusterStatus_To_v1beta1_ClusterStatus(in *ClusterStatus, out *v1beta1.ClusterStatus, s apiconversion.Scope) error {
 	return autoConvert_v1alpha3_ClusterStatus_To_v1beta1_ClusterStatus(in, out, s)
 }
@@ -312,3 +333,23 @@ func Convert_v1alpha3_MachineStatus_To_v1beta1_MachineStatus(in *MachineStatus,
 	// Status.version has been removed in v1beta1, thus requiring custom conversion function. the information will be dropped.
 	return autoConvert_v1alpha3_MachineStatus_To_v1beta1_MachineStatus(in, out, s)
 }
+
+func Convert_v1beta1_MachineSpec_To_v1alpha3_MachineSpec(in *v1beta1.MachineSpec, out *MachineSpec, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.nodeVolumeDetachTimeout and spec.nodeDeletionTimeout do not exist in v1alpha3
+	return autoConvert_v1beta1_MachineSpec_To_v1alpha3_MachineSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineSetSpec_To_v1alpha3_MachineSetSpec(in *v1beta1.MachineSetSpec, out *MachineSetSpec, s apiconversion.Scope) error {
+	// spec.spreadFailureDomains has been added in v1beta1.
+	return autoConvert_v1beta1_MachineSetSpec_To_v1alpha3_MachineSetSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentSpec_To_v1alpha3_MachineDeploymentSpec(in *v1beta1.MachineDeploymentSpec, out *MachineDeploymentSpec, s apiconversion.Scope) error {
+	// spec.spreadFailureDomains has been added in v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentSpec_To_v1alpha3_MachineDeploymentSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in *v1beta1.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s apiconversion.Scope) error {
+	// status.remediations has been added in v1beta1.
+	return autoConvert_v1beta1_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in, out, s)
+}
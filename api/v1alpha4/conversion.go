@@ -19,19 +19,67 @@ package v1alpha4
 import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.Cluster)
 
-	return Convert_v1alpha4_Cluster_To_v1beta1_Cluster(src, dst, nil)
+	if err := Convert_v1alpha4_Cluster_To_v1beta1_Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.Cluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	if restored.Spec.Topology != nil && dst.Spec.Topology != nil {
+		dst.Spec.Topology.Variables = restored.Spec.Topology.Variables
+		dst.Spec.Topology.UpgradeStrategy = restored.Spec.Topology.UpgradeStrategy
+
+		if restored.Spec.Topology.Workers != nil && len(restored.Spec.Topology.Workers.MachinePools) > 0 {
+			if dst.Spec.Topology.Workers == nil {
+				dst.Spec.Topology.Workers = &v1beta1.WorkersTopology{}
+			}
+			dst.Spec.Topology.Workers.MachinePools = restored.Spec.Topology.Workers.MachinePools
+		}
+
+		dst.Spec.Topology.ControlPlane.NodeVolumeDetachTimeout = restored.Spec.Topology.ControlPlane.NodeVolumeDetachTimeout
+		dst.Spec.Topology.ControlPlane.NodeDeletionTimeout = restored.Spec.Topology.ControlPlane.NodeDeletionTimeout
+
+		// Restore the timeouts of each MachineDeployment topology, matching by name.
+		if restored.Spec.Topology.Workers != nil && dst.Spec.Topology.Workers != nil {
+			for i := range dst.Spec.Topology.Workers.MachineDeployments {
+				for _, restoredMD := range restored.Spec.Topology.Workers.MachineDeployments {
+					if dst.Spec.Topology.Workers.MachineDeployments[i].Name == restoredMD.Name {
+						dst.Spec.Topology.Workers.MachineDeployments[i].NodeVolumeDetachTimeout = restoredMD.NodeVolumeDetachTimeout
+						dst.Spec.Topology.Workers.MachineDeployments[i].NodeDeletionTimeout = restoredMD.NodeDeletionTimeout
+					}
+				}
+			}
+		}
+	}
+	dst.Status.Topology = restored.Status.Topology
+
+	return nil
 }
 
 func (dst *Cluster) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.Cluster)
 
-	return Convert_v1beta1_Cluster_To_v1alpha4_Cluster(src, dst, nil)
+	if err := Convert_v1beta1_Cluster_To_v1alpha4_Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *ClusterList) ConvertTo(dstRaw conversion.Hub) error {
@@ -49,13 +97,46 @@ func (dst *ClusterList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *ClusterClass) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.ClusterClass)
 
-	return Convert_v1alpha4_ClusterClass_To_v1beta1_ClusterClass(src, dst, nil)
+	if err := Convert_v1alpha4_ClusterClass_To_v1beta1_ClusterClass(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.ClusterClass{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Variables = restored.Spec.Variables
+	dst.Spec.Patches = restored.Spec.Patches
+	dst.Spec.Workers.MachinePools = restored.Spec.Workers.MachinePools
+	dst.Spec.ControlPlane.MachineHealthCheck = restored.Spec.ControlPlane.MachineHealthCheck
+
+	// Restore the MachineHealthCheck of each MachineDeployment class, matching by class name.
+	for i := range dst.Spec.Workers.MachineDeployments {
+		for _, restoredClass := range restored.Spec.Workers.MachineDeployments {
+			if dst.Spec.Workers.MachineDeployments[i].Class == restoredClass.Class {
+				dst.Spec.Workers.MachineDeployments[i].MachineHealthCheck = restoredClass.MachineHealthCheck
+			}
+		}
+	}
+
+	return nil
 }
 
 func (dst *ClusterClass) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.ClusterClass)
 
-	return Convert_v1beta1_ClusterClass_To_v1alpha4_ClusterClass(src, dst, nil)
+	if err := Convert_v1beta1_ClusterClass_To_v1alpha4_ClusterClass(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *ClusterClassList) ConvertTo(dstRaw conversion.Hub) error {
@@ -73,13 +154,36 @@ func (dst *ClusterClassList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.Machine)
 
-	return Convert_v1alpha4_Machine_To_v1beta1_Machine(src, dst, nil)
+	if err := Convert_v1alpha4_Machine_To_v1beta1_Machine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.Machine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.NodeVolumeDetachTimeout = restored.Spec.NodeVolumeDetachTimeout
+	dst.Spec.NodeDeletionTimeout = restored.Spec.NodeDeletionTimeout
+	dst.Status.CertificatesExpiryDate = restored.Status.CertificatesExpiryDate
+
+	return nil
 }
 
 func (dst *Machine) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.Machine)
 
-	return Convert_v1beta1_Machine_To_v1alpha4_Machine(src, dst, nil)
+	if err := Convert_v1beta1_Machine_To_v1alpha4_Machine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineList) ConvertTo(dstRaw conversion.Hub) error {
@@ -97,13 +201,36 @@ func (dst *MachineList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.MachineSet)
 
-	return Convert_v1alpha4_MachineSet_To_v1beta1_MachineSet(src, dst, nil)
+	if err := Convert_v1alpha4_MachineSet_To_v1beta1_MachineSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.MachineSet{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Template.Spec.NodeVolumeDetachTimeout = restored.Spec.Template.Spec.NodeVolumeDetachTimeout
+	dst.Spec.Template.Spec.NodeDeletionTimeout = restored.Spec.Template.Spec.NodeDeletionTimeout
+	dst.Spec.SpreadFailureDomains = restored.Spec.SpreadFailureDomains
+
+	return nil
 }
 
 func (dst *MachineSet) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.MachineSet)
 
-	return Convert_v1beta1_MachineSet_To_v1alpha4_MachineSet(src, dst, nil)
+	if err := Convert_v1beta1_MachineSet_To_v1alpha4_MachineSet(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineSetList) ConvertTo(dstRaw conversion.Hub) error {
@@ -121,13 +248,36 @@ func (dst *MachineSetList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.MachineDeployment)
 
-	return Convert_v1alpha4_MachineDeployment_To_v1beta1_MachineDeployment(src, dst, nil)
+	if err := Convert_v1alpha4_MachineDeployment_To_v1beta1_MachineDeployment(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.MachineDeployment{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Template.Spec.NodeVolumeDetachTimeout = restored.Spec.Template.Spec.NodeVolumeDetachTimeout
+	dst.Spec.Template.Spec.NodeDeletionTimeout = restored.Spec.Template.Spec.NodeDeletionTimeout
+	dst.Spec.SpreadFailureDomains = restored.Spec.SpreadFailureDomains
+
+	return nil
 }
 
 func (dst *MachineDeployment) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.MachineDeployment)
 
-	return Convert_v1beta1_MachineDeployment_To_v1alpha4_MachineDeployment(src, dst, nil)
+	if err := Convert_v1beta1_MachineDeployment_To_v1alpha4_MachineDeployment(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineDeploymentList) ConvertTo(dstRaw conversion.Hub) error {
@@ -145,13 +295,39 @@ func (dst *MachineDeploymentList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *MachineHealthCheck) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.MachineHealthCheck)
 
-	return Convert_v1alpha4_MachineHealthCheck_To_v1beta1_MachineHealthCheck(src, dst, nil)
+	if err := Convert_v1alpha4_MachineHealthCheck_To_v1beta1_MachineHealthCheck(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.MachineHealthCheck{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.RemediationLimits = restored.Spec.RemediationLimits
+	dst.Spec.UnhealthyMachineConditions = restored.Spec.UnhealthyMachineConditions
+	dst.Spec.UnhealthyNodeTaints = restored.Spec.UnhealthyNodeTaints
+	dst.Spec.DrainedNodeNotReadyTimeout = restored.Spec.DrainedNodeNotReadyTimeout
+	dst.Spec.RemediationStrategy = restored.Spec.RemediationStrategy
+	dst.Status.Remediations = restored.Status.Remediations
+
+	return nil
 }
 
 func (dst *MachineHealthCheck) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.MachineHealthCheck)
 
-	return Convert_v1beta1_MachineHealthCheck_To_v1alpha4_MachineHealthCheck(src, dst, nil)
+	if err := Convert_v1beta1_MachineHealthCheck_To_v1alpha4_MachineHealthCheck(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *MachineHealthCheckList) ConvertTo(dstRaw conversion.Hub) error {
@@ -170,3 +346,78 @@ func Convert_v1alpha4_MachineStatus_To_v1beta1_MachineStatus(in *MachineStatus,
 	// Status.version has been removed in v1beta1, thus requiring custom conversion function. the information will be dropped.
 	return autoConvert_v1alpha4_MachineStatus_To_v1beta1_MachineStatus(in, out, s)
 }
+
+func Convert_v1beta1_ClusterClassSpec_To_v1alpha4_ClusterClassSpec(in *v1beta1.ClusterClassSpec, out *ClusterClassSpec, s apiconversion.Scope) error {
+	// spec.{variables,patches} has been added in v1beta1.
+	return autoConvert_v1beta1_ClusterClassSpec_To_v1alpha4_ClusterClassSpec(in, out, s)
+}
+
+func Convert_v1beta1_Topology_To_v1alpha4_Topology(in *v1beta1.Topology, out *Topology, s apiconversion.Scope) error {
+	// spec.topology.variables and spec.topology.upgradeStrategy have been added in v1beta1.
+	return autoConvert_v1beta1_Topology_To_v1alpha4_Topology(in, out, s)
+}
+
+func Convert_v1beta1_ClusterStatus_To_v1alpha4_ClusterStatus(in *v1beta1.ClusterStatus, out *ClusterStatus, s apiconversion.Scope) error {
+	// status.topology has been added in v1beta1.
+	return autoConvert_v1beta1_ClusterStatus_To_v1alpha4_ClusterStatus(in, out, s)
+}
+
+func Convert_v1beta1_WorkersClass_To_v1alpha4_WorkersClass(in *v1beta1.WorkersClass, out *WorkersClass, s apiconversion.Scope) error {
+	// spec.workers.machinePools has been added in v1beta1.
+	return autoConvert_v1beta1_WorkersClass_To_v1alpha4_WorkersClass(in, out, s)
+}
+
+func Convert_v1beta1_WorkersTopology_To_v1alpha4_WorkersTopology(in *v1beta1.WorkersTopology, out *WorkersTopology, s apiconversion.Scope) error {
+	// spec.topology.workers.machinePools has been added in v1beta1.
+	return autoConvert_v1beta1_WorkersTopology_To_v1alpha4_WorkersTopology(in, out, s)
+}
+
+func Convert_v1beta1_ControlPlaneClass_To_v1alpha4_ControlPlaneClass(in *v1beta1.ControlPlaneClass, out *ControlPlaneClass, s apiconversion.Scope) error {
+	// spec.controlPlane.machineHealthCheck has been added in v1beta1.
+	return autoConvert_v1beta1_ControlPlaneClass_To_v1alpha4_ControlPlaneClass(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentClass_To_v1alpha4_MachineDeploymentClass(in *v1beta1.MachineDeploymentClass, out *MachineDeploymentClass, s apiconversion.Scope) error {
+	// spec.workers.machineDeployments[].machineHealthCheck has been added in v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentClass_To_v1alpha4_MachineDeploymentClass(in, out, s)
+}
+
+func Convert_v1beta1_MachineSpec_To_v1alpha4_MachineSpec(in *v1beta1.MachineSpec, out *MachineSpec, s apiconversion.Scope) error {
+	// spec.nodeVolumeDetachTimeout and spec.nodeDeletionTimeout have been added in v1beta1.
+	return autoConvert_v1beta1_MachineSpec_To_v1alpha4_MachineSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineStatus_To_v1alpha4_MachineStatus(in *v1beta1.MachineStatus, out *MachineStatus, s apiconversion.Scope) error {
+	// status.certificatesExpiryDate has been added in v1beta1.
+	return autoConvert_v1beta1_MachineStatus_To_v1alpha4_MachineStatus(in, out, s)
+}
+
+func Convert_v1beta1_ControlPlaneTopology_To_v1alpha4_ControlPlaneTopology(in *v1beta1.ControlPlaneTopology, out *ControlPlaneTopology, s apiconversion.Scope) error {
+	// spec.topology.controlPlane.{nodeVolumeDetachTimeout,nodeDeletionTimeout} have been added in v1beta1.
+	return autoConvert_v1beta1_ControlPlaneTopology_To_v1alpha4_ControlPlaneTopology(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentTopology_To_v1alpha4_MachineDeploymentTopology(in *v1beta1.MachineDeploymentTopology, out *MachineDeploymentTopology, s apiconversion.Scope) error {
+	// spec.topology.workers.machineDeployments[].{nodeVolumeDetachTimeout,nodeDeletionTimeout} have been added in v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentTopology_To_v1alpha4_MachineDeploymentTopology(in, out, s)
+}
+
+func Convert_v1beta1_MachineSetSpec_To_v1alpha4_MachineSetSpec(in *v1beta1.MachineSetSpec, out *MachineSetSpec, s apiconversion.Scope) error {
+	// spec.spreadFailureDomains has been added in v1beta1.
+	return autoConvert_v1beta1_MachineSetSpec_To_v1alpha4_MachineSetSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineDeploymentSpec_To_v1alpha4_MachineDeploymentSpec(in *v1beta1.MachineDeploymentSpec, out *MachineDeploymentSpec, s apiconversion.Scope) error {
+	// spec.spreadFailureDomains has been added in v1beta1.
+	return autoConvert_v1beta1_MachineDeploymentSpec_To_v1alpha4_MachineDeploymentSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineHealthCheckSpec_To_v1alpha4_MachineHealthCheckSpec(in *v1beta1.MachineHealthCheckSpec, out *MachineHealthCheckSpec, s apiconversion.Scope) error {
+	// spec.{remediationLimits,unhealthyMachineConditions,unhealthyNodeTaints,drainedNodeNotReadyTimeout,remediationStrategy} have been added in v1beta1.
+	return autoConvert_v1beta1_MachineHealthCheckSpec_To_v1alpha4_MachineHealthCheckSpec(in, out, s)
+}
+
+func Convert_v1beta1_MachineHealthCheckStatus_To_v1alpha4_MachineHealthCheckStatus(in *v1beta1.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s apiconversion.Scope) error {
+	// status.remediations has been added in v1beta1.
+	return autoConvert_v1beta1_MachineHealthCheckStatus_To_v1alpha4_MachineHealthCheckStatus(in, out, s)
+}
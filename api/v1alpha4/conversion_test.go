@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	fuzz "github.com/google/gofuzz"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
@@ -28,12 +29,14 @@ import (
 
 func TestFuzzyConversion(t *testing.T) {
 	t.Run("for Cluster", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
-		Hub:   &v1beta1.Cluster{},
-		Spoke: &Cluster{},
+		Hub:         &v1beta1.Cluster{},
+		Spoke:       &Cluster{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{ClusterVariableFuzzFunc},
 	}))
 	t.Run("for ClusterClass", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
-		Hub:   &v1beta1.ClusterClass{},
-		Spoke: &ClusterClass{},
+		Hub:         &v1beta1.ClusterClass{},
+		Spoke:       &ClusterClass{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{ClusterClassJSONFuzzFunc},
 	}))
 
 	t.Run("for Machine", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
@@ -71,3 +74,76 @@ func MachineStatusFuzzer(in *MachineStatus, c fuzz.Continue) {
 	// data is going to be lost, so we're forcing zero values to avoid round trip errors.
 	in.Version = nil
 }
+
+func ClusterVariableFuzzFunc(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		ClusterVariableFuzzer,
+	}
+}
+
+func ClusterVariableFuzzer(in *v1beta1.ClusterVariable, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting a valid value.
+	in.Value = apiextensionsv1.JSON{Raw: []byte("\"test-string\"")}
+}
+
+func ClusterClassJSONFuzzFunc(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		JSONPatchFuzzer,
+		JSONSchemaPropsFuzzer,
+	}
+}
+
+func JSONPatchFuzzer(in *v1beta1.JSONPatch, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting a valid value.
+	in.Value = &apiextensionsv1.JSON{Raw: []byte("5")}
+}
+
+func JSONSchemaPropsFuzzer(in *v1beta1.JSONSchemaProps, c fuzz.Continue) {
+	// NOTE: We have to fuzz the individual fields manually,
+	// because we cannot call `FuzzNoCustom` as it would lead
+	// to an infinite recursion.
+	in.Type = c.RandString()
+	in.Format = c.RandString()
+	in.Pattern = c.RandString()
+	in.UniqueItems = c.RandBool()
+	in.ExclusiveMaximum = c.RandBool()
+	in.ExclusiveMinimum = c.RandBool()
+	c.Fuzz(&in.MaxItems)
+	c.Fuzz(&in.MinItems)
+	c.Fuzz(&in.MaxLength)
+	c.Fuzz(&in.MinLength)
+	c.Fuzz(&in.Maximum)
+	c.Fuzz(&in.Minimum)
+
+	// Empty slices are dropped on round-trip because of omitempty, so we're setting them to nil or to non-empty values.
+	in.Required = nil
+	for i := 0; i < c.Intn(5); i++ {
+		in.Required = append(in.Required, c.RandString())
+	}
+
+	// Not every random byte array is valid JSON, e.g. a string without `""`,so we're setting valid values.
+	in.Enum = []apiextensionsv1.JSON{
+		{Raw: []byte("\"a\"")},
+		{Raw: []byte("\"b\"")},
+		{Raw: []byte("\"c\"")},
+	}
+	in.Default = &apiextensionsv1.JSON{Raw: []byte("true")}
+
+	// We're using a copy of the current JSONSchemaProps,
+	// because we cannot recursively fuzz new schemas.
+	in.Properties = nil
+	if c.RandBool() {
+		in.Properties = map[string]v1beta1.JSONSchemaProps{}
+		for i := 0; i < c.Intn(10)+1; i++ {
+			in.Properties[c.RandString()] = *in.DeepCopy()
+		}
+	}
+	in.Items = nil
+	if c.RandBool() {
+		in.Items = in.DeepCopy()
+	}
+}
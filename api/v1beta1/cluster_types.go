@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
@@ -90,6 +91,17 @@ type Topology struct {
 	// for the cluster.
 	// +optional
 	Workers *WorkersTopology `json:"workers,omitempty"`
+
+	// UpgradeStrategy defines how the worker nodes of the cluster are upgraded
+	// when the Kubernetes version of the cluster changes.
+	// +optional
+	UpgradeStrategy *TopologyUpgradeStrategy `json:"upgradeStrategy,omitempty"`
+
+	// Variables can be used to customize the Cluster through
+	// patches. They must comply to the corresponding
+	// VariableClasses defined in the ClusterClass.
+	// +optional
+	Variables []ClusterVariable `json:"variables,omitempty"`
 }
 
 // ControlPlaneTopology specifies the parameters for the control plane nodes in the cluster.
@@ -108,6 +120,24 @@ type ControlPlaneTopology struct {
 	// When specified against a control plane provider that lacks support for this field, this value will be ignored.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// NodeVolumeDetachTimeout is the total amount of time that the controller will spend on waiting for all volumes
+	// to be detached from a control plane node after it has been drained.
+	// The default value is 0, meaning that the volumes can be detached without any time limitations.
+	//
+	// This field is supported if and only if the control plane provider template
+	// referenced in the ClusterClass is Machine based.
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+
+	// NodeDeletionTimeout defines how long the controller will attempt to delete the Node that the Machine
+	// hosts after the Machine is marked for deletion. A duration of 0 will retry deletion indefinitely.
+	// Defaults to 10 seconds.
+	//
+	// This field is supported if and only if the control plane provider template
+	// referenced in the ClusterClass is Machine based.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
 }
 
 // WorkersTopology represents the different sets of worker nodes in the cluster.
@@ -115,6 +145,11 @@ type WorkersTopology struct {
 	// MachineDeployments is a list of machine deployments in the cluster.
 	// +optional
 	MachineDeployments []MachineDeploymentTopology `json:"machineDeployments,omitempty"`
+
+	// MachinePools is a list of machine pools in the cluster.
+	// NOTE: This field is considered only if the MachinePool feature flag is enabled.
+	// +optional
+	MachinePools []MachinePoolTopology `json:"machinePools,omitempty"`
 }
 
 // MachineDeploymentTopology specifies the different parameters for a set of worker nodes in the topology.
@@ -142,6 +177,88 @@ type MachineDeploymentTopology struct {
 	// of this value.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// NodeVolumeDetachTimeout is the total amount of time that the controller will spend on waiting for all volumes
+	// to be detached from a node after it has been drained.
+	// The default value is 0, meaning that the volumes can be detached without any time limitations.
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+
+	// NodeDeletionTimeout defines how long the controller will attempt to delete the Node that the Machine
+	// hosts after the Machine is marked for deletion. A duration of 0 will retry deletion indefinitely.
+	// Defaults to 10 seconds.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
+}
+
+// MachinePoolTopology specifies the different parameters for a pool of worker nodes in the topology.
+// This pool of nodes is managed by a MachinePool object whose lifecycle is managed by the Cluster controller.
+type MachinePoolTopology struct {
+	// Metadata is the metadata applied to the machines of the MachinePool.
+	// At runtime this metadata is merged with the corresponding metadata from the ClusterClass.
+	// +optional
+	Metadata ObjectMeta `json:"metadata,omitempty"`
+
+	// Class is the name of the MachinePoolClass used to create the pool of worker nodes.
+	// This should match one of the pool classes defined in the ClusterClass object
+	// mentioned in the `Cluster.Spec.Class` field.
+	Class string `json:"class"`
+
+	// Name is the unique identifier for this MachinePoolTopology.
+	// The value is used with other unique identifiers to create a MachinePool's Name
+	// (e.g. cluster's name, etc).
+	Name string `json:"name"`
+
+	// Replicas is the number of worker nodes belonging to this pool.
+	// If the value is nil, the MachinePool is created without the number of Replicas (defaulting to one)
+	// and it's assumed that an external entity (like cluster autoscaler) is responsible for the management
+	// of this value.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// TopologyUpgradeStrategy defines how the worker nodes of a managed topology are upgraded.
+type TopologyUpgradeStrategy struct {
+	// MachineDeployments defines how the MachineDeployments of the topology are upgraded.
+	// +optional
+	MachineDeployments *MachineDeploymentsUpgradeStrategy `json:"machineDeployments,omitempty"`
+}
+
+// MachineDeploymentsUpgradeStrategy defines the order and the concurrency used to upgrade
+// the MachineDeployments of a managed topology.
+// NOTE: MachineDeployments are upgraded only after the control plane has been upgraded.
+type MachineDeploymentsUpgradeStrategy struct {
+	// MaxConcurrency is the maximum number of MachineDeployments which can be upgraded at the same time.
+	// Defaults to 1, which means that MachineDeployments are upgraded one at a time.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrency *int32 `json:"maxConcurrency,omitempty"`
+
+	// OrderByLabel is the key of a label in the metadata of the MachineDeployment topologies
+	// used to define the upgrade order: MachineDeployments are upgraded in groups sorted by the value of the label,
+	// and a group is upgraded only after all the MachineDeployments in the previous groups are upgraded.
+	// Values are compared as integers if both are integers, as strings otherwise; MachineDeployments without
+	// the label are upgraded last.
+	// If not set, MachineDeployments are upgraded in the order they are defined in the topology.
+	// +optional
+	OrderByLabel string `json:"orderByLabel,omitempty"`
+}
+
+// ClusterVariable can be used to customize the Cluster through
+// patches. It must comply to the corresponding
+// ClusterClassVariable defined in the ClusterClass.
+type ClusterVariable struct {
+	// Name of the variable.
+	Name string `json:"name"`
+
+	// Value of the variable.
+	// Note: the value will be validated against the schema of the corresponding ClusterClassVariable
+	// from the ClusterClass.
+	// Note: We have to use apiextensionsv1.JSON instead of a custom JSON type, because controller-tools has a
+	// hard-coded schema for apiextensionsv1.JSON which cannot be produced by another type via controller-tools,
+	// i.e. it's not possible to have no type field.
+	// Ref: https://github.com/kubernetes-sigs/controller-tools/blob/d0e03a142d0ecdd5491593e941ee1d6b5d91dba6/pkg/crd/known_types.go#L106-L111
+	Value apiextensionsv1.JSON `json:"value"`
 }
 
 // ANCHOR_END: ClusterSpec
@@ -226,10 +343,39 @@ type ClusterStatus struct {
 	// ObservedGeneration is the latest generation observed by the controller.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Topology reports the status of the managed topology, if any.
+	// +optional
+	Topology *TopologyStatus `json:"topology,omitempty"`
 }
 
 // ANCHOR_END: ClusterStatus
 
+// TopologyStatus reports the status of a managed topology.
+type TopologyStatus struct {
+	// MachineDeployments reports the upgrade status of the MachineDeployments of the topology.
+	// +optional
+	MachineDeployments MachineDeploymentsUpgradeStatus `json:"machineDeployments,omitempty"`
+}
+
+// MachineDeploymentsUpgradeStatus reports which MachineDeployments of a managed topology are waiting to be
+// upgraded, are upgrading or are upgraded to the Kubernetes version of the topology.
+// MachineDeployments are identified by the name of the corresponding MachineDeployment topology.
+type MachineDeploymentsUpgradeStatus struct {
+	// Pending is the list of MachineDeployments waiting to be upgraded.
+	// +optional
+	Pending []string `json:"pending,omitempty"`
+
+	// Upgrading is the list of MachineDeployments which picked up the Kubernetes version of the topology
+	// and are rolling out.
+	// +optional
+	Upgrading []string `json:"upgrading,omitempty"`
+
+	// Upgraded is the list of MachineDeployments running the Kubernetes version of the topology.
+	// +optional
+	Upgraded []string `json:"upgraded,omitempty"`
+}
+
 // SetTypedPhase sets the Phase field to the string representation of ClusterPhase.
 func (c *ClusterStatus) SetTypedPhase(p ClusterPhase) {
 	c.Phase = string(p)
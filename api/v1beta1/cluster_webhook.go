@@ -24,6 +24,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util/version"
@@ -173,6 +174,60 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 		}
 	}
 
+	if c.Spec.Topology.Workers != nil && len(c.Spec.Topology.Workers.MachinePools) > 0 {
+		// NOTE: MachinePools are behind the MachinePool feature gate flag; the web hook
+		// must prevent the usage of Cluster.Topology.Workers.MachinePools in case the feature flag is disabled.
+		if !feature.Gates.Enabled(feature.MachinePool) {
+			allErrs = append(allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "topology", "workers", "machinePools"),
+					"can be set only if the MachinePool feature flag is enabled",
+				),
+			)
+		}
+
+		// MachinePool names must be unique.
+		names := sets.String{}
+		for _, mp := range c.Spec.Topology.Workers.MachinePools {
+			if names.Has(mp.Name) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						field.NewPath("spec", "topology", "workers", "machinePools"),
+						mp,
+						fmt.Sprintf("MachinePool names should be unique. MachinePool with name %q is defined more than once.", mp.Name),
+					),
+				)
+			}
+			names.Insert(mp.Name)
+		}
+	}
+
+	// MachineDeployments upgrade strategy should be valid.
+	if c.Spec.Topology.UpgradeStrategy != nil && c.Spec.Topology.UpgradeStrategy.MachineDeployments != nil {
+		mdStrategy := c.Spec.Topology.UpgradeStrategy.MachineDeployments
+		fldPath := field.NewPath("spec", "topology", "upgradeStrategy", "machineDeployments")
+		if mdStrategy.MaxConcurrency != nil && *mdStrategy.MaxConcurrency < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(
+					fldPath.Child("maxConcurrency"),
+					*mdStrategy.MaxConcurrency,
+					"must be greater than or equal to 1",
+				),
+			)
+		}
+		if mdStrategy.OrderByLabel != "" {
+			for _, msg := range validation.IsQualifiedName(mdStrategy.OrderByLabel) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						fldPath.Child("orderByLabel"),
+						mdStrategy.OrderByLabel,
+						msg,
+					),
+				)
+			}
+		}
+	}
+
 	switch old {
 	case nil: // On create
 		// c.Spec.InfrastructureRef and c.Spec.ControlPlaneRef could not be set
@@ -197,17 +252,8 @@ func (c *Cluster) validateTopology(old *Cluster) field.ErrorList {
 			)
 		}
 	default: // On update
-		// Class could not be mutated.
-		if c.Spec.Topology.Class != old.Spec.Topology.Class {
-			allErrs = append(
-				allErrs,
-				field.Invalid(
-					field.NewPath("spec", "topology", "class"),
-					c.Spec.Topology.Class,
-					"class cannot be changed",
-				),
-			)
-		}
+		// NOTE: Class can be changed to a compatible ClusterClass (rebase); compatibility is validated by the
+		// Cluster webhook in internal/webhooks, given that it requires reading the ClusterClasses.
 
 		// Version could only be increased.
 		inVersion, err := semver.ParseTolerant(c.Spec.Topology.Version)
@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilfeature "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/cluster-api/feature"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
 )
@@ -369,8 +370,8 @@ func TestClusterTopologyValidation(t *testing.T) {
 			},
 		},
 		{
-			name:      "should return error on update when Topology class is changed",
-			expectErr: true,
+			name:      "should update when Topology class is changed",
+			expectErr: false,
 			old: &Cluster{
 				Spec: ClusterSpec{
 					InfrastructureRef: &corev1.ObjectReference{},
@@ -390,6 +391,58 @@ func TestClusterTopologyValidation(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "should return error when MachineDeployments upgrade concurrency is less than 1",
+			expectErr: true,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						UpgradeStrategy: &TopologyUpgradeStrategy{
+							MachineDeployments: &MachineDeploymentsUpgradeStrategy{
+								MaxConcurrency: pointer.Int32(0),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should return error when MachineDeployments upgrade order label is not a valid label key",
+			expectErr: true,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						UpgradeStrategy: &TopologyUpgradeStrategy{
+							MachineDeployments: &MachineDeploymentsUpgradeStrategy{
+								OrderByLabel: "not a label!",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should pass with a valid MachineDeployments upgrade strategy",
+			expectErr: false,
+			in: &Cluster{
+				Spec: ClusterSpec{
+					Topology: &Topology{
+						Class:   "foo",
+						Version: "v1.19.1",
+						UpgradeStrategy: &TopologyUpgradeStrategy{
+							MachineDeployments: &MachineDeploymentsUpgradeStrategy{
+								MaxConcurrency: pointer.Int32(2),
+								OrderByLabel:   "example.com/upgrade-order",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:      "should return error on update when Topology version is downgraded",
 			expectErr: true,
@@ -469,3 +522,67 @@ func TestClusterTopologyValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterTopologyMachinePoolsValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	clusterWithMachinePools := func(names ...string) *Cluster {
+		mps := []MachinePoolTopology{}
+		for _, name := range names {
+			mps = append(mps, MachinePoolTopology{Class: "aa", Name: name})
+		}
+		return &Cluster{
+			Spec: ClusterSpec{
+				Topology: &Topology{
+					Class:   "foo",
+					Version: "v1.19.1",
+					Workers: &WorkersTopology{
+						MachinePools: mps,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		in                 *Cluster
+		machinePoolEnabled bool
+		expectErr          bool
+	}{
+		{
+			name:               "should return error when machine pools are set but the MachinePool feature flag is disabled",
+			in:                 clusterWithMachinePools("aa"),
+			machinePoolEnabled: false,
+			expectErr:          true,
+		},
+		{
+			name:               "should return error when machine pool names are not unique",
+			in:                 clusterWithMachinePools("aa", "aa"),
+			machinePoolEnabled: true,
+			expectErr:          true,
+		},
+		{
+			name:               "should pass when machine pool names are unique",
+			in:                 clusterWithMachinePools("aa", "bb"),
+			machinePoolEnabled: true,
+			expectErr:          false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.MachinePool, tt.machinePoolEnabled)()
+			g := NewWithT(t)
+
+			err := tt.in.validate(nil)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
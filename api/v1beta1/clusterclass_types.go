@@ -18,7 +18,9 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +kubebuilder:object:root=true
@@ -54,6 +56,17 @@ type ClusterClassSpec struct {
 	// the worker nodes of the cluster.
 	// +optional
 	Workers WorkersClass `json:"workers,omitempty"`
+
+	// Variables defines the variables which can be configured
+	// in the Cluster topology and are then used in patches.
+	// +optional
+	Variables []ClusterClassVariable `json:"variables,omitempty"`
+
+	// Patches defines the patches which are applied to customize
+	// referenced templates of a ClusterClass.
+	// Note: Patches will be applied in the order of the array.
+	// +optional
+	Patches []ClusterClassPatch `json:"patches,omitempty"`
 }
 
 // ControlPlaneClass defines the class for the control plane.
@@ -77,14 +90,26 @@ type ControlPlaneClass struct {
 	//
 	// +optional
 	MachineInfrastructure *LocalObjectTemplate `json:"machineInfrastructure,omitempty"`
+
+	// MachineHealthCheck defines a MachineHealthCheck for this ControlPlaneClass.
+	// This field is supported if and only if the ControlPlane provider template
+	// referenced above is Machine based and supports setting replicas.
+	// +optional
+	MachineHealthCheck *MachineHealthCheckClass `json:"machineHealthCheck,omitempty"`
 }
 
-// WorkersClass is a collection of deployment classes.
+// WorkersClass is a collection of deployment and pool classes.
 type WorkersClass struct {
 	// MachineDeployments is a list of machine deployment classes that can be used to create
 	// a set of worker nodes.
 	// +optional
 	MachineDeployments []MachineDeploymentClass `json:"machineDeployments,omitempty"`
+
+	// MachinePools is a list of machine pool classes that can be used to create
+	// a set of worker nodes.
+	// NOTE: This field is considered only if the MachinePool feature flag is enabled.
+	// +optional
+	MachinePools []MachinePoolClass `json:"machinePools,omitempty"`
 }
 
 // MachineDeploymentClass serves as a template to define a set of worker nodes of the cluster
@@ -98,6 +123,10 @@ type MachineDeploymentClass struct {
 	// Template is a local struct containing a collection of templates for creation of
 	// MachineDeployment objects representing a set of worker nodes.
 	Template MachineDeploymentClassTemplate `json:"template"`
+
+	// MachineHealthCheck defines a MachineHealthCheck for this MachineDeploymentClass.
+	// +optional
+	MachineHealthCheck *MachineHealthCheckClass `json:"machineHealthCheck,omitempty"`
 }
 
 // MachineDeploymentClassTemplate defines how a MachineDeployment generated from a MachineDeploymentClass
@@ -117,6 +146,75 @@ type MachineDeploymentClassTemplate struct {
 	Infrastructure LocalObjectTemplate `json:"infrastructure"`
 }
 
+// MachineHealthCheckClass defines a MachineHealthCheck for a group of Machines.
+// The corresponding MachineHealthCheck objects are created and owned by the topology controller;
+// the target selector and the cluster name are computed for each Cluster.
+type MachineHealthCheckClass struct {
+	// UnhealthyConditions contains a list of the conditions that determine
+	// whether a node is considered unhealthy. The conditions are combined in a
+	// logical OR, i.e. if any of the conditions is met, the node is unhealthy.
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions,omitempty"`
+
+	// Any further remediation is only allowed if at most "MaxUnhealthy" machines selected by
+	// "selector" are not healthy.
+	// +optional
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+
+	// Any further remediation is only allowed if the number of machines selected by "selector" as not healthy
+	// is within the range of "UnhealthyRange". Takes precedence over MaxUnhealthy.
+	// Eg. "[3-5]" - This means that remediation will be allowed only when:
+	// (a) there are at least 3 unhealthy machines (and)
+	// (b) there are at most 5 unhealthy machines
+	// +optional
+	// +kubebuilder:validation:Pattern=^\[[0-9]+-[0-9]+\]$
+	UnhealthyRange *string `json:"unhealthyRange,omitempty"`
+
+	// Machines older than this duration without a node will be considered to have
+	// failed and will be remediated.
+	// If you wish to disable this feature, set the value explicitly to 0.
+	// +optional
+	NodeStartupTimeout *metav1.Duration `json:"nodeStartupTimeout,omitempty"`
+
+	// RemediationTemplate is a reference to a remediation template
+	// provided by an infrastructure provider.
+	//
+	// This field is completely optional, when filled, the MachineHealthCheck controller
+	// creates a new object from the template referenced and hands off remediation of the machine to
+	// a controller that lives outside of Cluster API.
+	// +optional
+	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+}
+
+// MachinePoolClass serves as a template to define a pool of worker nodes of the cluster
+// provisioned using the `ClusterClass`.
+type MachinePoolClass struct {
+	// Class denotes a type of machine pool present in the cluster,
+	// this name MUST be unique within a ClusterClass and can be referenced
+	// in the Cluster to create a managed MachinePool.
+	Class string `json:"class"`
+
+	// Template is a local struct containing a collection of templates for creation of
+	// MachinePool objects representing a pool of worker nodes.
+	Template MachinePoolClassTemplate `json:"template"`
+}
+
+// MachinePoolClassTemplate defines how a MachinePool generated from a MachinePoolClass
+// should look like.
+type MachinePoolClassTemplate struct {
+	// Metadata is the metadata applied to the machines of the MachinePool.
+	// At runtime this metadata is merged with the corresponding metadata from the topology.
+	// +optional
+	Metadata ObjectMeta `json:"metadata,omitempty"`
+
+	// Bootstrap contains the bootstrap template reference to be used
+	// for the creation of the bootstrap config of the MachinePool.
+	Bootstrap LocalObjectTemplate `json:"bootstrap"`
+
+	// Infrastructure contains the infrastructure template reference to be used
+	// for the creation of the infrastructure machine pool of the MachinePool.
+	Infrastructure LocalObjectTemplate `json:"infrastructure"`
+}
+
 // LocalObjectTemplate defines a template for a topology Class.
 type LocalObjectTemplate struct {
 	// Ref is a required reference to a custom resource
@@ -124,6 +222,259 @@ type LocalObjectTemplate struct {
 	Ref *corev1.ObjectReference `json:"ref"`
 }
 
+// ClusterClassVariable defines a variable which can
+// be configured in the Cluster topology and used in patches.
+type ClusterClassVariable struct {
+	// Name of the variable.
+	Name string `json:"name"`
+
+	// Required specifies if the variable is required.
+	// Note: this applies to the variable as a whole and thus the
+	// top-level object defined in the schema. If nested fields are
+	// required, this will be specified inside the schema.
+	Required bool `json:"required"`
+
+	// Schema defines the schema of the variable.
+	Schema VariableSchema `json:"schema"`
+}
+
+// VariableSchema defines the schema of a variable.
+type VariableSchema struct {
+	// OpenAPIV3Schema defines the schema of a variable via OpenAPI v3
+	// schema. The schema is a subset of the schema used in
+	// Kubernetes CRDs.
+	OpenAPIV3Schema JSONSchemaProps `json:"openAPIV3Schema"`
+}
+
+// JSONSchemaProps is a JSON-Schema following Specification Draft 4 (http://json-schema.org/).
+// This struct has been initially copied from apiextensionsv1.JSONSchemaProps, but all fields
+// which are not supported in CAPI have been removed.
+type JSONSchemaProps struct {
+	// Type is the type of the variable.
+	// Valid values are: object, array, string, integer, number or boolean.
+	Type string `json:"type"`
+
+	// Properties specifies fields of an object.
+	// NOTE: Can only be set if type is object.
+	// NOTE: This field uses PreserveUnknownFields and Schemaless,
+	// because recursive validation is not possible.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Properties map[string]JSONSchemaProps `json:"properties,omitempty"`
+
+	// Required specifies which fields of an object are required.
+	// NOTE: Can only be set if type is object.
+	// +optional
+	Required []string `json:"required,omitempty"`
+
+	// Items specifies fields of an array.
+	// NOTE: Can only be set if type is array.
+	// NOTE: This field uses PreserveUnknownFields and Schemaless,
+	// because recursive validation is not possible.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Items *JSONSchemaProps `json:"items,omitempty"`
+
+	// MaxItems is the max length of an array variable.
+	// NOTE: Can only be set if type is array.
+	// +optional
+	MaxItems *int64 `json:"maxItems,omitempty"`
+
+	// MinItems is the min length of an array variable.
+	// NOTE: Can only be set if type is array.
+	// +optional
+	MinItems *int64 `json:"minItems,omitempty"`
+
+	// UniqueItems specifies if items in an array must be unique.
+	// NOTE: Can only be set if type is array.
+	// +optional
+	UniqueItems bool `json:"uniqueItems,omitempty"`
+
+	// Format is an OpenAPI v3 format string. Unknown formats are ignored.
+	// For a list of supported formats please see: (of the k8s.io/apiextensions-apiserver version we're currently using)
+	// https://github.com/kubernetes/apiextensions-apiserver/blob/master/pkg/apiserver/validation/formats.go
+	// NOTE: Can only be set if type is string.
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// MaxLength is the max length of a string variable.
+	// NOTE: Can only be set if type is string.
+	// +optional
+	MaxLength *int64 `json:"maxLength,omitempty"`
+
+	// MinLength is the min length of a string variable.
+	// NOTE: Can only be set if type is string.
+	// +optional
+	MinLength *int64 `json:"minLength,omitempty"`
+
+	// Pattern is the regex which a string variable must match.
+	// NOTE: Can only be set if type is string.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Maximum is the maximum of an integer or number variable.
+	// If ExclusiveMaximum is false, the variable is valid if it is lower than, or equal to, the value of Maximum.
+	// If ExclusiveMaximum is true, the variable is valid if it is strictly lower than the value of Maximum.
+	// NOTE: Can only be set if type is integer or number.
+	// +optional
+	Maximum *int64 `json:"maximum,omitempty"`
+
+	// ExclusiveMaximum specifies if the Maximum is exclusive.
+	// NOTE: Can only be set if type is integer or number.
+	// +optional
+	ExclusiveMaximum bool `json:"exclusiveMaximum,omitempty"`
+
+	// Minimum is the minimum of an integer or number variable.
+	// If ExclusiveMinimum is false, the variable is valid if it is greater than, or equal to, the value of Minimum.
+	// If ExclusiveMinimum is true, the variable is valid if it is strictly greater than the value of Minimum.
+	// NOTE: Can only be set if type is integer or number.
+	// +optional
+	Minimum *int64 `json:"minimum,omitempty"`
+
+	// ExclusiveMinimum specifies if the Minimum is exclusive.
+	// NOTE: Can only be set if type is integer or number.
+	// +optional
+	ExclusiveMinimum bool `json:"exclusiveMinimum,omitempty"`
+
+	// Enum is the list of valid values of the variable.
+	// NOTE: Can be set for all types.
+	// +optional
+	Enum []apiextensionsv1.JSON `json:"enum,omitempty"`
+
+	// Default is the default value of the variable.
+	// NOTE: Can be set for all types.
+	// +optional
+	Default *apiextensionsv1.JSON `json:"default,omitempty"`
+}
+
+// ClusterClassPatch defines a patch which is applied to customize the referenced templates.
+type ClusterClassPatch struct {
+	// Name of the patch.
+	Name string `json:"name"`
+
+	// Definitions define the patches inline.
+	// Note: Patches will be applied in the order of the array.
+	// Exactly one of Definitions or External must be set.
+	// +optional
+	Definitions []PatchDefinition `json:"definitions,omitempty"`
+
+	// External defines an external patch generator, which is called by the topology
+	// controller to compute the patches.
+	// Exactly one of Definitions or External must be set.
+	// +optional
+	External *ExternalPatchDefinition `json:"external,omitempty"`
+}
+
+// ExternalPatchDefinition defines an external patch generator.
+type ExternalPatchDefinition struct {
+	// Extension is the name of the ExtensionConfig registering the extension
+	// which generates the patches.
+	Extension string `json:"extension"`
+}
+
+// PatchDefinition defines a patch which is applied to customize the referenced templates.
+type PatchDefinition struct {
+	// Selector defines on which templates the patch should be applied.
+	Selector PatchSelector `json:"selector"`
+
+	// JSONPatches defines the patches which should be applied on the templates
+	// matching the selector.
+	// Note: Patches will be applied in the order of the array.
+	JSONPatches []JSONPatch `json:"jsonPatches"`
+}
+
+// PatchSelector defines on which templates the patch should be applied.
+// Note: Matching on APIVersion and Kind is mandatory, to enforce that the patches are
+// written for the correct version. The version of the references in the ClusterClass may
+// be automatically updated during reconciliation if there is a newer version for the same contract.
+type PatchSelector struct {
+	// APIVersion filters templates by apiVersion.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind filters templates by kind.
+	Kind string `json:"kind"`
+
+	// MatchResources selects templates based on where they are referenced.
+	MatchResources PatchSelectorMatch `json:"matchResources"`
+}
+
+// PatchSelectorMatch selects templates based on where they are referenced.
+// Note: At least one of the fields must be set.
+// Note: The results of selection based on the individual fields are ORed.
+type PatchSelectorMatch struct {
+	// ControlPlane selects templates referenced in .spec.ControlPlane.
+	// Note: this will match the controlPlane and also the controlPlane
+	// machineInfrastructure (depending on the kind and apiVersion).
+	// +optional
+	ControlPlane bool `json:"controlPlane,omitempty"`
+
+	// InfrastructureCluster selects templates referenced in .spec.infrastructure.
+	// +optional
+	InfrastructureCluster bool `json:"infrastructureCluster,omitempty"`
+
+	// MachineDeploymentClass selects templates referenced in specific MachineDeploymentClasses in
+	// .spec.workers.machineDeployments.
+	// +optional
+	MachineDeploymentClass *PatchSelectorMatchMachineDeploymentClass `json:"machineDeploymentClass,omitempty"`
+
+	// MachinePoolClass selects templates referenced in specific MachinePoolClasses in
+	// .spec.workers.machinePools.
+	// +optional
+	MachinePoolClass *PatchSelectorMatchMachinePoolClass `json:"machinePoolClass,omitempty"`
+}
+
+// PatchSelectorMatchMachineDeploymentClass selects templates referenced
+// in specific MachineDeploymentClasses in .spec.workers.machineDeployments.
+type PatchSelectorMatchMachineDeploymentClass struct {
+	// Names selects templates by class names.
+	Names []string `json:"names"`
+}
+
+// PatchSelectorMatchMachinePoolClass selects templates referenced
+// in specific MachinePoolClasses in .spec.workers.machinePools.
+type PatchSelectorMatchMachinePoolClass struct {
+	// Names selects templates by class names.
+	Names []string `json:"names"`
+}
+
+// JSONPatch defines a JSON patch.
+type JSONPatch struct {
+	// Op defines the operation of the patch.
+	// Note: Only `add`, `replace` and `remove` are supported.
+	// +kubebuilder:validation:Enum=add;replace;remove
+	Op string `json:"op"`
+
+	// Path defines the path of the patch.
+	// Note: Only the spec of a template can be patched, thus the path has to start with /spec/.
+	// Note: For now the only allowed array modifications are `append` and `prepend`, i.e.:
+	// * for op: `add`: only index 0 (prepend) and - (append) are allowed
+	// * for op: `replace` or `remove`: no indexes are allowed
+	Path string `json:"path"`
+
+	// Value defines the value of the patch.
+	// Note: Either Value or ValueFrom is required for add and replace
+	// operations. Only one of them is allowed to be set at the same time.
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+
+	// ValueFrom defines the value of the patch.
+	// Note: Either Value or ValueFrom is required for add and replace
+	// operations. Only one of them is allowed to be set at the same time.
+	// +optional
+	ValueFrom *JSONPatchValue `json:"valueFrom,omitempty"`
+}
+
+// JSONPatchValue defines the value of a patch.
+type JSONPatchValue struct {
+	// Variable is the variable to be used as value.
+	// Variable can be one of the variables defined in .spec.variables or a builtin variable.
+	// Nested fields of an object variable can be referenced using a dot-separated path,
+	// e.g. `builtin.cluster.name` or `network.cidr`.
+	Variable string `json:"variable"`
+}
+
 // +kubebuilder:object:root=true
 
 // ClusterClassList contains a list of Cluster.
@@ -22,6 +22,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -53,9 +54,21 @@ func (in *ClusterClass) Default() {
 		defaultNamespace(in.Spec.ControlPlane.MachineInfrastructure.Ref, in.Namespace)
 	}
 
+	if in.Spec.ControlPlane.MachineHealthCheck != nil {
+		defaultNamespace(in.Spec.ControlPlane.MachineHealthCheck.RemediationTemplate, in.Namespace)
+	}
+
 	for i := range in.Spec.Workers.MachineDeployments {
 		defaultNamespace(in.Spec.Workers.MachineDeployments[i].Template.Bootstrap.Ref, in.Namespace)
 		defaultNamespace(in.Spec.Workers.MachineDeployments[i].Template.Infrastructure.Ref, in.Namespace)
+		if in.Spec.Workers.MachineDeployments[i].MachineHealthCheck != nil {
+			defaultNamespace(in.Spec.Workers.MachineDeployments[i].MachineHealthCheck.RemediationTemplate, in.Namespace)
+		}
+	}
+
+	for i := range in.Spec.Workers.MachinePools {
+		defaultNamespace(in.Spec.Workers.MachinePools[i].Template.Bootstrap.Ref, in.Namespace)
+		defaultNamespace(in.Spec.Workers.MachinePools[i].Template.Infrastructure.Ref, in.Namespace)
 	}
 }
 
@@ -96,12 +109,24 @@ func (in *ClusterClass) validate(old *ClusterClass) error {
 
 	var allErrs field.ErrorList
 
+	// NOTE: MachinePools are behind the MachinePool feature gate flag; the web hook
+	// must prevent defining MachinePool classes in case the feature flag is disabled.
+	if len(in.Spec.Workers.MachinePools) > 0 && !feature.Gates.Enabled(feature.MachinePool) {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("spec", "workers", "machinePools"),
+			"can be set only if the MachinePool feature flag is enabled",
+		))
+	}
+
 	// Ensure all references are valid.
 	allErrs = append(allErrs, in.validateAllRefs()...)
 
-	// Ensure all MachineDeployment classes are unique.
+	// Ensure all MachineDeployment and MachinePool classes are unique.
 	allErrs = append(allErrs, in.Spec.Workers.validateUniqueClasses(field.NewPath("spec", "workers"))...)
 
+	// Ensure all MachineHealthCheck definitions are valid.
+	allErrs = append(allErrs, in.validateMachineHealthCheckClasses()...)
+
 	// Ensure spec changes are compatible.
 	allErrs = append(allErrs, in.validateCompatibleSpecChanges(old)...)
 
@@ -125,9 +150,67 @@ func (in *ClusterClass) validateAllRefs() field.ErrorList {
 		allErrs = append(allErrs, class.Template.Infrastructure.isValid(in.Namespace, field.NewPath("spec", "workers", "machineDeployments").Index(i).Child("template", "infrastructure"))...)
 	}
 
+	for i, class := range in.Spec.Workers.MachinePools {
+		allErrs = append(allErrs, class.Template.Bootstrap.isValid(in.Namespace, field.NewPath("spec", "workers", "machinePools").Index(i).Child("template", "bootstrap"))...)
+		allErrs = append(allErrs, class.Template.Infrastructure.isValid(in.Namespace, field.NewPath("spec", "workers", "machinePools").Index(i).Child("template", "infrastructure"))...)
+	}
+
+	return allErrs
+}
+
+func (in *ClusterClass) validateMachineHealthCheckClasses() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if in.Spec.ControlPlane.MachineHealthCheck != nil {
+		fldPath := field.NewPath("spec", "controlPlane", "machineHealthCheck")
+
+		// A MachineHealthCheck can be defined only if the control plane is Machine based.
+		if in.Spec.ControlPlane.MachineInfrastructure == nil {
+			allErrs = append(allErrs, field.Forbidden(
+				fldPath,
+				"can be set only if spec.controlPlane.machineInfrastructure is set",
+			))
+		}
+		allErrs = append(allErrs, in.Spec.ControlPlane.MachineHealthCheck.validate(in.Namespace, fldPath)...)
+	}
+
+	for i, class := range in.Spec.Workers.MachineDeployments {
+		if class.MachineHealthCheck == nil {
+			continue
+		}
+		allErrs = append(allErrs, class.MachineHealthCheck.validate(in.Namespace, field.NewPath("spec", "workers", "machineDeployments").Index(i).Child("machineHealthCheck"))...)
+	}
+
 	return allErrs
 }
 
+// validate validates a MachineHealthCheckClass using the same rules applied to MachineHealthCheck objects.
+func (m *MachineHealthCheckClass) validate(namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// NOTE: UnhealthyConditions are required in MachineHealthCheck objects.
+	if len(m.UnhealthyConditions) == 0 {
+		allErrs = append(allErrs, field.Required(
+			fldPath.Child("unhealthyConditions"),
+			"must have at least one entry",
+		))
+	}
+
+	mhc := &MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+		},
+		Spec: MachineHealthCheckSpec{
+			UnhealthyConditions: m.UnhealthyConditions,
+			MaxUnhealthy:        m.MaxUnhealthy,
+			UnhealthyRange:      m.UnhealthyRange,
+			NodeStartupTimeout:  m.NodeStartupTimeout,
+			RemediationTemplate: m.RemediationTemplate,
+		},
+	}
+	return append(allErrs, mhc.validateCommonFields(fldPath)...)
+}
+
 func (in *ClusterClass) validateCompatibleSpecChanges(old *ClusterClass) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -140,6 +223,9 @@ func (in *ClusterClass) validateCompatibleSpecChanges(old *ClusterClass) field.E
 	// Validate changes to MachineDeployments.
 	allErrs = append(allErrs, in.validateMachineDeploymentsCompatibleChanges(old)...)
 
+	// Validate changes to MachinePools.
+	allErrs = append(allErrs, in.validateMachinePoolsCompatibleChanges(old)...)
+
 	// Validate InfrastructureClusterTemplate changes in a compatible way.
 	allErrs = append(allErrs, in.Spec.Infrastructure.isCompatibleWith(
 		old.Spec.Infrastructure,
@@ -329,6 +415,42 @@ func (r *LocalObjectTemplate) isCompatibleWith(old LocalObjectTemplate, pathPref
 	return allErrs
 }
 
+func (in *ClusterClass) validateMachinePoolsCompatibleChanges(old *ClusterClass) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Ensure no MachinePool class was removed.
+	classes := in.Spec.Workers.machinePoolClassNames()
+	for _, oldClass := range old.Spec.Workers.MachinePools {
+		if !classes.Has(oldClass.Class) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workers", "machinePools"),
+					in.Spec.Workers.MachinePools,
+					fmt.Sprintf("The %q MachinePool class can't be removed.", oldClass.Class),
+				),
+			)
+		}
+	}
+
+	// Ensure previous MachinePool class was modified in a compatible way.
+	for i, class := range in.Spec.Workers.MachinePools {
+		for _, oldClass := range old.Spec.Workers.MachinePools {
+			if class.Class == oldClass.Class {
+				// NOTE: class.Template.Metadata and class.Template.Bootstrap are allowed to change;
+				// class.Template.Bootstrap are ensured syntactically correct by validateAllRefs.
+
+				// Validates class.Template.Infrastructure template changes in a compatible way
+				allErrs = append(allErrs, class.Template.Infrastructure.isCompatibleWith(
+					oldClass.Template.Infrastructure,
+					field.NewPath("spec", "workers", "machinePools").Index(i).Child("template", "infrastructure"),
+				)...)
+			}
+		}
+	}
+
+	return allErrs
+}
+
 // classNames returns the set of MachineDeployment class names.
 func (w *WorkersClass) classNames() sets.String {
 	classes := sets.NewString()
@@ -338,6 +460,15 @@ func (w *WorkersClass) classNames() sets.String {
 	return classes
 }
 
+// machinePoolClassNames returns the set of MachinePool class names.
+func (w *WorkersClass) machinePoolClassNames() sets.String {
+	classes := sets.NewString()
+	for _, class := range w.MachinePools {
+		classes.Insert(class.Class)
+	}
+	return classes
+}
+
 func (w *WorkersClass) validateUniqueClasses(pathPrefix *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -355,5 +486,19 @@ func (w *WorkersClass) validateUniqueClasses(pathPrefix *field.Path) field.Error
 		classes.Insert(class.Class)
 	}
 
+	classes = sets.NewString()
+	for i, class := range w.MachinePools {
+		if classes.Has(class.Class) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					pathPrefix.Child("machinePools").Index(i).Child("class"),
+					class.Class,
+					fmt.Sprintf("MachinePool class should be unique. MachinePool with class %q is defined more than once.", class.Class),
+				),
+			)
+		}
+		classes.Insert(class.Class)
+	}
+
 	return allErrs
 }
@@ -18,10 +18,12 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilfeature "k8s.io/component-base/featuregate/testing"
 	"sigs.k8s.io/cluster-api/feature"
 
@@ -48,6 +50,10 @@ func TestClusterClassDefaultNamespaces(t *testing.T) {
 			ControlPlane: ControlPlaneClass{
 				LocalObjectTemplate:   LocalObjectTemplate{Ref: ref},
 				MachineInfrastructure: &LocalObjectTemplate{Ref: ref},
+				MachineHealthCheck: &MachineHealthCheckClass{
+					UnhealthyConditions: []UnhealthyCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}},
+					RemediationTemplate: ref.DeepCopy(),
+				},
 			},
 			Workers: WorkersClass{
 				MachineDeployments: []MachineDeploymentClass{
@@ -57,6 +63,10 @@ func TestClusterClassDefaultNamespaces(t *testing.T) {
 							Bootstrap:      LocalObjectTemplate{Ref: ref},
 							Infrastructure: LocalObjectTemplate{Ref: ref},
 						},
+						MachineHealthCheck: &MachineHealthCheckClass{
+							UnhealthyConditions: []UnhealthyCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}},
+							RemediationTemplate: ref.DeepCopy(),
+						},
 					},
 				},
 			},
@@ -71,9 +81,11 @@ func TestClusterClassDefaultNamespaces(t *testing.T) {
 	g.Expect(in.Spec.Infrastructure.Ref.Namespace).To(Equal(namespace))
 	g.Expect(in.Spec.ControlPlane.Ref.Namespace).To(Equal(namespace))
 	g.Expect(in.Spec.ControlPlane.MachineInfrastructure.Ref.Namespace).To(Equal(namespace))
+	g.Expect(in.Spec.ControlPlane.MachineHealthCheck.RemediationTemplate.Namespace).To(Equal(namespace))
 	for i := range in.Spec.Workers.MachineDeployments {
 		g.Expect(in.Spec.Workers.MachineDeployments[i].Template.Bootstrap.Ref.Namespace).To(Equal(namespace))
 		g.Expect(in.Spec.Workers.MachineDeployments[i].Template.Infrastructure.Ref.Namespace).To(Equal(namespace))
+		g.Expect(in.Spec.Workers.MachineDeployments[i].MachineHealthCheck.RemediationTemplate.Namespace).To(Equal(namespace))
 	}
 }
 
@@ -1442,3 +1454,243 @@ func TestClusterClassValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterClassMachinePoolsValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "group.test.io/foo",
+		Kind:       "barTemplate",
+		Name:       "baz",
+		Namespace:  "default",
+	}
+	incompatibleRef := &corev1.ObjectReference{
+		APIVersion: "group.test.io/foo",
+		Kind:       "another-barTemplate",
+		Name:       "baz",
+		Namespace:  "default",
+	}
+
+	clusterClassWithMachinePools := func(mps ...MachinePoolClass) *ClusterClass {
+		return &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+			},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref},
+				ControlPlane: ControlPlaneClass{
+					LocalObjectTemplate: LocalObjectTemplate{Ref: ref},
+				},
+				Workers: WorkersClass{
+					MachinePools: mps,
+				},
+			},
+		}
+	}
+	machinePoolClass := func(class string, infraRef *corev1.ObjectReference) MachinePoolClass {
+		return MachinePoolClass{
+			Class: class,
+			Template: MachinePoolClassTemplate{
+				Bootstrap:      LocalObjectTemplate{Ref: ref},
+				Infrastructure: LocalObjectTemplate{Ref: infraRef},
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		in                 *ClusterClass
+		old                *ClusterClass
+		machinePoolEnabled bool
+		expectErr          bool
+	}{
+		{
+			name:               "create should fail if machine pools are set but the MachinePool feature flag is disabled",
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", ref)),
+			machinePoolEnabled: false,
+			expectErr:          true,
+		},
+		{
+			name:               "create pass",
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", ref), machinePoolClass("bb", ref)),
+			machinePoolEnabled: true,
+			expectErr:          false,
+		},
+		{
+			name:               "create fail if machine pool classes are not unique",
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", ref), machinePoolClass("aa", ref)),
+			machinePoolEnabled: true,
+			expectErr:          true,
+		},
+		{
+			name:               "update fail if a machine pool class is removed",
+			old:                clusterClassWithMachinePools(machinePoolClass("aa", ref), machinePoolClass("bb", ref)),
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", ref)),
+			machinePoolEnabled: true,
+			expectErr:          true,
+		},
+		{
+			name:               "update fail if a machine pool infrastructure template changes to an incompatible kind",
+			old:                clusterClassWithMachinePools(machinePoolClass("aa", ref)),
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", incompatibleRef)),
+			machinePoolEnabled: true,
+			expectErr:          true,
+		},
+		{
+			name:               "update pass if a machine pool class is added",
+			old:                clusterClassWithMachinePools(machinePoolClass("aa", ref)),
+			in:                 clusterClassWithMachinePools(machinePoolClass("aa", ref), machinePoolClass("bb", ref)),
+			machinePoolEnabled: true,
+			expectErr:          false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.MachinePool, tt.machinePoolEnabled)()
+			g := NewWithT(t)
+			if tt.expectErr {
+				g.Expect(tt.in.validate(tt.old)).NotTo(Succeed())
+			} else {
+				g.Expect(tt.in.validate(tt.old)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestClusterClassMachineHealthCheckValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "group.test.io/foo",
+		Kind:       "barTemplate",
+		Name:       "baz",
+		Namespace:  "default",
+	}
+	unhealthyConditions := []UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	invalidMaxUnhealthy := intstr.FromString("fifty")
+
+	clusterClass := func(cpMachineInfrastructure *LocalObjectTemplate, cpMHC, mdMHC *MachineHealthCheckClass) *ClusterClass {
+		return &ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+			},
+			Spec: ClusterClassSpec{
+				Infrastructure: LocalObjectTemplate{Ref: ref},
+				ControlPlane: ControlPlaneClass{
+					LocalObjectTemplate:   LocalObjectTemplate{Ref: ref},
+					MachineInfrastructure: cpMachineInfrastructure,
+					MachineHealthCheck:    cpMHC,
+				},
+				Workers: WorkersClass{
+					MachineDeployments: []MachineDeploymentClass{
+						{
+							Class: "aa",
+							Template: MachineDeploymentClassTemplate{
+								Bootstrap:      LocalObjectTemplate{Ref: ref},
+								Infrastructure: LocalObjectTemplate{Ref: ref},
+							},
+							MachineHealthCheck: mdMHC,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		in        *ClusterClass
+		expectErr bool
+	}{
+		{
+			name: "pass with valid MachineHealthChecks for control plane and MachineDeployment",
+			in: clusterClass(
+				&LocalObjectTemplate{Ref: ref},
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions},
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions},
+			),
+			expectErr: false,
+		},
+		{
+			name: "fail if the control plane MachineHealthCheck is set without machineInfrastructure",
+			in: clusterClass(
+				nil,
+				&MachineHealthCheckClass{UnhealthyConditions: unhealthyConditions},
+				nil,
+			),
+			expectErr: true,
+		},
+		{
+			name: "fail if the control plane MachineHealthCheck has no unhealthyConditions",
+			in: clusterClass(
+				&LocalObjectTemplate{Ref: ref},
+				&MachineHealthCheckClass{},
+				nil,
+			),
+			expectErr: true,
+		},
+		{
+			name: "fail if the MachineDeployment MachineHealthCheck has a nodeStartupTimeout lower than 30s",
+			in: clusterClass(
+				nil,
+				nil,
+				&MachineHealthCheckClass{
+					UnhealthyConditions: unhealthyConditions,
+					NodeStartupTimeout:  &metav1.Duration{Duration: 10 * time.Second},
+				},
+			),
+			expectErr: true,
+		},
+		{
+			name: "fail if the MachineDeployment MachineHealthCheck has an invalid maxUnhealthy",
+			in: clusterClass(
+				nil,
+				nil,
+				&MachineHealthCheckClass{
+					UnhealthyConditions: unhealthyConditions,
+					MaxUnhealthy:        &invalidMaxUnhealthy,
+				},
+			),
+			expectErr: true,
+		},
+		{
+			name: "fail if the MachineDeployment MachineHealthCheck has a remediationTemplate in another namespace",
+			in: clusterClass(
+				nil,
+				nil,
+				&MachineHealthCheckClass{
+					UnhealthyConditions: unhealthyConditions,
+					RemediationTemplate: &corev1.ObjectReference{
+						APIVersion: "group.test.io/foo",
+						Kind:       "RemediationTemplate",
+						Name:       "baz",
+						Namespace:  "another-namespace",
+					},
+				},
+			),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			if tt.expectErr {
+				g.Expect(tt.in.validate(nil)).NotTo(Succeed())
+			} else {
+				g.Expect(tt.in.validate(nil)).To(Succeed())
+			}
+		})
+	}
+}
@@ -33,6 +33,15 @@ const (
 	// to track the name of the MachineDeployment topology it represents.
 	ClusterTopologyMachineDeploymentLabelName = "topology.cluster.x-k8s.io/deployment-name"
 
+	// ClusterTopologyMachinePoolLabelName is the label set on the generated MachinePool objects
+	// to track the name of the MachinePool topology it represents.
+	ClusterTopologyMachinePoolLabelName = "topology.cluster.x-k8s.io/pool-name"
+
+	// ClusterTopologyClassAnnotation is the annotation set on the generated ControlPlane and MachineDeployment objects
+	// to track the name of the ClusterClass they have been computed from; it is used to roll out
+	// a ClusterClass rebase in a controlled way.
+	ClusterTopologyClassAnnotation = "topology.cluster.x-k8s.io/class"
+
 	// ProviderLabelName is the label set on components in the provider manifest.
 	// This label allows to easily identify all the components belonging to a provider; the clusterctl
 	// tool uses this label for implementing provider's lifecycle operations.
@@ -86,12 +95,34 @@ const (
 	// MachineSkipRemediationAnnotation is the annotation used to mark the machines that should not be considered for remediation by MachineHealthCheck reconciler.
 	MachineSkipRemediationAnnotation = "cluster.x-k8s.io/skip-remediation"
 
+	// RebootRequestedAnnotation is the annotation set on Machines by the MachineHealthCheck reconciler to request
+	// infrastructure providers to reboot or power-cycle the underlying instance.
+	// The value is the time the reboot has been requested at, in RFC3339 format; a new value is a new reboot request.
+	RebootRequestedAnnotation = "cluster.x-k8s.io/reboot-requested"
+
 	// ClusterSecretType defines the type of secret created by core components.
 	ClusterSecretType corev1.SecretType = "cluster.x-k8s.io/secret" //nolint:gosec
 
 	// InterruptibleLabel is the label used to mark the nodes that run on interruptible instances.
 	InterruptibleLabel = "cluster.x-k8s.io/interruptible"
 
+	// NodeRoleLabelPrefix is one of the Node label prefixes propagated in place from Machines to Nodes.
+	NodeRoleLabelPrefix = "node-role.kubernetes.io"
+
+	// NodeRestrictionLabelDomain is one of the Node label domains propagated in place from Machines to Nodes.
+	NodeRestrictionLabelDomain = "node-restriction.kubernetes.io"
+
+	// ManagedNodeLabelDomain is one of the Node label domains propagated in place from Machines to Nodes.
+	ManagedNodeLabelDomain = "node.cluster.x-k8s.io"
+
+	// LabelsFromMachineAnnotation is the annotation set on Nodes to track the labels propagated from the Machine,
+	// so that labels removed from the Machine can be removed from the Node too.
+	LabelsFromMachineAnnotation = "cluster.x-k8s.io/labels-from-machine"
+
+	// LabelsFromMachineSetAnnotation is the annotation set on Machines to track the labels propagated from the
+	// MachineSet's machine template, so that labels removed from the machine template can be removed from the Machine too.
+	LabelsFromMachineSetAnnotation = "cluster.x-k8s.io/labels-from-machineset"
+
 	// ManagedByAnnotation is an annotation that can be applied to InfraCluster resources to signify that
 	// some external system is managing the cluster infrastructure.
 	//
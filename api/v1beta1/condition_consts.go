@@ -91,6 +91,25 @@ const (
 	WaitingForControlPlaneAvailableReason = "WaitingForControlPlaneAvailable"
 )
 
+// Conditions and condition Reasons for Clusters with a managed Topology.
+
+const (
+	// TopologyReconciledCondition provides evidence about the reconciliation of a Cluster topology into
+	// the managed objects of the Cluster.
+	// Status false means that for any reason, the values defined in Cluster.spec.topology are not yet applied to
+	// managed objects on the Cluster; status true means that Cluster.spec.topology have been applied to
+	// the objects in the Cluster (but this does not imply those objects are already reconciled to the spec provided).
+	TopologyReconciledCondition ConditionType = "TopologyReconciled"
+
+	// TopologyReconcileFailedReason (Severity=Error) documents the reconciliation of a Cluster topology
+	// failing due to an error.
+	TopologyReconcileFailedReason = "TopologyReconcileFailed"
+
+	// TopologyReconciledHookBlockingReason (Severity=Info) documents the reconciliation of a Cluster topology
+	// not yet completed because at least one of the lifecycle hooks is blocking.
+	TopologyReconciledHookBlockingReason = "LifecycleHookBlocking"
+)
+
 // Conditions and condition Reasons for the Machine object
 
 const (
@@ -144,6 +163,16 @@ const (
 
 	// UnhealthyNodeConditionReason is the reason used when a machine's node has one of the MachineHealthCheck's unhealthy conditions.
 	UnhealthyNodeConditionReason = "UnhealthyNode"
+
+	// UnhealthyMachineConditionReason is the reason used when a machine has one of the MachineHealthCheck's unhealthy machine conditions.
+	UnhealthyMachineConditionReason = "UnhealthyMachineCondition"
+
+	// UnhealthyNodeTaintReason is the reason used when a machine's node has one of the MachineHealthCheck's unhealthy node taints.
+	UnhealthyNodeTaintReason = "UnhealthyNodeTaint"
+
+	// DrainedNodeNotReadyReason is the reason used when a machine's node has been drained and is not ready for longer
+	// than the MachineHealthCheck's drainedNodeNotReadyTimeout.
+	DrainedNodeNotReadyReason = "DrainedNodeNotReady"
 )
 
 const (
@@ -160,6 +189,19 @@ const (
 	// RemediationInProgressReason is the reason used when an unhealthy machine is being remediated by the remediation owner.
 	RemediationInProgressReason = "RemediationInProgress"
 
+	// MachineRebootRemediatedCondition is set on machines that have failed a healthcheck by the MachineHealthCheck controller
+	// when using the RebootFirst remediation strategy; it reports the progress of the remediation escalation.
+	// MachineRebootRemediatedCondition is set to False when a reboot is requested, stays False if the remediation is escalated
+	// and is set to True when the machine becomes healthy again.
+	MachineRebootRemediatedCondition ConditionType = "RebootRemediated"
+
+	// RebootRequestedReason is the reason used when the reboot of an unhealthy machine has been requested.
+	RebootRequestedReason = "RebootRequested"
+
+	// RebootTimedOutReason is the reason used when an unhealthy machine is still unhealthy after a reboot
+	// and remediation is escalated.
+	RebootTimedOutReason = "RebootTimedOut"
+
 	// ExternalRemediationTemplateAvailable is set on machinehealthchecks when MachineHealthCheck controller uses external remediation.
 	// ExternalRemediationTemplateAvailable is set to false if external remediation template is not found.
 	ExternalRemediationTemplateAvailable ConditionType = "ExternalRemediationTemplateAvailable"
@@ -206,6 +248,13 @@ const (
 	// TooManyUnhealthyReason is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
 	// from making any further remediations.
 	TooManyUnhealthyReason = "TooManyUnhealthy"
+
+	// RemediationRateLimitedReason is the reason used when the MachineHealthCheck already remediated the maximum number
+	// of Machines allowed within the remediation window and is blocked from making any further remediations.
+	RemediationRateLimitedReason = "RemediationRateLimited"
+
+	// MaintenanceWindowActiveReason is the reason used when remediation is suspended because of a maintenance window.
+	MaintenanceWindowActiveReason = "MaintenanceWindowActive"
 )
 
 // Conditions and condition Reasons for  MachineDeployments
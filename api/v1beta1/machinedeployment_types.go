@@ -103,6 +103,14 @@ type MachineDeploymentSpec struct {
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// SpreadFailureDomains, if true, spreads the Machines of the MachineDeployment across the failure domains
+	// defined in Cluster.Status.FailureDomains, instead of creating all of them in the failure domain
+	// set in the Machine template. The value is propagated to the MachineSets, including the old MachineSets
+	// of a rollout, so scaling down removes Machines from the failure domain with the most Machines of the MachineDeployment.
+	// The Machine template must not set a failure domain when enabled.
+	// +optional
+	SpreadFailureDomains bool `json:"spreadFailureDomains,omitempty"`
+
 	// The maximum time in seconds for a deployment to make progress before it
 	// is considered to be failed. The deployment controller will continue to
 	// process failed deployments and a condition with a ProgressDeadlineExceeded
@@ -169,9 +177,9 @@ type MachineRollingUpdateDeployment struct {
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 
 	// DeletePolicy defines the policy used by the MachineDeployment to identify nodes to delete when downscaling.
-	// Valid values are "Random, "Newest", "Oldest"
+	// Valid values are "Random, "Newest", "Oldest", "FailureDomainBalanced", "LeastPods"
 	// When no value is supplied, the default DeletePolicy of MachineSet is used
-	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest;FailureDomainBalanced;LeastPods
 	// +optional
 	DeletePolicy *string `json:"deletePolicy,omitempty"`
 }
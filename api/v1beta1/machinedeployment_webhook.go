@@ -126,6 +126,23 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 		}
 	}
 
+	if m.Spec.SpreadFailureDomains {
+		if m.Spec.Template.Spec.FailureDomain != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "template", "spec", "failureDomain"), *m.Spec.Template.Spec.FailureDomain, "must not be set when spec.spreadFailureDomains is true"),
+			)
+		}
+		if m.Spec.Strategy != nil && m.Spec.Strategy.RollingUpdate != nil && m.Spec.Strategy.RollingUpdate.DeletePolicy != nil &&
+			*m.Spec.Strategy.RollingUpdate.DeletePolicy != string(FailureDomainBalancedMachineSetDeletePolicy) {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "strategy", "rollingUpdate", "deletePolicy"), *m.Spec.Strategy.RollingUpdate.DeletePolicy,
+					fmt.Sprintf("must be %q when spec.spreadFailureDomains is true", FailureDomainBalancedMachineSetDeletePolicy)),
+			)
+		}
+	}
+
 	if m.Spec.Template.Spec.Version != nil {
 		if !version.KubeSemver.MatchString(*m.Spec.Template.Spec.Version) {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "template", "spec", "version"), *m.Spec.Template.Spec.Version, "must be a valid semantic version"))
@@ -175,6 +192,10 @@ func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
 		d.Spec.Template.Labels = make(map[string]string)
 	}
 
+	if d.Spec.Template.Spec.NodeDeletionTimeout == nil {
+		d.Spec.Template.Spec.NodeDeletionTimeout = &metav1.Duration{Duration: defaultNodeDeletionTimeout}
+	}
+
 	// Default RollingUpdate strategy only if strategy type is RollingUpdate.
 	if d.Spec.Strategy.Type == RollingUpdateMachineDeploymentStrategyType {
 		if d.Spec.Strategy.RollingUpdate == nil {
@@ -188,6 +209,9 @@ func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
 			ios0 := intstr.FromInt(0)
 			d.Spec.Strategy.RollingUpdate.MaxUnavailable = &ios0
 		}
+		if d.Spec.SpreadFailureDomains && d.Spec.Strategy.RollingUpdate.DeletePolicy == nil {
+			d.Spec.Strategy.RollingUpdate.DeletePolicy = pointer.StringPtr(string(FailureDomainBalancedMachineSetDeletePolicy))
+		}
 	}
 
 	// If no selector has been provided, add label and selector for the
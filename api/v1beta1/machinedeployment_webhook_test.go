@@ -56,6 +56,7 @@ func TestMachineDeploymentDefault(t *testing.T) {
 	g.Expect(md.Spec.Strategy.RollingUpdate.MaxSurge.IntValue()).To(Equal(1))
 	g.Expect(md.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue()).To(Equal(0))
 	g.Expect(*md.Spec.Template.Spec.Version).To(Equal("v1.19.10"))
+	g.Expect(md.Spec.Template.Spec.NodeDeletionTimeout.Duration).To(Equal(defaultNodeDeletionTimeout))
 }
 
 func TestMachineDeploymentValidation(t *testing.T) {
@@ -308,3 +309,26 @@ func TestMachineDeploymentClusterNameImmutable(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDeploymentSpreadFailureDomains(t *testing.T) {
+	g := NewWithT(t)
+	md := &MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-md",
+		},
+		Spec: MachineDeploymentSpec{
+			SpreadFailureDomains: true,
+		},
+	}
+	md.Default()
+	g.Expect(md.Spec.Strategy.RollingUpdate.DeletePolicy).To(Equal(pointer.String(string(FailureDomainBalancedMachineSetDeletePolicy))))
+	g.Expect(md.ValidateCreate()).To(Succeed())
+
+	withDeletePolicy := md.DeepCopy()
+	withDeletePolicy.Spec.Strategy.RollingUpdate.DeletePolicy = pointer.String(string(NewestMachineSetDeletePolicy))
+	g.Expect(withDeletePolicy.ValidateCreate()).NotTo(Succeed())
+
+	withFailureDomain := md.DeepCopy()
+	withFailureDomain.Spec.Template.Spec.FailureDomain = pointer.String("fd1")
+	g.Expect(withFailureDomain.ValidateCreate()).NotTo(Succeed())
+}
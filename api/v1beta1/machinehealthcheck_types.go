@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -40,6 +42,23 @@ type MachineHealthCheckSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
 
+	// UnhealthyMachineConditions contains a list of Machine conditions, e.g. set by infrastructure providers,
+	// that determine whether a machine is considered unhealthy. The conditions are combined in a logical OR
+	// with UnhealthyConditions.
+	// +optional
+	UnhealthyMachineConditions []UnhealthyMachineCondition `json:"unhealthyMachineConditions,omitempty"`
+
+	// UnhealthyNodeTaints contains a list of Node taints that determine whether a node is considered unhealthy,
+	// e.g. node.kubernetes.io/unreachable. The taints are combined in a logical OR with UnhealthyConditions.
+	// +optional
+	UnhealthyNodeTaints []UnhealthyNodeTaint `json:"unhealthyNodeTaints,omitempty"`
+
+	// DrainedNodeNotReadyTimeout is the maximum duration a node which has been drained (i.e. cordoned)
+	// can report a Ready condition other than True before it is considered unhealthy.
+	// If not set, drained nodes are not checked.
+	// +optional
+	DrainedNodeNotReadyTimeout *metav1.Duration `json:"drainedNodeNotReadyTimeout,omitempty"`
+
 	// Any further remediation is only allowed if at most "MaxUnhealthy" machines selected by
 	// "selector" are not healthy.
 	// +optional
@@ -69,10 +88,92 @@ type MachineHealthCheckSpec struct {
 	// a controller that lives outside of Cluster API.
 	// +optional
 	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+
+	// RemediationLimits limits how often Machines are remediated, on top of the
+	// MaxUnhealthy and UnhealthyRange short circuiting.
+	// +optional
+	RemediationLimits *RemediationLimits `json:"remediationLimits,omitempty"`
+
+	// RemediationStrategy defines how unhealthy Machines are remediated.
+	// If not set, unhealthy Machines are remediated by their owner, or via the RemediationTemplate if set.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
 }
 
 // ANCHOR_END: MachineHealthCHeckSpec
 
+// ANCHOR: RemediationLimits
+
+// RemediationLimits defines how often a MachineHealthCheck is allowed to remediate Machines.
+type RemediationLimits struct {
+	// MaxRemediations is the maximum number of Machines which can be remediated within Window.
+	// If not set, the number of remediations is not limited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRemediations *int32 `json:"maxRemediations,omitempty"`
+
+	// Window is the time window used to count the remediations for MaxRemediations.
+	// If not set, this value is defaulted to 1 hour.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// MachineBackoff is the minimum time to wait before remediating the same Machine again.
+	// +optional
+	MachineBackoff *metav1.Duration `json:"machineBackoff,omitempty"`
+
+	// MaintenanceWindows are time windows during which remediation is suspended.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// MaintenanceWindow is a time window during which remediation is suspended.
+type MaintenanceWindow struct {
+	// Start is the beginning of the maintenance window.
+	Start metav1.Time `json:"start"`
+
+	// End is the end of the maintenance window.
+	End metav1.Time `json:"end"`
+}
+
+// IsActive returns true if the given time is within the maintenance window.
+func (w *MaintenanceWindow) IsActive(now time.Time) bool {
+	return !now.Before(w.Start.Time) && now.Before(w.End.Time)
+}
+
+// ANCHOR_END: RemediationLimits
+
+// ANCHOR: RemediationStrategy
+
+// RemediationStrategyType defines the type of remediation strategy for unhealthy Machines.
+type RemediationStrategyType string
+
+const (
+	// DeleteRemediationStrategyType remediates unhealthy Machines by handing them off to their owner,
+	// which usually deletes and replaces them, or to the controller reconciling the RemediationTemplate.
+	DeleteRemediationStrategyType = RemediationStrategyType("Delete")
+
+	// RebootFirstRemediationStrategyType first requests the infrastructure provider to reboot unhealthy Machines,
+	// and escalates to the Delete remediation strategy if the Machines are still unhealthy after RebootTimeout.
+	RebootFirstRemediationStrategyType = RemediationStrategyType("RebootFirst")
+)
+
+// RemediationStrategy defines how a MachineHealthCheck remediates unhealthy Machines.
+type RemediationStrategy struct {
+	// Type of remediation strategy. Allowed values are Delete and RebootFirst.
+	// Default is Delete.
+	// +kubebuilder:validation:Enum=Delete;RebootFirst
+	// +optional
+	Type RemediationStrategyType `json:"type,omitempty"`
+
+	// RebootTimeout is the time to wait for a Machine to become healthy after its reboot has been requested,
+	// before escalating to the Delete remediation strategy.
+	// Only applies to the RebootFirst remediation strategy; if not set, this value is defaulted to 10 minutes.
+	// +optional
+	RebootTimeout *metav1.Duration `json:"rebootTimeout,omitempty"`
+}
+
+// ANCHOR_END: RemediationStrategy
+
 // ANCHOR: UnhealthyCondition
 
 // UnhealthyCondition represents a Node condition type and value with a timeout
@@ -92,6 +193,37 @@ type UnhealthyCondition struct {
 
 // ANCHOR_END: UnhealthyCondition
 
+// UnhealthyMachineCondition represents a Machine condition type and value with a timeout
+// specified as a duration. When the named condition has been in the given
+// status for at least the timeout value, a machine is considered unhealthy.
+type UnhealthyMachineCondition struct {
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:MinLength=1
+	Type ConditionType `json:"type"`
+
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:MinLength=1
+	Status corev1.ConditionStatus `json:"status"`
+
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// UnhealthyNodeTaint represents a Node taint with a timeout specified as a duration.
+// When the taint has been on the node for at least the timeout value, a node is considered unhealthy.
+type UnhealthyNodeTaint struct {
+	// Key is the taint key to match.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Effect is the taint effect to match. Empty means to match all taint effects.
+	// +optional
+	Effect corev1.TaintEffect `json:"effect,omitempty"`
+
+	// Timeout is measured from the time the taint has been added to the node; for taints not
+	// reporting it, the last transition time of the node Ready condition is used instead.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
 // ANCHOR: MachineHealthCheckStatus
 
 // MachineHealthCheckStatus defines the observed state of MachineHealthCheck.
@@ -123,6 +255,20 @@ type MachineHealthCheckStatus struct {
 	// Conditions defines current service state of the MachineHealthCheck.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+
+	// Remediations lists the remediations triggered by this machine health check which are
+	// still relevant for enforcing the remediation limits.
+	// +optional
+	Remediations []MachineRemediation `json:"remediations,omitempty"`
+}
+
+// MachineRemediation records a remediation triggered by a MachineHealthCheck.
+type MachineRemediation struct {
+	// MachineName is the name of the remediated Machine.
+	MachineName string `json:"machineName"`
+
+	// Timestamp is the time the remediation has been triggered.
+	Timestamp metav1.Time `json:"timestamp"`
 }
 
 // ANCHOR_END: MachineHealthCheckStatus
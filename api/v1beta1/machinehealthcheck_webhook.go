@@ -39,6 +39,14 @@ var (
 	minNodeStartupTimeout = metav1.Duration{Duration: 30 * time.Second}
 	// We allow users to disable the nodeStartupTimeout by setting the duration to 0.
 	disabledNodeStartupTimeout = ZeroDuration
+
+	// DefaultRemediationWindow is the time window used to count remediations when
+	// remediationLimits.maxRemediations is set.
+	DefaultRemediationWindow = metav1.Duration{Duration: 1 * time.Hour}
+
+	// DefaultRebootTimeout is the time to wait for a Machine to become healthy after its reboot
+	// has been requested by the RebootFirst remediation strategy.
+	DefaultRebootTimeout = metav1.Duration{Duration: 10 * time.Minute}
 )
 
 // SetMinNodeStartupTimeout allows users to optionally set a custom timeout
@@ -81,6 +89,19 @@ func (m *MachineHealthCheck) Default() {
 	if m.Spec.RemediationTemplate != nil && len(m.Spec.RemediationTemplate.Namespace) == 0 {
 		m.Spec.RemediationTemplate.Namespace = m.Namespace
 	}
+
+	if m.Spec.RemediationLimits != nil && m.Spec.RemediationLimits.MaxRemediations != nil && m.Spec.RemediationLimits.Window == nil {
+		m.Spec.RemediationLimits.Window = &DefaultRemediationWindow
+	}
+
+	if m.Spec.RemediationStrategy != nil {
+		if m.Spec.RemediationStrategy.Type == "" {
+			m.Spec.RemediationStrategy.Type = DeleteRemediationStrategyType
+		}
+		if m.Spec.RemediationStrategy.Type == RebootFirstRemediationStrategyType && m.Spec.RemediationStrategy.RebootTimeout == nil {
+			m.Spec.RemediationStrategy.RebootTimeout = &DefaultRebootTimeout
+		}
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
@@ -135,12 +156,25 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		)
 	}
 
+	allErrs = append(allErrs, m.validateCommonFields(field.NewPath("spec"))...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("MachineHealthCheck").GroupKind(), m.Name, allErrs)
+}
+
+// validateCommonFields validates the fields of a MachineHealthCheck spec which are not
+// tied to the selected Machines; it is also used for validating MachineHealthCheckClasses.
+func (m *MachineHealthCheck) validateCommonFields(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
 	if m.Spec.NodeStartupTimeout != nil &&
 		m.Spec.NodeStartupTimeout.Seconds() != disabledNodeStartupTimeout.Seconds() &&
 		m.Spec.NodeStartupTimeout.Seconds() < minNodeStartupTimeout.Seconds() {
 		allErrs = append(
 			allErrs,
-			field.Invalid(field.NewPath("spec", "nodeStartupTimeout"), m.Spec.NodeStartupTimeout.Seconds(), "must be at least 30s"),
+			field.Invalid(fldPath.Child("nodeStartupTimeout"), m.Spec.NodeStartupTimeout.Seconds(), "must be at least 30s"),
 		)
 	}
 
@@ -148,24 +182,63 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		if _, err := intstr.GetScaledValueFromIntOrPercent(m.Spec.MaxUnhealthy, 0, false); err != nil {
 			allErrs = append(
 				allErrs,
-				field.Invalid(field.NewPath("spec", "maxUnhealthy"), m.Spec.MaxUnhealthy, fmt.Sprintf("must be either an int or a percentage: %v", err.Error())),
+				field.Invalid(fldPath.Child("maxUnhealthy"), m.Spec.MaxUnhealthy, fmt.Sprintf("must be either an int or a percentage: %v", err.Error())),
 			)
 		}
 	}
 
+	if m.Spec.RemediationLimits != nil {
+		allErrs = append(allErrs, validateRemediationLimits(m.Spec.RemediationLimits, fldPath.Child("remediationLimits"))...)
+	}
+
+	if m.Spec.RemediationStrategy != nil {
+		allErrs = append(allErrs, validateRemediationStrategy(m.Spec.RemediationStrategy, fldPath.Child("remediationStrategy"))...)
+	}
+
 	if m.Spec.RemediationTemplate != nil && m.Spec.RemediationTemplate.Namespace != m.Namespace {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
-				field.NewPath("spec", "remediationTemplate", "namespace"),
+				fldPath.Child("remediationTemplate", "namespace"),
 				m.Spec.RemediationTemplate.Namespace,
 				"must match metadata.namespace",
 			),
 		)
 	}
 
-	if len(allErrs) == 0 {
-		return nil
+	return allErrs
+}
+
+func validateRemediationLimits(limits *RemediationLimits, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if limits.Window != nil && limits.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), limits.Window.Duration.String(), "must be greater than 0"))
 	}
-	return apierrors.NewInvalid(GroupVersion.WithKind("MachineHealthCheck").GroupKind(), m.Name, allErrs)
+
+	if limits.MachineBackoff != nil && limits.MachineBackoff.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("machineBackoff"), limits.MachineBackoff.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	for i, w := range limits.MaintenanceWindows {
+		if !w.Start.Before(&w.End) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maintenanceWindows").Index(i).Child("end"), w.End, "must be after start"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateRemediationStrategy(strategy *RemediationStrategy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if strategy.RebootTimeout != nil {
+		if strategy.Type != RebootFirstRemediationStrategyType {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("rebootTimeout"), fmt.Sprintf("can only be set when type is %s", RebootFirstRemediationStrategyType)))
+		} else if strategy.RebootTimeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rebootTimeout"), strategy.RebootTimeout.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	return allErrs
 }
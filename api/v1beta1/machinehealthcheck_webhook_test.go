@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
 )
 
@@ -266,6 +267,167 @@ func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckRemediationLimits(t *testing.T) {
+	now := metav1.Now()
+	later := metav1.NewTime(now.Add(time.Hour))
+
+	tests := []struct {
+		name      string
+		limits    RemediationLimits
+		expectErr bool
+	}{
+		{
+			name: "when the limits are valid",
+			limits: RemediationLimits{
+				MaxRemediations:    pointer.Int32(2),
+				Window:             &metav1.Duration{Duration: time.Hour},
+				MachineBackoff:     &metav1.Duration{Duration: 10 * time.Minute},
+				MaintenanceWindows: []MaintenanceWindow{{Start: now, End: later}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "when the window is zero",
+			limits: RemediationLimits{
+				MaxRemediations: pointer.Int32(2),
+				Window:          &metav1.Duration{},
+			},
+			expectErr: true,
+		},
+		{
+			name: "when the machine backoff is negative",
+			limits: RemediationLimits{
+				MachineBackoff: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectErr: true,
+		},
+		{
+			name: "when a maintenance window ends before it starts",
+			limits: RemediationLimits{
+				MaintenanceWindows: []MaintenanceWindow{{Start: later, End: now}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			limits := tt.limits
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					RemediationLimits: &limits,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckRemediationLimitsDefault(t *testing.T) {
+	g := NewWithT(t)
+
+	mhc := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			RemediationLimits: &RemediationLimits{
+				MaxRemediations: pointer.Int32(1),
+			},
+		},
+	}
+	mhc.Default()
+
+	g.Expect(mhc.Spec.RemediationLimits.Window).To(Equal(&DefaultRemediationWindow))
+}
+
+func TestMachineHealthCheckRemediationStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  RemediationStrategy
+		expectErr bool
+	}{
+		{
+			name: "when the reboot timeout is set with the RebootFirst strategy",
+			strategy: RemediationStrategy{
+				Type:          RebootFirstRemediationStrategyType,
+				RebootTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			expectErr: false,
+		},
+		{
+			name: "when the reboot timeout is zero",
+			strategy: RemediationStrategy{
+				Type:          RebootFirstRemediationStrategyType,
+				RebootTimeout: &metav1.Duration{},
+			},
+			expectErr: true,
+		},
+		{
+			name: "when the reboot timeout is set with the Delete strategy",
+			strategy: RemediationStrategy{
+				Type:          DeleteRemediationStrategyType,
+				RebootTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			strategy := tt.strategy
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					RemediationStrategy: &strategy,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckRemediationStrategyDefault(t *testing.T) {
+	g := NewWithT(t)
+
+	mhc := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			RemediationStrategy: &RemediationStrategy{},
+		},
+	}
+	mhc.Default()
+	g.Expect(mhc.Spec.RemediationStrategy.Type).To(Equal(DeleteRemediationStrategyType))
+	g.Expect(mhc.Spec.RemediationStrategy.RebootTimeout).To(BeNil())
+
+	mhc.Spec.RemediationStrategy = &RemediationStrategy{Type: RebootFirstRemediationStrategyType}
+	mhc.Default()
+	g.Expect(mhc.Spec.RemediationStrategy.RebootTimeout).To(Equal(&DefaultRebootTimeout))
+}
+
 func TestMachineHealthCheckSelectorValidation(t *testing.T) {
 	g := NewWithT(t)
 	mhc := &MachineHealthCheck{}
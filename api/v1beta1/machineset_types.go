@@ -51,11 +51,21 @@ type MachineSetSpec struct {
 	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
 
 	// DeletePolicy defines the policy used to identify nodes to delete when downscaling.
-	// Defaults to "Random".  Valid values are "Random, "Newest", "Oldest"
-	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	// Defaults to "Random".  Valid values are "Random, "Newest", "Oldest", "FailureDomainBalanced", "LeastPods"
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest;FailureDomainBalanced;LeastPods
 	// +optional
 	DeletePolicy string `json:"deletePolicy,omitempty"`
 
+	// SpreadFailureDomains, if true, spreads the Machines of the MachineSet across the failure domains
+	// defined in Cluster.Status.FailureDomains. If the
+	// MachineSet is owned by a MachineDeployment, the Machines of all its MachineSets are taken into account.
+	// New Machines are created in the failure domain
+	// with the fewest Machines, and Machines are deleted from the failure domain with the most Machines
+	// using the "FailureDomainBalanced" delete policy.
+	// The Machine template must not set a failure domain when enabled.
+	// +optional
+	SpreadFailureDomains bool `json:"spreadFailureDomains,omitempty"`
+
 	// Selector is a label query over machines that should match the replica count.
 	// Label keys and values that must match in order to be controlled by this MachineSet.
 	// It must match the machine template's labels.
@@ -110,6 +120,21 @@ const (
 	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
 	// It then prioritizes the oldest Machines for deletion based on the Machine's CreationTimestamp.
 	OldestMachineSetDeletePolicy MachineSetDeletePolicy = "Oldest"
+
+	// FailureDomainBalancedMachineSetDeletePolicy prioritizes both Machines that have the annotation
+	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// It then picks Machines from the failure domain with the most Machines, so the remaining
+	// Machines are spread as evenly as possible across Spec.FailureDomain. When SpreadFailureDomains
+	// is set, the Machines of all the MachineSets of the owning MachineDeployment are counted.
+	FailureDomainBalancedMachineSetDeletePolicy MachineSetDeletePolicy = "FailureDomainBalanced"
+
+	// LeastPodsMachineSetDeletePolicy prioritizes both Machines that have the annotation
+	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// It then prioritizes the Machines whose Node runs the fewest Pods in the workload cluster,
+	// not counting Pods managed by a DaemonSet.
+	LeastPodsMachineSetDeletePolicy MachineSetDeletePolicy = "LeastPods"
 )
 
 // ANCHOR: MachineSetStatus
@@ -49,6 +49,9 @@ func (m *MachineSet) Default() {
 
 	if m.Spec.DeletePolicy == "" {
 		randomPolicy := string(RandomMachineSetDeletePolicy)
+		if m.Spec.SpreadFailureDomains {
+			randomPolicy = string(FailureDomainBalancedMachineSetDeletePolicy)
+		}
 		m.Spec.DeletePolicy = randomPolicy
 	}
 
@@ -60,6 +63,10 @@ func (m *MachineSet) Default() {
 		m.Spec.Template.Labels = make(map[string]string)
 	}
 
+	if m.Spec.Template.Spec.NodeDeletionTimeout == nil {
+		m.Spec.Template.Spec.NodeDeletionTimeout = &metav1.Duration{Duration: defaultNodeDeletionTimeout}
+	}
+
 	if len(m.Spec.Selector.MatchLabels) == 0 && len(m.Spec.Selector.MatchExpressions) == 0 {
 		m.Spec.Selector.MatchLabels[MachineSetLabelName] = m.Name
 		m.Spec.Template.Labels[MachineSetLabelName] = m.Name
@@ -111,6 +118,22 @@ func (m *MachineSet) validate(old *MachineSet) error {
 		)
 	}
 
+	if m.Spec.SpreadFailureDomains {
+		if m.Spec.Template.Spec.FailureDomain != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "template", "spec", "failureDomain"), *m.Spec.Template.Spec.FailureDomain, "must not be set when spec.spreadFailureDomains is true"),
+			)
+		}
+		if m.Spec.DeletePolicy != "" && m.Spec.DeletePolicy != string(FailureDomainBalancedMachineSetDeletePolicy) {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "deletePolicy"), m.Spec.DeletePolicy,
+					fmt.Sprintf("must be %q when spec.spreadFailureDomains is true", FailureDomainBalancedMachineSetDeletePolicy)),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
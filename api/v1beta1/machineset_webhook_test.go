@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
 )
 
@@ -39,6 +40,7 @@ func TestMachineSetDefault(t *testing.T) {
 	g.Expect(ms.Spec.DeletePolicy).To(Equal(string(RandomMachineSetDeletePolicy)))
 	g.Expect(ms.Spec.Selector.MatchLabels).To(HaveKeyWithValue(MachineSetLabelName, "test-ms"))
 	g.Expect(ms.Spec.Template.Labels).To(HaveKeyWithValue(MachineSetLabelName, "test-ms"))
+	g.Expect(ms.Spec.Template.Spec.NodeDeletionTimeout.Duration).To(Equal(defaultNodeDeletionTimeout))
 }
 
 func TestMachineSetLabelSelectorMatchValidation(t *testing.T) {
@@ -151,3 +153,26 @@ func TestMachineSetClusterNameImmutable(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineSetSpreadFailureDomains(t *testing.T) {
+	g := NewWithT(t)
+	ms := &MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-ms",
+		},
+		Spec: MachineSetSpec{
+			SpreadFailureDomains: true,
+		},
+	}
+	ms.Default()
+	g.Expect(ms.Spec.DeletePolicy).To(Equal(string(FailureDomainBalancedMachineSetDeletePolicy)))
+	g.Expect(ms.ValidateCreate()).To(Succeed())
+
+	withDeletePolicy := ms.DeepCopy()
+	withDeletePolicy.Spec.DeletePolicy = string(OldestMachineSetDeletePolicy)
+	g.Expect(withDeletePolicy.ValidateCreate()).NotTo(Succeed())
+
+	withFailureDomain := ms.DeepCopy()
+	withFailureDomain.Spec.Template.Spec.FailureDomain = pointer.String("fd1")
+	g.Expect(withFailureDomain.ValidateCreate()).NotTo(Succeed())
+}
@@ -48,6 +48,12 @@ type CertManagerUpgradePlan cluster.CertManagerUpgradePlan
 // Kubeconfig is a type that specifies inputs related to the actual kubeconfig.
 type Kubeconfig cluster.Kubeconfig
 
+// TopologyPlanOutput defines the changes the topology controller would apply to the Clusters affected by the input of a topology plan.
+type TopologyPlanOutput cluster.TopologyPlanOutput
+
+// TemplateValidationError is an error found when validating an object in a workload cluster template.
+type TemplateValidationError repository.TemplateValidationError
+
 // Processor defines the methods necessary for creating a specific yaml
 // processor.
 type Processor yaml.Processor
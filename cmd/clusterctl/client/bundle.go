@@ -0,0 +1,475 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	// BundleManifestFile is the file describing the content of a bundle.
+	BundleManifestFile = "bundle.yaml"
+
+	// BundleImagesFile is the file listing the images required by a bundle.
+	BundleImagesFile = "images.txt"
+
+	// BundleRepositoryFolder is the folder of a bundle containing the provider repositories, using the local repository layout.
+	BundleRepositoryFolder = "repository"
+
+	// BundleConfigFile is the name of the clusterctl configuration file generated when importing a bundle.
+	BundleConfigFile = "clusterctl.yaml"
+
+	bundleCertManagerName = "cert-manager"
+)
+
+// BundleExportOptions carries the options supported by BundleExport.
+type BundleExportOptions struct {
+	// CoreProvider version (e.g. cluster-api:v0.3.0) to add to the bundle. If unspecified, the
+	// cluster-api core provider's latest release is used.
+	CoreProvider string
+
+	// BootstrapProviders and versions (e.g. kubeadm:v0.3.0) to add to the bundle.
+	// If unspecified, the kubeadm bootstrap provider's latest release is used.
+	BootstrapProviders []string
+
+	// InfrastructureProviders and versions (e.g. aws:v0.5.0) to add to the bundle.
+	InfrastructureProviders []string
+
+	// ControlPlaneProviders and versions (e.g. kubeadm:v0.3.0) to add to the bundle.
+	// If unspecified, the kubeadm control plane provider latest release is used.
+	ControlPlaneProviders []string
+
+	// Flavors of the workload cluster templates to add to the bundle, in addition to the default one,
+	// for each infrastructure provider.
+	Flavors []string
+
+	// Directory where the bundle is written.
+	Directory string
+}
+
+// BundleImportOptions carries the options supported by BundleImport.
+type BundleImportOptions struct {
+	// Directory where the bundle has been exported.
+	Directory string
+
+	// RepositoryDirectory where the provider repositories of the bundle are imported.
+	RepositoryDirectory string
+
+	// ImageRepository where the images of the bundle are mirrored, e.g. registry.example.com/cluster-api.
+	// If unspecified, the images are not overridden.
+	ImageRepository string
+
+	// ConfigFile is the clusterctl configuration file to generate. If unspecified, a clusterctl.yaml
+	// file in RepositoryDirectory is used.
+	ConfigFile string
+}
+
+// BundleImage is an image required by a bundle.
+type BundleImage struct {
+	// Source is the image as referenced by the provider components.
+	Source string
+
+	// Target is the image as referenced by the provider components after applying the image overrides,
+	// i.e. where the source image should be mirrored.
+	Target string
+}
+
+// bundleManifest describes the content of a bundle.
+type bundleManifest struct {
+	Providers   []bundleProvider `json:"providers"`
+	CertManager bundleProvider   `json:"certManager"`
+}
+
+// bundleProvider describes a provider in a bundle.
+type bundleProvider struct {
+	Name    string                    `json:"name"`
+	Type    clusterctlv1.ProviderType `json:"type,omitempty"`
+	Version string                    `json:"version"`
+	// ComponentsPath is the path of the components YAML, relative to the repository folder of the bundle.
+	ComponentsPath    string   `json:"componentsPath"`
+	Images            []string `json:"images,omitempty"`
+	TemplateProcessor string   `json:"templateProcessor,omitempty"`
+}
+
+// imageComponent returns the name of the component used for the image overrides of the provider.
+func (p bundleProvider) imageComponent() string {
+	if p.Type == "" {
+		return config.CertManagerImageComponent
+	}
+	return clusterctlv1.ManifestLabel(p.Name, p.Type)
+}
+
+// bundleConfig is the clusterctl configuration generated when importing a bundle.
+type bundleConfig struct {
+	Providers   []bundleConfigProvider           `json:"providers"`
+	CertManager bundleConfigCertManager          `json:"cert-manager"`
+	Images      map[string]bundleConfigImageMeta `json:"images,omitempty"`
+}
+
+type bundleConfigProvider struct {
+	Name              string                    `json:"name"`
+	URL               string                    `json:"url"`
+	Type              clusterctlv1.ProviderType `json:"type"`
+	TemplateProcessor string                    `json:"templateProcessor,omitempty"`
+}
+
+type bundleConfigCertManager struct {
+	URL     string `json:"url"`
+	Version string `json:"version"`
+}
+
+type bundleConfigImageMeta struct {
+	Repository string `json:"repository"`
+}
+
+// BundleExport exports the components, metadata and templates of the requested providers, the cert-manager manifest
+// and the list of the images they require into a bundle.
+func (c *clusterctlClient) BundleExport(options BundleExportOptions) error {
+	log := logf.Log
+
+	if options.Directory == "" {
+		return errors.New("the bundle directory must be specified")
+	}
+
+	// The bundle includes the default providers, which are installed when initializing a management cluster
+	// (if not explicitly opted-out by the user).
+	if options.CoreProvider == "" {
+		options.CoreProvider = config.ClusterAPIProviderName
+	}
+	if len(options.BootstrapProviders) == 0 {
+		options.BootstrapProviders = append(options.BootstrapProviders, config.KubeadmBootstrapProviderName)
+	}
+	if len(options.ControlPlaneProviders) == 0 {
+		options.ControlPlaneProviders = append(options.ControlPlaneProviders, config.KubeadmControlPlaneProviderName)
+	}
+
+	manifest := &bundleManifest{}
+	providers := []struct {
+		providerType clusterctlv1.ProviderType
+		names        []string
+	}{
+		{clusterctlv1.CoreProviderType, []string{options.CoreProvider}},
+		{clusterctlv1.BootstrapProviderType, options.BootstrapProviders},
+		{clusterctlv1.ControlPlaneProviderType, options.ControlPlaneProviders},
+		{clusterctlv1.InfrastructureProviderType, options.InfrastructureProviders},
+	}
+	for _, p := range providers {
+		for _, provider := range p.names {
+			// It is possible to opt-out from automatic installation of bootstrap/control-plane providers using '-' as a provider name (NoopProvider).
+			if provider == NoopProvider {
+				if p.providerType == clusterctlv1.CoreProviderType {
+					return errors.New("the '-' value can not be used for the core provider")
+				}
+				continue
+			}
+
+			bundledProvider, err := c.exportProvider(options, provider, p.providerType)
+			if err != nil {
+				return errors.Wrapf(err, "failed to export the %q provider", provider)
+			}
+			log.Info("Exported provider", "name", bundledProvider.Name, "type", bundledProvider.Type, "version", bundledProvider.Version)
+			manifest.Providers = append(manifest.Providers, *bundledProvider)
+		}
+	}
+
+	certManager, err := c.exportCertManager(options)
+	if err != nil {
+		return errors.Wrap(err, "failed to export cert-manager")
+	}
+	log.Info("Exported cert-manager", "version", certManager.Version)
+	manifest.CertManager = *certManager
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the bundle manifest")
+	}
+	if err := writeBundleFile(filepath.Join(options.Directory, BundleManifestFile), data); err != nil {
+		return err
+	}
+
+	images := sets.NewString(manifest.CertManager.Images...)
+	for _, p := range manifest.Providers {
+		images.Insert(p.Images...)
+	}
+	return writeBundleFile(filepath.Join(options.Directory, BundleImagesFile), []byte(strings.Join(images.List(), "\n")+"\n"))
+}
+
+// exportProvider exports the components, metadata and templates of a provider into the repository folder of the bundle.
+func (c *clusterctlClient) exportProvider(options BundleExportOptions, provider string, providerType clusterctlv1.ProviderType) (*bundleProvider, error) {
+	name, version, err := parseProviderName(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, providerType)
+	if err != nil {
+		return nil, err
+	}
+
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Gets the components, resolving the version if not specified.
+	components, err := repositoryClient.Components().Get(repository.ComponentsOptions{Version: version, SkipTemplateProcess: true})
+	if err != nil {
+		return nil, err
+	}
+	version = components.Version()
+
+	rawComponents, err := repositoryClient.Components().Raw(repository.ComponentsOptions{Version: version})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := repositoryClient.Metadata(version).Get()
+	if err != nil {
+		return nil, err
+	}
+	metadata.APIVersion = clusterctlv1.GroupVersion.String()
+	metadata.Kind = "Metadata"
+	rawMetadata, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal metadata")
+	}
+
+	files := map[string][]byte{
+		path.Base(providerConfig.URL()): rawComponents,
+		"metadata.yaml":                 rawMetadata,
+	}
+
+	// Templates are expected to exist for the infrastructure providers only.
+	if providerType == clusterctlv1.InfrastructureProviderType {
+		if err := addBundleTemplates(files, repositoryClient, version, options.Flavors); err != nil {
+			return nil, err
+		}
+	}
+
+	versionPath := path.Join(providerConfig.ManifestLabel(), version)
+	for fileName, data := range files {
+		if err := writeBundleFile(filepath.Join(options.Directory, BundleRepositoryFolder, versionPath, fileName), data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bundleProvider{
+		Name:              providerConfig.Name(),
+		Type:              providerConfig.Type(),
+		Version:           version,
+		ComponentsPath:    path.Join(versionPath, path.Base(providerConfig.URL())),
+		Images:            components.Images(),
+		TemplateProcessor: providerConfig.TemplateProcessor(),
+	}, nil
+}
+
+// addBundleTemplates adds the default workload cluster template, if any, and the templates for the requested flavors to files.
+func addBundleTemplates(files map[string][]byte, repositoryClient repository.Client, version string, flavors []string) error {
+	if data, err := repositoryClient.GetFile(version, "cluster-template.yaml"); err == nil {
+		files["cluster-template.yaml"] = data
+	}
+
+	for _, flavor := range flavors {
+		fileName := fmt.Sprintf("cluster-template-%s.yaml", flavor)
+		data, err := repositoryClient.GetFile(version, fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the workload cluster template for the %q flavor", flavor)
+		}
+		files[fileName] = data
+	}
+	return nil
+}
+
+// exportCertManager exports the cert-manager manifest into the repository folder of the bundle.
+func (c *clusterctlClient) exportCertManager(options BundleExportOptions) (*bundleProvider, error) {
+	certManagerConfig, err := c.configClient.CertManager().Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// Given that cert manager components yaml are stored in a repository like providers components yaml,
+	// we are using the same machinery to retrieve the file by using a fake provider object using
+	// the cert manager repository url.
+	certManagerFakeProvider := config.NewProvider(bundleCertManagerName, certManagerConfig.URL(), "")
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: certManagerFakeProvider})
+	if err != nil {
+		return nil, err
+	}
+
+	rawComponents, err := repositoryClient.Components().Raw(repository.ComponentsOptions{Version: certManagerConfig.Version()})
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := utilyaml.ToUnstructured(rawComponents)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse yaml for cert-manager manifest")
+	}
+	images, err := util.InspectImages(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	componentsPath := path.Join(certManagerFakeProvider.ManifestLabel(), certManagerConfig.Version(), path.Base(certManagerConfig.URL()))
+	if err := writeBundleFile(filepath.Join(options.Directory, BundleRepositoryFolder, componentsPath), rawComponents); err != nil {
+		return nil, err
+	}
+
+	return &bundleProvider{
+		Name:           bundleCertManagerName,
+		Version:        certManagerConfig.Version(),
+		ComponentsPath: componentsPath,
+		Images:         images,
+	}, nil
+}
+
+// BundleImport imports the provider repositories of a bundle into a local repository and generates a clusterctl
+// configuration file using them, so a management cluster can be initialized without network access.
+// It returns the images required by the bundle, and where they should be mirrored.
+func (c *clusterctlClient) BundleImport(options BundleImportOptions) ([]BundleImage, error) {
+	log := logf.Log
+
+	if options.Directory == "" || options.RepositoryDirectory == "" {
+		return nil, errors.New("the bundle directory and the repository directory must be specified")
+	}
+	repositoryDirectory, err := filepath.Abs(options.RepositoryDirectory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid repository directory %q", options.RepositoryDirectory)
+	}
+	configFile := options.ConfigFile
+	if configFile == "" {
+		configFile = filepath.Join(repositoryDirectory, BundleConfigFile)
+	}
+
+	data, err := os.ReadFile(filepath.Join(options.Directory, BundleManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the bundle manifest")
+	}
+	manifest := &bundleManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the bundle manifest")
+	}
+
+	if err := copyBundleRepository(filepath.Join(options.Directory, BundleRepositoryFolder), repositoryDirectory); err != nil {
+		return nil, err
+	}
+	log.Info("Imported provider repositories", "directory", repositoryDirectory)
+
+	// Generates the clusterctl configuration, pointing to the local repositories and, if required, to the mirrored images.
+	bundleConfig := &bundleConfig{
+		CertManager: bundleConfigCertManager{
+			URL:     filepath.Join(repositoryDirectory, filepath.FromSlash(manifest.CertManager.ComponentsPath)),
+			Version: manifest.CertManager.Version,
+		},
+	}
+	for _, p := range manifest.Providers {
+		bundleConfig.Providers = append(bundleConfig.Providers, bundleConfigProvider{
+			Name:              p.Name,
+			URL:               filepath.Join(repositoryDirectory, filepath.FromSlash(p.ComponentsPath)),
+			Type:              p.Type,
+			TemplateProcessor: p.TemplateProcessor,
+		})
+	}
+
+	reader := config.NewMemoryReader()
+	if options.ImageRepository != "" {
+		bundleConfig.Images = map[string]bundleConfigImageMeta{
+			config.AllImagesComponent: {Repository: options.ImageRepository},
+		}
+		if _, err := reader.AddImageMeta(config.AllImagesComponent, options.ImageRepository, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err = yaml.Marshal(bundleConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the clusterctl configuration")
+	}
+	if err := writeBundleFile(configFile, data); err != nil {
+		return nil, err
+	}
+	log.Info("Generated clusterctl configuration", "file", configFile)
+
+	// Computes where each image should be mirrored, applying the same image overrides used when installing the providers.
+	imageMetaClient, err := config.New("", config.InjectReader(reader))
+	if err != nil {
+		return nil, err
+	}
+	images := []BundleImage{}
+	for _, p := range append(manifest.Providers, manifest.CertManager) {
+		for _, image := range p.Images {
+			target, err := imageMetaClient.ImageMeta().AlterImage(p.imageComponent(), image)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, BundleImage{Source: image, Target: target})
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Source < images[j].Source
+	})
+	return images, nil
+}
+
+// copyBundleRepository copies the repository folder of a bundle into the given directory.
+func copyBundleRepository(source, target string) error {
+	return filepath.Walk(source, func(sourcePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", sourcePath)
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(source, sourcePath)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", sourcePath)
+		}
+		return writeBundleFile(filepath.Join(target, relativePath), data)
+	})
+}
+
+// writeBundleFile writes a file, creating the parent directories if required.
+func writeBundleFile(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+		return errors.Wrapf(err, "failed to create directory %q", filepath.Dir(filePath))
+	}
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %q", filePath)
+	}
+	return nil
+}
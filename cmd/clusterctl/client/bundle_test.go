@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+var certManagerComponentsYAML = []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cert-manager
+  namespace: cert-manager
+spec:
+  template:
+    spec:
+      containers:
+      - name: cert-manager
+        image: quay.io/jetstack/cert-manager-controller:v1.5.3
+`)
+
+func fakeBundleClient() *fakeClient {
+	config1 := fakeConfig(
+		[]config.Provider{capiProviderConfig, bootstrapProviderConfig, controlPlaneProviderConfig, infraProviderConfig},
+		map[string]string{},
+	)
+
+	repositories := fakeRepositories(config1, nil)
+	certManagerRepository := newFakeRepository(config.NewProvider("cert-manager", config.CertManagerDefaultURL, ""), config1).
+		WithPaths("root", "cert-manager.yaml").
+		WithDefaultVersion(config.CertManagerDefaultVersion).
+		WithFile(config.CertManagerDefaultVersion, "cert-manager.yaml", certManagerComponentsYAML)
+	repositories = append(repositories, certManagerRepository)
+
+	return fakeClusterCtlClient(config1, repositories, nil)
+}
+
+func Test_clusterctlClient_BundleExport(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   BundleExportOptions
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name: "Exports the default providers and cert-manager",
+			options: BundleExportOptions{
+				InfrastructureProviders: []string{"infra"},
+			},
+			// NOTE: components are stored using the file name from the provider URL, like in a local repository.
+			wantFiles: []string{
+				"bundle.yaml",
+				"images.txt",
+				"repository/cluster-api/v1.0.0/url",
+				"repository/cluster-api/v1.0.0/metadata.yaml",
+				"repository/bootstrap-kubeadm/v2.0.0/url",
+				"repository/control-plane-kubeadm/v2.0.0/url",
+				"repository/infrastructure-infra/v3.0.0/url",
+				"repository/infrastructure-infra/v3.0.0/metadata.yaml",
+				"repository/infrastructure-infra/v3.0.0/cluster-template.yaml",
+				"repository/cert-manager/v1.5.3/cert-manager.yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Exports the requested provider versions",
+			options: BundleExportOptions{
+				CoreProvider:            "cluster-api:v1.1.0",
+				BootstrapProviders:      []string{"kubeadm:v2.1.0"},
+				ControlPlaneProviders:   []string{NoopProvider},
+				InfrastructureProviders: []string{"infra:v3.1.0"},
+			},
+			wantFiles: []string{
+				"repository/cluster-api/v1.1.0/url",
+				"repository/bootstrap-kubeadm/v2.1.0/url",
+				"repository/infrastructure-infra/v3.1.0/url",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails if the template for a flavor does not exist",
+			options: BundleExportOptions{
+				InfrastructureProviders: []string{"infra"},
+				Flavors:                 []string{"does-not-exist"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails if the provider is not configured",
+			options: BundleExportOptions{
+				InfrastructureProviders: []string{"does-not-exist"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tt.options.Directory = t.TempDir()
+
+			err := fakeBundleClient().BundleExport(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, f := range tt.wantFiles {
+				g.Expect(filepath.Join(tt.options.Directory, f)).To(BeAnExistingFile())
+			}
+		})
+	}
+}
+
+func Test_clusterctlClient_BundleImport(t *testing.T) {
+	tests := []struct {
+		name            string
+		imageRepository string
+		wantImages      []BundleImage
+	}{
+		{
+			name:            "Imports a bundle",
+			imageRepository: "",
+			wantImages: []BundleImage{
+				{
+					Source: "k8s.gcr.io/cluster-api-aws/cluster-api-aws-controller:v0.5.3",
+					Target: "k8s.gcr.io/cluster-api-aws/cluster-api-aws-controller:v0.5.3",
+				},
+				{
+					Source: "quay.io/jetstack/cert-manager-controller:v1.5.3",
+					Target: "quay.io/jetstack/cert-manager-controller:v1.5.3",
+				},
+			},
+		},
+		{
+			name:            "Imports a bundle overriding the image repository",
+			imageRepository: "registry.example.com/capi",
+			wantImages: []BundleImage{
+				{
+					Source: "k8s.gcr.io/cluster-api-aws/cluster-api-aws-controller:v0.5.3",
+					Target: "registry.example.com/capi/cluster-api-aws-controller:v0.5.3",
+				},
+				{
+					Source: "quay.io/jetstack/cert-manager-controller:v1.5.3",
+					Target: "registry.example.com/capi/cert-manager-controller:v1.5.3",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			bundleDirectory := t.TempDir()
+			repositoryDirectory := t.TempDir()
+
+			client := fakeBundleClient()
+			g.Expect(client.BundleExport(BundleExportOptions{
+				InfrastructureProviders: []string{"infra"},
+				Directory:               bundleDirectory,
+			})).To(Succeed())
+
+			images, err := client.BundleImport(BundleImportOptions{
+				Directory:           bundleDirectory,
+				RepositoryDirectory: repositoryDirectory,
+				ImageRepository:     tt.imageRepository,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(images).To(Equal(tt.wantImages))
+
+			g.Expect(filepath.Join(repositoryDirectory, "infrastructure-infra", "v3.0.0", "url")).To(BeAnExistingFile())
+
+			// The generated configuration points to the imported repositories and, if required, to the mirrored images.
+			importedConfig, err := config.New(filepath.Join(repositoryDirectory, BundleConfigFile))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			provider, err := importedConfig.Providers().Get("infra", infraProviderConfig.Type())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(provider.URL()).To(Equal(filepath.Join(repositoryDirectory, "infrastructure-infra", "v3.0.0", "url")))
+
+			certManager, err := importedConfig.CertManager().Get()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(certManager.Version()).To(Equal(config.CertManagerDefaultVersion))
+		})
+	}
+}
@@ -75,6 +75,14 @@ type Client interface {
 	// DescribeCluster returns the object tree representing the status of a Cluster API cluster.
 	DescribeCluster(options DescribeClusterOptions) (*tree.ObjectTree, error)
 
+	// BundleExport exports the providers, the cert-manager manifest and the list of the images they require into a bundle,
+	// to be used for initializing a management cluster in an air-gapped environment.
+	BundleExport(options BundleExportOptions) error
+
+	// BundleImport imports a bundle into a local repository, generates the clusterctl configuration for using it,
+	// and returns the images to be mirrored.
+	BundleImport(options BundleImportOptions) ([]BundleImage, error)
+
 	// Interface for alpha features in clusterctl
 	AlphaClient
 }
@@ -89,6 +97,10 @@ type AlphaClient interface {
 	RolloutResume(options RolloutOptions) error
 	// RolloutUndo provides rollout rollback of cluster-api resources
 	RolloutUndo(options RolloutOptions) error
+	// TopologyPlan dry runs the topology reconciler
+	TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error)
+	// ValidateTemplate validates a workload cluster template against the CRDs of the providers, without accessing a management cluster.
+	ValidateTemplate(options ValidateTemplateOptions) ([]TemplateValidationError, error)
 }
 
 // YamlPrinter exposes methods that prints the processed template and
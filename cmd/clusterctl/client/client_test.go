@@ -136,6 +136,14 @@ func (f fakeClient) DescribeCluster(options DescribeClusterOptions) (*tree.Objec
 	return f.internalClient.DescribeCluster(options)
 }
 
+func (f fakeClient) BundleExport(options BundleExportOptions) error {
+	return f.internalClient.BundleExport(options)
+}
+
+func (f fakeClient) BundleImport(options BundleImportOptions) ([]BundleImage, error) {
+	return f.internalClient.BundleImport(options)
+}
+
 func (f fakeClient) RolloutPause(options RolloutOptions) error {
 	return f.internalClient.RolloutPause(options)
 }
@@ -148,6 +156,14 @@ func (f fakeClient) RolloutUndo(options RolloutOptions) error {
 	return f.internalClient.RolloutUndo(options)
 }
 
+func (f fakeClient) TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error) {
+	return f.internalClient.TopologyPlan(options)
+}
+
+func (f fakeClient) ValidateTemplate(options ValidateTemplateOptions) ([]TemplateValidationError, error) {
+	return f.internalClient.ValidateTemplate(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -173,17 +189,28 @@ func newFakeClient(configClient config.Client) *fakeClient {
 	fake.internalClient, _ = newClusterctlClient("fake-config",
 		InjectConfig(fake.configClient),
 		InjectClusterClientFactory(clusterClientFactory),
-		InjectRepositoryFactory(func(input RepositoryClientFactoryInput) (repository.Client, error) {
-			if _, ok := fake.repositories[input.Provider.ManifestLabel()]; !ok {
-				return nil, errors.Errorf("Repository for kubeconfig %q does not exist.", input.Provider.ManifestLabel())
-			}
-			return fake.repositories[input.Provider.ManifestLabel()], nil
-		}),
+		InjectRepositoryFactory(fakeRepositoryClientFactory(fake.repositories)),
 	)
 
 	return fake
 }
 
+// fakeRepositoryClientFactory returns a RepositoryClientFactory for a set of fake repositories,
+// which honors the yaml processor requested by the caller, if any.
+func fakeRepositoryClientFactory(repositories map[string]repository.Client) RepositoryClientFactory {
+	return func(input RepositoryClientFactoryInput) (repository.Client, error) {
+		if _, ok := repositories[input.Provider.ManifestLabel()]; !ok {
+			return nil, errors.Errorf("Repository for kubeconfig %q does not exist.", input.Provider.ManifestLabel())
+		}
+		if r, ok := repositories[input.Provider.ManifestLabel()].(*fakeRepositoryClient); ok && input.Processor != nil {
+			withProcessor := *r
+			withProcessor.processor = input.Processor
+			return &withProcessor, nil
+		}
+		return repositories[input.Provider.ManifestLabel()], nil
+	}
+}
+
 func (f *fakeClient) WithCluster(clusterClient cluster.Client) *fakeClient {
 	input := clusterClient.Kubeconfig()
 	f.clusters[input] = clusterClient
@@ -318,6 +345,10 @@ func (f *fakeClusterClient) WorkloadCluster() cluster.WorkloadCluster {
 	return f.internalclient.WorkloadCluster()
 }
 
+func (f *fakeClusterClient) Topology() cluster.TopologyClient {
+	return f.internalclient.Topology()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...client.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
@@ -387,6 +418,9 @@ func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 
 func (f *fakeConfigClient) WithProvider(provider config.Provider) *fakeConfigClient {
 	f.fakeReader.WithProvider(provider.Name(), provider.Type(), provider.URL())
+	if provider.TemplateProcessor() != "" {
+		f.fakeReader.WithTemplateProcessor(provider.Name(), provider.Type(), provider.TemplateProcessor())
+	}
 	return f
 }
 
@@ -453,6 +487,10 @@ func (f fakeRepositoryClient) Metadata(version string) repository.MetadataClient
 	}
 }
 
+func (f fakeRepositoryClient) GetFile(version, path string) ([]byte, error) {
+	return f.fakeRepository.GetFile(version, path)
+}
+
 func (f *fakeRepositoryClient) WithPaths(rootPath, componentsPath string) *fakeRepositoryClient {
 	f.fakeRepository.WithPaths(rootPath, componentsPath)
 	return f
@@ -89,6 +89,9 @@ type Client interface {
 
 	// WorkloadCluster has methods for fetching kubeconfig of workload cluster from management cluster.
 	WorkloadCluster() WorkloadCluster
+
+	// Topology returns a TopologyClient that supports computing the changes to Clusters with a managed topology.
+	Topology() TopologyClient
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -150,6 +153,10 @@ func (c *clusterClient) WorkloadCluster() WorkloadCluster {
 	return newWorkloadCluster(c.proxy)
 }
 
+func (c *clusterClient) Topology() TopologyClient {
+	return newTopologyClient(c.proxy)
+}
+
 // Option is a configuration option supplied to New.
 type Option func(*clusterClient)
 
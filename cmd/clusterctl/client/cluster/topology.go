@@ -0,0 +1,883 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/controllers/topology"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/feature"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	// generatedNameSuffixLength is the length of the random suffix appended by the topology controller
+	// to the name of the objects it generates, e.g. when rotating templates.
+	generatedNameSuffixLength = 5
+)
+
+var (
+	// topologyScheme is the scheme used by the in-memory client used to compute a topology plan.
+	topologyScheme = runtime.NewScheme()
+
+	// topologyOwnedTypes defines the types of the objects that are always checked for changes
+	// when computing a topology plan, no matter if they are part of the input or not.
+	topologyOwnedTypes = []schema.GroupVersionKind{
+		clusterv1.GroupVersion.WithKind("Cluster"),
+		clusterv1.GroupVersion.WithKind("MachineDeployment"),
+		clusterv1.GroupVersion.WithKind("MachineHealthCheck"),
+		expv1.GroupVersion.WithKind("MachinePool"),
+	}
+)
+
+func init() {
+	_ = clientgoscheme.AddToScheme(topologyScheme)
+	_ = apiextensionsv1.AddToScheme(topologyScheme)
+	_ = clusterv1.AddToScheme(topologyScheme)
+	_ = expv1.AddToScheme(topologyScheme)
+	_ = runtimev1.AddToScheme(topologyScheme)
+}
+
+// TopologyClient has methods to work with ClusterClasses and Clusters with a managed topology.
+type TopologyClient interface {
+	// Plan computes the changes the topology controller would apply to the Clusters affected by the input objects,
+	// without applying them. The computation runs against an in-memory client, so nothing is changed in the
+	// management cluster.
+	Plan(in *TopologyPlanInput) (*TopologyPlanOutput, error)
+}
+
+// topologyClient implements TopologyClient.
+type topologyClient struct {
+	proxy Proxy
+}
+
+// ensure topologyClient implements TopologyClient.
+var _ TopologyClient = &topologyClient{}
+
+// newTopologyClient returns a topologyClient.
+func newTopologyClient(proxy Proxy) TopologyClient {
+	return &topologyClient{
+		proxy: proxy,
+	}
+}
+
+// TopologyPlanInput defines the input for the Plan function.
+type TopologyPlanInput struct {
+	// Objs is the list of objects the plan should be computed for, e.g. a new or modified ClusterClass,
+	// a template referenced by a ClusterClass or a Cluster.
+	Objs []*unstructured.Unstructured
+
+	// TargetClusterName limits the plan to the Cluster with the given name; if empty, the plan is computed
+	// for all the Clusters affected by the input objects.
+	TargetClusterName string
+
+	// TargetNamespace is the namespace to be used for input objects without a namespace.
+	TargetNamespace string
+
+	// FromCluster reads the current ClusterClasses, Clusters and the objects they reference from the management cluster;
+	// if false, the plan is computed only considering the input objects.
+	FromCluster bool
+}
+
+// TopologyPlanOutput defines the output of the Plan function.
+type TopologyPlanOutput struct {
+	// Clusters is the list of plans for the Clusters affected by the input objects.
+	Clusters []*ClusterTopologyPlan
+}
+
+// ClusterTopologyPlan defines the changes the topology controller would apply for a Cluster.
+type ClusterTopologyPlan struct {
+	// Cluster is the Cluster the changes are computed for.
+	Cluster client.ObjectKey
+
+	// Created is the list of objects that would be created.
+	Created []*unstructured.Unstructured
+
+	// Modified is the list of objects that would be modified.
+	Modified []*ModifiedObject
+
+	// Rotated is the list of objects, e.g. templates, that would be replaced by a new object.
+	Rotated []*RotatedObject
+
+	// Deleted is the list of objects that would be deleted.
+	Deleted []*unstructured.Unstructured
+}
+
+// HasChanges returns true if any change is planned for the Cluster.
+func (p *ClusterTopologyPlan) HasChanges() bool {
+	return len(p.Created)+len(p.Modified)+len(p.Rotated)+len(p.Deleted) > 0
+}
+
+// ModifiedObject defines an object that would be modified.
+type ModifiedObject struct {
+	Before *unstructured.Unstructured
+	After  *unstructured.Unstructured
+	// Diff is a human readable diff between Before and After, ignoring status and server side managed fields.
+	Diff string
+}
+
+// RotatedObject defines an object that would be replaced by a new object with a different name.
+type RotatedObject struct {
+	Old *unstructured.Unstructured
+	New *unstructured.Unstructured
+	// Diff is a human readable diff between Old and New, ignoring names, status and server side managed fields.
+	Diff string
+}
+
+func (t *topologyClient) Plan(in *TopologyPlanInput) (*TopologyPlanOutput, error) {
+	log := logf.Log
+
+	if len(in.Objs) == 0 {
+		return nil, errors.New("invalid input: at least one object is required for computing a topology plan")
+	}
+
+	// The plan simulates a management cluster where managed topologies are enabled, so the corresponding
+	// feature gates are enabled for the webhooks and the topology controller.
+	if err := feature.MutableGates.SetFromMap(map[string]bool{
+		string(feature.ClusterTopology): true,
+		string(feature.MachinePool):     true,
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to enable the feature gates required for computing a topology plan")
+	}
+
+	targetNamespace := in.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = metav1.NamespaceDefault
+		if in.FromCluster {
+			currentNamespace, err := t.proxy.CurrentNamespace()
+			if err != nil {
+				return nil, err
+			}
+			targetNamespace = currentNamespace
+		}
+	}
+
+	inputObjs := make([]*unstructured.Unstructured, 0, len(in.Objs))
+	for _, o := range in.Objs {
+		obj := o.DeepCopy()
+		if obj.GetNamespace() == "" && !isClusterScoped(obj.GroupVersionKind()) {
+			obj.SetNamespace(targetNamespace)
+		}
+		inputObjs = append(inputObjs, obj)
+	}
+
+	// Read the current state from the management cluster, if required.
+	var currentObjs []*unstructured.Unstructured
+	if in.FromCluster {
+		log.V(3).Info("Reading ClusterClasses and Clusters from the management cluster")
+		var err error
+		currentObjs, err = t.getCurrentObjects(namespacesFor(inputObjs))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Default and validate ClusterClasses and Clusters in input, the same way the API server would do when applying them.
+	if err := defaultAndValidateObjs(inputObjs, currentObjs); err != nil {
+		return nil, err
+	}
+
+	// Merge current and input objects, with the input objects taking precedence, and add the CRDs required by the topology controller.
+	objs := mergeObjs(currentObjs, inputObjs)
+	objs = append(objs, missingCRDs(objs)...)
+
+	affectedClusters, err := affectedClusters(objs, inputObjs, in.TargetClusterName, targetNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	trackingClient := newObjectTrackingClient(objs)
+	r := &topology.ClusterReconciler{
+		Client:                    trackingClient,
+		APIReader:                 trackingClient,
+		UnstructuredCachingClient: trackingClient,
+	}
+	r.SetupForDryRun()
+
+	// NOTE: logs from the topology controller are only relevant for debugging the plan.
+	reconcileCtx := ctrl.LoggerInto(ctx, log.V(5))
+
+	out := &TopologyPlanOutput{}
+	for _, cluster := range affectedClusters {
+		log.V(3).Info("Computing topology plan", "Cluster", cluster.String())
+
+		before, err := trackingClient.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.Reconcile(reconcileCtx, ctrl.Request{NamespacedName: cluster}); err != nil {
+			return nil, errors.Wrapf(err, "failed to compute the topology plan for Cluster %s", cluster)
+		}
+		after, err := trackingClient.snapshot()
+		if err != nil {
+			return nil, err
+		}
+
+		plan := diffSnapshots(before, after)
+		plan.Cluster = cluster
+		out.Clusters = append(out.Clusters, plan)
+	}
+	return out, nil
+}
+
+// getCurrentObjects reads from the management cluster the ClusterClasses, the Clusters, the topology owned objects
+// in the given namespaces, all the objects they reference and the corresponding CRDs.
+func (t *topologyClient) getCurrentObjects(namespaces []string) ([]*unstructured.Unstructured, error) {
+	c, err := t.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []*unstructured.Unstructured{}
+	for _, namespace := range namespaces {
+		for _, gvk := range append([]schema.GroupVersionKind{clusterv1.GroupVersion.WithKind("ClusterClass")}, topologyOwnedTypes...) {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				// MachinePools are an experimental feature, so the corresponding CRD could not be installed.
+				if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to list %s objects in namespace %q", gvk.Kind, namespace)
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	}
+
+	// Read all the objects referenced from the objects read so far, including transitive references, e.g.
+	// the InfrastructureMachineTemplate referenced by a ControlPlane referenced by a Cluster.
+	seen := map[string]bool{}
+	for _, o := range objs {
+		seen[objKey(o)] = true
+	}
+	for i := 0; i < len(objs); i++ {
+		for _, ref := range referencesFor(objs[i]) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(ref.APIVersion)
+			obj.SetKind(ref.Kind)
+			obj.SetNamespace(ref.Namespace)
+			obj.SetName(ref.Name)
+			if seen[objKey(obj)] {
+				continue
+			}
+			seen[objKey(obj)] = true
+
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to get %s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	// Read the CRDs for the referenced objects, so the topology controller can determine
+	// the latest apiVersion of the current contract.
+	for _, gk := range externalGroupKinds(objs) {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		if err := c.Get(ctx, client.ObjectKey{Name: crdName(gk)}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get the CustomResourceDefinition for %s", gk)
+		}
+		objs = append(objs, crd)
+	}
+
+	return objs, nil
+}
+
+// defaultAndValidateObjs runs defaulting and validation for ClusterClasses and Clusters in input;
+// if a current version of the object exists, the update validation is used.
+func defaultAndValidateObjs(inputObjs, currentObjs []*unstructured.Unstructured) error {
+	current := map[string]*unstructured.Unstructured{}
+	for _, o := range currentObjs {
+		current[objKey(o)] = o
+	}
+
+	for _, o := range inputObjs {
+		var obj, oldObj interface {
+			client.Object
+			Default()
+			ValidateCreate() error
+			ValidateUpdate(runtime.Object) error
+		}
+		switch o.GroupVersionKind() {
+		case clusterv1.GroupVersion.WithKind("ClusterClass"):
+			obj, oldObj = &clusterv1.ClusterClass{}, &clusterv1.ClusterClass{}
+		case clusterv1.GroupVersion.WithKind("Cluster"):
+			obj, oldObj = &clusterv1.Cluster{}, &clusterv1.Cluster{}
+		default:
+			continue
+		}
+
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, obj); err != nil {
+			return errors.Wrapf(err, "failed to convert %s %s", o.GetKind(), klogKey(o))
+		}
+		obj.Default()
+
+		if c, ok := current[objKey(o)]; ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(c.Object, oldObj); err != nil {
+				return errors.Wrapf(err, "failed to convert %s %s", c.GetKind(), klogKey(c))
+			}
+			if err := obj.ValidateUpdate(oldObj); err != nil {
+				return errors.Wrapf(err, "failed to validate %s %s", o.GetKind(), klogKey(o))
+			}
+		} else if err := obj.ValidateCreate(); err != nil {
+			return errors.Wrapf(err, "failed to validate %s %s", o.GetKind(), klogKey(o))
+		}
+
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %s %s", o.GetKind(), klogKey(o))
+		}
+		o.Object = u
+	}
+	return nil
+}
+
+// mergeObjs merges the current objects with the input objects; input objects take precedence.
+// NOTE: the resourceVersion of the current object is preserved, so the input object is seen as an update.
+func mergeObjs(currentObjs, inputObjs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	objs := []*unstructured.Unstructured{}
+	index := map[string]int{}
+	for _, o := range currentObjs {
+		index[objKey(o)] = len(objs)
+		objs = append(objs, o.DeepCopy())
+	}
+	for _, o := range inputObjs {
+		obj := o.DeepCopy()
+		if i, ok := index[objKey(o)]; ok {
+			obj.SetResourceVersion(objs[i].GetResourceVersion())
+			objs[i] = obj
+			continue
+		}
+		obj.SetResourceVersion("")
+		index[objKey(o)] = len(objs)
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+// missingCRDs generates a CRD for every external kind without a CRD in objs; generated CRDs are labeled
+// with the Cluster API contract, so the topology controller keeps using the apiVersion of the objects.
+func missingCRDs(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	existing := map[string]bool{}
+	for _, o := range objs {
+		if o.GroupVersionKind().GroupKind() == apiextensionsv1.Kind("CustomResourceDefinition") {
+			existing[o.GetName()] = true
+		}
+	}
+
+	versions := map[schema.GroupKind][]string{}
+	for _, gvk := range externalGVKs(objs) {
+		gk := gvk.GroupKind()
+		if existing[crdName(gk)] {
+			continue
+		}
+		versions[gk] = append(versions[gk], gvk.Version)
+	}
+
+	crds := []*unstructured.Unstructured{}
+	for _, gk := range sortedGroupKinds(versions) {
+		crd := generateCRD(gk, versions[gk])
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+		if err != nil {
+			// NOTE: this should never happen given that the CRD is generated from a well known type.
+			panic(err)
+		}
+		crds = append(crds, &unstructured.Unstructured{Object: u})
+	}
+	return crds
+}
+
+// affectedClusters returns the Clusters with a managed topology affected by the input objects.
+func affectedClusters(objs, inputObjs []*unstructured.Unstructured, targetClusterName, targetNamespace string) ([]client.ObjectKey, error) {
+	clusters := []*clusterv1.Cluster{}
+	classes := []*clusterv1.ClusterClass{}
+	for _, o := range objs {
+		switch o.GroupVersionKind() {
+		case clusterv1.GroupVersion.WithKind("Cluster"):
+			cluster := &clusterv1.Cluster{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, cluster); err != nil {
+				return nil, errors.Wrapf(err, "failed to convert Cluster %s", klogKey(o))
+			}
+			if cluster.Spec.Topology != nil {
+				clusters = append(clusters, cluster)
+			}
+		case clusterv1.GroupVersion.WithKind("ClusterClass"):
+			class := &clusterv1.ClusterClass{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, class); err != nil {
+				return nil, errors.Wrapf(err, "failed to convert ClusterClass %s", klogKey(o))
+			}
+			classes = append(classes, class)
+		}
+	}
+
+	if targetClusterName != "" {
+		for _, cluster := range clusters {
+			if cluster.Name == targetClusterName && cluster.Namespace == targetNamespace {
+				return []client.ObjectKey{client.ObjectKeyFromObject(cluster)}, nil
+			}
+		}
+		return nil, errors.Errorf("failed to find Cluster %s/%s with a managed topology", targetNamespace, targetClusterName)
+	}
+
+	input := map[string]bool{}
+	for _, o := range inputObjs {
+		input[objKey(o)] = true
+	}
+
+	// ClusterClasses are affected when they are part of the input, or when they reference an object in input.
+	affectedClasses := map[client.ObjectKey]bool{}
+	for _, class := range classes {
+		if input[objKey(class)] {
+			affectedClasses[client.ObjectKeyFromObject(class)] = true
+			continue
+		}
+		for _, ref := range classReferences(class) {
+			if input[refKey(ref)] {
+				affectedClasses[client.ObjectKeyFromObject(class)] = true
+				break
+			}
+		}
+	}
+
+	// Clusters are affected when they are part of the input, or when they use an affected ClusterClass.
+	keys := []client.ObjectKey{}
+	for _, cluster := range clusters {
+		classKey := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.Topology.Class}
+		if input[objKey(cluster)] || affectedClasses[classKey] {
+			keys = append(keys, client.ObjectKeyFromObject(cluster))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys, nil
+}
+
+// objectTrackingClient is a client for an in-memory set of objects, which keeps track of the types of
+// objects created by the topology controller, so it is possible to compute the changes for all of them.
+type objectTrackingClient struct {
+	client.Client
+
+	trackedTypes map[schema.GroupVersionKind]bool
+}
+
+func newObjectTrackingClient(objs []*unstructured.Unstructured) *objectTrackingClient {
+	c := &objectTrackingClient{
+		trackedTypes: map[schema.GroupVersionKind]bool{},
+	}
+	for _, gvk := range topologyOwnedTypes {
+		c.trackedTypes[gvk] = true
+	}
+
+	clientObjs := make([]client.Object, 0, len(objs))
+	for _, o := range objs {
+		if o.GroupVersionKind().GroupKind() != apiextensionsv1.Kind("CustomResourceDefinition") {
+			c.trackedTypes[o.GroupVersionKind()] = true
+		}
+		clientObjs = append(clientObjs, o)
+	}
+	c.Client = fake.NewClientBuilder().WithScheme(topologyScheme).WithObjects(clientObjs...).Build()
+	return c
+}
+
+func (c *objectTrackingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	c.trackedTypes[gvk] = true
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// snapshot returns all the objects of the tracked types, indexed by objKey.
+func (c *objectTrackingClient) snapshot() (map[string]*unstructured.Unstructured, error) {
+	objs := map[string]*unstructured.Unstructured{}
+	for gvk := range c.trackedTypes {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list); err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s objects", gvk.Kind)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			obj.SetGroupVersionKind(gvk)
+			objs[objKey(obj)] = obj
+		}
+	}
+	return objs, nil
+}
+
+// diffSnapshots computes the changes between two snapshots.
+func diffSnapshots(before, after map[string]*unstructured.Unstructured) *ClusterTopologyPlan {
+	plan := &ClusterTopologyPlan{}
+
+	created := []*unstructured.Unstructured{}
+	for _, key := range sortedKeys(after) {
+		afterObj := after[key]
+		beforeObj, ok := before[key]
+		if !ok {
+			created = append(created, afterObj)
+			continue
+		}
+		if diff := cmp.Diff(cleanObj(beforeObj).Object, cleanObj(afterObj).Object); diff != "" {
+			plan.Modified = append(plan.Modified, &ModifiedObject{Before: beforeObj, After: afterObj, Diff: diff})
+		}
+	}
+
+	deleted := []*unstructured.Unstructured{}
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			deleted = append(deleted, before[key])
+		}
+	}
+
+	// An object created with the same kind, namespace and name prefix of an object which has been deleted, or which
+	// is not referenced anymore by a modified object, is a rotation, e.g. a template replaced by a new one.
+	// NOTE: the templates of a MachineDeployment are not deleted when rotated, because they are still in use by the
+	// existing MachineSets.
+	candidates := append([]*unstructured.Unstructured{}, deleted...)
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; ok && isDereferenced(before[key], plan.Modified) {
+			candidates = append(candidates, before[key])
+		}
+	}
+	rotatedFrom := map[string]bool{}
+	remaining := []*unstructured.Unstructured{}
+	for _, c := range created {
+		rotated := false
+		for _, o := range candidates {
+			if rotatedFrom[objKey(o)] || !isRotation(o, c) {
+				continue
+			}
+			oldObj, newObj := cleanObj(o), cleanObj(c)
+			oldObj.SetName(c.GetName())
+			plan.Rotated = append(plan.Rotated, &RotatedObject{Old: o, New: c, Diff: cmp.Diff(oldObj.Object, newObj.Object)})
+			rotatedFrom[objKey(o)] = true
+			rotated = true
+			break
+		}
+		if !rotated {
+			remaining = append(remaining, c)
+		}
+	}
+	for _, d := range deleted {
+		if !rotatedFrom[objKey(d)] {
+			plan.Deleted = append(plan.Deleted, d)
+		}
+	}
+	plan.Created = remaining
+
+	return plan
+}
+
+// isDereferenced returns true if obj is referenced by a modified object before the change, but not after.
+func isDereferenced(obj *unstructured.Unstructured, modified []*ModifiedObject) bool {
+	for _, m := range modified {
+		if hasReference(m.Before.Object, obj) && !hasReference(m.After.Object, obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReference returns true if the given content includes a reference to obj.
+func hasReference(content interface{}, obj *unstructured.Unstructured) bool {
+	switch v := content.(type) {
+	case map[string]interface{}:
+		if v["kind"] == obj.GetKind() && v["name"] == obj.GetName() {
+			return true
+		}
+		for _, value := range v {
+			if hasReference(value, obj) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if hasReference(value, obj) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isRotation returns true if newObj replaces oldObj.
+func isRotation(oldObj, newObj *unstructured.Unstructured) bool {
+	if oldObj.GroupVersionKind().GroupKind() != newObj.GroupVersionKind().GroupKind() ||
+		oldObj.GetNamespace() != newObj.GetNamespace() {
+		return false
+	}
+	oldName, newName := oldObj.GetName(), newObj.GetName()
+	if len(oldName) <= generatedNameSuffixLength || len(oldName) != len(newName) {
+		return false
+	}
+	return oldName[:len(oldName)-generatedNameSuffixLength] == newName[:len(newName)-generatedNameSuffixLength]
+}
+
+// cleanObj returns a copy of the object without the fields which are not relevant when comparing objects
+// in a plan, like the status and the fields managed by the API server.
+func cleanObj(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	o := obj.DeepCopy()
+	o.SetResourceVersion("")
+	o.SetManagedFields(nil)
+	o.SetGeneration(0)
+	unstructured.RemoveNestedField(o.Object, "status")
+	return o
+}
+
+// referencesFor returns the references from the given object to other objects which are relevant for a managed topology.
+func referencesFor(obj *unstructured.Unstructured) []*corev1.ObjectReference {
+	if obj.GroupVersionKind() == clusterv1.GroupVersion.WithKind("ClusterClass") {
+		class := &clusterv1.ClusterClass{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, class); err != nil {
+			return nil
+		}
+		return classReferences(class)
+	}
+
+	paths := [][]string{
+		// Cluster.
+		{"spec", "infrastructureRef"},
+		{"spec", "controlPlaneRef"},
+		// ControlPlane.
+		{"spec", "machineTemplate", "infrastructureRef"},
+		// MachineDeployment and MachinePool.
+		{"spec", "template", "spec", "bootstrap", "configRef"},
+		{"spec", "template", "spec", "infrastructureRef"},
+		// MachineHealthCheck.
+		{"spec", "remediationTemplate"},
+	}
+	refs := []*corev1.ObjectReference{}
+	for _, path := range paths {
+		m, ok, err := unstructured.NestedMap(obj.Object, path...)
+		if err != nil || !ok {
+			continue
+		}
+		ref := &corev1.ObjectReference{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, ref); err != nil || ref.Kind == "" || ref.Name == "" {
+			continue
+		}
+		if ref.Namespace == "" {
+			ref.Namespace = obj.GetNamespace()
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// classReferences returns the references from a ClusterClass to templates.
+func classReferences(class *clusterv1.ClusterClass) []*corev1.ObjectReference {
+	refs := []*corev1.ObjectReference{
+		class.Spec.Infrastructure.Ref,
+		class.Spec.ControlPlane.Ref,
+	}
+	if class.Spec.ControlPlane.MachineInfrastructure != nil {
+		refs = append(refs, class.Spec.ControlPlane.MachineInfrastructure.Ref)
+	}
+	if class.Spec.ControlPlane.MachineHealthCheck != nil {
+		refs = append(refs, class.Spec.ControlPlane.MachineHealthCheck.RemediationTemplate)
+	}
+	for _, md := range class.Spec.Workers.MachineDeployments {
+		refs = append(refs, md.Template.Bootstrap.Ref, md.Template.Infrastructure.Ref)
+		if md.MachineHealthCheck != nil {
+			refs = append(refs, md.MachineHealthCheck.RemediationTemplate)
+		}
+	}
+	for _, mp := range class.Spec.Workers.MachinePools {
+		refs = append(refs, mp.Template.Bootstrap.Ref, mp.Template.Infrastructure.Ref)
+	}
+
+	ret := []*corev1.ObjectReference{}
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		r := ref.DeepCopy()
+		if r.Namespace == "" {
+			r.Namespace = class.Namespace
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// externalGVKs returns the GroupVersionKinds not known by the topology scheme of objects in objs
+// and of the objects they reference.
+func externalGVKs(objs []*unstructured.Unstructured) []schema.GroupVersionKind {
+	gvks := map[schema.GroupVersionKind]bool{}
+	for _, o := range objs {
+		gvks[o.GroupVersionKind()] = true
+		for _, ref := range referencesFor(o) {
+			gvks[ref.GroupVersionKind()] = true
+		}
+	}
+
+	ret := []schema.GroupVersionKind{}
+	for gvk := range gvks {
+		if topologyScheme.Recognizes(gvk) {
+			continue
+		}
+		ret = append(ret, gvk)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// externalGroupKinds returns the GroupKinds of the externalGVKs.
+func externalGroupKinds(objs []*unstructured.Unstructured) []schema.GroupKind {
+	gks := map[schema.GroupKind][]string{}
+	for _, gvk := range externalGVKs(objs) {
+		gks[gvk.GroupKind()] = append(gks[gvk.GroupKind()], gvk.Version)
+	}
+	return sortedGroupKinds(gks)
+}
+
+// generateCRD generates a minimal CRD for the given GroupKind, labeled with the Cluster API contract.
+func generateCRD(gk schema.GroupKind, versions []string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: crdName(gk),
+			Labels: map[string]string{
+				clusterv1.GroupVersion.String(): strings.Join(versions, "_"),
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: gk.Group,
+			Scope: apiextensionsv1.NamespaceScoped,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Kind:   gk.Kind,
+				Plural: flect.Pluralize(strings.ToLower(gk.Kind)),
+			},
+		},
+	}
+	for i, version := range versions {
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{
+			Name:    version,
+			Served:  true,
+			Storage: i == len(versions)-1,
+			Schema: &apiextensionsv1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type:                   "object",
+					XPreserveUnknownFields: pointer.BoolPtr(true),
+				},
+			},
+		})
+	}
+	return crd
+}
+
+// isClusterScoped returns true for the cluster scoped kinds that could be used as an input for a topology plan.
+func isClusterScoped(gvk schema.GroupVersionKind) bool {
+	return gvk.GroupKind() == apiextensionsv1.Kind("CustomResourceDefinition") ||
+		gvk.GroupKind() == corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind()
+}
+
+// namespacesFor returns the namespaces of the given objects.
+func namespacesFor(objs []*unstructured.Unstructured) []string {
+	namespaces := map[string]bool{}
+	for _, o := range objs {
+		if o.GetNamespace() != "" {
+			namespaces[o.GetNamespace()] = true
+		}
+	}
+	ret := []string{}
+	for n := range namespaces {
+		ret = append(ret, n)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func crdName(gk schema.GroupKind) string {
+	return fmt.Sprintf("%s.%s", flect.Pluralize(strings.ToLower(gk.Kind)), gk.Group)
+}
+
+func objKey(obj client.Object) string {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if gk.Empty() {
+		if gvk, err := apiutil.GVKForObject(obj, topologyScheme); err == nil {
+			gk = gvk.GroupKind()
+		}
+	}
+	return fmt.Sprintf("%s, %s/%s", gk, obj.GetNamespace(), obj.GetName())
+}
+
+func refKey(ref *corev1.ObjectReference) string {
+	return fmt.Sprintf("%s, %s/%s", ref.GroupVersionKind().GroupKind(), ref.Namespace, ref.Name)
+}
+
+func klogKey(obj client.Object) string {
+	return client.ObjectKeyFromObject(obj).String()
+}
+
+func sortedKeys(m map[string]*unstructured.Unstructured) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedGroupKinds(m map[schema.GroupKind][]string) []schema.GroupKind {
+	gks := make([]schema.GroupKind, 0, len(m))
+	for gk := range m {
+		gks = append(gks, gk)
+	}
+	sort.Slice(gks, func(i, j int) bool {
+		return gks[i].String() < gks[j].String()
+	})
+	return gks
+}
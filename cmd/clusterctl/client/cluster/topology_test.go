@@ -0,0 +1,326 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_topologyClient_Plan(t *testing.T) {
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra-cluster-template").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.region": "eu"}).
+		Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "control-plane-template").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.rolloutStrategy": "RollingUpdate"}).
+		Build()
+	workerInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "worker-infra-machine-template").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.flavor": "small"}).
+		Build()
+	// NOTE: the InfrastructureMachineTemplate builder sets the InfrastructureMachine kind; ClusterClass validation requires a template kind.
+	workerInfrastructureMachineTemplate.SetKind(builder.GenericInfrastructureMachineTemplateKind)
+	workerBootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "worker-bootstrap-template").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.format": "cloud-config"}).
+		Build()
+	// NOTE: the fake proxy scheme has typed versions of the generic providers, which drop the fields they do not define;
+	// using a different API group preserves all the fields of the objects read from the management cluster.
+	for _, o := range []*unstructured.Unstructured{infrastructureClusterTemplate, controlPlaneTemplate, workerInfrastructureMachineTemplate, workerBootstrapTemplate} {
+		o.SetAPIVersion(strings.Replace(o.GetAPIVersion(), ".cluster.x-k8s.io/", ".plan.test/", 1))
+	}
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{
+			*builder.MachineDeploymentClass(metav1.NamespaceDefault, "linux-worker").
+				WithClass("linux-worker").
+				WithInfrastructureTemplate(workerInfrastructureMachineTemplate).
+				WithBootstrapTemplate(workerBootstrapTemplate).
+				Build(),
+		}).
+		Build()
+	cluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Class:   "class1",
+				Version: "v1.21.2",
+				Workers: &clusterv1.WorkersTopology{
+					MachineDeployments: []clusterv1.MachineDeploymentTopology{
+						{Class: "linux-worker", Name: "md1"},
+					},
+				},
+			},
+		},
+	}
+
+	inputObjs := []*unstructured.Unstructured{
+		toUnstructured(t, clusterClass),
+		infrastructureClusterTemplate,
+		controlPlaneTemplate,
+		workerInfrastructureMachineTemplate,
+		workerBootstrapTemplate,
+		toUnstructured(t, cluster),
+	}
+
+	t.Run("Plans the creation of the topology for a new Cluster without a management cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		out, err := newTopologyClient(test.NewFakeProxy()).Plan(&TopologyPlanInput{Objs: inputObjs})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(out.Clusters).To(HaveLen(1))
+
+		plan := out.Clusters[0]
+		g.Expect(plan.Cluster).To(Equal(client.ObjectKeyFromObject(cluster)))
+		g.Expect(kinds(plan.Created)).To(ConsistOf(
+			builder.GenericInfrastructureClusterKind,
+			builder.GenericControlPlaneKind,
+			"MachineDeployment",
+			builder.GenericInfrastructureMachineTemplateKind,
+			builder.GenericBootstrapConfigTemplateKind,
+		))
+		g.Expect(plan.Modified).To(HaveLen(1))
+		g.Expect(plan.Modified[0].After.GetKind()).To(Equal("Cluster"))
+		g.Expect(plan.Modified[0].Diff).To(ContainSubstring("infrastructureRef"))
+		g.Expect(plan.Rotated).To(BeEmpty())
+		g.Expect(plan.Deleted).To(BeEmpty())
+	})
+
+	t.Run("Plans the rotation of a modified template for an existing Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// Compute the objects of an existing Cluster, and use them as the current state in the management cluster.
+		out, err := newTopologyClient(test.NewFakeProxy()).Plan(&TopologyPlanInput{Objs: inputObjs})
+		g.Expect(err).ToNot(HaveOccurred())
+		currentObjs := []client.Object{}
+		for _, o := range inputObjs[:len(inputObjs)-1] {
+			currentObjs = append(currentObjs, o)
+		}
+		for _, o := range out.Clusters[0].Created {
+			currentObjs = append(currentObjs, o)
+		}
+		currentObjs = append(currentObjs, out.Clusters[0].Modified[0].After)
+
+		modifiedTemplate := workerInfrastructureMachineTemplate.DeepCopy()
+		g.Expect(unstructured.SetNestedField(modifiedTemplate.Object, "large", "spec", "template", "spec", "flavor")).To(Succeed())
+
+		proxy := test.NewFakeProxy().WithObjs(currentObjs...)
+		out, err = newTopologyClient(proxy).Plan(&TopologyPlanInput{
+			Objs:            []*unstructured.Unstructured{modifiedTemplate},
+			TargetNamespace: metav1.NamespaceDefault,
+			FromCluster:     true,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(out.Clusters).To(HaveLen(1))
+
+		plan := out.Clusters[0]
+		g.Expect(plan.Created).To(BeEmpty())
+		g.Expect(plan.Deleted).To(BeEmpty())
+		g.Expect(plan.Rotated).To(HaveLen(1))
+		g.Expect(plan.Rotated[0].New.GetKind()).To(Equal(builder.GenericInfrastructureMachineTemplateKind))
+		g.Expect(plan.Rotated[0].Diff).To(ContainSubstring("large"))
+		g.Expect(kinds(modifiedObjs(plan.Modified))).To(ContainElement("MachineDeployment"))
+	})
+
+	t.Run("Returns no Clusters if the input does not affect any Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		out, err := newTopologyClient(test.NewFakeProxy()).Plan(&TopologyPlanInput{
+			Objs: []*unstructured.Unstructured{builder.BootstrapTemplate(metav1.NamespaceDefault, "unused").Build()},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(out.Clusters).To(BeEmpty())
+	})
+
+	t.Run("Fails if the target Cluster does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := newTopologyClient(test.NewFakeProxy()).Plan(&TopologyPlanInput{
+			Objs:              inputObjs,
+			TargetClusterName: "does-not-exist",
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Fails if the input is invalid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		invalidCluster := cluster.DeepCopy()
+		invalidCluster.Spec.Topology.Version = "invalid"
+
+		_, err := newTopologyClient(test.NewFakeProxy()).Plan(&TopologyPlanInput{
+			Objs: []*unstructured.Unstructured{toUnstructured(t, invalidCluster)},
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func Test_diffSnapshots(t *testing.T) {
+	obj := func(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		u.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+		u.SetKind(kind)
+		u.SetNamespace(metav1.NamespaceDefault)
+		u.SetName(name)
+		return u
+	}
+	snapshot := func(objs ...*unstructured.Unstructured) map[string]*unstructured.Unstructured {
+		m := map[string]*unstructured.Unstructured{}
+		for _, o := range objs {
+			m[objKey(o)] = o
+		}
+		return m
+	}
+
+	tests := []struct {
+		name         string
+		before       map[string]*unstructured.Unstructured
+		after        map[string]*unstructured.Unstructured
+		wantCreated  []string
+		wantModified []string
+		wantRotated  []string
+		wantDeleted  []string
+	}{
+		{
+			name:   "No changes",
+			before: snapshot(obj("Foo", "foo", map[string]interface{}{"a": "b"})),
+			after:  snapshot(obj("Foo", "foo", map[string]interface{}{"a": "b"})),
+		},
+		{
+			name:        "Created and deleted objects",
+			before:      snapshot(obj("Foo", "foo", nil)),
+			after:       snapshot(obj("Bar", "bar", nil)),
+			wantCreated: []string{"bar"},
+			wantDeleted: []string{"foo"},
+		},
+		{
+			name:         "Modified object",
+			before:       snapshot(obj("Foo", "foo", map[string]interface{}{"a": "b"})),
+			after:        snapshot(obj("Foo", "foo", map[string]interface{}{"a": "c"})),
+			wantModified: []string{"foo"},
+		},
+		{
+			name: "Changes to the status are ignored",
+			before: snapshot(func() *unstructured.Unstructured {
+				o := obj("Foo", "foo", nil)
+				o.Object["status"] = map[string]interface{}{"ready": false}
+				return o
+			}()),
+			after: snapshot(func() *unstructured.Unstructured {
+				o := obj("Foo", "foo", nil)
+				o.Object["status"] = map[string]interface{}{"ready": true}
+				return o
+			}()),
+		},
+		{
+			name:        "Rotated object",
+			before:      snapshot(obj("FooTemplate", "cluster1-md-0-abcde", map[string]interface{}{"a": "b"})),
+			after:       snapshot(obj("FooTemplate", "cluster1-md-0-fghij", map[string]interface{}{"a": "c"})),
+			wantRotated: []string{"cluster1-md-0-fghij"},
+		},
+		{
+			name: "Rotated object still existing but not referenced anymore",
+			before: snapshot(
+				obj("FooTemplate", "cluster1-md-0-abcde", map[string]interface{}{"a": "b"}),
+				obj("Foo", "foo", map[string]interface{}{"ref": map[string]interface{}{"kind": "FooTemplate", "name": "cluster1-md-0-abcde"}}),
+			),
+			after: snapshot(
+				obj("FooTemplate", "cluster1-md-0-abcde", map[string]interface{}{"a": "b"}),
+				obj("FooTemplate", "cluster1-md-0-fghij", map[string]interface{}{"a": "c"}),
+				obj("Foo", "foo", map[string]interface{}{"ref": map[string]interface{}{"kind": "FooTemplate", "name": "cluster1-md-0-fghij"}}),
+			),
+			wantModified: []string{"foo"},
+			wantRotated:  []string{"cluster1-md-0-fghij"},
+		},
+		{
+			name:        "Objects of a different kind are not a rotation",
+			before:      snapshot(obj("FooTemplate", "cluster1-md-0-abcde", nil)),
+			after:       snapshot(obj("BarTemplate", "cluster1-md-0-fghij", nil)),
+			wantCreated: []string{"cluster1-md-0-fghij"},
+			wantDeleted: []string{"cluster1-md-0-abcde"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			plan := diffSnapshots(tt.before, tt.after)
+
+			g.Expect(names(plan.Created)).To(ConsistOf(tt.wantCreated))
+			g.Expect(names(modifiedObjs(plan.Modified))).To(ConsistOf(tt.wantModified))
+			g.Expect(names(rotatedObjs(plan.Rotated))).To(ConsistOf(tt.wantRotated))
+			g.Expect(names(plan.Deleted)).To(ConsistOf(tt.wantDeleted))
+		})
+	}
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	t.Helper()
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &unstructured.Unstructured{Object: u}
+}
+
+func kinds(objs []*unstructured.Unstructured) []string {
+	ret := []string{}
+	for _, o := range objs {
+		ret = append(ret, o.GetKind())
+	}
+	return ret
+}
+
+func names(objs []*unstructured.Unstructured) []string {
+	ret := []string{}
+	for _, o := range objs {
+		ret = append(ret, o.GetName())
+	}
+	return ret
+}
+
+func modifiedObjs(objs []*ModifiedObject) []*unstructured.Unstructured {
+	ret := []*unstructured.Unstructured{}
+	for _, o := range objs {
+		ret = append(ret, o.After)
+	}
+	return ret
+}
+
+func rotatedObjs(objs []*RotatedObject) []*unstructured.Unstructured {
+	ret := []*unstructured.Unstructured{}
+	for _, o := range objs {
+		ret = append(ret, o.New)
+	}
+	return ret
+}
@@ -322,6 +322,14 @@ func (c *clusterctlClient) getTemplateFromRepository(cluster cluster.Client, opt
 		return nil, err
 	}
 
+	// If a yaml processor is not explicitly requested, use the one configured for the provider, if any.
+	if processor == nil && providerConfig.TemplateProcessor() != "" {
+		processor, err = yaml.NewProcessor(providerConfig.TemplateProcessor())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template processor for the %q provider", name)
+		}
+	}
+
 	repo, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig, Processor: processor})
 	if err != nil {
 		return nil, err
@@ -28,8 +28,10 @@ const (
 	// CertManagerImageComponent define the name of the cert-manager component in image overrides.
 	CertManagerImageComponent = "cert-manager"
 
+	// AllImagesComponent define the name of the image overrides applying to all the components.
+	AllImagesComponent = "all"
+
 	imagesConfigKey = "images"
-	allImageConfig  = "all"
 )
 
 // ImageMetaClient has methods to work with image meta configurations.
@@ -98,7 +100,7 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	//	- the selected component/image
 	//	and returns the union of all the above.
 	m := &imageMeta{}
-	if allMeta, ok := meta[allImageConfig]; ok {
+	if allMeta, ok := meta[AllImagesComponent]; ok {
 		m.Union(&allMeta)
 	}
 
@@ -133,7 +133,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		{
 			name: "image config for all: images for the cert-manager should be changed",
 			fields: fields{
-				reader: test.NewFakeReader().WithImageMeta(allImageConfig, "foo-repository.io", "foo-tag"),
+				reader: test.NewFakeReader().WithImageMeta(AllImagesComponent, "foo-repository.io", "foo-tag"),
 			},
 			args: args{
 				component: CertManagerImageComponent,
@@ -146,7 +146,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 			name: "image config for all and for cert-manager: images for the cert-manager should be changed according to the most specific",
 			fields: fields{
 				reader: test.NewFakeReader().
-					WithImageMeta(allImageConfig, "foo-repository.io", "foo-tag").
+					WithImageMeta(AllImagesComponent, "foo-repository.io", "foo-tag").
 					WithImageMeta(CertManagerImageComponent, "bar-repository.io", "bar-tag"),
 			},
 			args: args{
@@ -160,7 +160,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 			name: "image config for all and for cert-manager: images for the cert-manager should be changed according to the most specific (mixed case)",
 			fields: fields{
 				reader: test.NewFakeReader().
-					WithImageMeta(allImageConfig, "foo-repository.io", "").
+					WithImageMeta(AllImagesComponent, "foo-repository.io", "").
 					WithImageMeta(CertManagerImageComponent, "", "bar-tag"),
 			},
 			args: args{
@@ -176,7 +176,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 				reader: test.NewFakeReader().
 					WithImageMeta(fmt.Sprintf("%s/cert-manager-cainjector", CertManagerImageComponent), "foo-repository.io", "foo-tag").
 					WithImageMeta(CertManagerImageComponent, "bar-repository.io", "bar-tag").
-					WithImageMeta(allImageConfig, "baz-repository.io", "baz-tag"),
+					WithImageMeta(AllImagesComponent, "baz-repository.io", "baz-tag"),
 			},
 			args: args{
 				component: CertManagerImageComponent,
@@ -191,7 +191,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 				reader: test.NewFakeReader().
 					WithImageMeta(fmt.Sprintf("%s/cert-manager-cainjector", CertManagerImageComponent), "foo-repository.io", "").
 					WithImageMeta(CertManagerImageComponent, "", "bar-tag").
-					WithImageMeta(allImageConfig, "baz-repository.io", "baz-tag"),
+					WithImageMeta(AllImagesComponent, "baz-repository.io", "baz-tag"),
 			},
 			args: args{
 				component: CertManagerImageComponent,
@@ -206,7 +206,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 				reader: test.NewFakeReader().
 					WithImageMeta(fmt.Sprintf("%s/cert-manager-cainjector", CertManagerImageComponent), "foo-repository.io", "foo-tag").
 					WithImageMeta(CertManagerImageComponent, "bar-repository.io", "").
-					WithImageMeta(allImageConfig, "baz-repository.io", "baz-tag"),
+					WithImageMeta(AllImagesComponent, "baz-repository.io", "baz-tag"),
 			},
 			args: args{
 				component: CertManagerImageComponent,
@@ -230,7 +230,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		{
 			name: "fails if wrong image name",
 			fields: fields{
-				reader: test.NewFakeReader().WithImageMeta(allImageConfig, "foo-Repository.io", ""),
+				reader: test.NewFakeReader().WithImageMeta(AllImagesComponent, "foo-Repository.io", ""),
 			},
 			args: args{
 				component: "any",
@@ -41,6 +41,8 @@ func Test_providers_List(t *testing.T) {
 
 	defaultsAndZZZ := append(defaults, NewProvider("zzz", "https://zzz/infrastructure-components.yaml", "InfrastructureProvider"))
 
+	defaultsAndZZZWithTemplateProcessor := append(append([]Provider{}, defaults...), NewProviderWithTemplateProcessor("zzz", "https://zzz/infrastructure-components.yaml", "InfrastructureProvider", "go-template"))
+
 	defaultsWithOverride := append([]Provider{}, defaults...)
 	defaultsWithOverride[0] = NewProvider(defaults[0].Name(), "https://zzz/infrastructure-components.yaml", defaults[0].Type())
 
@@ -75,6 +77,21 @@ func Test_providers_List(t *testing.T) {
 			want:    defaultsAndZZZ,
 			wantErr: false,
 		},
+		{
+			name: "Returns user defined provider configurations with a template processor",
+			fields: fields{
+				configGetter: test.NewFakeReader().
+					WithVar(
+						ProvidersConfigKey,
+						"- name: \"zzz\"\n"+
+							"  url: \"https://zzz/infrastructure-components.yaml\"\n"+
+							"  type: \"InfrastructureProvider\"\n"+
+							"  templateProcessor: \"go-template\"\n",
+					),
+			},
+			want:    defaultsAndZZZWithTemplateProcessor,
+			wantErr: false,
+		},
 		{
 			name: "User defined provider configurations override defaults",
 			fields: fields{
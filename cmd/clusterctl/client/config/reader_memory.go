@@ -28,6 +28,7 @@ import (
 type MemoryReader struct {
 	variables map[string]string
 	providers []configProvider
+	images    map[string]imageMeta
 }
 
 var _ Reader = &MemoryReader{}
@@ -37,6 +38,7 @@ func NewMemoryReader() *MemoryReader {
 	return &MemoryReader{
 		variables: map[string]string{},
 		providers: []configProvider{},
+		images:    map[string]imageMeta{},
 	}
 }
 
@@ -49,8 +51,8 @@ func (f *MemoryReader) Init(_ string) error {
 	f.variables["providers"] = string(data)
 
 	// images is not used by the operator, but it is read by the clusterctrl
-	// code, so we need a correct empty "images".
-	data, err = yaml.Marshal(map[string]imageMeta{})
+	// code, so we need a correct "images", empty unless image overrides have been added.
+	data, err = yaml.Marshal(f.images)
 	if err != nil {
 		return err
 	}
@@ -96,3 +98,19 @@ func (f *MemoryReader) AddProvider(name string, ttype clusterctlv1.ProviderType,
 
 	return f, nil
 }
+
+// AddImageMeta adds the image override for the given component (or component/image) to the "images" map entry and returns any errors.
+func (f *MemoryReader) AddImageMeta(component, repository, tag string) (*MemoryReader, error) {
+	f.images[component] = imageMeta{
+		Repository: repository,
+		Tag:        tag,
+	}
+
+	yaml, err := yaml.Marshal(f.images)
+	if err != nil {
+		return f, err
+	}
+	f.variables[imagesConfigKey] = string(yaml)
+
+	return f, nil
+}
@@ -48,6 +48,14 @@ func TestMemoryReader(t *testing.T) {
 				"three": "3",
 			},
 		},
+		{
+			name:      "image overrides",
+			providers: []configProvider{},
+			imageMetas: map[string]imageMeta{
+				AllImagesComponent: {Repository: "registry.example.com/mirror"},
+				CertManagerImageComponent + "/cert-manager-controller": {Tag: "v1.5.3"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -58,6 +66,10 @@ func TestMemoryReader(t *testing.T) {
 				_, err := f.AddProvider(p.Name, p.Type, p.URL)
 				g.Expect(err).ToNot(HaveOccurred())
 			}
+			for c, m := range tt.imageMetas {
+				_, err := f.AddImageMeta(c, m.Repository, m.Tag)
+				g.Expect(err).ToNot(HaveOccurred())
+			}
 			for n, v := range tt.variables {
 				f.Set(n, v)
 			}
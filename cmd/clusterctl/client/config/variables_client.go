@@ -19,6 +19,28 @@ package config
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token.
 	GitHubTokenVariable = "github-token"
+
+	// GitLabAccessTokenVariable defines a variable hosting the GitLab access token.
+	GitLabAccessTokenVariable = "gitlab-access-token"
+
+	// HTTPRepositoryTokenVariable defines a variable hosting the bearer token used to access HTTP(S) provider repositories.
+	HTTPRepositoryTokenVariable = "http-repository-token"
+
+	// OCIUsernameVariable defines a variable hosting the username used to access OCI registries.
+	OCIUsernameVariable = "oci-username"
+
+	// OCIPasswordVariable defines a variable hosting the password or token used to access OCI registries.
+	OCIPasswordVariable = "oci-password"
+
+	// OCIPlainHTTPVariable defines a variable which, if true, makes clusterctl access OCI registries using plain HTTP instead of HTTPS.
+	OCIPlainHTTPVariable = "oci-plain-http"
+
+	// OCIInsecureSkipTLSVerifyVariable defines a variable which, if true, disables the verification of the certificates of OCI registries.
+	OCIInsecureSkipTLSVerifyVariable = "oci-insecure-skip-tls-verify"
+
+	// OCICAFileVariable defines a variable hosting the path of a PEM file with CA certificates to trust when accessing OCI registries,
+	// in addition to the system ones.
+	OCICAFileVariable = "oci-ca-file"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
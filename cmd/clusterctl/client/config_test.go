@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 )
 
@@ -782,12 +783,7 @@ func newFakeClientWithoutCluster(configClient config.Client) *fakeClient {
 	var err error
 	fake.internalClient, err = newClusterctlClient("fake-config",
 		InjectConfig(fake.configClient),
-		InjectRepositoryFactory(func(input RepositoryClientFactoryInput) (repository.Client, error) {
-			if _, ok := fake.repositories[input.Provider.ManifestLabel()]; !ok {
-				return nil, errors.Errorf("Repository for kubeconfig %q does not exist.", input.Provider.ManifestLabel())
-			}
-			return fake.repositories[input.Provider.ManifestLabel()], nil
-		}),
+		InjectRepositoryFactory(fakeRepositoryClientFactory(fake.repositories)),
 	)
 	if err != nil {
 		panic(err)
@@ -884,6 +880,40 @@ func Test_clusterctlClient_GetClusterTemplate_withoutCluster(t *testing.T) {
 	}
 }
 
+func Test_clusterctlClient_GetClusterTemplate_withTemplateProcessor(t *testing.T) {
+	g := NewWithT(t)
+
+	// the infra provider is configured for using the go-template processor
+	providerConfig := config.NewProviderWithTemplateProcessor(infraProviderConfig.Name(), infraProviderConfig.URL(), infraProviderConfig.Type(), yaml.GoTemplateProcessorName)
+
+	config1 := newFakeConfig().
+		WithProvider(providerConfig)
+
+	repository1 := newFakeRepository(providerConfig, config1).
+		WithPaths("root", "components").
+		WithDefaultVersion("v3.0.0").
+		WithFile("v3.0.0", "cluster-template.yaml", templateYAML("ns3", "{{ .CLUSTER_NAME }}"))
+
+	client := newFakeClientWithoutCluster(config1).
+		WithRepository(repository1)
+
+	got, err := client.GetClusterTemplate(GetClusterTemplateOptions{
+		Kubeconfig: Kubeconfig{Path: "", Context: ""},
+		ProviderRepositorySource: &ProviderRepositorySourceOptions{
+			InfrastructureProvider: "infra:v3.0.0",
+		},
+		ClusterName:              "test",
+		TargetNamespace:          "ns1",
+		ControlPlaneMachineCount: pointer.Int64Ptr(1),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Variables()).To(Equal([]string{"CLUSTER_NAME"}))
+
+	gotYaml, err := got.Yaml()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gotYaml).To(Equal(templateYAML("ns1", "test")))
+}
+
 func Test_clusterctlClient_ProcessYAML(t *testing.T) {
 	g := NewWithT(t)
 	template := `v1: ${VAR1:=default1}
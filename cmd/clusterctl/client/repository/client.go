@@ -43,6 +43,9 @@ type Client interface {
 
 	// Metadata provide access to YAML with the provider's metadata.
 	Metadata(version string) MetadataClient
+
+	// GetFile returns a file for a given provider version as it is stored in the repository, e.g. a workload cluster template.
+	GetFile(version, path string) ([]byte, error)
 }
 
 // repositoryClient implements Client.
@@ -72,6 +75,10 @@ func (c *repositoryClient) Metadata(version string) MetadataClient {
 	return newMetadataClient(c.Provider, version, c.repository, c.configClient.Variables())
 }
 
+func (c *repositoryClient) GetFile(version, path string) ([]byte, error) {
+	return c.repository.GetFile(version, path)
+}
+
 // Option is a configuration option supplied to New.
 type Option func(*repositoryClient)
 
@@ -164,6 +171,34 @@ func repositoryFactory(providerConfig config.Provider, configVariablesClient con
 		return repo, err
 	}
 
+	// if the url is a GitLab repository
+	if (rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme) && isGitLabRepositoryURL(rURL) {
+		repo, err := newGitLabRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the GitLab repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is a generic HTTP(S) repository
+	// NB. this is the fallback for all the http(s) urls not matching the GitHub and GitLab urls above, e.g. GitHub Enterprise urls.
+	if rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme {
+		repo, err := newHTTPRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the HTTP repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is an OCI repository
+	if rURL.Scheme == ociScheme {
+		repo, err := newOCIRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the OCI repository client")
+		}
+		return repo, err
+	}
+
 	// if the url is a local filesystem repository
 	if rURL.Scheme == "file" || rURL.Scheme == "" {
 		repo, err := newLocalRepository(providerConfig, configVariablesClient)
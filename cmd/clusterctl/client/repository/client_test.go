@@ -73,6 +73,72 @@ func Test_newRepositoryClient_LocalFileSystemRepository(t *testing.T) {
 	}
 }
 
+func Test_repositoryFactory(t *testing.T) {
+	server := newFakeHTTPRepositoryServer(t, "")
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		want    Repository
+		wantErr bool
+	}{
+		{
+			name: "GitHub release",
+			url:  "https://github.com/org/repo/releases/v1.0.0/infrastructure-components.yaml",
+			want: &gitHubRepository{},
+		},
+		{
+			name: "GitLab package, on any host",
+			url:  "http://gitlab.example.com/api/v4/projects/group%2Fproject/packages/generic/infrastructure-foo/v1.0.0/infrastructure-components.yaml",
+			want: &gitLabRepository{},
+		},
+		{
+			name: "GitLab release, on any host",
+			url:  "https://gitlab.example.com/group/project/-/releases/v1.0.0/downloads/infrastructure-components.yaml",
+			want: &gitLabRepository{},
+		},
+		{
+			name: "HTTP repository",
+			url:  server.URL + "/repo/v0.4.0/components.yaml",
+			want: &httpRepository{},
+		},
+		{
+			name:    "GitHub Enterprise release is not an HTTP repository without an index file",
+			url:     server.URL + "/org/repo/releases/v1.0.0/infrastructure-components.yaml",
+			wantErr: true,
+		},
+		{
+			name: "OCI",
+			url:  "oci://registry.example.com/infrastructure-foo:v1.0.0/infrastructure-components.yaml",
+			want: &ociRepository{},
+		},
+		{
+			name: "Local file",
+			url:  "file:///repository/infrastructure-foo/v1.0.0/infrastructure-components.yaml",
+			want: &localRepository{},
+		},
+		{
+			name:    "Unsupported scheme",
+			url:     "ftp://example.com/infrastructure-foo/v1.0.0/infrastructure-components.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := repositoryFactory(config.NewProvider("foo", tt.url, clusterctlv1.InfrastructureProviderType), test.NewFakeVariableClient())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(BeAssignableToTypeOf(tt.want))
+		})
+	}
+}
+
 func Test_newRepositoryClient_YamlProcessor(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,341 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	gitLabAPIProjectsPath      = "api/v4/projects"
+	gitLabGenericPackagesPath  = "packages/generic"
+	gitLabReleasesSeparator    = "/-/releases/"
+	gitLabReleaseDownloadsPath = "downloads"
+	gitLabTokenHeader          = "Private-Token"
+	gitLabNextPageHeader       = "X-Next-Page"
+
+	// gitLabAPIPageSize is the number of packages or releases requested when listing versions.
+	gitLabAPIPageSize = 100
+)
+
+// gitLabRepository provides support for providers hosted on GitLab, including self-managed instances.
+//
+// We support providers published either in the generic package registry of a project, with the package
+// version used as provider version:
+// http[s]://{host}/api/v4/projects/{project-id or url-encoded path}/packages/generic/{package}/{latest|version}/{components.yaml}
+//
+// or as links of the releases of a project, with the release tag used as provider version:
+// http[s]://{host}/{namespace}/{project}/-/releases/{latest|version-tag}/downloads/{components.yaml}
+//
+// If the gitlab-access-token variable is set, it is used to authenticate with the GitLab API.
+type gitLabRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	token                 string
+	host                  string
+	project               string
+	packageName           string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+}
+
+var _ Repository = &gitLabRepository{}
+
+// gitLabPackage is a package in the GitLab package registry, as returned by the GitLab API.
+type gitLabPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// gitLabRelease is a GitLab release, as returned by the GitLab API.
+type gitLabRelease struct {
+	TagName string `json:"tag_name"`
+}
+
+// isGitLabRepositoryURL returns true if the url is a GitLab package registry or release url.
+func isGitLabRepositoryURL(rURL *url.URL) bool {
+	escapedPath := rURL.EscapedPath()
+	return (strings.HasPrefix(escapedPath, "/"+gitLabAPIProjectsPath+"/") && strings.Contains(escapedPath, "/"+gitLabGenericPackagesPath+"/")) ||
+		strings.Contains(escapedPath, gitLabReleasesSeparator)
+}
+
+// DefaultVersion returns defaultVersion field of gitLabRepository struct.
+func (g *gitLabRepository) DefaultVersion() string {
+	return g.defaultVersion
+}
+
+// RootPath returns rootPath field of gitLabRepository struct.
+func (g *gitLabRepository) RootPath() string {
+	return g.rootPath
+}
+
+// ComponentsPath returns componentsPath field of gitLabRepository struct.
+func (g *gitLabRepository) ComponentsPath() string {
+	return g.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (g *gitLabRepository) GetFile(version, fileName string) ([]byte, error) {
+	var err error
+	if version == latestVersionTag {
+		version, err = latestRelease(g)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	}
+
+	filePath := path.Join(g.rootPath, fileName)
+	var fileURL string
+	if g.packageName != "" {
+		fileURL = fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s", g.host, gitLabAPIProjectsPath, g.project, gitLabGenericPackagesPath, g.packageName, version, filePath)
+	} else {
+		fileURL = fmt.Sprintf("%s/%s%s%s/%s/%s", g.host, g.project, gitLabReleasesSeparator, version, gitLabReleaseDownloadsPath, filePath)
+	}
+
+	content, err := httpGet(fileURL, g.header())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q for version %q from GitLab", fileName, version)
+	}
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+func (g *gitLabRepository) GetVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s/%s", g.host, g.project, g.packageName)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	var candidates []string
+	var err error
+	if g.packageName != "" {
+		candidates, err = g.getPackageVersions()
+	} else {
+		candidates, err = g.getReleaseVersions()
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+
+	versions := []string{}
+	for _, v := range candidates {
+		if _, err := version.ParseSemantic(v); err != nil {
+			// Discard versions that are not a valid semantic versions (the user can point explicitly to such versions).
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// getPackageVersions returns the versions of the package in the generic package registry of the project.
+// NB. the package_name filter of the GitLab API is a fuzzy match, so packages with a different name are discarded.
+func (g *gitLabRepository) getPackageVersions() ([]string, error) {
+	listURL := fmt.Sprintf("%s/%s/%s/packages?package_type=generic&package_name=%s&per_page=%d", g.host, gitLabAPIProjectsPath, g.project, url.QueryEscape(g.packageName), gitLabAPIPageSize)
+	versions := []string{}
+	err := g.listPages(listURL, func(content []byte) error {
+		packages := []gitLabPackage{}
+		if err := json.Unmarshal(content, &packages); err != nil {
+			return errors.Wrap(err, "failed to parse the list of packages")
+		}
+		for _, p := range packages {
+			if p.Name == g.packageName {
+				versions = append(versions, p.Version)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// getReleaseVersions returns the tags of the releases of the project.
+func (g *gitLabRepository) getReleaseVersions() ([]string, error) {
+	listURL := fmt.Sprintf("%s/%s/%s/releases?per_page=%d", g.host, gitLabAPIProjectsPath, url.PathEscape(g.project), gitLabAPIPageSize)
+	versions := []string{}
+	err := g.listPages(listURL, func(content []byte) error {
+		releases := []gitLabRelease{}
+		if err := json.Unmarshal(content, &releases); err != nil {
+			return errors.Wrap(err, "failed to parse the list of releases")
+		}
+		for _, r := range releases {
+			versions = append(versions, r.TagName)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// listPages calls the given function with each page of the results of a GitLab API list request, following
+// the pagination of the GitLab API.
+func (g *gitLabRepository) listPages(listURL string, pageFunc func(content []byte) error) error {
+	for listURL != "" {
+		content, header, err := httpGetWithResponseHeader(listURL, g.header())
+		if err != nil {
+			return err
+		}
+		if err := pageFunc(content); err != nil {
+			return err
+		}
+
+		listURL, err = gitLabNextPageURL(listURL, header)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitLabNextPageURL returns the url of the next page of the results of a GitLab API list request, or an empty
+// string if the response is the last page.
+// The next page is read from the Link header, which is set with both offset and keyset-based pagination,
+// otherwise from the X-Next-Page header, which is set only with offset-based pagination.
+// NOTE: The next page url from the Link header must have the same scheme and host of the list request, given
+// that the GitLab token is sent with the request for the next page.
+func gitLabNextPageURL(listURL string, header http.Header) (string, error) {
+	u, err := url.Parse(listURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse url %q", listURL)
+	}
+
+	next, err := resolveNextLinkURL(listURL, header)
+	if err != nil {
+		return "", err
+	}
+	if next != "" {
+		nextURL, err := url.Parse(next)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse the next page url %q", next)
+		}
+		if nextURL.Scheme != u.Scheme || nextURL.Host != u.Host {
+			return "", errors.Errorf("invalid next page url %q: the scheme and host must match the ones of %q", next, listURL)
+		}
+		return next, nil
+	}
+
+	nextPage := header.Get(gitLabNextPageHeader)
+	if nextPage == "" {
+		return "", nil
+	}
+	query := u.Query()
+	query.Set("page", nextPage)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// header returns the header to be used for requests to GitLab.
+func (g *gitLabRepository) header() http.Header {
+	header := http.Header{}
+	if g.token != "" {
+		header.Set(gitLabTokenHeader, g.token)
+	}
+	return header
+}
+
+// newGitLabRepository returns a gitLabRepository implementation.
+func newGitLabRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient) (*gitLabRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme {
+		return nil, errors.New("invalid url: a GitLab repository url should start with http:// or https://")
+	}
+
+	repo := &gitLabRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		host:                  fmt.Sprintf("%s://%s", rURL.Scheme, rURL.Host),
+	}
+
+	// NB. the escaped path is used, given that the project path in package registry urls is url-encoded.
+	escapedPath := strings.TrimPrefix(rURL.EscapedPath(), "/")
+	var filePath string
+	switch {
+	case strings.HasPrefix(escapedPath, gitLabAPIProjectsPath+"/"):
+		// {project}/packages/generic/{package}/{version}/{components.yaml}
+		urlSplit := strings.Split(strings.TrimPrefix(escapedPath, gitLabAPIProjectsPath+"/"), "/")
+		if len(urlSplit) < 6 || strings.Join(urlSplit[1:3], "/") != gitLabGenericPackagesPath {
+			return nil, errors.Errorf(
+				"invalid url: a GitLab package url should be in the form http[s]://{host}/%s/{project}/%s/{package}/{latest|version}/{components.yaml}",
+				gitLabAPIProjectsPath, gitLabGenericPackagesPath,
+			)
+		}
+		repo.project = urlSplit[0]
+		repo.packageName = urlSplit[3]
+		repo.defaultVersion = urlSplit[4]
+		filePath = strings.Join(urlSplit[5:], "/")
+	case strings.Contains(escapedPath, gitLabReleasesSeparator):
+		// {namespace}/{project}/-/releases/{version}/downloads/{components.yaml}
+		pathSplit := strings.SplitN(escapedPath, gitLabReleasesSeparator, 2)
+		urlSplit := strings.Split(pathSplit[1], "/")
+		if pathSplit[0] == "" || len(urlSplit) < 3 || urlSplit[1] != gitLabReleaseDownloadsPath {
+			return nil, errors.Errorf(
+				"invalid url: a GitLab release url should be in the form http[s]://{host}/{namespace}/{project}%s{latest|version-tag}/%s/{components.yaml}",
+				gitLabReleasesSeparator, gitLabReleaseDownloadsPath,
+			)
+		}
+		repo.project = pathSplit[0]
+		repo.defaultVersion = urlSplit[0]
+		filePath = strings.Join(urlSplit[2:], "/")
+	default:
+		return nil, errors.New("invalid url: a GitLab repository url should point to a package of the generic package registry or to a release")
+	}
+
+	// use path's directory as a rootPath
+	repo.rootPath = filepath.Dir(filePath)
+	// use the file name (if any) as componentsPath
+	repo.componentsPath = getComponentsPath(filePath, repo.rootPath)
+
+	if token, err := configVariablesClient.Get(config.GitLabAccessTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if repo.defaultVersion == latestVersionTag {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+	}
+
+	return repo, nil
+}
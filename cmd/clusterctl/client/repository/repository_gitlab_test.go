@@ -0,0 +1,374 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeGitLabServer returns a server hosting the v0.4.0 and v0.4.1 versions of a provider, both as generic packages
+// and as releases of the group/project project, which requires the given access token if not empty.
+// The lists of packages and releases have two pages, the latest version being on the second one.
+func newFakeGitLabServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		testMethod(t, r, "GET")
+		if token != "" && r.Header.Get("Private-Token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	handle := func(pattern, content string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if authorized(w, r) {
+				fmt.Fprint(w, content)
+			}
+		})
+	}
+	// handlePages serves a list with two pages, linking to the second page using either the Link or the X-Next-Page header.
+	handlePages := func(pattern string, useLink bool, firstPage, secondPage string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(w, r) {
+				return
+			}
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, secondPage)
+				return
+			}
+			if useLink {
+				w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2&per_page=100>; rel="next", <http://%s%s?page=1&per_page=100>; rel="first"`, r.Host, r.URL.Path, r.Host, r.URL.Path))
+			} else {
+				w.Header().Set("X-Next-Page", "2")
+			}
+			fmt.Fprint(w, firstPage)
+		})
+	}
+	// NB. the package_name filter of the GitLab API is a fuzzy match, so other packages are returned as well.
+	handlePages("/api/v4/projects/group%2Fproject/packages", false,
+		`[{"name": "provider", "version": "v0.4.0"}, {"name": "provider-foo", "version": "v0.5.0"}]`,
+		`[{"name": "provider", "version": "v0.4.1"}, {"name": "provider", "version": "foo"}]`,
+	)
+	handlePages("/api/v4/projects/group%2Fproject/releases", true,
+		`[{"tag_name": "v0.4.0"}]`,
+		`[{"tag_name": "v0.4.1"}, {"tag_name": "foo"}]`,
+	)
+	for _, v := range []string{"v0.4.0", "v0.4.1"} {
+		handle(fmt.Sprintf("/api/v4/projects/group%%2Fproject/packages/generic/provider/%s/components.yaml", v), fmt.Sprintf("package components %s", v))
+		handle(fmt.Sprintf("/group/project/-/releases/%s/downloads/components.yaml", v), fmt.Sprintf("release components %s", v))
+	}
+	// GitLab expects url-encoded project paths, so requests are routed without decoding the path.
+	return httptest.NewServer(rawPathHandler(mux))
+}
+
+func Test_gitLabRepository_newGitLabRepository(t *testing.T) {
+	server := newFakeGitLabServer(t, "")
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		variableClient config.VariablesClient
+		want           *gitLabRepository
+		wantErr        bool
+	}{
+		{
+			name:           "can create a new GitLab package repository",
+			url:            server.URL + "/api/v4/projects/group%2Fproject/packages/generic/provider/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"),
+			want: &gitLabRepository{
+				token:          "token",
+				host:           server.URL,
+				project:        "group%2Fproject",
+				packageName:    "provider",
+				defaultVersion: "v0.4.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "can create a new GitLab release repository",
+			url:            server.URL + "/group/project/-/releases/v0.4.0/downloads/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want: &gitLabRepository{
+				host:           server.URL,
+				project:        "group/project",
+				defaultVersion: "v0.4.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "resolves the latest package version",
+			url:            server.URL + "/api/v4/projects/group%2Fproject/packages/generic/provider/latest/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want: &gitLabRepository{
+				host:           server.URL,
+				project:        "group%2Fproject",
+				packageName:    "provider",
+				defaultVersion: "v0.4.1",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "resolves the latest release",
+			url:            server.URL + "/group/project/-/releases/latest/downloads/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want: &gitLabRepository{
+				host:           server.URL,
+				project:        "group/project",
+				defaultVersion: "v0.4.1",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "missing variableClient",
+			url:            server.URL + "/group/project/-/releases/v0.4.0/downloads/components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "package url should be in http[s]://{host}/api/v4/projects/{project}/packages/generic/{package}/{latest|version}/{components.yaml} format",
+			url:            server.URL + "/api/v4/projects/group%2Fproject/packages/generic/provider",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "release url should be in http[s]://{host}/{namespace}/{project}/-/releases/{latest|version-tag}/downloads/{components.yaml} format",
+			url:            server.URL + "/group/project/-/releases/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			got, err := newGitLabRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			tt.want.providerConfig = providerConfig
+			tt.want.configVariablesClient = tt.variableClient
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_gitLabRepository_GetFile(t *testing.T) {
+	server := newFakeGitLabServer(t, "token")
+	defer server.Close()
+
+	packageURL := server.URL + "/api/v4/projects/group%2Fproject/packages/generic/provider/v0.4.0/components.yaml"
+	releaseURL := server.URL + "/group/project/-/releases/v0.4.0/downloads/components.yaml"
+
+	tests := []struct {
+		name     string
+		url      string
+		token    string
+		version  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Package version and file exist",
+			url:      packageURL,
+			token:    "token",
+			version:  "v0.4.0",
+			fileName: "components.yaml",
+			want:     []byte("package components v0.4.0"),
+		},
+		{
+			name:     "Latest package version",
+			url:      packageURL,
+			token:    "token",
+			version:  "latest",
+			fileName: "components.yaml",
+			want:     []byte("package components v0.4.1"),
+		},
+		{
+			name:     "Release and file exist",
+			url:      releaseURL,
+			token:    "token",
+			version:  "v0.4.1",
+			fileName: "components.yaml",
+			want:     []byte("release components v0.4.1"),
+		},
+		{
+			name:     "Release does not exist",
+			url:      releaseURL,
+			token:    "token",
+			version:  "v0.5.0",
+			fileName: "components.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "Missing token",
+			url:      releaseURL,
+			version:  "v0.4.0",
+			fileName: "components.yaml",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			variableClient := test.NewFakeVariableClient()
+			if tt.token != "" {
+				variableClient.WithVar(config.GitLabAccessTokenVariable, tt.token)
+			}
+			repo, err := newGitLabRepository(config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType), variableClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_gitLabRepository_GetVersions(t *testing.T) {
+	server := newFakeGitLabServer(t, "")
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{
+			name: "Package versions",
+			url:  server.URL + "/api/v4/projects/group%2Fproject/packages/generic/provider/v0.4.0/components.yaml",
+			want: []string{"v0.4.0", "v0.4.1"},
+		},
+		{
+			name: "Release versions",
+			url:  server.URL + "/group/project/-/releases/v0.4.0/downloads/components.yaml",
+			want: []string{"v0.4.0", "v0.4.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			repo, err := newGitLabRepository(config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType), test.NewFakeVariableClient())
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetVersions()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(ConsistOf(tt.want))
+		})
+	}
+}
+
+func Test_gitLabNextPageURL(t *testing.T) {
+	listURL := "https://gitlab.example.com/api/v4/projects/group%2Fproject/releases?per_page=100"
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Last page",
+			header: http.Header{},
+			want:   "",
+		},
+		{
+			name:   "Next page from the X-Next-Page header",
+			header: http.Header{"X-Next-Page": []string{"3"}},
+			want:   "https://gitlab.example.com/api/v4/projects/group%2Fproject/releases?page=3&per_page=100",
+		},
+		{
+			name: "Next page from the Link header",
+			header: http.Header{
+				"Link":        []string{`<https://gitlab.example.com/api/v4/projects/1/releases?cursor=abc>; rel="next", <https://gitlab.example.com/api/v4/projects/1/releases>; rel="first"`},
+				"X-Next-Page": []string{"3"},
+			},
+			want: "https://gitlab.example.com/api/v4/projects/1/releases?cursor=abc",
+		},
+		{
+			name:   "Last page with a Link header",
+			header: http.Header{"Link": []string{`<https://gitlab.example.com/api/v4/projects/1/releases>; rel="first"`}},
+			want:   "",
+		},
+		{
+			name:   "Next page from a relative Link header",
+			header: http.Header{"Link": []string{`</api/v4/projects/1/releases?cursor=abc>; rel="next"`}},
+			want:   "https://gitlab.example.com/api/v4/projects/1/releases?cursor=abc",
+		},
+		{
+			name:    "Returns error if the Link header points to a different host",
+			header:  http.Header{"Link": []string{`<https://attacker.example.com/api/v4/projects/1/releases?cursor=abc>; rel="next"`}},
+			wantErr: true,
+		},
+		{
+			name:    "Returns error if the Link header points to a different scheme",
+			header:  http.Header{"Link": []string{`<http://gitlab.example.com/api/v4/projects/1/releases?cursor=abc>; rel="next"`}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := gitLabNextPageURL(listURL, tt.header)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+// rawPathHandler routes requests using the escaped path, so url-encoded project paths are matched as they are sent.
+func rawPathHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = r.URL.EscapedPath()
+		r.URL.RawPath = ""
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	httpScheme = "http"
+
+	// httpRepositoryIndexFile is the file listing the versions hosted in an HTTP repository.
+	httpRepositoryIndexFile = "index.yaml"
+
+	httpRequestTimeout = 30 * time.Second
+)
+
+// httpRepository provides support for providers hosted on a generic HTTP(S) server.
+// The provider URL is expected to point to the components yaml, and the files of each
+// version must adhere to the following layout:
+// http[s]://{host}/{basepath}/{version}/{components.yaml}
+//
+// (1): {version} must obey the syntax and semantics of the "Semantic Versioning"
+// specification (http://semver.org/); however, "latest" is also an acceptable value.
+// (2): the versions available in the repository are listed in {basepath}/index.yaml, e.g.
+//
+//	versions:
+//	- v0.4.0
+//	- v0.4.1
+//
+// If the http-repository-token variable is set, it is sent as a bearer token with every request.
+type httpRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	baseURL               string
+	token                 string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+}
+
+var _ Repository = &httpRepository{}
+
+// httpRepositoryIndex is the content of the index file of an HTTP repository.
+type httpRepositoryIndex struct {
+	Versions []string `json:"versions"`
+}
+
+// DefaultVersion returns defaultVersion field of httpRepository struct.
+func (h *httpRepository) DefaultVersion() string {
+	return h.defaultVersion
+}
+
+// RootPath returns rootPath field of httpRepository struct.
+func (h *httpRepository) RootPath() string {
+	return h.rootPath
+}
+
+// ComponentsPath returns componentsPath field of httpRepository struct.
+func (h *httpRepository) ComponentsPath() string {
+	return h.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (h *httpRepository) GetFile(version, fileName string) ([]byte, error) {
+	var err error
+	if version == latestVersionTag {
+		version, err = latestRelease(h)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	}
+
+	content, err := h.get(path.Join(version, h.rootPath, fileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q for version %q", fileName, version)
+	}
+	return content, nil
+}
+
+// GetVersions returns the list of versions listed in the index file of the repository.
+func (h *httpRepository) GetVersions() ([]string, error) {
+	cacheID := h.baseURL
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	content, err := h.get(httpRepositoryIndexFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+
+	index := &httpRepositoryIndex{}
+	if err := yaml.Unmarshal(content, index); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the %s file of the repository", httpRepositoryIndexFile)
+	}
+	versions := []string{}
+	for _, v := range index.Versions {
+		if _, err := version.ParseSemantic(v); err != nil {
+			// Discard versions that are not a valid semantic versions (the user can point explicitly to such versions).
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// get returns the content of a file in the repository.
+func (h *httpRepository) get(fileName string) ([]byte, error) {
+	header := http.Header{}
+	if h.token != "" {
+		header.Set("Authorization", "Bearer "+h.token)
+	}
+	return httpGet(h.baseURL+"/"+fileName, header)
+}
+
+// newHTTPRepository returns a httpRepository implementation.
+func newHTTPRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient) (*httpRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme {
+		return nil, errors.New("invalid url: a HTTP repository url should start with http:// or https://")
+	}
+
+	// Check if the path is in the expected format; the last two elements of the path are the version and the components yaml.
+	urlSplit := strings.Split(strings.TrimPrefix(rURL.Path, "/"), "/")
+	if len(urlSplit) < 2 || urlSplit[len(urlSplit)-2] == "" || urlSplit[len(urlSplit)-1] == "" {
+		return nil, errors.New("invalid url: a HTTP repository url should be in the form http[s]://{host}/{basepath}/{latest|version}/{components.yaml}")
+	}
+
+	basePath := strings.Join(urlSplit[:len(urlSplit)-2], "/")
+	baseURL := url.URL{Scheme: rURL.Scheme, User: rURL.User, Host: rURL.Host, Path: "/" + basePath}
+
+	repo := &httpRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		baseURL:               strings.TrimSuffix(baseURL.String(), "/"),
+		defaultVersion:        urlSplit[len(urlSplit)-2],
+		rootPath:              ".",
+		componentsPath:        urlSplit[len(urlSplit)-1],
+	}
+
+	if token, err := configVariablesClient.Get(config.HTTPRepositoryTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	// All the http(s) urls not matching a GitHub or GitLab repository url are read as HTTP repositories, so check the
+	// repository lists its versions before using it; this reports urls which are not HTTP repositories, e.g. GitHub
+	// Enterprise urls or typos, when creating the repository instead of when reading the first file.
+	if _, err := repo.GetVersions(); err != nil {
+		return nil, errors.Wrapf(err, "%q is not a GitHub or GitLab repository url, so it is read as an HTTP repository, which must list its versions in %s/%s",
+			providerConfig.URL(), repo.baseURL, httpRepositoryIndexFile)
+	}
+
+	if repo.defaultVersion == latestVersionTag {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// httpGet returns the body of the response to a GET request to the given url.
+// Responses are cached, so each file is downloaded only once.
+func httpGet(rawURL string, header http.Header) ([]byte, error) {
+	if content, ok := cacheFiles[rawURL]; ok {
+		return content, nil
+	}
+
+	content, _, err := httpGetWithResponseHeader(rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheFiles[rawURL] = content
+	return content, nil
+}
+
+// httpGetWithResponseHeader returns the body and the header of the response to a GET request to the given url.
+// Responses are not cached, e.g. because the header is used to follow the pagination of an API.
+func httpGetWithResponseHeader(rawURL string, header http.Header) ([]byte, http.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpRequestTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create request for %q", rawURL)
+	}
+	for key := range header {
+		request.Header.Set(key, header.Get(key))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get %q", rawURL)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %q: %s", rawURL, response.Status)
+	}
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %q", rawURL)
+	}
+	return content, response.Header, nil
+}
+
+// resolveNextLinkURL returns the url with the "next" relation in the Link header, if any, resolved against the
+// url of the request, given that the link might be relative.
+func resolveNextLinkURL(requestURL string, header http.Header) (string, error) {
+	next := nextLinkURL(header)
+	if next == "" {
+		return "", nil
+	}
+	base, err := url.Parse(requestURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse url %q", requestURL)
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the next page url %q", next)
+	}
+	return base.ResolveReference(nextURL).String(), nil
+}
+
+// nextLinkURL returns the url with the "next" relation in the Link header, as defined in RFC 8288, if any.
+func nextLinkURL(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				if strings.ReplaceAll(strings.TrimSpace(param), " ", "") == `rel="next"` {
+					return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+				}
+			}
+		}
+	}
+	return ""
+}
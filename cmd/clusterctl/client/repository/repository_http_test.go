@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeHTTPRepositoryServer returns a server hosting the v0.4.0 and v0.4.1 versions of a provider under /repo,
+// which requires the given bearer token if not empty.
+func newFakeHTTPRepositoryServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		testMethod(t, r, "GET")
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/repo/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			fmt.Fprint(w, "versions:\n- v0.4.0\n- v0.4.1\n- foo\n")
+		}
+	})
+	for _, v := range []string{"v0.4.0", "v0.4.1"} {
+		v := v
+		mux.HandleFunc(fmt.Sprintf("/repo/%s/components.yaml", v), func(w http.ResponseWriter, r *http.Request) {
+			if authorized(w, r) {
+				fmt.Fprintf(w, "components %s", v)
+			}
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+func Test_httpRepository_newHTTPRepository(t *testing.T) {
+	server := newFakeHTTPRepositoryServer(t, "")
+	defer server.Close()
+	tokenServer := newFakeHTTPRepositoryServer(t, "token")
+	defer tokenServer.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		variableClient config.VariablesClient
+		want           *httpRepository
+		wantErr        bool
+	}{
+		{
+			name:           "can create a new HTTP repository",
+			url:            server.URL + "/repo/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryTokenVariable, "token"),
+			want: &httpRepository{
+				baseURL:        server.URL + "/repo",
+				token:          "token",
+				defaultVersion: "v0.4.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "resolves the latest version",
+			url:            server.URL + "/repo/latest/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want: &httpRepository{
+				baseURL:        server.URL + "/repo",
+				defaultVersion: "v0.4.1",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "provider url should point to a repository with an index file",
+			url:            server.URL + "/org/repo/releases/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should be accessible with the configured token",
+			url:            tokenServer.URL + "/repo/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "missing variableClient",
+			url:            server.URL + "/repo/v0.4.0/components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should be in http or https",
+			url:            "ftp://example.com/repo/v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should be in http[s]://{host}/{basepath}/{latest|version}/{components.yaml} format",
+			url:            server.URL + "/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			got, err := newHTTPRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			tt.want.providerConfig = providerConfig
+			tt.want.configVariablesClient = tt.variableClient
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_httpRepository_GetFile(t *testing.T) {
+	server := newFakeHTTPRepositoryServer(t, "token")
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		token    string
+		version  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Version and file exist",
+			token:    "token",
+			version:  "v0.4.0",
+			fileName: "components.yaml",
+			want:     []byte("components v0.4.0"),
+		},
+		{
+			name:     "Latest version",
+			token:    "token",
+			version:  "latest",
+			fileName: "components.yaml",
+			want:     []byte("components v0.4.1"),
+		},
+		{
+			name:     "Version does not exist",
+			token:    "token",
+			version:  "v0.5.0",
+			fileName: "components.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not exist",
+			token:    "token",
+			version:  "v0.4.0",
+			fileName: "404.file",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			variableClient := test.NewFakeVariableClient()
+			if tt.token != "" {
+				variableClient.WithVar(config.HTTPRepositoryTokenVariable, tt.token)
+			}
+			repo, err := newHTTPRepository(config.NewProvider("test", server.URL+"/repo/v0.4.0/components.yaml", clusterctlv1.CoreProviderType), variableClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_httpRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+	resetCaches()
+
+	server := newFakeHTTPRepositoryServer(t, "")
+	defer server.Close()
+
+	repo, err := newHTTPRepository(config.NewProvider("test", server.URL+"/repo/v0.4.0/components.yaml", clusterctlv1.CoreProviderType), test.NewFakeVariableClient())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1"))
+}
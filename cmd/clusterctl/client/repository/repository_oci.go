@@ -0,0 +1,479 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme = "oci"
+
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ociImageTitleAnnotation = "org.opencontainers.image.title"
+)
+
+// ociAuthenticateParamRegex matches the parameters of a WWW-Authenticate header, e.g. realm="https://auth.example.com/token".
+var ociAuthenticateParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociRepository provides support for providers published as OCI artifacts in an OCI registry.
+//
+// Each provider version is an OCI artifact tagged with the version, whose layers are the components YAML, the metadata YAML
+// and eventually the workload cluster templates, each one with the file name stored in the org.opencontainers.image.title
+// annotation (e.g. as pushed by oras). The provider URL is expected to be in the form:
+// oci://{registry}/{repository}:{latest|version}/{components.yaml}
+//
+// If the oci-username and oci-password variables are set, they are used to authenticate with the registry.
+// If the oci-plain-http variable is true, the registry is accessed using plain HTTP; otherwise HTTPS is used, trusting the
+// CA certificates in the oci-ca-file variable in addition to the system ones, or skipping the verification of the registry
+// certificate if the oci-insecure-skip-tls-verify variable is true.
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	scheme                string
+	registry              string
+	repository            string
+	username              string
+	password              string
+	token                 string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+	httpClient            *http.Client
+}
+
+var _ Repository = &ociRepository{}
+
+type ociRepositoryOption func(*ociRepository)
+
+func injectOCIHTTPClient(c *http.Client) ociRepositoryOption {
+	return func(o *ociRepository) {
+		o.httpClient = c
+	}
+}
+
+// ociTagList is the list of tags of a repository, as returned by the registry.
+type ociTagList struct {
+	Tags []string `json:"tags"`
+}
+
+// ociManifest is an OCI image manifest, as returned by the registry.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor is an OCI content descriptor.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociToken is the response of a registry token server.
+type ociToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// DefaultVersion returns defaultVersion field of ociRepository struct.
+func (o *ociRepository) DefaultVersion() string {
+	return o.defaultVersion
+}
+
+// RootPath returns rootPath field of ociRepository struct.
+func (o *ociRepository) RootPath() string {
+	return o.rootPath
+}
+
+// ComponentsPath returns componentsPath field of ociRepository struct.
+func (o *ociRepository) ComponentsPath() string {
+	return o.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (o *ociRepository) GetFile(version, fileName string) ([]byte, error) {
+	var err error
+	if version == latestVersionTag {
+		version, err = latestRelease(o)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	}
+
+	cacheID := fmt.Sprintf("%s/%s:%s:%s", o.registry, o.repository, version, fileName)
+	if content, ok := cacheFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	manifestContent, err := o.get("manifests/"+version, ociManifestMediaType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the OCI artifact %s:%s", o.repository, version)
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(manifestContent, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the manifest of the OCI artifact %s:%s", o.repository, version)
+	}
+
+	// search for the file into the artifact layers, retrieving the layer digest
+	absoluteFileName := path.Join(o.rootPath, fileName)
+	var layer *ociDescriptor
+	for i := range manifest.Layers {
+		if manifest.Layers[i].Annotations[ociImageTitleAnnotation] == absoluteFileName {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return nil, errors.Errorf("failed to get file %q from the OCI artifact %s:%s", fileName, o.repository, version)
+	}
+
+	content, err := o.get("blobs/"+layer.Digest, layer.MediaType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from the OCI artifact %s:%s", fileName, o.repository, version)
+	}
+	if err := verifyOCIDigest(content, layer.Digest); err != nil {
+		return nil, errors.Wrapf(err, "failed to verify file %q from the OCI artifact %s:%s", fileName, o.repository, version)
+	}
+
+	cacheFiles[cacheID] = content
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available in a provider repository, i.e. the tags of the repository
+// which are valid semantic versions.
+func (o *ociRepository) GetVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s", o.registry, o.repository)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	// The registry might paginate the list of tags, linking to the next page using the Link header.
+	versions := []string{}
+	listURL := o.endpointURL("tags/list")
+	for listURL != "" {
+		content, header, err := o.getURL(listURL, "application/json")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get repository versions")
+		}
+		tagList := &ociTagList{}
+		if err := json.Unmarshal(content, tagList); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the list of tags")
+		}
+
+		for _, tag := range tagList.Tags {
+			if _, err := version.ParseSemantic(tag); err != nil {
+				// Discard tags that are not a valid semantic versions (the user can point explicitly to such tags).
+				continue
+			}
+			versions = append(versions, tag)
+		}
+
+		listURL, err = resolveNextLinkURL(listURL, header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// endpointURL returns the url of a registry API endpoint of the repository.
+func (o *ociRepository) endpointURL(endpoint string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s", o.scheme, o.registry, o.repository, endpoint)
+}
+
+// get returns the content of a registry API endpoint of the repository, authenticating with the registry if required.
+func (o *ociRepository) get(endpoint, accept string) ([]byte, error) {
+	content, _, err := o.getURL(o.endpointURL(endpoint), accept)
+	return content, err
+}
+
+// getURL returns the content and the response header of a registry API url, authenticating with the registry if required.
+func (o *ociRepository) getURL(rawURL, accept string) ([]byte, http.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpRequestTimeout)
+	defer cancel()
+
+	response, err := o.do(ctx, rawURL, accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		authenticate := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		if err := o.authenticate(authenticate); err != nil {
+			return nil, nil, err
+		}
+		if response, err = o.do(ctx, rawURL, accept); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %q: %s", rawURL, response.Status)
+	}
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %q", rawURL)
+	}
+	return content, response.Header, nil
+}
+
+// do sends a GET request to the registry, using the current credentials.
+func (o *ociRepository) do(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %q", rawURL)
+	}
+	request.Header.Set("Accept", accept)
+	switch {
+	case o.token != "":
+		request.Header.Set("Authorization", "Bearer "+o.token)
+	case o.username != "":
+		request.SetBasicAuth(o.username, o.password)
+	}
+
+	response, err := o.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", rawURL)
+	}
+	return response, nil
+}
+
+// authenticate gets a bearer token from the token server requested by the registry, if any.
+// See https://docs.docker.com/registry/spec/auth/token/ for more details.
+func (o *ociRepository) authenticate(authenticate string) error {
+	if !strings.HasPrefix(strings.ToLower(authenticate), "bearer ") {
+		if o.username == "" {
+			return errors.Errorf("the registry %s requires authentication, please set the %s and %s variables in the clusterctl config file, or the OCI_USERNAME and OCI_PASSWORD environment variables",
+				o.registry, config.OCIUsernameVariable, config.OCIPasswordVariable)
+		}
+		// The registry requires basic authentication, and credentials have already been sent.
+		return errors.Errorf("failed to authenticate with the registry %s", o.registry)
+	}
+
+	params := map[string]string{}
+	for _, match := range ociAuthenticateParamRegex.FindAllStringSubmatch(authenticate, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return errors.Errorf("invalid WWW-Authenticate header from the registry %s: %q", o.registry, authenticate)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid token server url %q", params["realm"])
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpRequestTimeout)
+	defer cancel()
+
+	o.token = ""
+	response, err := o.do(ctx, tokenURL.String(), "application/json")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to get a token for the registry %s: %s", o.registry, response.Status)
+	}
+
+	token := &ociToken{}
+	if err := json.NewDecoder(response.Body).Decode(token); err != nil {
+		return errors.Wrapf(err, "failed to parse the token for the registry %s", o.registry)
+	}
+	o.token = token.Token
+	if o.token == "" {
+		o.token = token.AccessToken
+	}
+	if o.token == "" {
+		return errors.Errorf("failed to get a token for the registry %s", o.registry)
+	}
+	return nil
+}
+
+// configureTransport configures the scheme and the TLS settings used to access the registry, according to the
+// oci-plain-http, oci-insecure-skip-tls-verify and oci-ca-file variables.
+func (o *ociRepository) configureTransport(configVariablesClient config.VariablesClient) error {
+	plainHTTP, err := getBoolVariable(configVariablesClient, config.OCIPlainHTTPVariable)
+	if err != nil {
+		return err
+	}
+	if plainHTTP {
+		o.scheme = httpScheme
+	}
+
+	insecureSkipTLSVerify, err := getBoolVariable(configVariablesClient, config.OCIInsecureSkipTLSVerifyVariable)
+	if err != nil {
+		return err
+	}
+	caFile, _ := configVariablesClient.Get(config.OCICAFileVariable)
+	if !insecureSkipTLSVerify && caFile == "" {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipTLSVerify, //nolint:gosec
+	}
+	if caFile != "" {
+		// NB. the system cert pool is not available on all the platforms, e.g. on Windows.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the OCI CA file %q", caFile)
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return errors.Errorf("failed to parse the OCI CA file %q: no PEM encoded certificate found", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	o.httpClient = &http.Client{Transport: transport, Timeout: httpRequestTimeout}
+	return nil
+}
+
+// getBoolVariable returns the value of a boolean variable, which is false if the variable is not set.
+func getBoolVariable(configVariablesClient config.VariablesClient, key string) (bool, error) {
+	value, err := configVariablesClient.Get(key)
+	if err != nil || value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf("invalid value %q for the %s variable: must be true or false", value, key)
+	}
+	return b, nil
+}
+
+// verifyOCIDigest checks that the content matches the given sha256 digest.
+func verifyOCIDigest(content []byte, digest string) error {
+	algorithm := strings.SplitN(digest, ":", 2)[0]
+	if algorithm != "sha256" {
+		return errors.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	sum := sha256.Sum256(content)
+	if expected := "sha256:" + hex.EncodeToString(sum[:]); digest != expected {
+		return errors.Errorf("digest mismatch: expected %s, got %s", digest, expected)
+	}
+	return nil
+}
+
+// newOCIRepository returns an ociRepository implementation.
+func newOCIRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...ociRepositoryOption) (*ociRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != ociScheme {
+		return nil, errors.New("invalid url: an OCI repository url should start with oci://")
+	}
+
+	// Check if the path is in the expected format, i.e. {repository}:{version}/{components.yaml};
+	// the repository path can't contain colons, so the first colon separates the repository from the version.
+	urlSplit := strings.SplitN(strings.TrimPrefix(rURL.Path, "/"), ":", 2)
+	versionSplit := []string{}
+	if len(urlSplit) == 2 {
+		versionSplit = strings.SplitN(urlSplit[1], "/", 2)
+	}
+	if rURL.Host == "" || urlSplit[0] == "" || len(versionSplit) != 2 || versionSplit[0] == "" || versionSplit[1] == "" {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version}/{components.yaml}")
+	}
+	filePath := versionSplit[1]
+
+	// use path's directory as a rootPath
+	rootPath := filepath.Dir(filePath)
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(filePath, rootPath)
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		scheme:                httpsScheme,
+		registry:              rURL.Host,
+		repository:            urlSplit[0],
+		defaultVersion:        versionSplit[0],
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+		httpClient:            &http.Client{Timeout: httpRequestTimeout},
+	}
+
+	if err := repo.configureTransport(configVariablesClient); err != nil {
+		return nil, err
+	}
+
+	// process ociRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if username, err := configVariablesClient.Get(config.OCIUsernameVariable); err == nil {
+		repo.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIPasswordVariable); err == nil {
+		repo.password = password
+	}
+
+	if repo.defaultVersion == latestVersionTag {
+		repo.defaultVersion, err = latestContractRelease(repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get OCI latest version")
+		}
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeOCIRegistry returns a registry hosting the v0.4.0 and v0.4.1 versions of a provider in the org/provider repository,
+// which requires a bearer token issued to the user/password credentials. The registry uses TLS unless plainHTTP is true.
+// The list of tags has two pages, the latest version being on the second one.
+func newFakeOCIRegistry(t *testing.T, plainHTTP bool) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewUnstartedServer(mux)
+	if plainHTTP {
+		server.Start()
+	} else {
+		server.StartTLS()
+	}
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		testMethod(t, r, "GET")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:org/provider:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "password" ||
+			r.URL.Query().Get("scope") != "repository:org/provider:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token": "token"}`)
+	})
+	mux.HandleFunc("/v2/org/provider/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.URL.Query().Get("last") == "foo" {
+			fmt.Fprint(w, `{"name": "org/provider", "tags": ["v0.4.1"]}`)
+			return
+		}
+		w.Header().Set("Link", `</v2/org/provider/tags/list?last=foo&n=2>; rel="next"`)
+		fmt.Fprint(w, `{"name": "org/provider", "tags": ["v0.4.0", "foo"]}`)
+	})
+
+	blob := func(content string) ociDescriptor {
+		sum := sha256.Sum256([]byte(content))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		mux.HandleFunc("/v2/org/provider/blobs/"+digest, func(w http.ResponseWriter, r *http.Request) {
+			if authorized(w, r) {
+				fmt.Fprint(w, content)
+			}
+		})
+		return ociDescriptor{MediaType: "application/vnd.cncf.cluster-api.yaml", Digest: digest}
+	}
+	mux.HandleFunc(fmt.Sprintf("/v2/org/provider/blobs/sha256:%064d", 0), func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			fmt.Fprint(w, "corrupted")
+		}
+	})
+	for _, v := range []string{"v0.4.0", "v0.4.1"} {
+		components := blob(fmt.Sprintf("components %s", v))
+		components.Annotations = map[string]string{ociImageTitleAnnotation: "components.yaml"}
+		template := blob(fmt.Sprintf("template %s", v))
+		template.Annotations = map[string]string{ociImageTitleAnnotation: "cluster-template.yaml"}
+		// The digest of this layer does not match its content.
+		corrupted := ociDescriptor{
+			Digest:      fmt.Sprintf("sha256:%064d", 0),
+			Annotations: map[string]string{ociImageTitleAnnotation: "corrupted.yaml"},
+		}
+
+		manifest, err := json.Marshal(ociManifest{Layers: []ociDescriptor{components, template, corrupted}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux.HandleFunc("/v2/org/provider/manifests/"+v, func(w http.ResponseWriter, r *http.Request) {
+			if authorized(w, r) {
+				if r.Header.Get("Accept") != ociManifestMediaType {
+					t.Errorf("Request accept header: %v, want %v", r.Header.Get("Accept"), ociManifestMediaType)
+				}
+				fmt.Fprint(w, string(manifest))
+			}
+		})
+	}
+	return server
+}
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	server := newFakeOCIRegistry(t, false)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+	credentials := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+
+	tests := []struct {
+		name           string
+		url            string
+		variableClient config.VariablesClient
+		want           *ociRepository
+		wantErr        bool
+	}{
+		{
+			name:           "can create a new OCI repository",
+			url:            "oci://ghcr.io/org/provider:v0.4.0/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want: &ociRepository{
+				scheme:         "https",
+				registry:       "ghcr.io",
+				repository:     "org/provider",
+				defaultVersion: "v0.4.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "resolves the latest version",
+			url:            "oci://" + registry + "/org/provider:latest/components.yaml",
+			variableClient: credentials,
+			want: &ociRepository{
+				scheme:         "https",
+				registry:       registry,
+				repository:     "org/provider",
+				username:       "user",
+				password:       "password",
+				token:          "token",
+				defaultVersion: "v0.4.1",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:           "fails to resolve the latest version without credentials",
+			url:            "oci://" + registry + "/org/provider:latest/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "missing variableClient",
+			url:            "oci://ghcr.io/org/provider:v0.4.0/components.yaml",
+			variableClient: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should be in oci://{registry}/{repository}:{latest|version}/{components.yaml} format",
+			url:            "oci://ghcr.io/org/provider/components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+		{
+			name:           "provider url should include the components yaml",
+			url:            "oci://ghcr.io/org/provider:v0.4.0",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			got, err := newOCIRepository(providerConfig, tt.variableClient, injectOCIHTTPClient(server.Client()))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			tt.want.providerConfig = providerConfig
+			tt.want.configVariablesClient = tt.variableClient
+			tt.want.httpClient = server.Client()
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	server := newFakeOCIRegistry(t, false)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name     string
+		version  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Version and file exist",
+			version:  "v0.4.0",
+			fileName: "components.yaml",
+			want:     []byte("components v0.4.0"),
+		},
+		{
+			name:     "Latest version",
+			version:  "latest",
+			fileName: "cluster-template.yaml",
+			want:     []byte("template v0.4.1"),
+		},
+		{
+			name:     "Version does not exist",
+			version:  "v0.5.0",
+			fileName: "components.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not exist",
+			version:  "v0.4.0",
+			fileName: "404.file",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not match its digest",
+			version:  "v0.4.0",
+			fileName: "corrupted.yaml",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			variableClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+			repo, err := newOCIRepository(config.NewProvider("test", "oci://"+registry+"/org/provider:v0.4.0/components.yaml", clusterctlv1.CoreProviderType), variableClient, injectOCIHTTPClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_authenticate(t *testing.T) {
+	g := NewWithT(t)
+
+	repo := &ociRepository{registry: "registry.example.com"}
+
+	// Without credentials, the error tells how to set them.
+	err := repo.authenticate(`Basic realm="registry"`)
+	g.Expect(err).To(MatchError(ContainSubstring("please set the oci-username and oci-password variables")))
+	g.Expect(err).To(MatchError(ContainSubstring("OCI_USERNAME and OCI_PASSWORD environment variables")))
+
+	// With credentials already sent, basic authentication failed.
+	repo.username = "user"
+	g.Expect(repo.authenticate(`Basic realm="registry"`)).To(MatchError("failed to authenticate with the registry registry.example.com"))
+}
+
+func Test_ociRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+	resetCaches()
+
+	server := newFakeOCIRegistry(t, false)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	variableClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+	repo, err := newOCIRepository(config.NewProvider("test", "oci://"+registry+"/org/provider:v0.4.0/components.yaml", clusterctlv1.CoreProviderType), variableClient, injectOCIHTTPClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1"))
+}
+
+func Test_ociRepository_transport(t *testing.T) {
+	tlsServer := newFakeOCIRegistry(t, false)
+	defer tlsServer.Close()
+	plainHTTPServer := newFakeOCIRegistry(t, true)
+	defer plainHTTPServer.Close()
+
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+	caFile := filepath.Join(tmpDir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidCAFile := filepath.Join(tmpDir, "invalid.crt")
+	if err := os.WriteFile(invalidCAFile, []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		server    *httptest.Server
+		variables map[string]string
+		wantErr   bool
+	}{
+		{
+			name:    "fails to verify a registry certificate signed by an unknown CA",
+			server:  tlsServer,
+			wantErr: true,
+		},
+		{
+			name:      "trusts the CA file",
+			server:    tlsServer,
+			variables: map[string]string{config.OCICAFileVariable: caFile},
+		},
+		{
+			name:      "skips the verification of the registry certificate",
+			server:    tlsServer,
+			variables: map[string]string{config.OCIInsecureSkipTLSVerifyVariable: "true"},
+		},
+		{
+			name:      "uses plain HTTP",
+			server:    plainHTTPServer,
+			variables: map[string]string{config.OCIPlainHTTPVariable: "true"},
+		},
+		{
+			name:      "fails if the CA file does not exist",
+			server:    tlsServer,
+			variables: map[string]string{config.OCICAFileVariable: filepath.Join(tmpDir, "missing.crt")},
+			wantErr:   true,
+		},
+		{
+			name:      "fails if the CA file does not contain certificates",
+			server:    tlsServer,
+			variables: map[string]string{config.OCICAFileVariable: invalidCAFile},
+			wantErr:   true,
+		},
+		{
+			name:      "fails if a boolean variable is invalid",
+			server:    plainHTTPServer,
+			variables: map[string]string{config.OCIPlainHTTPVariable: "foo"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resetCaches()
+
+			variableClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+			for k, v := range tt.variables {
+				variableClient.WithVar(k, v)
+			}
+			registry := strings.TrimPrefix(strings.TrimPrefix(tt.server.URL, "https://"), "http://")
+
+			// Resolving the latest version requires to list the tags of the repository.
+			repo, err := newOCIRepository(config.NewProvider("test", "oci://"+registry+"/org/provider:latest/components.yaml", clusterctlv1.CoreProviderType), variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.DefaultVersion()).To(Equal("v0.4.1"))
+			// An unresponsive registry must not hang clusterctl.
+			g.Expect(repo.httpClient.Timeout).To(Equal(httpRequestTimeout))
+		})
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// controlPlaneGroup is the API group of the Cluster API control plane providers.
+const controlPlaneGroup = "controlplane.cluster.x-k8s.io"
+
+// templateObjectReferences defines, for each kind, the fields referencing other objects in a workload cluster template.
+var templateObjectReferences = map[schema.GroupKind][]string{
+	{Group: clusterv1.GroupVersion.Group, Kind: "Cluster"}:           {"spec.infrastructureRef", "spec.controlPlaneRef"},
+	{Group: clusterv1.GroupVersion.Group, Kind: "MachineDeployment"}: {"spec.template.spec.bootstrap.configRef", "spec.template.spec.infrastructureRef"},
+	{Group: clusterv1.GroupVersion.Group, Kind: "MachineSet"}:        {"spec.template.spec.bootstrap.configRef", "spec.template.spec.infrastructureRef"},
+	{Group: clusterv1.GroupVersion.Group, Kind: "MachinePool"}:       {"spec.template.spec.bootstrap.configRef", "spec.template.spec.infrastructureRef"},
+	{Group: clusterv1.GroupVersion.Group, Kind: "Machine"}:           {"spec.bootstrap.configRef", "spec.infrastructureRef"},
+	{Group: controlPlaneGroup, Kind: "KubeadmControlPlane"}:          {"spec.machineTemplate.infrastructureRef"},
+}
+
+// templateClusterNameKinds defines the kinds referencing a Cluster in a workload cluster template using spec.clusterName.
+var templateClusterNameKinds = sets.NewString("MachineDeployment", "MachineSet", "MachinePool", "Machine", "MachineHealthCheck")
+
+// TemplateValidationError is an error found when validating an object in a workload cluster template.
+type TemplateValidationError struct {
+	// APIVersion of the invalid object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the invalid object.
+	Kind string `json:"kind"`
+
+	// Name of the invalid object.
+	Name string `json:"name"`
+
+	// Field is the path of the invalid field, if any, e.g. spec.infrastructureRef.
+	Field string `json:"field,omitempty"`
+
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+func (e TemplateValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s/%s: %s", e.Kind, e.Name, e.Message)
+	}
+	return fmt.Sprintf("%s/%s: %s: %s", e.Kind, e.Name, e.Field, e.Message)
+}
+
+// ValidateTemplateObjects validates the objects of a workload cluster template against the CustomResourceDefinitions
+// in crds, usually read from the provider components YAML, and checks the references across objects in the template,
+// e.g. from a Cluster to its infrastructure and control plane objects.
+// Objects in API groups not defined by any of the CustomResourceDefinitions, e.g. ConfigMaps, are not validated.
+func ValidateTemplateObjects(objs []unstructured.Unstructured, crds []unstructured.Unstructured) ([]TemplateValidationError, error) {
+	v, err := newTemplateValidator(crds)
+	if err != nil {
+		return nil, err
+	}
+
+	var validationErrors []TemplateValidationError
+	for i := range objs {
+		errs, err := v.validateObject(objs[i])
+		if err != nil {
+			return nil, err
+		}
+		validationErrors = append(validationErrors, errs...)
+	}
+	validationErrors = append(validationErrors, validateTemplateReferences(objs)...)
+	return validationErrors, nil
+}
+
+// templateValidator validates objects against a set of CustomResourceDefinitions.
+type templateValidator struct {
+	crds   map[schema.GroupKind]*apiextensionsv1.CustomResourceDefinition
+	groups sets.String
+}
+
+func newTemplateValidator(crds []unstructured.Unstructured) (*templateValidator, error) {
+	v := &templateValidator{
+		crds:   map[schema.GroupKind]*apiextensionsv1.CustomResourceDefinition{},
+		groups: sets.NewString(),
+	}
+	for _, obj := range crds {
+		// NOTE: CustomResourceDefinitions using deprecated API versions are ignored.
+		if obj.GetKind() != "CustomResourceDefinition" || obj.GetAPIVersion() != apiextensionsv1.SchemeGroupVersion.String() {
+			continue
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), crd); err != nil {
+			return nil, errors.Wrapf(err, "failed to convert CustomResourceDefinition %q", obj.GetName())
+		}
+		v.crds[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = crd
+		v.groups.Insert(crd.Spec.Group)
+	}
+	return v, nil
+}
+
+// validateObject validates an object against the schema of the corresponding CustomResourceDefinition version.
+func (v *templateValidator) validateObject(obj unstructured.Unstructured) ([]TemplateValidationError, error) {
+	gvk := obj.GroupVersionKind()
+	newError := func(field, message string, args ...interface{}) TemplateValidationError {
+		return TemplateValidationError{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Field:      field,
+			Message:    fmt.Sprintf(message, args...),
+		}
+	}
+
+	crd, ok := v.crds[gvk.GroupKind()]
+	if !ok {
+		if v.groups.Has(gvk.Group) {
+			return []TemplateValidationError{newError("kind", "kind %s is not served by any provider in the %s API group", gvk.Kind, gvk.Group)}, nil
+		}
+		return nil, nil
+	}
+
+	var version *apiextensionsv1.CustomResourceDefinitionVersion
+	servedVersions := []string{}
+	for i := range crd.Spec.Versions {
+		if !crd.Spec.Versions[i].Served {
+			continue
+		}
+		servedVersions = append(servedVersions, crd.Spec.Versions[i].Name)
+		if crd.Spec.Versions[i].Name == gvk.Version {
+			version = &crd.Spec.Versions[i]
+		}
+	}
+	if version == nil {
+		return []TemplateValidationError{newError("apiVersion", "version %s of %s is not served, served versions are [%s]", gvk.Version, gvk.Kind, strings.Join(servedVersions, ", "))}, nil
+	}
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil, nil
+	}
+
+	// Convert schema to Kubernetes APIExtensions Schema.
+	validation := &apiextensions.CustomResourceValidation{}
+	if err := apiextensionsv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(version.Schema, validation, nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to convert the schema of %s", gvk)
+	}
+
+	// Validate the object against the schema.
+	// NOTE: We're reusing a library func used in CRD validation.
+	validator, _, err := apiextensionsvalidation.NewSchemaValidator(validation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the schema validator for %s", gvk)
+	}
+	var validationErrors []TemplateValidationError
+	for _, e := range apiextensionsvalidation.ValidateCustomResource(nil, obj.UnstructuredContent(), validator) {
+		validationErrors = append(validationErrors, newError(e.Field, e.ErrorBody()))
+	}
+
+	// Detect unknown fields, e.g. typos, by comparing the object with the object pruned like the API server would do.
+	// NOTE: Pruning requires a structural schema; if the schema is not structural, unknown fields are not detected.
+	structural, err := structuralschema.NewStructural(validation.OpenAPIV3Schema)
+	if err != nil {
+		return validationErrors, nil
+	}
+	pruned := runtime.DeepCopyJSONValue(obj.UnstructuredContent())
+	pruning.Prune(pruned, structural, true)
+	for _, f := range unknownFields(obj.UnstructuredContent(), pruned, nil) {
+		validationErrors = append(validationErrors, newError(f, "unknown field"))
+	}
+	return validationErrors, nil
+}
+
+// unknownFields returns the paths of the fields in original which do not exist in pruned.
+func unknownFields(original, pruned interface{}, fldPath *field.Path) []string {
+	var fields []string
+	switch o := original.(type) {
+	case map[string]interface{}:
+		p, _ := pruned.(map[string]interface{})
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := p[k]; !ok {
+				fields = append(fields, fldPath.Child(k).String())
+				continue
+			}
+			fields = append(fields, unknownFields(o[k], p[k], fldPath.Child(k))...)
+		}
+	case []interface{}:
+		p, _ := pruned.([]interface{})
+		for i := range o {
+			if i < len(p) {
+				fields = append(fields, unknownFields(o[i], p[i], fldPath.Index(i))...)
+			}
+		}
+	}
+	return fields
+}
+
+// validateTemplateReferences checks that the objects referenced by other objects in the template exist in the template.
+func validateTemplateReferences(objs []unstructured.Unstructured) []TemplateValidationError {
+	type objKey struct {
+		kind string
+		name string
+	}
+	apiVersions := map[objKey]string{}
+	for _, obj := range objs {
+		apiVersions[objKey{kind: obj.GetKind(), name: obj.GetName()}] = obj.GetAPIVersion()
+	}
+
+	var validationErrors []TemplateValidationError
+	for _, obj := range objs {
+		newError := func(field, message string, args ...interface{}) TemplateValidationError {
+			return TemplateValidationError{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Name:       obj.GetName(),
+				Field:      field,
+				Message:    fmt.Sprintf(message, args...),
+			}
+		}
+		gvk := obj.GroupVersionKind()
+
+		for _, refPath := range templateObjectReferences[gvk.GroupKind()] {
+			ref, ok, _ := unstructured.NestedStringMap(obj.UnstructuredContent(), strings.Split(refPath, ".")...)
+			if !ok {
+				continue
+			}
+			apiVersion, ok := apiVersions[objKey{kind: ref["kind"], name: ref["name"]}]
+			if !ok {
+				validationErrors = append(validationErrors, newError(refPath, "%s %q does not exist in the template", ref["kind"], ref["name"]))
+				continue
+			}
+			if apiVersion != ref["apiVersion"] {
+				validationErrors = append(validationErrors, newError(refPath+".apiVersion", "apiVersion %s does not match the apiVersion %s of %s %q in the template", ref["apiVersion"], apiVersion, ref["kind"], ref["name"]))
+			}
+		}
+
+		if gvk.Group == clusterv1.GroupVersion.Group && templateClusterNameKinds.Has(gvk.Kind) {
+			clusterName, ok, _ := unstructured.NestedString(obj.UnstructuredContent(), "spec", "clusterName")
+			if !ok {
+				continue
+			}
+			if apiVersion, ok := apiVersions[objKey{kind: "Cluster", name: clusterName}]; !ok || schema.FromAPIVersionAndKind(apiVersion, "Cluster").Group != clusterv1.GroupVersion.Group {
+				validationErrors = append(validationErrors, newError("spec.clusterName", "Cluster %q does not exist in the template", clusterName))
+			}
+		}
+	}
+	return validationErrors
+}
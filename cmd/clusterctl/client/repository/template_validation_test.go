@@ -0,0 +1,246 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+var templateValidationCRDs = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusters.cluster.x-k8s.io
+spec:
+  group: cluster.x-k8s.io
+  names:
+    kind: Cluster
+    plural: clusters
+  scope: Namespaced
+  versions:
+  - name: v1alpha3
+    served: false
+    storage: false
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            type: object
+            properties:
+              paused:
+                type: boolean
+              infrastructureRef:
+                type: object
+                properties:
+                  apiVersion:
+                    type: string
+                  kind:
+                    type: string
+                  name:
+                    type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: machinehealthchecks.cluster.x-k8s.io
+spec:
+  group: cluster.x-k8s.io
+  names:
+    kind: MachineHealthCheck
+    plural: machinehealthchecks
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: dummyclusters.infrastructure.cluster.x-k8s.io
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: DummyCluster
+    plural: dummyclusters
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            type: object
+            required:
+            - region
+            properties:
+              region:
+                type: string
+`
+
+func TestValidateTemplateObjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []TemplateValidationError
+	}{
+		{
+			name: "valid template",
+			template: `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: foo
+spec:
+  infrastructureRef:
+    apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+    kind: DummyCluster
+    name: foo
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DummyCluster
+metadata:
+  name: foo
+spec:
+  region: eu
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-validated
+data:
+  foo: bar
+`,
+			want: nil,
+		},
+		{
+			name: "schema errors",
+			template: `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: foo
+spec:
+  paused: "yes"
+  infrastructurRef: {}
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DummyCluster
+metadata:
+  name: foo
+spec: {}
+`,
+			want: []TemplateValidationError{
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "foo", Field: "spec.paused", Message: `Invalid value: "string": spec.paused in body must be of type boolean: "string"`},
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "foo", Field: "spec.infrastructurRef", Message: "unknown field"},
+				{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DummyCluster", Name: "foo", Field: "spec.region", Message: "Required value"},
+			},
+		},
+		{
+			name: "versions and kinds not served",
+			template: `apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Cluster
+metadata:
+  name: foo
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DummyMachine
+metadata:
+  name: foo
+`,
+			want: []TemplateValidationError{
+				{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Cluster", Name: "foo", Field: "apiVersion", Message: "version v1alpha3 of Cluster is not served, served versions are [v1beta1]"},
+				{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DummyMachine", Name: "foo", Field: "kind", Message: "kind DummyMachine is not served by any provider in the infrastructure.cluster.x-k8s.io API group"},
+			},
+		},
+		{
+			name: "references errors",
+			template: `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: foo
+spec:
+  infrastructureRef:
+    apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
+    kind: DummyCluster
+    name: foo
+  controlPlaneRef:
+    apiVersion: controlplane.cluster.x-k8s.io/v1beta1
+    kind: KubeadmControlPlane
+    name: foo
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DummyCluster
+metadata:
+  name: foo
+spec:
+  region: eu
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineHealthCheck
+metadata:
+  name: foo
+spec:
+  clusterName: bar
+`,
+			want: []TemplateValidationError{
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "foo", Field: "spec.controlPlaneRef", Message: "unknown field"},
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "foo", Field: "spec.infrastructureRef.apiVersion", Message: "apiVersion infrastructure.cluster.x-k8s.io/v1alpha4 does not match the apiVersion infrastructure.cluster.x-k8s.io/v1beta1 of DummyCluster \"foo\" in the template"},
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "foo", Field: "spec.controlPlaneRef", Message: "KubeadmControlPlane \"foo\" does not exist in the template"},
+				{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "MachineHealthCheck", Name: "foo", Field: "spec.clusterName", Message: "Cluster \"bar\" does not exist in the template"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			crds, err := utilyaml.ToUnstructured([]byte(templateValidationCRDs))
+			g.Expect(err).NotTo(HaveOccurred())
+			objs, err := utilyaml.ToUnstructured([]byte(tt.template))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := ValidateTemplateObjects(objs, crds)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// TopologyPlanOptions define options for TopologyPlan.
+type TopologyPlanOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Objs is the list of objects that are input to the topology plan, e.g. a ClusterClass, its templates or Clusters.
+	Objs []*unstructured.Unstructured
+
+	// Cluster is the name of the Cluster to compute the plan for. If empty, the plan is computed for all the
+	// Clusters affected by the input objects.
+	Cluster string
+
+	// Namespace where the input objects without a namespace and the target Cluster live. If unspecified,
+	// the default namespace is used, or the current namespace from the kubeconfig if FromCluster is set.
+	Namespace string
+
+	// FromCluster defines if the objects currently existing in the management cluster should be used
+	// together with the input objects; if false, the plan is computed offline using only the input objects.
+	FromCluster bool
+}
+
+// TopologyPlan computes the changes the topology controller would apply to the Clusters affected by the input objects.
+func (c *clusterctlClient) TopologyPlan(options TopologyPlanOptions) (*TopologyPlanOutput, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := clusterClient.Topology().Plan(&cluster.TopologyPlanInput{
+		Objs:              options.Objs,
+		TargetClusterName: options.Cluster,
+		TargetNamespace:   options.Namespace,
+		FromCluster:       options.FromCluster,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*TopologyPlanOutput)(out), nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// ValidateTemplateOptions carries the options supported by ValidateTemplate.
+type ValidateTemplateOptions struct {
+	// Template defines the workload cluster template to be validated and the options used for rendering it, like
+	// when generating a cluster. If the TargetNamespace is unspecified, the default namespace is used.
+	Template GetClusterTemplateOptions
+
+	// CoreProvider version (e.g. cluster-api:v0.3.0) providing the CRDs used for validating the template.
+	// If unspecified, the cluster-api core provider's latest release is used.
+	CoreProvider string
+
+	// BootstrapProviders and versions (e.g. kubeadm:v0.3.0) providing the CRDs used for validating the template.
+	// If unspecified, the kubeadm bootstrap provider's latest release is used.
+	BootstrapProviders []string
+
+	// ControlPlaneProviders and versions (e.g. kubeadm:v0.3.0) providing the CRDs used for validating the template.
+	// If unspecified, the kubeadm control plane provider's latest release is used.
+	ControlPlaneProviders []string
+
+	// InfrastructureProviders and versions (e.g. aws:v0.5.0) providing the CRDs used for validating the template.
+	// The infrastructure provider the template is read from, if any, is always included.
+	InfrastructureProviders []string
+
+	// ComponentsFiles are additional components YAML files providing CRDs used for validating the template,
+	// e.g. the components of a provider under development.
+	ComponentsFiles []string
+}
+
+// ValidateTemplate renders a workload cluster template and validates its objects against the CRDs defined in the
+// provider components YAML, without accessing the management cluster; it also checks the references across objects
+// in the template. It returns the list of the errors found in the template.
+func (c *clusterctlClient) ValidateTemplate(options ValidateTemplateOptions) ([]TemplateValidationError, error) {
+	// The template is validated offline, so the target namespace can't be read from the kubeconfig.
+	templateOptions := options.Template
+	if templateOptions.TargetNamespace == "" {
+		templateOptions.TargetNamespace = "default"
+	}
+
+	if options.CoreProvider == "" {
+		options.CoreProvider = config.ClusterAPIProviderName
+	}
+	if len(options.BootstrapProviders) == 0 {
+		options.BootstrapProviders = append(options.BootstrapProviders, config.KubeadmBootstrapProviderName)
+	}
+	if len(options.ControlPlaneProviders) == 0 {
+		options.ControlPlaneProviders = append(options.ControlPlaneProviders, config.KubeadmControlPlaneProviderName)
+	}
+
+	crds := []unstructured.Unstructured{}
+	providers := []struct {
+		providerType clusterctlv1.ProviderType
+		names        []string
+	}{
+		{clusterctlv1.CoreProviderType, []string{options.CoreProvider}},
+		{clusterctlv1.BootstrapProviderType, options.BootstrapProviders},
+		{clusterctlv1.ControlPlaneProviderType, options.ControlPlaneProviders},
+		{clusterctlv1.InfrastructureProviderType, options.InfrastructureProviders},
+	}
+	for _, p := range providers {
+		for _, provider := range p.names {
+			// It is possible to opt-out from bootstrap/control-plane providers using '-' as a provider name (NoopProvider).
+			if provider == NoopProvider {
+				continue
+			}
+			_, providerCRDs, err := c.getProviderCRDs(provider, p.providerType)
+			if err != nil {
+				return nil, err
+			}
+			crds = append(crds, providerCRDs...)
+		}
+	}
+
+	// If the template is read from an infrastructure provider repository, the CRDs of the same provider are used;
+	// the resolved version is used also for reading the template, given that it can't be read from the management cluster.
+	if templateOptions.ProviderRepositorySource != nil && templateOptions.ProviderRepositorySource.InfrastructureProvider != "" {
+		source := *templateOptions.ProviderRepositorySource
+		name, _, err := parseProviderName(source.InfrastructureProvider)
+		if err != nil {
+			return nil, err
+		}
+		version, providerCRDs, err := c.getProviderCRDs(source.InfrastructureProvider, clusterctlv1.InfrastructureProviderType)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, providerCRDs...)
+		source.InfrastructureProvider = name + ":" + version
+		templateOptions.ProviderRepositorySource = &source
+	}
+
+	for _, f := range options.ComponentsFiles {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read components file %q", f)
+		}
+		objs, err := utilyaml.ToUnstructured(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse components file %q", f)
+		}
+		crds = append(crds, objs...)
+	}
+
+	template, err := c.GetClusterTemplate(templateOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the template")
+	}
+
+	validationErrors, err := repository.ValidateTemplateObjects(template.Objs(), crds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TemplateValidationError, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		result = append(result, TemplateValidationError(e))
+	}
+	return result, nil
+}
+
+// getProviderCRDs returns the CustomResourceDefinitions in the components YAML of a provider, and the provider version.
+func (c *clusterctlClient) getProviderCRDs(provider string, providerType clusterctlv1.ProviderType) (string, []unstructured.Unstructured, error) {
+	name, version, err := parseProviderName(provider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, providerType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Variables in the components YAML are not replaced, given that they are not relevant for the CRDs.
+	components, err := repositoryClient.Components().Get(repository.ComponentsOptions{Version: version, SkipTemplateProcess: true})
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to get the components of the %q provider", provider)
+	}
+
+	crds := []unstructured.Unstructured{}
+	for _, obj := range components.Objs() {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			crds = append(crds, obj)
+		}
+	}
+	return components.Version(), crds, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+// validateCRDYAML returns a CustomResourceDefinition for a kind with a spec.infrastructureRef object field,
+// and a spec.replicas integer field.
+func validateCRDYAML(group, kind, plural string) []byte {
+	return []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ` + plural + "." + group + `
+spec:
+  group: ` + group + `
+  names:
+    kind: ` + kind + `
+    plural: ` + plural + `
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: integer
+              infrastructureRef:
+                type: object
+                properties:
+                  apiVersion:
+                    type: string
+                  kind:
+                    type: string
+                  name:
+                    type: string
+`)
+}
+
+// validateComponentsYAML returns a provider components YAML with a namespace and a CustomResourceDefinition.
+func validateComponentsYAML(ns string, crd []byte) []byte {
+	return append(append(componentsYAML(ns), []byte("\n---\n")...), crd...)
+}
+
+var validateClusterTemplateYAML = []byte(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+spec:
+  infrastructureRef:
+    apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+    kind: InfraCluster
+    name: ${CLUSTER_NAME}
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: InfraCluster
+metadata:
+  name: ${CLUSTER_NAME}
+spec:
+  replicas: ${REPLICAS}
+`)
+
+func fakeValidateClient() *fakeClient {
+	config1 := fakeConfig(
+		[]config.Provider{capiProviderConfig, bootstrapProviderConfig, controlPlaneProviderConfig, infraProviderConfig},
+		map[string]string{"REPLICAS": "3"},
+	)
+
+	repository1 := newFakeRepository(capiProviderConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.0.0").
+		WithFile("v1.0.0", "components.yaml", validateComponentsYAML("ns1", validateCRDYAML("cluster.x-k8s.io", "Cluster", "clusters")))
+	repository2 := newFakeRepository(bootstrapProviderConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v2.0.0").
+		WithFile("v2.0.0", "components.yaml", componentsYAML("ns2"))
+	repository3 := newFakeRepository(controlPlaneProviderConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v2.0.0").
+		WithFile("v2.0.0", "components.yaml", componentsYAML("ns3"))
+	repository4 := newFakeRepository(infraProviderConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v3.0.0").
+		WithFile("v3.0.0", "components.yaml", validateComponentsYAML("ns4", validateCRDYAML("infrastructure.cluster.x-k8s.io", "InfraCluster", "infraclusters"))).
+		WithFile("v3.0.0", "cluster-template.yaml", validateClusterTemplateYAML).
+		WithFile("v3.0.0", "cluster-template-missing-ref.yaml", []byte(strings.Split(string(validateClusterTemplateYAML), "---")[0])).
+		WithFile("v3.0.0", "cluster-template-string.yaml", []byte(strings.ReplaceAll(string(validateClusterTemplateYAML), "${REPLICAS}", "\"three\"")))
+
+	// The template is rendered without a management cluster, like when using clusterctl generate cluster without a kubeconfig.
+	cluster1 := newFakeCluster(cluster.Kubeconfig{}, config1)
+
+	return fakeClusterCtlClient(config1, []*fakeRepositoryClient{repository1, repository2, repository3, repository4}, []*fakeClusterClient{cluster1})
+}
+
+func Test_clusterctlClient_ValidateTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	// Components file with a CustomResourceDefinition for a provider under development.
+	componentsFile := filepath.Join(t.TempDir(), "components.yaml")
+	g.Expect(os.WriteFile(componentsFile, validateCRDYAML("infrastructure.cluster.x-k8s.io", "InfraCluster", "infraclusters"), 0600)).To(Succeed())
+
+	templateFile := filepath.Join(t.TempDir(), "cluster-template.yaml")
+	g.Expect(os.WriteFile(templateFile, validateClusterTemplateYAML, 0600)).To(Succeed())
+
+	tests := []struct {
+		name    string
+		options ValidateTemplateOptions
+		want    []TemplateValidationError
+		wantErr bool
+	}{
+		{
+			name: "Validates a template from a provider repository using the provider CRDs",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					ProviderRepositorySource: &ProviderRepositorySourceOptions{
+						InfrastructureProvider: "infra",
+					},
+					ClusterName: "test",
+				},
+			},
+			want: []TemplateValidationError{},
+		},
+		{
+			name: "Reports schema errors",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					ProviderRepositorySource: &ProviderRepositorySourceOptions{
+						InfrastructureProvider: "infra:v3.0.0",
+						Flavor:                 "string",
+					},
+					ClusterName: "test",
+				},
+			},
+			want: []TemplateValidationError{
+				{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "InfraCluster",
+					Name:       "test",
+					Field:      "spec.replicas",
+					Message:    "Invalid value: \"string\": spec.replicas in body must be of type integer: \"string\"",
+				},
+			},
+		},
+		{
+			name: "Reports references to objects not existing in the template",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					ProviderRepositorySource: &ProviderRepositorySourceOptions{
+						InfrastructureProvider: "infra",
+						Flavor:                 "missing-ref",
+					},
+					ClusterName: "test",
+				},
+			},
+			want: []TemplateValidationError{
+				{
+					APIVersion: "cluster.x-k8s.io/v1beta1",
+					Kind:       "Cluster",
+					Name:       "test",
+					Field:      "spec.infrastructureRef",
+					Message:    "InfraCluster \"test\" does not exist in the template",
+				},
+			},
+		},
+		{
+			name: "Skips objects without CRDs",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					URLSource:   &URLSourceOptions{URL: templateFile},
+					ClusterName: "test",
+				},
+				InfrastructureProviders: []string{NoopProvider},
+			},
+			want: []TemplateValidationError{},
+		},
+		{
+			name: "Validates a template using the CRDs from components files",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					URLSource:   &URLSourceOptions{URL: templateFile},
+					ClusterName: "test",
+				},
+				ComponentsFiles: []string{componentsFile},
+			},
+			want: []TemplateValidationError{},
+		},
+		{
+			name: "Fails if a components file does not exist",
+			options: ValidateTemplateOptions{
+				Template: GetClusterTemplateOptions{
+					URLSource:   &URLSourceOptions{URL: templateFile},
+					ClusterName: "test",
+				},
+				ComponentsFiles: []string{"does-not-exist.yaml"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := fakeValidateClient().ValidateTemplate(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
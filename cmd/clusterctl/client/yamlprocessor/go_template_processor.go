@@ -0,0 +1,280 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+)
+
+// GoTemplateProcessor is a yaml processor that uses Go text/template, thus allowing templates to use
+// conditionals and loops in addition to variable substitution. Variables are referenced as fields of the
+// template data in the format {{ .VAR }}, and default values can be specified using the default function,
+// e.g. {{ .VAR | default "value" }}.
+// See https://pkg.go.dev/text/template for more details.
+type GoTemplateProcessor struct{}
+
+var _ Processor = &GoTemplateProcessor{}
+
+// NewGoTemplateProcessor returns a new Go template processor.
+func NewGoTemplateProcessor() *GoTemplateProcessor {
+	return &GoTemplateProcessor{}
+}
+
+// goTemplateFuncs defines the functions available in templates, in addition to the text/template built-in functions.
+var goTemplateFuncs = template.FuncMap{
+	// default returns the default value if the value is empty, e.g. {{ .VAR | default "value" }}.
+	"default": func(defaultValue string, value interface{}) string {
+		if value == nil || fmt.Sprint(value) == "" {
+			return defaultValue
+		}
+		return fmt.Sprint(value)
+	},
+	// split splits a value into a list, e.g. {{ range .ZONES | split "," }}.
+	"split": func(separator string, value interface{}) []string {
+		if value == nil || fmt.Sprint(value) == "" {
+			return nil
+		}
+		return strings.Split(fmt.Sprint(value), separator)
+	},
+	// seq returns the list of integers from 0 to count-1, e.g. {{ range $i := seq .WORKER_MACHINE_COUNT }}.
+	"seq": func(count interface{}) ([]int, error) {
+		n, err := strconv.Atoi(fmt.Sprint(count))
+		if err != nil {
+			return nil, errors.Errorf("invalid count %q", count)
+		}
+		s := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			s = append(s, i)
+		}
+		return s, nil
+	},
+}
+
+// GetTemplateName returns the name of the template that the Go template processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *GoTemplateProcessor) GetTemplateName(_, flavor string) string {
+	return clusterTemplateName(flavor)
+}
+
+// GetVariables returns a list of the variables specified in the yaml.
+func (tp *GoTemplateProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	variables, err := tp.GetVariableMap(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+	varNames := make([]string, 0, len(variables))
+	for k := range variables {
+		varNames = append(varNames, k)
+	}
+	sort.Strings(varNames)
+	return varNames, nil
+}
+
+// GetVariableMap returns a map of the variables specified in the yaml.
+func (tp *GoTemplateProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]*string{}
+	for _, tt := range t.Templates() {
+		if tt.Tree == nil {
+			continue
+		}
+		if err := inspectGoTemplateNode(tt.Tree.Root, true, variables); err != nil {
+			return nil, err
+		}
+	}
+	return variables, nil
+}
+
+// Process returns the final yaml generated by executing the template with the variables
+// as data. If there are variables without corresponding values, it will return the raw
+// yaml along with an error.
+func (tp *GoTemplateProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	variables, err := tp.GetVariableMap(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	data := make(map[string]interface{}, len(variables))
+	var missingVariables []string
+	for name, defaultValue := range variables {
+		value, err := variablesClient(name)
+		if err != nil {
+			// add to missingVariables list if the variable does not exist in the
+			// variablesClient AND it does not have a default value
+			if defaultValue == nil {
+				missingVariables = append(missingVariables, name)
+				continue
+			}
+			// an empty value lets the default function return the default value.
+			value = ""
+		}
+		data[name] = value
+	}
+
+	if len(missingVariables) > 0 {
+		return rawArtifact, &errMissingVariables{missingVariables}
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to execute the template")
+	}
+	return out.Bytes(), nil
+}
+
+func parseGoTemplate(rawArtifact []byte) (*template.Template, error) {
+	t, err := template.New("template").Funcs(goTemplateFuncs).Option("missingkey=error").Parse(string(rawArtifact))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the template")
+	}
+	return t, nil
+}
+
+// inspectGoTemplateNode recursively walks down the node and tracks the variables, which are the fields
+// of the template data, and if the variables have default values.
+// NOTE: inside range and with blocks dot is set to a different value, so only variables accessed
+// using $ are considered there.
+func inspectGoTemplateNode(node parse.Node, dotIsData bool, variables map[string]*string) error {
+	var pipe *parse.PipeNode
+	var lists []*parse.ListNode
+	var listDotIsData []bool
+	switch v := node.(type) {
+	case *parse.ListNode:
+		if v == nil {
+			return nil
+		}
+		for _, n := range v.Nodes {
+			if err := inspectGoTemplateNode(n, dotIsData, variables); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.ActionNode:
+		pipe = v.Pipe
+	case *parse.TemplateNode:
+		pipe = v.Pipe
+	case *parse.IfNode:
+		pipe, lists, listDotIsData = v.Pipe, []*parse.ListNode{v.List, v.ElseList}, []bool{dotIsData, dotIsData}
+	case *parse.RangeNode:
+		pipe, lists, listDotIsData = v.Pipe, []*parse.ListNode{v.List, v.ElseList}, []bool{false, dotIsData}
+	case *parse.WithNode:
+		pipe, lists, listDotIsData = v.Pipe, []*parse.ListNode{v.List, v.ElseList}, []bool{false, dotIsData}
+	}
+
+	if err := inspectGoTemplatePipe(pipe, dotIsData, variables); err != nil {
+		return err
+	}
+	for i, list := range lists {
+		if err := inspectGoTemplateNode(list, listDotIsData[i], variables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inspectGoTemplatePipe(pipe *parse.PipeNode, dotIsData bool, variables map[string]*string) error {
+	if pipe == nil {
+		return nil
+	}
+
+	defaultValue := goTemplateDefaultValue(pipe)
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			name := ""
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				if dotIsData {
+					name = a.Ident[0]
+				}
+			case *parse.VariableNode:
+				if a.Ident[0] == "$" && len(a.Ident) > 1 {
+					name = a.Ident[1]
+				}
+			case *parse.PipeNode:
+				if err := inspectGoTemplatePipe(a, dotIsData, variables); err != nil {
+					return err
+				}
+			}
+			if name == "" {
+				continue
+			}
+			if err := addGoTemplateVariable(variables, name, defaultValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addGoTemplateVariable keys the variable name to its default value from the template, or to nil if it's required,
+// i.e. if it is used at least once without a default value.
+// NOTE: A variable used with different default values is rejected, because it has no single default value
+// to be reported when listing the variables.
+func addGoTemplateVariable(variables map[string]*string, name string, defaultValue *string) error {
+	current, ok := variables[name]
+	switch {
+	case !ok:
+		variables[name] = defaultValue
+	case current == nil:
+		// The variable is already required.
+	case defaultValue == nil:
+		variables[name] = nil
+	case *current != *defaultValue:
+		return errors.Errorf("variable %q has conflicting default values %q and %q", name, *current, *defaultValue)
+	}
+	return nil
+}
+
+// goTemplateDefaultValue returns the default value set by a pipeline in the format {{ .VAR | default "value" }}
+// or {{ default "value" .VAR }}, if any.
+func goTemplateDefaultValue(pipe *parse.PipeNode) *string {
+	var args []parse.Node
+	switch {
+	case len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 3:
+		args = pipe.Cmds[0].Args[:2]
+	case len(pipe.Cmds) == 2 && len(pipe.Cmds[0].Args) == 1 && len(pipe.Cmds[1].Args) == 2:
+		args = pipe.Cmds[1].Args
+	default:
+		return nil
+	}
+
+	if identifier, ok := args[0].(*parse.IdentifierNode); !ok || identifier.Ident != "default" {
+		return nil
+	}
+	if s, ok := args[1].(*parse.StringNode); ok {
+		return &s.Text
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestGoTemplateProcessor_GetTemplateName(t *testing.T) {
+	g := NewWithT(t)
+	p := NewGoTemplateProcessor()
+	g.Expect(p.GetTemplateName("some-version", "some-flavor")).To(Equal("cluster-template-some-flavor.yaml"))
+	g.Expect(p.GetTemplateName("", "")).To(Equal("cluster-template.yaml"))
+}
+
+func TestGoTemplateProcessor_GetVariableMap(t *testing.T) {
+	def := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]*string
+		wantErr bool
+	}{
+		{
+			name: "variables are fields of the template data",
+			data: "yaml with {{ .A }} {{.B}} {{ .A }}",
+			want: map[string]*string{"A": nil, "B": nil},
+		},
+		{
+			name: "variables with default values",
+			data: `yaml with {{ .A | default "a" }} {{ default "b" .B }} {{ .C | default "" }}`,
+			want: map[string]*string{"A": def("a"), "B": def("b"), "C": def("")},
+		},
+		{
+			name: "variables in conditionals and loops",
+			data: `{{ if .A }}{{ .B }}{{ else }}{{ .C }}{{ end }}{{ range split "," .D }}{{ . }}{{ $.E }}{{ end }}`,
+			want: map[string]*string{"A": nil, "B": nil, "C": nil, "D": nil, "E": nil},
+		},
+		{
+			name: "fields of the range elements are not variables",
+			data: `{{ range seq .COUNT }}{{ .Name }}{{ end }}`,
+			want: map[string]*string{"COUNT": nil},
+		},
+		{
+			name: "variables with the same default value",
+			data: `{{ .A | default "a" }} {{ .A | default "a" }}`,
+			want: map[string]*string{"A": def("a")},
+		},
+		{
+			name: "variables used at least once without default value are required",
+			data: `{{ .A | default "a" }} {{ .A }} {{ .B }} {{ .B | default "b" }}`,
+			want: map[string]*string{"A": nil, "B": nil},
+		},
+		{
+			name:    "returns error for variables with conflicting default values",
+			data:    `{{ .A | default "a" }} {{ if .B }}{{ .A | default "b" }}{{ end }}`,
+			wantErr: true,
+		},
+		{
+			name:    "returns error for invalid templates",
+			data:    "yaml with {{ .A ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.GetVariableMap([]byte(tt.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+
+			variables, err := p.GetVariables([]byte(tt.data))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(variables).To(HaveLen(len(tt.want)))
+		})
+	}
+}
+
+func TestGoTemplateProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name                  string
+		yaml                  string
+		configVariablesClient config.VariablesClient
+		want                  string
+		wantErr               bool
+		missingVariables      []string
+	}{
+		{
+			name: "replaces variables",
+			yaml: "foo {{ .BAR }}, {{ .BAR }}",
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("BAR", "bar"),
+			want: "foo bar, bar",
+		},
+		{
+			name: "uses default values if variable doesn't exist in variables client",
+			yaml: `foo {{ .BAR | default "default_bar" }} {{ default "default_baz" .BAZ }} {{ .CAZ | default "default_caz" }}`,
+			configVariablesClient: test.NewFakeVariableClient().
+				// CAZ is set but has no value
+				WithVar("BAR", "bar").WithVar("CAZ", ""),
+			want: "foo bar default_baz default_caz",
+		},
+		{
+			name: "supports conditionals",
+			yaml: `{{ if eq .ENABLED "true" }}enabled{{ else }}disabled{{ end }}`,
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("ENABLED", "true"),
+			want: "enabled",
+		},
+		{
+			name: "supports loops",
+			yaml: "{{ range $i, $zone := split \",\" .ZONES }}- md-{{ $i }}: {{ $zone }}-{{ $.SUFFIX }}\n{{ end }}{{ range seq .COUNT }}x{{ end }}",
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("ZONES", "a,b").WithVar("SUFFIX", "s").WithVar("COUNT", "3"),
+			want: "- md-0: a-s\n- md-1: b-s\nxxx",
+		},
+		{
+			name: "returns error with missing template variables listed (for better ux)",
+			yaml: "foo {{ .BAR }} {{ .BAZ }} {{ .CAR }}",
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("CAR", "car"),
+			wantErr:          true,
+			missingVariables: []string{"BAR", "BAZ"},
+		},
+		{
+			name: "returns error when the template fails to execute",
+			yaml: "{{ range seq .COUNT }}x{{ end }}",
+			configVariablesClient: test.NewFakeVariableClient().
+				WithVar("COUNT", "not-a-number"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.Process([]byte(tt.yaml), tt.configVariablesClient.Get)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				if len(tt.missingVariables) != 0 {
+					e, ok := err.(*errMissingVariables)
+					g.Expect(ok).To(BeTrue())
+					g.Expect(e.Missing).To(ConsistOf(tt.missingVariables))
+				}
+				// we want to ensure that we keep returning the original yaml
+				// as per the intended behavior of Process
+				g.Expect(string(got)).To(Equal(tt.yaml))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
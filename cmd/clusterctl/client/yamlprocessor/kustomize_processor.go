@@ -0,0 +1,251 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind       = "Kustomization"
+)
+
+// KustomizeProcessor is a yaml processor that, after substituting variables like the SimpleProcessor,
+// applies the overlay defined by a Kustomization object in the template to the other objects in the template.
+// Given that templates are single files, patches must be defined inline in the Kustomization; the supported
+// fields are commonLabels, commonAnnotations and patchesJson6902, and any other field is rejected.
+// NOTE: patchesStrategicMerge is not supported, given that most of the objects in a workload cluster template
+// are custom resources without the metadata required to merge lists by key; use patchesJson6902 instead.
+// NOTE: Unlike kustomize build, commonLabels are only added to the metadata of the objects, not to their
+// selectors and templates, given that the label fields of custom resources are not known.
+// See https://kubectl.docs.kubernetes.io/references/kustomize/kustomization/ for more details.
+type KustomizeProcessor struct {
+	simpleProcessor *SimpleProcessor
+}
+
+var _ Processor = &KustomizeProcessor{}
+
+// NewKustomizeProcessor returns a new Kustomize template processor.
+func NewKustomizeProcessor() *KustomizeProcessor {
+	return &KustomizeProcessor{
+		simpleProcessor: NewSimpleProcessor(),
+	}
+}
+
+// kustomization defines the subset of the Kustomization fields supported by the KustomizeProcessor.
+type kustomization struct {
+	APIVersion        string                   `json:"apiVersion"`
+	Kind              string                   `json:"kind"`
+	Metadata          map[string]interface{}   `json:"metadata,omitempty"`
+	CommonLabels      map[string]string        `json:"commonLabels,omitempty"`
+	CommonAnnotations map[string]string        `json:"commonAnnotations,omitempty"`
+	PatchesJSON6902   []kustomizationJSONPatch `json:"patchesJson6902,omitempty"`
+}
+
+// kustomizationJSONPatch defines an inline JSON patch and the objects it applies to.
+type kustomizationJSONPatch struct {
+	Target kustomizationPatchTarget `json:"target"`
+	Patch  string                   `json:"patch"`
+}
+
+// kustomizationPatchTarget selects the objects a JSON patch applies to; empty fields match any value.
+type kustomizationPatchTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (t kustomizationPatchTarget) matches(obj unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return (t.Group == "" || t.Group == gvk.Group) &&
+		(t.Version == "" || t.Version == gvk.Version) &&
+		(t.Kind == "" || t.Kind == gvk.Kind) &&
+		(t.Name == "" || t.Name == obj.GetName()) &&
+		(t.Namespace == "" || t.Namespace == obj.GetNamespace())
+}
+
+// GetTemplateName returns the name of the template that the Kustomize processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *KustomizeProcessor) GetTemplateName(_, flavor string) string {
+	return clusterTemplateName(flavor)
+}
+
+// GetVariables returns a list of the variables specified in the yaml, including the Kustomization.
+func (tp *KustomizeProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	return tp.simpleProcessor.GetVariables(rawArtifact)
+}
+
+// GetVariableMap returns a map of the variables specified in the yaml, including the Kustomization.
+func (tp *KustomizeProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	return tp.simpleProcessor.GetVariableMap(rawArtifact)
+}
+
+// Process returns the final yaml with all the variables replaced with their
+// respective values and the Kustomization applied. If there are variables without
+// corresponding values, it will return the raw yaml along with an error.
+func (tp *KustomizeProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	processed, err := tp.simpleProcessor.Process(rawArtifact, variablesClient)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	objs, err := utilyaml.ToUnstructured(processed)
+	if err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to parse the template")
+	}
+
+	// Splits the Kustomization from the objects it applies to.
+	var k *kustomization
+	resources := make([]unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if obj.GetAPIVersion() != kustomizationAPIVersion || obj.GetKind() != kustomizationKind {
+			resources = append(resources, obj)
+			continue
+		}
+		if k != nil {
+			return rawArtifact, errors.New("the template must contain at most one Kustomization")
+		}
+		k, err = toKustomization(obj)
+		if err != nil {
+			return rawArtifact, err
+		}
+	}
+
+	// If there is no Kustomization, the template is returned as processed by the SimpleProcessor, thus
+	// preserving its formatting.
+	if k == nil {
+		return processed, nil
+	}
+
+	if err := k.apply(resources); err != nil {
+		return rawArtifact, err
+	}
+
+	out, err := utilyaml.FromUnstructured(resources)
+	if err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to generate the yaml")
+	}
+	return out, nil
+}
+
+func toKustomization(obj unstructured.Unstructured) (*kustomization, error) {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the Kustomization")
+	}
+	if _, ok := obj.Object["patchesStrategicMerge"]; ok {
+		return nil, errors.New("patchesStrategicMerge is not supported in the Kustomization, because lists would not be merged by key; use patchesJson6902 instead")
+	}
+
+	// Fields which are not supported are rejected instead of being silently ignored.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	k := &kustomization{}
+	if err := decoder.Decode(k); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the Kustomization")
+	}
+	return k, nil
+}
+
+// apply applies the Kustomization to the objects.
+func (k *kustomization) apply(objs []unstructured.Unstructured) error {
+	for i, p := range k.PatchesJSON6902 {
+		if err := applyJSONPatch(objs, p); err != nil {
+			return errors.Wrapf(err, "failed to apply patchesJson6902[%d]", i)
+		}
+	}
+
+	for i := range objs {
+		if len(k.CommonLabels) > 0 {
+			labels := objs[i].GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			for key, value := range k.CommonLabels {
+				labels[key] = value
+			}
+			objs[i].SetLabels(labels)
+		}
+		if len(k.CommonAnnotations) > 0 {
+			annotations := objs[i].GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for key, value := range k.CommonAnnotations {
+				annotations[key] = value
+			}
+			objs[i].SetAnnotations(annotations)
+		}
+	}
+	return nil
+}
+
+// applyJSONPatch applies a JSON patch to all the objects matching its target.
+func applyJSONPatch(objs []unstructured.Unstructured, p kustomizationJSONPatch) error {
+	patchJSON, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the patch")
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the patch")
+	}
+
+	patched := false
+	for i := range objs {
+		if !p.Target.matches(objs[i]) {
+			continue
+		}
+		if err := patchObject(&objs[i], patch.Apply); err != nil {
+			return err
+		}
+		patched = true
+	}
+	if !patched {
+		return errors.Errorf("failed to find objects matching the patch target %+v", p.Target)
+	}
+	return nil
+}
+
+func patchObject(obj *unstructured.Unstructured, patch func([]byte) ([]byte, error)) error {
+	objJSON, err := json.Marshal(obj.Object)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s %q", obj.GetKind(), obj.GetName())
+	}
+	patchedJSON, err := patch(objJSON)
+	if err != nil {
+		return errors.Wrapf(err, "failed to patch %s %q", obj.GetKind(), obj.GetName())
+	}
+	patched := map[string]interface{}{}
+	if err := json.Unmarshal(patchedJSON, &patched); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s %q", obj.GetKind(), obj.GetName())
+	}
+	obj.Object = patched
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+func TestKustomizeProcessor_GetVariables(t *testing.T) {
+	g := NewWithT(t)
+	p := NewKustomizeProcessor()
+
+	variables, err := p.GetVariables([]byte(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+commonLabels:
+  team: ${TEAM:=dev}
+`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(variables).To(Equal([]string{"CLUSTER_NAME", "TEAM"}))
+
+	variableMap, err := p.GetVariableMap([]byte("${A} ${B:=b}"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(variableMap).To(HaveKeyWithValue("A", BeNil()))
+	g.Expect(variableMap).To(HaveKey("B"))
+	g.Expect(*variableMap["B"]).To(Equal("b"))
+}
+
+func TestKustomizeProcessor_Process(t *testing.T) {
+	resources := `apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: ${CLUSTER_NAME}-md-0
+spec:
+  replicas: 1
+  template:
+    spec:
+      version: v1.22.0
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: ${CLUSTER_NAME}-md-1
+spec:
+  replicas: 1
+`
+
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "returns the template with variables replaced if there is no Kustomization",
+			yaml: resources,
+			want: strings.ReplaceAll(resources, "${CLUSTER_NAME}", "foo"),
+		},
+		{
+			name: "applies the Kustomization",
+			yaml: resources + `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+commonLabels:
+  cluster: ${CLUSTER_NAME}
+commonAnnotations:
+  team: dev
+patchesJson6902:
+- target:
+    kind: MachineDeployment
+    name: ${CLUSTER_NAME}-md-0
+  patch: |
+    - op: replace
+      path: /spec/replicas
+      value: ${WORKER_MACHINE_COUNT}
+- target:
+    group: cluster.x-k8s.io
+    kind: MachineDeployment
+  patch: |
+    - op: add
+      path: /spec/paused
+      value: true
+`,
+			want: `apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  annotations:
+    team: dev
+  labels:
+    cluster: foo
+  name: foo-md-0
+spec:
+  paused: true
+  replicas: 3
+  template:
+    spec:
+      version: v1.22.0
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  annotations:
+    team: dev
+  labels:
+    cluster: foo
+  name: foo-md-1
+spec:
+  paused: true
+  replicas: 1
+`,
+		},
+		{
+			name: "returns error if the JSON patch target does not exist",
+			yaml: resources + `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+patchesJson6902:
+- target:
+    kind: MachineDeployment
+    name: does-not-exist
+  patch: |
+    - op: add
+      path: /spec/paused
+      value: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "returns error if the Kustomization has strategic merge patches",
+			yaml: resources + `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+patchesStrategicMerge:
+- |
+  apiVersion: cluster.x-k8s.io/v1beta1
+  kind: MachineDeployment
+  metadata:
+    name: ${CLUSTER_NAME}-md-0
+  spec:
+    replicas: ${WORKER_MACHINE_COUNT}
+`,
+			wantErr: true,
+		},
+		{
+			name: "returns error if the Kustomization has unsupported fields",
+			yaml: resources + `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: prod-
+`,
+			wantErr: true,
+		},
+		{
+			name: "returns error if there are many Kustomizations",
+			yaml: resources + `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewKustomizeProcessor()
+
+			variablesClient := test.NewFakeVariableClient().
+				WithVar("CLUSTER_NAME", "foo").WithVar("WORKER_MACHINE_COUNT", "3")
+
+			got, err := p.Process([]byte(tt.yaml), variablesClient.Get)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(string(got)).To(Equal(tt.yaml))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			gotObjs, err := utilyaml.ToUnstructured(got)
+			g.Expect(err).NotTo(HaveOccurred())
+			wantObjs, err := utilyaml.ToUnstructured([]byte(tt.want))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotObjs).To(Equal(wantObjs))
+		})
+	}
+}
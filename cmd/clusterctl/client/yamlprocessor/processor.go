@@ -17,6 +17,23 @@ limitations under the License.
 // Package yamlprocessor implements YAML processing.
 package yamlprocessor
 
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SimpleProcessorName is the name of the SimpleProcessor, the default yaml processor.
+	SimpleProcessorName = "simple"
+
+	// GoTemplateProcessorName is the name of the GoTemplateProcessor.
+	GoTemplateProcessorName = "go-template"
+
+	// KustomizeProcessorName is the name of the KustomizeProcessor.
+	KustomizeProcessorName = "kustomize"
+)
+
 // Processor defines the methods necessary for creating a specific yaml
 // processor.
 type Processor interface {
@@ -36,3 +53,29 @@ type Processor interface {
 	// yaml with values retrieved from the values getter
 	Process([]byte, func(string) (string, error)) ([]byte, error)
 }
+
+// NewProcessor returns the yaml processor with the given name; if the name is empty, the SimpleProcessor is returned.
+func NewProcessor(name string) (Processor, error) {
+	switch name {
+	case "", SimpleProcessorName:
+		return NewSimpleProcessor(), nil
+	case GoTemplateProcessorName:
+		return NewGoTemplateProcessor(), nil
+	case KustomizeProcessorName:
+		return NewKustomizeProcessor(), nil
+	default:
+		return nil, errors.Errorf("invalid yaml processor %q. Allowed values are [%s, %s, %s]", name, SimpleProcessorName, GoTemplateProcessorName, KustomizeProcessorName)
+	}
+}
+
+// clusterTemplateName returns the name of a workload cluster template following the naming convention of
+// "cluster-template<-flavor>.yaml".
+func clusterTemplateName(flavor string) string {
+	name := "cluster-template"
+	if flavor != "" {
+		name = fmt.Sprintf("%s-%s", name, flavor)
+	}
+	name = fmt.Sprintf("%s.yaml", name)
+
+	return name
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewProcessor(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Processor
+		wantErr bool
+	}{
+		{name: "", want: NewSimpleProcessor()},
+		{name: SimpleProcessorName, want: NewSimpleProcessor()},
+		{name: GoTemplateProcessorName, want: NewGoTemplateProcessor()},
+		{name: KustomizeProcessorName, want: NewKustomizeProcessor()},
+		{name: "does-not-exist", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := NewProcessor(tt.name)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -43,13 +43,7 @@ func NewSimpleProcessor() *SimpleProcessor {
 // uses. It follows the cluster template naming convention of
 // "cluster-template<-flavor>.yaml".
 func (tp *SimpleProcessor) GetTemplateName(_, flavor string) string {
-	name := "cluster-template"
-	if flavor != "" {
-		name = fmt.Sprintf("%s-%s", name, flavor)
-	}
-	name = fmt.Sprintf("%s.yaml", name)
-
-	return name
+	return clusterTemplateName(flavor)
 }
 
 // GetVariables returns a list of the variables specified in the yaml.
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleExportOptions struct {
+	coreProvider            string
+	bootstrapProviders      []string
+	controlPlaneProviders   []string
+	infrastructureProviders []string
+	flavors                 []string
+	directory               string
+}
+
+var bundleExportOpts = &bundleExportOptions{}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the providers required for initializing a management cluster into a bundle",
+	Long: LongDesc(`
+		Export the components, metadata and workload cluster templates of the providers required
+		for initializing a management cluster, together with the cert-manager manifest, into a bundle.
+
+		The bundle also contains the list of the container images required by the providers and by
+		cert-manager, which should be mirrored into a registry reachable from the air-gapped environment.
+
+		Use clusterctl bundle import for importing the bundle in the air-gapped environment.`),
+
+	Example: Examples(`
+		# Exports the Cluster API core provider, the kubeadm bootstrap and control-plane providers,
+		# the AWS infrastructure provider and cert-manager into the bundle directory.
+		clusterctl bundle export --infrastructure aws --directory bundle
+
+		# Exports specific versions of the providers, including the workload cluster templates
+		# for the machinepool flavor.
+		clusterctl bundle export --core cluster-api:v1.0.0 --infrastructure aws:v1.0.0 --flavor machinepool --directory bundle`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleExport()
+	},
+}
+
+func init() {
+	bundleExportCmd.Flags().StringVar(&bundleExportOpts.coreProvider, "core", "",
+		"Core provider version (e.g. cluster-api:v0.3.0) to add to the bundle. If unspecified, Cluster API's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&bundleExportOpts.infrastructureProviders, "infrastructure", "i", nil,
+		"Infrastructure providers and versions (e.g. aws:v0.5.0) to add to the bundle.")
+	bundleExportCmd.Flags().StringSliceVarP(&bundleExportOpts.bootstrapProviders, "bootstrap", "b", nil,
+		"Bootstrap providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, Kubeadm bootstrap provider's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&bundleExportOpts.controlPlaneProviders, "control-plane", "c", nil,
+		"Control plane providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, the Kubeadm control plane provider's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&bundleExportOpts.flavors, "flavor", "f", nil,
+		"Flavors of the workload cluster templates to add to the bundle, in addition to the default one, for each infrastructure provider.")
+	bundleExportCmd.Flags().StringVar(&bundleExportOpts.directory, "directory", "",
+		"The directory where the bundle is written.")
+}
+
+func runBundleExport() error {
+	if bundleExportOpts.directory == "" {
+		return errors.New("please specify a directory to export the bundle to using the --directory flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.BundleExport(client.BundleExportOptions{
+		CoreProvider:            bundleExportOpts.coreProvider,
+		BootstrapProviders:      bundleExportOpts.bootstrapProviders,
+		ControlPlaneProviders:   bundleExportOpts.controlPlaneProviders,
+		InfrastructureProviders: bundleExportOpts.infrastructureProviders,
+		Flavors:                 bundleExportOpts.flavors,
+		Directory:               bundleExportOpts.directory,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nThe bundle has been exported to %q.\n", bundleExportOpts.directory)
+	fmt.Printf("Mirror the images listed in %q, then import the bundle using:\n\n", client.BundleImagesFile)
+	fmt.Printf("  clusterctl bundle import --directory %s --repository-directory <repository-directory>\n\n", bundleExportOpts.directory)
+	return nil
+}
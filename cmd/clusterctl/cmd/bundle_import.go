@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleImportOptions struct {
+	directory           string
+	repositoryDirectory string
+	imageRepository     string
+	configFile          string
+}
+
+var bundleImportOpts = &bundleImportOptions{}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a bundle for initializing a management cluster in an air-gapped environment",
+	Long: LongDesc(`
+		Import a bundle created with clusterctl bundle export into a local repository, and generate
+		the clusterctl configuration file for initializing a management cluster using it.
+
+		If an image repository is provided, the generated configuration overrides the repository of all
+		the container images, so they are pulled from the registry where the images have been mirrored.`),
+
+	Example: Examples(`
+		# Imports the bundle into a local repository, and overrides the images repository.
+		clusterctl bundle import --directory bundle --repository-directory ~/.cluster-api/overrides \
+		  --image-repository registry.example.com/cluster-api
+
+		# Initializes a management cluster using the imported bundle.
+		clusterctl init --config ~/.cluster-api/overrides/clusterctl.yaml --infrastructure aws`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleImport()
+	},
+}
+
+func init() {
+	bundleImportCmd.Flags().StringVar(&bundleImportOpts.directory, "directory", "",
+		"The directory where the bundle has been exported.")
+	bundleImportCmd.Flags().StringVar(&bundleImportOpts.repositoryDirectory, "repository-directory", "",
+		"The directory where the provider repositories of the bundle are imported.")
+	bundleImportCmd.Flags().StringVar(&bundleImportOpts.imageRepository, "image-repository", "",
+		"The repository where the images of the bundle are mirrored (e.g. registry.example.com/cluster-api). If unspecified, images are not overridden.")
+	bundleImportCmd.Flags().StringVar(&bundleImportOpts.configFile, "config-file", "",
+		"The clusterctl configuration file to generate. If unspecified, clusterctl.yaml in the repository directory is used.")
+}
+
+func runBundleImport() error {
+	if bundleImportOpts.directory == "" || bundleImportOpts.repositoryDirectory == "" {
+		return errors.New("please specify the bundle directory and the directory to import the provider repositories to using the --directory and --repository-directory flags")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	images, err := c.BundleImport(client.BundleImportOptions{
+		Directory:           bundleImportOpts.directory,
+		RepositoryDirectory: bundleImportOpts.repositoryDirectory,
+		ImageRepository:     bundleImportOpts.imageRepository,
+		ConfigFile:          bundleImportOpts.configFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(images) > 0 {
+		fmt.Println("\nThe following images should be available in the air-gapped environment:")
+		fmt.Println("")
+		w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tTARGET")
+		for _, image := range images {
+			fmt.Fprintf(w, "%s\t%s\n", image.Source, image.Target)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	configFile := bundleImportOpts.configFile
+	if configFile == "" {
+		configFile = filepath.Join(bundleImportOpts.repositoryDirectory, client.BundleConfigFile)
+	}
+	fmt.Println("\nYou can now initialize a management cluster using the imported bundle:")
+	fmt.Println("")
+	fmt.Printf("  clusterctl init --config %s\n\n", configFile)
+	return nil
+}
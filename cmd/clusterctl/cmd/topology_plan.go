@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+type topologyPlanOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	files             []string
+	cluster           string
+	namespace         string
+	fromCluster       bool
+	outDir            string
+}
+
+var tp = &topologyPlanOptions{}
+
+var topologyPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "List the changes to clusters that use managed topologies for a given input",
+	Long: LongDesc(`
+		Provide a list of the objects that would be created, modified, rotated or deleted by the topology
+		controller when applying the given input, e.g. a new or modified ClusterClass, its templates, or Clusters.
+
+		The plan is computed against an in-memory copy of the objects, so nothing is changed in the
+		management cluster. By default only the input objects are considered; use --from-cluster to
+		compute the plan on top of the ClusterClasses and Clusters currently existing in the management cluster.`),
+
+	Example: Examples(`
+		# List the changes for the Clusters defined in the input file.
+		clusterctl alpha topology plan -f input.yaml
+
+		# List the changes to the Clusters in the management cluster using a modified ClusterClass.
+		clusterctl alpha topology plan -f modified-clusterclass.yaml --from-cluster
+
+		# Limit the plan to a specific Cluster and write the changed objects and diffs to a directory.
+		clusterctl alpha topology plan -f input.yaml --cluster my-cluster -o output/`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTopologyPlan()
+	},
+}
+
+func init() {
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+
+	topologyPlanCmd.Flags().StringArrayVarP(&tp.files, "file", "f", nil,
+		"Path to the file with the input objects, e.g. ClusterClasses, templates or Clusters.")
+	topologyPlanCmd.Flags().StringVarP(&tp.cluster, "cluster", "c", "",
+		"Name of the Cluster to compute the plan for. If unspecified, the plan is computed for all the Clusters affected by the input.")
+	topologyPlanCmd.Flags().StringVarP(&tp.namespace, "namespace", "n", "",
+		"Namespace for the input objects without a namespace and for the Cluster. If unspecified, the default namespace is used, or the current context's namespace with --from-cluster.")
+	topologyPlanCmd.Flags().BoolVar(&tp.fromCluster, "from-cluster", false,
+		"Compute the plan on top of the objects existing in the management cluster.")
+	topologyPlanCmd.Flags().StringVarP(&tp.outDir, "output-directory", "o", "",
+		"Directory where the created and modified objects and the diffs are written. If unspecified, diffs are printed to stdout.")
+}
+
+func runTopologyPlan() error {
+	if len(tp.files) == 0 {
+		return errors.New("please specify the input objects using the --file flag")
+	}
+
+	objs := []*unstructured.Unstructured{}
+	for _, f := range tp.files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read input file %q", f)
+		}
+		fileObjs, err := utilyaml.ToUnstructured(raw)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse input file %q", f)
+		}
+		for i := range fileObjs {
+			objs = append(objs, &fileObjs[i])
+		}
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	out, err := c.TopologyPlan(client.TopologyPlanOptions{
+		Kubeconfig:  client.Kubeconfig{Path: tp.kubeconfig, Context: tp.kubeconfigContext},
+		Objs:        objs,
+		Cluster:     tp.cluster,
+		Namespace:   tp.namespace,
+		FromCluster: tp.fromCluster,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printTopologyPlanOutput(os.Stdout, out, tp.outDir)
+}
+
+func printTopologyPlanOutput(w io.Writer, out *client.TopologyPlanOutput, outDir string) error {
+	if len(out.Clusters) == 0 {
+		fmt.Fprintln(w, "No Clusters with a managed topology are affected by the input.")
+		return nil
+	}
+
+	for _, plan := range out.Clusters {
+		fmt.Fprintf(w, "Changes for Cluster %q:\n\n", plan.Cluster.String())
+		if !plan.HasChanges() {
+			fmt.Fprintf(w, "No changes detected.\n\n")
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tKIND\tNAME\tACTION")
+		for _, obj := range plan.Created {
+			fmt.Fprintf(tw, "%s\t%s\t%s\tcreated\n", obj.GetNamespace(), obj.GetKind(), obj.GetName())
+		}
+		for _, m := range plan.Modified {
+			fmt.Fprintf(tw, "%s\t%s\t%s\tmodified\n", m.After.GetNamespace(), m.After.GetKind(), m.After.GetName())
+		}
+		for _, r := range plan.Rotated {
+			fmt.Fprintf(tw, "%s\t%s\t%s\trotated (%s)\n", r.New.GetNamespace(), r.New.GetKind(), r.New.GetName(), r.Old.GetName())
+		}
+		for _, obj := range plan.Deleted {
+			fmt.Fprintf(tw, "%s\t%s\t%s\tdeleted\n", obj.GetNamespace(), obj.GetKind(), obj.GetName())
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "")
+
+		if outDir != "" {
+			clusterDir := filepath.Join(outDir, plan.Cluster.Namespace, plan.Cluster.Name)
+			if err := writeTopologyPlan(clusterDir, plan); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "Created and modified objects and diffs have been written to %q.\n\n", clusterDir)
+			continue
+		}
+
+		for _, m := range plan.Modified {
+			fmt.Fprintf(w, "Diff for %s %s/%s:\n%s\n", m.After.GetKind(), m.After.GetNamespace(), m.After.GetName(), m.Diff)
+		}
+		for _, r := range plan.Rotated {
+			fmt.Fprintf(w, "Diff for %s %s/%s (rotated from %s):\n%s\n", r.New.GetKind(), r.New.GetNamespace(), r.New.GetName(), r.Old.GetName(), r.Diff)
+		}
+	}
+	return nil
+}
+
+// writeTopologyPlan writes the objects and diffs of a plan into dir, using a sub-directory for each type of change.
+func writeTopologyPlan(dir string, plan *cluster.ClusterTopologyPlan) error {
+	for _, obj := range plan.Created {
+		if err := writeTopologyPlanObject(filepath.Join(dir, "created"), obj, ""); err != nil {
+			return err
+		}
+	}
+	for _, m := range plan.Modified {
+		if err := writeTopologyPlanObject(filepath.Join(dir, "modified"), m.Before, ".before"); err != nil {
+			return err
+		}
+		if err := writeTopologyPlanObject(filepath.Join(dir, "modified"), m.After, ".after"); err != nil {
+			return err
+		}
+		if err := writeTopologyPlanFile(filepath.Join(dir, "modified"), topologyPlanFileName(m.After, ".diff"), []byte(m.Diff)); err != nil {
+			return err
+		}
+	}
+	for _, r := range plan.Rotated {
+		if err := writeTopologyPlanObject(filepath.Join(dir, "rotated"), r.Old, ".old"); err != nil {
+			return err
+		}
+		if err := writeTopologyPlanObject(filepath.Join(dir, "rotated"), r.New, ".new"); err != nil {
+			return err
+		}
+		if err := writeTopologyPlanFile(filepath.Join(dir, "rotated"), topologyPlanFileName(r.New, ".diff"), []byte(r.Diff)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range plan.Deleted {
+		if err := writeTopologyPlanObject(filepath.Join(dir, "deleted"), obj, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTopologyPlanObject(dir string, obj *unstructured.Unstructured, suffix string) error {
+	raw, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return writeTopologyPlanFile(dir, topologyPlanFileName(obj, suffix+".yaml"), raw)
+}
+
+func writeTopologyPlanFile(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return errors.Wrapf(err, "failed to create directory %q", dir)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write file %q", path)
+	}
+	return nil
+}
+
+func topologyPlanFileName(obj *unstructured.Unstructured, suffix string) string {
+	return fmt.Sprintf("%s_%s_%s%s", strings.ToLower(obj.GetKind()), obj.GetNamespace(), obj.GetName(), suffix)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ValidateTemplateOutputText is an option used to print the template validation errors in text format.
+	ValidateTemplateOutputText = "text"
+	// ValidateTemplateOutputYaml is an option used to print the template validation errors in yaml format.
+	ValidateTemplateOutputYaml = "yaml"
+	// ValidateTemplateOutputJSON is an option used to print the template validation errors in json format.
+	ValidateTemplateOutputJSON = "json"
+)
+
+var (
+	// ValidateTemplateOutputs is a list of valid template validation outputs.
+	ValidateTemplateOutputs = []string{ValidateTemplateOutputText, ValidateTemplateOutputYaml, ValidateTemplateOutputJSON}
+)
+
+type validateTemplateOptions struct {
+	flavor                 string
+	infrastructureProvider string
+	url                    string
+
+	targetNamespace          string
+	kubernetesVersion        string
+	controlPlaneMachineCount int64
+	workerMachineCount       int64
+
+	coreProvider            string
+	bootstrapProviders      []string
+	controlPlaneProviders   []string
+	infrastructureProviders []string
+	componentsFiles         []string
+
+	output string
+}
+
+var vt = &validateTemplateOptions{}
+
+var validateTemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Validate templates for creating workload clusters.",
+	Long: LongDesc(`
+		Validate templates for creating workload clusters.
+
+		The template is rendered like in clusterctl generate cluster, and each object is validated against
+		the OpenAPI schema of the CustomResourceDefinitions in the components YAML of the providers;
+		references across objects in the template, e.g. from a Cluster to its infrastructure and control plane
+		objects, are validated as well.
+
+		Validation does not require a management cluster; the provider components are read from the
+		provider repositories, and additional components YAML files can be provided e.g. when developing
+		a new provider.`),
+
+	Example: Examples(`
+		# Validates the default template of the AWS infrastructure provider.
+		clusterctl alpha validate template my-cluster --infrastructure=aws
+
+		# Validates a template variant of a specific version of the AWS infrastructure provider.
+		clusterctl alpha validate template my-cluster --infrastructure=aws:v0.4.1 --flavor=machinepool
+
+		# Validates a template stored locally, using the CRDs of a provider under development.
+		clusterctl alpha validate template my-cluster --from ~/workspace/cluster-template.yaml \
+			--components-file ~/workspace/infrastructure-components.yaml
+
+		# Validates a template and prints the errors in json format.
+		clusterctl alpha validate template my-cluster --infrastructure=aws -o json`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runValidateTemplate(cmd, args[0], os.Stdout)
+	},
+}
+
+func init() {
+	// flags for the template variables
+	validateTemplateCmd.Flags().StringVarP(&vt.targetNamespace, "target-namespace", "n", "",
+		"The namespace to use for the workload cluster. If unspecified, the default namespace will be used.")
+	validateTemplateCmd.Flags().StringVar(&vt.kubernetesVersion, "kubernetes-version", "",
+		"The Kubernetes version to use for the workload cluster. If unspecified, the value from OS environment variables or the .cluster-api/clusterctl.yaml config file will be used.")
+	validateTemplateCmd.Flags().Int64Var(&vt.controlPlaneMachineCount, "control-plane-machine-count", 1,
+		"The number of control plane machines for the workload cluster.")
+	validateTemplateCmd.Flags().Int64Var(&vt.workerMachineCount, "worker-machine-count", 0,
+		"The number of worker machines for the workload cluster.")
+
+	// flags for the template source
+	validateTemplateCmd.Flags().StringVarP(&vt.infrastructureProvider, "infrastructure", "i", "",
+		"The infrastructure provider to read the workload cluster template from; the CRDs of this provider are used for validating the template.")
+	validateTemplateCmd.Flags().StringVarP(&vt.flavor, "flavor", "f", "",
+		"The workload cluster template variant to be used when reading from the infrastructure provider repository. If unspecified, the default cluster template will be used.")
+	validateTemplateCmd.Flags().StringVar(&vt.url, "from", "",
+		"The URL to read the workload cluster template from. This can be used as alternative to read from the provider repository")
+
+	// flags for the providers used for validating the template
+	validateTemplateCmd.Flags().StringVar(&vt.coreProvider, "core", "",
+		"Core provider version (e.g. cluster-api:v0.3.0) providing the CRDs for validating the template. If unspecified, Cluster API's latest release is used.")
+	validateTemplateCmd.Flags().StringSliceVarP(&vt.bootstrapProviders, "bootstrap", "b", nil,
+		"Bootstrap providers and versions (e.g. kubeadm:v0.3.0) providing the CRDs for validating the template. If unspecified, Kubeadm bootstrap provider's latest release is used.")
+	validateTemplateCmd.Flags().StringSliceVarP(&vt.controlPlaneProviders, "control-plane", "c", nil,
+		"ControlPlane providers and versions (e.g. kubeadm:v0.3.0) providing the CRDs for validating the template. If unspecified, the Kubeadm control plane provider's latest release is used.")
+	validateTemplateCmd.Flags().StringSliceVar(&vt.infrastructureProviders, "infrastructure-crds", nil,
+		"Additional infrastructure providers and versions (e.g. aws:v0.5.0) providing the CRDs for validating the template.")
+	validateTemplateCmd.Flags().StringArrayVar(&vt.componentsFiles, "components-file", nil,
+		"Path to an additional components YAML file providing CRDs for validating the template, e.g. the components of a provider under development.")
+
+	// other flags
+	validateTemplateCmd.Flags().StringVarP(&vt.output, "output", "o", ValidateTemplateOutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", ValidateTemplateOutputs))
+}
+
+func runValidateTemplate(cmd *cobra.Command, name string, out io.Writer) error {
+	if vt.output != ValidateTemplateOutputText && vt.output != ValidateTemplateOutputYaml && vt.output != ValidateTemplateOutputJSON {
+		return errors.Errorf("Invalid output format %q. Valid values: %v.", vt.output, ValidateTemplateOutputs)
+	}
+
+	if vt.url == "" && vt.infrastructureProvider == "" {
+		return errors.New("please specify the template to be validated using the --infrastructure or the --from flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	templateOptions := client.GetClusterTemplateOptions{
+		ClusterName:       name,
+		TargetNamespace:   vt.targetNamespace,
+		KubernetesVersion: vt.kubernetesVersion,
+	}
+
+	if cmd.Flags().Changed("control-plane-machine-count") {
+		templateOptions.ControlPlaneMachineCount = &vt.controlPlaneMachineCount
+	}
+	if cmd.Flags().Changed("worker-machine-count") {
+		templateOptions.WorkerMachineCount = &vt.workerMachineCount
+	}
+
+	if vt.url != "" {
+		templateOptions.URLSource = &client.URLSourceOptions{
+			URL: vt.url,
+		}
+	}
+
+	if vt.infrastructureProvider != "" || vt.flavor != "" {
+		templateOptions.ProviderRepositorySource = &client.ProviderRepositorySourceOptions{
+			InfrastructureProvider: vt.infrastructureProvider,
+			Flavor:                 vt.flavor,
+		}
+	}
+
+	validationErrors, err := c.ValidateTemplate(client.ValidateTemplateOptions{
+		Template:                templateOptions,
+		CoreProvider:            vt.coreProvider,
+		BootstrapProviders:      vt.bootstrapProviders,
+		ControlPlaneProviders:   vt.controlPlaneProviders,
+		InfrastructureProviders: vt.infrastructureProviders,
+		ComponentsFiles:         vt.componentsFiles,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := printTemplateValidationErrors(out, validationErrors, vt.output); err != nil {
+		return err
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.Errorf("template validation failed with %d error(s)", len(validationErrors))
+	}
+	return nil
+}
+
+// printTemplateValidationErrors prints the template validation errors in the given output format.
+func printTemplateValidationErrors(out io.Writer, validationErrors []client.TemplateValidationError, output string) error {
+	switch output {
+	case ValidateTemplateOutputText:
+		if len(validationErrors) == 0 {
+			fmt.Fprintln(out, "The template is valid.")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "KIND\tNAME\tFIELD\tERROR")
+		for _, e := range validationErrors {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Kind, e.Name, e.Field, e.Message)
+		}
+		return w.Flush()
+	case ValidateTemplateOutputYaml:
+		y, err := yaml.Marshal(validationErrors)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, string(y))
+	case ValidateTemplateOutputJSON:
+		j, err := json.MarshalIndent(validationErrors, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(j))
+	}
+	return nil
+}
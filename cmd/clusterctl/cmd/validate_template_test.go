@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+func Test_printTemplateValidationErrors(t *testing.T) {
+	validationErrors := []client.TemplateValidationError{
+		{
+			APIVersion: "cluster.x-k8s.io/v1beta1",
+			Kind:       "Cluster",
+			Name:       "my-cluster",
+			Field:      "spec.infrastructureRef",
+			Message:    "AWSCluster \"my-cluster\" does not exist in the template",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		validationErrors []client.TemplateValidationError
+		output           string
+		want             string
+	}{
+		{
+			name:             "text output without errors",
+			validationErrors: []client.TemplateValidationError{},
+			output:           ValidateTemplateOutputText,
+			want:             "The template is valid.\n",
+		},
+		{
+			name:             "text output",
+			validationErrors: validationErrors,
+			output:           ValidateTemplateOutputText,
+			want: "KIND      NAME         FIELD                    ERROR\n" +
+				"Cluster   my-cluster   spec.infrastructureRef   AWSCluster \"my-cluster\" does not exist in the template\n",
+		},
+		{
+			name:             "yaml output",
+			validationErrors: validationErrors,
+			output:           ValidateTemplateOutputYaml,
+			want: `- apiVersion: cluster.x-k8s.io/v1beta1
+  field: spec.infrastructureRef
+  kind: Cluster
+  message: AWSCluster "my-cluster" does not exist in the template
+  name: my-cluster
+`,
+		},
+		{
+			name:             "json output",
+			validationErrors: validationErrors,
+			output:           ValidateTemplateOutputJSON,
+			want: `[
+  {
+    "apiVersion": "cluster.x-k8s.io/v1beta1",
+    "kind": "Cluster",
+    "name": "my-cluster",
+    "field": "spec.infrastructureRef",
+    "message": "AWSCluster \"my-cluster\" does not exist in the template"
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			buf := bytes.NewBufferString("")
+			g.Expect(printTemplateValidationErrors(buf, tt.validationErrors, tt.output)).To(Succeed())
+			g.Expect(buf.String()).To(Equal(tt.want))
+		})
+	}
+}
@@ -34,9 +34,10 @@ type FakeReader struct {
 // configProvider is a mirror of config.Provider, re-implemented here in order to
 // avoid circular dependencies between pkg/client/config and pkg/internal/test.
 type configProvider struct {
-	Name string                    `json:"name,omitempty"`
-	URL  string                    `json:"url,omitempty"`
-	Type clusterctlv1.ProviderType `json:"type,omitempty"`
+	Name              string                    `json:"name,omitempty"`
+	URL               string                    `json:"url,omitempty"`
+	Type              clusterctlv1.ProviderType `json:"type,omitempty"`
+	TemplateProcessor string                    `json:"templateProcessor,omitempty"`
 }
 
 // configCertManager is a mirror of config.CertManager, re-implemented here in order to
@@ -103,6 +104,20 @@ func (f *FakeReader) WithProvider(name string, ttype clusterctlv1.ProviderType,
 	return f
 }
 
+// WithTemplateProcessor sets the yaml processor for the workload cluster templates of a provider already added with WithProvider.
+func (f *FakeReader) WithTemplateProcessor(name string, ttype clusterctlv1.ProviderType, templateProcessor string) *FakeReader {
+	for i := range f.providers {
+		if f.providers[i].Name == name && f.providers[i].Type == ttype {
+			f.providers[i].TemplateProcessor = templateProcessor
+		}
+	}
+
+	yaml, _ := yaml.Marshal(f.providers)
+	f.variables["providers"] = string(yaml)
+
+	return f
+}
+
 func (f *FakeReader) WithCertManager(url, version, timeout string) *FakeReader {
 	f.certManager = configCertManager{
 		URL:     url,
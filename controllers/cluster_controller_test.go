@@ -18,10 +18,12 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/component-base/featuregate/testing"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,6 +31,7 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -754,3 +757,55 @@ func TestReconcileControlPlaneInitializedControlPlaneRef(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(conditions.Has(c, clusterv1.ControlPlaneInitializedCondition)).To(BeFalse())
 }
+
+func TestClusterReconcilerReconcileDeleteWithTopology(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	tests := []struct {
+		name              string
+		annotations       map[string]string
+		wantFinalizerGone bool
+	}{
+		{
+			name:              "should wait for the BeforeClusterDelete hook if the Cluster is not marked as ok to delete",
+			wantFinalizerGone: false,
+		},
+		{
+			name:              "should delete the Cluster if it is marked as ok to delete",
+			annotations:       map[string]string{runtimev1.OkToDeleteAnnotation: ""},
+			wantFinalizerGone: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "c",
+					Namespace:         "test",
+					Annotations:       tt.annotations,
+					Finalizers:        []string{clusterv1.ClusterFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: clusterv1.ClusterSpec{
+					Topology: &clusterv1.Topology{
+						Class:   "class1",
+						Version: "v1.22.2",
+					},
+				},
+			}
+
+			r := &ClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(cluster).Build(),
+			}
+			_, err := r.reconcileDelete(ctx, cluster)
+			g.Expect(err).ToNot(HaveOccurred())
+			if tt.wantFinalizerGone {
+				g.Expect(cluster.Finalizers).ToNot(ContainElement(clusterv1.ClusterFinalizer))
+			} else {
+				g.Expect(cluster.Finalizers).To(ContainElement(clusterv1.ClusterFinalizer))
+			}
+		})
+	}
+}
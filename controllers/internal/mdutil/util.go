@@ -364,38 +364,108 @@ func getMachineSetFraction(ms clusterv1.MachineSet, d clusterv1.MachineDeploymen
 	return integer.RoundToInt32(newMSsize) - *(ms.Spec.Replicas)
 }
 
-// EqualMachineTemplate returns true if two given machineTemplateSpec are equal,
-// ignoring the diff in value of Labels["machine-template-hash"], and the version from external references.
-func EqualMachineTemplate(template1, template2 *clusterv1.MachineTemplateSpec) bool {
-	t1Copy := template1.DeepCopy()
-	t2Copy := template2.DeepCopy()
+// MachineTemplateUpToDate returns true if the current MachineTemplateSpec is up-to-date with a corresponding desired MachineTemplateSpec.
+// Note: The comparison does not consider any in-place propagated fields, as well as the version from external references.
+func MachineTemplateUpToDate(current, desired *clusterv1.MachineTemplateSpec) bool {
+	currentCopy := MachineTemplateDeepCopyRolloutFields(current)
+	desiredCopy := MachineTemplateDeepCopyRolloutFields(desired)
 
-	// Remove `machine-template-hash` from the comparison:
+	return apiequality.Semantic.DeepEqual(currentCopy, desiredCopy)
+}
+
+// MachineTemplateDeepCopyRolloutFields copies a MachineTemplateSpec
+// and sets all fields that should be propagated in-place to nil and drops version from
+// external references.
+func MachineTemplateDeepCopyRolloutFields(template *clusterv1.MachineTemplateSpec) *clusterv1.MachineTemplateSpec {
+	templateCopy := template.DeepCopy()
+
+	// Drop labels and annotations, they are propagated in-place.
+	// Note: this also drops the `machine-template-hash` label:
 	// 1. The hash result would be different upon machineTemplateSpec API changes
 	//    (e.g. the addition of a new field will cause the hash code to change)
 	// 2. The deployment template won't have hash labels
-	delete(t1Copy.Labels, clusterv1.MachineDeploymentUniqueLabel)
-	delete(t2Copy.Labels, clusterv1.MachineDeploymentUniqueLabel)
+	templateCopy.Labels = nil
+	templateCopy.Annotations = nil
+
+	// Drop node timeout values, they are propagated in-place.
+	templateCopy.Spec.NodeDrainTimeout = nil
+	templateCopy.Spec.NodeVolumeDetachTimeout = nil
+	templateCopy.Spec.NodeDeletionTimeout = nil
 
 	// Remove the version part from the references APIVersion field,
 	// for more details see issue #2183 and #2140.
-	t1Copy.Spec.InfrastructureRef.APIVersion = t1Copy.Spec.InfrastructureRef.GroupVersionKind().Group
-	if t1Copy.Spec.Bootstrap.ConfigRef != nil {
-		t1Copy.Spec.Bootstrap.ConfigRef.APIVersion = t1Copy.Spec.Bootstrap.ConfigRef.GroupVersionKind().Group
+	templateCopy.Spec.InfrastructureRef.APIVersion = templateCopy.Spec.InfrastructureRef.GroupVersionKind().Group
+	if templateCopy.Spec.Bootstrap.ConfigRef != nil {
+		templateCopy.Spec.Bootstrap.ConfigRef.APIVersion = templateCopy.Spec.Bootstrap.ConfigRef.GroupVersionKind().Group
+	}
+
+	return templateCopy
+}
+
+// SyncMachineTemplateInPlaceFields copies all the fields that are propagated in-place, i.e. labels, annotations
+// and node timeouts, from the desired MachineTemplateSpec to the current one; the `machine-template-hash` label of the
+// current MachineTemplateSpec is preserved.
+// It returns true if the current MachineTemplateSpec has been changed.
+func SyncMachineTemplateInPlaceFields(current, desired *clusterv1.MachineTemplateSpec) bool {
+	changed := false
+
+	desiredLabels := map[string]string{}
+	for k, v := range desired.Labels {
+		desiredLabels[k] = v
+	}
+	if hash, ok := current.Labels[clusterv1.MachineDeploymentUniqueLabel]; ok {
+		desiredLabels[clusterv1.MachineDeploymentUniqueLabel] = hash
+	}
+	if !apiequality.Semantic.DeepEqual(current.Labels, desiredLabels) {
+		current.Labels = desiredLabels
+		changed = true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Annotations, desired.Annotations) {
+		current.Annotations = nil
+		if desired.Annotations != nil {
+			current.Annotations = map[string]string{}
+			for k, v := range desired.Annotations {
+				current.Annotations[k] = v
+			}
+		}
+		changed = true
+	}
+
+	if SyncMachineSpecNodeTimeouts(&current.Spec, &desired.Spec) {
+		changed = true
+	}
+
+	return changed
+}
+
+// SyncMachineSpecNodeTimeouts copies the node timeouts, i.e. nodeDrainTimeout, nodeVolumeDetachTimeout and
+// nodeDeletionTimeout, from the desired MachineSpec to the current one.
+// It returns true if the current MachineSpec has been changed.
+func SyncMachineSpecNodeTimeouts(current, desired *clusterv1.MachineSpec) bool {
+	changed := false
+
+	if !apiequality.Semantic.DeepEqual(current.NodeDrainTimeout, desired.NodeDrainTimeout) {
+		current.NodeDrainTimeout = desired.NodeDrainTimeout.DeepCopy()
+		changed = true
+	}
+	if !apiequality.Semantic.DeepEqual(current.NodeVolumeDetachTimeout, desired.NodeVolumeDetachTimeout) {
+		current.NodeVolumeDetachTimeout = desired.NodeVolumeDetachTimeout.DeepCopy()
+		changed = true
 	}
-	t2Copy.Spec.InfrastructureRef.APIVersion = t2Copy.Spec.InfrastructureRef.GroupVersionKind().Group
-	if t2Copy.Spec.Bootstrap.ConfigRef != nil {
-		t2Copy.Spec.Bootstrap.ConfigRef.APIVersion = t2Copy.Spec.Bootstrap.ConfigRef.GroupVersionKind().Group
+	if !apiequality.Semantic.DeepEqual(current.NodeDeletionTimeout, desired.NodeDeletionTimeout) {
+		current.NodeDeletionTimeout = desired.NodeDeletionTimeout.DeepCopy()
+		changed = true
 	}
 
-	return apiequality.Semantic.DeepEqual(t1Copy, t2Copy)
+	return changed
 }
 
 // FindNewMachineSet returns the new MS this given deployment targets (the one with the same machine template).
 func FindNewMachineSet(deployment *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) *clusterv1.MachineSet {
 	sort.Sort(MachineSetsByCreationTimestamp(msList))
 	for i := range msList {
-		if EqualMachineTemplate(&msList[i].Spec.Template, &deployment.Spec.Template) {
+		if MachineTemplateUpToDate(&msList[i].Spec.Template, &deployment.Spec.Template) {
 			// In rare cases, such as after cluster upgrades, Deployment may end up with
 			// having more than one new MachineSets that have the same template,
 			// see https://github.com/kubernetes/kubernetes/issues/40415
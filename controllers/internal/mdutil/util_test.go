@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/klog/v2/klogr"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -97,7 +98,7 @@ func generateMachineTemplateSpec(annotations, labels map[string]string) clusterv
 	}
 }
 
-func TestEqualMachineTemplate(t *testing.T) {
+func TestMachineTemplateUpToDate(t *testing.T) {
 	tests := []struct {
 		Name           string
 		Former, Latter clusterv1.MachineTemplateSpec
@@ -131,42 +132,90 @@ func TestEqualMachineTemplate(t *testing.T) {
 			Name:     "Same spec, the label is different, the former doesn't have machine-template-hash label, same number of labels",
 			Former:   generateMachineTemplateSpec(map[string]string{}, map[string]string{"something": "else"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-2"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
 			Name:     "Same spec, the label is different, the latter doesn't have machine-template-hash label, same number of labels",
 			Former:   generateMachineTemplateSpec(map[string]string{}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{}, map[string]string{"something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
 			Name:     "Same spec, the label is different, and the machine-template-hash label value is the same",
 			Former:   generateMachineTemplateSpec(map[string]string{}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
-			Name:     "Different spec, same labels",
+			Name:     "Different annotations, same labels",
 			Former:   generateMachineTemplateSpec(map[string]string{"former": "value"}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1", "something": "else"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{"latter": "value"}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
-			Name:     "Different spec, different machine-template-hash label value",
+			Name:     "Different annotations, different machine-template-hash label value",
 			Former:   generateMachineTemplateSpec(map[string]string{"x": ""}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-1", "something": "else"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{"x": "1"}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-2", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
-			Name:     "Different spec, the former doesn't have machine-template-hash label",
+			Name:     "Different annotations, the former doesn't have machine-template-hash label",
 			Former:   generateMachineTemplateSpec(map[string]string{"x": ""}, map[string]string{"something": "else"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{"x": "1"}, map[string]string{clusterv1.MachineDeploymentUniqueLabel: "value-2", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
-			Name:     "Different spec, different labels",
+			Name:     "Same spec, different labels",
 			Former:   generateMachineTemplateSpec(map[string]string{}, map[string]string{"something": "else"}),
 			Latter:   generateMachineTemplateSpec(map[string]string{}, map[string]string{"nothing": "else"}),
+			Expected: true,
+		},
+		{
+			Name:     "Same spec, different annotations",
+			Former:   generateMachineTemplateSpec(map[string]string{"former": "value"}, map[string]string{}),
+			Latter:   generateMachineTemplateSpec(map[string]string{"latter": "value"}, map[string]string{}),
+			Expected: true,
+		},
+		{
+			Name: "Same spec, except for node timeouts",
+			Former: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					NodeDrainTimeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			Latter: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					NodeDrainTimeout:        &metav1.Duration{Duration: 20 * time.Second},
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: 30 * time.Second},
+					NodeDeletionTimeout:     &metav1.Duration{Duration: 40 * time.Second},
+				},
+			},
+			Expected: true,
+		},
+		{
+			Name: "Different spec, different versions",
+			Former: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version: pointer.String("v1.21.2"),
+				},
+			},
+			Latter: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version: pointer.String("v1.22.0"),
+				},
+			},
 			Expected: false,
 		},
 		{
@@ -253,7 +302,7 @@ func TestEqualMachineTemplate(t *testing.T) {
 
 			runTest := func(t1, t2 *clusterv1.MachineTemplateSpec) {
 				// Run
-				equal := EqualMachineTemplate(t1, t2)
+				equal := MachineTemplateUpToDate(t1, t2)
 				g.Expect(equal).To(Equal(test.Expected))
 				g.Expect(t1.Labels).NotTo(BeNil())
 				g.Expect(t2.Labels).NotTo(BeNil())
@@ -266,6 +315,107 @@ func TestEqualMachineTemplate(t *testing.T) {
 	}
 }
 
+func TestSyncMachineTemplateInPlaceFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         clusterv1.MachineTemplateSpec
+		desired         clusterv1.MachineTemplateSpec
+		expected        clusterv1.MachineTemplateSpec
+		expectedChanged bool
+	}{
+		{
+			name: "No changes",
+			current: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "bar", clusterv1.MachineDeploymentUniqueLabel: "hash"},
+					Annotations: map[string]string{"a": "b"},
+				},
+				Spec: clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: 10 * time.Second}},
+			},
+			desired: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "bar"},
+					Annotations: map[string]string{"a": "b"},
+				},
+				Spec: clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: 10 * time.Second}},
+			},
+			expected: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "bar", clusterv1.MachineDeploymentUniqueLabel: "hash"},
+					Annotations: map[string]string{"a": "b"},
+				},
+				Spec: clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: 10 * time.Second}},
+			},
+			expectedChanged: false,
+		},
+		{
+			name: "Labels and annotations are replaced, the machine-template-hash label is preserved",
+			current: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "bar", "removed": "", clusterv1.MachineDeploymentUniqueLabel: "hash"},
+					Annotations: map[string]string{"a": "b"},
+				},
+			},
+			desired: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "baz"},
+					Annotations: map[string]string{"c": "d"},
+				},
+			},
+			expected: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"foo": "baz", clusterv1.MachineDeploymentUniqueLabel: "hash"},
+					Annotations: map[string]string{"c": "d"},
+				},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "Node timeouts are updated, other spec fields are left untouched",
+			current: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version:          pointer.String("v1.21.2"),
+					NodeDrainTimeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			desired: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version:                 pointer.String("v1.22.0"),
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: 20 * time.Second},
+					NodeDeletionTimeout:     &metav1.Duration{Duration: 30 * time.Second},
+				},
+			},
+			expected: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version:                 pointer.String("v1.21.2"),
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: 20 * time.Second},
+					NodeDeletionTimeout:     &metav1.Duration{Duration: 30 * time.Second},
+				},
+			},
+			expectedChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			changed := SyncMachineTemplateInPlaceFields(&tt.current, &tt.desired)
+			g.Expect(changed).To(Equal(tt.expectedChanged))
+			g.Expect(tt.current).To(Equal(tt.expected))
+		})
+	}
+}
+
 func TestFindNewMachineSet(t *testing.T) {
 	now := metav1.Now()
 	later := metav1.Time{Time: now.Add(time.Minute)}
@@ -281,9 +431,7 @@ func TestFindNewMachineSet(t *testing.T) {
 
 	oldDeployment := generateDeployment("nginx")
 	oldMS := generateMS(oldDeployment)
-	oldMS.Spec.Template.Annotations = map[string]string{
-		"old": "true",
-	}
+	oldMS.Spec.Template.Spec.Version = pointer.String("v1.20.0")
 	oldMS.Status.FullyLabeledReplicas = *(oldMS.Spec.Replicas)
 
 	tests := []struct {
@@ -339,9 +487,7 @@ func TestFindOldMachineSets(t *testing.T) {
 
 	oldDeployment := generateDeployment("nginx")
 	oldMS := generateMS(oldDeployment)
-	oldMS.Spec.Template.Annotations = map[string]string{
-		"old": "true",
-	}
+	oldMS.Spec.Template.Spec.Version = pointer.String("v1.20.0")
 	oldMS.Status.FullyLabeledReplicas = *(oldMS.Spec.Replicas)
 	oldMS.CreationTimestamp = before
 
@@ -388,11 +534,11 @@ func TestFindOldMachineSets(t *testing.T) {
 			expectedRequire: nil,
 		},
 		{
-			Name:            "Get old MachineSets after label changed in MachineDeployments",
+			Name:            "Get old MachineSets after only labels changed in MachineDeployments, the oldest MachineSet is seen as new MachineSet",
 			deployment:      deployment,
 			msList:          []*clusterv1.MachineSet{&newMS, &oldMSwithOldLabel},
-			expected:        []*clusterv1.MachineSet{&oldMSwithOldLabel},
-			expectedRequire: nil,
+			expected:        []*clusterv1.MachineSet{&newMS},
+			expectedRequire: []*clusterv1.MachineSet{&newMS},
 		},
 	}
 
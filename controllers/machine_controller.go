@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -247,6 +248,7 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 
 	phases := []func(context.Context, *clusterv1.Cluster, *clusterv1.Machine) (ctrl.Result, error){
 		r.reconcileBootstrap,
+		r.reconcileCertificateExpiry,
 		r.reconcileInfrastructure,
 		r.reconcileNode,
 		r.reconcileInterruptibleNodeLabel,
@@ -320,8 +322,14 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 
 			conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
 			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+		}
 
-			// After node draining, make sure volumes are detached before deleting the Node.
+		// After node draining is completed or skipped, and if isNodeVolumeDetachingAllowed returns true, make sure all
+		// volumes are detached before proceeding to delete the Node.
+		if r.isNodeVolumeDetachingAllowed(m) {
+			// The VolumeDetachSucceededCondition never exists before we wait for volume detachment for the first time,
+			// so its transition time can be used to record the first time we wait for volume detachment.
+			// This `if` condition prevents the transition time to be changed more than once.
 			if conditions.Get(m, clusterv1.VolumeDetachSucceededCondition) == nil {
 				conditions.MarkFalse(m, clusterv1.VolumeDetachSucceededCondition, clusterv1.WaitingForVolumeDetachReason, clusterv1.ConditionSeverityInfo, "Waiting for node volumes to be detached")
 			}
@@ -331,7 +339,8 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 					return ctrl.Result{}, err
 				}
 				log.Info("Waiting for node volumes to be detached", "node", m.Status.NodeRef.Name)
-				return ctrl.Result{}, nil
+				// VolumeAttachments are not watched, so make sure we check again even if the Node does not change.
+				return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
 			}
 			conditions.MarkTrue(m, clusterv1.VolumeDetachSucceededCondition)
 			r.recorder.Eventf(m, corev1.EventTypeNormal, "NodeVolumesDetached", "success waiting for node volumes detach Machine's node %q", m.Status.NodeRef.Name)
@@ -380,9 +389,15 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			return true, nil
 		})
 		if waitErr != nil {
-			log.Error(deleteNodeErr, "Timed out deleting node, moving on", "node", m.Status.NodeRef.Name)
+			log.Error(deleteNodeErr, "Timed out deleting node", "node", m.Status.NodeRef.Name)
 			conditions.MarkFalse(m, clusterv1.MachineNodeHealthyCondition, clusterv1.DeletionFailedReason, clusterv1.ConditionSeverityWarning, "")
 			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+
+			// If the node deletion timeout is not expired yet, requeue the Machine for reconciliation.
+			if !r.nodeDeletionTimeoutExceeded(m) {
+				return ctrl.Result{}, deleteNodeErr
+			}
+			log.Info("Node deletion timeout expired, continuing without Node deletion", "node", m.Status.NodeRef.Name)
 		}
 	}
 
@@ -419,6 +434,43 @@ func (r *MachineReconciler) nodeDrainTimeoutExceeded(machine *clusterv1.Machine)
 	return diff.Seconds() >= machine.Spec.NodeDrainTimeout.Seconds()
 }
 
+// isNodeVolumeDetachingAllowed returns false if the nodeVolumeDetachTimeout is exceeded, otherwise returns true.
+func (r *MachineReconciler) isNodeVolumeDetachingAllowed(m *clusterv1.Machine) bool {
+	return !r.nodeVolumeDetachTimeoutExceeded(m)
+}
+
+func (r *MachineReconciler) nodeVolumeDetachTimeoutExceeded(machine *clusterv1.Machine) bool {
+	// if the NodeVolumeDetachTimeout type is not set by user
+	if machine.Spec.NodeVolumeDetachTimeout == nil || machine.Spec.NodeVolumeDetachTimeout.Seconds() <= 0 {
+		return false
+	}
+
+	// if the volume detaching succeeded condition does not exist
+	if conditions.Get(machine, clusterv1.VolumeDetachSucceededCondition) == nil {
+		return false
+	}
+
+	now := time.Now()
+	firstTimeDetach := conditions.GetLastTransitionTime(machine, clusterv1.VolumeDetachSucceededCondition)
+	diff := now.Sub(firstTimeDetach.Time)
+	return diff.Seconds() >= machine.Spec.NodeVolumeDetachTimeout.Seconds()
+}
+
+// nodeDeletionTimeoutExceeded returns true if the time elapsed since the Machine has been marked
+// for deletion exceeds the nodeDeletionTimeout; a nil or zero timeout never expires.
+func (r *MachineReconciler) nodeDeletionTimeoutExceeded(machine *clusterv1.Machine) bool {
+	if machine.Spec.NodeDeletionTimeout == nil || machine.Spec.NodeDeletionTimeout.Seconds() <= 0 {
+		return false
+	}
+
+	if machine.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	diff := time.Since(machine.DeletionTimestamp.Time)
+	return diff.Seconds() >= machine.Spec.NodeDeletionTimeout.Seconds()
+}
+
 // isDeleteNodeAllowed returns nil only if the Machine's NodeRef is not nil
 // and if the Machine is not the last control plane node in the cluster.
 func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
@@ -548,7 +600,8 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 	return ctrl.Result{}, nil
 }
 
-// shouldWaitForNodeVolumes returns true if node status still have volumes attached
+// shouldWaitForNodeVolumes returns true if node status still have volumes attached or if there are
+// VolumeAttachments referencing the node in the workload cluster.
 // pod deletion and volume detach happen asynchronously, so pod could be deleted before volume detached from the node
 // this could cause issue for some storage provisioner, for example, vsphere-volume this is problematic
 // because if the node is deleted before detach success, then the underline VMDK will be deleted together with the Machine
@@ -570,7 +623,22 @@ func (r *MachineReconciler) shouldWaitForNodeVolumes(ctx context.Context, cluste
 		return true, err
 	}
 
-	return len(node.Status.VolumesAttached) != 0, nil
+	if len(node.Status.VolumesAttached) != 0 {
+		return true, nil
+	}
+
+	// CSI drivers track attachments using VolumeAttachments, which can outlive the entries in the Node status.
+	volumeAttachments := &storagev1.VolumeAttachmentList{}
+	if err := remoteClient.List(ctx, volumeAttachments); err != nil {
+		return true, errors.Wrapf(err, "failed to list VolumeAttachments")
+	}
+	for _, va := range volumeAttachments.Items {
+		if va.Spec.NodeName == nodeName {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.Cluster, name string) error {
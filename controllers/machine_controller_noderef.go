@@ -19,10 +19,12 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/api/v1beta1/index"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
@@ -108,9 +110,16 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 		desired[clusterv1.OwnerKindAnnotation] = owner.Kind
 		desired[clusterv1.OwnerNameAnnotation] = owner.Name
 	}
-	if annotations.AddAnnotations(node, desired) {
+
+	// Reconcile the labels propagated in place from the Machine to the Node, keeping track of them
+	// in an annotation so labels removed from the Machine are removed from the Node too.
+	nodeLabels := getManagedLabels(machine.Labels)
+	labelsChanged := syncNodeLabels(node, nodeLabels)
+	desired[clusterv1.LabelsFromMachineAnnotation] = strings.Join(sets.StringKeySet(nodeLabels).List(), ",")
+
+	if annotationsChanged := annotations.AddAnnotations(node, desired); annotationsChanged || labelsChanged {
 		if err := patchHelper.Patch(ctx, node); err != nil {
-			log.V(2).Info("Failed patch node to set annotations", "err", err, "node name", node.Name)
+			log.V(2).Info("Failed patch node to set annotations and labels", "err", err, "node name", node.Name)
 			return ctrl.Result{}, err
 		}
 	}
@@ -130,6 +139,57 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 	return ctrl.Result{}, nil
 }
 
+// getManagedLabels gets a map[string]string and returns another map[string]string
+// filtering out labels not managed by CAPI, i.e. the labels that are propagated from Machines to Nodes.
+// Managed labels are the ones with a key prefixed by node-role.kubernetes.io, or belonging to the
+// node-restriction.kubernetes.io or node.cluster.x-k8s.io domains (including sub-domains).
+func getManagedLabels(labels map[string]string) map[string]string {
+	managedLabels := make(map[string]string)
+	for key, value := range labels {
+		dnsSubdomainOrName := strings.Split(key, "/")[0]
+		if dnsSubdomainOrName == clusterv1.NodeRoleLabelPrefix {
+			managedLabels[key] = value
+		}
+		if dnsSubdomainOrName == clusterv1.NodeRestrictionLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+clusterv1.NodeRestrictionLabelDomain) {
+			managedLabels[key] = value
+		}
+		if dnsSubdomainOrName == clusterv1.ManagedNodeLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+clusterv1.ManagedNodeLabelDomain) {
+			managedLabels[key] = value
+		}
+	}
+	return managedLabels
+}
+
+// syncNodeLabels sets the given labels on the Node and removes the labels previously propagated from the Machine
+// which are not present anymore, as tracked by the LabelsFromMachineAnnotation.
+// It returns true if the Node labels have been changed.
+func syncNodeLabels(node *corev1.Node, labels map[string]string) bool {
+	changed := false
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+
+	if previous, ok := node.Annotations[clusterv1.LabelsFromMachineAnnotation]; ok && previous != "" {
+		for _, key := range strings.Split(previous, ",") {
+			if _, ok := labels[key]; ok {
+				continue
+			}
+			if _, ok := node.Labels[key]; ok {
+				delete(node.Labels, key)
+				changed = true
+			}
+		}
+	}
+
+	for key, value := range labels {
+		if current, ok := node.Labels[key]; !ok || current != value {
+			node.Labels[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
 // summarizeNodeConditions summarizes a Node's conditions and returns the summary of condition statuses and concatenate failed condition messages:
 // if there is at least 1 semantically-negative condition, summarized status = False;
 // if there is at least 1 semantically-positive condition when there is 0 semantically negative condition, summarized status = True;
@@ -226,3 +226,120 @@ func TestSummarizeNodeConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestGetManagedLabels(t *testing.T) {
+	// Create managedLabels map from known managed prefixes.
+	managedLabels := map[string]string{
+		clusterv1.NodeRoleLabelPrefix + "/anyRole": "",
+
+		clusterv1.ManagedNodeLabelDomain:                                  "",
+		"custom-prefix." + clusterv1.ManagedNodeLabelDomain:               "",
+		"custom-prefix." + clusterv1.ManagedNodeLabelDomain + "/anything": "",
+		clusterv1.ManagedNodeLabelDomain + "/anything":                    "",
+
+		clusterv1.NodeRestrictionLabelDomain:                                  "",
+		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain:               "",
+		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain + "/anything": "",
+		clusterv1.NodeRestrictionLabelDomain + "/anything":                    "",
+	}
+
+	// Append arbitrary labels.
+	allLabels := map[string]string{
+		"foo":                               "",
+		"bar":                               "",
+		"company.xyz/node.cluster.x-k8s.io": "not-managed",
+		"gpu-node.cluster.x-k8s.io":         "not-managed",
+		"company.xyz/node-restriction.kubernetes.io": "not-managed",
+		"gpu-node-restriction.kubernetes.io":         "not-managed",
+	}
+	for k, v := range managedLabels {
+		allLabels[k] = v
+	}
+
+	g := NewWithT(t)
+	got := getManagedLabels(allLabels)
+	g.Expect(got).To(BeEquivalentTo(managedLabels))
+}
+
+func TestSyncNodeLabels(t *testing.T) {
+	tests := []struct {
+		name            string
+		node            *corev1.Node
+		labels          map[string]string
+		expectedLabels  map[string]string
+		expectedChanged bool
+	}{
+		{
+			name: "Adds and updates labels",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"node-role.kubernetes.io/worker": "old",
+						"kubernetes.io/hostname":         "node-1",
+					},
+				},
+			},
+			labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"node.cluster.x-k8s.io/gpu":      "true",
+			},
+			expectedLabels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"node.cluster.x-k8s.io/gpu":      "true",
+				"kubernetes.io/hostname":         "node-1",
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "Removes labels previously propagated from the Machine",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						clusterv1.LabelsFromMachineAnnotation: "node-role.kubernetes.io/worker,node.cluster.x-k8s.io/gpu",
+					},
+					Labels: map[string]string{
+						"node-role.kubernetes.io/worker": "",
+						"node.cluster.x-k8s.io/gpu":      "true",
+						"node.cluster.x-k8s.io/other":    "set-by-others",
+					},
+				},
+			},
+			labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+			},
+			expectedLabels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"node.cluster.x-k8s.io/other":    "set-by-others",
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "No changes",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						clusterv1.LabelsFromMachineAnnotation: "node-role.kubernetes.io/worker",
+					},
+					Labels: map[string]string{
+						"node-role.kubernetes.io/worker": "",
+					},
+				},
+			},
+			labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+			},
+			expectedLabels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+			},
+			expectedChanged: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(syncNodeLabels(tt.node, tt.labels)).To(Equal(tt.expectedChanged))
+			g.Expect(tt.node.Labels).To(Equal(tt.expectedLabels))
+		})
+	}
+}
@@ -234,6 +234,38 @@ func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clu
 	return ctrl.Result{}, nil
 }
 
+// reconcileCertificateExpiry records the expiry date of the certificates of a control plane Machine.
+// The expiry date is read from the MachineCertificatesExpiryDateAnnotation on the Machine, or on its bootstrap config.
+func (r *MachineReconciler) reconcileCertificateExpiry(ctx context.Context, _ *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	if !util.IsControlPlaneMachine(m) {
+		return ctrl.Result{}, nil
+	}
+
+	// The annotation on the Machine takes precedence over the annotation on the bootstrap config.
+	expiry, ok := m.GetAnnotations()[clusterv1.MachineCertificatesExpiryDateAnnotation]
+	if !ok && m.Spec.Bootstrap.ConfigRef != nil {
+		bootstrapConfig, err := external.Get(ctx, r.Client, m.Spec.Bootstrap.ConfigRef, m.Namespace)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to get bootstrap config for Machine %q in namespace %q", m.Name, m.Namespace)
+		}
+		if err == nil {
+			expiry, ok = bootstrapConfig.GetAnnotations()[clusterv1.MachineCertificatesExpiryDateAnnotation]
+		}
+	}
+
+	if !ok {
+		m.Status.CertificatesExpiryDate = nil
+		return ctrl.Result{}, nil
+	}
+
+	expiryTime, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to parse the certificates expiry date of Machine %q in namespace %q", m.Name, m.Namespace)
+	}
+	m.Status.CertificatesExpiryDate = &metav1.Time{Time: expiryTime}
+	return ctrl.Result{}, nil
+}
+
 // reconcileInfrastructure reconciles the Spec.InfrastructureRef object on a Machine.
 func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
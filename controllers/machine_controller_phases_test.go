@@ -888,6 +888,105 @@ func TestReconcileBootstrap(t *testing.T) {
 	}
 }
 
+func TestReconcileCertificateExpiry(t *testing.T) {
+	fooTime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	barTime := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	bootstrapConfigWithExpiry := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "GenericBootstrapConfig",
+		"apiVersion": "bootstrap.cluster.x-k8s.io/v1beta1",
+		"metadata": map[string]interface{}{
+			"name":      "bootstrap-config-with-expiry",
+			"namespace": metav1.NamespaceDefault,
+			"annotations": map[string]interface{}{
+				clusterv1.MachineCertificatesExpiryDateAnnotation: barTime.Format(time.RFC3339),
+			},
+		},
+	}}
+	bootstrapConfigWithoutExpiry := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "GenericBootstrapConfig",
+		"apiVersion": "bootstrap.cluster.x-k8s.io/v1beta1",
+		"metadata": map[string]interface{}{
+			"name":      "bootstrap-config-without-expiry",
+			"namespace": metav1.NamespaceDefault,
+		},
+	}}
+	machine := func(controlPlane bool, annotations map[string]string, bootstrapConfig *unstructured.Unstructured) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "machine-test",
+				Namespace:   metav1.NamespaceDefault,
+				Labels:      map[string]string{},
+				Annotations: annotations,
+			},
+			Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{
+					ConfigRef: &corev1.ObjectReference{
+						APIVersion: bootstrapConfig.GetAPIVersion(),
+						Kind:       bootstrapConfig.GetKind(),
+						Name:       bootstrapConfig.GetName(),
+					},
+				},
+			},
+		}
+		if controlPlane {
+			m.Labels[clusterv1.MachineControlPlaneLabelName] = ""
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		machine  *clusterv1.Machine
+		expected *metav1.Time
+	}{
+		{
+			name:     "worker machine with certificates expiry annotation should not be updated",
+			machine:  machine(false, map[string]string{clusterv1.MachineCertificatesExpiryDateAnnotation: fooTime.Format(time.RFC3339)}, bootstrapConfigWithExpiry),
+			expected: nil,
+		},
+		{
+			name:     "control plane machine with certificates expiry annotation should take precedence",
+			machine:  machine(true, map[string]string{clusterv1.MachineCertificatesExpiryDateAnnotation: fooTime.Format(time.RFC3339)}, bootstrapConfigWithExpiry),
+			expected: &metav1.Time{Time: fooTime},
+		},
+		{
+			name:     "control plane machine with certificates expiry annotation on the bootstrap config",
+			machine:  machine(true, nil, bootstrapConfigWithExpiry),
+			expected: &metav1.Time{Time: barTime},
+		},
+		{
+			name:     "control plane machine without certificates expiry annotation",
+			machine:  machine(true, nil, bootstrapConfigWithoutExpiry),
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineReconciler{
+				Client: fake.NewClientBuilder().
+					WithObjects(
+						tt.machine,
+						builder.GenericBootstrapConfigCRD.DeepCopy(),
+						bootstrapConfigWithExpiry.DeepCopy(),
+						bootstrapConfigWithoutExpiry.DeepCopy(),
+					).Build(),
+			}
+
+			_, err := r.reconcileCertificateExpiry(ctx, nil, tt.machine)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.expected == nil {
+				g.Expect(tt.machine.Status.CertificatesExpiryDate).To(BeNil())
+			} else {
+				g.Expect(tt.machine.Status.CertificatesExpiryDate).NotTo(BeNil())
+				g.Expect(tt.machine.Status.CertificatesExpiryDate.Time.Equal(tt.expected.Time)).To(BeTrue())
+			}
+		})
+	}
+}
+
 func TestReconcileInfrastructure(t *testing.T) {
 	defaultMachine := clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
@@ -22,10 +22,12 @@ import (
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -1230,6 +1232,303 @@ func TestIsNodeDrainedAllowed(t *testing.T) {
 	}
 }
 
+func TestIsNodeVolumeDetachingAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		machine  *clusterv1.Machine
+		expected bool
+	}{
+		{
+			name: "Node volume detach timeout is over",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:             "test-cluster",
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 30},
+				},
+				Status: clusterv1.MachineStatus{
+					Conditions: clusterv1.Conditions{
+						{
+							Type:               clusterv1.VolumeDetachSucceededCondition,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: metav1.Time{Time: time.Now().Add(-(time.Second * 60)).UTC()},
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Node volume detach timeout is not yet over",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:             "test-cluster",
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 60},
+				},
+				Status: clusterv1.MachineStatus{
+					Conditions: clusterv1.Conditions{
+						{
+							Type:               clusterv1.VolumeDetachSucceededCondition,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: metav1.Time{Time: time.Now().Add(-(time.Second * 30)).UTC()},
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "Node volume detach has not started yet",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:             "test-cluster",
+					NodeVolumeDetachTimeout: &metav1.Duration{Duration: time.Second * 60},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "NodeVolumeDetachTimeout option is set to its default value 0",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+				},
+				Status: clusterv1.MachineStatus{
+					Conditions: clusterv1.Conditions{
+						{
+							Type:               clusterv1.VolumeDetachSucceededCondition,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: metav1.Time{Time: time.Now().Add(-(time.Second * 1000)).UTC()},
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineReconciler{}
+
+			got := r.isNodeVolumeDetachingAllowed(tt.machine)
+			g.Expect(got).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestNodeDeletionTimeoutExceeded(t *testing.T) {
+	tests := []struct {
+		name                string
+		deletionTimestamp   *metav1.Time
+		nodeDeletionTimeout *metav1.Duration
+		expected            bool
+	}{
+		{
+			name:                "Node deletion timeout is over",
+			deletionTimestamp:   &metav1.Time{Time: time.Now().Add(-(time.Second * 60))},
+			nodeDeletionTimeout: &metav1.Duration{Duration: time.Second * 10},
+			expected:            true,
+		},
+		{
+			name:                "Node deletion timeout is not yet over",
+			deletionTimestamp:   &metav1.Time{Time: time.Now().Add(-(time.Second * 5))},
+			nodeDeletionTimeout: &metav1.Duration{Duration: time.Second * 60},
+			expected:            false,
+		},
+		{
+			name:                "NodeDeletionTimeout set to 0 retries indefinitely",
+			deletionTimestamp:   &metav1.Time{Time: time.Now().Add(-(time.Hour * 24))},
+			nodeDeletionTimeout: &metav1.Duration{},
+			expected:            false,
+		},
+		{
+			name:              "NodeDeletionTimeout not set retries indefinitely",
+			deletionTimestamp: &metav1.Time{Time: time.Now().Add(-(time.Hour * 24))},
+			expected:          false,
+		},
+		{
+			name:                "Machine is not being deleted",
+			nodeDeletionTimeout: &metav1.Duration{Duration: time.Second * 10},
+			expected:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-machine",
+					Namespace:         metav1.NamespaceDefault,
+					DeletionTimestamp: tt.deletionTimestamp,
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:         "test-cluster",
+					NodeDeletionTimeout: tt.nodeDeletionTimeout,
+				},
+			}
+
+			r := &MachineReconciler{}
+
+			g.Expect(r.nodeDeletionTimeoutExceeded(machine)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestShouldWaitForNodeVolumes(t *testing.T) {
+	testCluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "test-cluster"},
+	}
+
+	tests := []struct {
+		name     string
+		objs     []client.Object
+		expected bool
+	}{
+		{
+			name: "Node has no volumes attached",
+			objs: []client.Object{
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+			},
+			expected: false,
+		},
+		{
+			name: "Node status reports volumes attached",
+			objs: []client.Object{
+				&corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+					Status: corev1.NodeStatus{
+						VolumesAttached: []corev1.AttachedVolume{{Name: "test-volume", DevicePath: "test-path"}},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "VolumeAttachment references the node",
+			objs: []client.Object{
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				&storagev1.VolumeAttachment{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-volume-attachment"},
+					Spec: storagev1.VolumeAttachmentSpec{
+						Attacher: "test-attacher",
+						NodeName: "test-node",
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "VolumeAttachment references another node",
+			objs: []client.Object{
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				&storagev1.VolumeAttachment{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-volume-attachment"},
+					Spec: storagev1.VolumeAttachmentSpec{
+						Attacher: "test-attacher",
+						NodeName: "another-node",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "Node does not exist",
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.objs...).Build()
+			r := &MachineReconciler{
+				Client:  cl,
+				Tracker: remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKeyFromObject(testCluster)),
+			}
+
+			got, err := r.shouldWaitForNodeVolumes(ctx, testCluster, "test-node", "test-machine")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestReconcileDeleteWaitsForNodeVolumesWhenDrainIsSkipped(t *testing.T) {
+	g := NewWithT(t)
+
+	testCluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "test-cluster"},
+	}
+
+	controlPlaneMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp1",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:             "test-cluster",
+				clusterv1.MachineControlPlaneLabelName: "",
+			},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-machine",
+			Namespace:   metav1.NamespaceDefault,
+			Labels:      map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			Annotations: map[string]string{clusterv1.ExcludeNodeDrainingAnnotation: ""},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "test-node"},
+		},
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			VolumesAttached: []corev1.AttachedVolume{{Name: "test-volume", DevicePath: "test-path"}},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testCluster, controlPlaneMachine, machine, node).Build()
+	r := &MachineReconciler{
+		Client:   cl,
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKeyFromObject(testCluster)),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	res, err := r.reconcileDelete(ctx, testCluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(Equal(20 * time.Second))
+	g.Expect(conditions.IsFalse(machine, clusterv1.VolumeDetachSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.Get(machine, clusterv1.DrainingSucceededCondition)).To(BeNil())
+
+	// The Node must not be deleted while volumes are still attached.
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(node), &corev1.Node{})).To(Succeed())
+}
+
 func TestIsDeleteNodeAllowed(t *testing.T) {
 	deletionts := metav1.Now()
 
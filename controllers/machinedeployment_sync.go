@@ -77,6 +77,12 @@ func (r *MachineDeploymentReconciler) sync(ctx context.Context, d *clusterv1.Mac
 func (r *MachineDeploymentReconciler) getAllMachineSetsAndSyncRevision(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, createIfNotExisted bool) (*clusterv1.MachineSet, []*clusterv1.MachineSet, error) {
 	_, allOldMSs := mdutil.FindOldMachineSets(d, msList)
 
+	// Propagate the node timeouts and the failure domain spreading to the old machine sets, so they apply
+	// to the Machines being deleted during the rollout.
+	if err := r.syncOldMachineSets(ctx, d, allOldMSs); err != nil {
+		return nil, nil, err
+	}
+
 	// Get new machine set with the updated revision number
 	newMS, err := r.getNewMachineSet(ctx, d, msList, allOldMSs, createIfNotExisted)
 	if err != nil {
@@ -86,6 +92,53 @@ func (r *MachineDeploymentReconciler) getAllMachineSetsAndSyncRevision(ctx conte
 	return newMS, allOldMSs, nil
 }
 
+// syncOldMachineSets updates in place the node timeouts of the old machine sets with the ones
+// defined in the deployment's machine template, and propagates SpreadFailureDomains so that scaling down
+// the old machine sets keeps the Machines of the deployment spread across failure domains.
+// NOTE: Labels and annotations are propagated in place only to the new machine set.
+func (r *MachineDeploymentReconciler) syncOldMachineSets(ctx context.Context, d *clusterv1.MachineDeployment, oldMSs []*clusterv1.MachineSet) error {
+	for _, ms := range oldMSs {
+		patchHelper, err := patch.NewHelper(ms, r.Client)
+		if err != nil {
+			return err
+		}
+
+		changed := mdutil.SyncMachineSpecNodeTimeouts(&ms.Spec.Template.Spec, &d.Spec.Template.Spec)
+
+		// Machine sets pinned to a failure domain by their template cannot spread their Machines.
+		if ms.Spec.Template.Spec.FailureDomain == nil && ms.Spec.SpreadFailureDomains != d.Spec.SpreadFailureDomains {
+			ms.Spec.SpreadFailureDomains = d.Spec.SpreadFailureDomains
+			ms.Spec.DeletePolicy = machineSetDeletePolicy(d)
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := patchHelper.Patch(ctx, ms); err != nil {
+			return errors.Wrapf(err, "failed to update MachineSet %q", ms.Name)
+		}
+	}
+	return nil
+}
+
+// machineSetDeletePolicy returns the delete policy the machine sets of the given deployment should use:
+// the deployment's delete policy if set, FailureDomainBalanced if the deployment spreads its Machines
+// across failure domains, otherwise an empty policy which is defaulted by the MachineSet webhook.
+// NOTE: The MachineDeployment webhook rejects delete policies other than FailureDomainBalanced when
+// spreading Machines across failure domains, so the deployment's delete policy is never overridden and
+// is restored on the machine sets when spreading is turned off.
+func machineSetDeletePolicy(d *clusterv1.MachineDeployment) string {
+	if d.Spec.Strategy != nil && d.Spec.Strategy.RollingUpdate != nil && d.Spec.Strategy.RollingUpdate.DeletePolicy != nil {
+		return *d.Spec.Strategy.RollingUpdate.DeletePolicy
+	}
+	if d.Spec.SpreadFailureDomains {
+		return string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy)
+	}
+	return ""
+}
+
 // Returns a machine set that matches the intent of the given deployment. Returns nil if the new machine set doesn't exist yet.
 // 1. Get existing new MS (the MS that the given deployment targets, whose machine template is the same as deployment's).
 // 2. If there's existing new MS, update its revision number if it's smaller than (maxOldRevision + 1), where maxOldRevision is the max revision number among all old MSes.
@@ -116,13 +169,19 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 		// Set existing new machine set's annotation
 		annotationsUpdated := mdutil.SetNewMachineSetAnnotations(d, msCopy, newRevision, true, log)
 
+		// Propagate in place the labels, annotations and node timeouts of the deployment's machine template;
+		// changes to those fields do not require a rollout.
+		templateUpdated := mdutil.SyncMachineTemplateInPlaceFields(&msCopy.Spec.Template, &d.Spec.Template)
+
 		minReadySecondsNeedsUpdate := msCopy.Spec.MinReadySeconds != *d.Spec.MinReadySeconds
 		deletePolicyNeedsUpdate := d.Spec.Strategy.RollingUpdate.DeletePolicy != nil && msCopy.Spec.DeletePolicy != *d.Spec.Strategy.RollingUpdate.DeletePolicy
-		if annotationsUpdated || minReadySecondsNeedsUpdate || deletePolicyNeedsUpdate {
+		spreadFailureDomainsNeedsUpdate := msCopy.Spec.SpreadFailureDomains != d.Spec.SpreadFailureDomains
+		if annotationsUpdated || templateUpdated || minReadySecondsNeedsUpdate || deletePolicyNeedsUpdate || spreadFailureDomainsNeedsUpdate {
 			msCopy.Spec.MinReadySeconds = *d.Spec.MinReadySeconds
+			msCopy.Spec.SpreadFailureDomains = d.Spec.SpreadFailureDomains
 
-			if deletePolicyNeedsUpdate {
-				msCopy.Spec.DeletePolicy = *d.Spec.Strategy.RollingUpdate.DeletePolicy
+			if deletePolicyNeedsUpdate || spreadFailureDomainsNeedsUpdate {
+				msCopy.Spec.DeletePolicy = machineSetDeletePolicy(d)
 			}
 
 			return nil, patchHelper.Patch(ctx, msCopy)
@@ -141,7 +200,9 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 
 	// new MachineSet does not exist, create one.
 	newMSTemplate := *d.Spec.Template.DeepCopy()
-	hash, err := mdutil.ComputeSpewHash(&newMSTemplate)
+	// Only the fields requiring a rollout are considered when computing the hash, given that
+	// all the other fields are propagated in place to the existing new machine set.
+	hash, err := mdutil.ComputeSpewHash(mdutil.MachineTemplateDeepCopyRolloutFields(&newMSTemplate))
 	if err != nil {
 		return nil, err
 	}
@@ -168,11 +229,12 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(d, machineDeploymentKind)},
 		},
 		Spec: clusterv1.MachineSetSpec{
-			ClusterName:     d.Spec.ClusterName,
-			Replicas:        new(int32),
-			MinReadySeconds: minReadySeconds,
-			Selector:        *newMSSelector,
-			Template:        newMSTemplate,
+			ClusterName:          d.Spec.ClusterName,
+			Replicas:             new(int32),
+			MinReadySeconds:      minReadySeconds,
+			SpreadFailureDomains: d.Spec.SpreadFailureDomains,
+			Selector:             *newMSSelector,
+			Template:             newMSTemplate,
 		},
 	}
 
@@ -187,9 +249,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 		}
 	}
 
-	if d.Spec.Strategy.RollingUpdate.DeletePolicy != nil {
-		newMS.Spec.DeletePolicy = *d.Spec.Strategy.RollingUpdate.DeletePolicy
-	}
+	newMS.Spec.DeletePolicy = machineSetDeletePolicy(d)
 
 	// Add foregroundDeletion finalizer to MachineSet if the MachineDeployment has it
 	if sets.NewString(d.Finalizers...).Has(metav1.FinalizerDeleteDependents) {
@@ -223,12 +283,12 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 			return nil, msErr
 		}
 
-		// If the Deployment owns the MachineSet and the MachineSet's MachineTemplateSpec is semantically
-		// deep equal to the MachineTemplateSpec of the Deployment, it's the Deployment's new MachineSet.
+		// If the Deployment owns the MachineSet and the MachineSet's MachineTemplateSpec is up-to-date
+		// with the MachineTemplateSpec of the Deployment, it's the Deployment's new MachineSet.
 		// Otherwise, this is a hash collision and we need to increment the collisionCount field in
 		// the status of the Deployment and requeue to try the creation in the next sync.
 		controllerRef := metav1.GetControllerOf(ms)
-		if controllerRef != nil && controllerRef.UID == d.UID && mdutil.EqualMachineTemplate(&d.Spec.Template, &ms.Spec.Template) {
+		if controllerRef != nil && controllerRef.UID == d.UID && mdutil.MachineTemplateUpToDate(&ms.Spec.Template, &d.Spec.Template) {
 			createdMS = ms
 			break
 		}
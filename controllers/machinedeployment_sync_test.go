@@ -441,6 +441,88 @@ func newTestMachinesetWithReplicas(name string, specReplicas, statusReplicas, av
 	}
 }
 
+func TestSyncOldMachineSets(t *testing.T) {
+	newMachineSet := func(name string, failureDomain *string) *clusterv1.MachineSet {
+		ms := newTestMachinesetWithReplicas(name, 1, 1, 1)
+		ms.Spec.DeletePolicy = string(clusterv1.OldestMachineSetDeletePolicy)
+		ms.Spec.Template.Spec.FailureDomain = failureDomain
+		return ms
+	}
+	spreadingMachineSet := func(name string) *clusterv1.MachineSet {
+		ms := newMachineSet(name, nil)
+		ms.Spec.SpreadFailureDomains = true
+		ms.Spec.DeletePolicy = string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy)
+		return ms
+	}
+
+	tests := []struct {
+		name                     string
+		spreadFailureDomains     bool
+		deletePolicy             *string
+		machineSet               *clusterv1.MachineSet
+		wantSpreadFailureDomains bool
+		wantDeletePolicy         string
+	}{
+		{
+			name:                     "propagates SpreadFailureDomains and the delete policy of the MachineDeployment",
+			spreadFailureDomains:     true,
+			deletePolicy:             pointer.StringPtr(string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy)),
+			machineSet:               newMachineSet("old", nil),
+			wantSpreadFailureDomains: true,
+			wantDeletePolicy:         string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy),
+		},
+		{
+			name:                     "propagates SpreadFailureDomains and the FailureDomainBalanced delete policy if the MachineDeployment has no delete policy",
+			spreadFailureDomains:     true,
+			machineSet:               newMachineSet("old", nil),
+			wantSpreadFailureDomains: true,
+			wantDeletePolicy:         string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy),
+		},
+		{
+			name:                     "does not spread the Machines of a MachineSet pinned to a failure domain",
+			spreadFailureDomains:     true,
+			deletePolicy:             pointer.StringPtr(string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy)),
+			machineSet:               newMachineSet("old", pointer.String("fd1")),
+			wantSpreadFailureDomains: false,
+			wantDeletePolicy:         string(clusterv1.OldestMachineSetDeletePolicy),
+		},
+		{
+			name:                     "restores the delete policy of the MachineDeployment when SpreadFailureDomains is turned off",
+			deletePolicy:             pointer.StringPtr(string(clusterv1.NewestMachineSetDeletePolicy)),
+			machineSet:               spreadingMachineSet("old"),
+			wantSpreadFailureDomains: false,
+			wantDeletePolicy:         string(clusterv1.NewestMachineSetDeletePolicy),
+		},
+		{
+			name:                     "leaves the delete policy unchanged without SpreadFailureDomains",
+			deletePolicy:             pointer.StringPtr(string(clusterv1.NewestMachineSetDeletePolicy)),
+			machineSet:               newMachineSet("old", nil),
+			wantSpreadFailureDomains: false,
+			wantDeletePolicy:         string(clusterv1.OldestMachineSetDeletePolicy),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := newTestMachineDeployment(nil, 1, 1, 1, 1, nil)
+			d.Spec.SpreadFailureDomains = tt.spreadFailureDomains
+			d.Spec.Strategy.RollingUpdate.DeletePolicy = tt.deletePolicy
+
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewClientBuilder().WithObjects(tt.machineSet).Build(),
+				recorder: record.NewFakeRecorder(32),
+			}
+			g.Expect(r.syncOldMachineSets(ctx, d, []*clusterv1.MachineSet{tt.machineSet})).To(Succeed())
+
+			got := &clusterv1.MachineSet{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(tt.machineSet), got)).To(Succeed())
+			g.Expect(got.Spec.SpreadFailureDomains).To(Equal(tt.wantSpreadFailureDomains))
+			g.Expect(got.Spec.DeletePolicy).To(Equal(tt.wantDeletePolicy))
+		})
+	}
+}
+
 func TestSyncDeploymentStatus(t *testing.T) {
 	pds := int32(60)
 	tests := []struct {
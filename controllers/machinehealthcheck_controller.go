@@ -258,32 +258,40 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		}
 
 		// Remediation not allowed, the number of not started or unhealthy machines either exceeds maxUnhealthy (or) not within unhealthyRange
-		m.Status.RemediationsAllowed = 0
-		conditions.Set(m, &clusterv1.Condition{
-			Type:     clusterv1.RemediationAllowedCondition,
-			Status:   corev1.ConditionFalse,
-			Severity: clusterv1.ConditionSeverityWarning,
-			Reason:   clusterv1.TooManyUnhealthyReason,
-			Message:  message,
-		})
+		return r.restrictRemediation(ctx, m, append(healthy, unhealthy...), clusterv1.TooManyUnhealthyReason, message, reconcile.Result{Requeue: true})
+	}
 
-		r.recorder.Eventf(
-			m,
-			corev1.EventTypeWarning,
-			EventRemediationRestricted,
-			message,
-		)
-		errList := []error{}
-		for _, t := range append(healthy, unhealthy...) {
-			if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
-				errList = append(errList, errors.Wrapf(err, "failed to patch machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
-				continue
-			}
+	// check the remediation limits
+	now := time.Now()
+	pruneRemediations(m, now)
+
+	if w := activeMaintenanceWindow(m, now); w != nil {
+		logger.V(3).Info("Remediation suspended by maintenance window", "start", w.Start, "end", w.End)
+		message := fmt.Sprintf("Remediation is suspended during the maintenance window (start: %v, end: %v)", w.Start.UTC(), w.End.UTC())
+		return r.restrictRemediation(ctx, m, append(healthy, unhealthy...), clusterv1.MaintenanceWindowActiveReason, message, reconcile.Result{RequeueAfter: w.End.Sub(now)})
+	}
+
+	budget, retryAfter := remediationBudget(m, now)
+	if budget == 0 {
+		logger.V(3).Info("Remediation rate limited", "maxRemediations", *m.Spec.RemediationLimits.MaxRemediations, "window", remediationWindow(m))
+		message := fmt.Sprintf("Remediation is not allowed, the maximum number of remediations has been reached (maxRemediations: %v, window: %v)",
+			*m.Spec.RemediationLimits.MaxRemediations,
+			remediationWindow(m))
+
+		// Waiting for rebooted Machines to become healthy, and escalating their remediation if they do not,
+		// is part of remediations already accounted for, so it is not subject to the remediation limits.
+		rebooted, notRebooted := splitRebootedTargets(m, unhealthy)
+		if errList := r.patchUnhealthyTargets(ctx, logger, rebooted, cluster, m); len(errList) > 0 {
+			logger.V(3).Info("Error(s) marking machine, requeueing")
+			return reconcile.Result{}, kerrors.NewAggregate(errList)
 		}
-		if len(errList) > 0 {
-			return ctrl.Result{}, kerrors.NewAggregate(errList)
+		if rebootRetryAfter := minDuration(rebootRetryTimes(m, rebooted, now)); rebootRetryAfter > 0 && rebootRetryAfter < retryAfter {
+			retryAfter = rebootRetryAfter
 		}
-		return reconcile.Result{Requeue: true}, nil
+		return r.restrictRemediation(ctx, m, append(healthy, notRebooted...), clusterv1.RemediationRateLimitedReason, message, reconcile.Result{RequeueAfter: retryAfter})
+	}
+	if budget != unlimitedRemediations && int32(budget) < remediationCount {
+		remediationCount = int32(budget)
 	}
 
 	if m.Spec.UnhealthyRange == nil {
@@ -315,6 +323,11 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return reconcile.Result{}, kerrors.NewAggregate(errList)
 	}
 
+	// Ensure targets which have not been remediated because of the remediation limits are checked again.
+	nextCheckTimes = append(nextCheckTimes, remediationRetryTimes(m, unhealthy, time.Now())...)
+	// Ensure targets waiting to become healthy after a reboot are checked again before escalating their remediation.
+	nextCheckTimes = append(nextCheckTimes, rebootRetryTimes(m, unhealthy, time.Now())...)
+
 	if minNextCheck := minDuration(nextCheckTimes); minNextCheck > 0 {
 		logger.V(3).Info("Some targets might go unhealthy. Ensuring a requeue happens", "requeueIn", minNextCheck.Truncate(time.Second).String())
 		return ctrl.Result{RequeueAfter: minNextCheck}, nil
@@ -325,6 +338,37 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	return ctrl.Result{}, nil
 }
 
+// restrictRemediation blocks any further remediation, reporting the reason through the RemediationAllowed condition,
+// and patches the targets with the results of the health check.
+func (r *MachineHealthCheckReconciler) restrictRemediation(ctx context.Context, m *clusterv1.MachineHealthCheck, targets []healthCheckTarget, reason, message string, result ctrl.Result) (ctrl.Result, error) {
+	m.Status.RemediationsAllowed = 0
+	conditions.Set(m, &clusterv1.Condition{
+		Type:     clusterv1.RemediationAllowedCondition,
+		Status:   corev1.ConditionFalse,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  message,
+	})
+
+	r.recorder.Eventf(
+		m,
+		corev1.EventTypeWarning,
+		EventRemediationRestricted,
+		message,
+	)
+	errList := []error{}
+	for _, t := range targets {
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+			continue
+		}
+	}
+	if len(errList) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
+	}
+	return result, nil
+}
+
 // patchHealthyTargets patches healthy machines with MachineHealthCheckSucceededCondition.
 func (r *MachineHealthCheckReconciler) patchHealthyTargets(ctx context.Context, logger logr.Logger, healthy []healthCheckTarget, m *clusterv1.MachineHealthCheck) []error {
 	errList := []error{}
@@ -348,6 +392,8 @@ func (r *MachineHealthCheckReconciler) patchHealthyTargets(ctx context.Context,
 			}
 		}
 
+		resetRebootRemediation(t.Machine)
+
 		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
 			logger.Error(err, "failed to patch healthy machine status for machine", "machine", t.Machine.GetName())
 			errList = append(errList, errors.Wrapf(err, "failed to patch healthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
@@ -360,12 +406,40 @@ func (r *MachineHealthCheckReconciler) patchHealthyTargets(ctx context.Context,
 func (r *MachineHealthCheckReconciler) patchUnhealthyTargets(ctx context.Context, logger logr.Logger, unhealthy []healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) []error {
 	// mark for remediation
 	errList := []error{}
+	now := time.Now()
+	budget, _ := remediationBudget(m, now)
 	for _, t := range unhealthy {
 		condition := conditions.Get(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+		triggersRemediation := m.Spec.RemediationTemplate != nil ||
+			!conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition)
+		step := remediateStep
+		if triggersRemediation {
+			step, _ = nextRemediationStep(m, t.Machine, now)
+		}
 
+		// NOTE: escalating the remediation of a Machine still unhealthy after its reboot is part of the same remediation,
+		// so it is not subject to the remediation limits.
 		if annotations.IsPaused(cluster, t.Machine) {
 			logger.Info("Machine has failed health check, but machine is paused so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		} else if step == waitForRebootStep {
+			logger.Info("Machine has failed health check, waiting for the machine to become healthy after reboot", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		} else if backoff := remediationBackoff(m, t.Machine.Name, now); step != escalateStep && triggersRemediation && backoff > 0 {
+			logger.Info("Machine has failed health check, but machine has been remediated recently so skipping remediation", "target", t.string(), "retryAfter", backoff.Truncate(time.Second).String())
+		} else if step != escalateStep && triggersRemediation && budget == 0 {
+			logger.Info("Machine has failed health check, but the maximum number of remediations has been reached so skipping remediation", "target", t.string())
+		} else if step == rebootStep {
+			logger.Info("Target has failed health check, requesting reboot", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+			requestReboot(m, t.Machine, now)
+			recordRemediation(m, t.Machine.Name, now)
+			if budget > 0 {
+				budget--
+			}
 		} else {
+			if step == escalateStep {
+				logger.Info("Target is still unhealthy after reboot, escalating remediation", "target", t.string())
+				escalateReboot(m, t.Machine)
+			}
+
 			if m.Spec.RemediationTemplate != nil {
 				// If external remediation request already exists,
 				// return early
@@ -415,12 +489,24 @@ func (r *MachineHealthCheckReconciler) patchUnhealthyTargets(ctx context.Context
 					errList = append(errList, errors.Wrapf(err, "error creating remediation request for machine %q in namespace %q within cluster %q", t.Machine.Name, t.Machine.Namespace, t.Machine.ClusterName))
 					return errList
 				}
+				if step != escalateStep {
+					recordRemediation(m, t.Machine.Name, now)
+					if budget > 0 {
+						budget--
+					}
+				}
 			} else {
 				logger.Info("Target has failed health check, marking for remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
 				// NOTE: MHC is responsible for creating MachineOwnerRemediatedCondition if missing or to trigger another remediation if the previous one is completed;
 				// instead, if a remediation is in already progress, the remediation owner is responsible for completing the process and MHC should not overwrite the condition.
-				if !conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+				if triggersRemediation {
 					conditions.MarkFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+					if step != escalateStep {
+						recordRemediation(m, t.Machine.Name, now)
+						if budget > 0 {
+							budget--
+						}
+					}
 				}
 			}
 		}
@@ -2622,3 +2622,70 @@ func TestPatchTargets(t *testing.T) {
 	// Target with wrong patch helper will fail but the other one will be patched.
 	g.Expect(len(r.patchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, mhc))).To(BeNumerically(">", 0))
 }
+
+func TestPatchUnhealthyTargetsEscalatesRebootWithRemediationBudgetExhausted(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := metav1.NamespaceDefault
+	clusterName := testClusterName
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	now := time.Now()
+
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationStrategy = &clusterv1.RemediationStrategy{
+		Type:          clusterv1.RebootFirstRemediationStrategyType,
+		RebootTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	mhc.Spec.RemediationLimits = &clusterv1.RemediationLimits{MaxRemediations: pointer.Int32Ptr(1)}
+	mhc.Status.Remediations = []clusterv1.MachineRemediation{
+		{MachineName: "other", Timestamp: metav1.NewTime(now.Add(-time.Minute))},
+	}
+
+	// The reboot of this Machine timed out, so its remediation must be escalated.
+	rebooted := newTestMachine("rebooted", namespace, clusterName, "nodeName", labels)
+	conditions.MarkFalse(rebooted, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+	requestReboot(mhc, rebooted, now.Add(-20*time.Minute))
+	// This Machine has never been rebooted, so it must not be remediated.
+	notRebooted := newTestMachine("not-rebooted", namespace, clusterName, "nodeName", labels)
+	conditions.MarkFalse(notRebooted, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+
+	cl := fake.NewClientBuilder().WithObjects(rebooted, notRebooted, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	budget, _ := remediationBudget(mhc, now)
+	g.Expect(budget).To(Equal(0))
+
+	var targets []healthCheckTarget
+	for _, name := range []string{rebooted.Name, notRebooted.Name} {
+		m := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, m)).To(Succeed())
+		patchHelper, err := patch.NewHelper(m, cl)
+		g.Expect(err).ToNot(HaveOccurred())
+		targets = append(targets, healthCheckTarget{MHC: mhc, Machine: m, patchHelper: patchHelper, Node: &corev1.Node{}})
+	}
+
+	toRemediate, toSkip := splitRebootedTargets(mhc, targets)
+	g.Expect(toRemediate).To(HaveLen(1))
+	g.Expect(toRemediate[0].Machine.Name).To(Equal(rebooted.Name))
+	g.Expect(toSkip).To(HaveLen(1))
+	g.Expect(toSkip[0].Machine.Name).To(Equal(notRebooted.Name))
+
+	g.Expect(r.patchUnhealthyTargets(ctx, log.NullLogger{}, toRemediate, defaultCluster, mhc)).To(BeEmpty())
+
+	got := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(rebooted), got)).To(Succeed())
+	g.Expect(got.Annotations).NotTo(HaveKey(clusterv1.RebootRequestedAnnotation))
+	g.Expect(conditions.GetReason(got, clusterv1.MachineRebootRemediatedCondition)).To(Equal(clusterv1.RebootTimedOutReason))
+	g.Expect(conditions.IsFalse(got, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	// Escalating the remediation does not count as a new remediation.
+	g.Expect(mhc.Status.Remediations).To(HaveLen(1))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// unlimitedRemediations is returned by remediationBudget when the MachineHealthCheck
+// does not limit the number of remediations.
+const unlimitedRemediations = -1
+
+// activeMaintenanceWindow returns the maintenance window which is active at the given time, if any.
+func activeMaintenanceWindow(mhc *clusterv1.MachineHealthCheck, now time.Time) *clusterv1.MaintenanceWindow {
+	if mhc.Spec.RemediationLimits == nil {
+		return nil
+	}
+	for i := range mhc.Spec.RemediationLimits.MaintenanceWindows {
+		w := &mhc.Spec.RemediationLimits.MaintenanceWindows[i]
+		if w.IsActive(now) {
+			return w
+		}
+	}
+	return nil
+}
+
+// remediationWindow returns the time window used to count remediations.
+func remediationWindow(mhc *clusterv1.MachineHealthCheck) time.Duration {
+	if mhc.Spec.RemediationLimits == nil || mhc.Spec.RemediationLimits.Window == nil {
+		return clusterv1.DefaultRemediationWindow.Duration
+	}
+	return mhc.Spec.RemediationLimits.Window.Duration
+}
+
+// machineBackoff returns the minimum time between two remediations of the same Machine.
+func machineBackoff(mhc *clusterv1.MachineHealthCheck) time.Duration {
+	if mhc.Spec.RemediationLimits == nil || mhc.Spec.RemediationLimits.MachineBackoff == nil {
+		return 0
+	}
+	return mhc.Spec.RemediationLimits.MachineBackoff.Duration
+}
+
+// pruneRemediations drops the remediations which are not relevant anymore for
+// enforcing the remediation limits of the MachineHealthCheck.
+func pruneRemediations(mhc *clusterv1.MachineHealthCheck, now time.Time) {
+	if mhc.Spec.RemediationLimits == nil {
+		mhc.Status.Remediations = nil
+		return
+	}
+
+	retention := machineBackoff(mhc)
+	if mhc.Spec.RemediationLimits.MaxRemediations != nil && remediationWindow(mhc) > retention {
+		retention = remediationWindow(mhc)
+	}
+
+	var remediations []clusterv1.MachineRemediation
+	for _, r := range mhc.Status.Remediations {
+		if now.Sub(r.Timestamp.Time) < retention {
+			remediations = append(remediations, r)
+		}
+	}
+	mhc.Status.Remediations = remediations
+}
+
+// remediationBudget returns the number of remediations the MachineHealthCheck can still trigger
+// within the current window, or unlimitedRemediations if there is no limit.
+// If no remediation is allowed, it also returns the time after which the next remediation is allowed.
+func remediationBudget(mhc *clusterv1.MachineHealthCheck, now time.Time) (int, time.Duration) {
+	if mhc.Spec.RemediationLimits == nil || mhc.Spec.RemediationLimits.MaxRemediations == nil {
+		return unlimitedRemediations, 0
+	}
+
+	window := remediationWindow(mhc)
+	var inWindow []time.Time
+	for _, r := range mhc.Status.Remediations {
+		if now.Sub(r.Timestamp.Time) < window {
+			inWindow = append(inWindow, r.Timestamp.Time)
+		}
+	}
+
+	budget := int(*mhc.Spec.RemediationLimits.MaxRemediations) - len(inWindow)
+	if budget > 0 {
+		return budget, 0
+	}
+
+	// The next remediation is allowed as soon as enough remediations fall out of the window.
+	oldest := now
+	for _, t := range inWindow {
+		if t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return 0, oldest.Add(window).Sub(now)
+}
+
+// remediationBackoff returns how long the given Machine must wait before being remediated again.
+func remediationBackoff(mhc *clusterv1.MachineHealthCheck, machineName string, now time.Time) time.Duration {
+	backoff := machineBackoff(mhc)
+	if backoff == 0 {
+		return 0
+	}
+
+	var wait time.Duration
+	for _, r := range mhc.Status.Remediations {
+		if r.MachineName != machineName {
+			continue
+		}
+		if d := r.Timestamp.Add(backoff).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// recordRemediation records that a remediation has been triggered for the given Machine.
+// Remediations are only recorded when the MachineHealthCheck defines remediation limits.
+func recordRemediation(mhc *clusterv1.MachineHealthCheck, machineName string, now time.Time) {
+	if mhc.Spec.RemediationLimits == nil {
+		return
+	}
+	mhc.Status.Remediations = append(mhc.Status.Remediations, clusterv1.MachineRemediation{
+		MachineName: machineName,
+		Timestamp:   metav1.NewTime(now),
+	})
+}
+
+// remediationRetryTimes returns the durations after which the unhealthy targets, which have not been
+// remediated because of the remediation limits, can be remediated.
+func remediationRetryTimes(mhc *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget, now time.Time) []time.Duration {
+	if mhc.Spec.RemediationLimits == nil {
+		return nil
+	}
+
+	var retryTimes []time.Duration
+	_, retryAfter := remediationBudget(mhc, now)
+	for _, t := range unhealthy {
+		if conditions.IsFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+			// Remediation is already in progress.
+			continue
+		}
+		if backoff := remediationBackoff(mhc, t.Machine.Name, now); backoff > 0 {
+			retryTimes = append(retryTimes, backoff)
+		}
+		if retryAfter > 0 {
+			retryTimes = append(retryTimes, retryAfter)
+		}
+	}
+	return retryTimes
+}
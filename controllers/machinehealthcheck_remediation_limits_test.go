@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestActiveMaintenanceWindow(t *testing.T) {
+	now := time.Now()
+	window := clusterv1.MaintenanceWindow{
+		Start: metav1.NewTime(now.Add(-time.Hour)),
+		End:   metav1.NewTime(now.Add(time.Hour)),
+	}
+
+	tests := []struct {
+		name     string
+		limits   *clusterv1.RemediationLimits
+		expected *clusterv1.MaintenanceWindow
+	}{
+		{
+			name:     "no remediation limits",
+			limits:   nil,
+			expected: nil,
+		},
+		{
+			name: "no active maintenance window",
+			limits: &clusterv1.RemediationLimits{
+				MaintenanceWindows: []clusterv1.MaintenanceWindow{
+					{Start: metav1.NewTime(now.Add(-2 * time.Hour)), End: metav1.NewTime(now.Add(-time.Hour))},
+					{Start: metav1.NewTime(now.Add(time.Hour)), End: metav1.NewTime(now.Add(2 * time.Hour))},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "active maintenance window",
+			limits: &clusterv1.RemediationLimits{
+				MaintenanceWindows: []clusterv1.MaintenanceWindow{
+					{Start: metav1.NewTime(now.Add(-2 * time.Hour)), End: metav1.NewTime(now.Add(-time.Hour))},
+					window,
+				},
+			},
+			expected: &window,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &clusterv1.MachineHealthCheck{Spec: clusterv1.MachineHealthCheckSpec{RemediationLimits: tt.limits}}
+			g.Expect(activeMaintenanceWindow(mhc, now)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestRemediationBudget(t *testing.T) {
+	now := time.Now()
+	remediatedAgo := func(name string, d time.Duration) clusterv1.MachineRemediation {
+		return clusterv1.MachineRemediation{MachineName: name, Timestamp: metav1.NewTime(now.Add(-d))}
+	}
+
+	tests := []struct {
+		name               string
+		limits             *clusterv1.RemediationLimits
+		remediations       []clusterv1.MachineRemediation
+		expectedBudget     int
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name:           "no remediation limits",
+			limits:         nil,
+			expectedBudget: unlimitedRemediations,
+		},
+		{
+			name:           "no maxRemediations",
+			limits:         &clusterv1.RemediationLimits{MachineBackoff: &metav1.Duration{Duration: time.Hour}},
+			remediations:   []clusterv1.MachineRemediation{remediatedAgo("m1", time.Minute)},
+			expectedBudget: unlimitedRemediations,
+		},
+		{
+			name: "remediations left within the window",
+			limits: &clusterv1.RemediationLimits{
+				MaxRemediations: pointer.Int32(3),
+				Window:          &metav1.Duration{Duration: time.Hour},
+			},
+			remediations: []clusterv1.MachineRemediation{
+				remediatedAgo("m1", 10*time.Minute),
+				remediatedAgo("m2", 2*time.Hour),
+			},
+			expectedBudget: 2,
+		},
+		{
+			name: "no remediations left within the window",
+			limits: &clusterv1.RemediationLimits{
+				MaxRemediations: pointer.Int32(2),
+				Window:          &metav1.Duration{Duration: time.Hour},
+			},
+			remediations: []clusterv1.MachineRemediation{
+				remediatedAgo("m1", 10*time.Minute),
+				remediatedAgo("m2", 40*time.Minute),
+			},
+			expectedBudget:     0,
+			expectedRetryAfter: 20 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec:   clusterv1.MachineHealthCheckSpec{RemediationLimits: tt.limits},
+				Status: clusterv1.MachineHealthCheckStatus{Remediations: tt.remediations},
+			}
+			budget, retryAfter := remediationBudget(mhc, now)
+			g.Expect(budget).To(Equal(tt.expectedBudget))
+			g.Expect(retryAfter).To(Equal(tt.expectedRetryAfter))
+		})
+	}
+}
+
+func TestRemediationBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	mhc := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			RemediationLimits: &clusterv1.RemediationLimits{
+				MachineBackoff: &metav1.Duration{Duration: 30 * time.Minute},
+			},
+		},
+		Status: clusterv1.MachineHealthCheckStatus{
+			Remediations: []clusterv1.MachineRemediation{
+				{MachineName: "m1", Timestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+				{MachineName: "m2", Timestamp: metav1.NewTime(now.Add(-40 * time.Minute))},
+			},
+		},
+	}
+
+	g.Expect(remediationBackoff(mhc, "m1", now)).To(Equal(20 * time.Minute))
+	g.Expect(remediationBackoff(mhc, "m2", now)).To(BeZero())
+	g.Expect(remediationBackoff(mhc, "m3", now)).To(BeZero())
+}
+
+func TestPruneAndRecordRemediations(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	mhc := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			RemediationLimits: &clusterv1.RemediationLimits{
+				MaxRemediations: pointer.Int32(1),
+				Window:          &metav1.Duration{Duration: time.Hour},
+				MachineBackoff:  &metav1.Duration{Duration: 2 * time.Hour},
+			},
+		},
+		Status: clusterv1.MachineHealthCheckStatus{
+			Remediations: []clusterv1.MachineRemediation{
+				{MachineName: "m1", Timestamp: metav1.NewTime(now.Add(-90 * time.Minute))},
+				{MachineName: "m2", Timestamp: metav1.NewTime(now.Add(-3 * time.Hour))},
+			},
+		},
+	}
+
+	// Remediations are retained for the longest of the window and the Machine backoff.
+	pruneRemediations(mhc, now)
+	g.Expect(mhc.Status.Remediations).To(HaveLen(1))
+	g.Expect(mhc.Status.Remediations[0].MachineName).To(Equal("m1"))
+
+	recordRemediation(mhc, "m3", now)
+	g.Expect(mhc.Status.Remediations).To(HaveLen(2))
+	g.Expect(mhc.Status.Remediations[1].MachineName).To(Equal("m3"))
+
+	// Remediations are not tracked without remediation limits.
+	mhc.Spec.RemediationLimits = nil
+	pruneRemediations(mhc, now)
+	g.Expect(mhc.Status.Remediations).To(BeEmpty())
+	recordRemediation(mhc, "m4", now)
+	g.Expect(mhc.Status.Remediations).To(BeEmpty())
+}
+
+func TestRemediationRetryTimes(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	mhc := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			RemediationLimits: &clusterv1.RemediationLimits{
+				MachineBackoff: &metav1.Duration{Duration: 30 * time.Minute},
+			},
+		},
+		Status: clusterv1.MachineHealthCheckStatus{
+			Remediations: []clusterv1.MachineRemediation{
+				{MachineName: "in-backoff", Timestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+				{MachineName: "remediating", Timestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+			},
+		},
+	}
+
+	remediating := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "remediating"}}
+	conditions.MarkFalse(remediating, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	unhealthy := []healthCheckTarget{
+		{Machine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "in-backoff"}}},
+		{Machine: remediating},
+		{Machine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "new"}}},
+	}
+
+	g.Expect(remediationRetryTimes(mhc, unhealthy, now)).To(ConsistOf(20 * time.Minute))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// remediationStep is the next step of the remediation of an unhealthy Machine.
+type remediationStep string
+
+const (
+	// remediateStep hands off the Machine to its owner, or to the external remediation if any.
+	remediateStep = remediationStep("Remediate")
+
+	// rebootStep requests the infrastructure provider to reboot the Machine.
+	rebootStep = remediationStep("Reboot")
+
+	// waitForRebootStep waits for the Machine to become healthy after its reboot has been requested.
+	waitForRebootStep = remediationStep("WaitForReboot")
+
+	// escalateStep escalates the remediation of a Machine which is still unhealthy after its reboot.
+	escalateStep = remediationStep("Escalate")
+)
+
+// rebootFirst returns true if the MachineHealthCheck uses the RebootFirst remediation strategy.
+func rebootFirst(mhc *clusterv1.MachineHealthCheck) bool {
+	return mhc.Spec.RemediationStrategy != nil && mhc.Spec.RemediationStrategy.Type == clusterv1.RebootFirstRemediationStrategyType
+}
+
+// rebootTimeout returns the time to wait for a Machine to become healthy after its reboot has been requested.
+func rebootTimeout(mhc *clusterv1.MachineHealthCheck) time.Duration {
+	if mhc.Spec.RemediationStrategy == nil || mhc.Spec.RemediationStrategy.RebootTimeout == nil {
+		return clusterv1.DefaultRebootTimeout.Duration
+	}
+	return mhc.Spec.RemediationStrategy.RebootTimeout.Duration
+}
+
+// rebootRequestedAt returns the time the reboot of the Machine has been requested at, if any.
+func rebootRequestedAt(machine *clusterv1.Machine) *time.Time {
+	value, ok := machine.GetAnnotations()[clusterv1.RebootRequestedAnnotation]
+	if !ok {
+		return nil
+	}
+	requestedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// An invalid value is handled like a missing request, so a new reboot is requested.
+		return nil
+	}
+	return &requestedAt
+}
+
+// nextRemediationStep returns the next step of the remediation of an unhealthy Machine.
+// When waiting for a Machine to become healthy after its reboot, it also returns the time left before escalating.
+func nextRemediationStep(mhc *clusterv1.MachineHealthCheck, machine *clusterv1.Machine, now time.Time) (remediationStep, time.Duration) {
+	if !rebootFirst(mhc) {
+		return remediateStep, 0
+	}
+
+	requestedAt := rebootRequestedAt(machine)
+	if requestedAt == nil {
+		// If the remediation has already been escalated, the Machine is remediated as usual.
+		if conditions.IsFalse(machine, clusterv1.MachineRebootRemediatedCondition) &&
+			conditions.GetReason(machine, clusterv1.MachineRebootRemediatedCondition) == clusterv1.RebootTimedOutReason {
+			return remediateStep, 0
+		}
+		return rebootStep, 0
+	}
+
+	if wait := requestedAt.Add(rebootTimeout(mhc)).Sub(now); wait > 0 {
+		return waitForRebootStep, wait
+	}
+	return escalateStep, 0
+}
+
+// requestReboot requests the infrastructure provider to reboot the Machine.
+func requestReboot(mhc *clusterv1.MachineHealthCheck, machine *clusterv1.Machine, now time.Time) {
+	annotations.AddAnnotations(machine, map[string]string{
+		clusterv1.RebootRequestedAnnotation: now.UTC().Format(time.RFC3339),
+	})
+	conditions.MarkFalse(machine, clusterv1.MachineRebootRemediatedCondition, clusterv1.RebootRequestedReason, clusterv1.ConditionSeverityWarning,
+		"Reboot requested, waiting up to %s for the machine to become healthy", rebootTimeout(mhc).String())
+}
+
+// escalateReboot records that the Machine is still unhealthy after its reboot, and that its remediation is escalated.
+func escalateReboot(mhc *clusterv1.MachineHealthCheck, machine *clusterv1.Machine) {
+	machineAnnotations := machine.GetAnnotations()
+	delete(machineAnnotations, clusterv1.RebootRequestedAnnotation)
+	machine.SetAnnotations(machineAnnotations)
+	conditions.MarkFalse(machine, clusterv1.MachineRebootRemediatedCondition, clusterv1.RebootTimedOutReason, clusterv1.ConditionSeverityWarning,
+		"Machine is still unhealthy %s after the reboot request, remediation has been escalated", rebootTimeout(mhc).String())
+}
+
+// resetRebootRemediation records that a Machine is healthy again after a reboot remediation, if any.
+func resetRebootRemediation(machine *clusterv1.Machine) {
+	if machineAnnotations := machine.GetAnnotations(); machineAnnotations != nil {
+		delete(machineAnnotations, clusterv1.RebootRequestedAnnotation)
+		machine.SetAnnotations(machineAnnotations)
+	}
+	if conditions.IsFalse(machine, clusterv1.MachineRebootRemediatedCondition) {
+		conditions.MarkTrue(machine, clusterv1.MachineRebootRemediatedCondition)
+	}
+}
+
+// splitRebootedTargets splits the unhealthy targets between the ones whose reboot has been requested,
+// which are either waiting to become healthy or to be escalated, and the other ones.
+func splitRebootedTargets(mhc *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget) ([]healthCheckTarget, []healthCheckTarget) {
+	if !rebootFirst(mhc) {
+		return nil, unhealthy
+	}
+
+	var rebooted, notRebooted []healthCheckTarget
+	for _, t := range unhealthy {
+		if rebootRequestedAt(t.Machine) != nil {
+			rebooted = append(rebooted, t)
+			continue
+		}
+		notRebooted = append(notRebooted, t)
+	}
+	return rebooted, notRebooted
+}
+
+// rebootRetryTimes returns the durations after which the unhealthy targets, which are waiting to become healthy
+// after a reboot, should have their remediation escalated.
+func rebootRetryTimes(mhc *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget, now time.Time) []time.Duration {
+	if !rebootFirst(mhc) {
+		return nil
+	}
+
+	var retryTimes []time.Duration
+	for _, t := range unhealthy {
+		if step, wait := nextRemediationStep(mhc, t.Machine, now); step == waitForRebootStep {
+			retryTimes = append(retryTimes, wait)
+		}
+	}
+	return retryTimes
+}
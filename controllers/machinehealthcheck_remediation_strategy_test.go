@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestNextRemediationStep(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rebootFirstMHC := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			RemediationStrategy: &clusterv1.RemediationStrategy{
+				Type:          clusterv1.RebootFirstRemediationStrategyType,
+				RebootTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}
+	rebootRequested := func(ago time.Duration) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					clusterv1.RebootRequestedAnnotation: now.Add(-ago).UTC().Format(time.RFC3339),
+				},
+			},
+		}
+	}
+	escalated := &clusterv1.Machine{}
+	conditions.MarkFalse(escalated, clusterv1.MachineRebootRemediatedCondition, clusterv1.RebootTimedOutReason, clusterv1.ConditionSeverityWarning, "")
+
+	tests := []struct {
+		name         string
+		mhc          *clusterv1.MachineHealthCheck
+		machine      *clusterv1.Machine
+		expectedStep remediationStep
+		expectedWait time.Duration
+	}{
+		{
+			name:         "without remediation strategy",
+			mhc:          &clusterv1.MachineHealthCheck{},
+			machine:      &clusterv1.Machine{},
+			expectedStep: remediateStep,
+		},
+		{
+			name: "with the Delete remediation strategy",
+			mhc: &clusterv1.MachineHealthCheck{
+				Spec: clusterv1.MachineHealthCheckSpec{
+					RemediationStrategy: &clusterv1.RemediationStrategy{Type: clusterv1.DeleteRemediationStrategyType},
+				},
+			},
+			machine:      &clusterv1.Machine{},
+			expectedStep: remediateStep,
+		},
+		{
+			name:         "when the reboot has not been requested yet",
+			mhc:          rebootFirstMHC,
+			machine:      &clusterv1.Machine{},
+			expectedStep: rebootStep,
+		},
+		{
+			name: "when the reboot request is invalid",
+			mhc:  rebootFirstMHC,
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{clusterv1.RebootRequestedAnnotation: "invalid"},
+				},
+			},
+			expectedStep: rebootStep,
+		},
+		{
+			name:         "when the reboot has been requested within the reboot timeout",
+			mhc:          rebootFirstMHC,
+			machine:      rebootRequested(4 * time.Minute),
+			expectedStep: waitForRebootStep,
+			expectedWait: 6 * time.Minute,
+		},
+		{
+			name:         "when the reboot timeout has expired",
+			mhc:          rebootFirstMHC,
+			machine:      rebootRequested(11 * time.Minute),
+			expectedStep: escalateStep,
+		},
+		{
+			name:         "when the remediation has already been escalated",
+			mhc:          rebootFirstMHC,
+			machine:      escalated,
+			expectedStep: remediateStep,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			step, wait := nextRemediationStep(tt.mhc, tt.machine, now)
+			g.Expect(step).To(Equal(tt.expectedStep))
+			g.Expect(wait).To(Equal(tt.expectedWait))
+		})
+	}
+}
+
+func TestRebootRemediation(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now().Truncate(time.Second)
+	mhc := &clusterv1.MachineHealthCheck{
+		Spec: clusterv1.MachineHealthCheckSpec{
+			RemediationStrategy: &clusterv1.RemediationStrategy{
+				Type:          clusterv1.RebootFirstRemediationStrategyType,
+				RebootTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine"}}
+
+	// Request the reboot.
+	requestReboot(mhc, machine, now)
+	g.Expect(machine.Annotations).To(HaveKeyWithValue(clusterv1.RebootRequestedAnnotation, now.UTC().Format(time.RFC3339)))
+	g.Expect(conditions.IsFalse(machine, clusterv1.MachineRebootRemediatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.MachineRebootRemediatedCondition)).To(Equal(clusterv1.RebootRequestedReason))
+	g.Expect(rebootRetryTimes(mhc, []healthCheckTarget{{Machine: machine}}, now)).To(ConsistOf(10 * time.Minute))
+
+	// Escalate the remediation.
+	escalateReboot(mhc, machine)
+	g.Expect(machine.Annotations).NotTo(HaveKey(clusterv1.RebootRequestedAnnotation))
+	g.Expect(conditions.IsFalse(machine, clusterv1.MachineRebootRemediatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.MachineRebootRemediatedCondition)).To(Equal(clusterv1.RebootTimedOutReason))
+	g.Expect(rebootRetryTimes(mhc, []healthCheckTarget{{Machine: machine}}, now)).To(BeEmpty())
+
+	// The Machine becomes healthy again.
+	requestReboot(mhc, machine, now)
+	resetRebootRemediation(machine)
+	g.Expect(machine.Annotations).NotTo(HaveKey(clusterv1.RebootRequestedAnnotation))
+	g.Expect(conditions.IsTrue(machine, clusterv1.MachineRebootRemediatedCondition)).To(BeTrue())
+
+	// Machines which have never been rebooted are left untouched.
+	healthy := &clusterv1.Machine{}
+	resetRebootRemediation(healthy)
+	g.Expect(conditions.Has(healthy, clusterv1.MachineRebootRemediatedCondition)).To(BeFalse())
+}
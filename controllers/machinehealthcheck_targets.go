@@ -82,7 +82,10 @@ func (t *healthCheckTarget) nodeName() string {
 // - The Machine has failed for some reason
 // - The Machine did not get a node before `timeoutForMachineToHaveNode` elapses
 // - The Node has gone away
+// - Any condition on the machine is matched for the given timeout
 // - Any condition on the node is matched for the given timeout
+// - Any taint on the node is matched for the given timeout
+// - The node has been drained and is not ready for the given timeout
 // If the target doesn't currently need rememdiation, provide a duration after
 // which the target should next be checked.
 // The target should be requeued after this duration.
@@ -123,12 +126,35 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 		return false, 0
 	}
 
+	// check machine conditions
+	for _, c := range t.MHC.Spec.UnhealthyMachineConditions {
+		machineCondition := conditions.Get(t.Machine, c.Type)
+
+		// Skip when current machine condition is different from the one reported
+		// in the MachineHealthCheck.
+		if machineCondition == nil || machineCondition.Status != c.Status {
+			continue
+		}
+
+		if machineCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyMachineConditionReason, clusterv1.ConditionSeverityWarning, "Condition %s on machine is reporting status %s for more than %s", c.Type, c.Status, c.Timeout.Duration.String())
+			logger.V(3).Info("Target is unhealthy: machine condition is in state longer than allowed timeout", "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
+			return true, time.Duration(0)
+		}
+
+		durationUnhealthy := now.Sub(machineCondition.LastTransitionTime.Time)
+		nextCheck := c.Timeout.Duration - durationUnhealthy + time.Second
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+	}
+
 	// the node has not been set yet
 	if t.Node == nil {
 		if timeoutForMachineToHaveNode == disabledNodeStartupTimeout {
 			// Startup timeout is disabled so no need to go any further.
 			// No node yet to check conditions, can return early here.
-			return false, 0
+			return false, minDuration(nextCheckTimes)
 		}
 
 		controlPlaneInitializedTime := conditions.GetLastTransitionTime(t.Cluster, clusterv1.ControlPlaneInitializedCondition).Time
@@ -155,7 +181,7 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 		durationUnhealthy := now.Sub(comparisonTime)
 		nextCheck := timeoutForMachineToHaveNode.Duration - durationUnhealthy + time.Second
 
-		return false, nextCheck
+		return false, minDuration(append(nextCheckTimes, nextCheck))
 	}
 
 	// check conditions
@@ -182,9 +208,99 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 		}
 	}
+
+	// check taints
+	for _, c := range t.MHC.Spec.UnhealthyNodeTaints {
+		taintedSince := t.nodeTaintedSince(c)
+		if taintedSince == nil {
+			continue
+		}
+
+		if taintedSince.Add(c.Timeout.Duration).Before(now) {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeTaintReason, clusterv1.ConditionSeverityWarning, "Taint %s on node is present for more than %s", c.Key, c.Timeout.Duration.String())
+			logger.V(3).Info("Target is unhealthy: taint is present longer than allowed timeout", "taint", c.Key, "timeout", c.Timeout.Duration.String())
+			return true, time.Duration(0)
+		}
+
+		durationUnhealthy := now.Sub(taintedSince.Time)
+		nextCheck := c.Timeout.Duration - durationUnhealthy + time.Second
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+	}
+
+	// check drained node readiness
+	if timeout := t.MHC.Spec.DrainedNodeNotReadyTimeout; timeout != nil {
+		if notReadySince := t.drainedNodeNotReadySince(); notReadySince != nil {
+			if notReadySince.Add(timeout.Duration).Before(now) {
+				conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.DrainedNodeNotReadyReason, clusterv1.ConditionSeverityWarning, "Node has been drained and is not ready for more than %s", timeout.Duration.String())
+				logger.V(3).Info("Target is unhealthy: drained node is not ready longer than allowed timeout", "timeout", timeout.Duration.String())
+				return true, time.Duration(0)
+			}
+
+			durationUnhealthy := now.Sub(notReadySince.Time)
+			nextCheck := timeout.Duration - durationUnhealthy + time.Second
+			if nextCheck > 0 {
+				nextCheckTimes = append(nextCheckTimes, nextCheck)
+			}
+		}
+	}
 	return false, minDuration(nextCheckTimes)
 }
 
+// nodeTaintedSince returns the time since the node has a taint matching the given UnhealthyNodeTaint,
+// or nil if the node does not have a matching taint.
+// For taints not reporting when they have been added, the last transition time of the node Ready condition is used.
+func (t *healthCheckTarget) nodeTaintedSince(c clusterv1.UnhealthyNodeTaint) *metav1.Time {
+	var since *metav1.Time
+	for i := range t.Node.Spec.Taints {
+		taint := &t.Node.Spec.Taints[i]
+		if taint.Key != c.Key || (c.Effect != "" && taint.Effect != c.Effect) {
+			continue
+		}
+
+		taintTime := taint.TimeAdded
+		if taintTime == nil {
+			readyCondition := getNodeCondition(t.Node, corev1.NodeReady)
+			if readyCondition == nil {
+				continue
+			}
+			taintTime = &readyCondition.LastTransitionTime
+		}
+		if since == nil || taintTime.Before(since) {
+			since = taintTime
+		}
+	}
+	return since
+}
+
+// drainedNodeNotReadySince returns the time since the node has been drained and not ready,
+// or nil if the node is not drained or is ready.
+func (t *healthCheckTarget) drainedNodeNotReadySince() *metav1.Time {
+	if !t.Node.Spec.Unschedulable {
+		return nil
+	}
+
+	readyCondition := getNodeCondition(t.Node, corev1.NodeReady)
+	if readyCondition == nil || readyCondition.Status == corev1.ConditionTrue {
+		return nil
+	}
+
+	// Use the latest between the time the node became not ready and the time it has been drained.
+	since := readyCondition.LastTransitionTime.DeepCopy()
+	for _, taint := range t.Node.Spec.Taints {
+		if taint.Key == corev1.TaintNodeUnschedulable && taint.TimeAdded != nil && since.Before(taint.TimeAdded) {
+			since = taint.TimeAdded.DeepCopy()
+		}
+	}
+	if conditions.IsTrue(t.Machine, clusterv1.DrainingSucceededCondition) {
+		if drainedTime := conditions.GetLastTransitionTime(t.Machine, clusterv1.DrainingSucceededCondition); drainedTime != nil && since.Before(drainedTime) {
+			since = drainedTime.DeepCopy()
+		}
+	}
+	return since
+}
+
 // getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
 // and their nodes targeted by the health check, ready for health checking.
 func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, logger logr.Logger, clusterClient client.Reader, cluster *clusterv1.Cluster, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
@@ -411,6 +411,180 @@ func TestHealthCheckTargets(t *testing.T) {
 	}
 }
 
+func TestHealthCheckTargetsCustomSignals(t *testing.T) {
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+
+	testMHC := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mhc",
+			Namespace: namespace,
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: mhcSelector,
+			},
+			ClusterName: clusterName,
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 1 * time.Hour},
+				},
+			},
+			UnhealthyMachineConditions: []clusterv1.UnhealthyMachineCondition{
+				{
+					Type:    "InstanceHealthy",
+					Status:  corev1.ConditionFalse,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			UnhealthyNodeTaints: []clusterv1.UnhealthyNodeTaint{
+				{
+					Key:     corev1.TaintNodeUnreachable,
+					Effect:  corev1.TaintEffectNoExecute,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			DrainedNodeNotReadyTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	testMachine := newTestMachine("machine1", namespace, clusterName, "node1", mhcSelector)
+
+	machineWithCondition := func(unhealthyDuration time.Duration) *clusterv1.Machine {
+		m := testMachine.DeepCopy()
+		m.Status.Conditions = clusterv1.Conditions{
+			{
+				Type:               "InstanceHealthy",
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-unhealthyDuration)),
+			},
+		}
+		return m
+	}
+	machineCondition200 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: machineWithCondition(200 * time.Second), Node: newTestNode("node1")}
+	machineCondition400 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: machineWithCondition(400 * time.Second), Node: newTestNode("node1")}
+
+	nodeWithTaint := func(effect corev1.TaintEffect, taintedDuration time.Duration) *corev1.Node {
+		n := newTestUnhealthyNode("node1", corev1.NodeReady, corev1.ConditionUnknown, taintedDuration)
+		timeAdded := metav1.NewTime(time.Now().Add(-taintedDuration))
+		n.Spec.Taints = []corev1.Taint{{Key: corev1.TaintNodeUnreachable, Effect: effect, TimeAdded: &timeAdded}}
+		return n
+	}
+	nodeTaint200 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: testMachine, Node: nodeWithTaint(corev1.TaintEffectNoExecute, 200*time.Second)}
+	nodeTaint400 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: testMachine, Node: nodeWithTaint(corev1.TaintEffectNoExecute, 400*time.Second)}
+	nodeTaintOtherEffect := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: testMachine, Node: nodeWithTaint(corev1.TaintEffectNoSchedule, 400*time.Second)}
+
+	drainedNode := func(notReadyDuration time.Duration) *corev1.Node {
+		n := newTestUnhealthyNode("node1", corev1.NodeReady, corev1.ConditionFalse, notReadyDuration)
+		n.Spec.Unschedulable = true
+		return n
+	}
+	drainedNotReady200 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: testMachine, Node: drainedNode(200 * time.Second)}
+	drainedNotReady400 := healthCheckTarget{Cluster: cluster, MHC: testMHC, Machine: testMachine, Node: drainedNode(400 * time.Second)}
+
+	testCases := []struct {
+		desc                     string
+		targets                  []healthCheckTarget
+		expectedHealthy          []healthCheckTarget
+		expectedNeedsRemediation []healthCheckTarget
+		expectedNextCheckTimes   []time.Duration
+		expectedReason           string
+	}{
+		{
+			desc:                     "when the machine condition is unhealthy for shorter than the timeout",
+			targets:                  []healthCheckTarget{machineCondition200},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{100 * time.Second},
+		},
+		{
+			desc:                     "when the machine condition is unhealthy for longer than the timeout",
+			targets:                  []healthCheckTarget{machineCondition400},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{machineCondition400},
+			expectedNextCheckTimes:   []time.Duration{},
+			expectedReason:           clusterv1.UnhealthyMachineConditionReason,
+		},
+		{
+			desc:                     "when the node has the taint for shorter than the timeout",
+			targets:                  []healthCheckTarget{nodeTaint200},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{100 * time.Second},
+		},
+		{
+			desc:                     "when the node has the taint for longer than the timeout",
+			targets:                  []healthCheckTarget{nodeTaint400},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeTaint400},
+			expectedNextCheckTimes:   []time.Duration{},
+			expectedReason:           clusterv1.UnhealthyNodeTaintReason,
+		},
+		{
+			desc:                     "when the node has the taint with a different effect",
+			targets:                  []healthCheckTarget{nodeTaintOtherEffect},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{3200 * time.Second},
+		},
+		{
+			desc:                     "when the drained node is not ready for shorter than the timeout",
+			targets:                  []healthCheckTarget{drainedNotReady200},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{100 * time.Second},
+		},
+		{
+			desc:                     "when the drained node is not ready for longer than the timeout",
+			targets:                  []healthCheckTarget{drainedNotReady400},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{drainedNotReady400},
+			expectedNextCheckTimes:   []time.Duration{},
+			expectedReason:           clusterv1.DrainedNodeNotReadyReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gs := NewWithT(t)
+
+			reconciler := &MachineHealthCheckReconciler{
+				recorder: record.NewFakeRecorder(5),
+			}
+
+			healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(tc.targets, ctrl.LoggerFrom(ctx), metav1.Duration{Duration: 10 * time.Minute})
+
+			roundDurations := func(in []time.Duration) []time.Duration {
+				out := []time.Duration{}
+				for _, d := range in {
+					out = append(out, d.Truncate(time.Second))
+				}
+				return out
+			}
+
+			gs.Expect(healthy).To(ConsistOf(tc.expectedHealthy))
+			gs.Expect(unhealthy).To(ConsistOf(tc.expectedNeedsRemediation))
+			gs.Expect(nextCheckTimes).To(WithTransform(roundDurations, ConsistOf(tc.expectedNextCheckTimes)))
+			for _, u := range unhealthy {
+				gs.Expect(conditions.GetReason(u.Machine, clusterv1.MachineHealthCheckSuccededCondition)).To(Equal(tc.expectedReason))
+			}
+		})
+	}
+}
+
 func newTestMachine(name, namespace, clusterName, nodeName string, labels map[string]string) *clusterv1.Machine {
 	// Copy the labels so that the map is unique to each test Machine
 	l := make(map[string]string)
@@ -28,9 +28,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/internal/mdutil"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
@@ -38,6 +40,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/cluster-api/util/failuredomains"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -289,7 +292,12 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrap(err, "failed to remediate machines")
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
+	// Propagate in place the labels, annotations and node timeouts of the machine template to the existing Machines.
+	if err := r.syncMachines(ctx, machineSet, filteredMachines); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to update Machines")
+	}
+
+	syncErr := r.syncReplicas(ctx, cluster, machineSet, filteredMachines)
 
 	// Always updates status as machines come up or die.
 	if err := r.updateStatus(ctx, cluster, machineSet, filteredMachines); err != nil {
@@ -327,8 +335,80 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 	return ctrl.Result{}, nil
 }
 
+// syncMachines updates the Machines of a MachineSet with the fields of the machine template that can be
+// propagated in place, i.e. labels, annotations and node timeouts.
+// NOTE: Labels removed from the machine template are removed from the Machines, while other labels and
+// annotations are never removed, given that they could have been set by other actors.
+func (r *MachineSetReconciler) syncMachines(ctx context.Context, machineSet *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+	for _, m := range machines {
+		patchHelper, err := patch.NewHelper(m, r.Client)
+		if err != nil {
+			return err
+		}
+
+		changed := syncMachineLabels(m, machineSet.Spec.Template.Labels)
+		if annotations.AddAnnotations(m, machineSet.Spec.Template.Annotations) {
+			changed = true
+		}
+
+		// The Machine webhook defaults nodeDeletionTimeout, so a nil value in a machine template which has not been
+		// defaulted yet is not propagated; otherwise, the Machine would be patched back and forth on every reconcile.
+		desiredSpec := machineSet.Spec.Template.Spec
+		if desiredSpec.NodeDeletionTimeout == nil {
+			desiredSpec.NodeDeletionTimeout = m.Spec.NodeDeletionTimeout
+		}
+		if mdutil.SyncMachineSpecNodeTimeouts(&m.Spec, &desiredSpec) {
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if err := patchHelper.Patch(ctx, m); err != nil {
+			return errors.Wrapf(err, "failed to update Machine %q", m.Name)
+		}
+	}
+	return nil
+}
+
+// syncMachineLabels sets the labels of the machine template on the Machine and removes the labels previously
+// propagated from the machine template which are not present anymore, as tracked by the LabelsFromMachineSetAnnotation.
+// It returns true if the Machine has been changed.
+func syncMachineLabels(m *clusterv1.Machine, templateLabels map[string]string) bool {
+	changed := false
+	if m.Labels == nil {
+		m.Labels = map[string]string{}
+	}
+
+	if previous, ok := m.Annotations[clusterv1.LabelsFromMachineSetAnnotation]; ok && previous != "" {
+		for _, key := range strings.Split(previous, ",") {
+			if _, ok := templateLabels[key]; ok {
+				continue
+			}
+			if _, ok := m.Labels[key]; ok {
+				delete(m.Labels, key)
+				changed = true
+			}
+		}
+	}
+
+	for key, value := range templateLabels {
+		if current, ok := m.Labels[key]; !ok || current != value {
+			m.Labels[key] = value
+			changed = true
+		}
+	}
+
+	if annotations.AddAnnotations(m, map[string]string{
+		clusterv1.LabelsFromMachineSetAnnotation: strings.Join(sets.StringKeySet(templateLabels).List(), ","),
+	}) {
+		changed = true
+	}
+	return changed
+}
+
 // syncReplicas scales Machine resources up or down.
-func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) syncReplicas(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
 	log := ctrl.LoggerFrom(ctx)
 	if ms.Spec.Replicas == nil {
 		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
@@ -349,11 +429,24 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			errs        []error
 		)
 
+		// Keep track of the created Machines, so new Machines are spread across failure domains.
+		currentMachines, err := r.getMachinesForFailureDomainSpread(ctx, ms, machines)
+		if err != nil {
+			return err
+		}
+		if ms.Spec.SpreadFailureDomains && len(cluster.Status.FailureDomains) == 0 {
+			log.Info("Cluster does not report any failure domain, creating Machines without a failure domain")
+			r.recorder.Eventf(ms, corev1.EventTypeWarning, "NoFailureDomains", "Cluster %q does not report any failure domain to spread Machines across, creating Machines without a failure domain", cluster.Name)
+		}
+
 		for i := 0; i < diff; i++ {
 			log.Info(fmt.Sprintf("Creating machine %d of %d, ( spec.replicas(%d) > currentMachineCount(%d) )",
 				i+1, diff, *(ms.Spec.Replicas), len(machines)))
 
 			machine := r.getNewMachine(ms)
+			if fd := failureDomainForNewMachine(cluster, ms, currentMachines); fd != nil {
+				machine.Spec.FailureDomain = fd
+			}
 
 			// Clone and set the infrastructure and bootstrap references.
 			var (
@@ -412,6 +505,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			log.Info(fmt.Sprintf("Created machine %d of %d with name %q", i+1, diff, machine.Name))
 			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
 			machineList = append(machineList, machine)
+			currentMachines.Insert(machine)
 		}
 
 		if len(errs) > 0 {
@@ -421,14 +515,18 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 	case diff > 0:
 		log.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
-		deletePriorityFunc, err := getDeletePriorityFunc(ms)
+		deletePolicy, err := getDeletePolicy(ctx, r, ms)
 		if err != nil {
 			return err
 		}
 		log.Info("Found delete policy", "delete-policy", ms.Spec.DeletePolicy)
 
+		machinesToDelete, err := deletePolicy.machinesToDelete(ctx, machines, diff)
+		if err != nil {
+			return err
+		}
+
 		var errs []error
-		machinesToDelete := getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
 		for _, machine := range machinesToDelete {
 			if err := r.Client.Delete(ctx, machine); err != nil {
 				log.Error(err, "Unable to delete Machine", "machine", machine.Name)
@@ -449,6 +547,41 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 	return nil
 }
 
+// getMachinesForFailureDomainSpread returns the Machines to take into account when spreading the Machines of the
+// MachineSet across failure domains. If the MachineSet is owned by a MachineDeployment, these are the Machines of
+// all its MachineSets, so the MachineDeployment as a whole stays spread across failure domains, e.g. during a rollout.
+func (r *MachineSetReconciler) getMachinesForFailureDomainSpread(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) (collections.Machines, error) {
+	result := collections.FromMachines(machines...)
+	if !ms.Spec.SpreadFailureDomains {
+		return result, nil
+	}
+
+	owner := metav1.GetControllerOf(ms)
+	if owner == nil || owner.Kind != machineDeploymentKind.Kind {
+		return result, nil
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, client.InNamespace(ms.Namespace), client.MatchingLabels{clusterv1.MachineDeploymentLabelName: owner.Name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Machines of MachineDeployment %s", owner.Name)
+	}
+	for i := range machineList.Items {
+		result.Insert(&machineList.Items[i])
+	}
+	return result, nil
+}
+
+// failureDomainForNewMachine returns the failure domain with the fewest Machines among the Cluster's failure domains,
+// or nil if the MachineSet does not spread its Machines across failure domains or the Cluster has no failure domains.
+// NOTE: FailureDomainSpec.ControlPlane marks failure domains which are suitable for control plane Machines,
+// not reserved to them, so all the failure domains are used.
+func failureDomainForNewMachine(cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines collections.Machines) *string {
+	if !ms.Spec.SpreadFailureDomains {
+		return nil
+	}
+	return failuredomains.PickFewest(cluster.Status.FailureDomains, machines)
+}
+
 // getNewMachine creates a new Machine object. The name of the newly created resource is going
 // to be created by the API server, we set the generateName field.
 func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *clusterv1.Machine {
@@ -26,10 +26,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/internal/builder"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -712,6 +714,139 @@ func TestAdoptOrphan(t *testing.T) {
 	}
 }
 
+func TestMachineSetSyncMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := newMachineSet("ms1", "test-cluster", 1)
+	ms.Spec.Template.Labels["new-label"] = "new-value"
+	ms.Spec.Template.Annotations = map[string]string{"new-annotation": "new-value"}
+	ms.Spec.Template.Spec.NodeDrainTimeout = &metav1.Duration{Duration: 10 * time.Second}
+	ms.Spec.Template.Spec.NodeVolumeDetachTimeout = &metav1.Duration{Duration: 20 * time.Second}
+	ms.Spec.Template.Spec.NodeDeletionTimeout = &metav1.Duration{Duration: 30 * time.Second}
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine1",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: "test-cluster",
+				"new-label":                "old-value",
+				"other-label":              "other-value",
+			},
+			Annotations: map[string]string{
+				"other-annotation": "other-value",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:      "test-cluster",
+			NodeDrainTimeout: &metav1.Duration{Duration: 5 * time.Second},
+		},
+	}
+
+	r := &MachineSetReconciler{
+		Client: fake.NewClientBuilder().WithObjects(m).Build(),
+	}
+	g.Expect(r.syncMachines(ctx, ms, []*clusterv1.Machine{m.DeepCopy()})).To(Succeed())
+
+	got := &clusterv1.Machine{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), got)).To(Succeed())
+	g.Expect(got.Labels).To(Equal(map[string]string{
+		clusterv1.ClusterLabelName: "test-cluster",
+		"new-label":                "new-value",
+		"other-label":              "other-value",
+	}))
+	g.Expect(got.Annotations).To(Equal(map[string]string{
+		"new-annotation":                         "new-value",
+		"other-annotation":                       "other-value",
+		clusterv1.LabelsFromMachineSetAnnotation: clusterv1.ClusterLabelName + ",new-label",
+	}))
+	g.Expect(got.Spec.NodeDrainTimeout).To(Equal(ms.Spec.Template.Spec.NodeDrainTimeout))
+	g.Expect(got.Spec.NodeVolumeDetachTimeout).To(Equal(ms.Spec.Template.Spec.NodeVolumeDetachTimeout))
+	g.Expect(got.Spec.NodeDeletionTimeout).To(Equal(ms.Spec.Template.Spec.NodeDeletionTimeout))
+
+	// A second sync does not change the Machine.
+	g.Expect(r.syncMachines(ctx, ms, []*clusterv1.Machine{got.DeepCopy()})).To(Succeed())
+	gotAgain := &clusterv1.Machine{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), gotAgain)).To(Succeed())
+	g.Expect(gotAgain.ResourceVersion).To(Equal(got.ResourceVersion))
+}
+
+func TestMachineSetSyncMachinesRemovesLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := newMachineSet("ms1", "test-cluster", 1)
+	ms.Spec.Template.Labels["node.cluster.x-k8s.io/pool"] = "a"
+	ms.Spec.Template.Labels["kept-label"] = "kept-value"
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine1",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				"other-label": "other-value",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+		},
+	}
+
+	r := &MachineSetReconciler{
+		Client: fake.NewClientBuilder().WithObjects(m).Build(),
+	}
+	g.Expect(r.syncMachines(ctx, ms, []*clusterv1.Machine{m.DeepCopy()})).To(Succeed())
+
+	got := &clusterv1.Machine{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), got)).To(Succeed())
+	g.Expect(got.Labels).To(HaveKeyWithValue("node.cluster.x-k8s.io/pool", "a"))
+
+	// Labels removed from the machine template are removed from the Machine, while other labels are preserved.
+	delete(ms.Spec.Template.Labels, "node.cluster.x-k8s.io/pool")
+	g.Expect(r.syncMachines(ctx, ms, []*clusterv1.Machine{got.DeepCopy()})).To(Succeed())
+
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), got)).To(Succeed())
+	g.Expect(got.Labels).NotTo(HaveKey("node.cluster.x-k8s.io/pool"))
+	g.Expect(got.Labels).To(HaveKeyWithValue("kept-label", "kept-value"))
+	g.Expect(got.Labels).To(HaveKeyWithValue("other-label", "other-value"))
+}
+
+func TestMachineSetSyncMachinesKeepsDefaultedNodeDeletionTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	// The machine template has not been defaulted by the MachineSet webhook, while the Machine has been
+	// defaulted by the Machine webhook.
+	ms := newMachineSet("ms1", "test-cluster", 1)
+	ms.Spec.Template.Spec.NodeDeletionTimeout = nil
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:         "test-cluster",
+			NodeDeletionTimeout: &metav1.Duration{Duration: 10 * time.Second},
+		},
+	}
+
+	r := &MachineSetReconciler{
+		Client: fake.NewClientBuilder().WithObjects(m).Build(),
+	}
+	for i := 0; i < 2; i++ {
+		before := &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), before)).To(Succeed())
+		g.Expect(r.syncMachines(ctx, ms, []*clusterv1.Machine{before.DeepCopy()})).To(Succeed())
+
+		got := &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(m), got)).To(Succeed())
+		g.Expect(got.Spec.NodeDeletionTimeout).To(Equal(m.Spec.NodeDeletionTimeout))
+		if i > 0 {
+			// The second sync does not patch the Machine.
+			g.Expect(got.ResourceVersion).To(Equal(before.ResourceVersion))
+		}
+	}
+}
+
 func newMachineSet(name, cluster string, replicas int32) *clusterv1.MachineSet {
 	return &clusterv1.MachineSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -740,6 +875,149 @@ func newMachineSet(name, cluster string, replicas int32) *clusterv1.MachineSet {
 	}
 }
 
+func TestFailureDomainForNewMachine(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		Status: clusterv1.ClusterStatus{
+			FailureDomains: clusterv1.FailureDomains{
+				"one":   clusterv1.FailureDomainSpec{ControlPlane: true},
+				"two":   clusterv1.FailureDomainSpec{},
+				"three": clusterv1.FailureDomainSpec{},
+			},
+		},
+	}
+	machineInFailureDomain := func(name, fd string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       clusterv1.MachineSpec{FailureDomain: pointer.String(fd)},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		spreadFailureDomains bool
+		failureDomains       clusterv1.FailureDomains
+		machines             collections.Machines
+		expected             *string
+	}{
+		{
+			name:                 "returns nil when not spreading Machines across failure domains",
+			spreadFailureDomains: false,
+			machines:             collections.FromMachines(machineInFailureDomain("m1", "one")),
+			expected:             nil,
+		},
+		{
+			name:                 "returns the failure domain with the fewest Machines",
+			spreadFailureDomains: true,
+			machines:             collections.FromMachines(machineInFailureDomain("m1", "one"), machineInFailureDomain("m2", "two")),
+			expected:             pointer.String("three"),
+		},
+		{
+			name:                 "uses failure domains suitable for control plane Machines",
+			spreadFailureDomains: true,
+			machines: collections.FromMachines(
+				machineInFailureDomain("m1", "two"),
+				machineInFailureDomain("m2", "two"),
+				machineInFailureDomain("m3", "three"),
+			),
+			expected: pointer.String("one"),
+		},
+		{
+			name:                 "uses failure domains when all of them are suitable for control plane Machines",
+			spreadFailureDomains: true,
+			failureDomains: clusterv1.FailureDomains{
+				"one": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"two": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+			machines: collections.FromMachines(machineInFailureDomain("m1", "one")),
+			expected: pointer.String("two"),
+		},
+		{
+			name:                 "returns nil when the Cluster has no failure domains",
+			spreadFailureDomains: true,
+			failureDomains:       clusterv1.FailureDomains{},
+			machines:             collections.FromMachines(machineInFailureDomain("m1", "one")),
+			expected:             nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := cluster.DeepCopy()
+			if tt.failureDomains != nil {
+				cluster.Status.FailureDomains = tt.failureDomains
+			}
+
+			ms := newMachineSet("ms1", "test-cluster", 1)
+			ms.Spec.SpreadFailureDomains = tt.spreadFailureDomains
+			g.Expect(failureDomainForNewMachine(cluster, ms, tt.machines)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestMachineSetReconciler_getMachinesForFailureDomainSpread(t *testing.T) {
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "md1", Namespace: metav1.NamespaceDefault},
+	}
+	machineOf := func(name, deployment string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: metav1.NamespaceDefault,
+				Labels:    map[string]string{clusterv1.MachineDeploymentLabelName: deployment},
+			},
+		}
+	}
+	ownMachine := machineOf("own", "md1")
+	// A Machine of another MachineSet of the same MachineDeployment, e.g. during a rollout.
+	siblingMachine := machineOf("sibling", "md1")
+	otherMachine := machineOf("other", "md2")
+
+	tests := []struct {
+		name                 string
+		spreadFailureDomains bool
+		ownedByDeployment    bool
+		expected             []string
+	}{
+		{
+			name:                 "returns the Machines of the MachineSet when not spreading Machines across failure domains",
+			spreadFailureDomains: false,
+			ownedByDeployment:    true,
+			expected:             []string{"own"},
+		},
+		{
+			name:                 "returns the Machines of the MachineSet when it is not owned by a MachineDeployment",
+			spreadFailureDomains: true,
+			ownedByDeployment:    false,
+			expected:             []string{"own"},
+		},
+		{
+			name:                 "returns the Machines of all the MachineSets of the owning MachineDeployment",
+			spreadFailureDomains: true,
+			ownedByDeployment:    true,
+			expected:             []string{"own", "sibling"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := newMachineSet("ms1", "test-cluster", 1)
+			ms.Spec.SpreadFailureDomains = tt.spreadFailureDomains
+			if tt.ownedByDeployment {
+				ms.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(md, machineDeploymentKind)}
+			}
+
+			r := &MachineSetReconciler{
+				Client: fake.NewClientBuilder().WithObjects(ownMachine.DeepCopy(), siblingMachine.DeepCopy(), otherMachine.DeepCopy()).Build(),
+			}
+			machines, err := r.getMachinesForFailureDomainSpread(ctx, ms, []*clusterv1.Machine{ownMachine.DeepCopy()})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(machines.Names()).To(ConsistOf(tt.expected))
+		})
+	}
+}
+
 func TestMachineSetReconcile_MachinesCreatedConditionFalseOnBadInfraRef(t *testing.T) {
 	g := NewWithT(t)
 	replicas := int32(1)
@@ -17,12 +17,17 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"math"
 	"sort"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type (
@@ -122,18 +127,220 @@ func getMachinesToDeletePrioritized(filteredMachines []*clusterv1.Machine, diff
 	return sortable.machines[:diff]
 }
 
-func getDeletePriorityFunc(ms *clusterv1.MachineSet) (deletePriorityFunc, error) {
-	// Map the Spec.DeletePolicy value to the appropriate delete priority function
-	switch msdp := clusterv1.MachineSetDeletePolicy(ms.Spec.DeletePolicy); msdp {
-	case clusterv1.RandomMachineSetDeletePolicy:
-		return randomDeletePolicy, nil
-	case clusterv1.NewestMachineSetDeletePolicy:
-		return newestDeletePriority, nil
-	case clusterv1.OldestMachineSetDeletePolicy:
-		return oldestDeletePriority, nil
-	case "":
-		return randomDeletePolicy, nil
-	default:
-		return nil, errors.Errorf("Unsupported delete policy %s. Must be one of 'Random', 'Newest', or 'Oldest'", msdp)
+// deletePolicy selects the Machines of a MachineSet to be deleted when scaling down.
+type deletePolicy interface {
+	// machinesToDelete returns the diff Machines which should be deleted first.
+	machinesToDelete(ctx context.Context, machines []*clusterv1.Machine, diff int) ([]*clusterv1.Machine, error)
+}
+
+// deletePolicyFactory returns the deletePolicy to be used for the given MachineSet.
+type deletePolicyFactory func(ctx context.Context, r *MachineSetReconciler, ms *clusterv1.MachineSet) (deletePolicy, error)
+
+// deletePolicies maps the values of MachineSet.Spec.DeletePolicy to the corresponding deletePolicy.
+// New policies only need to be added here; an empty value defaults to the Random policy.
+var deletePolicies = map[clusterv1.MachineSetDeletePolicy]deletePolicyFactory{
+	"":                                     priorityDeletePolicyFactory(randomDeletePolicy),
+	clusterv1.RandomMachineSetDeletePolicy: priorityDeletePolicyFactory(randomDeletePolicy),
+	clusterv1.NewestMachineSetDeletePolicy: priorityDeletePolicyFactory(newestDeletePriority),
+	clusterv1.OldestMachineSetDeletePolicy: priorityDeletePolicyFactory(oldestDeletePriority),
+	clusterv1.FailureDomainBalancedMachineSetDeletePolicy: newFailureDomainBalancedDeletePolicy,
+	clusterv1.LeastPodsMachineSetDeletePolicy:             newLeastPodsDeletePolicy,
+}
+
+func getDeletePolicy(ctx context.Context, r *MachineSetReconciler, ms *clusterv1.MachineSet) (deletePolicy, error) {
+	msdp := clusterv1.MachineSetDeletePolicy(ms.Spec.DeletePolicy)
+	factory, ok := deletePolicies[msdp]
+	if !ok {
+		return nil, errors.Errorf("Unsupported delete policy %s. Must be one of 'Random', 'Newest', 'Oldest', 'FailureDomainBalanced' or 'LeastPods'", msdp)
+	}
+	return factory(ctx, r, ms)
+}
+
+// priorityDeletePolicy deletes the Machines with the highest deletePriority first.
+type priorityDeletePolicy struct {
+	priority deletePriorityFunc
+}
+
+func priorityDeletePolicyFactory(fun deletePriorityFunc) deletePolicyFactory {
+	return func(_ context.Context, _ *MachineSetReconciler, _ *clusterv1.MachineSet) (deletePolicy, error) {
+		return priorityDeletePolicy{priority: fun}, nil
+	}
+}
+
+func (p priorityDeletePolicy) machinesToDelete(_ context.Context, machines []*clusterv1.Machine, diff int) ([]*clusterv1.Machine, error) {
+	return getMachinesToDeletePrioritized(machines, diff, p.priority), nil
+}
+
+// isDeletePreferred returns true if the Machine is being deleted, is marked for deletion, has no Node
+// or has failed; those Machines are always deleted first, regardless of the delete policy.
+func isDeletePreferred(machine *clusterv1.Machine) bool {
+	return randomDeletePolicy(machine) > couldDelete
+}
+
+// splitDeletePreferred returns the Machines to be deleted first, sorted from the highest to the lowest priority,
+// and the remaining Machines.
+func splitDeletePreferred(machines []*clusterv1.Machine) ([]*clusterv1.Machine, []*clusterv1.Machine) {
+	var preferred, remaining []*clusterv1.Machine
+	for _, m := range machines {
+		if isDeletePreferred(m) {
+			preferred = append(preferred, m)
+			continue
+		}
+		remaining = append(remaining, m)
+	}
+	sort.SliceStable(preferred, func(i, j int) bool {
+		return randomDeletePolicy(preferred[j]) < randomDeletePolicy(preferred[i])
+	})
+	return preferred, remaining
+}
+
+// failureDomainBalancedDeletePolicy deletes Machines from the failure domain with the most Machines first,
+// so that the remaining Machines are spread as evenly as possible across failure domains.
+// Within a failure domain the newest Machines are deleted first.
+type failureDomainBalancedDeletePolicy struct {
+	// others are the Machines of the other MachineSets of the MachineDeployment, which are taken into account
+	// when counting the Machines of a failure domain but are never deleted.
+	others collections.Machines
+}
+
+func newFailureDomainBalancedDeletePolicy(ctx context.Context, r *MachineSetReconciler, ms *clusterv1.MachineSet) (deletePolicy, error) {
+	machines, err := r.getMachinesForFailureDomainSpread(ctx, ms, nil)
+	if err != nil {
+		return nil, err
+	}
+	return failureDomainBalancedDeletePolicy{others: machines.Filter(func(m *clusterv1.Machine) bool {
+		return !metav1.IsControlledBy(m, ms)
+	})}, nil
+}
+
+func (p failureDomainBalancedDeletePolicy) machinesToDelete(_ context.Context, machines []*clusterv1.Machine, diff int) ([]*clusterv1.Machine, error) {
+	if diff >= len(machines) {
+		return machines, nil
+	} else if diff <= 0 {
+		return []*clusterv1.Machine{}, nil
+	}
+
+	result, remaining := splitDeletePreferred(machines)
+	if len(result) >= diff {
+		return result[:diff], nil
+	}
+
+	// Group the remaining Machines by failure domain, newest first, and count the Machines left in each
+	// failure domain including the ones of the other MachineSets.
+	machinesByFailureDomain := map[string][]*clusterv1.Machine{}
+	machinesPerFailureDomain := map[string]int{}
+	for _, m := range remaining {
+		fd := failureDomainOf(m)
+		machinesByFailureDomain[fd] = append(machinesByFailureDomain[fd], m)
+		machinesPerFailureDomain[fd]++
+	}
+	for _, m := range p.others {
+		machinesPerFailureDomain[failureDomainOf(m)]++
+	}
+	failureDomains := make([]string, 0, len(machinesByFailureDomain))
+	for fd, fdMachines := range machinesByFailureDomain {
+		failureDomains = append(failureDomains, fd)
+		sort.SliceStable(fdMachines, func(i, j int) bool {
+			return fdMachines[j].CreationTimestamp.Before(&fdMachines[i].CreationTimestamp)
+		})
+	}
+	sort.Strings(failureDomains)
+
+	// Pick one Machine at a time from the failure domain with the most Machines left.
+	for len(result) < diff {
+		selected, found := "", false
+		for _, fd := range failureDomains {
+			if len(machinesByFailureDomain[fd]) == 0 {
+				continue
+			}
+			if !found || machinesPerFailureDomain[fd] > machinesPerFailureDomain[selected] {
+				selected, found = fd, true
+			}
+		}
+		result = append(result, machinesByFailureDomain[selected][0])
+		machinesByFailureDomain[selected] = machinesByFailureDomain[selected][1:]
+		machinesPerFailureDomain[selected]--
+	}
+	return result, nil
+}
+
+func failureDomainOf(m *clusterv1.Machine) string {
+	if m.Spec.FailureDomain == nil {
+		return ""
+	}
+	return *m.Spec.FailureDomain
+}
+
+// leastPodsDeletePolicy deletes the Machines whose Node runs the fewest Pods first.
+// Pods managed by a DaemonSet or in a terminal phase are not counted.
+type leastPodsDeletePolicy struct {
+	// client is the ClusterCacheTracker client for the workload cluster. Pods are listed per candidate Node
+	// and must not be cached by the tracker, so the controller never has to watch all the Pods of the cluster.
+	client client.Reader
+}
+
+func newLeastPodsDeletePolicy(ctx context.Context, r *MachineSetReconciler, ms *clusterv1.MachineSet) (deletePolicy, error) {
+	if r.Tracker == nil {
+		return nil, errors.Errorf("delete policy %s requires access to the workload cluster", clusterv1.LeastPodsMachineSetDeletePolicy)
+	}
+	remoteClient, err := r.Tracker.GetClient(ctx, client.ObjectKey{Namespace: ms.Namespace, Name: ms.Spec.ClusterName})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get client for Cluster %s/%s", ms.Namespace, ms.Spec.ClusterName)
+	}
+	return leastPodsDeletePolicy{client: remoteClient}, nil
+}
+
+// podsOnNode returns the number of Pods counted by the LeastPods delete policy for the given Node.
+func (p leastPodsDeletePolicy) podsOnNode(ctx context.Context, nodeName string) (int, error) {
+	pods := &corev1.PodList{}
+	if err := p.client.List(ctx, pods, client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("spec.nodeName", nodeName)}); err != nil {
+		return 0, errors.Wrapf(err, "failed to list Pods on Node %s", nodeName)
+	}
+	return countPodsPerNode(pods.Items)[nodeName], nil
+}
+
+func countPodsPerNode(pods []corev1.Pod) map[string]int {
+	podsPerNode := map[string]int{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.Kind == "DaemonSet" {
+			continue
+		}
+		podsPerNode[pod.Spec.NodeName]++
+	}
+	return podsPerNode
+}
+
+func (p leastPodsDeletePolicy) machinesToDelete(ctx context.Context, machines []*clusterv1.Machine, diff int) ([]*clusterv1.Machine, error) {
+	if diff >= len(machines) {
+		return machines, nil
+	} else if diff <= 0 {
+		return []*clusterv1.Machine{}, nil
+	}
+
+	result, remaining := splitDeletePreferred(machines)
+	if len(result) >= diff {
+		return result[:diff], nil
+	}
+
+	// Machines without a NodeRef are always preferred, so all the remaining Machines have one.
+	podsPerNode := map[string]int{}
+	for _, m := range remaining {
+		nodeName := m.Status.NodeRef.Name
+		if _, ok := podsPerNode[nodeName]; ok {
+			continue
+		}
+		count, err := p.podsOnNode(ctx, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		podsPerNode[nodeName] = count
 	}
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return podsPerNode[remaining[i].Status.NodeRef.Name] < podsPerNode[remaining[j].Status.NodeRef.Name]
+	})
+	return append(result, remaining[:diff-len(result)]...), nil
 }
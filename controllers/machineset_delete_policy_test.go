@@ -17,13 +17,19 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestMachineToDelete(t *testing.T) {
@@ -395,3 +401,263 @@ func TestMachineOldestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineFailureDomainBalancedDelete(t *testing.T) {
+	now := metav1.Now()
+	nodeRef := &corev1.ObjectReference{Name: "some-node"}
+	newMachine := func(name, failureDomain string, age time.Duration) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     clusterv1.MachineStatus{NodeRef: nodeRef},
+		}
+		if failureDomain != "" {
+			m.Spec.FailureDomain = pointer.String(failureDomain)
+		}
+		return m
+	}
+
+	a1 := newMachine("a1", "a", 3*time.Hour)
+	a2 := newMachine("a2", "a", 2*time.Hour)
+	a3 := newMachine("a3", "a", 1*time.Hour)
+	b1 := newMachine("b1", "b", 3*time.Hour)
+	b2 := newMachine("b2", "b", 2*time.Hour)
+	c1 := newMachine("c1", "c", 1*time.Hour)
+	noFailureDomain := newMachine("none", "", 1*time.Hour)
+	deleteMachineWithMachineAnnotation := newMachine("annotated", "c", 5*time.Hour)
+	deleteMachineWithMachineAnnotation.Annotations = map[string]string{clusterv1.DeleteMachineAnnotation: ""}
+	otherA1 := newMachine("other-a1", "a", 4*time.Hour)
+	otherA2 := newMachine("other-a2", "a", 4*time.Hour)
+	otherA3 := newMachine("other-a3", "a", 4*time.Hour)
+
+	tests := []struct {
+		desc     string
+		machines []*clusterv1.Machine
+		// others are the Machines of the other MachineSets of the MachineDeployment.
+		others []*clusterv1.Machine
+		diff   int
+		expect []*clusterv1.Machine
+	}{
+		{
+			desc:     "diff=0",
+			machines: []*clusterv1.Machine{a1, b1},
+			diff:     0,
+			expect:   []*clusterv1.Machine{},
+		},
+		{
+			desc:     "diff>len(machines)",
+			machines: []*clusterv1.Machine{a1, b1},
+			diff:     3,
+			expect:   []*clusterv1.Machine{a1, b1},
+		},
+		{
+			desc:     "deletes the newest Machine from the largest failure domain",
+			machines: []*clusterv1.Machine{a1, b1, a2, c1, a3, b2},
+			diff:     1,
+			expect:   []*clusterv1.Machine{a3},
+		},
+		{
+			desc:     "spreads deletions across failure domains",
+			machines: []*clusterv1.Machine{a1, b1, a2, c1, a3, b2},
+			diff:     3,
+			expect:   []*clusterv1.Machine{a3, a2, b2},
+		},
+		{
+			desc:     "Machines without a failure domain are treated as one failure domain",
+			machines: []*clusterv1.Machine{a1, a2, noFailureDomain},
+			diff:     2,
+			expect:   []*clusterv1.Machine{a2, noFailureDomain},
+		},
+		{
+			desc:     "DeleteMachineAnnotation is deleted first",
+			machines: []*clusterv1.Machine{a1, a2, a3, deleteMachineWithMachineAnnotation},
+			diff:     2,
+			expect:   []*clusterv1.Machine{deleteMachineWithMachineAnnotation, a3},
+		},
+		{
+			desc:     "counts the Machines of the other MachineSets",
+			machines: []*clusterv1.Machine{a1, b1, b2},
+			others:   []*clusterv1.Machine{otherA1, otherA2},
+			diff:     1,
+			expect:   []*clusterv1.Machine{a1},
+		},
+		{
+			desc:     "never deletes the Machines of the other MachineSets",
+			machines: []*clusterv1.Machine{a1, a2, b1},
+			others:   []*clusterv1.Machine{otherA1, otherA2, otherA3},
+			diff:     2,
+			expect:   []*clusterv1.Machine{a2, a1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			policy := failureDomainBalancedDeletePolicy{others: collections.FromMachines(test.others...)}
+			result, err := policy.machinesToDelete(ctx, test.machines, test.diff)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result).To(Equal(test.expect))
+		})
+	}
+}
+
+func TestMachineLeastPodsDelete(t *testing.T) {
+	newMachine := func(name string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: name}},
+		}
+	}
+
+	empty := newMachine("empty")
+	small := newMachine("small")
+	large := newMachine("large")
+	noNodeRef := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "no-node"}}
+
+	podsOn := func(nodeName string, count int) []corev1.Pod {
+		pods := make([]corev1.Pod, count)
+		for i := range pods {
+			pods[i] = corev1.Pod{Spec: corev1.PodSpec{NodeName: nodeName}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+		}
+		return pods
+	}
+	podLister := &fakePodLister{pods: append(podsOn("small", 1), podsOn("large", 10)...)}
+	policy := leastPodsDeletePolicy{client: podLister}
+
+	tests := []struct {
+		desc     string
+		machines []*clusterv1.Machine
+		diff     int
+		expect   []*clusterv1.Machine
+		// nodes are the Nodes Pods are expected to be listed for.
+		nodes   []string
+		listErr error
+		wantErr bool
+	}{
+		{
+			desc:     "diff=0",
+			machines: []*clusterv1.Machine{large, small},
+			diff:     0,
+			expect:   []*clusterv1.Machine{},
+		},
+		{
+			desc:     "deletes the Machine with the fewest Pods",
+			machines: []*clusterv1.Machine{large, small, empty},
+			diff:     2,
+			expect:   []*clusterv1.Machine{empty, small},
+			nodes:    []string{"large", "small", "empty"},
+		},
+		{
+			desc:     "Machines without a NodeRef are deleted first",
+			machines: []*clusterv1.Machine{large, small, noNodeRef},
+			diff:     1,
+			expect:   []*clusterv1.Machine{noNodeRef},
+		},
+		{
+			desc:     "returns an error when listing Pods fails",
+			machines: []*clusterv1.Machine{large, small},
+			diff:     1,
+			listErr:  errors.New("boom"),
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			podLister.listedNodes = nil
+			podLister.err = test.listErr
+
+			result, err := policy.machinesToDelete(ctx, test.machines, test.diff)
+			if test.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result).To(Equal(test.expect))
+			g.Expect(podLister.listedNodes).To(Equal(test.nodes))
+		})
+	}
+}
+
+// fakePodLister is a client.Reader that only supports listing Pods by spec.nodeName,
+// which the fake client does not support.
+type fakePodLister struct {
+	client.Reader
+	pods        []corev1.Pod
+	listedNodes []string
+	err         error
+}
+
+func (f *fakePodLister) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if f.err != nil {
+		return f.err
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	nodeName, ok := listOpts.FieldSelector.RequiresExactMatch("spec.nodeName")
+	if !ok {
+		return errors.New("Pods must be listed by spec.nodeName")
+	}
+	f.listedNodes = append(f.listedNodes, nodeName)
+
+	podList := list.(*corev1.PodList)
+	podList.Items = nil
+	for _, pod := range f.pods {
+		if pod.Spec.NodeName == nodeName {
+			podList.Items = append(podList.Items, pod)
+		}
+	}
+	return nil
+}
+
+func TestCountPodsPerNode(t *testing.T) {
+	g := NewWithT(t)
+
+	pods := []corev1.Pod{
+		{Spec: corev1.PodSpec{NodeName: "node-1"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{Spec: corev1.PodSpec{NodeName: "node-1"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{Spec: corev1.PodSpec{NodeName: "node-1"}, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		{Spec: corev1.PodSpec{NodeName: "node-2"}, Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+		{Spec: corev1.PodSpec{}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds", Controller: pointer.Bool(true)}},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node-2"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+	g.Expect(countPodsPerNode(pods)).To(Equal(map[string]int{"node-1": 2}))
+}
+
+func TestGetDeletePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{policy: ""},
+		{policy: string(clusterv1.RandomMachineSetDeletePolicy)},
+		{policy: string(clusterv1.NewestMachineSetDeletePolicy)},
+		{policy: string(clusterv1.OldestMachineSetDeletePolicy)},
+		{policy: string(clusterv1.FailureDomainBalancedMachineSetDeletePolicy)},
+		{policy: "Unknown", wantErr: true},
+		// LeastPods requires access to the workload cluster.
+		{policy: string(clusterv1.LeastPodsMachineSetDeletePolicy), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &clusterv1.MachineSet{Spec: clusterv1.MachineSetSpec{DeletePolicy: tt.policy}}
+			_, err := getDeletePolicy(ctx, &MachineSetReconciler{}, ms)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -36,6 +37,7 @@ func (r *ClusterReconciler) getBlueprint(ctx context.Context, cluster *clusterv1
 		Topology:           cluster.Spec.Topology,
 		ClusterClass:       &clusterv1.ClusterClass{},
 		MachineDeployments: map[string]*scope.MachineDeploymentBlueprint{},
+		MachinePools:       map[string]*scope.MachinePoolBlueprint{},
 	}
 
 	// Get ClusterClass.
@@ -80,6 +82,9 @@ func (r *ClusterReconciler) getBlueprint(ctx context.Context, cluster *clusterv1
 		}
 	}
 
+	// Get the MachineHealthCheck for the control plane, if defined.
+	blueprint.ControlPlane.MachineHealthCheck = blueprint.ClusterClass.Spec.ControlPlane.MachineHealthCheck
+
 	// Loop over the machine deployments classes in ClusterClass
 	// and fetch the related templates.
 	for _, machineDeploymentClass := range blueprint.ClusterClass.Spec.Workers.MachineDeployments {
@@ -102,8 +107,38 @@ func (r *ClusterReconciler) getBlueprint(ctx context.Context, cluster *clusterv1
 			return nil, errors.Wrapf(err, "failed to get bootstrap machine template for %s, MachineDeployment class %q", tlog.KObj{Obj: blueprint.ClusterClass}, machineDeploymentClass.Class)
 		}
 
+		// Get the MachineHealthCheck for the MachineDeployment, if defined.
+		machineDeploymentBlueprint.MachineHealthCheck = machineDeploymentClass.MachineHealthCheck
+
 		blueprint.MachineDeployments[machineDeploymentClass.Class] = machineDeploymentBlueprint
 	}
 
+	// If the MachinePool feature flag is enabled, loop over the machine pool classes in ClusterClass
+	// and fetch the related templates.
+	if feature.Gates.Enabled(feature.MachinePool) {
+		for _, machinePoolClass := range blueprint.ClusterClass.Spec.Workers.MachinePools {
+			machinePoolBlueprint := &scope.MachinePoolBlueprint{}
+
+			// Make sure to copy the metadata from the blueprint, which is later layered
+			// with the additional metadata defined in the Cluster's topology section
+			// for the MachinePool that is created or updated.
+			machinePoolClass.Template.Metadata.DeepCopyInto(&machinePoolBlueprint.Metadata)
+
+			// Get the infrastructure machine pool template.
+			machinePoolBlueprint.InfrastructureMachinePoolTemplate, err = r.getReference(ctx, machinePoolClass.Template.Infrastructure.Ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get infrastructure machine pool template for %s, MachinePool class %q", tlog.KObj{Obj: blueprint.ClusterClass}, machinePoolClass.Class)
+			}
+
+			// Get the bootstrap template.
+			machinePoolBlueprint.BootstrapTemplate, err = r.getReference(ctx, machinePoolClass.Template.Bootstrap.Ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get bootstrap template for %s, MachinePool class %q", tlog.KObj{Obj: blueprint.ClusterClass}, machinePoolClass.Class)
+			}
+
+			blueprint.MachinePools[machinePoolClass.Class] = machinePoolBlueprint
+		}
+	}
+
 	return blueprint, nil
 }
@@ -23,10 +23,18 @@ import (
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/api/v1beta1/index"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/hooks"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimeclient "sigs.k8s.io/cluster-api/exp/runtime/client"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/predicates"
@@ -41,7 +49,10 @@ import (
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusterclasses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinehealthchecks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=runtime.cluster.x-k8s.io,resources=extensionconfigs,verbs=get;list;watch
 
 // ClusterReconciler reconciles a managed topology for a Cluster object.
 type ClusterReconciler struct {
@@ -55,7 +66,14 @@ type ClusterReconciler struct {
 	// thus allowing to optimize reads for templates or provider specific objects in a managed topology.
 	UnstructuredCachingClient client.Client
 
+	// RuntimeClient is used to call the extensions registered for lifecycle hooks.
+	// Defaults to a client reading ExtensionConfig objects with Client.
+	RuntimeClient runtimeclient.Client
+
 	externalTracker external.ObjectTracker
+
+	// patchEngine is used to apply patches during computeDesiredState.
+	patchEngine patches.Engine
 }
 
 func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -78,12 +96,37 @@ func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
 
+	// MachinePools are only part of a managed topology if the MachinePool feature flag is enabled.
+	if feature.Gates.Enabled(feature.MachinePool) {
+		if err := c.Watch(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			handler.EnqueueRequestsFromMapFunc(r.machinePoolToCluster),
+			predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
+		); err != nil {
+			return errors.Wrap(err, "failed adding Watch for MachinePools to controller manager")
+		}
+	}
+
 	r.externalTracker = external.ObjectTracker{
 		Controller: c,
 	}
+	r.patchEngine = patches.NewEngine(r.Client)
+	if r.RuntimeClient == nil {
+		r.RuntimeClient = runtimeclient.New(r.Client)
+	}
 	return nil
 }
 
+// SetupForDryRun prepares the ClusterReconciler for running against an in-memory client without a controller manager,
+// e.g. for computing a topology plan; in this case no watches are added for the Infrastructure and ControlPlane CRs.
+func (r *ClusterReconciler) SetupForDryRun() {
+	r.externalTracker = external.ObjectTracker{}
+	r.patchEngine = patches.NewEngine(r.Client)
+	if r.RuntimeClient == nil {
+		r.RuntimeClient = runtimeclient.New(r.Client)
+	}
+}
+
 func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -110,22 +153,31 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		return ctrl.Result{}, nil
 	}
 
-	// TODO: Add patching as soon as we define how to report managed topology state into conditions
+	// Create a scope initialized with only the cluster; during reconcile
+	// additional information will be added about the Cluster blueprint, current state and desired state.
+	s := scope.New(cluster)
+
+	defer func() {
+		// NOTE: Conditions are not reported anymore once the Cluster is marked as ok to delete,
+		// because from now on the Cluster can be gone at any time.
+		if !cluster.ObjectMeta.DeletionTimestamp.IsZero() && hooks.IsOkToDelete(cluster) {
+			return
+		}
+		if err := r.reconcileConditions(ctx, s, cluster, reterr); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, errors.Wrap(err, "failed to reconcile cluster topology conditions")})
+		}
+	}()
 
-	// In case the object is deleted, the managed topology stops to reconcile;
-	// (the other controllers will take care of deletion).
+	// In case the object is deleted, the managed topology stops to reconcile; the BeforeClusterDelete hook is called
+	// and the other controllers will take care of deletion once the hook is not blocking anymore.
 	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
 		// TODO: When external patching is supported, we should handle the deletion
 		// of those external CRDs we created.
-		return ctrl.Result{}, nil
+		return r.reconcileDelete(ctx, s)
 	}
 
-	// Create a scope initialized with only the cluster; during reconcile
-	// additional information will be added about the Cluster blueprint, current state and desired state.
-	scope := scope.New(cluster)
-
 	// Handle normal reconciliation loop.
-	return r.reconcile(ctx, scope)
+	return r.reconcile(ctx, s)
 }
 
 // reconcile handles cluster reconciliation.
@@ -145,6 +197,13 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, s *scope.Scope) (ctrl
 		return ctrl.Result{}, errors.Wrap(err, "error reading current state of the Cluster topology")
 	}
 
+	// Call the BeforeClusterCreate hook before the topology owned objects are created.
+	// If the hook is blocking, do not create anything and check again later.
+	res, err := r.callBeforeClusterCreateHook(ctx, s)
+	if err != nil || !res.IsZero() {
+		return res, err
+	}
+
 	// Watch Infrastructure and ControlPlane CRs when they exist.
 	if s.Current.InfrastructureCluster != nil {
 		if err := r.externalTracker.Watch(ctrl.LoggerFrom(ctx), s.Current.InfrastructureCluster,
@@ -170,6 +229,54 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, s *scope.Scope) (ctrl
 		return ctrl.Result{}, errors.Wrap(err, "error reconciling the Cluster topology")
 	}
 
+	// If a lifecycle hook is blocking, e.g. the upgrade of the control plane, check again later.
+	return ctrl.Result{RequeueAfter: s.HookResponseTracker.AggregateRetryAfter()}, nil
+}
+
+// callBeforeClusterCreateHook calls the BeforeClusterCreate hook if the topology owned objects are not created yet.
+// A non-zero result is returned if the hook is blocking.
+func (r *ClusterReconciler) callBeforeClusterCreateHook(ctx context.Context, s *scope.Scope) (ctrl.Result, error) {
+	// The objects are created only once, so the hook is called only until the Cluster references them.
+	if s.Current.Cluster.Spec.InfrastructureRef != nil || s.Current.Cluster.Spec.ControlPlaneRef != nil {
+		return ctrl.Result{}, nil
+	}
+
+	hookResponse, err := r.RuntimeClient.CallAllExtensions(ctx, runtimehooksv1.BeforeClusterCreate, &runtimehooksv1.BeforeClusterCreateRequest{
+		Cluster: *s.Current.Cluster,
+	})
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "error calling the %s hook", runtimehooksv1.BeforeClusterCreate)
+	}
+	s.HookResponseTracker.Add(runtimehooksv1.BeforeClusterCreate, hookResponse)
+	if hookResponse.RetryAfterSeconds > 0 {
+		tlog.LoggerFrom(ctx).Infof("Creation of the Cluster topology is blocked by the %s hook", runtimehooksv1.BeforeClusterCreate)
+		return ctrl.Result{RequeueAfter: s.HookResponseTracker.AggregateRetryAfter()}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileDelete calls the BeforeClusterDelete hook and marks the Cluster as ok to delete once the hook is not blocking anymore.
+func (r *ClusterReconciler) reconcileDelete(ctx context.Context, s *scope.Scope) (ctrl.Result, error) {
+	cluster := s.Current.Cluster
+	if hooks.IsOkToDelete(cluster) {
+		return ctrl.Result{}, nil
+	}
+
+	hookResponse, err := r.RuntimeClient.CallAllExtensions(ctx, runtimehooksv1.BeforeClusterDelete, &runtimehooksv1.BeforeClusterDeleteRequest{
+		Cluster: *cluster,
+	})
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "error calling the %s hook", runtimehooksv1.BeforeClusterDelete)
+	}
+	s.HookResponseTracker.Add(runtimehooksv1.BeforeClusterDelete, hookResponse)
+	if hookResponse.RetryAfterSeconds > 0 {
+		tlog.LoggerFrom(ctx).Infof("Deletion of the Cluster is blocked by the %s hook", runtimehooksv1.BeforeClusterDelete)
+		return ctrl.Result{RequeueAfter: s.HookResponseTracker.AggregateRetryAfter()}, nil
+	}
+
+	if err := hooks.MarkAsOkToDelete(ctx, r.Client, cluster); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -218,3 +325,22 @@ func (r *ClusterReconciler) machineDeploymentToCluster(o client.Object) []ctrl.R
 		},
 	}}
 }
+
+// machinePoolToCluster is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for Cluster to update when one of its own MachinePools gets updated.
+func (r *ClusterReconciler) machinePoolToCluster(o client.Object) []ctrl.Request {
+	mp, ok := o.(*expv1.MachinePool)
+	if !ok {
+		panic(fmt.Sprintf("Expected a MachinePool but got a %T", o))
+	}
+	if mp.Spec.ClusterName == "" {
+		return nil
+	}
+
+	return []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: mp.Namespace,
+			Name:      mp.Spec.ClusterName,
+		},
+	}}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/hooks"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCallBeforeClusterCreateHook(t *testing.T) {
+	tests := []struct {
+		name                string
+		controlPlaneRef     *corev1.ObjectReference
+		hookResponses       map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+		expectedResult      ctrl.Result
+		expectedCalledHooks []runtimehooksv1.Hook
+	}{
+		{
+			name:                "should continue if the hook is not blocking",
+			expectedCalledHooks: []runtimehooksv1.Hook{runtimehooksv1.BeforeClusterCreate},
+		},
+		{
+			name: "should requeue if the hook is blocking",
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.BeforeClusterCreate: blockingResponse(10),
+			},
+			expectedResult:      ctrl.Result{RequeueAfter: 10 * time.Second},
+			expectedCalledHooks: []runtimehooksv1.Hook{runtimehooksv1.BeforeClusterCreate},
+		},
+		{
+			name:            "should not call the hook if the topology is already created",
+			controlPlaneRef: &corev1.ObjectReference{Kind: "ControlPlane", Name: "cp1"},
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.BeforeClusterCreate: blockingResponse(10),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			cluster.Spec.ControlPlaneRef = tt.controlPlaneRef
+			s := scope.New(cluster)
+
+			runtimeClient := &fakeRuntimeClient{responses: tt.hookResponses}
+			r := &ClusterReconciler{
+				RuntimeClient: runtimeClient,
+			}
+			res, err := r.callBeforeClusterCreateHook(ctx, s)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res).To(Equal(tt.expectedResult))
+			g.Expect(runtimeClient.calledHooks).To(Equal(tt.expectedCalledHooks))
+		})
+	}
+}
+
+func TestReconcileDelete(t *testing.T) {
+	tests := []struct {
+		name               string
+		hookResponses      map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+		expectedResult     ctrl.Result
+		expectedOkToDelete bool
+	}{
+		{
+			name:               "should mark the Cluster as ok to delete if the hook is not blocking",
+			expectedOkToDelete: true,
+		},
+		{
+			name: "should requeue if the hook is blocking",
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.BeforeClusterDelete: blockingResponse(30),
+			},
+			expectedResult:     ctrl.Result{RequeueAfter: 30 * time.Second},
+			expectedOkToDelete: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			fakeClient := fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(cluster).Build()
+			s := scope.New(cluster)
+
+			r := &ClusterReconciler{
+				Client:        fakeClient,
+				RuntimeClient: &fakeRuntimeClient{responses: tt.hookResponses},
+			}
+			res, err := r.reconcileDelete(ctx, s)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res).To(Equal(tt.expectedResult))
+
+			gotCluster := &clusterv1.Cluster{}
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), gotCluster)).To(Succeed())
+			g.Expect(hooks.IsOkToDelete(gotCluster)).To(Equal(tt.expectedOkToDelete))
+		})
+	}
+}
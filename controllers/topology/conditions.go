@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+// reconcileConditions sets the TopologyReconciled condition and the topology status on the Cluster and patches it.
+func (r *ClusterReconciler) reconcileConditions(ctx context.Context, s *scope.Scope, cluster *clusterv1.Cluster, reconcileErr error) error {
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return errors.Wrap(err, "failed to create patch helper")
+	}
+
+	setTopologyReconciledCondition(s, cluster, reconcileErr)
+	setTopologyStatus(s, cluster)
+
+	return patchHelper.Patch(ctx, cluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+		clusterv1.TopologyReconciledCondition,
+	}})
+}
+
+// setTopologyReconciledCondition sets the TopologyReconciled condition on the Cluster, reporting
+// if the reconcile failed or if one of the lifecycle hooks is blocking.
+func setTopologyReconciledCondition(s *scope.Scope, cluster *clusterv1.Cluster, reconcileErr error) {
+	if reconcileErr != nil {
+		conditions.MarkFalse(cluster, clusterv1.TopologyReconciledCondition, clusterv1.TopologyReconcileFailedReason, clusterv1.ConditionSeverityError, reconcileErr.Error())
+		return
+	}
+
+	if s.HookResponseTracker.AggregateRetryAfter() > 0 {
+		conditions.MarkFalse(cluster, clusterv1.TopologyReconciledCondition, clusterv1.TopologyReconciledHookBlockingReason, clusterv1.ConditionSeverityInfo, s.HookResponseTracker.AggregateMessage())
+		return
+	}
+
+	conditions.MarkTrue(cluster, clusterv1.TopologyReconciledCondition)
+}
+
+// setTopologyStatus sets the topology status on the Cluster, reporting which MachineDeployments are waiting
+// to be upgraded, are upgrading or are upgraded to the version defined in the topology.
+// NOTE: The status is not changed if the desired state has not been computed, e.g. because the reconcile failed.
+func setTopologyStatus(s *scope.Scope, cluster *clusterv1.Cluster) {
+	if s.Blueprint == nil || s.Blueprint.Topology == nil || s.Desired == nil {
+		return
+	}
+
+	status := clusterv1.MachineDeploymentsUpgradeStatus{}
+	if s.Blueprint.Topology.Workers != nil {
+		for _, mdTopology := range s.Blueprint.Topology.Workers.MachineDeployments {
+			desiredMD := s.Desired.MachineDeployments[mdTopology.Name]
+			currentMD := s.Current.MachineDeployments[mdTopology.Name]
+			// NOTE: MachineDeployments which do not exist yet are reported only after they are created.
+			if desiredMD == nil || desiredMD.Object == nil || currentMD == nil || currentMD.Object == nil {
+				continue
+			}
+
+			desiredVersion := desiredMD.Object.Spec.Template.Spec.Version
+			currentVersion := currentMD.Object.Spec.Template.Spec.Version
+			switch {
+			case desiredVersion == nil || *desiredVersion != s.Blueprint.Topology.Version:
+				status.Pending = append(status.Pending, mdTopology.Name)
+			case currentVersion == nil || *currentVersion != *desiredVersion || currentMD.IsRollingOut():
+				status.Upgrading = append(status.Upgrading, mdTopology.Name)
+			default:
+				status.Upgraded = append(status.Upgraded, mdTopology.Name)
+			}
+		}
+	}
+
+	cluster.Status.Topology = &clusterv1.TopologyStatus{
+		MachineDeployments: status,
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestSetTopologyReconciledCondition(t *testing.T) {
+	tests := []struct {
+		name            string
+		reconcileErr    error
+		hookResponses   map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "should set the condition to true if the topology has been reconciled",
+			expectedStatus: corev1.ConditionTrue,
+		},
+		{
+			name:            "should set the condition to false if the reconcile failed",
+			reconcileErr:    errors.New("failed to create the ControlPlane"),
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  clusterv1.TopologyReconcileFailedReason,
+			expectedMessage: "failed to create the ControlPlane",
+		},
+		{
+			name: "should set the condition to false if a lifecycle hook is blocking",
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.BeforeClusterUpgrade: blockingResponse(10),
+			},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  clusterv1.TopologyReconciledHookBlockingReason,
+			expectedMessage: "hook \"BeforeClusterUpgrade\" is blocking: blocked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			s := scope.New(cluster)
+			for hook, response := range tt.hookResponses {
+				s.HookResponseTracker.Add(hook, response)
+			}
+
+			setTopologyReconciledCondition(s, cluster, tt.reconcileErr)
+
+			condition := conditions.Get(cluster, clusterv1.TopologyReconciledCondition)
+			g.Expect(condition).ToNot(BeNil())
+			g.Expect(condition.Status).To(Equal(tt.expectedStatus))
+			g.Expect(condition.Reason).To(Equal(tt.expectedReason))
+			g.Expect(condition.Message).To(Equal(tt.expectedMessage))
+		})
+	}
+}
+
+func TestSetTopologyStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	machineDeployment := func(version string, rollingOut bool) *clusterv1.MachineDeployment {
+		status := clusterv1.MachineDeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			ReadyReplicas:      2,
+		}
+		if rollingOut {
+			status.UpdatedReplicas = 1
+		}
+		return builder.MachineDeployment(metav1.NamespaceDefault, "md").
+			WithGeneration(1).
+			WithReplicas(2).
+			WithVersion(version).
+			WithStatus(status).
+			Build()
+	}
+
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+	s := scope.New(cluster)
+	s.Blueprint.Topology = &clusterv1.Topology{
+		Version: "v1.2.3",
+		Workers: &clusterv1.WorkersTopology{
+			MachineDeployments: []clusterv1.MachineDeploymentTopology{
+				{Name: "md-pending"},
+				{Name: "md-picked-up"},
+				{Name: "md-rolling-out"},
+				{Name: "md-upgraded"},
+				{Name: "md-new"},
+			},
+		},
+	}
+	s.Current.MachineDeployments = scope.MachineDeploymentsStateMap{
+		"md-pending":     {Object: machineDeployment("v1.2.2", false)},
+		"md-picked-up":   {Object: machineDeployment("v1.2.2", false)},
+		"md-rolling-out": {Object: machineDeployment("v1.2.3", true)},
+		"md-upgraded":    {Object: machineDeployment("v1.2.3", false)},
+	}
+	s.Desired = &scope.ClusterState{
+		MachineDeployments: scope.MachineDeploymentsStateMap{
+			"md-pending":     {Object: machineDeployment("v1.2.2", false)},
+			"md-picked-up":   {Object: machineDeployment("v1.2.3", false)},
+			"md-rolling-out": {Object: machineDeployment("v1.2.3", false)},
+			"md-upgraded":    {Object: machineDeployment("v1.2.3", false)},
+			"md-new":         {Object: machineDeployment("v1.2.3", false)},
+		},
+	}
+
+	setTopologyStatus(s, cluster)
+
+	g.Expect(cluster.Status.Topology).ToNot(BeNil())
+	g.Expect(cluster.Status.Topology.MachineDeployments.Pending).To(Equal([]string{"md-pending"}))
+	g.Expect(cluster.Status.Topology.MachineDeployments.Upgrading).To(Equal([]string{"md-picked-up", "md-rolling-out"}))
+	g.Expect(cluster.Status.Topology.MachineDeployments.Upgraded).To(Equal([]string{"md-upgraded"}))
+}
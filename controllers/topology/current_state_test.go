@@ -86,6 +86,28 @@ func TestGetCurrentState(t *testing.T) {
 		WithInfrastructureTemplate(machineDeploymentInfrastructure).
 		Build()
 
+	// MachineHealthCheck objects.
+	controlPlaneMachineHealthCheck := &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachineHealthCheck",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp1",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+	machineDeploymentMachineHealthCheck := &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachineHealthCheck",
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md1",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+
 	tests := []struct {
 		name    string
 		cluster *clusterv1.Cluster
@@ -366,6 +388,46 @@ func TestGetCurrentState(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Should read a full Cluster, including the MachineHealthChecks for ControlPlane and MachineDeployments",
+			cluster: builder.Cluster(metav1.NamespaceDefault, "cluster1").
+				WithInfrastructureCluster(infraCluster).
+				WithControlPlane(controlPlaneWithInfra).
+				Build(),
+			class: clusterClassWithControlPlaneInfra,
+			objects: []client.Object{
+				infraCluster,
+				clusterClassWithControlPlaneInfra,
+				controlPlaneInfrastructureMachineTemplate,
+				controlPlaneWithInfra,
+				controlPlaneMachineHealthCheck,
+				machineDeploymentInfrastructure,
+				machineDeploymentBootstrap,
+				machineDeployment,
+				machineDeploymentMachineHealthCheck,
+			},
+			// Expect valid return of full ClusterState with MachineHealthChecks read by name.
+			want: &scope.ClusterState{
+				Cluster: builder.Cluster(metav1.NamespaceDefault, "cluster1").
+					WithInfrastructureCluster(infraCluster).
+					WithControlPlane(controlPlaneWithInfra).
+					Build(),
+				ControlPlane: &scope.ControlPlaneState{
+					Object:                        controlPlaneWithInfra,
+					InfrastructureMachineTemplate: controlPlaneInfrastructureMachineTemplate,
+					MachineHealthCheck:            controlPlaneMachineHealthCheck,
+				},
+				InfrastructureCluster: infraCluster,
+				MachineDeployments: map[string]*scope.MachineDeploymentState{
+					"md1": {
+						Object:                        machineDeployment,
+						BootstrapTemplate:             machineDeploymentBootstrap,
+						InfrastructureMachineTemplate: machineDeploymentInfrastructure,
+						MachineHealthCheck:            machineDeploymentMachineHealthCheck,
+					},
+				},
+			},
+		},
 		{
 			name: "Fails if a Cluster has a MachineDeployment without the Bootstrap Template ref",
 			cluster: builder.Cluster(metav1.NamespaceDefault, "cluster1").
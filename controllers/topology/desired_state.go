@@ -19,6 +19,9 @@ package topology
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -29,8 +32,13 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/hooks"
 	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -58,26 +66,54 @@ func (r *ClusterReconciler) computeDesiredState(ctx context.Context, s *scope.Sc
 
 	// Compute the desired state of the ControlPlane object, eventually adding a reference to the
 	// InfrastructureMachineTemplate generated by the previous step.
-	if desiredState.ControlPlane.Object, err = computeControlPlane(ctx, s, desiredState.ControlPlane.InfrastructureMachineTemplate); err != nil {
+	if desiredState.ControlPlane.Object, err = r.computeControlPlane(ctx, s, desiredState.ControlPlane.InfrastructureMachineTemplate); err != nil {
 		return nil, err
 	}
 
+	// If the clusterClass defines a MachineHealthCheck for the control plane, compute its desired state.
+	if s.Blueprint.HasControlPlaneMachineHealthCheck() {
+		desiredState.ControlPlane.MachineHealthCheck = computeMachineHealthCheck(
+			desiredState.ControlPlane.Object,
+			selectorForControlPlaneMHC(),
+			s.Current.Cluster,
+			s.Blueprint.ControlPlane.MachineHealthCheck,
+		)
+	}
+
 	// Compute the desired state for the Cluster object adding a reference to the
 	// InfrastructureCluster and the ControlPlane objects generated by the previous step.
 	desiredState.Cluster = computeCluster(ctx, s, desiredState.InfrastructureCluster, desiredState.ControlPlane.Object)
 
 	// If required by the blueprint, compute the desired state of the MachineDeployment objects for the worker nodes, if any.
-	if !s.Blueprint.HasMachineDeployments() {
-		return desiredState, nil
+	if s.Blueprint.HasMachineDeployments() {
+		// Compute the desired state of the MachineDeployments from the list of MachineDeploymentTopologies
+		// defined in the cluster.
+		desiredState.MachineDeployments, err = computeMachineDeployments(ctx, s, desiredState.ControlPlane)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Compute the desired state of the MachineDeployments from the list of MachineDeploymentTopologies
-	// defined in the cluster.
-	desiredState.MachineDeployments, err = computeMachineDeployments(ctx, s, desiredState.ControlPlane)
-	if err != nil {
-		return nil, err
+	// If required by the blueprint and enabled by the MachinePool feature flag, compute the desired state
+	// of the MachinePool objects for the worker nodes, if any.
+	if feature.Gates.Enabled(feature.MachinePool) && s.Blueprint.HasMachinePools() {
+		// Compute the desired state of the MachinePools from the list of MachinePoolTopologies
+		// defined in the cluster.
+		desiredState.MachinePools, err = computeMachinePools(ctx, s, desiredState.ControlPlane)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply patches to the desired state according to the patches from the ClusterClass, variables from the Cluster
+	// and builtin variables.
+	if err := r.patchEngine.Apply(ctx, s.Blueprint, desiredState); err != nil {
+		return nil, errors.Wrap(err, "failed to apply patches")
 	}
 
+	// Ensure MachineDeployments waiting to be rebased to a new ClusterClass are not rolled out yet.
+	holdMachineDeploymentRebases(s, desiredState.MachineDeployments)
+
 	return desiredState, nil
 }
 
@@ -131,7 +167,7 @@ func computeControlPlaneInfrastructureMachineTemplate(_ context.Context, s *scop
 
 // computeControlPlane computes the desired state for the ControlPlane object starting from the
 // corresponding template defined in the blueprint.
-func computeControlPlane(_ context.Context, s *scope.Scope, infrastructureMachineTemplate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+func (r *ClusterReconciler) computeControlPlane(ctx context.Context, s *scope.Scope, infrastructureMachineTemplate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	template := s.Blueprint.ControlPlane.Template
 	templateClonedFromRef := s.Blueprint.ClusterClass.Spec.ControlPlane.Ref
 	cluster := s.Current.Cluster
@@ -148,6 +184,15 @@ func computeControlPlane(_ context.Context, s *scope.Scope, infrastructureMachin
 		return nil, errors.Wrapf(err, "failed to generate the ControlPlane object from the %s", template.GetKind())
 	}
 
+	// Track the ClusterClass the ControlPlane is computed from; in case of a ClusterClass rebase, MachineDeployments
+	// are rebased only after the ControlPlane.
+	annotations := controlPlane.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.ClusterTopologyClassAnnotation] = s.Blueprint.ClusterClass.Name
+	controlPlane.SetAnnotations(annotations)
+
 	// If the ClusterClass mandates the controlPlane has infrastructureMachines, add a reference to InfrastructureMachine
 	// template and metadata to be used for the control plane machines.
 	if s.Blueprint.HasControlPlaneInfrastructureMachine() {
@@ -171,6 +216,18 @@ func computeControlPlane(_ context.Context, s *scope.Scope, infrastructureMachin
 			}); err != nil {
 			return nil, errors.Wrap(err, "failed to spec.machineTemplate.metadata in the ControlPlane object")
 		}
+
+		// Set the node volume detach and node deletion timeouts to be applied to ControlPlane machines, if defined.
+		if s.Blueprint.Topology.ControlPlane.NodeVolumeDetachTimeout != nil {
+			if err := contract.ControlPlane().MachineTemplate().NodeVolumeDetachTimeout().Set(controlPlane, *s.Blueprint.Topology.ControlPlane.NodeVolumeDetachTimeout); err != nil {
+				return nil, errors.Wrap(err, "failed to set spec.machineTemplate.nodeVolumeDetachTimeout in the ControlPlane object")
+			}
+		}
+		if s.Blueprint.Topology.ControlPlane.NodeDeletionTimeout != nil {
+			if err := contract.ControlPlane().MachineTemplate().NodeDeletionTimeout().Set(controlPlane, *s.Blueprint.Topology.ControlPlane.NodeDeletionTimeout); err != nil {
+				return nil, errors.Wrap(err, "failed to set spec.machineTemplate.nodeDeletionTimeout in the ControlPlane object")
+			}
+		}
 	}
 
 	// If it is required to manage the number of replicas for the control plane, set the corresponding field.
@@ -183,7 +240,7 @@ func computeControlPlane(_ context.Context, s *scope.Scope, infrastructureMachin
 	}
 
 	// Sets the desired Kubernetes version for the control plane.
-	version, err := computeControlPlaneVersion(s)
+	version, err := r.computeControlPlaneVersion(ctx, s)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to compute version of control plane")
 	}
@@ -197,7 +254,9 @@ func computeControlPlane(_ context.Context, s *scope.Scope, infrastructureMachin
 // computeControlPlaneVersion calculates the version of the desired control plane.
 // The version is calculated using the state of the current machine deployments, the current control plane
 // and the version defined in the topology.
-func computeControlPlaneVersion(s *scope.Scope) (string, error) {
+// NOTE: This func also calls the BeforeClusterUpgrade and the AfterControlPlaneUpgrade lifecycle hooks, which
+// can block picking up the new version for the control plane and for the MachineDeployments respectively.
+func (r *ClusterReconciler) computeControlPlaneVersion(ctx context.Context, s *scope.Scope) (string, error) {
 	desiredVersion := s.Blueprint.Topology.Version
 	// If we are creating the control plane object (current control plane is nil), use version from topology.
 	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil {
@@ -211,8 +270,12 @@ func computeControlPlaneVersion(s *scope.Scope) (string, error) {
 	}
 
 	// Return early if the version is already equal to the desired version
-	// no further checks required.
+	// no further checks required, except calling the AfterControlPlaneUpgrade hook
+	// if an upgrade of the control plane to this version has been completed.
 	if *currentVersion == desiredVersion {
+		if err := r.callAfterControlPlaneUpgradeHook(ctx, s, *currentVersion); err != nil {
+			return "", err
+		}
 		return *currentVersion, nil
 	}
 
@@ -242,18 +305,80 @@ func computeControlPlaneVersion(s *scope.Scope) (string, error) {
 	}
 
 	// If the control plane is not upgrading or scaling, we can assume the control plane is stable.
-	// However, we should also check for the MachineDeployments to be stable.
-	// If the MachineDeployments are rolling out (still completing a previous upgrade), then do not pick
-	// up the desiredVersion yet. We will pick up the new version after the MachineDeployments are stable.
-	if s.Current.MachineDeployments.IsAnyRollingOut() {
+	// However, we should also check for the MachineDeployments and MachinePools to be stable.
+	// If they are rolling out (still completing a previous upgrade), then do not pick up the desiredVersion yet.
+	// We will pick up the new version after the MachineDeployments and MachinePools are stable.
+	if s.Current.MachineDeployments.IsAnyRollingOut() || s.Current.MachinePools.IsAnyRollingOut() {
 		return *currentVersion, nil
 	}
 
-	// Control plane and machine deployments are stable.
-	// Ready to pick up the topology version.
+	// Control plane, machine deployments and machine pools are stable.
+	// Ready to pick up the topology version, unless the BeforeClusterUpgrade hook is blocking.
+	hookResponse, err := r.RuntimeClient.CallAllExtensions(ctx, runtimehooksv1.BeforeClusterUpgrade, &runtimehooksv1.BeforeClusterUpgradeRequest{
+		Cluster:               *s.Current.Cluster,
+		FromKubernetesVersion: *currentVersion,
+		ToKubernetesVersion:   desiredVersion,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "error calling the %s hook", runtimehooksv1.BeforeClusterUpgrade)
+	}
+	s.HookResponseTracker.Add(runtimehooksv1.BeforeClusterUpgrade, hookResponse)
+	if hookResponse.RetryAfterSeconds > 0 {
+		tlog.LoggerFrom(ctx).Infof("Upgrade of the control plane to version %s is blocked by the %s hook", desiredVersion, runtimehooksv1.BeforeClusterUpgrade)
+		return *currentVersion, nil
+	}
+
+	// Track that the AfterControlPlaneUpgrade hook has to be called once the control plane is upgraded.
+	// NOTE: This is persisted before the control plane is updated, so the hook is not missed if the controller restarts.
+	if err := hooks.MarkAsPending(ctx, r.Client, s.Current.Cluster, runtimehooksv1.AfterControlPlaneUpgrade); err != nil {
+		return "", err
+	}
 	return desiredVersion, nil
 }
 
+// callAfterControlPlaneUpgradeHook calls the AfterControlPlaneUpgrade hook if it is pending and the control plane
+// completed the upgrade. MachineDeployments and MachinePools are not upgraded until the hook has been called and is not blocking.
+func (r *ClusterReconciler) callAfterControlPlaneUpgradeHook(ctx context.Context, s *scope.Scope, currentVersion string) error {
+	if !hooks.IsPending(runtimehooksv1.AfterControlPlaneUpgrade, s.Current.Cluster) {
+		return nil
+	}
+
+	// If the control plane is still upgrading or scaling, wait; MachineDeployments and MachinePools are not upgraded in the meantime.
+	cpUpgrading, err := contract.ControlPlane().IsUpgrading(s.Current.ControlPlane.Object)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if control plane is upgrading")
+	}
+	if cpUpgrading {
+		return nil
+	}
+	if s.Blueprint.Topology.ControlPlane.Replicas != nil {
+		cpScaling, err := contract.ControlPlane().IsScaling(s.Current.ControlPlane.Object)
+		if err != nil {
+			return errors.Wrap(err, "failed to check if the control plane is scaling")
+		}
+		if cpScaling {
+			return nil
+		}
+	}
+
+	hookResponse, err := r.RuntimeClient.CallAllExtensions(ctx, runtimehooksv1.AfterControlPlaneUpgrade, &runtimehooksv1.AfterControlPlaneUpgradeRequest{
+		Cluster:           *s.Current.Cluster,
+		KubernetesVersion: currentVersion,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error calling the %s hook", runtimehooksv1.AfterControlPlaneUpgrade)
+	}
+	s.HookResponseTracker.Add(runtimehooksv1.AfterControlPlaneUpgrade, hookResponse)
+	if hookResponse.RetryAfterSeconds > 0 {
+		tlog.LoggerFrom(ctx).Infof("Upgrade of the MachineDeployments and MachinePools to version %s is blocked by the %s hook", currentVersion, runtimehooksv1.AfterControlPlaneUpgrade)
+		s.UpgradeTracker.MachineDeployments.HoldUpgrades(true)
+		s.UpgradeTracker.MachinePools.HoldUpgrades(true)
+		return nil
+	}
+
+	return hooks.MarkAsDone(ctx, r.Client, s.Current.Cluster, runtimehooksv1.AfterControlPlaneUpgrade)
+}
+
 // computeCluster computes the desired state for the Cluster object.
 // NOTE: Some fields of the Cluster’s fields contribute to defining the Cluster blueprint (e.g. Cluster.Spec.Topology),
 // while some other fields should be managed as part of the actual Cluster (e.g. Cluster.Spec.ControlPlaneRef); in this func
@@ -280,17 +405,110 @@ func computeCluster(_ context.Context, s *scope.Scope, infrastructureCluster, co
 
 // computeMachineDeployments computes the desired state of the list of MachineDeployments.
 func computeMachineDeployments(ctx context.Context, s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState) (scope.MachineDeploymentsStateMap, error) {
+	// MachineDeployments already rolling out count towards the maximum number of concurrent upgrades.
+	s.UpgradeTracker.MachineDeployments.MarkRollingOut(s.Current.MachineDeployments.RollingOut()...)
+
+	// Compute the MachineDeployments in upgrade order, so MachineDeployments earlier in the order are the first
+	// to pick up a new version.
 	machineDeploymentsStateMap := make(scope.MachineDeploymentsStateMap)
-	for _, mdTopology := range s.Blueprint.Topology.Workers.MachineDeployments {
-		desiredMachineDeployment, err := computeMachineDeployment(ctx, s, desiredControlPlaneState, mdTopology)
-		if err != nil {
-			return nil, err
+	for _, group := range machineDeploymentUpgradeGroups(s.Blueprint.Topology) {
+		for _, mdTopology := range group {
+			desiredMachineDeployment, err := computeMachineDeployment(ctx, s, desiredControlPlaneState, mdTopology)
+			if err != nil {
+				return nil, err
+			}
+			machineDeploymentsStateMap[mdTopology.Name] = desiredMachineDeployment
+		}
+
+		// MachineDeployments in the next groups can be upgraded only after all the MachineDeployments
+		// in this group are upgraded.
+		if !isMachineDeploymentGroupUpgraded(s, group, machineDeploymentsStateMap) {
+			s.UpgradeTracker.MachineDeployments.HoldUpgrades(true)
 		}
-		machineDeploymentsStateMap[mdTopology.Name] = desiredMachineDeployment
 	}
 	return machineDeploymentsStateMap, nil
 }
 
+// machineDeploymentUpgradeGroups returns the MachineDeploymentTopologies grouped and sorted according to the
+// upgrade strategy of the topology. If no order label is defined, all the MachineDeploymentTopologies are part
+// of a single group, in the order they are defined in the topology.
+func machineDeploymentUpgradeGroups(topology *clusterv1.Topology) [][]clusterv1.MachineDeploymentTopology {
+	mdTopologies := topology.Workers.MachineDeployments
+
+	orderLabel := ""
+	if topology.UpgradeStrategy != nil && topology.UpgradeStrategy.MachineDeployments != nil {
+		orderLabel = topology.UpgradeStrategy.MachineDeployments.OrderByLabel
+	}
+	if orderLabel == "" {
+		return [][]clusterv1.MachineDeploymentTopology{mdTopologies}
+	}
+
+	sorted := make([]clusterv1.MachineDeploymentTopology, len(mdTopologies))
+	copy(sorted, mdTopologies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareUpgradeOrder(sorted[i].Metadata.Labels, sorted[j].Metadata.Labels, orderLabel) < 0
+	})
+
+	groups := [][]clusterv1.MachineDeploymentTopology{}
+	for i, mdTopology := range sorted {
+		if i == 0 || compareUpgradeOrder(sorted[i-1].Metadata.Labels, mdTopology.Metadata.Labels, orderLabel) != 0 {
+			groups = append(groups, []clusterv1.MachineDeploymentTopology{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], mdTopology)
+	}
+	return groups
+}
+
+// compareUpgradeOrder compares the values of the order label in two label sets; values are compared as integers
+// if both are integers, as strings otherwise. Label sets without the order label are sorted last.
+func compareUpgradeOrder(a, b map[string]string, orderLabel string) int {
+	aValue, aOk := a[orderLabel]
+	bValue, bOk := b[orderLabel]
+	switch {
+	case !aOk && !bOk:
+		return 0
+	case !aOk:
+		return 1
+	case !bOk:
+		return -1
+	}
+
+	aInt, aErr := strconv.ParseInt(aValue, 10, 64)
+	bInt, bErr := strconv.ParseInt(bValue, 10, 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case aInt < bInt:
+			return -1
+		case aInt > bInt:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(aValue, bValue)
+}
+
+// isMachineDeploymentGroupUpgraded returns true if all the MachineDeployments in a group run the version
+// defined in the topology and are not rolling out.
+// NOTE: MachineDeployments which do not exist yet are created with the version defined in the topology,
+// thus they are not considered.
+func isMachineDeploymentGroupUpgraded(s *scope.Scope, group []clusterv1.MachineDeploymentTopology, desiredMachineDeployments scope.MachineDeploymentsStateMap) bool {
+	for _, mdTopology := range group {
+		currentMD := s.Current.MachineDeployments[mdTopology.Name]
+		if currentMD == nil || currentMD.Object == nil {
+			continue
+		}
+		desiredVersion := desiredMachineDeployments[mdTopology.Name].Object.Spec.Template.Spec.Version
+		currentVersion := currentMD.Object.Spec.Template.Spec.Version
+		if currentVersion == nil || *currentVersion != s.Blueprint.Topology.Version || *desiredVersion != s.Blueprint.Topology.Version {
+			return false
+		}
+		if currentMD.IsRollingOut() {
+			return false
+		}
+	}
+	return true
+}
+
 // computeMachineDeployment computes the desired state for a MachineDeploymentTopology.
 // The generated machineDeployment object is calculated using the values from the machineDeploymentTopology and
 // the machineDeployment class.
@@ -346,6 +564,15 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 	// Add ClusterTopologyMachineDeploymentLabel to the generated InfrastructureMachine template
 	infraMachineTemplateLabels[clusterv1.ClusterTopologyMachineDeploymentLabelName] = machineDeploymentTopology.Name
 	desiredMachineDeployment.InfrastructureMachineTemplate.SetLabels(infraMachineTemplateLabels)
+
+	// Compute the ClusterClass the MachineDeployment should be computed from.
+	// NOTE: This is computed before the version, so the rebase to a new ClusterClass and the upgrade to a new version
+	// of the same MachineDeployment are not rolled out at the same time.
+	clusterClassName, err := computeMachineDeploymentClusterClass(s, desiredControlPlaneState, currentMachineDeployment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute ClusterClass for %s", machineDeploymentTopology.Name)
+	}
+
 	version, err := computeMachineDeploymentVersion(s, desiredControlPlaneState, currentMachineDeployment)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to compute version for %s", machineDeploymentTopology.Name)
@@ -361,6 +588,9 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.SimpleNameGenerator.GenerateName(fmt.Sprintf("%s-%s-", s.Current.Cluster.Name, machineDeploymentTopology.Name)),
 			Namespace: s.Current.Cluster.Namespace,
+			Annotations: map[string]string{
+				clusterv1.ClusterTopologyClassAnnotation: clusterClassName,
+			},
 		},
 		Spec: clusterv1.MachineDeploymentSpec{
 			ClusterName: s.Current.Cluster.Name,
@@ -370,10 +600,12 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 					Annotations: mergeMap(machineDeploymentTopology.Metadata.Annotations, machineDeploymentBlueprint.Metadata.Annotations),
 				},
 				Spec: clusterv1.MachineSpec{
-					ClusterName:       s.Current.Cluster.Name,
-					Version:           pointer.String(version),
-					Bootstrap:         clusterv1.Bootstrap{ConfigRef: contract.ObjToRef(desiredMachineDeployment.BootstrapTemplate)},
-					InfrastructureRef: *contract.ObjToRef(desiredMachineDeployment.InfrastructureMachineTemplate),
+					ClusterName:             s.Current.Cluster.Name,
+					Version:                 pointer.String(version),
+					Bootstrap:               clusterv1.Bootstrap{ConfigRef: contract.ObjToRef(desiredMachineDeployment.BootstrapTemplate)},
+					InfrastructureRef:       *contract.ObjToRef(desiredMachineDeployment.InfrastructureMachineTemplate),
+					NodeVolumeDetachTimeout: machineDeploymentTopology.NodeVolumeDetachTimeout,
+					NodeDeletionTimeout:     machineDeploymentTopology.NodeDeletionTimeout,
 				},
 			},
 		},
@@ -412,15 +644,100 @@ func computeMachineDeployment(_ context.Context, s *scope.Scope, desiredControlP
 	desiredMachineDeploymentObj.Spec.Replicas = machineDeploymentTopology.Replicas
 
 	desiredMachineDeployment.Object = desiredMachineDeploymentObj
+
+	// If the MachineDeployment class defines a MachineHealthCheck, compute its desired state.
+	if s.Blueprint.HasMachineDeploymentMachineHealthCheck(className) {
+		desiredMachineDeployment.MachineHealthCheck = computeMachineHealthCheck(
+			desiredMachineDeploymentObj,
+			selectorForMachineDeploymentMHC(machineDeploymentTopology.Name),
+			s.Current.Cluster,
+			machineDeploymentBlueprint.MachineHealthCheck,
+		)
+	}
+
 	return desiredMachineDeployment, nil
 }
 
+// computeMachineDeploymentClusterClass calculates the name of the ClusterClass the desired machine deployment
+// is computed from. In case of a ClusterClass rebase, machine deployments pick up the new ClusterClass
+// within the same concurrency limits used for upgrades, and only after the control plane has been rebased and is stable.
+// NOTE: Machine deployments without the ClusterClass annotation, e.g. created by a previous version of
+// the controller, are assumed to be already using the current ClusterClass.
+func computeMachineDeploymentClusterClass(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState, currentMDState *scope.MachineDeploymentState) (string, error) {
+	desiredClusterClass := s.Blueprint.ClusterClass.Name
+	// If creating a new machine deployment, we can pick up the desired ClusterClass.
+	if currentMDState == nil || currentMDState.Object == nil {
+		return desiredClusterClass, nil
+	}
+
+	// Return early if the machine deployment is already using the desired ClusterClass.
+	currentClusterClass, ok := currentMDState.Object.GetAnnotations()[clusterv1.ClusterTopologyClassAnnotation]
+	if !ok || currentClusterClass == desiredClusterClass {
+		return desiredClusterClass, nil
+	}
+
+	// Return early if we are not allowed to roll out the machine deployment.
+	if !s.UpgradeTracker.MachineDeployments.AllowUpgrade() {
+		return currentClusterClass, nil
+	}
+
+	// If the control plane has not been rebased yet, do not rebase the machine deployment.
+	// NOTE: The control plane is rebased in the same reconcile the rebase is detected, so machine deployments are
+	// going to be rebased after the control plane rollout triggered by the rebase completes.
+	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil ||
+		s.Current.ControlPlane.Object.GetAnnotations()[clusterv1.ClusterTopologyClassAnnotation] != desiredClusterClass {
+		return currentClusterClass, nil
+	}
+
+	// If the control plane is not stable (being created, upgrading, scaling or about to be upgraded),
+	// do not rebase the machine deployment.
+	cpStable, err := isControlPlaneStable(s, desiredControlPlaneState)
+	if err != nil {
+		return "", err
+	}
+	if !cpStable {
+		return currentClusterClass, nil
+	}
+
+	// If the MachineDeployment is rolling out, do not rebase it yet.
+	if currentMDState.IsRollingOut() {
+		return currentClusterClass, nil
+	}
+
+	// Control plane and machine deployment are stable.
+	// Ready to pick up the new ClusterClass.
+	s.UpgradeTracker.MachineDeployments.Insert(currentMDState.Object.Name)
+	return desiredClusterClass, nil
+}
+
+// holdMachineDeploymentRebases keeps the current templates and machine template metadata for the machine deployments
+// which are not yet allowed to pick up the ClusterClass the Cluster has been rebased to, thus preventing them to roll out.
+// NOTE: This must be called after patches are applied, because patches are applied to the templates
+// computed from the new ClusterClass.
+func holdMachineDeploymentRebases(s *scope.Scope, desiredMachineDeployments scope.MachineDeploymentsStateMap) {
+	for mdTopologyName, desiredMD := range desiredMachineDeployments {
+		if desiredMD.Object.GetAnnotations()[clusterv1.ClusterTopologyClassAnnotation] == s.Blueprint.ClusterClass.Name {
+			continue
+		}
+
+		currentMD := s.Current.MachineDeployments[mdTopologyName]
+		if currentMD == nil || currentMD.Object == nil || currentMD.BootstrapTemplate == nil || currentMD.InfrastructureMachineTemplate == nil {
+			continue
+		}
+
+		desiredMD.BootstrapTemplate = currentMD.BootstrapTemplate.DeepCopy()
+		desiredMD.InfrastructureMachineTemplate = currentMD.InfrastructureMachineTemplate.DeepCopy()
+		desiredMD.Object.Spec.Template.ObjectMeta = *currentMD.Object.Spec.Template.ObjectMeta.DeepCopy()
+		desiredMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef = currentMD.Object.Spec.Template.Spec.Bootstrap.ConfigRef.DeepCopy()
+		desiredMD.Object.Spec.Template.Spec.InfrastructureRef = *currentMD.Object.Spec.Template.Spec.InfrastructureRef.DeepCopy()
+	}
+}
+
 // computeMachineDeploymentVersion calculates the version of the desired machine deployment.
 // The version is calculated using the state of the current machine deployments,
 // the current control plane and the version defined in the topology.
-// Nb: No MachineDeployment upgrades will be triggered while any MachineDeployment is in the middle
-// of an upgrade. Even if the number of MachineDeployments that are being upgraded is less
-// than the number of allowed concurrent upgrades.
+// NOTE: MachineDeployments rolling out count towards the maximum number of concurrent upgrades, and the
+// MachineDeployment itself is not upgraded while it is rolling out.
 func computeMachineDeploymentVersion(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState, currentMDState *scope.MachineDeploymentState) (string, error) {
 	desiredVersion := s.Blueprint.Topology.Version
 	// If creating a new machine deployment, we can pick up the desired version
@@ -444,70 +761,338 @@ func computeMachineDeploymentVersion(s *scope.Scope, desiredControlPlaneState *s
 		return currentVersion, nil
 	}
 
-	// If the control plane is being created (current control plane is nil), do not perform
-	// any machine deployment upgrade in this case.
-	// Return the current version of the machine deployment.
-	// NOTE: this case should never happen (upgrading a MachineDeployment) before creating a CP,
+	// If the control plane is not stable (being created, upgrading, scaling or about to be upgraded),
+	// do not perform any machine deployment upgrade. Return the current version of the machine deployment.
+	// We will pick up the new version after the control plane is stable.
+	cpStable, err := isControlPlaneStable(s, desiredControlPlaneState)
+	if err != nil {
+		return "", err
+	}
+	if !cpStable {
+		return currentVersion, nil
+	}
+
+	// At this point the control plane is stable (not scaling, not upgrading, not being upgraded).
+	// If the MachineDeployment is rolling out, do not upgrade it yet.
+	// NOTE: Other MachineDeployments rolling out are taken into account by the UpgradeTracker, given that they
+	// count towards the maximum number of concurrent upgrades.
+	if currentMDState.IsRollingOut() {
+		return currentVersion, nil
+	}
+
+	// Control plane and machine deployment are stable.
+	// Ready to pick up the topology version.
+	s.UpgradeTracker.MachineDeployments.Insert(currentMDState.Object.Name)
+	return desiredVersion, nil
+}
+
+// isControlPlaneStable returns true if the control plane is stable, i.e. it is not being created, upgrading,
+// scaling or about to be upgraded. Workers (MachineDeployments and MachinePools) only pick up a new version
+// once the control plane is stable.
+func isControlPlaneStable(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState) (bool, error) {
+	// If the control plane is being created (current control plane is nil), it is not stable.
+	// NOTE: this case should never happen (upgrading workers) before creating a CP,
 	// but we are implementing this check for extra safety.
 	if s.Current.ControlPlane == nil || s.Current.ControlPlane.Object == nil {
-		return currentVersion, nil
+		return false, nil
 	}
 
-	// If the current control plane is upgrading, then do not pick up the desiredVersion yet.
-	// Return the current version of the machine deployment. We will pick up the new version after the control
-	// plane is stable.
+	// If the current control plane is upgrading, it is not stable.
 	cpUpgrading, err := contract.ControlPlane().IsUpgrading(s.Current.ControlPlane.Object)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to check if control plane is upgrading")
+		return false, errors.Wrap(err, "failed to check if control plane is upgrading")
 	}
 	if cpUpgrading {
-		return currentVersion, nil
+		return false, nil
 	}
 
 	// If control plane supports replicas, check if the control plane is in the middle of a scale operation.
-	// If the current control plane is scaling, then do not pick up the desiredVersion yet.
-	// Return the current version of the machine deployment. We will pick up the new version after the control
-	// plane is stable.
 	if s.Blueprint.Topology.ControlPlane.Replicas != nil {
 		cpScaling, err := contract.ControlPlane().IsScaling(s.Current.ControlPlane.Object)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to check if the control plane is scaling")
+			return false, errors.Wrap(err, "failed to check if the control plane is scaling")
 		}
 		if cpScaling {
-			return currentVersion, nil
+			return false, nil
 		}
 	}
 
-	// Check if we are about to upgrade the control plane. In that case, do not upgrade the machine deployment yet.
-	// Wait for the new upgrade operation on the control plane to finish before picking up the new version for the
-	// machine deployment.
+	// Check if we are about to upgrade the control plane. In that case, the control plane is not stable;
+	// wait for the new upgrade operation on the control plane to finish first.
 	currentCPVersion, err := contract.ControlPlane().Version().Get(s.Current.ControlPlane.Object)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get version of current control plane")
+		return false, errors.Wrap(err, "failed to get version of current control plane")
 	}
 	desiredCPVersion, err := contract.ControlPlane().Version().Get(desiredControlPlaneState.Object)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get version of desired control plane")
+		return false, errors.Wrap(err, "failed to get version of desired control plane")
 	}
 	if *currentCPVersion != *desiredCPVersion {
 		// The versions of the current and desired control planes do no match,
 		// implies we are about to upgrade the control plane.
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// computeMachinePools computes the desired state of the list of MachinePools.
+func computeMachinePools(ctx context.Context, s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState) (scope.MachinePoolsStateMap, error) {
+	machinePoolsStateMap := make(scope.MachinePoolsStateMap)
+	for _, mpTopology := range s.Blueprint.Topology.Workers.MachinePools {
+		desiredMachinePool, err := computeMachinePool(ctx, s, desiredControlPlaneState, mpTopology)
+		if err != nil {
+			return nil, err
+		}
+		machinePoolsStateMap[mpTopology.Name] = desiredMachinePool
+	}
+	return machinePoolsStateMap, nil
+}
+
+// computeMachinePool computes the desired state for a MachinePoolTopology.
+// The generated machinePool object is calculated using the values from the machinePoolTopology and
+// the machinePool class.
+// NOTE: Differently from MachineDeployments, MachinePools reference a bootstrap config and an infrastructure
+// machine pool object directly; those objects are generated from the templates defined in the ClusterClass.
+func computeMachinePool(_ context.Context, s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState, machinePoolTopology clusterv1.MachinePoolTopology) (*scope.MachinePoolState, error) {
+	desiredMachinePool := &scope.MachinePoolState{}
+
+	// Gets the blueprint for the MachinePool class.
+	className := machinePoolTopology.Class
+	machinePoolBlueprint, ok := s.Blueprint.MachinePools[className]
+	if !ok {
+		return nil, errors.Errorf("MachinePool class %s not found in %s", className, tlog.KObj{Obj: s.Blueprint.ClusterClass})
+	}
+
+	// Compute the bootstrap config.
+	currentMachinePool := s.Current.MachinePools[machinePoolTopology.Name]
+	var currentBootstrapConfigRef *corev1.ObjectReference
+	if currentMachinePool != nil && currentMachinePool.BootstrapObject != nil {
+		currentBootstrapConfigRef = currentMachinePool.Object.Spec.Template.Spec.Bootstrap.ConfigRef
+	}
+	var err error
+	desiredMachinePool.BootstrapObject, err = templateToObject(templateToInput{
+		template:              machinePoolBlueprint.BootstrapTemplate,
+		templateClonedFromRef: contract.ObjToRef(machinePoolBlueprint.BootstrapTemplate),
+		cluster:               s.Current.Cluster,
+		namePrefix:            bootstrapConfigNamePrefix(s.Current.Cluster.Name, machinePoolTopology.Name),
+		currentObjectRef:      currentBootstrapConfigRef,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute bootstrap object for topology %q", machinePoolTopology.Name)
+	}
+
+	bootstrapObjectLabels := desiredMachinePool.BootstrapObject.GetLabels()
+	if bootstrapObjectLabels == nil {
+		bootstrapObjectLabels = map[string]string{}
+	}
+	// Add ClusterTopologyMachinePoolLabel to the generated Bootstrap config
+	bootstrapObjectLabels[clusterv1.ClusterTopologyMachinePoolLabelName] = machinePoolTopology.Name
+	desiredMachinePool.BootstrapObject.SetLabels(bootstrapObjectLabels)
+
+	// Compute the Infrastructure machine pool.
+	var currentInfraMachinePoolRef *corev1.ObjectReference
+	if currentMachinePool != nil && currentMachinePool.InfrastructureMachinePoolObject != nil {
+		currentInfraMachinePoolRef = &currentMachinePool.Object.Spec.Template.Spec.InfrastructureRef
+	}
+	desiredMachinePool.InfrastructureMachinePoolObject, err = templateToObject(templateToInput{
+		template:              machinePoolBlueprint.InfrastructureMachinePoolTemplate,
+		templateClonedFromRef: contract.ObjToRef(machinePoolBlueprint.InfrastructureMachinePoolTemplate),
+		cluster:               s.Current.Cluster,
+		namePrefix:            infrastructureMachinePoolNamePrefix(s.Current.Cluster.Name, machinePoolTopology.Name),
+		currentObjectRef:      currentInfraMachinePoolRef,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute infrastructure object for topology %q", machinePoolTopology.Name)
+	}
+
+	infraMachinePoolObjectLabels := desiredMachinePool.InfrastructureMachinePoolObject.GetLabels()
+	if infraMachinePoolObjectLabels == nil {
+		infraMachinePoolObjectLabels = map[string]string{}
+	}
+	// Add ClusterTopologyMachinePoolLabel to the generated InfrastructureMachinePool object
+	infraMachinePoolObjectLabels[clusterv1.ClusterTopologyMachinePoolLabelName] = machinePoolTopology.Name
+	desiredMachinePool.InfrastructureMachinePoolObject.SetLabels(infraMachinePoolObjectLabels)
+	version, err := computeMachinePoolVersion(s, desiredControlPlaneState, currentMachinePool)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute version for %s", machinePoolTopology.Name)
+	}
+
+	// Compute the MachinePool object.
+	gv := expv1.GroupVersion
+	desiredMachinePoolObj := &expv1.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       gv.WithKind("MachinePool").Kind,
+			APIVersion: gv.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      names.SimpleNameGenerator.GenerateName(fmt.Sprintf("%s-%s-", s.Current.Cluster.Name, machinePoolTopology.Name)),
+			Namespace: s.Current.Cluster.Namespace,
+		},
+		Spec: expv1.MachinePoolSpec{
+			ClusterName: s.Current.Cluster.Name,
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      mergeMap(machinePoolTopology.Metadata.Labels, machinePoolBlueprint.Metadata.Labels),
+					Annotations: mergeMap(machinePoolTopology.Metadata.Annotations, machinePoolBlueprint.Metadata.Annotations),
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:       s.Current.Cluster.Name,
+					Version:           pointer.String(version),
+					Bootstrap:         clusterv1.Bootstrap{ConfigRef: contract.ObjToRef(desiredMachinePool.BootstrapObject)},
+					InfrastructureRef: *contract.ObjToRef(desiredMachinePool.InfrastructureMachinePoolObject),
+				},
+			},
+		},
+	}
+
+	// If an existing MachinePool is present, override the MachinePool generate name
+	// re-using the existing name (this will help in reconcile).
+	if currentMachinePool != nil && currentMachinePool.Object != nil {
+		desiredMachinePoolObj.SetName(currentMachinePool.Object.Name)
+	}
+
+	// Apply Labels
+	// NOTE: On top of all the labels applied to managed objects we are applying the ClusterTopologyMachinePoolLabel
+	// keeping track of the MachinePool name from the Topology; this will be used to identify the object in next reconcile loops.
+	labels := map[string]string{}
+	labels[clusterv1.ClusterLabelName] = s.Current.Cluster.Name
+	labels[clusterv1.ClusterTopologyOwnedLabel] = ""
+	labels[clusterv1.ClusterTopologyMachinePoolLabelName] = machinePoolTopology.Name
+	desiredMachinePoolObj.SetLabels(labels)
+
+	// Also set the labels in .spec.template.labels so that they are propagated to the machines of the pool.
+	desiredMachinePoolObj.Spec.Template.Labels[clusterv1.ClusterLabelName] = s.Current.Cluster.Name
+	desiredMachinePoolObj.Spec.Template.Labels[clusterv1.ClusterTopologyOwnedLabel] = ""
+	desiredMachinePoolObj.Spec.Template.Labels[clusterv1.ClusterTopologyMachinePoolLabelName] = machinePoolTopology.Name
+
+	// Set the desired replicas.
+	desiredMachinePoolObj.Spec.Replicas = machinePoolTopology.Replicas
+
+	desiredMachinePool.Object = desiredMachinePoolObj
+	return desiredMachinePool, nil
+}
+
+// computeMachinePoolVersion calculates the version of the desired machine pool.
+// The version is calculated using the state of the current machine pools,
+// the current control plane and the version defined in the topology.
+// Nb: No MachinePool upgrades will be triggered while any MachinePool is in the middle
+// of an upgrade. Even if the number of MachinePools that are being upgraded is less
+// than the number of allowed concurrent upgrades.
+func computeMachinePoolVersion(s *scope.Scope, desiredControlPlaneState *scope.ControlPlaneState, currentMPState *scope.MachinePoolState) (string, error) {
+	desiredVersion := s.Blueprint.Topology.Version
+	// If creating a new machine pool, we can pick up the desired version
+	// Note: We are not blocking the creation of new machine pools when
+	// the control plane or any of the machine pools are upgrading/scaling.
+	if currentMPState == nil || currentMPState.Object == nil {
+		return desiredVersion, nil
+	}
+
+	// Get the current version of the machine pool.
+	currentVersion := *currentMPState.Object.Spec.Template.Spec.Version
+
+	// Return early if we are not allowed to upgrade the machine pool.
+	if !s.UpgradeTracker.MachinePools.AllowUpgrade() {
+		return currentVersion, nil
+	}
+
+	// Return early if the currentVersion is already equal to the desiredVersion
+	// no further checks required.
+	if currentVersion == desiredVersion {
+		return currentVersion, nil
+	}
+
+	// If the control plane is not stable (being created, upgrading, scaling or about to be upgraded),
+	// do not perform any machine pool upgrade. Return the current version of the machine pool.
+	// We will pick up the new version after the control plane is stable.
+	cpStable, err := isControlPlaneStable(s, desiredControlPlaneState)
+	if err != nil {
+		return "", err
+	}
+	if !cpStable {
 		return currentVersion, nil
 	}
 
 	// At this point the control plane is stable (not scaling, not upgrading, not being upgraded).
-	// Checking to see if the machine deployments are also stable.
-	// If any of the MachineDeployments is rolling out, do not upgrade the machine deployment yet.
-	if s.Current.MachineDeployments.IsAnyRollingOut() {
+	// If any of the MachinePools is rolling out, do not upgrade the machine pool yet.
+	if s.Current.MachinePools.IsAnyRollingOut() {
 		return currentVersion, nil
 	}
 
-	// Control plane and machine deployments are stable.
+	// Control plane and machine pools are stable.
 	// Ready to pick up the topology version.
-	s.UpgradeTracker.MachineDeployments.Insert(currentMDState.Object.Name)
+	s.UpgradeTracker.MachinePools.Insert(currentMPState.Object.Name)
 	return desiredVersion, nil
 }
 
+// computeMachineHealthCheck computes the desired state for a MachineHealthCheck targeting the Machines
+// of the given object, using the values from the MachineHealthCheckClass defined in the ClusterClass.
+// NOTE: The MachineHealthCheck gets the same name of the target object, so it can be looked up in next reconcile loops.
+func computeMachineHealthCheck(healthCheckTarget client.Object, selector *metav1.LabelSelector, cluster *clusterv1.Cluster, check *clusterv1.MachineHealthCheckClass) *clusterv1.MachineHealthCheck {
+	check = check.DeepCopy()
+
+	mhc := &clusterv1.MachineHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterv1.GroupVersion.WithKind("MachineHealthCheck").Kind,
+			APIVersion: clusterv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      healthCheckTarget.GetName(),
+			Namespace: healthCheckTarget.GetNamespace(),
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:          cluster.Name,
+				clusterv1.ClusterTopologyOwnedLabel: "",
+			},
+			// NOTE: The MachineHealthCheck controller adds the same OwnerReference, so it is
+			// set using the same format to avoid fighting over it.
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				},
+			},
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName:         cluster.Name,
+			Selector:            *selector,
+			UnhealthyConditions: check.UnhealthyConditions,
+			MaxUnhealthy:        check.MaxUnhealthy,
+			UnhealthyRange:      check.UnhealthyRange,
+			NodeStartupTimeout:  check.NodeStartupTimeout,
+			RemediationTemplate: check.RemediationTemplate,
+		},
+	}
+
+	// Apply the same defaults applied by the MachineHealthCheck webhook, so the desired
+	// state does not differ from the current state only because of defaulting.
+	mhc.Default()
+
+	return mhc
+}
+
+// selectorForControlPlaneMHC returns the selector for the MachineHealthCheck of the control plane Machines.
+func selectorForControlPlaneMHC() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			clusterv1.ClusterTopologyOwnedLabel:    "",
+			clusterv1.MachineControlPlaneLabelName: "",
+		},
+	}
+}
+
+// selectorForMachineDeploymentMHC returns the selector for the MachineHealthCheck of the Machines
+// belonging to the MachineDeployment with the given topology name.
+func selectorForMachineDeploymentMHC(mdTopologyName string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			clusterv1.ClusterTopologyOwnedLabel:                 "",
+			clusterv1.ClusterTopologyMachineDeploymentLabelName: mdTopologyName,
+		},
+	}
+}
+
 type templateToInput struct {
 	template              *unstructured.Unstructured
 	templateClonedFromRef *corev1.ObjectReference
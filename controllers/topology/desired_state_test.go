@@ -17,18 +17,31 @@ limitations under the License.
 package topology
 
 import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/hooks"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/server"
 	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -48,6 +61,92 @@ var (
 	}
 )
 
+func TestComputeDesiredStateWithExternalPatches(t *testing.T) {
+	g := NewWithT(t)
+
+	// The extension sets the location on the InfrastructureClusterTemplate.
+	generatePatches := func(_ context.Context, req *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		resp := &runtimehooksv1.GeneratePatchesResponse{}
+		for _, item := range req.Items {
+			if item.TemplateRef.TemplateType != runtimehooksv1.InfrastructureClusterTemplateType {
+				continue
+			}
+			resp.Items = append(resp.Items, runtimehooksv1.GeneratePatchesResponseItem{
+				TemplateRef: item.TemplateRef,
+				Patch:       apiextensionsv1.JSON{Raw: []byte(`[{"op":"add","path":"/spec/template/spec/location","value":"us-west"}]`)},
+				PatchType:   runtimehooksv1.JSONPatchType,
+			})
+		}
+		return resp, nil
+	}
+	srv := httptest.NewTLSServer(server.NewGeneratePatchesHandler(generatePatches))
+	defer srv.Close()
+
+	extensionConfig := &runtimev1.ExtensionConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "location-extension"},
+		Spec: runtimev1.ExtensionConfigSpec{
+			ClientConfig: runtimev1.ClientConfig{
+				URL:      pointer.String(srv.URL),
+				CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+			},
+		},
+	}
+
+	// templates and ClusterClass
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "template1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.location": "us-east"}).
+		Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "template1").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		Build()
+	clusterClass.Spec.Patches = []clusterv1.ClusterClassPatch{
+		{
+			Name:     "location",
+			External: &clusterv1.ExternalPatchDefinition{Extension: extensionConfig.Name},
+		},
+	}
+
+	// current cluster objects
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Class:   clusterClass.Name,
+				Version: "v1.21.2",
+			},
+		},
+	}
+
+	// aggregating templates and cluster class into a blueprint (simulating getBlueprint)
+	s := scope.New(cluster)
+	s.Blueprint = &scope.ClusterBlueprint{
+		Topology:                      cluster.Spec.Topology,
+		ClusterClass:                  clusterClass,
+		InfrastructureClusterTemplate: infrastructureClusterTemplate,
+		ControlPlane: &scope.ControlPlaneBlueprint{
+			Template: controlPlaneTemplate,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(extensionConfig).Build()
+	r := &ClusterReconciler{
+		Client:        fakeClient,
+		RuntimeClient: &fakeRuntimeClient{},
+	}
+	r.SetupForDryRun()
+
+	desiredState, err := r.computeDesiredState(ctx, s)
+	g.Expect(err).ToNot(HaveOccurred())
+	assertNestedField(g, desiredState.InfrastructureCluster, "us-west", "spec", "location")
+}
+
 func TestComputeInfrastructureCluster(t *testing.T) {
 	// templates and ClusterClass
 	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "template1").
@@ -224,6 +323,8 @@ func TestComputeControlPlane(t *testing.T) {
 	// current cluster objects
 	version := "v1.21.2"
 	replicas := int32(3)
+	nodeVolumeDetachTimeout := metav1.Duration{Duration: 5 * time.Minute}
+	nodeDeletionTimeout := metav1.Duration{Duration: 30 * time.Second}
 	cluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "cluster1",
@@ -237,7 +338,9 @@ func TestComputeControlPlane(t *testing.T) {
 						Labels:      map[string]string{"l2": ""},
 						Annotations: map[string]string{"a2": ""},
 					},
-					Replicas: &replicas,
+					Replicas:                &replicas,
+					NodeVolumeDetachTimeout: &nodeVolumeDetachTimeout,
+					NodeDeletionTimeout:     &nodeDeletionTimeout,
 				},
 			},
 		},
@@ -258,7 +361,8 @@ func TestComputeControlPlane(t *testing.T) {
 		scope := scope.New(cluster)
 		scope.Blueprint = blueprint
 
-		obj, err := computeControlPlane(ctx, scope, nil)
+		r := &ClusterReconciler{}
+		obj, err := r.computeControlPlane(ctx, scope, nil)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(obj).ToNot(BeNil())
 
@@ -273,6 +377,9 @@ func TestComputeControlPlane(t *testing.T) {
 		assertNestedField(g, obj, version, contract.ControlPlane().Version().Path()...)
 		assertNestedField(g, obj, int64(replicas), contract.ControlPlane().Replicas().Path()...)
 		assertNestedFieldUnset(g, obj, contract.ControlPlane().MachineTemplate().InfrastructureRef().Path()...)
+		assertNestedFieldUnset(g, obj, contract.ControlPlane().MachineTemplate().NodeVolumeDetachTimeout().Path()...)
+		assertNestedFieldUnset(g, obj, contract.ControlPlane().MachineTemplate().NodeDeletionTimeout().Path()...)
+		g.Expect(obj.GetAnnotations()).To(HaveKeyWithValue(clusterv1.ClusterTopologyClassAnnotation, clusterClass.Name))
 	})
 	t.Run("Skips setting replicas if required", func(t *testing.T) {
 		g := NewWithT(t)
@@ -293,7 +400,8 @@ func TestComputeControlPlane(t *testing.T) {
 		scope := scope.New(clusterWithoutReplicas)
 		scope.Blueprint = blueprint
 
-		obj, err := computeControlPlane(ctx, scope, nil)
+		r := &ClusterReconciler{}
+		obj, err := r.computeControlPlane(ctx, scope, nil)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(obj).ToNot(BeNil())
 
@@ -333,7 +441,8 @@ func TestComputeControlPlane(t *testing.T) {
 		scope := scope.New(cluster)
 		scope.Blueprint = blueprint
 
-		obj, err := computeControlPlane(ctx, scope, infrastructureMachineTemplate)
+		r := &ClusterReconciler{}
+		obj, err := r.computeControlPlane(ctx, scope, infrastructureMachineTemplate)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(obj).ToNot(BeNil())
 
@@ -354,6 +463,8 @@ func TestComputeControlPlane(t *testing.T) {
 			Labels:      expectedLabels,
 			Annotations: mergeMap(scope.Current.Cluster.Spec.Topology.ControlPlane.Metadata.Annotations, blueprint.ClusterClass.Spec.ControlPlane.Metadata.Annotations),
 		}))
+		assertNestedField(g, obj, nodeVolumeDetachTimeout.Duration.String(), contract.ControlPlane().MachineTemplate().NodeVolumeDetachTimeout().Path()...)
+		assertNestedField(g, obj, nodeDeletionTimeout.Duration.String(), contract.ControlPlane().MachineTemplate().NodeDeletionTimeout().Path()...)
 
 		assertNestedField(g, obj, version, contract.ControlPlane().Version().Path()...)
 		assertNestedField(g, obj, int64(replicas), contract.ControlPlane().Replicas().Path()...)
@@ -383,7 +494,8 @@ func TestComputeControlPlane(t *testing.T) {
 		scope := scope.New(clusterWithControlPlaneRef)
 		scope.Blueprint = blueprint
 
-		obj, err := computeControlPlane(ctx, scope, nil)
+		r := &ClusterReconciler{}
+		obj, err := r.computeControlPlane(ctx, scope, nil)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(obj).ToNot(BeNil())
 
@@ -449,7 +561,8 @@ func TestComputeControlPlane(t *testing.T) {
 					Object: tt.currentControlPlane,
 				}
 
-				obj, err := computeControlPlane(ctx, s, nil)
+				r := &ClusterReconciler{}
+				obj, err := r.computeControlPlane(ctx, s, nil)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(obj).NotTo(BeNil())
 				assertNestedField(g, obj, tt.expectedVersion, contract.ControlPlane().Version().Path()...)
@@ -607,21 +720,159 @@ func TestComputeControlPlaneVersion(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			s := &scope.Scope{
-				Blueprint: &scope.ClusterBlueprint{Topology: &clusterv1.Topology{
-					Version: tt.topologyVersion,
-					ControlPlane: clusterv1.ControlPlaneTopology{
-						Replicas: pointer.Int32(2),
-					},
-				}},
-				Current: &scope.ClusterState{
-					ControlPlane:       &scope.ControlPlaneState{Object: tt.controlPlaneObj},
-					MachineDeployments: tt.machineDeploymentsState,
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			s := scope.New(cluster)
+			s.Blueprint = &scope.ClusterBlueprint{Topology: &clusterv1.Topology{
+				Version: tt.topologyVersion,
+				ControlPlane: clusterv1.ControlPlaneTopology{
+					Replicas: pointer.Int32(2),
+				},
+			}}
+			s.Current.ControlPlane = &scope.ControlPlaneState{Object: tt.controlPlaneObj}
+			s.Current.MachineDeployments = tt.machineDeploymentsState
+
+			r := &ClusterReconciler{
+				Client:        fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(cluster).Build(),
+				RuntimeClient: &fakeRuntimeClient{},
+			}
+			version, err := r.computeControlPlaneVersion(ctx, s)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(version).To(Equal(tt.expectedVersion))
+		})
+	}
+}
+
+func TestComputeControlPlaneVersionLifecycleHooks(t *testing.T) {
+	controlPlaneStable := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.2",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.2",
+			"status.replicas":        int64(2),
+			"status.updatedReplicas": int64(2),
+			"status.readyReplicas":   int64(2),
+		}).
+		Build()
+	controlPlaneUpgrading := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.3",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.2",
+			"status.replicas":        int64(2),
+			"status.updatedReplicas": int64(2),
+			"status.readyReplicas":   int64(2),
+		}).
+		Build()
+	controlPlaneUpgraded := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.3",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.3",
+			"status.replicas":        int64(2),
+			"status.updatedReplicas": int64(2),
+			"status.readyReplicas":   int64(2),
+		}).
+		Build()
+
+	tests := []struct {
+		name                    string
+		controlPlaneObj         *unstructured.Unstructured
+		afterCPUpgradePending   bool
+		hookResponses           map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+		expectedVersion         string
+		expectedCalledHooks     []runtimehooksv1.Hook
+		expectedPending         bool
+		expectedMDUpgradesAllow bool
+	}{
+		{
+			name:                    "should pick up the new version and mark AfterControlPlaneUpgrade as pending if BeforeClusterUpgrade is not blocking",
+			controlPlaneObj:         controlPlaneStable,
+			expectedVersion:         "v1.2.3",
+			expectedCalledHooks:     []runtimehooksv1.Hook{runtimehooksv1.BeforeClusterUpgrade},
+			expectedPending:         true,
+			expectedMDUpgradesAllow: true,
+		},
+		{
+			name:            "should keep the current version if BeforeClusterUpgrade is blocking",
+			controlPlaneObj: controlPlaneStable,
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.BeforeClusterUpgrade: blockingResponse(10),
+			},
+			expectedVersion:         "v1.2.2",
+			expectedCalledHooks:     []runtimehooksv1.Hook{runtimehooksv1.BeforeClusterUpgrade},
+			expectedPending:         false,
+			expectedMDUpgradesAllow: true,
+		},
+		{
+			name:                    "should not call AfterControlPlaneUpgrade while the control plane is upgrading",
+			controlPlaneObj:         controlPlaneUpgrading,
+			afterCPUpgradePending:   true,
+			expectedVersion:         "v1.2.3",
+			expectedPending:         true,
+			expectedMDUpgradesAllow: true,
+		},
+		{
+			name:                  "should hold MachineDeployment upgrades if AfterControlPlaneUpgrade is blocking",
+			controlPlaneObj:       controlPlaneUpgraded,
+			afterCPUpgradePending: true,
+			hookResponses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{
+				runtimehooksv1.AfterControlPlaneUpgrade: blockingResponse(10),
+			},
+			expectedVersion:         "v1.2.3",
+			expectedCalledHooks:     []runtimehooksv1.Hook{runtimehooksv1.AfterControlPlaneUpgrade},
+			expectedPending:         true,
+			expectedMDUpgradesAllow: false,
+		},
+		{
+			name:                    "should mark AfterControlPlaneUpgrade as done if it is not blocking",
+			controlPlaneObj:         controlPlaneUpgraded,
+			afterCPUpgradePending:   true,
+			expectedVersion:         "v1.2.3",
+			expectedCalledHooks:     []runtimehooksv1.Hook{runtimehooksv1.AfterControlPlaneUpgrade},
+			expectedPending:         false,
+			expectedMDUpgradesAllow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+			fakeClient := fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(cluster).Build()
+			if tt.afterCPUpgradePending {
+				g.Expect(hooks.MarkAsPending(ctx, fakeClient, cluster, runtimehooksv1.AfterControlPlaneUpgrade)).To(Succeed())
+			}
+
+			s := scope.New(cluster)
+			s.Blueprint = &scope.ClusterBlueprint{Topology: &clusterv1.Topology{
+				Version: "v1.2.3",
+				ControlPlane: clusterv1.ControlPlaneTopology{
+					Replicas: pointer.Int32(2),
 				},
+			}}
+			s.Current.ControlPlane = &scope.ControlPlaneState{Object: tt.controlPlaneObj}
+
+			runtimeClient := &fakeRuntimeClient{responses: tt.hookResponses}
+			r := &ClusterReconciler{
+				Client:        fakeClient,
+				RuntimeClient: runtimeClient,
 			}
-			version, err := computeControlPlaneVersion(s)
+			version, err := r.computeControlPlaneVersion(ctx, s)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(version).To(Equal(tt.expectedVersion))
+			g.Expect(runtimeClient.calledHooks).To(Equal(tt.expectedCalledHooks))
+			g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade()).To(Equal(tt.expectedMDUpgradesAllow))
+
+			// The pending state of the AfterControlPlaneUpgrade hook must be persisted.
+			gotCluster := &clusterv1.Cluster{}
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cluster), gotCluster)).To(Succeed())
+			g.Expect(hooks.IsPending(runtimehooksv1.AfterControlPlaneUpgrade, gotCluster)).To(Equal(tt.expectedPending))
 		})
 	}
 }
@@ -714,13 +965,17 @@ func TestComputeMachineDeployment(t *testing.T) {
 	}
 
 	replicas := int32(5)
+	nodeVolumeDetachTimeout := metav1.Duration{Duration: 5 * time.Minute}
+	nodeDeletionTimeout := metav1.Duration{Duration: 30 * time.Second}
 	mdTopology := clusterv1.MachineDeploymentTopology{
 		Metadata: clusterv1.ObjectMeta{
 			Labels: map[string]string{"foo": "baz"},
 		},
-		Class:    "linux-worker",
-		Name:     "big-pool-of-machines",
-		Replicas: &replicas,
+		Class:                   "linux-worker",
+		Name:                    "big-pool-of-machines",
+		Replicas:                &replicas,
+		NodeVolumeDetachTimeout: &nodeVolumeDetachTimeout,
+		NodeDeletionTimeout:     &nodeDeletionTimeout,
 	}
 
 	t.Run("Generates the machine deployment and the referenced templates", func(t *testing.T) {
@@ -749,6 +1004,8 @@ func TestComputeMachineDeployment(t *testing.T) {
 		g.Expect(actualMd.Spec.Template.ObjectMeta.Labels).To(HaveKey(clusterv1.ClusterTopologyOwnedLabel))
 		g.Expect(actualMd.Spec.Template.Spec.InfrastructureRef.Name).ToNot(Equal("linux-worker-inframachinetemplate"))
 		g.Expect(actualMd.Spec.Template.Spec.Bootstrap.ConfigRef.Name).ToNot(Equal("linux-worker-bootstraptemplate"))
+		g.Expect(*actualMd.Spec.Template.Spec.NodeVolumeDetachTimeout).To(Equal(nodeVolumeDetachTimeout))
+		g.Expect(*actualMd.Spec.Template.Spec.NodeDeletionTimeout).To(Equal(nodeDeletionTimeout))
 	})
 
 	t.Run("If there is already a machine deployment, it preserves the object name and the reference names", func(t *testing.T) {
@@ -1018,6 +1275,11 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		}).
 		Build()
 
+	machineDeploymentCurrent122 := machineDeploymentStable.DeepCopy()
+	machineDeploymentCurrent122.Spec.Template.Spec.Version = pointer.String("v1.2.2")
+	machineDeploymentRollingOut122 := machineDeploymentRollingOut.DeepCopy()
+	machineDeploymentRollingOut122.Spec.Template.Spec.Version = pointer.String("v1.2.2")
+
 	machineDeploymentsStateStable := scope.MachineDeploymentsStateMap{
 		"md1": &scope.MachineDeploymentState{Object: machineDeploymentStable},
 		"md2": &scope.MachineDeploymentState{Object: machineDeploymentStable},
@@ -1033,6 +1295,7 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		machineDeploymentsStateMap    scope.MachineDeploymentsStateMap
 		currentControlPlane           *unstructured.Unstructured
 		desiredControlPlane           *unstructured.Unstructured
+		maxConcurrency                int
 		topologyVersion               string
 		expectedVersion               string
 	}{
@@ -1045,7 +1308,7 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		},
 		{
 			name:                          "should return machine deployment's spec.template.spec.version if any one of the machine deployments is rolling out",
-			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
 			machineDeploymentsStateMap:    machineDeploymentsStateRollingOut,
 			currentControlPlane:           controlPlaneStable123,
 			desiredControlPlane:           controlPlaneDesired,
@@ -1055,7 +1318,7 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		{
 			// Control plane is considered upgrading if the control plane's spec.version and status.version is not equal.
 			name:                          "should return machine deployment's spec.template.spec.version if control plane is upgrading",
-			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
 			machineDeploymentsStateMap:    machineDeploymentsStateStable,
 			currentControlPlane:           controlPlaneUpgrading,
 			topologyVersion:               "v1.2.3",
@@ -1064,7 +1327,7 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		{
 			// Control plane is considered ready to upgrade if spec.version of current and desired control planes are not equal.
 			name:                          "should return machine deployment's spec.template.spec.version if control plane is ready to upgrade",
-			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
 			machineDeploymentsStateMap:    machineDeploymentsStateStable,
 			currentControlPlane:           controlPlaneStable122,
 			desiredControlPlane:           controlPlaneDesired,
@@ -1074,15 +1337,35 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 		{
 			// Control plane is considered scaling if its spec.replicas is not equal to any of status.replicas, status.readyReplicas or status.updatedReplicas.
 			name:                          "should return machine deployment's spec.template.spec.version if control plane is scaling",
-			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
 			machineDeploymentsStateMap:    machineDeploymentsStateStable,
 			currentControlPlane:           controlPlaneScaling,
 			topologyVersion:               "v1.2.3",
 			expectedVersion:               "v1.2.2",
 		},
+		{
+			name:                          "should return cluster.spec.topology.version if machine deployments are rolling out but the max upgrade concurrency is not reached",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
+			machineDeploymentsStateMap:    machineDeploymentsStateRollingOut,
+			currentControlPlane:           controlPlaneStable123,
+			desiredControlPlane:           controlPlaneDesired,
+			maxConcurrency:                2,
+			topologyVersion:               "v1.2.3",
+			expectedVersion:               "v1.2.3",
+		},
+		{
+			name:                          "should return machine deployment's spec.template.spec.version if the machine deployment is rolling out",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentRollingOut122},
+			machineDeploymentsStateMap:    machineDeploymentsStateRollingOut,
+			currentControlPlane:           controlPlaneStable123,
+			desiredControlPlane:           controlPlaneDesired,
+			maxConcurrency:                2,
+			topologyVersion:               "v1.2.3",
+			expectedVersion:               "v1.2.2",
+		},
 		{
 			name:                          "should return cluster.spec.topology.version if the control plane is not upgrading, not scaling, not ready to upgrade and none of the machine deployments are rolling out",
-			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: builder.MachineDeployment("test1", "md-current").WithVersion("v1.2.2").Build()},
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentCurrent122},
 			machineDeploymentsStateMap:    machineDeploymentsStateStable,
 			currentControlPlane:           controlPlaneStable123,
 			desiredControlPlane:           controlPlaneDesired,
@@ -1108,6 +1391,10 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 				},
 				UpgradeTracker: scope.NewUpgradeTracker(),
 			}
+			if tt.maxConcurrency > 0 {
+				s.UpgradeTracker = scope.NewUpgradeTracker(scope.MaxMachineDeploymentUpgradeConcurrency(tt.maxConcurrency))
+			}
+			s.UpgradeTracker.MachineDeployments.MarkRollingOut(tt.machineDeploymentsStateMap.RollingOut()...)
 			desiredControlPlaneState := &scope.ControlPlaneState{Object: tt.desiredControlPlane}
 			version, err := computeMachineDeploymentVersion(s, desiredControlPlaneState, tt.currentMachineDeploymentState)
 			g.Expect(err).NotTo(HaveOccurred())
@@ -1116,6 +1403,760 @@ func TestComputeMachineDeploymentVersion(t *testing.T) {
 	}
 }
 
+func TestMachineDeploymentUpgradeGroups(t *testing.T) {
+	mdTopology := func(name string, labels map[string]string) clusterv1.MachineDeploymentTopology {
+		return clusterv1.MachineDeploymentTopology{Name: name, Metadata: clusterv1.ObjectMeta{Labels: labels}}
+	}
+	mdTopologies := []clusterv1.MachineDeploymentTopology{
+		mdTopology("md1", map[string]string{"order": "10"}),
+		mdTopology("md2", nil),
+		mdTopology("md3", map[string]string{"order": "2"}),
+		mdTopology("md4", map[string]string{"order": "10"}),
+		mdTopology("md5", map[string]string{"order": "a"}),
+	}
+
+	tests := []struct {
+		name           string
+		upgradeStategy *clusterv1.TopologyUpgradeStrategy
+		expectedGroups [][]string
+	}{
+		{
+			name:           "returns a single group in topology order if there is no upgrade strategy",
+			upgradeStategy: nil,
+			expectedGroups: [][]string{{"md1", "md2", "md3", "md4", "md5"}},
+		},
+		{
+			name: "returns a single group in topology order if there is no order label",
+			upgradeStategy: &clusterv1.TopologyUpgradeStrategy{
+				MachineDeployments: &clusterv1.MachineDeploymentsUpgradeStrategy{MaxConcurrency: pointer.Int32(2)},
+			},
+			expectedGroups: [][]string{{"md1", "md2", "md3", "md4", "md5"}},
+		},
+		{
+			name: "returns groups sorted by the value of the order label",
+			upgradeStategy: &clusterv1.TopologyUpgradeStrategy{
+				MachineDeployments: &clusterv1.MachineDeploymentsUpgradeStrategy{OrderByLabel: "order"},
+			},
+			expectedGroups: [][]string{{"md3"}, {"md1", "md4"}, {"md5"}, {"md2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			topology := &clusterv1.Topology{
+				Workers:         &clusterv1.WorkersTopology{MachineDeployments: mdTopologies},
+				UpgradeStrategy: tt.upgradeStategy,
+			}
+
+			groups := [][]string{}
+			for _, group := range machineDeploymentUpgradeGroups(topology) {
+				names := []string{}
+				for _, mdTopology := range group {
+					names = append(names, mdTopology.Name)
+				}
+				groups = append(groups, names)
+			}
+			g.Expect(groups).To(Equal(tt.expectedGroups))
+		})
+	}
+}
+
+func TestIsMachineDeploymentGroupUpgraded(t *testing.T) {
+	machineDeployment := func(version string, rollingOut bool) *clusterv1.MachineDeployment {
+		status := clusterv1.MachineDeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			ReadyReplicas:      2,
+		}
+		if rollingOut {
+			status.UpdatedReplicas = 1
+		}
+		return builder.MachineDeployment("test1", "md").
+			WithGeneration(1).
+			WithReplicas(2).
+			WithVersion(version).
+			WithStatus(status).
+			Build()
+	}
+	group := []clusterv1.MachineDeploymentTopology{{Name: "md1"}, {Name: "md2"}}
+
+	tests := []struct {
+		name     string
+		current  scope.MachineDeploymentsStateMap
+		desired  scope.MachineDeploymentsStateMap
+		expected bool
+	}{
+		{
+			name: "returns true if all the MachineDeployments run the topology version",
+			current: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", false)},
+			},
+			desired: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", false)},
+			},
+			expected: true,
+		},
+		{
+			name: "returns true if a MachineDeployment does not exist yet",
+			current: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+			},
+			desired: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", false)},
+			},
+			expected: true,
+		},
+		{
+			name: "returns false if a MachineDeployment is waiting to be upgraded",
+			current: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.2", false)},
+			},
+			desired: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.2", false)},
+			},
+			expected: false,
+		},
+		{
+			name: "returns false if a MachineDeployment just picked up the topology version",
+			current: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.2", false)},
+			},
+			desired: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", false)},
+			},
+			expected: false,
+		},
+		{
+			name: "returns false if a MachineDeployment is rolling out",
+			current: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", true)},
+			},
+			desired: scope.MachineDeploymentsStateMap{
+				"md1": {Object: machineDeployment("v1.2.3", false)},
+				"md2": {Object: machineDeployment("v1.2.3", false)},
+			},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &scope.Scope{
+				Blueprint: &scope.ClusterBlueprint{Topology: &clusterv1.Topology{Version: "v1.2.3"}},
+				Current:   &scope.ClusterState{MachineDeployments: tt.current},
+			}
+			g.Expect(isMachineDeploymentGroupUpgraded(s, group, tt.desired)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestComputeMachineDeploymentClusterClass(t *testing.T) {
+	controlPlaneStable := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.3",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.3",
+			"status.replicas":        int64(2),
+			"status.updatedReplicas": int64(2),
+			"status.readyReplicas":   int64(2),
+		}).
+		Build()
+	controlPlaneScaling := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.3",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.3",
+			"status.replicas":        int64(3),
+			"status.updatedReplicas": int64(1),
+			"status.readyReplicas":   int64(3),
+		}).
+		Build()
+	controlPlaneDesired := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "v1.2.3",
+		}).
+		Build()
+
+	machineDeploymentStable := builder.MachineDeployment("test-namespace", "md-1").
+		WithGeneration(1).
+		WithReplicas(2).
+		WithStatus(clusterv1.MachineDeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			ReadyReplicas:      2,
+		}).
+		Build()
+	machineDeploymentRollingOut := builder.MachineDeployment("test-namespace", "md-2").
+		WithGeneration(1).
+		WithReplicas(2).
+		WithStatus(clusterv1.MachineDeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  1,
+			ReadyReplicas:      1,
+		}).
+		Build()
+
+	machineDeploymentsStateStable := scope.MachineDeploymentsStateMap{
+		"md1": &scope.MachineDeploymentState{Object: machineDeploymentStable},
+		"md2": &scope.MachineDeploymentState{Object: machineDeploymentStable},
+	}
+	machineDeploymentsStateRollingOut := scope.MachineDeploymentsStateMap{
+		"md1": &scope.MachineDeploymentState{Object: machineDeploymentStable},
+		"md2": &scope.MachineDeploymentState{Object: machineDeploymentRollingOut},
+	}
+
+	controlPlaneWithClusterClass := func(cp *unstructured.Unstructured, clusterClass string) *unstructured.Unstructured {
+		cp = cp.DeepCopy()
+		cp.SetAnnotations(map[string]string{clusterv1.ClusterTopologyClassAnnotation: clusterClass})
+		return cp
+	}
+	machineDeploymentWithClusterClass := func(clusterClass string) *clusterv1.MachineDeployment {
+		md := machineDeploymentStable.DeepCopy()
+		md.SetAnnotations(map[string]string{clusterv1.ClusterTopologyClassAnnotation: clusterClass})
+		return md
+	}
+
+	tests := []struct {
+		name                          string
+		currentMachineDeploymentState *scope.MachineDeploymentState
+		machineDeploymentsStateMap    scope.MachineDeploymentsStateMap
+		currentControlPlane           *unstructured.Unstructured
+		upgradingMachineDeployments   []string
+		expectedClusterClass          string
+	}{
+		{
+			name:                          "should return the ClusterClass of the Cluster if creating a new machine deployment",
+			currentMachineDeploymentState: nil,
+			machineDeploymentsStateMap:    make(scope.MachineDeploymentsStateMap),
+			expectedClusterClass:          "class2",
+		},
+		{
+			name:                          "should return the ClusterClass of the Cluster if the machine deployment has no ClusterClass annotation",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentStable},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneStable, "class1"),
+			expectedClusterClass:          "class2",
+		},
+		{
+			name:                          "should return the current ClusterClass if the control plane has not been rebased yet",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentWithClusterClass("class1")},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneStable, "class1"),
+			expectedClusterClass:          "class1",
+		},
+		{
+			name:                          "should return the current ClusterClass if the control plane is scaling",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentWithClusterClass("class1")},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneScaling, "class2"),
+			expectedClusterClass:          "class1",
+		},
+		{
+			name:                          "should return the current ClusterClass if any one of the machine deployments is rolling out",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentWithClusterClass("class1")},
+			machineDeploymentsStateMap:    machineDeploymentsStateRollingOut,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneStable, "class2"),
+			expectedClusterClass:          "class1",
+		},
+		{
+			name:                          "should return the current ClusterClass if another machine deployment is already picking up changes",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentWithClusterClass("class1")},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneStable, "class2"),
+			upgradingMachineDeployments:   []string{"md-other"},
+			expectedClusterClass:          "class1",
+		},
+		{
+			name:                          "should return the ClusterClass of the Cluster if the control plane has been rebased and is stable and none of the machine deployments are rolling out",
+			currentMachineDeploymentState: &scope.MachineDeploymentState{Object: machineDeploymentWithClusterClass("class1")},
+			machineDeploymentsStateMap:    machineDeploymentsStateStable,
+			currentControlPlane:           controlPlaneWithClusterClass(controlPlaneStable, "class2"),
+			expectedClusterClass:          "class2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &scope.Scope{
+				Blueprint: &scope.ClusterBlueprint{
+					ClusterClass: builder.ClusterClass("test1", "class2").Build(),
+					Topology: &clusterv1.Topology{
+						Class:   "class2",
+						Version: "v1.2.3",
+						ControlPlane: clusterv1.ControlPlaneTopology{
+							Replicas: pointer.Int32(2),
+						},
+					},
+				},
+				Current: &scope.ClusterState{
+					ControlPlane:       &scope.ControlPlaneState{Object: tt.currentControlPlane},
+					MachineDeployments: tt.machineDeploymentsStateMap,
+				},
+				UpgradeTracker: scope.NewUpgradeTracker(),
+			}
+			for _, name := range tt.upgradingMachineDeployments {
+				s.UpgradeTracker.MachineDeployments.Insert(name)
+			}
+			s.UpgradeTracker.MachineDeployments.MarkRollingOut(tt.machineDeploymentsStateMap.RollingOut()...)
+			desiredControlPlaneState := &scope.ControlPlaneState{Object: controlPlaneDesired}
+			clusterClass, err := computeMachineDeploymentClusterClass(s, desiredControlPlaneState, tt.currentMachineDeploymentState)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(clusterClass).To(Equal(tt.expectedClusterClass))
+
+			// A machine deployment picking up the new ClusterClass must be tracked, so the rebase
+			// is rolled out one machine deployment at a time.
+			if tt.currentMachineDeploymentState != nil && tt.expectedClusterClass != tt.currentMachineDeploymentState.Object.Annotations[clusterv1.ClusterTopologyClassAnnotation] &&
+				tt.currentMachineDeploymentState.Object.Annotations[clusterv1.ClusterTopologyClassAnnotation] != "" {
+				g.Expect(s.UpgradeTracker.MachineDeployments.AllowUpgrade()).To(BeFalse())
+			}
+		})
+	}
+}
+
+func TestHoldMachineDeploymentRebases(t *testing.T) {
+	g := NewWithT(t)
+
+	currentBootstrapTemplate := builder.BootstrapTemplate("test1", "bootstrap-current").Build()
+	currentInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate("test1", "infra-current").Build()
+	currentMD := builder.MachineDeployment("test1", "md1").
+		WithBootstrapTemplate(currentBootstrapTemplate).
+		WithInfrastructureTemplate(currentInfrastructureMachineTemplate).
+		WithLabels(map[string]string{"foo": "bar"}).
+		Build()
+	currentMD.Spec.Template.Labels = map[string]string{"foo": "bar"}
+
+	newDesiredMD := func(clusterClass string) *scope.MachineDeploymentState {
+		desiredBootstrapTemplate := builder.BootstrapTemplate("test1", "bootstrap-desired").Build()
+		desiredInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate("test1", "infra-desired").Build()
+		desiredMD := builder.MachineDeployment("test1", "md1").
+			WithBootstrapTemplate(desiredBootstrapTemplate).
+			WithInfrastructureTemplate(desiredInfrastructureMachineTemplate).
+			WithReplicas(3).
+			Build()
+		desiredMD.Spec.Template.Labels = map[string]string{"foo": "baz"}
+		desiredMD.SetAnnotations(map[string]string{clusterv1.ClusterTopologyClassAnnotation: clusterClass})
+		return &scope.MachineDeploymentState{
+			Object:                        desiredMD,
+			BootstrapTemplate:             desiredBootstrapTemplate,
+			InfrastructureMachineTemplate: desiredInfrastructureMachineTemplate,
+		}
+	}
+
+	s := &scope.Scope{
+		Blueprint: &scope.ClusterBlueprint{
+			ClusterClass: builder.ClusterClass("test1", "class2").Build(),
+		},
+		Current: &scope.ClusterState{
+			MachineDeployments: scope.MachineDeploymentsStateMap{
+				"md-rebased": &scope.MachineDeploymentState{
+					Object:                        currentMD,
+					BootstrapTemplate:             currentBootstrapTemplate,
+					InfrastructureMachineTemplate: currentInfrastructureMachineTemplate,
+				},
+				"md-pending": &scope.MachineDeploymentState{
+					Object:                        currentMD,
+					BootstrapTemplate:             currentBootstrapTemplate,
+					InfrastructureMachineTemplate: currentInfrastructureMachineTemplate,
+				},
+			},
+		},
+	}
+	desiredMachineDeployments := scope.MachineDeploymentsStateMap{
+		"md-rebased": newDesiredMD("class2"),
+		"md-pending": newDesiredMD("class1"),
+	}
+
+	holdMachineDeploymentRebases(s, desiredMachineDeployments)
+
+	// The MachineDeployment which picked up the new ClusterClass uses the desired templates.
+	rebased := desiredMachineDeployments["md-rebased"]
+	g.Expect(rebased.BootstrapTemplate.GetName()).To(Equal("bootstrap-desired"))
+	g.Expect(rebased.InfrastructureMachineTemplate.GetName()).To(Equal("infra-desired"))
+	g.Expect(rebased.Object.Spec.Template.Spec.Bootstrap.ConfigRef.Name).To(Equal("bootstrap-desired"))
+	g.Expect(rebased.Object.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("infra-desired"))
+	g.Expect(rebased.Object.Spec.Template.Labels).To(HaveKeyWithValue("foo", "baz"))
+
+	// The MachineDeployment waiting for the rebase keeps the current templates, but other changes are applied.
+	pending := desiredMachineDeployments["md-pending"]
+	g.Expect(pending.BootstrapTemplate.GetName()).To(Equal("bootstrap-current"))
+	g.Expect(pending.InfrastructureMachineTemplate.GetName()).To(Equal("infra-current"))
+	g.Expect(pending.Object.Spec.Template.Spec.Bootstrap.ConfigRef.Name).To(Equal("bootstrap-current"))
+	g.Expect(pending.Object.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("infra-current"))
+	g.Expect(pending.Object.Spec.Template.Labels).To(HaveKeyWithValue("foo", "bar"))
+	g.Expect(*pending.Object.Spec.Replicas).To(Equal(int32(3)))
+}
+
+func TestComputeMachinePool(t *testing.T) {
+	workerInfrastructureMachinePoolTemplate := builder.InfrastructureMachinePoolTemplate(metav1.NamespaceDefault, "linux-worker-inframachinepooltemplate").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	workerBootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "linux-worker-bootstraptemplate").
+		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
+		Build()
+	labels := map[string]string{"fizz": "buzz", "foo": "bar"}
+	annotations := map[string]string{"annotation-1": "annotation-1-val"}
+
+	mp1 := builder.MachinePoolClass(metav1.NamespaceDefault, "class1").
+		WithClass("linux-worker").
+		WithLabels(labels).
+		WithAnnotations(annotations).
+		WithInfrastructureTemplate(workerInfrastructureMachinePoolTemplate).
+		WithBootstrapTemplate(workerBootstrapTemplate).
+		Build()
+	fakeClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithWorkerMachinePoolClasses([]clusterv1.MachinePoolClass{*mp1}).
+		Build()
+
+	version := "v1.21.2"
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Version: version,
+			},
+		},
+	}
+
+	blueprint := &scope.ClusterBlueprint{
+		Topology:     cluster.Spec.Topology,
+		ClusterClass: fakeClass,
+		MachinePools: map[string]*scope.MachinePoolBlueprint{
+			"linux-worker": {
+				Metadata: clusterv1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+				BootstrapTemplate:                 workerBootstrapTemplate,
+				InfrastructureMachinePoolTemplate: workerInfrastructureMachinePoolTemplate,
+			},
+		},
+	}
+
+	replicas := int32(5)
+	mpTopology := clusterv1.MachinePoolTopology{
+		Metadata: clusterv1.ObjectMeta{
+			Labels: map[string]string{"foo": "baz"},
+		},
+		Class:    "linux-worker",
+		Name:     "big-pool-of-machines",
+		Replicas: &replicas,
+	}
+
+	t.Run("Generates the machine pool and the referenced objects", func(t *testing.T) {
+		g := NewWithT(t)
+		scope := scope.New(cluster)
+		scope.Blueprint = blueprint
+
+		actual, err := computeMachinePool(ctx, scope, nil, mpTopology)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(actual.BootstrapObject.GetKind()).To(Equal(builder.GenericBootstrapConfigKind))
+		g.Expect(actual.BootstrapObject.GetLabels()).To(HaveKeyWithValue(clusterv1.ClusterTopologyMachinePoolLabelName, "big-pool-of-machines"))
+		g.Expect(actual.BootstrapObject.GetAnnotations()).To(HaveKeyWithValue(clusterv1.TemplateClonedFromNameAnnotation, "linux-worker-bootstraptemplate"))
+		g.Expect(actual.InfrastructureMachinePoolObject.GetKind()).To(Equal(builder.GenericInfrastructureMachinePoolKind))
+		g.Expect(actual.InfrastructureMachinePoolObject.GetLabels()).To(HaveKeyWithValue(clusterv1.ClusterTopologyMachinePoolLabelName, "big-pool-of-machines"))
+		g.Expect(actual.InfrastructureMachinePoolObject.Object["spec"]).To(HaveKeyWithValue("fakeSetting", true))
+
+		actualMp := actual.Object
+		g.Expect(*actualMp.Spec.Replicas).To(Equal(replicas))
+		g.Expect(actualMp.Spec.ClusterName).To(Equal("cluster1"))
+		g.Expect(actualMp.Name).To(ContainSubstring("cluster1"))
+		g.Expect(actualMp.Name).To(ContainSubstring("big-pool-of-machines"))
+		g.Expect(*actualMp.Spec.Template.Spec.Version).To(Equal(version))
+
+		g.Expect(actualMp.Labels).To(HaveKeyWithValue(clusterv1.ClusterTopologyMachinePoolLabelName, "big-pool-of-machines"))
+		g.Expect(actualMp.Labels).To(HaveKey(clusterv1.ClusterTopologyOwnedLabel))
+
+		g.Expect(actualMp.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("foo", "baz"))
+		g.Expect(actualMp.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("fizz", "buzz"))
+		g.Expect(actualMp.Spec.Template.ObjectMeta.Labels).To(HaveKey(clusterv1.ClusterTopologyOwnedLabel))
+		g.Expect(actualMp.Spec.Template.Spec.InfrastructureRef.Name).To(Equal(actual.InfrastructureMachinePoolObject.GetName()))
+		g.Expect(actualMp.Spec.Template.Spec.Bootstrap.ConfigRef.Name).To(Equal(actual.BootstrapObject.GetName()))
+	})
+
+	t.Run("If there is already a machine pool, it preserves the object name and the reference names", func(t *testing.T) {
+		g := NewWithT(t)
+		s := scope.New(cluster)
+		s.Blueprint = blueprint
+
+		currentBootstrapConfig := builder.BootstrapConfig(metav1.NamespaceDefault, "existing-bootstrap-config").Build()
+		currentInfrastructureMachinePool := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "existing-infrastructure-machinepool").Build()
+		currentMp := builder.MachinePool(metav1.NamespaceDefault, "existing-pool-1").
+			WithVersion(version).
+			WithReplicas(3).
+			WithBootstrap(currentBootstrapConfig).
+			WithInfrastructure(currentInfrastructureMachinePool).
+			Build()
+		s.Current.MachinePools = map[string]*scope.MachinePoolState{
+			"big-pool-of-machines": {
+				Object:                          currentMp,
+				BootstrapObject:                 currentBootstrapConfig,
+				InfrastructureMachinePoolObject: currentInfrastructureMachinePool,
+			},
+		}
+
+		actual, err := computeMachinePool(ctx, s, nil, mpTopology)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		actualMp := actual.Object
+		g.Expect(*actualMp.Spec.Replicas).To(Equal(replicas))
+		g.Expect(actualMp.Name).To(Equal("existing-pool-1"))
+		g.Expect(actualMp.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("existing-infrastructure-machinepool"))
+		g.Expect(actualMp.Spec.Template.Spec.Bootstrap.ConfigRef.Name).To(Equal("existing-bootstrap-config"))
+	})
+
+	t.Run("If a machine pool references a topology class that does not exist, machine pool generation fails", func(t *testing.T) {
+		g := NewWithT(t)
+		scope := scope.New(cluster)
+		scope.Blueprint = blueprint
+
+		mpTopology := clusterv1.MachinePoolTopology{
+			Class: "windows-worker",
+			Name:  "big-pool-of-machines",
+		}
+
+		_, err := computeMachinePool(ctx, scope, nil, mpTopology)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestComputeMachinePoolVersion(t *testing.T) {
+	controlPlaneStable123 := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":  "v1.2.3",
+			"spec.replicas": int64(2),
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version":         "v1.2.3",
+			"status.replicas":        int64(2),
+			"status.updatedReplicas": int64(2),
+			"status.readyReplicas":   int64(2),
+		}).
+		Build()
+	controlPlaneUpgrading := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "v1.2.3",
+		}).
+		WithStatusFields(map[string]interface{}{
+			"status.version": "v1.2.1",
+		}).
+		Build()
+	controlPlaneDesired := builder.ControlPlane("test1", "cp1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "v1.2.3",
+		}).
+		Build()
+
+	// A machine pool is considered stable if all the following are true:
+	// - mp.status.observedGeneration >= mp.Generation
+	// - mp.spec.replicas == mp.status.readyReplicas
+	// - mp.status.unavailableReplicas == 0
+	machinePoolStable := builder.MachinePool("test-namespace", "mp-1").
+		WithGeneration(1).
+		WithReplicas(2).
+		WithStatus(expv1.MachinePoolStatus{
+			ObservedGeneration: 1,
+			Replicas:           2,
+			ReadyReplicas:      2,
+			AvailableReplicas:  2,
+		}).
+		Build()
+	machinePoolRollingOut := builder.MachinePool("test-namespace", "mp-2").
+		WithGeneration(1).
+		WithReplicas(2).
+		WithStatus(expv1.MachinePoolStatus{
+			ObservedGeneration:  1,
+			Replicas:            2,
+			ReadyReplicas:       1,
+			AvailableReplicas:   1,
+			UnavailableReplicas: 1,
+		}).
+		Build()
+
+	machinePoolsStateStable := scope.MachinePoolsStateMap{
+		"mp1": &scope.MachinePoolState{Object: machinePoolStable},
+	}
+	machinePoolsStateRollingOut := scope.MachinePoolsStateMap{
+		"mp1": &scope.MachinePoolState{Object: machinePoolStable},
+		"mp2": &scope.MachinePoolState{Object: machinePoolRollingOut},
+	}
+
+	tests := []struct {
+		name                    string
+		currentMachinePoolState *scope.MachinePoolState
+		machinePoolsStateMap    scope.MachinePoolsStateMap
+		currentControlPlane     *unstructured.Unstructured
+		desiredControlPlane     *unstructured.Unstructured
+		holdUpgrades            bool
+		topologyVersion         string
+		expectedVersion         string
+	}{
+		{
+			name:                    "should return cluster.spec.topology.version if creating a new machine pool",
+			currentMachinePoolState: nil,
+			machinePoolsStateMap:    make(scope.MachinePoolsStateMap),
+			topologyVersion:         "v1.2.3",
+			expectedVersion:         "v1.2.3",
+		},
+		{
+			name:                    "should return machine pool's spec.template.spec.version if any one of the machine pools is rolling out",
+			currentMachinePoolState: &scope.MachinePoolState{Object: builder.MachinePool("test1", "mp-current").WithVersion("v1.2.2").Build()},
+			machinePoolsStateMap:    machinePoolsStateRollingOut,
+			currentControlPlane:     controlPlaneStable123,
+			desiredControlPlane:     controlPlaneDesired,
+			topologyVersion:         "v1.2.3",
+			expectedVersion:         "v1.2.2",
+		},
+		{
+			name:                    "should return machine pool's spec.template.spec.version if control plane is upgrading",
+			currentMachinePoolState: &scope.MachinePoolState{Object: builder.MachinePool("test1", "mp-current").WithVersion("v1.2.2").Build()},
+			machinePoolsStateMap:    machinePoolsStateStable,
+			currentControlPlane:     controlPlaneUpgrading,
+			topologyVersion:         "v1.2.3",
+			expectedVersion:         "v1.2.2",
+		},
+		{
+			name:                    "should return machine pool's spec.template.spec.version if upgrades are held",
+			currentMachinePoolState: &scope.MachinePoolState{Object: builder.MachinePool("test1", "mp-current").WithVersion("v1.2.2").Build()},
+			machinePoolsStateMap:    machinePoolsStateStable,
+			currentControlPlane:     controlPlaneStable123,
+			desiredControlPlane:     controlPlaneDesired,
+			holdUpgrades:            true,
+			topologyVersion:         "v1.2.3",
+			expectedVersion:         "v1.2.2",
+		},
+		{
+			name:                    "should return cluster.spec.topology.version if the control plane is stable and none of the machine pools are rolling out",
+			currentMachinePoolState: &scope.MachinePoolState{Object: builder.MachinePool("test1", "mp-current").WithVersion("v1.2.2").Build()},
+			machinePoolsStateMap:    machinePoolsStateStable,
+			currentControlPlane:     controlPlaneStable123,
+			desiredControlPlane:     controlPlaneDesired,
+			topologyVersion:         "v1.2.3",
+			expectedVersion:         "v1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &scope.Scope{
+				Blueprint: &scope.ClusterBlueprint{Topology: &clusterv1.Topology{
+					Version: tt.topologyVersion,
+					ControlPlane: clusterv1.ControlPlaneTopology{
+						Replicas: pointer.Int32(2),
+					},
+				}},
+				Current: &scope.ClusterState{
+					ControlPlane: &scope.ControlPlaneState{Object: tt.currentControlPlane},
+					MachinePools: tt.machinePoolsStateMap,
+				},
+				UpgradeTracker: scope.NewUpgradeTracker(),
+			}
+			s.UpgradeTracker.MachinePools.HoldUpgrades(tt.holdUpgrades)
+			desiredControlPlaneState := &scope.ControlPlaneState{Object: tt.desiredControlPlane}
+			version, err := computeMachinePoolVersion(s, desiredControlPlaneState, tt.currentMachinePoolState)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(version).To(Equal(tt.expectedVersion))
+		})
+	}
+}
+
+func TestComputeMachineHealthCheck(t *testing.T) {
+	maxUnhealthy := intstr.FromString("50%")
+	check := &clusterv1.MachineHealthCheckClass{
+		UnhealthyConditions: []clusterv1.UnhealthyCondition{
+			{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+		MaxUnhealthy: &maxUnhealthy,
+		RemediationTemplate: &corev1.ObjectReference{
+			APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+			Kind:       "GenericRemediationTemplate",
+			Name:       "remediation-template",
+		},
+	}
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+	cluster.UID = "cluster1-uid"
+	controlPlane := builder.ControlPlane(metav1.NamespaceDefault, "cluster1-cp").Build()
+
+	t.Run("Generates the MachineHealthCheck for the control plane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got := computeMachineHealthCheck(controlPlane, selectorForControlPlaneMHC(), cluster, check)
+
+		g.Expect(got.Name).To(Equal("cluster1-cp"))
+		g.Expect(got.Namespace).To(Equal(metav1.NamespaceDefault))
+		g.Expect(got.Labels).To(Equal(map[string]string{
+			clusterv1.ClusterLabelName:          "cluster1",
+			clusterv1.ClusterTopologyOwnedLabel: "",
+		}))
+		g.Expect(got.OwnerReferences).To(Equal([]metav1.OwnerReference{
+			{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       "cluster1",
+				UID:        "cluster1-uid",
+			},
+		}))
+		g.Expect(got.Spec.ClusterName).To(Equal("cluster1"))
+		g.Expect(got.Spec.Selector.MatchLabels).To(Equal(map[string]string{
+			clusterv1.ClusterTopologyOwnedLabel:    "",
+			clusterv1.MachineControlPlaneLabelName: "",
+		}))
+		g.Expect(got.Spec.UnhealthyConditions).To(Equal(check.UnhealthyConditions))
+		g.Expect(got.Spec.MaxUnhealthy).To(Equal(&maxUnhealthy))
+
+		// Fields not set in the MachineHealthCheckClass are defaulted as done by the MachineHealthCheck webhook.
+		g.Expect(got.Spec.NodeStartupTimeout).To(Equal(&clusterv1.DefaultNodeStartupTimeout))
+		g.Expect(got.Spec.RemediationTemplate.Namespace).To(Equal(metav1.NamespaceDefault))
+
+		// The MachineHealthCheckClass from the blueprint is not modified.
+		g.Expect(check.RemediationTemplate.Namespace).To(BeEmpty())
+	})
+
+	t.Run("Generates the MachineHealthCheck for a MachineDeployment", func(t *testing.T) {
+		g := NewWithT(t)
+
+		md := builder.MachineDeployment(metav1.NamespaceDefault, "cluster1-md-1-abcde").Build()
+		got := computeMachineHealthCheck(md, selectorForMachineDeploymentMHC("md-1"), cluster, check)
+
+		g.Expect(got.Name).To(Equal("cluster1-md-1-abcde"))
+		g.Expect(got.Spec.Selector.MatchLabels).To(Equal(map[string]string{
+			clusterv1.ClusterTopologyOwnedLabel:                 "",
+			clusterv1.ClusterTopologyMachineDeploymentLabelName: "md-1",
+		}))
+	})
+}
+
 func TestTemplateToObject(t *testing.T) {
 	template := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infrastructureClusterTemplate").
 		WithSpecFields(map[string]interface{}{"spec.template.spec.fakeSetting": true}).
@@ -196,3 +196,17 @@ func (c *ControlPlaneMachineTemplate) Metadata() *Metadata {
 		path: Path{"spec", "machineTemplate", "metadata"},
 	}
 }
+
+// NodeVolumeDetachTimeout provides access to the nodeVolumeDetachTimeout of a MachineTemplate.
+func (c *ControlPlaneMachineTemplate) NodeVolumeDetachTimeout() *Duration {
+	return &Duration{
+		path: Path{"spec", "machineTemplate", "nodeVolumeDetachTimeout"},
+	}
+}
+
+// NodeDeletionTimeout provides access to the nodeDeletionTimeout of a MachineTemplate.
+func (c *ControlPlaneMachineTemplate) NodeDeletionTimeout() *Duration {
+	return &Duration{
+		path: Path{"spec", "machineTemplate", "nodeDeletionTimeout"},
+	}
+}
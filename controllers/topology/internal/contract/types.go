@@ -18,8 +18,10 @@ package contract
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -87,3 +89,39 @@ func (s *String) Set(obj *unstructured.Unstructured, value string) error {
 	}
 	return nil
 }
+
+// Duration represents an accessor to a metav1.Duration path value.
+type Duration struct {
+	path Path
+}
+
+// Path returns the path to the metav1.Duration value.
+func (i *Duration) Path() Path {
+	return i.path
+}
+
+// Get gets the metav1.Duration value.
+func (i *Duration) Get(obj *unstructured.Unstructured) (*metav1.Duration, error) {
+	durationString, ok, err := unstructured.NestedString(obj.UnstructuredContent(), i.path...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s from object", "."+strings.Join(i.path, "."))
+	}
+	if !ok {
+		return nil, errors.Wrapf(errNotFound, "path %s", "."+strings.Join(i.path, "."))
+	}
+
+	d, err := time.ParseDuration(durationString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse duration %s from object", "."+strings.Join(i.path, "."))
+	}
+
+	return &metav1.Duration{Duration: d}, nil
+}
+
+// Set sets the metav1.Duration value in the path.
+func (i *Duration) Set(obj *unstructured.Unstructured, value metav1.Duration) error {
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), value.Duration.String(), i.path...); err != nil {
+		return errors.Wrapf(err, "failed to set path %s of object %v", "."+strings.Join(i.path, "."), obj.GroupVersionKind())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MarkAsPending adds the hooks to the pending hooks annotation of the object, so they are called
+// in a later reconcile even if the controller restarts in the meantime.
+func MarkAsPending(ctx context.Context, c client.Client, obj client.Object, hooks ...runtimehooksv1.Hook) error {
+	patchHelper, err := patch.NewHelper(obj, c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: obj})
+	}
+
+	pending := pendingHooks(obj)
+	for _, hook := range hooks {
+		pending.Insert(string(hook))
+	}
+	setPendingHooks(obj, pending)
+
+	if err := patchHelper.Patch(ctx, obj); err != nil {
+		return errors.Wrapf(err, "failed to mark %s as pending", strings.Join(toStrings(hooks), ","))
+	}
+	return nil
+}
+
+// IsPending returns true if the hook is marked as pending in the annotations of the object.
+func IsPending(hook runtimehooksv1.Hook, obj client.Object) bool {
+	return pendingHooks(obj).Has(string(hook))
+}
+
+// MarkAsDone removes the hooks from the pending hooks annotation of the object.
+func MarkAsDone(ctx context.Context, c client.Client, obj client.Object, hooks ...runtimehooksv1.Hook) error {
+	patchHelper, err := patch.NewHelper(obj, c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: obj})
+	}
+
+	pending := pendingHooks(obj)
+	for _, hook := range hooks {
+		pending.Delete(string(hook))
+	}
+	setPendingHooks(obj, pending)
+
+	if err := patchHelper.Patch(ctx, obj); err != nil {
+		return errors.Wrapf(err, "failed to mark %s as done", strings.Join(toStrings(hooks), ","))
+	}
+	return nil
+}
+
+// MarkAsOkToDelete sets the ok-to-delete annotation on the object, signalling to the controller
+// deleting the object that the BeforeClusterDelete hook is not blocking anymore.
+func MarkAsOkToDelete(ctx context.Context, c client.Client, obj client.Object) error {
+	patchHelper, err := patch.NewHelper(obj, c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: obj})
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[runtimev1.OkToDeleteAnnotation] = ""
+	obj.SetAnnotations(annotations)
+
+	if err := patchHelper.Patch(ctx, obj); err != nil {
+		return errors.Wrap(err, "failed to mark as ok to delete")
+	}
+	return nil
+}
+
+// IsOkToDelete returns true if the object has the ok-to-delete annotation.
+func IsOkToDelete(obj client.Object) bool {
+	_, ok := obj.GetAnnotations()[runtimev1.OkToDeleteAnnotation]
+	return ok
+}
+
+func pendingHooks(obj client.Object) sets.String {
+	value := obj.GetAnnotations()[runtimev1.PendingHooksAnnotation]
+	if value == "" {
+		return sets.NewString()
+	}
+	return sets.NewString(strings.Split(value, ",")...)
+}
+
+func setPendingHooks(obj client.Object, pending sets.String) {
+	annotations := obj.GetAnnotations()
+	if pending.Len() == 0 {
+		delete(annotations, runtimev1.PendingHooksAnnotation)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[runtimev1.PendingHooksAnnotation] = strings.Join(pending.List(), ",")
+	obj.SetAnnotations(annotations)
+}
+
+func toStrings(hooks []runtimehooksv1.Hook) []string {
+	ret := make([]string, 0, len(hooks))
+	for _, hook := range hooks {
+		ret = append(ret, string(hook))
+	}
+	return ret
+}
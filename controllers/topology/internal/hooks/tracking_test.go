@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMarkAsPendingAndDone(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: metav1.NamespaceDefault},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	g.Expect(IsPending(runtimehooksv1.AfterControlPlaneUpgrade, cluster)).To(BeFalse())
+
+	g.Expect(MarkAsPending(context.Background(), c, cluster, runtimehooksv1.AfterControlPlaneUpgrade, runtimehooksv1.BeforeClusterDelete)).To(Succeed())
+	got := &clusterv1.Cluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+	g.Expect(got.Annotations).To(HaveKeyWithValue(runtimev1.PendingHooksAnnotation, "AfterControlPlaneUpgrade,BeforeClusterDelete"))
+	g.Expect(IsPending(runtimehooksv1.AfterControlPlaneUpgrade, got)).To(BeTrue())
+
+	g.Expect(MarkAsDone(context.Background(), c, cluster, runtimehooksv1.AfterControlPlaneUpgrade)).To(Succeed())
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+	g.Expect(IsPending(runtimehooksv1.AfterControlPlaneUpgrade, got)).To(BeFalse())
+	g.Expect(IsPending(runtimehooksv1.BeforeClusterDelete, got)).To(BeTrue())
+
+	g.Expect(MarkAsDone(context.Background(), c, cluster, runtimehooksv1.BeforeClusterDelete)).To(Succeed())
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+	g.Expect(got.Annotations).ToNot(HaveKey(runtimev1.PendingHooksAnnotation))
+}
+
+func TestMarkAsOkToDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: metav1.NamespaceDefault},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	g.Expect(IsOkToDelete(cluster)).To(BeFalse())
+	g.Expect(MarkAsOkToDelete(context.Background(), c, cluster)).To(Succeed())
+
+	got := &clusterv1.Cluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+	g.Expect(IsOkToDelete(got)).To(BeTrue())
+}
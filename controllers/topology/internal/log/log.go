@@ -23,6 +23,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -54,6 +55,9 @@ type Logger interface {
 	// WithMachineDeployment adds to the logger information about the MachineDeployment object being processed.
 	WithMachineDeployment(md *clusterv1.MachineDeployment) Logger
 
+	// WithMachinePool adds to the logger information about the MachinePool object being processed.
+	WithMachinePool(mp *expv1.MachinePool) Logger
+
 	// V returns a logger value for a specific verbosity level, relative to
 	// this logger.
 	V(level int) Logger
@@ -105,6 +109,16 @@ func (l *topologyReconcileLogger) WithMachineDeployment(md *clusterv1.MachineDep
 	return l
 }
 
+// WithMachinePool adds to the logger information about the MachinePool object being processed.
+func (l *topologyReconcileLogger) WithMachinePool(mp *expv1.MachinePool) Logger {
+	topologyName := mp.Labels[clusterv1.ClusterTopologyMachinePoolLabelName]
+	l.Logger = l.Logger.WithValues(
+		"machinePool name", mp.GetName(),
+		"machinePool topologyName", topologyName,
+	)
+	return l
+}
+
 // V returns a logger value for a specific verbosity level, relative to
 // this logger.
 func (l *topologyReconcileLogger) V(level int) Logger {
@@ -0,0 +1,572 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/external"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/inline"
+	patchvariables "sigs.k8s.io/cluster-api/controllers/topology/internal/patches/variables"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	topologyvariables "sigs.k8s.io/cluster-api/internal/topology/variables"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Engine is a patch engine which applies patches defined in a ClusterBlueprint to a ClusterState.
+type Engine interface {
+	Apply(ctx context.Context, blueprint *scope.ClusterBlueprint, desired *scope.ClusterState) error
+}
+
+// NewEngine creates a new patch engine.
+// The client is used to read the ExtensionConfigs referenced by external patches.
+func NewEngine(c client.Reader) Engine {
+	return &engine{
+		client: c,
+	}
+}
+
+// engine implements the Engine interface.
+type engine struct {
+	client client.Reader
+}
+
+// Apply applies patches to the desired state according to the patches from the ClusterClass, variables from the Cluster
+// and builtin variables.
+// * A GenerateRequest with all templates and global and template-specific variables is created.
+// * Then for all ClusterClassPatches of a ClusterClass, JSON or JSON merge patches are generated
+//   and successively applied to the templates in the GenerateRequest.
+// * Eventually the patched templates are used to update the specs of the desired objects.
+func (e *engine) Apply(ctx context.Context, blueprint *scope.ClusterBlueprint, desired *scope.ClusterState) error {
+	// Return if there are no patches.
+	if len(blueprint.ClusterClass.Spec.Patches) == 0 {
+		return nil
+	}
+
+	log := tlog.LoggerFrom(ctx)
+
+	// Create a patch generation request.
+	req, err := createRequest(blueprint, desired)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate patch request")
+	}
+
+	// Loop over patches in ClusterClass, generate patches and apply them to the request,
+	// respecting the order in which they are defined.
+	for i := range blueprint.ClusterClass.Spec.Patches {
+		clusterClassPatch := blueprint.ClusterClass.Spec.Patches[i]
+
+		log.V(5).Infof("Applying patch %q to templates", clusterClassPatch.Name)
+
+		// Create patch generator for the current patch.
+		generator, err := e.createPatchGenerator(ctx, &clusterClassPatch)
+		if err != nil {
+			return err
+		}
+
+		// Generate patches.
+		// NOTE: All the partial patches accumulate on top of the request, so the
+		// patch generator in the next iteration of the loop will get the modified
+		// version of the request (including the patched version of the templates).
+		resp, err := generator.Generate(ctx, req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to generate patches for patch %q", clusterClassPatch.Name)
+		}
+
+		// Apply patches to the request.
+		if err := applyPatchesToRequest(ctx, req, resp); err != nil {
+			return errors.Wrapf(err, "failed to apply patches for patch %q", clusterClassPatch.Name)
+		}
+	}
+
+	// Use patched templates to update the desired state objects.
+	log.V(5).Infof("Applying patched templates to desired state")
+	if err := updateDesiredState(req, blueprint, desired); err != nil {
+		return errors.Wrapf(err, "failed to apply patches to desired state")
+	}
+
+	return nil
+}
+
+// createPatchGenerator creates a patch generator for the given patch.
+// NOTE: External patches are generated by calling the extension registered through the referenced ExtensionConfig,
+// while inline patches are generated from the patch definitions in the ClusterClass.
+func (e *engine) createPatchGenerator(ctx context.Context, patch *clusterv1.ClusterClassPatch) (api.Generator, error) {
+	if patch.External == nil {
+		return inline.New(patch), nil
+	}
+
+	extensionConfig := &runtimev1.ExtensionConfig{}
+	if err := e.client.Get(ctx, client.ObjectKey{Name: patch.External.Extension}, extensionConfig); err != nil {
+		return nil, errors.Wrapf(err, "failed to get ExtensionConfig %q for patch %q", patch.External.Extension, patch.Name)
+	}
+	return external.New(extensionConfig), nil
+}
+
+// createRequest creates a GenerateRequest based on the ClusterBlueprint and the desired state.
+// NOTE: GenerateRequests will be created for the templates of the desired objects (InfrastructureCluster,
+// ControlPlane, BootstrapConfigs and InfrastructureMachinePools of MachinePools) and for the desired
+// templates (InfrastructureMachineTemplates, BootstrapConfigTemplates).
+func createRequest(blueprint *scope.ClusterBlueprint, desired *scope.ClusterState) (*api.GenerateRequest, error) {
+	req := &api.GenerateRequest{}
+
+	// Calculate the user defined variables, including defaults defined in the ClusterClass.
+	clusterVariables, errs := topologyvariables.DefaultClusterVariables(blueprint.Topology.Variables, blueprint.ClusterClass.Spec.Variables, field.NewPath("spec", "topology", "variables"))
+	if len(errs) > 0 {
+		return nil, errors.Wrapf(errs.ToAggregate(), "failed to default variables")
+	}
+
+	// Calculate global variables.
+	globalVariables, err := patchvariables.Global(blueprint.Topology, desired.Cluster, clusterVariables)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate global variables")
+	}
+	req.Variables = globalVariables
+
+	// Add the InfrastructureClusterTemplate.
+	t, err := newTemplateBuilder(blueprint.InfrastructureClusterTemplate).
+		WithType(api.InfrastructureClusterTemplateType).
+		Build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to prepare InfrastructureCluster template %s for patching",
+			tlog.KObj{Obj: blueprint.InfrastructureClusterTemplate})
+	}
+	req.Items = append(req.Items, t)
+
+	// Calculate controlPlane variables.
+	controlPlaneVariables, err := patchvariables.ControlPlane(&blueprint.Topology.ControlPlane, desired.ControlPlane.Object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate ControlPlane variables")
+	}
+
+	// Add the ControlPlaneTemplate.
+	t, err = newTemplateBuilder(blueprint.ControlPlane.Template).
+		WithType(api.ControlPlaneTemplateType).
+		Build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to prepare ControlPlane template %s for patching",
+			tlog.KObj{Obj: blueprint.ControlPlane.Template})
+	}
+	t.Variables = controlPlaneVariables
+	req.Items = append(req.Items, t)
+
+	// If the clusterClass mandates the controlPlane has infrastructureMachines,
+	// add the InfrastructureMachineTemplate for control plane machines.
+	if blueprint.HasControlPlaneInfrastructureMachine() {
+		t, err := newTemplateBuilder(desired.ControlPlane.InfrastructureMachineTemplate).
+			WithType(api.ControlPlaneInfrastructureMachineTemplateType).
+			Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare ControlPlane's machine template %s for patching",
+				tlog.KObj{Obj: desired.ControlPlane.InfrastructureMachineTemplate})
+		}
+		t.Variables = controlPlaneVariables
+		req.Items = append(req.Items, t)
+	}
+
+	// Add BootstrapConfigTemplate and InfrastructureMachine template for all MachineDeploymentTopologies
+	// in the Cluster.
+	// NOTE: We intentionally iterate over MachineDeployment in the Cluster instead of over
+	// MachineDeploymentClasses in the ClusterClass because each MachineDeployment in a topology
+	// has its own state, e.g. version or replicas. This state is used to calculate builtin variables,
+	// which can then be used e.g. to compute the machine image for a specific Kubernetes version.
+	for mdTopologyName, md := range desired.MachineDeployments {
+		// Lookup MachineDeploymentTopology definition from cluster.spec.topology.
+		mdTopology, err := lookupMDTopology(blueprint.Topology, mdTopologyName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Get corresponding MachineDeploymentClass from the ClusterClass.
+		mdClass, ok := blueprint.MachineDeployments[mdTopology.Class]
+		if !ok {
+			return nil, errors.Errorf("failed to lookup MachineDeployment class %q in ClusterClass", mdTopology.Class)
+		}
+
+		// Calculate MachineDeployment variables.
+		mdVariables, err := patchvariables.MachineDeployment(mdTopology, md.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate variables for %s", tlog.KObj{Obj: md.Object})
+		}
+
+		// Add the BootstrapTemplate.
+		t, err := newTemplateBuilder(md.BootstrapTemplate).
+			WithType(api.MachineDeploymentBootstrapConfigTemplateType).
+			WithMachineDeploymentRef(mdTopology).
+			Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare BootstrapConfig template %s for MachineDeployment topology %s for patching",
+				tlog.KObj{Obj: mdClass.BootstrapTemplate}, mdTopologyName)
+		}
+		t.Variables = mdVariables
+		req.Items = append(req.Items, t)
+
+		// Add the InfrastructureMachineTemplate.
+		t, err = newTemplateBuilder(md.InfrastructureMachineTemplate).
+			WithType(api.MachineDeploymentInfrastructureMachineTemplateType).
+			WithMachineDeploymentRef(mdTopology).
+			Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare InfrastructureMachine template %s for MachineDeployment topology %s for patching",
+				tlog.KObj{Obj: mdClass.InfrastructureMachineTemplate}, mdTopologyName)
+		}
+		t.Variables = mdVariables
+		req.Items = append(req.Items, t)
+	}
+
+	// Add BootstrapConfigTemplate and InfrastructureMachinePool template for all MachinePoolTopologies
+	// in the Cluster.
+	// NOTE: As for MachineDeployments, we iterate over the MachinePools in the Cluster, so builtin
+	// variables can be calculated from the state of each MachinePool.
+	for mpTopologyName, mp := range desired.MachinePools {
+		// Lookup MachinePoolTopology definition from cluster.spec.topology.
+		mpTopology, err := lookupMPTopology(blueprint.Topology, mpTopologyName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Get corresponding MachinePoolClass from the ClusterClass.
+		mpClass, ok := blueprint.MachinePools[mpTopology.Class]
+		if !ok {
+			return nil, errors.Errorf("failed to lookup MachinePool class %q in ClusterClass", mpTopology.Class)
+		}
+
+		// Calculate MachinePool variables.
+		mpVariables, err := patchvariables.MachinePool(mpTopology, mp.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate variables for %s", tlog.KObj{Obj: mp.Object})
+		}
+
+		// Add the BootstrapTemplate.
+		t, err := newTemplateBuilder(mpClass.BootstrapTemplate).
+			WithType(api.MachinePoolBootstrapConfigTemplateType).
+			WithMachinePoolRef(mpTopology).
+			Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare BootstrapConfig template %s for MachinePool topology %s for patching",
+				tlog.KObj{Obj: mpClass.BootstrapTemplate}, mpTopologyName)
+		}
+		t.Variables = mpVariables
+		req.Items = append(req.Items, t)
+
+		// Add the InfrastructureMachinePoolTemplate.
+		t, err = newTemplateBuilder(mpClass.InfrastructureMachinePoolTemplate).
+			WithType(api.MachinePoolInfrastructureMachinePoolTemplateType).
+			WithMachinePoolRef(mpTopology).
+			Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare InfrastructureMachinePool template %s for MachinePool topology %s for patching",
+				tlog.KObj{Obj: mpClass.InfrastructureMachinePoolTemplate}, mpTopologyName)
+		}
+		t.Variables = mpVariables
+		req.Items = append(req.Items, t)
+	}
+
+	return req, nil
+}
+
+// lookupMDTopology looks up the MachineDeploymentTopology based on a mdTopologyName in a topology.
+func lookupMDTopology(topology *clusterv1.Topology, mdTopologyName string) (*clusterv1.MachineDeploymentTopology, error) {
+	if topology.Workers == nil {
+		return nil, errors.Errorf("failed to lookup MachineDeployment topology %q in Cluster.spec.topology: no workers defined", mdTopologyName)
+	}
+	for i := range topology.Workers.MachineDeployments {
+		if topology.Workers.MachineDeployments[i].Name == mdTopologyName {
+			return &topology.Workers.MachineDeployments[i], nil
+		}
+	}
+	return nil, errors.Errorf("failed to lookup MachineDeployment topology %q in Cluster.spec.topology", mdTopologyName)
+}
+
+// lookupMPTopology looks up the MachinePoolTopology based on a mpTopologyName in a topology.
+func lookupMPTopology(topology *clusterv1.Topology, mpTopologyName string) (*clusterv1.MachinePoolTopology, error) {
+	if topology.Workers == nil {
+		return nil, errors.Errorf("failed to lookup MachinePool topology %q in Cluster.spec.topology: no workers defined", mpTopologyName)
+	}
+	for i := range topology.Workers.MachinePools {
+		if topology.Workers.MachinePools[i].Name == mpTopologyName {
+			return &topology.Workers.MachinePools[i], nil
+		}
+	}
+	return nil, errors.Errorf("failed to lookup MachinePool topology %q in Cluster.spec.topology", mpTopologyName)
+}
+
+// applyPatchesToRequest updates the templates of a GenerateRequest by applying the patches
+// of a GenerateResponse.
+// NOTE: Only changes to the spec are retained; changes to metadata, apiVersion or kind are discarded.
+func applyPatchesToRequest(ctx context.Context, req *api.GenerateRequest, resp *api.GenerateResponse) error {
+	log := tlog.LoggerFrom(ctx)
+
+	for _, patch := range resp.Items {
+		// Get the template the patch should be applied to.
+		template := getTemplateByRef(req, patch.TemplateRef)
+
+		// If a patch doesn't apply to any template, this is a misconfiguration.
+		if template == nil {
+			return errors.Errorf("generated patch is targeted at the template %s which does not exist", patch.TemplateRef)
+		}
+
+		// Use the patch to create a patched copy of the template.
+		var patchedTemplate []byte
+		var err error
+
+		switch patch.PatchType {
+		case api.JSONPatchType:
+			log.V(5).Infof("Accumulating JSON patch: %s", string(patch.Patch.Raw))
+			jsonPatch, err := jsonpatch.DecodePatch(patch.Patch.Raw)
+			if err != nil {
+				return errors.Wrapf(err, "failed to apply patch to template %s: error decoding json patch (RFC6902): %s",
+					patch.TemplateRef, string(patch.Patch.Raw))
+			}
+
+			patchedTemplate, err = jsonPatch.Apply(template.Template.Raw)
+			if err != nil {
+				return errors.Wrapf(err, "failed to apply patch to template %s: error applying json patch (RFC6902): %s",
+					patch.TemplateRef, string(patch.Patch.Raw))
+			}
+		case api.JSONMergePatchType:
+			log.V(5).Infof("Accumulating JSON merge patch: %s", string(patch.Patch.Raw))
+			patchedTemplate, err = jsonpatch.MergePatch(template.Template.Raw, patch.Patch.Raw)
+			if err != nil {
+				return errors.Wrapf(err, "failed to apply patch to template %s: error applying json merge patch (RFC7386): %s",
+					patch.TemplateRef, string(patch.Patch.Raw))
+			}
+		default:
+			return errors.Errorf("failed to apply patch to template %s: unknown patch type %q", patch.TemplateRef, patch.PatchType)
+		}
+
+		// Overwrite the spec of template.Template with the spec of the patchedTemplate,
+		// to ensure that we only pick up changes to the spec.
+		if err := copySpecFromRaw(template.Template.Raw, patchedTemplate, template); err != nil {
+			return errors.Wrapf(err, "failed to apply patch to template %s", patch.TemplateRef)
+		}
+	}
+	return nil
+}
+
+// copySpecFromRaw writes the original template with the spec of the patched template to the request item.
+func copySpecFromRaw(original, patched []byte, template *api.GenerateRequestTemplate) error {
+	originalObj := &unstructured.Unstructured{}
+	if err := originalObj.UnmarshalJSON(original); err != nil {
+		return errors.Wrap(err, "failed to unmarshal template")
+	}
+	patchedObj := &unstructured.Unstructured{}
+	if err := patchedObj.UnmarshalJSON(patched); err != nil {
+		return errors.Wrap(err, "failed to unmarshal patched template")
+	}
+
+	if err := copySpec(copySpecInput{
+		src:          patchedObj,
+		dest:         originalObj,
+		srcSpecPath:  "spec",
+		destSpecPath: "spec",
+	}); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(originalObj)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patched template")
+	}
+	template.Template = apiextensionsv1.JSON{Raw: raw}
+	return nil
+}
+
+// updateDesiredState uses the patched templates of a GenerateRequest to update the desired state.
+// NOTE: This func should be called after all the patches have been applied to the GenerateRequest.
+func updateDesiredState(req *api.GenerateRequest, blueprint *scope.ClusterBlueprint, desired *scope.ClusterState) error {
+	// Update the InfrastructureCluster.
+	infrastructureClusterTemplate, err := getTemplateAsUnstructured(req, api.InfrastructureClusterTemplateType, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := copySpec(copySpecInput{
+		src:          infrastructureClusterTemplate,
+		dest:         desired.InfrastructureCluster,
+		srcSpecPath:  "spec.template.spec",
+		destSpecPath: "spec",
+	}); err != nil {
+		return err
+	}
+
+	// Update the ControlPlane.
+	controlPlaneTemplate, err := getTemplateAsUnstructured(req, api.ControlPlaneTemplateType, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := copySpec(copySpecInput{
+		src:          controlPlaneTemplate,
+		dest:         desired.ControlPlane.Object,
+		srcSpecPath:  "spec.template.spec",
+		destSpecPath: "spec",
+		// Fields managed by the topology controller itself must not be overwritten by the template.
+		fieldsToPreserve: []contract.Path{
+			contract.ControlPlane().Version().Path(),
+			contract.ControlPlane().Replicas().Path(),
+			contract.ControlPlane().MachineTemplate().Metadata().Path(),
+			contract.ControlPlane().MachineTemplate().InfrastructureRef().Path(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	// If the ClusterClass mandates the ControlPlane has InfrastructureMachines,
+	// update the InfrastructureMachineTemplate for ControlPlane machines.
+	if blueprint.HasControlPlaneInfrastructureMachine() {
+		infrastructureMachineTemplate, err := getTemplateAsUnstructured(req, api.ControlPlaneInfrastructureMachineTemplateType, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := copySpec(copySpecInput{
+			src:          infrastructureMachineTemplate,
+			dest:         desired.ControlPlane.InfrastructureMachineTemplate,
+			srcSpecPath:  "spec",
+			destSpecPath: "spec",
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Update the templates for all MachineDeployments.
+	for mdTopologyName, md := range desired.MachineDeployments {
+		mdRef := &api.MachineDeploymentRef{TopologyName: mdTopologyName}
+
+		// Update the BootstrapConfigTemplate.
+		bootstrapTemplate, err := getTemplateAsUnstructured(req, api.MachineDeploymentBootstrapConfigTemplateType, mdRef, nil)
+		if err != nil {
+			return err
+		}
+		if err := copySpec(copySpecInput{
+			src:          bootstrapTemplate,
+			dest:         md.BootstrapTemplate,
+			srcSpecPath:  "spec",
+			destSpecPath: "spec",
+		}); err != nil {
+			return err
+		}
+
+		// Update the InfrastructureMachineTemplate.
+		infrastructureMachineTemplate, err := getTemplateAsUnstructured(req, api.MachineDeploymentInfrastructureMachineTemplateType, mdRef, nil)
+		if err != nil {
+			return err
+		}
+		if err := copySpec(copySpecInput{
+			src:          infrastructureMachineTemplate,
+			dest:         md.InfrastructureMachineTemplate,
+			srcSpecPath:  "spec",
+			destSpecPath: "spec",
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Update the BootstrapConfigs and InfrastructureMachinePools of all MachinePools.
+	for mpTopologyName, mp := range desired.MachinePools {
+		mpRef := &api.MachinePoolRef{TopologyName: mpTopologyName}
+
+		// Update the BootstrapConfig.
+		bootstrapTemplate, err := getTemplateAsUnstructured(req, api.MachinePoolBootstrapConfigTemplateType, nil, mpRef)
+		if err != nil {
+			return err
+		}
+		if err := copySpec(copySpecInput{
+			src:          bootstrapTemplate,
+			dest:         mp.BootstrapObject,
+			srcSpecPath:  "spec.template.spec",
+			destSpecPath: "spec",
+		}); err != nil {
+			return err
+		}
+
+		// Update the InfrastructureMachinePool.
+		infrastructureMachinePoolTemplate, err := getTemplateAsUnstructured(req, api.MachinePoolInfrastructureMachinePoolTemplateType, nil, mpRef)
+		if err != nil {
+			return err
+		}
+		if err := copySpec(copySpecInput{
+			src:          infrastructureMachinePoolTemplate,
+			dest:         mp.InfrastructureMachinePoolObject,
+			srcSpecPath:  "spec.template.spec",
+			destSpecPath: "spec",
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type copySpecInput struct {
+	src              *unstructured.Unstructured
+	dest             *unstructured.Unstructured
+	srcSpecPath      string
+	destSpecPath     string
+	fieldsToPreserve []contract.Path
+}
+
+// copySpec copies a field from a srcSpecPath in src to a destSpecPath in dest,
+// while preserving fieldsToPreserve.
+func copySpec(in copySpecInput) error {
+	// Backup fields that should be preserved from dest.
+	preservedFields := map[string]interface{}{}
+	for _, field := range in.fieldsToPreserve {
+		value, found, err := unstructured.NestedFieldNoCopy(in.dest.Object, field...)
+		if !found {
+			// Continue if the field does not exist in dest.
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get field %q from %s", strings.Join(field, "."), tlog.KObj{Obj: in.dest})
+		}
+
+		preservedFields[strings.Join(field, ".")] = value
+	}
+
+	// Get spec from src.
+	srcSpec, found, err := unstructured.NestedFieldNoCopy(in.src.Object, strings.Split(in.srcSpecPath, ".")...)
+	if !found {
+		return errors.Errorf("missing field %q in %s", in.srcSpecPath, tlog.KObj{Obj: in.src})
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get field %q from %s", in.srcSpecPath, tlog.KObj{Obj: in.src})
+	}
+
+	// Set spec in dest.
+	if err := unstructured.SetNestedField(in.dest.Object, srcSpec, strings.Split(in.destSpecPath, ".")...); err != nil {
+		return errors.Wrapf(err, "failed to set field %q on %s", in.destSpecPath, tlog.KObj{Obj: in.dest})
+	}
+
+	// Restore preserved fields.
+	errs := []error{}
+	for path, value := range preservedFields {
+		if err := unstructured.SetNestedField(in.dest.Object, value, strings.Split(path, ".")...); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to set field %q on %s", path, tlog.KObj{Obj: in.dest}))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,647 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/server"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApply(t *testing.T) {
+	type expectedFields struct {
+		infrastructureCluster                          map[string]interface{}
+		controlPlane                                   map[string]interface{}
+		controlPlaneInfrastructureMachineTemplate      map[string]interface{}
+		machineDeploymentBootstrapConfigTemplate       map[string]map[string]interface{}
+		machineDeploymentInfrastructureMachineTemplate map[string]map[string]interface{}
+		machinePoolBootstrapConfig                     map[string]map[string]interface{}
+		machinePoolInfrastructureMachinePool           map[string]map[string]interface{}
+	}
+
+	tests := []struct {
+		name           string
+		patches        []clusterv1.ClusterClassPatch
+		variables      []clusterv1.ClusterClassVariable
+		clusterVars    []clusterv1.ClusterVariable
+		expectedFields expectedFields
+		wantErr        bool
+	}{
+		{
+			name: "Should preserve desired state, if there are no patches",
+			// No changes expected.
+			expectedFields: expectedFields{},
+		},
+		{
+			name: "Should apply JSON patches to all templates, using values and variables",
+			variables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "location",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type:    "string",
+							Default: &apiextensionsv1.JSON{Raw: []byte(`"us-central"`)},
+						},
+					},
+				},
+				{
+					Name:     "cpu",
+					Required: true,
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "integer",
+						},
+					},
+				},
+			},
+			clusterVars: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("8")},
+				},
+			},
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "fake-patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureClusterTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									InfrastructureCluster: true,
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/location",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "location",
+									},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.ControlPlaneGroupVersion.String(),
+								Kind:       builder.GenericControlPlaneTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									ControlPlane: true,
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/clusterName",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "builtin.cluster.name",
+									},
+								},
+								// Fields owned by the topology controller must be preserved.
+								{
+									Op:    "add",
+									Path:  "/spec/template/spec/version",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`"v9.9.9"`)},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureMachineKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									ControlPlane: true,
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/cpu",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "cpu",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "fake-patch2",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.BootstrapGroupVersion.String(),
+								Kind:       builder.GenericBootstrapConfigTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+										Names: []string{"default-worker"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/version",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "builtin.machineDeployment.version",
+									},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureMachineKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+										Names: []string{"default-worker"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:    "add",
+									Path:  "/spec/template/spec/role",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`"worker"`)},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "fake-patch3",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.BootstrapGroupVersion.String(),
+								Kind:       builder.GenericBootstrapConfigTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+										Names: []string{"default-mp-worker"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/version",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "builtin.machinePool.version",
+									},
+								},
+							},
+						},
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureMachinePoolTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+										Names: []string{"default-mp-worker"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/role",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "builtin.machinePool.topologyName",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedFields: expectedFields{
+				infrastructureCluster: map[string]interface{}{
+					"spec.location": "us-central",
+				},
+				controlPlane: map[string]interface{}{
+					"spec.clusterName": "cluster1",
+					"spec.version":     "v1.21.2",
+				},
+				controlPlaneInfrastructureMachineTemplate: map[string]interface{}{
+					"spec.template.spec.cpu": int64(8),
+				},
+				machineDeploymentBootstrapConfigTemplate: map[string]map[string]interface{}{
+					"default-worker-topo1": {"spec.template.spec.version": "v1.21.2"},
+				},
+				machineDeploymentInfrastructureMachineTemplate: map[string]map[string]interface{}{
+					"default-worker-topo1": {"spec.template.spec.role": "worker"},
+				},
+				machinePoolBootstrapConfig: map[string]map[string]interface{}{
+					"default-mp-worker-topo1": {"spec.version": "v1.21.3"},
+				},
+				machinePoolInfrastructureMachinePool: map[string]map[string]interface{}{
+					"default-mp-worker-topo1": {"spec.role": "default-mp-worker-topo1"},
+				},
+			},
+		},
+		{
+			name: "Should fail if a patch references a variable which does not exist",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "fake-patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureClusterTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									InfrastructureCluster: true,
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:   "add",
+									Path: "/spec/template/spec/location",
+									ValueFrom: &clusterv1.JSONPatchValue{
+										Variable: "notExisting",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Set up test objects.
+			blueprint, desired := setupTestObjects()
+
+			// If there are patches, set up patch generators.
+			blueprint.ClusterClass.Spec.Patches = tt.patches
+			blueprint.ClusterClass.Spec.Variables = tt.variables
+			blueprint.Topology.Variables = tt.clusterVars
+
+			// Copy the desired objects before applying patches.
+			expectedCluster := desired.Cluster.DeepCopy()
+			expectedInfrastructureCluster := desired.InfrastructureCluster.DeepCopy()
+			expectedControlPlane := desired.ControlPlane.Object.DeepCopy()
+			expectedControlPlaneInfrastructureMachineTemplate := desired.ControlPlane.InfrastructureMachineTemplate.DeepCopy()
+			expectedBootstrapTemplates := map[string]*unstructured.Unstructured{}
+			expectedInfrastructureMachineTemplate := map[string]*unstructured.Unstructured{}
+			for mdTopology, md := range desired.MachineDeployments {
+				expectedBootstrapTemplates[mdTopology] = md.BootstrapTemplate.DeepCopy()
+				expectedInfrastructureMachineTemplate[mdTopology] = md.InfrastructureMachineTemplate.DeepCopy()
+			}
+			expectedBootstrapConfigs := map[string]*unstructured.Unstructured{}
+			expectedInfrastructureMachinePools := map[string]*unstructured.Unstructured{}
+			for mpTopology, mp := range desired.MachinePools {
+				expectedBootstrapConfigs[mpTopology] = mp.BootstrapObject.DeepCopy()
+				expectedInfrastructureMachinePools[mpTopology] = mp.InfrastructureMachinePoolObject.DeepCopy()
+			}
+
+			// Set expected fields on the copy of the objects, so they can be used for comparison with the result of Apply.
+			setSpecFields(expectedInfrastructureCluster, tt.expectedFields.infrastructureCluster)
+			setSpecFields(expectedControlPlane, tt.expectedFields.controlPlane)
+			setSpecFields(expectedControlPlaneInfrastructureMachineTemplate, tt.expectedFields.controlPlaneInfrastructureMachineTemplate)
+			for mdTopology, expectedFields := range tt.expectedFields.machineDeploymentBootstrapConfigTemplate {
+				setSpecFields(expectedBootstrapTemplates[mdTopology], expectedFields)
+			}
+			for mdTopology, expectedFields := range tt.expectedFields.machineDeploymentInfrastructureMachineTemplate {
+				setSpecFields(expectedInfrastructureMachineTemplate[mdTopology], expectedFields)
+			}
+			for mpTopology, expectedFields := range tt.expectedFields.machinePoolBootstrapConfig {
+				setSpecFields(expectedBootstrapConfigs[mpTopology], expectedFields)
+			}
+			for mpTopology, expectedFields := range tt.expectedFields.machinePoolInfrastructureMachinePool {
+				setSpecFields(expectedInfrastructureMachinePools[mpTopology], expectedFields)
+			}
+
+			// Apply patches.
+			if err := NewEngine(nil).Apply(context.Background(), blueprint, desired); err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			g.Expect(tt.wantErr).To(BeFalse())
+
+			// Compare the patched desired objects with the expected desired objects.
+			g.Expect(desired.Cluster).To(Equal(expectedCluster))
+			g.Expect(desired.InfrastructureCluster).To(Equal(expectedInfrastructureCluster))
+			g.Expect(desired.ControlPlane.Object).To(Equal(expectedControlPlane))
+			g.Expect(desired.ControlPlane.InfrastructureMachineTemplate).To(Equal(expectedControlPlaneInfrastructureMachineTemplate))
+			for mdTopology, bootstrapTemplate := range expectedBootstrapTemplates {
+				g.Expect(desired.MachineDeployments[mdTopology].BootstrapTemplate).To(Equal(bootstrapTemplate))
+			}
+			for mdTopology, infrastructureMachineTemplate := range expectedInfrastructureMachineTemplate {
+				g.Expect(desired.MachineDeployments[mdTopology].InfrastructureMachineTemplate).To(Equal(infrastructureMachineTemplate))
+			}
+			for mpTopology, bootstrapConfig := range expectedBootstrapConfigs {
+				g.Expect(desired.MachinePools[mpTopology].BootstrapObject).To(Equal(bootstrapConfig))
+			}
+			for mpTopology, infrastructureMachinePool := range expectedInfrastructureMachinePools {
+				g.Expect(desired.MachinePools[mpTopology].InfrastructureMachinePoolObject).To(Equal(infrastructureMachinePool))
+			}
+		})
+	}
+}
+
+func TestApplyExternalPatch(t *testing.T) {
+	g := NewWithT(t)
+
+	// The extension sets the image on the InfrastructureMachineTemplates of the MachineDeployments
+	// and on the InfrastructureMachinePoolTemplates of the MachinePools.
+	generatePatches := func(_ context.Context, req *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		resp := &runtimehooksv1.GeneratePatchesResponse{}
+		for _, item := range req.Items {
+			if item.TemplateRef.TemplateType != runtimehooksv1.MachineDeploymentInfrastructureMachineTemplateType &&
+				item.TemplateRef.TemplateType != runtimehooksv1.MachinePoolInfrastructureMachinePoolTemplateType {
+				continue
+			}
+			resp.Items = append(resp.Items, runtimehooksv1.GeneratePatchesResponseItem{
+				TemplateRef: item.TemplateRef,
+				Patch:       apiextensionsv1.JSON{Raw: []byte(`[{"op":"add","path":"/spec/template/spec/image","value":"image-1"}]`)},
+				PatchType:   runtimehooksv1.JSONPatchType,
+			})
+		}
+		return resp, nil
+	}
+	srv := httptest.NewTLSServer(server.NewGeneratePatchesHandler(generatePatches))
+	defer srv.Close()
+
+	extensionConfig := &runtimev1.ExtensionConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-extension"},
+		Spec: runtimev1.ExtensionConfigSpec{
+			ClientConfig: runtimev1.ClientConfig{
+				URL:      pointer.String(srv.URL),
+				CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	g.Expect(runtimev1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(extensionConfig).Build()
+
+	blueprint, desired := setupTestObjects()
+	blueprint.ClusterClass.Spec.Patches = []clusterv1.ClusterClassPatch{
+		{
+			Name:     "image",
+			External: &clusterv1.ExternalPatchDefinition{Extension: "image-extension"},
+		},
+	}
+
+	expectedInfrastructureMachineTemplate := desired.MachineDeployments["default-worker-topo1"].InfrastructureMachineTemplate.DeepCopy()
+	setSpecFields(expectedInfrastructureMachineTemplate, map[string]interface{}{"spec.template.spec.image": "image-1"})
+	expectedBootstrapTemplate := desired.MachineDeployments["default-worker-topo1"].BootstrapTemplate.DeepCopy()
+	expectedInfrastructureMachinePool := desired.MachinePools["default-mp-worker-topo1"].InfrastructureMachinePoolObject.DeepCopy()
+	setSpecFields(expectedInfrastructureMachinePool, map[string]interface{}{"spec.image": "image-1"})
+	expectedBootstrapConfig := desired.MachinePools["default-mp-worker-topo1"].BootstrapObject.DeepCopy()
+
+	g.Expect(NewEngine(c).Apply(context.Background(), blueprint, desired)).To(Succeed())
+	g.Expect(desired.MachineDeployments["default-worker-topo1"].InfrastructureMachineTemplate).To(Equal(expectedInfrastructureMachineTemplate))
+	g.Expect(desired.MachineDeployments["default-worker-topo1"].BootstrapTemplate).To(Equal(expectedBootstrapTemplate))
+	g.Expect(desired.MachinePools["default-mp-worker-topo1"].InfrastructureMachinePoolObject).To(Equal(expectedInfrastructureMachinePool))
+	g.Expect(desired.MachinePools["default-mp-worker-topo1"].BootstrapObject).To(Equal(expectedBootstrapConfig))
+
+	// Applying patches fails if the referenced ExtensionConfig does not exist.
+	blueprint.ClusterClass.Spec.Patches[0].External.Extension = "does-not-exist"
+	g.Expect(NewEngine(c).Apply(context.Background(), blueprint, desired)).ToNot(Succeed())
+}
+
+func setupTestObjects() (*scope.ClusterBlueprint, *scope.ClusterState) {
+	infrastructureClusterTemplate := builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infraClusterTemplate1").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.location": "us-east",
+		}).
+		Build()
+
+	controlPlaneInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "controlplaneinframachinetemplate1").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.cpu": int64(2),
+		}).
+		Build()
+	controlPlaneTemplate := builder.ControlPlaneTemplate(metav1.NamespaceDefault, "controlPlaneTemplate1").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.clusterName": "",
+		}).
+		WithInfrastructureMachineTemplate(controlPlaneInfrastructureMachineTemplate).
+		Build()
+
+	workerInfrastructureMachineTemplate := builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "linux-worker-inframachinetemplate").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.role": "",
+		}).
+		Build()
+	workerBootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "linux-worker-bootstraptemplate").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.version": "",
+		}).
+		Build()
+	mdClass1 := builder.MachineDeploymentClass(metav1.NamespaceDefault, "class1").
+		WithClass("default-worker").
+		WithInfrastructureTemplate(workerInfrastructureMachineTemplate).
+		WithBootstrapTemplate(workerBootstrapTemplate).
+		Build()
+
+	workerInfrastructureMachinePoolTemplate := builder.InfrastructureMachinePoolTemplate(metav1.NamespaceDefault, "linux-worker-inframachinepooltemplate").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.role": "",
+		}).
+		Build()
+	workerMachinePoolBootstrapTemplate := builder.BootstrapTemplate(metav1.NamespaceDefault, "linux-worker-machinepool-bootstraptemplate").
+		WithSpecFields(map[string]interface{}{
+			"spec.template.spec.version": "",
+		}).
+		Build()
+	mpClass1 := builder.MachinePoolClass(metav1.NamespaceDefault, "class1").
+		WithClass("default-mp-worker").
+		WithInfrastructureTemplate(workerInfrastructureMachinePoolTemplate).
+		WithBootstrapTemplate(workerMachinePoolBootstrapTemplate).
+		Build()
+
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "clusterClass1").
+		WithInfrastructureClusterTemplate(infrastructureClusterTemplate).
+		WithControlPlaneTemplate(controlPlaneTemplate).
+		WithControlPlaneInfrastructureMachineTemplate(controlPlaneInfrastructureMachineTemplate).
+		WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{*mdClass1}).
+		WithWorkerMachinePoolClasses([]clusterv1.MachinePoolClass{*mpClass1}).
+		Build()
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Paused: false,
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				APIServerPort: pointer.Int32(8),
+				Services: &clusterv1.NetworkRanges{
+					CIDRBlocks: []string{"10.10.10.1/24"},
+				},
+				Pods: &clusterv1.NetworkRanges{
+					CIDRBlocks: []string{"11.10.10.1/24"},
+				},
+				ServiceDomain: "lark",
+			},
+			ControlPlaneRef:   nil,
+			InfrastructureRef: nil,
+			Topology: &clusterv1.Topology{
+				Version: "v1.21.2",
+				Class:   clusterClass.Name,
+				ControlPlane: clusterv1.ControlPlaneTopology{
+					Replicas: pointer.Int32(3),
+				},
+				Workers: &clusterv1.WorkersTopology{
+					MachineDeployments: []clusterv1.MachineDeploymentTopology{
+						{
+							Metadata: clusterv1.ObjectMeta{},
+							Class:    "default-worker",
+							Name:     "default-worker-topo1",
+						},
+					},
+					MachinePools: []clusterv1.MachinePoolTopology{
+						{
+							Metadata: clusterv1.ObjectMeta{},
+							Class:    "default-mp-worker",
+							Name:     "default-mp-worker-topo1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Aggregating Cluster, Templates and ClusterClass into a blueprint.
+	blueprint := &scope.ClusterBlueprint{
+		Topology:                      cluster.Spec.Topology,
+		ClusterClass:                  clusterClass,
+		InfrastructureClusterTemplate: infrastructureClusterTemplate,
+		ControlPlane: &scope.ControlPlaneBlueprint{
+			Template:                      controlPlaneTemplate,
+			InfrastructureMachineTemplate: controlPlaneInfrastructureMachineTemplate,
+		},
+		MachineDeployments: map[string]*scope.MachineDeploymentBlueprint{
+			"default-worker": {
+				InfrastructureMachineTemplate: workerInfrastructureMachineTemplate,
+				BootstrapTemplate:             workerBootstrapTemplate,
+			},
+		},
+		MachinePools: map[string]*scope.MachinePoolBlueprint{
+			"default-mp-worker": {
+				InfrastructureMachinePoolTemplate: workerInfrastructureMachinePoolTemplate,
+				BootstrapTemplate:                 workerMachinePoolBootstrapTemplate,
+			},
+		},
+	}
+
+	// Create a Cluster using the ClusterClass from above with multiple MachineDeployments
+	// using the same MachineDeployment class.
+	desiredCluster := cluster.DeepCopy()
+	infrastructureCluster := builder.InfrastructureCluster(metav1.NamespaceDefault, "infraClusterTemplate1").
+		WithSpecFields(map[string]interface{}{
+			"spec.location": "us-east",
+		}).
+		Build()
+	controlPlane := builder.ControlPlane(metav1.NamespaceDefault, "controlPlane1").
+		WithSpecFields(map[string]interface{}{
+			"spec.version":     "v1.21.2",
+			"spec.replicas":    int64(3),
+			"spec.clusterName": "",
+		}).
+		WithInfrastructureMachineTemplate(controlPlaneInfrastructureMachineTemplate).
+		Build()
+
+	md1 := builder.MachineDeployment(metav1.NamespaceDefault, "md1").
+		WithReplicas(3).
+		WithVersion("v1.21.2").
+		Build()
+
+	mp1 := builder.MachinePool(metav1.NamespaceDefault, "mp1").
+		WithReplicas(3).
+		WithVersion("v1.21.3").
+		Build()
+	mp1BootstrapConfig := builder.BootstrapConfig(metav1.NamespaceDefault, "mp1-bootstrapconfig").
+		WithSpecFields(map[string]interface{}{
+			"spec.version": "",
+		}).
+		Build()
+	mp1InfrastructureMachinePool := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "mp1-inframachinepool").
+		WithSpecFields(map[string]interface{}{
+			"spec.role": "",
+		}).
+		Build()
+
+	// Aggregating current cluster objects into ClusterState (simulating getCurrentState).
+	desired := &scope.ClusterState{
+		Cluster:               desiredCluster,
+		InfrastructureCluster: infrastructureCluster,
+		ControlPlane: &scope.ControlPlaneState{
+			Object:                        controlPlane,
+			InfrastructureMachineTemplate: controlPlaneInfrastructureMachineTemplate.DeepCopy(),
+		},
+		MachineDeployments: map[string]*scope.MachineDeploymentState{
+			"default-worker-topo1": {
+				Object:                        md1,
+				InfrastructureMachineTemplate: workerInfrastructureMachineTemplate.DeepCopy(),
+				BootstrapTemplate:             workerBootstrapTemplate.DeepCopy(),
+			},
+		},
+		MachinePools: map[string]*scope.MachinePoolState{
+			"default-mp-worker-topo1": {
+				Object:                          mp1,
+				BootstrapObject:                 mp1BootstrapConfig,
+				InfrastructureMachinePoolObject: mp1InfrastructureMachinePool,
+			},
+		},
+	}
+	return blueprint, desired
+}
+
+// setSpecFields sets fields on an unstructured object from a map.
+func setSpecFields(obj *unstructured.Unstructured, fields map[string]interface{}) {
+	for k, v := range fields {
+		fieldParts := strings.Split(k, ".")
+		if len(fieldParts) == 0 {
+			panic(fmt.Errorf("fieldParts invalid"))
+		}
+		if fieldParts[0] != "spec" {
+			panic(fmt.Errorf("can not set fields outside spec"))
+		}
+		if err := unstructured.SetNestedField(obj.UnstructuredContent(), v, strings.Split(k, ".")...); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package external
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimeclient "sigs.k8s.io/cluster-api/exp/runtime/client"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+)
+
+// externalPatchGenerator generates JSON patches for a GenerateRequest by calling an extension.
+type externalPatchGenerator struct {
+	extensionConfig *runtimev1.ExtensionConfig
+}
+
+// New returns a new external Generator calling the extension registered by the given ExtensionConfig.
+func New(extensionConfig *runtimev1.ExtensionConfig) api.Generator {
+	return &externalPatchGenerator{
+		extensionConfig: extensionConfig,
+	}
+}
+
+// Generate generates patches for the given GenerateRequest by calling the extension.
+// NOTE: If the failure policy of the ExtensionConfig is Ignore, errors are logged and an empty response is returned,
+// so the templates are not patched by this generator.
+func (e *externalPatchGenerator) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	resp, err := e.generate(ctx, req)
+	if err != nil {
+		if runtimeclient.FailurePolicy(e.extensionConfig) == runtimev1.FailurePolicyIgnore {
+			tlog.LoggerFrom(ctx).Infof("Ignoring error calling extension %q: %v", e.extensionConfig.Name, err)
+			return &api.GenerateResponse{}, nil
+		}
+		return nil, errors.Wrapf(err, "failed to call extension %q", e.extensionConfig.Name)
+	}
+	return resp, nil
+}
+
+func (e *externalPatchGenerator) generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	resp := &runtimehooksv1.GeneratePatchesResponse{}
+	if err := runtimeclient.CallExtension(ctx, e.extensionConfig, "", convertRequest(req), resp); err != nil {
+		return nil, err
+	}
+	return convertResponse(resp), nil
+}
+
+// convertRequest converts a GenerateRequest into the request sent to the extension.
+func convertRequest(req *api.GenerateRequest) *runtimehooksv1.GeneratePatchesRequest {
+	ret := &runtimehooksv1.GeneratePatchesRequest{
+		Variables: req.Variables,
+		Items:     []runtimehooksv1.GeneratePatchesRequestItem{},
+	}
+	for _, item := range req.Items {
+		ret.Items = append(ret.Items, runtimehooksv1.GeneratePatchesRequestItem{
+			TemplateRef: runtimehooksv1.TemplateRef{
+				APIVersion:   item.TemplateRef.APIVersion,
+				Kind:         item.TemplateRef.Kind,
+				TemplateType: runtimehooksv1.TemplateType(item.TemplateRef.TemplateType),
+				MachineDeploymentRef: runtimehooksv1.MachineDeploymentRef{
+					TopologyName: item.TemplateRef.MachineDeploymentRef.TopologyName,
+					Class:        item.TemplateRef.MachineDeploymentRef.Class,
+				},
+				MachinePoolRef: runtimehooksv1.MachinePoolRef{
+					TopologyName: item.TemplateRef.MachinePoolRef.TopologyName,
+					Class:        item.TemplateRef.MachinePoolRef.Class,
+				},
+			},
+			Variables: item.Variables,
+			Template:  item.Template,
+		})
+	}
+	return ret
+}
+
+// convertResponse converts the response returned by the extension into a GenerateResponse.
+func convertResponse(resp *runtimehooksv1.GeneratePatchesResponse) *api.GenerateResponse {
+	ret := &api.GenerateResponse{}
+	for _, item := range resp.Items {
+		ret.Items = append(ret.Items, api.GenerateResponsePatch{
+			TemplateRef: api.TemplateRef{
+				APIVersion:   item.TemplateRef.APIVersion,
+				Kind:         item.TemplateRef.Kind,
+				TemplateType: api.TemplateType(item.TemplateRef.TemplateType),
+				MachineDeploymentRef: api.MachineDeploymentRef{
+					TopologyName: item.TemplateRef.MachineDeploymentRef.TopologyName,
+					Class:        item.TemplateRef.MachineDeploymentRef.Class,
+				},
+				MachinePoolRef: api.MachinePoolRef{
+					TopologyName: item.TemplateRef.MachinePoolRef.TopologyName,
+					Class:        item.TemplateRef.MachinePoolRef.Class,
+				},
+			},
+			Patch:     item.Patch,
+			PatchType: api.PatchType(item.PatchType),
+		})
+	}
+	return ret
+}
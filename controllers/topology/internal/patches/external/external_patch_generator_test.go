@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package external
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/server"
+)
+
+func TestGenerate(t *testing.T) {
+	templateRef := api.TemplateRef{
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Kind:         "GenericInfrastructureMachineTemplate",
+		TemplateType: api.MachineDeploymentInfrastructureMachineTemplateType,
+		MachineDeploymentRef: api.MachineDeploymentRef{
+			TopologyName: "md1",
+			Class:        "default-worker",
+		},
+	}
+	req := &api.GenerateRequest{
+		Variables: map[string]apiextensionsv1.JSON{
+			"builtin": {Raw: []byte(`{"cluster":{"name":"cluster1"}}`)},
+		},
+		Items: []*api.GenerateRequestTemplate{
+			{
+				TemplateRef: templateRef,
+				Template:    apiextensionsv1.JSON{Raw: []byte(`{"spec":{"template":{"spec":{}}}}`)},
+			},
+		},
+	}
+
+	// generatePatches returns a patch setting the image for each of the templates in the request.
+	generatePatches := func(_ context.Context, req *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		resp := &runtimehooksv1.GeneratePatchesResponse{}
+		for _, item := range req.Items {
+			resp.Items = append(resp.Items, runtimehooksv1.GeneratePatchesResponseItem{
+				TemplateRef: item.TemplateRef,
+				Patch:       apiextensionsv1.JSON{Raw: []byte(`[{"op":"add","path":"/spec/template/spec/image","value":"image-1"}]`)},
+				PatchType:   runtimehooksv1.JSONPatchType,
+			})
+		}
+		return resp, nil
+	}
+	failGeneratePatches := func(_ context.Context, _ *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		return nil, errors.New("image lookup failed")
+	}
+	blockGeneratePatches := func(ctx context.Context, _ *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	tests := []struct {
+		name            string
+		generatePatches server.GeneratePatchesFunc
+		failurePolicy   runtimev1.FailurePolicy
+		skipCABundle    bool
+		want            *api.GenerateResponse
+		wantErr         bool
+	}{
+		{
+			name:            "Should return the patches generated by the extension",
+			generatePatches: generatePatches,
+			want: &api.GenerateResponse{
+				Items: []api.GenerateResponsePatch{
+					{
+						TemplateRef: templateRef,
+						Patch:       apiextensionsv1.JSON{Raw: []byte(`[{"op":"add","path":"/spec/template/spec/image","value":"image-1"}]`)},
+						PatchType:   api.JSONPatchType,
+					},
+				},
+			},
+		},
+		{
+			name:            "Should fail if the extension fails and failure policy is Fail",
+			generatePatches: failGeneratePatches,
+			failurePolicy:   runtimev1.FailurePolicyFail,
+			wantErr:         true,
+		},
+		{
+			name:            "Should return an empty response if the extension fails and failure policy is Ignore",
+			generatePatches: failGeneratePatches,
+			failurePolicy:   runtimev1.FailurePolicyIgnore,
+			want:            &api.GenerateResponse{},
+		},
+		{
+			name:            "Should fail if the extension does not respond within the timeout",
+			generatePatches: blockGeneratePatches,
+			wantErr:         true,
+		},
+		{
+			name:            "Should fail if the server certificate can't be verified",
+			generatePatches: generatePatches,
+			skipCABundle:    true,
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			srv := httptest.NewTLSServer(server.NewGeneratePatchesHandler(tt.generatePatches))
+			defer srv.Close()
+
+			extensionConfig := &runtimev1.ExtensionConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "extension1"},
+				Spec: runtimev1.ExtensionConfigSpec{
+					ClientConfig: runtimev1.ClientConfig{
+						URL: pointer.String(srv.URL),
+					},
+					TimeoutSeconds: pointer.Int32(1),
+				},
+			}
+			if !tt.skipCABundle {
+				extensionConfig.Spec.ClientConfig.CABundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+			}
+			if tt.failurePolicy != "" {
+				extensionConfig.Spec.FailurePolicy = &tt.failurePolicy
+			}
+
+			got, err := New(extensionConfig).Generate(context.Background(), req)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inline
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+	patchvariables "sigs.k8s.io/cluster-api/controllers/topology/internal/patches/variables"
+)
+
+// jsonPatchGenerator generates JSON patches for a GenerateRequest based on a ClusterClassPatch.
+type jsonPatchGenerator struct {
+	patch *clusterv1.ClusterClassPatch
+}
+
+// New returns a new inline Generator from a given ClusterClassPatch object.
+func New(patch *clusterv1.ClusterClassPatch) api.Generator {
+	return &jsonPatchGenerator{
+		patch: patch,
+	}
+}
+
+// Generate generates JSON patches for the given GenerateRequest based on a ClusterClassPatch.
+func (j *jsonPatchGenerator) Generate(_ context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	resp := &api.GenerateResponse{}
+
+	globalVariables := patchvariables.VariableMap(req.Variables)
+
+	// Loop over all templates.
+	errs := []error{}
+	for _, item := range req.Items {
+		templateVariables := patchvariables.VariableMap(item.Variables)
+
+		// Calculate the list of patches which match the current template.
+		matchingPatches := []clusterv1.PatchDefinition{}
+		for _, patch := range j.patch.Definitions {
+			// Add the patch to the list, if it matches the template.
+			if templateMatchesSelector(&item.TemplateRef, patch.Selector) {
+				matchingPatches = append(matchingPatches, patch)
+			}
+		}
+
+		// Continue if there are no matching patches.
+		if len(matchingPatches) == 0 {
+			continue
+		}
+
+		// Merge template-specific and global variables.
+		variables, err := patchvariables.MergeVariableMaps(globalVariables, templateVariables)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to merge global and template-specific variables for %q", item.TemplateRef))
+			continue
+		}
+
+		// Loop over all PatchDefinitions.
+		enabledPatches := jsonPatches{}
+		for _, patch := range matchingPatches {
+			// Generate JSON patches.
+			jsonPatches, err := generateJSONPatches(patch.JSONPatches, variables)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to generate JSON patches for %q", item.TemplateRef))
+				continue
+			}
+			enabledPatches = append(enabledPatches, jsonPatches...)
+		}
+
+		// Continue if no patches have been generated for the current template.
+		if len(enabledPatches) == 0 {
+			continue
+		}
+
+		// Add JSON patches to the response.
+		patchJSON, err := json.Marshal(enabledPatches)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to marshal JSON patches for %q", item.TemplateRef))
+			continue
+		}
+		resp.Items = append(resp.Items, api.GenerateResponsePatch{
+			TemplateRef: item.TemplateRef,
+			Patch:       apiextensionsv1.JSON{Raw: patchJSON},
+			PatchType:   api.JSONPatchType,
+		})
+	}
+
+	if err := kerrors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// templateMatchesSelector returns true if the template matches the selector.
+func templateMatchesSelector(templateRef *api.TemplateRef, selector clusterv1.PatchSelector) bool {
+	// Check if the apiVersion and kind are matching.
+	if templateRef.APIVersion != selector.APIVersion {
+		return false
+	}
+	if templateRef.Kind != selector.Kind {
+		return false
+	}
+
+	// Check if target matches InfrastructureCluster.
+	if selector.MatchResources.InfrastructureCluster {
+		if templateRef.TemplateType == api.InfrastructureClusterTemplateType {
+			return true
+		}
+	}
+
+	// Check if target matches ControlPlane or the infrastructure machines of the ControlPlane.
+	if selector.MatchResources.ControlPlane {
+		if templateRef.TemplateType == api.ControlPlaneTemplateType ||
+			templateRef.TemplateType == api.ControlPlaneInfrastructureMachineTemplateType {
+			return true
+		}
+	}
+
+	// Check if target matches a template of a MachineDeploymentClass listed in the selector.
+	if selector.MatchResources.MachineDeploymentClass != nil {
+		if templateRef.TemplateType == api.MachineDeploymentBootstrapConfigTemplateType ||
+			templateRef.TemplateType == api.MachineDeploymentInfrastructureMachineTemplateType {
+			for _, className := range selector.MatchResources.MachineDeploymentClass.Names {
+				if templateRef.MachineDeploymentRef.Class == className {
+					return true
+				}
+			}
+		}
+	}
+
+	// Check if target matches a template of a MachinePoolClass listed in the selector.
+	if selector.MatchResources.MachinePoolClass != nil {
+		if templateRef.TemplateType == api.MachinePoolBootstrapConfigTemplateType ||
+			templateRef.TemplateType == api.MachinePoolInfrastructureMachinePoolTemplateType {
+			for _, className := range selector.MatchResources.MachinePoolClass.Names {
+				if templateRef.MachinePoolRef.Class == className {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// jsonPatchRFC6902 is used to render the generated JSONPatches.
+type jsonPatchRFC6902 struct {
+	Op    string                `json:"op"`
+	Path  string                `json:"path"`
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+type jsonPatches []jsonPatchRFC6902
+
+// generateJSONPatches generates JSON patches based on the given JSONPatches and variables.
+func generateJSONPatches(jsonPatches []clusterv1.JSONPatch, variables patchvariables.VariableMap) (jsonPatches, error) {
+	res := []jsonPatchRFC6902{}
+
+	for _, jsonPatch := range jsonPatches {
+		var value *apiextensionsv1.JSON
+		if jsonPatch.Op == "add" || jsonPatch.Op == "replace" {
+			var err error
+			value, err = calculateValue(jsonPatch, variables)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, jsonPatchRFC6902{
+			Op:    jsonPatch.Op,
+			Path:  jsonPatch.Path,
+			Value: value,
+		})
+	}
+
+	return res, nil
+}
+
+// calculateValue calculates a value for a JSON patch.
+func calculateValue(patch clusterv1.JSONPatch, variables patchvariables.VariableMap) (*apiextensionsv1.JSON, error) {
+	// Return if values are set incorrectly.
+	if patch.Value == nil && patch.ValueFrom == nil {
+		return nil, errors.Errorf("failed to calculate value: neither .value nor .valueFrom are set")
+	}
+	if patch.Value != nil && patch.ValueFrom != nil {
+		return nil, errors.Errorf("failed to calculate value: both .value and .valueFrom are set")
+	}
+	if patch.ValueFrom != nil && strings.TrimSpace(patch.ValueFrom.Variable) == "" {
+		return nil, errors.Errorf("failed to calculate value: .valueFrom.variable is not set")
+	}
+
+	// Return .value.
+	if patch.Value != nil {
+		return patch.Value, nil
+	}
+
+	// Return .valueFrom.variable.
+	value, err := patchvariables.GetVariableValue(variables, patch.ValueFrom.Variable)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate value")
+	}
+	return value, nil
+}
@@ -0,0 +1,468 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch *clusterv1.ClusterClassPatch
+		req   *api.GenerateRequest
+		want  *api.GenerateResponse
+	}{
+		{
+			name: "Should generate JSON Patches with correctly calculated values",
+			patch: &clusterv1.ClusterClassPatch{
+				Name: "clusterName",
+				Definitions: []clusterv1.PatchDefinition{
+					{
+						Selector: clusterv1.PatchSelector{
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:       "ControlPlaneTemplate",
+							MatchResources: clusterv1.PatchSelectorMatch{
+								ControlPlane: true,
+							},
+						},
+						JSONPatches: []clusterv1.JSONPatch{
+							// .value
+							{
+								Op:    "replace",
+								Path:  "/spec/value",
+								Value: &apiextensionsv1.JSON{Raw: []byte("1")},
+							},
+							// .valueFrom.variable
+							{
+								Op:   "replace",
+								Path: "/spec/valueFrom/variable",
+								ValueFrom: &clusterv1.JSONPatchValue{
+									Variable: "variableA",
+								},
+							},
+							// .valueFrom.variable with a nested builtin
+							{
+								Op:   "replace",
+								Path: "/spec/valueFrom/builtin",
+								ValueFrom: &clusterv1.JSONPatchValue{
+									Variable: "builtin.controlPlane.version",
+								},
+							},
+							// remove
+							{
+								Op:   "remove",
+								Path: "/spec/toRemove",
+							},
+						},
+					},
+				},
+			},
+			req: &api.GenerateRequest{
+				Variables: map[string]apiextensionsv1.JSON{
+					"builtin":   {Raw: []byte(`{"cluster":{"name":"cluster-name"}}`)},
+					"variableA": {Raw: []byte(`"A"`)},
+				},
+				Items: []*api.GenerateRequestTemplate{
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "ControlPlaneTemplate",
+							TemplateType: api.ControlPlaneTemplateType,
+						},
+						Variables: map[string]apiextensionsv1.JSON{
+							"builtin": {Raw: []byte(`{"controlPlane":{"version":"v1.21.1"}}`)},
+						},
+					},
+				},
+			},
+			want: &api.GenerateResponse{
+				Items: []api.GenerateResponsePatch{
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "ControlPlaneTemplate",
+							TemplateType: api.ControlPlaneTemplateType,
+						},
+						Patch: toJSONCompact(`[
+{"op":"replace","path":"/spec/value","value":1},
+{"op":"replace","path":"/spec/valueFrom/variable","value":"A"},
+{"op":"replace","path":"/spec/valueFrom/builtin","value":"v1.21.1"},
+{"op":"remove","path":"/spec/toRemove"}
+]`),
+						PatchType: api.JSONPatchType,
+					},
+				},
+			},
+		},
+		{
+			name: "Should only generate patches for matching templates",
+			patch: &clusterv1.ClusterClassPatch{
+				Name: "machineDeploymentOnly",
+				Definitions: []clusterv1.PatchDefinition{
+					{
+						Selector: clusterv1.PatchSelector{
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:       "InfrastructureMachineTemplate",
+							MatchResources: clusterv1.PatchSelectorMatch{
+								MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+									Names: []string{"default-worker"},
+								},
+							},
+						},
+						JSONPatches: []clusterv1.JSONPatch{
+							{
+								Op:    "add",
+								Path:  "/spec/template/spec/value",
+								Value: &apiextensionsv1.JSON{Raw: []byte(`"worker"`)},
+							},
+						},
+					},
+				},
+			},
+			req: &api.GenerateRequest{
+				Items: []*api.GenerateRequestTemplate{
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "InfrastructureMachineTemplate",
+							TemplateType: api.ControlPlaneInfrastructureMachineTemplateType,
+						},
+					},
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "InfrastructureMachineTemplate",
+							TemplateType: api.MachineDeploymentInfrastructureMachineTemplateType,
+							MachineDeploymentRef: api.MachineDeploymentRef{
+								TopologyName: "md1",
+								Class:        "default-worker",
+							},
+						},
+					},
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "InfrastructureMachineTemplate",
+							TemplateType: api.MachineDeploymentInfrastructureMachineTemplateType,
+							MachineDeploymentRef: api.MachineDeploymentRef{
+								TopologyName: "md2",
+								Class:        "other-worker",
+							},
+						},
+					},
+				},
+			},
+			want: &api.GenerateResponse{
+				Items: []api.GenerateResponsePatch{
+					{
+						TemplateRef: api.TemplateRef{
+							APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+							Kind:         "InfrastructureMachineTemplate",
+							TemplateType: api.MachineDeploymentInfrastructureMachineTemplateType,
+							MachineDeploymentRef: api.MachineDeploymentRef{
+								TopologyName: "md1",
+								Class:        "default-worker",
+							},
+						},
+						Patch:     toJSONCompact(`[{"op":"add","path":"/spec/template/spec/value","value":"worker"}]`),
+						PatchType: api.JSONPatchType,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := New(tt.patch).Generate(context.Background(), tt.req)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestTemplateMatchesSelector(t *testing.T) {
+	tests := []struct {
+		name        string
+		templateRef *api.TemplateRef
+		selector    clusterv1.PatchSelector
+		match       bool
+	}{
+		{
+			name: "Match InfrastructureClusterTemplate",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureClusterTemplate",
+				TemplateType: api.InfrastructureClusterTemplateType,
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureClusterTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					InfrastructureCluster: true,
+				},
+			},
+			match: true,
+		},
+		{
+			name: "Don't match InfrastructureClusterTemplate, .matchResources.infrastructureCluster not set",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureClusterTemplate",
+				TemplateType: api.InfrastructureClusterTemplateType,
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureClusterTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					ControlPlane: true,
+				},
+			},
+			match: false,
+		},
+		{
+			name: "Don't match InfrastructureClusterTemplate, kind does not match",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureClusterTemplate",
+				TemplateType: api.InfrastructureClusterTemplateType,
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AWSClusterTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					InfrastructureCluster: true,
+				},
+			},
+			match: false,
+		},
+		{
+			name: "Match ControlPlane InfrastructureMachineTemplate",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureMachineTemplate",
+				TemplateType: api.ControlPlaneInfrastructureMachineTemplateType,
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureMachineTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					ControlPlane: true,
+				},
+			},
+			match: true,
+		},
+		{
+			name: "Don't match MachineDeployment InfrastructureMachineTemplate, class not listed",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureMachineTemplate",
+				TemplateType: api.MachineDeploymentInfrastructureMachineTemplateType,
+				MachineDeploymentRef: api.MachineDeploymentRef{
+					Class: "classB",
+				},
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureMachineTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+						Names: []string{"classA"},
+					},
+				},
+			},
+			match: false,
+		},
+		{
+			name: "Match MachinePool InfrastructureMachinePoolTemplate",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureMachinePoolTemplate",
+				TemplateType: api.MachinePoolInfrastructureMachinePoolTemplateType,
+				MachinePoolRef: api.MachinePoolRef{
+					Class: "classA",
+				},
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureMachinePoolTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+						Names: []string{"classA"},
+					},
+				},
+			},
+			match: true,
+		},
+		{
+			name: "Match MachinePool BootstrapConfigTemplate",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "bootstrap.cluster.x-k8s.io/v1beta1",
+				Kind:         "KubeadmConfigTemplate",
+				TemplateType: api.MachinePoolBootstrapConfigTemplateType,
+				MachinePoolRef: api.MachinePoolRef{
+					Class: "classA",
+				},
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+				Kind:       "KubeadmConfigTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+						Names: []string{"classA"},
+					},
+				},
+			},
+			match: true,
+		},
+		{
+			name: "Don't match MachinePool InfrastructureMachinePoolTemplate, class not listed",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:         "AzureMachinePoolTemplate",
+				TemplateType: api.MachinePoolInfrastructureMachinePoolTemplateType,
+				MachinePoolRef: api.MachinePoolRef{
+					Class: "classB",
+				},
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "AzureMachinePoolTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+						Names: []string{"classA"},
+					},
+				},
+			},
+			match: false,
+		},
+		{
+			name: "Don't match MachinePool BootstrapConfigTemplate with a MachineDeploymentClass selector",
+			templateRef: &api.TemplateRef{
+				APIVersion:   "bootstrap.cluster.x-k8s.io/v1beta1",
+				Kind:         "KubeadmConfigTemplate",
+				TemplateType: api.MachinePoolBootstrapConfigTemplateType,
+				MachinePoolRef: api.MachinePoolRef{
+					Class: "classA",
+				},
+			},
+			selector: clusterv1.PatchSelector{
+				APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+				Kind:       "KubeadmConfigTemplate",
+				MatchResources: clusterv1.PatchSelectorMatch{
+					MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+						Names: []string{"classA"},
+					},
+				},
+			},
+			match: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(templateMatchesSelector(tt.templateRef, tt.selector)).To(Equal(tt.match))
+		})
+	}
+}
+
+func TestCalculateValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		patch     clusterv1.JSONPatch
+		variables map[string]apiextensionsv1.JSON
+		want      *apiextensionsv1.JSON
+		wantErr   bool
+	}{
+		{
+			name:    "Fails if neither .value nor .valueFrom are set",
+			patch:   clusterv1.JSONPatch{},
+			wantErr: true,
+		},
+		{
+			name: "Fails if both .value and .valueFrom are set",
+			patch: clusterv1.JSONPatch{
+				Value: &apiextensionsv1.JSON{Raw: []byte(`"value"`)},
+				ValueFrom: &clusterv1.JSONPatchValue{
+					Variable: "variableA",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails if .valueFrom.variable does not exist",
+			patch: clusterv1.JSONPatch{
+				ValueFrom: &clusterv1.JSONPatchValue{
+					Variable: "variableA",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Should return .value if set",
+			patch: clusterv1.JSONPatch{
+				Value: &apiextensionsv1.JSON{Raw: []byte(`"value"`)},
+			},
+			want: &apiextensionsv1.JSON{Raw: []byte(`"value"`)},
+		},
+		{
+			name: "Should return .valueFrom.variable if set",
+			patch: clusterv1.JSONPatch{
+				ValueFrom: &clusterv1.JSONPatchValue{
+					Variable: "variableA",
+				},
+			},
+			variables: map[string]apiextensionsv1.JSON{
+				"variableA": {Raw: []byte(`"value"`)},
+			},
+			want: &apiextensionsv1.JSON{Raw: []byte(`"value"`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := calculateValue(tt.patch, tt.variables)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+// toJSONCompact is used to be able to write JSON values in a readable manner.
+func toJSONCompact(value string) apiextensionsv1.JSON {
+	var compactValue bytes.Buffer
+	if err := json.Compact(&compactValue, []byte(value)); err != nil {
+		panic(err)
+	}
+	return apiextensionsv1.JSON{Raw: compactValue.Bytes()}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patches
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/topology/internal/patches/api"
+)
+
+// templateBuilder builds templates.
+type templateBuilder struct {
+	template     *unstructured.Unstructured
+	templateType api.TemplateType
+	mdTopology   *clusterv1.MachineDeploymentTopology
+	mpTopology   *clusterv1.MachinePoolTopology
+}
+
+// newTemplateBuilder returns a new templateBuilder.
+func newTemplateBuilder(template *unstructured.Unstructured) *templateBuilder {
+	return &templateBuilder{
+		template: template,
+	}
+}
+
+// WithType adds templateType to the templateBuilder.
+func (t *templateBuilder) WithType(templateType api.TemplateType) *templateBuilder {
+	t.templateType = templateType
+	return t
+}
+
+// WithMachineDeploymentRef adds a MachineDeploymentTopology to the templateBuilder,
+// which is used to add a MachineDeploymentRef to the GenerateRequestTemplate.
+func (t *templateBuilder) WithMachineDeploymentRef(mdTopology *clusterv1.MachineDeploymentTopology) *templateBuilder {
+	t.mdTopology = mdTopology
+	return t
+}
+
+// WithMachinePoolRef adds a MachinePoolTopology to the templateBuilder,
+// which is used to add a MachinePoolRef to the GenerateRequestTemplate.
+func (t *templateBuilder) WithMachinePoolRef(mpTopology *clusterv1.MachinePoolTopology) *templateBuilder {
+	t.mpTopology = mpTopology
+	return t
+}
+
+// Build builds a new GenerateRequestTemplate.
+func (t *templateBuilder) Build() (*api.GenerateRequestTemplate, error) {
+	tpl := &api.GenerateRequestTemplate{}
+
+	tpl.TemplateRef.APIVersion = t.template.GetAPIVersion()
+	tpl.TemplateRef.Kind = t.template.GetKind()
+	tpl.TemplateRef.TemplateType = t.templateType
+
+	if t.mdTopology != nil {
+		tpl.TemplateRef.MachineDeploymentRef.TopologyName = t.mdTopology.Name
+		tpl.TemplateRef.MachineDeploymentRef.Class = t.mdTopology.Class
+	}
+
+	if t.mpTopology != nil {
+		tpl.TemplateRef.MachinePoolRef.TopologyName = t.mpTopology.Name
+		tpl.TemplateRef.MachinePoolRef.Class = t.mpTopology.Class
+	}
+
+	jsonObj, err := json.Marshal(t.template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal template to JSON")
+	}
+	tpl.Template = apiextensionsv1.JSON{Raw: jsonObj}
+
+	return tpl, nil
+}
+
+// getTemplateAsUnstructured is a utility func that returns a template matching the templateType, mdTopologyName
+// and mpTopologyName from a GenerateRequest.
+func getTemplateAsUnstructured(req *api.GenerateRequest, templateType api.TemplateType, mdRef *api.MachineDeploymentRef, mpRef *api.MachinePoolRef) (*unstructured.Unstructured, error) {
+	// Find the template the patch should be applied to.
+	template := getTemplate(req, templateType, mdRef, mpRef)
+
+	// If a patch doesn't apply to any template, this is a misconfiguration.
+	if template == nil {
+		return nil, errors.Errorf("failed to get template %q from request", templateType)
+	}
+
+	// Unmarshal the template.
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(template.Template.Raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal template %q", templateType)
+	}
+	return u, nil
+}
+
+// getTemplateByRef is a utility function to get a template from a GenerateRequest by TemplateRef.
+func getTemplateByRef(req *api.GenerateRequest, templateRef api.TemplateRef) *api.GenerateRequestTemplate {
+	for _, template := range req.Items {
+		if templateRefsAreEqual(templateRef, template.TemplateRef) {
+			return template
+		}
+	}
+	return nil
+}
+
+// getTemplate is a utility function to get a template from a GenerateRequest by templateType and
+// the topology name of the MachineDeployment or MachinePool, if given.
+func getTemplate(req *api.GenerateRequest, templateType api.TemplateType, mdRef *api.MachineDeploymentRef, mpRef *api.MachinePoolRef) *api.GenerateRequestTemplate {
+	for _, template := range req.Items {
+		if template.TemplateRef.TemplateType != templateType {
+			continue
+		}
+		if mdRef != nil && template.TemplateRef.MachineDeploymentRef.TopologyName != mdRef.TopologyName {
+			continue
+		}
+		if mpRef != nil && template.TemplateRef.MachinePoolRef.TopologyName != mpRef.TopologyName {
+			continue
+		}
+		return template
+	}
+	return nil
+}
+
+// templateRefsAreEqual returns true if the TemplateRefs are equal.
+func templateRefsAreEqual(a, b api.TemplateRef) bool {
+	return a.APIVersion == b.APIVersion &&
+		a.Kind == b.Kind &&
+		a.TemplateType == b.TemplateType &&
+		a.MachineDeploymentRef.TopologyName == b.MachineDeploymentRef.TopologyName &&
+		a.MachineDeploymentRef.Class == b.MachineDeploymentRef.Class &&
+		a.MachinePoolRef.TopologyName == b.MachinePoolRef.TopologyName &&
+		a.MachinePoolRef.Class == b.MachinePoolRef.Class
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/builder"
+)
+
+func TestGlobal(t *testing.T) {
+	tests := []struct {
+		name             string
+		clusterTopology  *clusterv1.Topology
+		cluster          *clusterv1.Cluster
+		clusterVariables []clusterv1.ClusterVariable
+		want             VariableMap
+	}{
+		{
+			name: "Should calculate global variables",
+			clusterTopology: &clusterv1.Topology{
+				Version: "v1.21.1",
+				Class:   "clusterClass1",
+			},
+			cluster: &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster1",
+					Namespace: metav1.NamespaceDefault,
+				},
+			},
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "location",
+					Value: toJSON("\"us-central\""),
+				},
+				{
+					Name:  "cpu",
+					Value: toJSON("8"),
+				},
+			},
+			want: VariableMap{
+				"location": toJSON("\"us-central\""),
+				"cpu":      toJSON("8"),
+				"builtin":  toJSON(`{"cluster":{"name":"cluster1","namespace":"default","topology":{"version":"v1.21.1","class":"clusterClass1"}}}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := Global(tt.clusterTopology, tt.cluster, tt.clusterVariables)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestControlPlane(t *testing.T) {
+	tests := []struct {
+		name                 string
+		controlPlaneTopology *clusterv1.ControlPlaneTopology
+		controlPlane         *builder.ControlPlaneBuilder
+		want                 VariableMap
+	}{
+		{
+			name: "Should calculate ControlPlane variables",
+			controlPlaneTopology: &clusterv1.ControlPlaneTopology{
+				Replicas: pointer.Int32(3),
+			},
+			controlPlane: builder.ControlPlane(metav1.NamespaceDefault, "controlPlane1").
+				WithSpecFields(map[string]interface{}{
+					"spec.replicas": int64(3),
+					"spec.version":  "v1.21.1",
+				}),
+			want: VariableMap{
+				"builtin": toJSON(`{"controlPlane":{"version":"v1.21.1","replicas":3}}`),
+			},
+		},
+		{
+			name:                 "Should calculate ControlPlane variables without replicas",
+			controlPlaneTopology: &clusterv1.ControlPlaneTopology{},
+			controlPlane: builder.ControlPlane(metav1.NamespaceDefault, "controlPlane1").
+				WithSpecFields(map[string]interface{}{
+					"spec.version": "v1.21.1",
+				}),
+			want: VariableMap{
+				"builtin": toJSON(`{"controlPlane":{"version":"v1.21.1"}}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := ControlPlane(tt.controlPlaneTopology, tt.controlPlane.Build())
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachineDeployment(t *testing.T) {
+	tests := []struct {
+		name       string
+		mdTopology *clusterv1.MachineDeploymentTopology
+		md         *clusterv1.MachineDeployment
+		want       VariableMap
+	}{
+		{
+			name: "Should calculate MachineDeployment variables",
+			mdTopology: &clusterv1.MachineDeploymentTopology{
+				Replicas: pointer.Int32(3),
+				Name:     "md-topology",
+				Class:    "md-class",
+			},
+			md: builder.MachineDeployment(metav1.NamespaceDefault, "md1").
+				WithReplicas(3).
+				WithVersion("v1.21.1").
+				Build(),
+			want: VariableMap{
+				"builtin": toJSON(`{"machineDeployment":{"version":"v1.21.1","class":"md-class","name":"md1","topologyName":"md-topology","replicas":3}}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := MachineDeployment(tt.mdTopology, tt.md)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachinePool(t *testing.T) {
+	tests := []struct {
+		name       string
+		mpTopology *clusterv1.MachinePoolTopology
+		mp         *expv1.MachinePool
+		want       VariableMap
+	}{
+		{
+			name: "Should calculate MachinePool variables",
+			mpTopology: &clusterv1.MachinePoolTopology{
+				Replicas: pointer.Int32(3),
+				Name:     "mp-topology",
+				Class:    "mp-class",
+			},
+			mp: builder.MachinePool(metav1.NamespaceDefault, "mp1").
+				WithReplicas(3).
+				WithVersion("v1.21.1").
+				Build(),
+			want: VariableMap{
+				"builtin": toJSON(`{"machinePool":{"version":"v1.21.1","class":"mp-class","name":"mp1","topologyName":"mp-topology","replicas":3}}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := MachinePool(tt.mpTopology, tt.mp)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMergeVariableMaps(t *testing.T) {
+	g := NewWithT(t)
+
+	m1, err := MergeVariableMaps(
+		VariableMap{
+			"a":       toJSON("a-different"),
+			"c":       toJSON("c"),
+			"builtin": toJSON(`{"cluster":{"name":"cluster-name"}}`),
+		},
+		VariableMap{
+			"a":       toJSON("a"),
+			"b":       toJSON("b"),
+			"builtin": toJSON(`{"machineDeployment":{"name":"md-name"}}`),
+		},
+	)
+	g.Expect(err).To(BeNil())
+
+	g.Expect(m1).To(HaveKeyWithValue("a", toJSON("a")))
+	g.Expect(m1).To(HaveKeyWithValue("b", toJSON("b")))
+	g.Expect(m1).To(HaveKeyWithValue("c", toJSON("c")))
+	g.Expect(m1).To(HaveKeyWithValue("builtin", toJSON(`{"cluster":{"name":"cluster-name"},"machineDeployment":{"name":"md-name"}}`)))
+}
+
+func TestGetVariableValue(t *testing.T) {
+	variables := VariableMap{
+		"location": toJSON("\"us-central\""),
+		"builtin":  toJSON(`{"cluster":{"name":"cluster1","topology":{"version":"v1.21.1"}}}`),
+	}
+
+	tests := []struct {
+		name         string
+		variablePath string
+		want         *apiextensionsv1.JSON
+		wantErr      bool
+	}{
+		{
+			name:         "Should return a top-level variable",
+			variablePath: "location",
+			want:         toJSONPtr("\"us-central\""),
+		},
+		{
+			name:         "Should return a nested builtin variable",
+			variablePath: "builtin.cluster.name",
+			want:         toJSONPtr("\"cluster1\""),
+		},
+		{
+			name:         "Should return a nested object",
+			variablePath: "builtin.cluster.topology",
+			want:         toJSONPtr(`{"version":"v1.21.1"}`),
+		},
+		{
+			name:         "Fails for a variable that does not exist",
+			variablePath: "notExisting",
+			wantErr:      true,
+		},
+		{
+			name:         "Fails for a nested field that does not exist",
+			variablePath: "builtin.cluster.notExisting",
+			wantErr:      true,
+		},
+		{
+			name:         "Fails when walking into a scalar value",
+			variablePath: "location.region",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := GetVariableValue(variables, tt.variablePath)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func toJSON(value string) apiextensionsv1.JSON {
+	return apiextensionsv1.JSON{Raw: []byte(value)}
+}
+
+func toJSONPtr(value string) *apiextensionsv1.JSON {
+	return &apiextensionsv1.JSON{Raw: []byte(value)}
+}
@@ -38,6 +38,9 @@ type ClusterBlueprint struct {
 
 	// MachineDeployments holds the MachineDeploymentBlueprints derived from ClusterClass.
 	MachineDeployments map[string]*MachineDeploymentBlueprint
+
+	// MachinePools holds the MachinePoolBlueprints derived from ClusterClass.
+	MachinePools map[string]*MachinePoolBlueprint
 }
 
 // ControlPlaneBlueprint holds the templates required for computing the desired state of a managed control plane.
@@ -47,6 +50,9 @@ type ControlPlaneBlueprint struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template for the control plane, if defined in the ClusterClass.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheckClass for the control plane, if defined in the ClusterClass.
+	MachineHealthCheck *clusterv1.MachineHealthCheckClass
 }
 
 // MachineDeploymentBlueprint holds the templates required for computing the desired state of a managed MachineDeployment;
@@ -62,6 +68,24 @@ type MachineDeploymentBlueprint struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template for a MachineDeployment referenced from ClusterClass.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheckClass for a MachineDeployment, if defined in the ClusterClass.
+	MachineHealthCheck *clusterv1.MachineHealthCheckClass
+}
+
+// MachinePoolBlueprint holds the templates required for computing the desired state of a managed MachinePool;
+// it also holds a copy of the MachinePool metadata from the ClusterClass, thus providing all the required info
+// in a single place.
+type MachinePoolBlueprint struct {
+	// Metadata holds the metadata for a MachinePool.
+	// NOTE: This is a convenience copy of the metadata field from ClusterClass.Spec.Workers.MachinePools[x].
+	Metadata clusterv1.ObjectMeta
+
+	// BootstrapTemplate holds the bootstrap template for a MachinePool referenced from ClusterClass.
+	BootstrapTemplate *unstructured.Unstructured
+
+	// InfrastructureMachinePoolTemplate holds the infrastructure machine pool template for a MachinePool referenced from ClusterClass.
+	InfrastructureMachinePoolTemplate *unstructured.Unstructured
 }
 
 // HasControlPlaneInfrastructureMachine checks whether the clusterClass mandates the controlPlane has infrastructureMachines.
@@ -69,7 +93,24 @@ func (b *ClusterBlueprint) HasControlPlaneInfrastructureMachine() bool {
 	return b.ClusterClass.Spec.ControlPlane.MachineInfrastructure != nil && b.ClusterClass.Spec.ControlPlane.MachineInfrastructure.Ref != nil
 }
 
+// HasControlPlaneMachineHealthCheck checks whether the clusterClass defines a MachineHealthCheck for the control plane.
+func (b *ClusterBlueprint) HasControlPlaneMachineHealthCheck() bool {
+	return b.ControlPlane != nil && b.ControlPlane.MachineHealthCheck != nil
+}
+
+// HasMachineDeploymentMachineHealthCheck checks whether the clusterClass defines a MachineHealthCheck
+// for the given MachineDeployment class.
+func (b *ClusterBlueprint) HasMachineDeploymentMachineHealthCheck(class string) bool {
+	md, ok := b.MachineDeployments[class]
+	return ok && md.MachineHealthCheck != nil
+}
+
 // HasMachineDeployments checks whether the topology has MachineDeployments.
 func (b *ClusterBlueprint) HasMachineDeployments() bool {
 	return b.Topology.Workers != nil && len(b.Topology.Workers.MachineDeployments) > 0
 }
+
+// HasMachinePools checks whether the topology has MachinePools.
+func (b *ClusterBlueprint) HasMachinePools() bool {
+	return b.Topology.Workers != nil && len(b.Topology.Workers.MachinePools) > 0
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+)
+
+// HookResponseTracker is a helper to capture the responses of the lifecycle hooks called
+// while reconciling a Cluster topology.
+type HookResponseTracker struct {
+	responses map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+}
+
+// NewHookResponseTracker returns a HookResponseTracker without responses.
+func NewHookResponseTracker() *HookResponseTracker {
+	return &HookResponseTracker{
+		responses: map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse{},
+	}
+}
+
+// Add records the response of a hook.
+func (h *HookResponseTracker) Add(hook runtimehooksv1.Hook, response *runtimehooksv1.CommonRetryResponse) {
+	h.responses[hook] = response
+}
+
+// IsBlocking returns true if the response recorded for the hook is blocking.
+func (h *HookResponseTracker) IsBlocking(hook runtimehooksv1.Hook) bool {
+	response, ok := h.responses[hook]
+	return ok && response.RetryAfterSeconds > 0
+}
+
+// AggregateRetryAfter returns the lowest retry of the blocking hooks, or zero if no hook is blocking.
+func (h *HookResponseTracker) AggregateRetryAfter() time.Duration {
+	var retryAfterSeconds int32
+	for _, response := range h.responses {
+		if response.RetryAfterSeconds > 0 && (retryAfterSeconds == 0 || response.RetryAfterSeconds < retryAfterSeconds) {
+			retryAfterSeconds = response.RetryAfterSeconds
+		}
+	}
+	return time.Duration(retryAfterSeconds) * time.Second
+}
+
+// AggregateMessage returns a human-readable message listing the blocking hooks.
+func (h *HookResponseTracker) AggregateMessage() string {
+	var messages []string
+	for hook, response := range h.responses {
+		if response.RetryAfterSeconds == 0 {
+			continue
+		}
+		if response.Message != "" {
+			messages = append(messages, fmt.Sprintf("hook %q is blocking: %s", hook, response.Message))
+		} else {
+			messages = append(messages, fmt.Sprintf("hook %q is blocking", hook))
+		}
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
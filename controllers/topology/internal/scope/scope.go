@@ -33,6 +33,9 @@ type Scope struct {
 
 	// UpgradeTracker holds information about ongoing upgrades in the managed topology.
 	UpgradeTracker *UpgradeTracker
+
+	// HookResponseTracker holds the responses of the lifecycle hooks called while reconciling the managed topology.
+	HookResponseTracker *HookResponseTracker
 }
 
 // New returns a new Scope with only the cluster; while processing a request in the topology/ClusterReconciler controller
@@ -43,6 +46,20 @@ func New(cluster *clusterv1.Cluster) *Scope {
 		Current: &ClusterState{
 			Cluster: cluster,
 		},
-		UpgradeTracker: NewUpgradeTracker(),
+		UpgradeTracker:      NewUpgradeTracker(upgradeTrackerOptions(cluster)...),
+		HookResponseTracker: NewHookResponseTracker(),
+	}
+}
+
+// upgradeTrackerOptions returns the UpgradeTracker options for the upgrade strategy defined in the Cluster topology.
+func upgradeTrackerOptions(cluster *clusterv1.Cluster) []UpgradeTrackerOption {
+	if cluster == nil || cluster.Spec.Topology == nil || cluster.Spec.Topology.UpgradeStrategy == nil {
+		return nil
+	}
+
+	var opts []UpgradeTrackerOption
+	if mdStrategy := cluster.Spec.Topology.UpgradeStrategy.MachineDeployments; mdStrategy != nil && mdStrategy.MaxConcurrency != nil {
+		opts = append(opts, MaxMachineDeploymentUpgradeConcurrency(int(*mdStrategy.MaxConcurrency)))
 	}
+	return opts
 }
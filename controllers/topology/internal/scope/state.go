@@ -20,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/internal/mdutil"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 )
 
 // ClusterState holds all the objects representing the state of a managed Cluster topology.
@@ -37,6 +38,9 @@ type ClusterState struct {
 
 	// MachineDeployments holds the machine deployments in the Cluster.
 	MachineDeployments MachineDeploymentsStateMap
+
+	// MachinePools holds the machine pools in the Cluster.
+	MachinePools MachinePoolsStateMap
 }
 
 // ControlPlaneState holds all the objects representing the state of a managed control plane.
@@ -46,6 +50,9 @@ type ControlPlaneState struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure template referenced by the ControlPlane object.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheck for the ControlPlane machines.
+	MachineHealthCheck *clusterv1.MachineHealthCheck
 }
 
 // MachineDeploymentsStateMap holds a collection of MachineDeployment states.
@@ -62,6 +69,17 @@ func (mds MachineDeploymentsStateMap) IsAnyRollingOut() bool {
 	return false
 }
 
+// RollingOut returns the names of the machine deployments which are rolling out.
+func (mds MachineDeploymentsStateMap) RollingOut() []string {
+	names := []string{}
+	for _, md := range mds {
+		if md.IsRollingOut() {
+			names = append(names, md.Object.Name)
+		}
+	}
+	return names
+}
+
 // MachineDeploymentState holds all the objects representing the state of a managed deployment.
 type MachineDeploymentState struct {
 	// Object holds the MachineDeployment object.
@@ -72,6 +90,9 @@ type MachineDeploymentState struct {
 
 	// InfrastructureMachineTemplate holds the infrastructure machine template referenced by the MachineDeployment object.
 	InfrastructureMachineTemplate *unstructured.Unstructured
+
+	// MachineHealthCheck holds the MachineHealthCheck for the MachineDeployment machines.
+	MachineHealthCheck *clusterv1.MachineHealthCheck
 }
 
 // IsRollingOut determines if the machine deployment is upgrading.
@@ -80,3 +101,43 @@ type MachineDeploymentState struct {
 func (md *MachineDeploymentState) IsRollingOut() bool {
 	return !mdutil.DeploymentComplete(md.Object, &md.Object.Status) || *md.Object.Spec.Replicas != md.Object.Status.ReadyReplicas
 }
+
+// MachinePoolsStateMap holds a collection of MachinePool states.
+type MachinePoolsStateMap map[string]*MachinePoolState
+
+// IsAnyRollingOut returns true if at least one of the machine pools
+// is upgrading.
+func (mps MachinePoolsStateMap) IsAnyRollingOut() bool {
+	for _, mp := range mps {
+		if mp.IsRollingOut() {
+			return true
+		}
+	}
+	return false
+}
+
+// MachinePoolState holds all the objects representing the state of a managed pool.
+type MachinePoolState struct {
+	// Object holds the MachinePool object.
+	Object *expv1.MachinePool
+
+	// BootstrapObject holds the bootstrap config referenced by the MachinePool object.
+	BootstrapObject *unstructured.Unstructured
+
+	// InfrastructureMachinePoolObject holds the infrastructure machine pool referenced by the MachinePool object.
+	InfrastructureMachinePoolObject *unstructured.Unstructured
+}
+
+// IsRollingOut determines if the machine pool is upgrading.
+// A machine pool is considered upgrading if:
+// - the latest spec has not been observed yet.
+// - if any of the replicas of the machine pool is not ready or unavailable.
+func (mp *MachinePoolState) IsRollingOut() bool {
+	replicas := int32(1)
+	if mp.Object.Spec.Replicas != nil {
+		replicas = *mp.Object.Spec.Replicas
+	}
+	return mp.Object.Status.ObservedGeneration < mp.Object.Generation ||
+		replicas != mp.Object.Status.ReadyReplicas ||
+		mp.Object.Status.UnavailableReplicas > 0
+}
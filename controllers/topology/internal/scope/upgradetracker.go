@@ -18,26 +18,60 @@ package scope
 
 import "k8s.io/apimachinery/pkg/util/sets"
 
-const maxMachineDeploymentUpgradeConcurrency = 1
+const (
+	defaultMaxMachineDeploymentUpgradeConcurrency = 1
+	maxMachinePoolUpgradeConcurrency              = 1
+)
 
 // UpgradeTracker is a helper to capture the upgrade status and make upgrade decisions.
 type UpgradeTracker struct {
 	MachineDeployments MachineDeploymentUpgradeTracker
+	MachinePools       MachinePoolUpgradeTracker
 }
 
 // MachineDeploymentUpgradeTracker holds the current upgrade status and makes upgrade
 // decisions for MachineDeployments.
 type MachineDeploymentUpgradeTracker struct {
-	names sets.String
+	names          sets.String
+	rollingOut     sets.String
+	holdUpgrades   bool
+	maxConcurrency int
+}
+
+// MachinePoolUpgradeTracker holds the current upgrade status and makes upgrade
+// decisions for MachinePools.
+type MachinePoolUpgradeTracker struct {
+	names        sets.String
+	holdUpgrades bool
+}
+
+// UpgradeTrackerOption is an option for the UpgradeTracker.
+type UpgradeTrackerOption func(*UpgradeTracker)
+
+// MaxMachineDeploymentUpgradeConcurrency sets the maximum number of MachineDeployments
+// that can be upgraded at the same time.
+func MaxMachineDeploymentUpgradeConcurrency(n int) UpgradeTrackerOption {
+	return func(u *UpgradeTracker) {
+		u.MachineDeployments.maxConcurrency = n
+	}
 }
 
 // NewUpgradeTracker returns an upgrade tracker with empty tracking information.
-func NewUpgradeTracker() *UpgradeTracker {
-	return &UpgradeTracker{
+func NewUpgradeTracker(opts ...UpgradeTrackerOption) *UpgradeTracker {
+	u := &UpgradeTracker{
 		MachineDeployments: MachineDeploymentUpgradeTracker{
+			names:          sets.NewString(),
+			rollingOut:     sets.NewString(),
+			maxConcurrency: defaultMaxMachineDeploymentUpgradeConcurrency,
+		},
+		MachinePools: MachinePoolUpgradeTracker{
 			names: sets.NewString(),
 		},
 	}
+	for _, o := range opts {
+		o(u)
+	}
+	return u
 }
 
 // Insert adds name to the set of MachineDeployments that will be upgraded.
@@ -45,8 +79,41 @@ func (m *MachineDeploymentUpgradeTracker) Insert(name string) {
 	m.names.Insert(name)
 }
 
+// MarkRollingOut adds names to the set of MachineDeployments which are already rolling out;
+// those MachineDeployments count towards the maximum number of concurrent upgrades.
+func (m *MachineDeploymentUpgradeTracker) MarkRollingOut(names ...string) {
+	m.rollingOut.Insert(names...)
+}
+
+// HoldUpgrades is used to prevent MachineDeployments from upgrading, e.g. while a lifecycle hook is blocking.
+func (m *MachineDeploymentUpgradeTracker) HoldUpgrades(val bool) {
+	m.holdUpgrades = val
+}
+
 // AllowUpgrade returns true if a MachineDeployment is allowed to upgrade,
 // returns false otherwise.
 func (m *MachineDeploymentUpgradeTracker) AllowUpgrade() bool {
-	return m.names.Len() < maxMachineDeploymentUpgradeConcurrency
+	if m.holdUpgrades {
+		return false
+	}
+	return m.names.Union(m.rollingOut).Len() < m.maxConcurrency
+}
+
+// Insert adds name to the set of MachinePools that will be upgraded.
+func (m *MachinePoolUpgradeTracker) Insert(name string) {
+	m.names.Insert(name)
+}
+
+// HoldUpgrades is used to prevent MachinePools from upgrading, e.g. while a lifecycle hook is blocking.
+func (m *MachinePoolUpgradeTracker) HoldUpgrades(val bool) {
+	m.holdUpgrades = val
+}
+
+// AllowUpgrade returns true if a MachinePool is allowed to upgrade,
+// returns false otherwise.
+func (m *MachinePoolUpgradeTracker) AllowUpgrade() bool {
+	if m.holdUpgrades {
+		return false
+	}
+	return m.names.Len() < maxMachinePoolUpgradeConcurrency
 }
@@ -26,11 +26,12 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/storage/names"
-	"sigs.k8s.io/cluster-api/controllers/topology/internal/check"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
 	tlog "sigs.k8s.io/cluster-api/controllers/topology/internal/log"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/mergepatch"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	"sigs.k8s.io/cluster-api/internal/topology/check"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -58,7 +59,12 @@ func (r *ClusterReconciler) reconcileState(ctx context.Context, s *scope.Scope)
 	}
 
 	// Reconcile desired state of the MachineDeployment objects.
-	return r.reconcileMachineDeployments(ctx, s)
+	if err := r.reconcileMachineDeployments(ctx, s); err != nil {
+		return err
+	}
+
+	// Reconcile desired state of the MachinePool objects.
+	return r.reconcileMachinePools(ctx, s)
 }
 
 // reconcileInfrastructureCluster reconciles the desired state of the InfrastructureCluster object.
@@ -114,6 +120,14 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, s *scope.
 		})
 	}
 
+	// Create, update or delete the MachineHealthCheck for the control plane machines, if required.
+	if err := r.reconcileMachineHealthCheck(ctx, s.Current.ControlPlane.MachineHealthCheck, s.Desired.ControlPlane.MachineHealthCheck); err != nil {
+		return kerrors.NewAggregate([]error{
+			err,
+			cleanup(),
+		})
+	}
+
 	// At this point we've updated the ControlPlane object and, where required, the ControlPlane InfrastructureMachineTemplate
 	// without error. Run the cleanup in order to delete the old InfrastructureMachineTemplate if template rotation was done during update.
 	return cleanup()
@@ -198,7 +212,10 @@ func (r *ClusterReconciler) createMachineDeployment(ctx context.Context, md *sco
 	if err := r.Client.Create(ctx, md.Object.DeepCopy()); err != nil {
 		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: md.Object})
 	}
-	return nil
+
+	// Create the MachineHealthCheck for the MachineDeployment, if required.
+	ctx, _ = log.Into(ctx)
+	return r.reconcileMachineHealthCheck(ctx, nil, md.MachineHealthCheck)
 }
 
 // updateMachineDeployment updates a MachineDeployment. Also rotates the corresponding Templates if necessary.
@@ -227,6 +244,12 @@ func (r *ClusterReconciler) updateMachineDeployment(ctx context.Context, cluster
 		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMD.Object})
 	}
 
+	// Create, update or delete the MachineHealthCheck for the MachineDeployment, if required.
+	ctx, _ = log.Into(ctx)
+	if err := r.reconcileMachineHealthCheck(ctx, currentMD.MachineHealthCheck, desiredMD.MachineHealthCheck); err != nil {
+		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMD.Object})
+	}
+
 	// Check differences between current and desired MachineDeployment, and eventually patch the current object.
 	log = log.WithObject(desiredMD.Object)
 	patchHelper, err := mergepatch.NewHelper(currentMD.Object, desiredMD.Object, r.Client)
@@ -249,8 +272,15 @@ func (r *ClusterReconciler) updateMachineDeployment(ctx context.Context, cluster
 
 // deleteMachineDeployment deletes a MachineDeployment.
 func (r *ClusterReconciler) deleteMachineDeployment(ctx context.Context, md *scope.MachineDeploymentState) error {
-	log := tlog.LoggerFrom(ctx).WithMachineDeployment(md.Object).WithObject(md.Object)
+	log := tlog.LoggerFrom(ctx).WithMachineDeployment(md.Object)
 
+	// Delete the MachineHealthCheck first, so it does not try to remediate Machines being deleted.
+	ctx, _ = log.Into(ctx)
+	if err := r.reconcileMachineHealthCheck(ctx, md.MachineHealthCheck, nil); err != nil {
+		return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: md.Object})
+	}
+
+	log = log.WithObject(md.Object)
 	log.Infof("Deleting %s", tlog.KObj{Obj: md.Object})
 	if err := r.Client.Delete(ctx, md.Object); err != nil && !apierrors.IsNotFound(err) {
 		return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: md.Object})
@@ -258,6 +288,54 @@ func (r *ClusterReconciler) deleteMachineDeployment(ctx context.Context, md *sco
 	return nil
 }
 
+// reconcileMachineHealthCheck creates, patches or deletes a MachineHealthCheck depending on the current and desired state.
+// NOTE: The MachineHealthChecks generated by the topology controller are expected to be changed only via
+// the corresponding ClusterClass.
+func (r *ClusterReconciler) reconcileMachineHealthCheck(ctx context.Context, current, desired *clusterv1.MachineHealthCheck) error {
+	log := tlog.LoggerFrom(ctx)
+
+	// If there is neither a current nor a desired MachineHealthCheck, there is nothing to do.
+	if current == nil && desired == nil {
+		return nil
+	}
+
+	// If there is no current MachineHealthCheck, create the desired one.
+	if current == nil {
+		log = log.WithObject(desired)
+		log.Infof("Creating %s", tlog.KObj{Obj: desired})
+		if err := r.Client.Create(ctx, desired.DeepCopy()); err != nil {
+			return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: desired})
+		}
+		return nil
+	}
+
+	// If there is no desired MachineHealthCheck, delete the current one.
+	log = log.WithObject(current)
+	if desired == nil {
+		log.Infof("Deleting %s", tlog.KObj{Obj: current})
+		if err := r.Client.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: current})
+		}
+		return nil
+	}
+
+	// Check differences between current and desired MachineHealthCheck, and eventually patch the current object.
+	patchHelper, err := mergepatch.NewHelper(current, desired, r.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: current})
+	}
+	if !patchHelper.HasChanges() {
+		log.V(3).Infof("No changes for %s", tlog.KObj{Obj: current})
+		return nil
+	}
+
+	log.Infof("Patching %s", tlog.KObj{Obj: current})
+	if err := patchHelper.Patch(ctx); err != nil {
+		return errors.Wrapf(err, "failed to patch %s", tlog.KObj{Obj: current})
+	}
+	return nil
+}
+
 type machineDeploymentDiff struct {
 	toCreate, toUpdate, toDelete []string
 }
@@ -284,6 +362,162 @@ func calculateMachineDeploymentDiff(current, desired map[string]*scope.MachineDe
 	return diff
 }
 
+// reconcileMachinePools reconciles the desired state of the MachinePool objects.
+func (r *ClusterReconciler) reconcileMachinePools(ctx context.Context, s *scope.Scope) error {
+	diff := calculateMachinePoolDiff(s.Current.MachinePools, s.Desired.MachinePools)
+
+	// Create MachinePools.
+	for _, mpTopologyName := range diff.toCreate {
+		mp := s.Desired.MachinePools[mpTopologyName]
+		if err := r.createMachinePool(ctx, mp); err != nil {
+			return err
+		}
+	}
+
+	// Update MachinePools.
+	for _, mpTopologyName := range diff.toUpdate {
+		currentMP := s.Current.MachinePools[mpTopologyName]
+		desiredMP := s.Desired.MachinePools[mpTopologyName]
+		if err := r.updateMachinePool(ctx, s.Current.Cluster.Name, mpTopologyName, currentMP, desiredMP); err != nil {
+			return err
+		}
+	}
+
+	// Delete MachinePools.
+	for _, mpTopologyName := range diff.toDelete {
+		mp := s.Current.MachinePools[mpTopologyName]
+		if err := r.deleteMachinePool(ctx, mp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createMachinePool creates a MachinePool and the corresponding bootstrap config and infrastructure machine pool.
+func (r *ClusterReconciler) createMachinePool(ctx context.Context, mp *scope.MachinePoolState) error {
+	log := tlog.LoggerFrom(ctx).WithMachinePool(mp.Object)
+
+	ctx, _ = log.WithObject(mp.InfrastructureMachinePoolObject).Into(ctx)
+	if err := r.reconcileReferencedObject(ctx, nil, mp.InfrastructureMachinePoolObject); err != nil {
+		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: mp.Object})
+	}
+
+	ctx, _ = log.WithObject(mp.BootstrapObject).Into(ctx)
+	if err := r.reconcileReferencedObject(ctx, nil, mp.BootstrapObject); err != nil {
+		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: mp.Object})
+	}
+
+	log = log.WithObject(mp.Object)
+	log.Infof(fmt.Sprintf("Creating %s", tlog.KObj{Obj: mp.Object}))
+	if err := r.Client.Create(ctx, mp.Object.DeepCopy()); err != nil {
+		return errors.Wrapf(err, "failed to create %s", tlog.KObj{Obj: mp.Object})
+	}
+	return nil
+}
+
+// updateMachinePool updates a MachinePool. Also updates the corresponding infrastructure machine pool and
+// rotates the bootstrap config if necessary.
+// NOTE: The infrastructure machine pool is updated in place, because the infrastructure provider is responsible
+// for rolling out changes to the machines of the pool; the bootstrap config instead is rotated, so the
+// MachinePool picks up the bootstrap data generated from the new config.
+func (r *ClusterReconciler) updateMachinePool(ctx context.Context, clusterName string, mpTopologyName string, currentMP, desiredMP *scope.MachinePoolState) error {
+	log := tlog.LoggerFrom(ctx).WithMachinePool(desiredMP.Object)
+
+	ctx, _ = log.WithObject(desiredMP.InfrastructureMachinePoolObject).Into(ctx)
+	if err := r.reconcileReferencedObject(ctx, currentMP.InfrastructureMachinePoolObject, desiredMP.InfrastructureMachinePoolObject); err != nil {
+		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMP.Object})
+	}
+
+	ctx, _ = log.WithObject(desiredMP.BootstrapObject).Into(ctx)
+	cleanup, err := r.reconcileReferencedTemplate(ctx, reconcileReferencedTemplateInput{
+		ref:                  desiredMP.Object.Spec.Template.Spec.Bootstrap.ConfigRef,
+		current:              currentMP.BootstrapObject,
+		desired:              desiredMP.BootstrapObject,
+		templateNamePrefix:   bootstrapConfigNamePrefix(clusterName, mpTopologyName),
+		compatibilityChecker: check.ObjectsAreInTheSameNamespace,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s", tlog.KObj{Obj: currentMP.Object})
+	}
+	bootstrapRotated := desiredMP.Object.Spec.Template.Spec.Bootstrap.ConfigRef.Name != currentMP.Object.Spec.Template.Spec.Bootstrap.ConfigRef.Name
+
+	// Check differences between current and desired MachinePool, and eventually patch the current object.
+	log = log.WithObject(desiredMP.Object)
+	patchHelper, err := mergepatch.NewHelper(currentMP.Object, desiredMP.Object, r.Client)
+	if err != nil {
+		return kerrors.NewAggregate([]error{
+			errors.Wrapf(err, "failed to create patch helper for %s", tlog.KObj{Obj: currentMP.Object}),
+			cleanup(),
+		})
+	}
+	if !patchHelper.HasChanges() {
+		log.V(3).Infof("No changes for %s", tlog.KObj{Obj: currentMP.Object})
+		return cleanup()
+	}
+
+	log.Infof("Patching %s", tlog.KObj{Obj: currentMP.Object})
+	if err := patchHelper.Patch(ctx); err != nil {
+		return kerrors.NewAggregate([]error{
+			errors.Wrapf(err, "failed to patch %s", tlog.KObj{Obj: currentMP.Object}),
+			cleanup(),
+		})
+	}
+
+	// If the bootstrap config has been rotated, drop the data secret name generated from the previous
+	// bootstrap config, so the MachinePool controller picks up the one generated from the new bootstrap config.
+	// NOTE: This can't be done with the patch above, because fields not defined in desired are always preserved.
+	if bootstrapRotated && currentMP.Object.Spec.Template.Spec.Bootstrap.DataSecretName != nil {
+		original := currentMP.Object.DeepCopy()
+		currentMP.Object.Spec.Template.Spec.Bootstrap.DataSecretName = nil
+		if err := r.Client.Patch(ctx, currentMP.Object, client.MergeFrom(original)); err != nil {
+			return errors.Wrapf(err, "failed to remove the bootstrap data secret name from %s", tlog.KObj{Obj: currentMP.Object})
+		}
+	}
+
+	// At this point the MachinePool references the new bootstrap config, if rotated;
+	// run the cleanup in order to delete the old bootstrap config.
+	return cleanup()
+}
+
+// deleteMachinePool deletes a MachinePool.
+// NOTE: The bootstrap config and the infrastructure machine pool are deleted by the MachinePool controller.
+func (r *ClusterReconciler) deleteMachinePool(ctx context.Context, mp *scope.MachinePoolState) error {
+	log := tlog.LoggerFrom(ctx).WithMachinePool(mp.Object).WithObject(mp.Object)
+
+	log.Infof("Deleting %s", tlog.KObj{Obj: mp.Object})
+	if err := r.Client.Delete(ctx, mp.Object); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete %s", tlog.KObj{Obj: mp.Object})
+	}
+	return nil
+}
+
+type machinePoolDiff struct {
+	toCreate, toUpdate, toDelete []string
+}
+
+// calculateMachinePoolDiff compares two maps of MachinePoolState and calculates which
+// MachinePools should be created, updated or deleted.
+func calculateMachinePoolDiff(current, desired map[string]*scope.MachinePoolState) machinePoolDiff {
+	var diff machinePoolDiff
+
+	for mp := range desired {
+		if _, ok := current[mp]; ok {
+			diff.toUpdate = append(diff.toUpdate, mp)
+		} else {
+			diff.toCreate = append(diff.toCreate, mp)
+		}
+	}
+
+	for mp := range current {
+		if _, ok := desired[mp]; !ok {
+			diff.toDelete = append(diff.toDelete, mp)
+		}
+	}
+
+	return diff
+}
+
 // reconcileReferencedObject reconciles the desired state of the referenced object.
 // NOTE: After a referenced object is created it is assumed that the reference should
 // never change (only the content of the object can eventually change). Thus, we are checking for strict compatibility.
@@ -20,13 +20,19 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/contract"
 	"sigs.k8s.io/cluster-api/controllers/topology/internal/scope"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/internal/builder"
 	. "sigs.k8s.io/cluster-api/internal/matchers"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -825,3 +831,356 @@ func toMachineDeploymentTopologyStateMap(states []*scope.MachineDeploymentState)
 	}
 	return ret
 }
+
+func TestReconcileMachinePools(t *testing.T) {
+	infrastructureMachinePool1 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-1").Build()
+	bootstrapConfig1 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-1").Build()
+	mp1 := newFakeMachinePoolTopologyState("mp-1", infrastructureMachinePool1, bootstrapConfig1)
+
+	infrastructureMachinePool2 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-2").Build()
+	bootstrapConfig2 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-2").Build()
+	mp2 := newFakeMachinePoolTopologyState("mp-2", infrastructureMachinePool2, bootstrapConfig2)
+	infrastructureMachinePool2WithChanges := infrastructureMachinePool2.DeepCopy()
+	infrastructureMachinePool2WithChanges.SetLabels(map[string]string{"foo": "bar"})
+	mp2WithChangedInfrastructureMachinePool := newFakeMachinePoolTopologyState("mp-2", infrastructureMachinePool2WithChanges, bootstrapConfig2)
+
+	infrastructureMachinePool3 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-3").Build()
+	bootstrapConfig3 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-3").Build()
+	mp3 := newFakeMachinePoolTopologyState("mp-3", infrastructureMachinePool3, bootstrapConfig3)
+	mp3.Object.Spec.Template.Spec.Bootstrap.DataSecretName = pointer.String("bootstrap-data-3")
+	bootstrapConfig3WithChanges := bootstrapConfig3.DeepCopy()
+	bootstrapConfig3WithChanges.SetLabels(map[string]string{"foo": "bar"})
+	mp3WithRotatedBootstrapConfig := newFakeMachinePoolTopologyState("mp-3", infrastructureMachinePool3, bootstrapConfig3WithChanges)
+
+	infrastructureMachinePool4 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-4").Build()
+	bootstrapConfig4 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-4").Build()
+	mp4 := newFakeMachinePoolTopologyState("mp-4", infrastructureMachinePool4, bootstrapConfig4)
+	infrastructureMachinePool4WithChangedKind := infrastructureMachinePool4.DeepCopy()
+	infrastructureMachinePool4WithChangedKind.SetKind("ChangedKind")
+	mp4WithChangedInfrastructureMachinePoolKind := newFakeMachinePoolTopologyState("mp-4", infrastructureMachinePool4WithChangedKind, bootstrapConfig4)
+
+	infrastructureMachinePool5 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-5").Build()
+	bootstrapConfig5 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-5").Build()
+	mp5 := newFakeMachinePoolTopologyState("mp-5", infrastructureMachinePool5, bootstrapConfig5)
+	bootstrapConfig5WithChangedNamespace := bootstrapConfig5.DeepCopy()
+	bootstrapConfig5WithChangedNamespace.SetNamespace("ChangedNamespace")
+	mp5WithChangedBootstrapConfigNamespace := newFakeMachinePoolTopologyState("mp-5", infrastructureMachinePool5, bootstrapConfig5WithChangedNamespace)
+
+	infrastructureMachinePool6 := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-6").Build()
+	bootstrapConfig6 := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-6").Build()
+	mp6 := newFakeMachinePoolTopologyState("mp-6", infrastructureMachinePool6, bootstrapConfig6)
+
+	infrastructureMachinePool7Create := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-7-create").Build()
+	bootstrapConfig7Create := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-7-create").Build()
+	mp7Create := newFakeMachinePoolTopologyState("mp-7-create", infrastructureMachinePool7Create, bootstrapConfig7Create)
+	infrastructureMachinePool7Delete := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-7-delete").Build()
+	bootstrapConfig7Delete := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-7-delete").Build()
+	mp7Delete := newFakeMachinePoolTopologyState("mp-7-delete", infrastructureMachinePool7Delete, bootstrapConfig7Delete)
+	infrastructureMachinePool7Update := builder.InfrastructureMachinePool(metav1.NamespaceDefault, "infrastructure-machinepool-7-update").Build()
+	bootstrapConfig7Update := builder.BootstrapConfig(metav1.NamespaceDefault, "bootstrap-config-7-update").Build()
+	mp7Update := newFakeMachinePoolTopologyState("mp-7-update", infrastructureMachinePool7Update, bootstrapConfig7Update)
+	infrastructureMachinePool7UpdateWithChanges := infrastructureMachinePool7Update.DeepCopy()
+	infrastructureMachinePool7UpdateWithChanges.SetLabels(map[string]string{"foo": "bar"})
+	bootstrapConfig7UpdateWithChanges := bootstrapConfig7Update.DeepCopy()
+	bootstrapConfig7UpdateWithChanges.SetLabels(map[string]string{"foo": "bar"})
+	mp7UpdateWithChanges := newFakeMachinePoolTopologyState("mp-7-update", infrastructureMachinePool7UpdateWithChanges, bootstrapConfig7UpdateWithChanges)
+
+	tests := []struct {
+		name                        string
+		current                     []*scope.MachinePoolState
+		desired                     []*scope.MachinePoolState
+		want                        []*scope.MachinePoolState
+		wantBootstrapConfigRotation map[string]bool
+		wantDataSecretNameRemoval   map[string]bool
+		wantErr                     bool
+	}{
+		{
+			name:    "Should create desired MachinePool if the current does not exists yet",
+			current: nil,
+			desired: []*scope.MachinePoolState{mp1},
+			want:    []*scope.MachinePoolState{mp1},
+			wantErr: false,
+		},
+		{
+			name:    "No-op if current MachinePool is equal to desired",
+			current: []*scope.MachinePoolState{mp1},
+			desired: []*scope.MachinePoolState{mp1},
+			want:    []*scope.MachinePoolState{mp1},
+			wantErr: false,
+		},
+		{
+			name:    "Should update InfrastructureMachinePool in place",
+			current: []*scope.MachinePoolState{mp2},
+			desired: []*scope.MachinePoolState{mp2WithChangedInfrastructureMachinePool},
+			want:    []*scope.MachinePoolState{mp2WithChangedInfrastructureMachinePool},
+			wantErr: false,
+		},
+		{
+			name:                        "Should update MachinePool with BootstrapConfig rotation",
+			current:                     []*scope.MachinePoolState{mp3},
+			desired:                     []*scope.MachinePoolState{mp3WithRotatedBootstrapConfig},
+			want:                        []*scope.MachinePoolState{mp3WithRotatedBootstrapConfig},
+			wantBootstrapConfigRotation: map[string]bool{"mp-3": true},
+			wantDataSecretNameRemoval:   map[string]bool{"mp-3": true},
+			wantErr:                     false,
+		},
+		{
+			name:    "Should fail update MachinePool because of changed InfrastructureMachinePool kind",
+			current: []*scope.MachinePoolState{mp4},
+			desired: []*scope.MachinePoolState{mp4WithChangedInfrastructureMachinePoolKind},
+			wantErr: true,
+		},
+		{
+			name:    "Should fail update MachinePool because of changed BootstrapConfig namespace",
+			current: []*scope.MachinePoolState{mp5},
+			desired: []*scope.MachinePoolState{mp5WithChangedBootstrapConfigNamespace},
+			wantErr: true,
+		},
+		{
+			name:    "Should delete MachinePool",
+			current: []*scope.MachinePoolState{mp6},
+			desired: []*scope.MachinePoolState{},
+			want:    []*scope.MachinePoolState{},
+			wantErr: false,
+		},
+		{
+			name:                        "Should create, update and delete MachinePools",
+			current:                     []*scope.MachinePoolState{mp7Update, mp7Delete},
+			desired:                     []*scope.MachinePoolState{mp7Create, mp7UpdateWithChanges},
+			want:                        []*scope.MachinePoolState{mp7Create, mp7UpdateWithChanges},
+			wantBootstrapConfigRotation: map[string]bool{"mp-7-update": true},
+			wantErr:                     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fakeObjs := make([]client.Object, 0)
+			for _, mpts := range tt.current {
+				fakeObjs = append(fakeObjs, mpts.Object.DeepCopy())
+				fakeObjs = append(fakeObjs, mpts.InfrastructureMachinePoolObject.DeepCopy())
+				fakeObjs = append(fakeObjs, mpts.BootstrapObject.DeepCopy())
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(fakeScheme).
+				WithObjects(fakeObjs...).
+				Build()
+
+			// Read back the current state, so it reflects what is stored in the fake client.
+			currentMachinePoolStates := map[string]*scope.MachinePoolState{}
+			for topologyName, mpts := range toMachinePoolTopologyStateMap(tt.current) {
+				currentMP := &expv1.MachinePool{}
+				g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(mpts.Object), currentMP)).To(Succeed())
+				currentInfra := mpts.InfrastructureMachinePoolObject.DeepCopy()
+				g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(currentInfra), currentInfra)).To(Succeed())
+				currentBootstrap := mpts.BootstrapObject.DeepCopy()
+				g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(currentBootstrap), currentBootstrap)).To(Succeed())
+				currentMachinePoolStates[topologyName] = &scope.MachinePoolState{
+					Object:                          currentMP,
+					InfrastructureMachinePoolObject: currentInfra,
+					BootstrapObject:                 currentBootstrap,
+				}
+			}
+			s := scope.New(builder.Cluster(metav1.NamespaceDefault, "cluster-1").Build())
+			s.Current.MachinePools = currentMachinePoolStates
+
+			desiredMachinePoolStates := map[string]*scope.MachinePoolState{}
+			for topologyName, mpts := range toMachinePoolTopologyStateMap(tt.desired) {
+				desiredMachinePoolStates[topologyName] = &scope.MachinePoolState{
+					Object:                          mpts.Object.DeepCopy(),
+					InfrastructureMachinePoolObject: mpts.InfrastructureMachinePoolObject.DeepCopy(),
+					BootstrapObject:                 mpts.BootstrapObject.DeepCopy(),
+				}
+				// Desired objects are always computed without data secret name.
+				desiredMachinePoolStates[topologyName].Object.Spec.Template.Spec.Bootstrap.DataSecretName = nil
+			}
+			s.Desired = &scope.ClusterState{MachinePools: desiredMachinePoolStates}
+
+			r := ClusterReconciler{
+				Client: fakeClient,
+			}
+			err := r.reconcileMachinePools(ctx, s)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var gotMachinePoolList expv1.MachinePoolList
+			g.Expect(fakeClient.List(ctx, &gotMachinePoolList)).To(Succeed())
+			g.Expect(gotMachinePoolList.Items).To(HaveLen(len(tt.want)))
+
+			for _, wantMachinePoolState := range tt.want {
+				for _, gotMachinePool := range gotMachinePoolList.Items {
+					if wantMachinePoolState.Object.Name != gotMachinePool.Name {
+						continue
+					}
+					currentMachinePoolState := toMachinePoolTopologyStateMap(tt.current)[wantMachinePoolState.Object.Labels[clusterv1.ClusterTopologyMachinePoolLabelName]]
+
+					// Compare BootstrapConfig.
+					gotBootstrapConfigRef := gotMachinePool.Spec.Template.Spec.Bootstrap.ConfigRef
+					gotBootstrapConfig := unstructured.Unstructured{}
+					gotBootstrapConfig.SetKind(gotBootstrapConfigRef.Kind)
+					gotBootstrapConfig.SetAPIVersion(gotBootstrapConfigRef.APIVersion)
+					g.Expect(fakeClient.Get(ctx, client.ObjectKey{
+						Namespace: gotBootstrapConfigRef.Namespace,
+						Name:      gotBootstrapConfigRef.Name,
+					}, &gotBootstrapConfig)).To(Succeed())
+					g.Expect(gotBootstrapConfig.GetLabels()).To(Equal(wantMachinePoolState.BootstrapObject.GetLabels()))
+
+					// Check BootstrapConfig rotation and data secret name removal if there was a previous MachinePool.
+					if currentMachinePoolState != nil {
+						if tt.wantBootstrapConfigRotation[currentMachinePoolState.Object.Name] {
+							g.Expect(gotBootstrapConfig.GetName()).ToNot(Equal(currentMachinePoolState.BootstrapObject.GetName()))
+
+							// The previous BootstrapConfig should be deleted.
+							oldBootstrapConfig := currentMachinePoolState.BootstrapObject.DeepCopy()
+							err := fakeClient.Get(ctx, client.ObjectKeyFromObject(oldBootstrapConfig), oldBootstrapConfig)
+							g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+						} else {
+							g.Expect(gotBootstrapConfig.GetName()).To(Equal(currentMachinePoolState.BootstrapObject.GetName()))
+						}
+						if tt.wantDataSecretNameRemoval[currentMachinePoolState.Object.Name] {
+							g.Expect(gotMachinePool.Spec.Template.Spec.Bootstrap.DataSecretName).To(BeNil())
+						}
+					}
+
+					// Compare InfrastructureMachinePool; it is never rotated.
+					gotInfrastructureMachinePoolRef := gotMachinePool.Spec.Template.Spec.InfrastructureRef
+					gotInfrastructureMachinePool := unstructured.Unstructured{}
+					gotInfrastructureMachinePool.SetKind(gotInfrastructureMachinePoolRef.Kind)
+					gotInfrastructureMachinePool.SetAPIVersion(gotInfrastructureMachinePoolRef.APIVersion)
+					g.Expect(fakeClient.Get(ctx, client.ObjectKey{
+						Namespace: gotInfrastructureMachinePoolRef.Namespace,
+						Name:      gotInfrastructureMachinePoolRef.Name,
+					}, &gotInfrastructureMachinePool)).To(Succeed())
+					g.Expect(gotInfrastructureMachinePool.GetName()).To(Equal(wantMachinePoolState.InfrastructureMachinePoolObject.GetName()))
+					g.Expect(gotInfrastructureMachinePool.GetLabels()).To(Equal(wantMachinePoolState.InfrastructureMachinePoolObject.GetLabels()))
+				}
+			}
+		})
+	}
+}
+
+func newFakeMachinePoolTopologyState(name string, infrastructureMachinePool, bootstrapConfig *unstructured.Unstructured) *scope.MachinePoolState {
+	return &scope.MachinePoolState{
+		Object: builder.MachinePool(metav1.NamespaceDefault, name).
+			WithInfrastructure(infrastructureMachinePool).
+			WithBootstrap(bootstrapConfig).
+			WithLabels(map[string]string{clusterv1.ClusterTopologyMachinePoolLabelName: name + "-topology"}).
+			Build(),
+		InfrastructureMachinePoolObject: infrastructureMachinePool,
+		BootstrapObject:                 bootstrapConfig,
+	}
+}
+
+func toMachinePoolTopologyStateMap(states []*scope.MachinePoolState) map[string]*scope.MachinePoolState {
+	ret := map[string]*scope.MachinePoolState{}
+	for _, state := range states {
+		ret[state.Object.Labels[clusterv1.ClusterTopologyMachinePoolLabelName]] = state
+	}
+	return ret
+}
+
+func TestReconcileMachineHealthCheck(t *testing.T) {
+	cluster := builder.Cluster(metav1.NamespaceDefault, "cluster1").Build()
+	md := builder.MachineDeployment(metav1.NamespaceDefault, "md-1").Build()
+	check := &clusterv1.MachineHealthCheckClass{
+		UnhealthyConditions: []clusterv1.UnhealthyCondition{
+			{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+	}
+	mhc := computeMachineHealthCheck(md, selectorForMachineDeploymentMHC("md-topology-1"), cluster, check)
+
+	maxUnhealthy := intstr.FromString("50%")
+	mhcWithChanges := mhc.DeepCopy()
+	mhcWithChanges.Spec.MaxUnhealthy = &maxUnhealthy
+
+	tests := []struct {
+		name       string
+		current    *clusterv1.MachineHealthCheck
+		desired    *clusterv1.MachineHealthCheck
+		want       *clusterv1.MachineHealthCheck
+		wantDelete bool
+	}{
+		{
+			name:    "Should be a no op if there is neither a current nor a desired MachineHealthCheck",
+			current: nil,
+			desired: nil,
+			want:    nil,
+		},
+		{
+			name:    "Should create the desired MachineHealthCheck if there is no current MachineHealthCheck",
+			current: nil,
+			desired: mhc.DeepCopy(),
+			want:    mhc.DeepCopy(),
+		},
+		{
+			name:    "Should be a no op if the current and desired MachineHealthCheck are equal",
+			current: mhc.DeepCopy(),
+			desired: mhc.DeepCopy(),
+			want:    mhc.DeepCopy(),
+		},
+		{
+			name:    "Should patch the current MachineHealthCheck if it differs from the desired MachineHealthCheck",
+			current: mhc.DeepCopy(),
+			desired: mhcWithChanges.DeepCopy(),
+			want:    mhcWithChanges.DeepCopy(),
+		},
+		{
+			name:       "Should delete the current MachineHealthCheck if there is no desired MachineHealthCheck",
+			current:    mhc.DeepCopy(),
+			desired:    nil,
+			wantDelete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fakeObjs := make([]client.Object, 0)
+			if tt.current != nil {
+				fakeObjs = append(fakeObjs, tt.current)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(fakeScheme).
+				WithObjects(fakeObjs...).
+				Build()
+
+			// Read the current object back from the fake client, so it has the expected ResourceVersion.
+			current := tt.current
+			if current != nil {
+				current = &clusterv1.MachineHealthCheck{}
+				g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(tt.current), current)).To(Succeed())
+			}
+
+			r := ClusterReconciler{
+				Client: fakeClient,
+			}
+			g.Expect(r.reconcileMachineHealthCheck(ctx, current, tt.desired)).To(Succeed())
+
+			if tt.wantDelete {
+				err := fakeClient.Get(ctx, client.ObjectKeyFromObject(tt.current), &clusterv1.MachineHealthCheck{})
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			if tt.want == nil {
+				list := &clusterv1.MachineHealthCheckList{}
+				g.Expect(fakeClient.List(ctx, list)).To(Succeed())
+				g.Expect(list.Items).To(BeEmpty())
+				return
+			}
+
+			got := &clusterv1.MachineHealthCheck{}
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(tt.want), got)).To(Succeed())
+			g.Expect(got.Labels).To(Equal(tt.want.Labels))
+			g.Expect(got.OwnerReferences).To(Equal(tt.want.OwnerReferences))
+			g.Expect(got.Spec).To(Equal(tt.want.Spec))
+		})
+	}
+}
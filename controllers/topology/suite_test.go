@@ -17,12 +17,17 @@ limitations under the License.
 package topology
 
 import (
+	"context"
+
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
 )
 
 var (
@@ -33,5 +38,32 @@ var (
 func init() {
 	_ = clientgoscheme.AddToScheme(fakeScheme)
 	_ = clusterv1.AddToScheme(fakeScheme)
+	_ = expv1.AddToScheme(fakeScheme)
 	_ = apiextensionsv1.AddToScheme(fakeScheme)
+	_ = runtimev1.AddToScheme(fakeScheme)
+}
+
+// fakeRuntimeClient is a runtime client returning the configured responses for lifecycle hooks,
+// or a non-blocking response if not configured, and recording the hooks being called.
+type fakeRuntimeClient struct {
+	responses   map[runtimehooksv1.Hook]*runtimehooksv1.CommonRetryResponse
+	calledHooks []runtimehooksv1.Hook
+}
+
+func (f *fakeRuntimeClient) CallAllExtensions(_ context.Context, hook runtimehooksv1.Hook, _ interface{}) (*runtimehooksv1.CommonRetryResponse, error) {
+	f.calledHooks = append(f.calledHooks, hook)
+	if response, ok := f.responses[hook]; ok {
+		return response, nil
+	}
+	return &runtimehooksv1.CommonRetryResponse{
+		CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess},
+	}, nil
+}
+
+// blockingResponse returns a response blocking a lifecycle hook for the given number of seconds.
+func blockingResponse(retryAfterSeconds int32) *runtimehooksv1.CommonRetryResponse {
+	return &runtimehooksv1.CommonRetryResponse{
+		CommonResponse:    runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess, Message: "blocked"},
+		RetryAfterSeconds: retryAfterSeconds,
+	}
 }
@@ -43,6 +43,16 @@ func infrastructureMachineTemplateNamePrefix(clusterName, machineDeploymentTopol
 	return fmt.Sprintf("%s-%s-infra-", clusterName, machineDeploymentTopologyName)
 }
 
+// bootstrapConfigNamePrefix calculates the name prefix for a BootstrapConfig of a MachinePool.
+func bootstrapConfigNamePrefix(clusterName, machinePoolTopologyName string) string {
+	return fmt.Sprintf("%s-%s-bootstrap-", clusterName, machinePoolTopologyName)
+}
+
+// infrastructureMachinePoolNamePrefix calculates the name prefix for a InfrastructureMachinePool.
+func infrastructureMachinePoolNamePrefix(clusterName, machinePoolTopologyName string) string {
+	return fmt.Sprintf("%s-%s-infra-", clusterName, machinePoolTopologyName)
+}
+
 // infrastructureMachineTemplateNamePrefix calculates the name prefix for a InfrastructureMachineTemplate.
 func controlPlaneInfrastructureMachineTemplateNamePrefix(clusterName string) string {
 	return fmt.Sprintf("%s-control-plane-", clusterName)
@@ -38,7 +38,16 @@ func (src *KubeadmControlPlane) ConvertTo(destRaw conversion.Hub) error {
 	}
 
 	dest.Spec.MachineTemplate.ObjectMeta = restored.Spec.MachineTemplate.ObjectMeta
+	dest.Spec.MachineTemplate.NodeVolumeDetachTimeout = restored.Spec.MachineTemplate.NodeVolumeDetachTimeout
+	dest.Spec.MachineTemplate.NodeDeletionTimeout = restored.Spec.MachineTemplate.NodeDeletionTimeout
+	dest.Spec.RolloutBefore = restored.Spec.RolloutBefore
+	dest.Spec.EtcdBackup = restored.Spec.EtcdBackup
+	dest.Spec.EtcdClusterRef = restored.Spec.EtcdClusterRef
+	if dest.Spec.RolloutStrategy != nil && restored.Spec.RolloutStrategy != nil {
+		dest.Spec.RolloutStrategy.InPlaceUpgrade = restored.Spec.RolloutStrategy.InPlaceUpgrade
+	}
 	dest.Status.Version = restored.Status.Version
+	dest.Status.EtcdBackup = restored.Status.EtcdBackup
 
 	if restored.Spec.KubeadmConfigSpec.JoinConfiguration != nil && restored.Spec.KubeadmConfigSpec.JoinConfiguration.NodeRegistration.IgnorePreflightErrors != nil {
 		if dest.Spec.KubeadmConfigSpec.JoinConfiguration == nil {
@@ -92,7 +101,7 @@ func Convert_v1beta1_KubeadmControlPlaneSpec_To_v1alpha3_KubeadmControlPlaneSpec
 }
 
 func Convert_v1beta1_KubeadmControlPlaneStatus_To_v1alpha3_KubeadmControlPlaneStatus(in *v1beta1.KubeadmControlPlaneStatus, out *KubeadmControlPlaneStatus, s apiconversion.Scope) error {
-	// NOTE: custom conversion func is required because status.Version does not exist in v1alpha3.
+	// NOTE: custom conversion func is required because status.Version and status.EtcdBackup do not exist in v1alpha3.
 	return autoConvert_v1beta1_KubeadmControlPlaneStatus_To_v1alpha3_KubeadmControlPlaneStatus(in, out, s)
 }
 
@@ -102,3 +111,8 @@ func Convert_v1alpha3_KubeadmControlPlaneSpec_To_v1beta1_KubeadmControlPlaneSpec
 	out.MachineTemplate.NodeDrainTimeout = in.NodeDrainTimeout
 	return autoConvert_v1alpha3_KubeadmControlPlaneSpec_To_v1beta1_KubeadmControlPlaneSpec(in, out, s)
 }
+
+func Convert_v1beta1_RolloutStrategy_To_v1alpha3_RolloutStrategy(in *v1beta1.RolloutStrategy, out *RolloutStrategy, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.rolloutStrategy.inPlaceUpgrade does not exist in v1alpha3.
+	return autoConvert_v1beta1_RolloutStrategy_To_v1alpha3_RolloutStrategy(in, out, s)
+}
@@ -17,20 +17,51 @@ limitations under the License.
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *KubeadmControlPlane) ConvertTo(destRaw conversion.Hub) error {
 	dest := destRaw.(*v1beta1.KubeadmControlPlane)
 
-	return Convert_v1alpha4_KubeadmControlPlane_To_v1beta1_KubeadmControlPlane(src, dest, nil)
+	if err := Convert_v1alpha4_KubeadmControlPlane_To_v1beta1_KubeadmControlPlane(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.KubeadmControlPlane{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dest.Spec.MachineTemplate.NodeVolumeDetachTimeout = restored.Spec.MachineTemplate.NodeVolumeDetachTimeout
+	dest.Spec.MachineTemplate.NodeDeletionTimeout = restored.Spec.MachineTemplate.NodeDeletionTimeout
+	dest.Spec.RolloutBefore = restored.Spec.RolloutBefore
+	dest.Spec.EtcdBackup = restored.Spec.EtcdBackup
+	dest.Spec.EtcdClusterRef = restored.Spec.EtcdClusterRef
+	if dest.Spec.RolloutStrategy != nil && restored.Spec.RolloutStrategy != nil {
+		dest.Spec.RolloutStrategy.InPlaceUpgrade = restored.Spec.RolloutStrategy.InPlaceUpgrade
+	}
+	dest.Status.EtcdBackup = restored.Status.EtcdBackup
+
+	return nil
 }
 
 func (dest *KubeadmControlPlane) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.KubeadmControlPlane)
 
-	return Convert_v1beta1_KubeadmControlPlane_To_v1alpha4_KubeadmControlPlane(src, dest, nil)
+	if err := Convert_v1beta1_KubeadmControlPlane_To_v1alpha4_KubeadmControlPlane(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dest); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *KubeadmControlPlaneList) ConvertTo(destRaw conversion.Hub) error {
@@ -48,13 +79,41 @@ func (dest *KubeadmControlPlaneList) ConvertFrom(srcRaw conversion.Hub) error {
 func (src *KubeadmControlPlaneTemplate) ConvertTo(destRaw conversion.Hub) error {
 	dest := destRaw.(*v1beta1.KubeadmControlPlaneTemplate)
 
-	return Convert_v1alpha4_KubeadmControlPlaneTemplate_To_v1beta1_KubeadmControlPlaneTemplate(src, dest, nil)
+	if err := Convert_v1alpha4_KubeadmControlPlaneTemplate_To_v1beta1_KubeadmControlPlaneTemplate(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.KubeadmControlPlaneTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dest.Spec.Template.Spec.MachineTemplate.NodeVolumeDetachTimeout = restored.Spec.Template.Spec.MachineTemplate.NodeVolumeDetachTimeout
+	dest.Spec.Template.Spec.MachineTemplate.NodeDeletionTimeout = restored.Spec.Template.Spec.MachineTemplate.NodeDeletionTimeout
+	dest.Spec.Template.Spec.RolloutBefore = restored.Spec.Template.Spec.RolloutBefore
+	dest.Spec.Template.Spec.EtcdBackup = restored.Spec.Template.Spec.EtcdBackup
+	dest.Spec.Template.Spec.EtcdClusterRef = restored.Spec.Template.Spec.EtcdClusterRef
+	if dest.Spec.Template.Spec.RolloutStrategy != nil && restored.Spec.Template.Spec.RolloutStrategy != nil {
+		dest.Spec.Template.Spec.RolloutStrategy.InPlaceUpgrade = restored.Spec.Template.Spec.RolloutStrategy.InPlaceUpgrade
+	}
+
+	return nil
 }
 
 func (dest *KubeadmControlPlaneTemplate) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.KubeadmControlPlaneTemplate)
 
-	return Convert_v1beta1_KubeadmControlPlaneTemplate_To_v1alpha4_KubeadmControlPlaneTemplate(src, dest, nil)
+	if err := Convert_v1beta1_KubeadmControlPlaneTemplate_To_v1alpha4_KubeadmControlPlaneTemplate(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dest); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *KubeadmControlPlaneTemplateList) ConvertTo(destRaw conversion.Hub) error {
@@ -68,3 +127,24 @@ func (dest *KubeadmControlPlaneTemplateList) ConvertFrom(srcRaw conversion.Hub)
 
 	return Convert_v1beta1_KubeadmControlPlaneTemplateList_To_v1alpha4_KubeadmControlPlaneTemplateList(src, dest, nil)
 }
+
+func Convert_v1beta1_KubeadmControlPlaneSpec_To_v1alpha4_KubeadmControlPlaneSpec(in *v1beta1.KubeadmControlPlaneSpec, out *KubeadmControlPlaneSpec, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.rolloutBefore, spec.etcdBackup and spec.etcdClusterRef do not exist in v1alpha4.
+	return autoConvert_v1beta1_KubeadmControlPlaneSpec_To_v1alpha4_KubeadmControlPlaneSpec(in, out, s)
+}
+
+func Convert_v1beta1_KubeadmControlPlaneStatus_To_v1alpha4_KubeadmControlPlaneStatus(in *v1beta1.KubeadmControlPlaneStatus, out *KubeadmControlPlaneStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.etcdBackup does not exist in v1alpha4.
+	return autoConvert_v1beta1_KubeadmControlPlaneStatus_To_v1alpha4_KubeadmControlPlaneStatus(in, out, s)
+}
+
+func Convert_v1beta1_KubeadmControlPlaneMachineTemplate_To_v1alpha4_KubeadmControlPlaneMachineTemplate(in *v1beta1.KubeadmControlPlaneMachineTemplate, out *KubeadmControlPlaneMachineTemplate, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.machineTemplate.nodeVolumeDetachTimeout and
+	// spec.machineTemplate.nodeDeletionTimeout do not exist in v1alpha4.
+	return autoConvert_v1beta1_KubeadmControlPlaneMachineTemplate_To_v1alpha4_KubeadmControlPlaneMachineTemplate(in, out, s)
+}
+
+func Convert_v1beta1_RolloutStrategy_To_v1alpha4_RolloutStrategy(in *v1beta1.RolloutStrategy, out *RolloutStrategy, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.rolloutStrategy.inPlaceUpgrade does not exist in v1alpha4.
+	return autoConvert_v1beta1_RolloutStrategy_To_v1alpha4_RolloutStrategy(in, out, s)
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	cabpkv1alpha4 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/upstreamv1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
@@ -57,6 +58,7 @@ func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
 	return []interface{}{
 		kubeadmBootstrapTokenStringFuzzer,
 		cabpkBootstrapTokenStringFuzzer,
+		cabpkV1alpha4BootstrapTokenStringFuzzer,
 		dnsFuzzer,
 	}
 }
@@ -69,6 +71,10 @@ func cabpkBootstrapTokenStringFuzzer(in *cabpkv1.BootstrapTokenString, c fuzz.Co
 	in.ID = "abcdef"
 	in.Secret = "abcdef0123456789"
 }
+func cabpkV1alpha4BootstrapTokenStringFuzzer(in *cabpkv1alpha4.BootstrapTokenString, c fuzz.Continue) {
+	in.ID = "abcdef"
+	in.Secret = "abcdef0123456789"
+}
 
 func dnsFuzzer(obj *upstreamv1beta1.DNS, c fuzz.Continue) {
 	c.FuzzNoCustom(obj)
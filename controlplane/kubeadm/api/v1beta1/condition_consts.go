@@ -54,6 +54,22 @@ const (
 	// RollingUpdateInProgressReason (Severity=Warning) documents a KubeadmControlPlane object executing a
 	// rolling upgrade for aligning the machines spec to the desired state.
 	RollingUpdateInProgressReason = "RollingUpdateInProgress"
+
+	// InPlaceUpgradeInProgressReason (Severity=Info) documents a KubeadmControlPlane object, or a machine, executing
+	// an in-place upgrade of the control plane components for aligning the machines spec to the desired state.
+	InPlaceUpgradeInProgressReason = "InPlaceUpgradeInProgress"
+)
+
+const (
+	// MachineInPlaceUpgradeSucceededCondition reports the result of the last in-place upgrade of the control plane
+	// components of a machine.
+	// NOTE: This condition exists only on machines which have been upgraded in place.
+	MachineInPlaceUpgradeSucceededCondition clusterv1.ConditionType = "InPlaceUpgradeSucceeded"
+
+	// InPlaceUpgradeFailedReason (Severity=Warning) documents a machine whose control plane components could not be
+	// upgraded in place, either because the node agent reported a failure or because it did not complete in time;
+	// the machine is replaced instead.
+	InPlaceUpgradeFailedReason = "InPlaceUpgradeFailed"
 )
 
 const (
@@ -122,6 +138,20 @@ const (
 	// EtcdClusterUnhealthyReason (Severity=Error) is set when the etcd cluster is unhealthy.
 	EtcdClusterUnhealthyReason = "EtcdClusterUnhealthy"
 
+	// ExternalEtcdClusterNotReadyReason (Severity=Warning) is set when the external etcd cluster referenced
+	// by the KubeadmControlPlane is not ready.
+	ExternalEtcdClusterNotReadyReason = "ExternalEtcdClusterNotReady"
+
+	// ExternalEtcdEndpointsUpToDateCondition documents that the control plane machines use the endpoints reported
+	// by the external etcd cluster referenced by the KubeadmControlPlane.
+	// NOTE: Machines are not rolled out when the endpoints change; a rollout must be requested explicitly, e.g.
+	// using spec.rolloutAfter.
+	ExternalEtcdEndpointsUpToDateCondition clusterv1.ConditionType = "ExternalEtcdEndpointsUpToDate"
+
+	// ExternalEtcdEndpointsOutdatedReason (Severity=Warning) is set when some control plane machines use endpoints
+	// other than the ones reported by the external etcd cluster.
+	ExternalEtcdEndpointsOutdatedReason = "ExternalEtcdEndpointsOutdated"
+
 	// MachineEtcdMemberHealthyCondition report the machine's etcd member's health status.
 	// NOTE: This conditions exists only if a stacked etcd cluster is used.
 	MachineEtcdMemberHealthyCondition clusterv1.ConditionType = "EtcdMemberHealthy"
@@ -149,3 +179,17 @@ const (
 	// generate a machine object.
 	MachineGenerationFailedReason = "MachineGenerationFailed"
 )
+
+const (
+	// EtcdBackupSucceededCondition documents that the last etcd snapshot scheduled by the KubeadmControlPlane
+	// has been taken and stored in the backup target.
+	// NOTE: This condition exists only if etcd backups are configured.
+	EtcdBackupSucceededCondition clusterv1.ConditionType = "EtcdBackupSucceeded"
+
+	// EtcdSnapshotFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to take an etcd snapshot.
+	EtcdSnapshotFailedReason = "EtcdSnapshotFailed"
+
+	// EtcdSnapshotStoreFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to store an etcd
+	// snapshot in the backup target, or to delete the snapshots exceeding the retention.
+	EtcdSnapshotStoreFailedReason = "EtcdSnapshotStoreFailed"
+)
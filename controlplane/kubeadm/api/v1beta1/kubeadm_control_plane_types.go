@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -48,8 +50,37 @@ const (
 	// KubeadmClusterConfigurationAnnotation is a machine annotation that stores the json-marshalled string of KCP ClusterConfiguration.
 	// This annotation is used to detect any changes in ClusterConfiguration and trigger machine rollout in KCP.
 	KubeadmClusterConfigurationAnnotation = "controlplane.cluster.x-k8s.io/kubeadm-cluster-configuration"
+
+	// MinimumCertificatesExpiryDays is the minimum value allowed for RolloutBefore.CertificatesExpiryDays, so
+	// there is enough time for machines to be rolled out before their certificates expire.
+	MinimumCertificatesExpiryDays = 7
+
+	// DefaultEtcdBackupRetention is the default number of etcd snapshots kept in the backup target.
+	DefaultEtcdBackupRetention = 3
+
+	// InPlaceUpgradeRequestAnnotation is set by the KubeadmControlPlane on a control plane Node to ask the node agent
+	// to upgrade the control plane components in place; the value identifies the requested ClusterConfiguration.
+	InPlaceUpgradeRequestAnnotation = "controlplane.cluster.x-k8s.io/in-place-upgrade-request"
+
+	// InPlaceUpgradeCompletedAnnotation is set by the node agent on a control plane Node, with the value of the
+	// InPlaceUpgradeRequestAnnotation, after the control plane components have been upgraded in place.
+	InPlaceUpgradeCompletedAnnotation = "controlplane.cluster.x-k8s.io/in-place-upgrade-completed"
+
+	// InPlaceUpgradeFailedAnnotation is set by the node agent on a control plane Node, with the value of the
+	// InPlaceUpgradeRequestAnnotation, when the control plane components could not be upgraded in place.
+	InPlaceUpgradeFailedAnnotation = "controlplane.cluster.x-k8s.io/in-place-upgrade-failed"
+
+	// InPlaceUpgradeMessageAnnotation can be set by the node agent on a control plane Node to report details about
+	// the last in-place upgrade, e.g. the reason of a failure.
+	InPlaceUpgradeMessageAnnotation = "controlplane.cluster.x-k8s.io/in-place-upgrade-message"
 )
 
+// DefaultInPlaceUpgradeTimeout is the default time to wait for a node to complete an in-place upgrade.
+var DefaultInPlaceUpgradeTimeout = metav1.Duration{Duration: 10 * time.Minute}
+
+// MinimumEtcdBackupInterval is the minimum time allowed between two etcd snapshots.
+var MinimumEtcdBackupInterval = metav1.Duration{Duration: 5 * time.Minute}
+
 // KubeadmControlPlaneSpec defines the desired state of KubeadmControlPlane.
 type KubeadmControlPlaneSpec struct {
 	// Number of desired machines. Defaults to 1. When stacked etcd is used only
@@ -76,6 +107,25 @@ type KubeadmControlPlaneSpec struct {
 	// +optional
 	RolloutAfter *metav1.Time `json:"rolloutAfter,omitempty"`
 
+	// RolloutBefore is a field to indicate a rollout should be performed
+	// if the specified criteria is met.
+	// +optional
+	RolloutBefore *RolloutBefore `json:"rolloutBefore,omitempty"`
+
+	// EtcdBackup configures the etcd snapshots taken by the KubeadmControlPlane, and the
+	// snapshot to restore when initializing the control plane.
+	// It can be used only when etcd is managed by the KubeadmControlPlane.
+	// +optional
+	EtcdBackup *EtcdBackup `json:"etcdBackup,omitempty"`
+
+	// EtcdClusterRef is an optional reference to a provider-specific resource managing the
+	// external etcd cluster configured in KubeadmConfigSpec.ClusterConfiguration.Etcd.External.
+	// When set, the KubeadmControlPlane waits for the etcd cluster to be ready before creating
+	// control plane machines, reports its health in the EtcdClusterHealthy condition and keeps
+	// the external etcd endpoints in sync with the ones reported by the etcd cluster.
+	// +optional
+	EtcdClusterRef *corev1.ObjectReference `json:"etcdClusterRef,omitempty"`
+
 	// The RolloutStrategy to use to replace control plane machines with
 	// new ones.
 	// +optional
@@ -100,6 +150,81 @@ type KubeadmControlPlaneMachineTemplate struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// NodeVolumeDetachTimeout is the total amount of time that the controller will spend on waiting for all volumes
+	// to be detached from a controlplane node after it has been drained.
+	// The default value is 0, meaning that the volumes can be detached without any time limitations.
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+
+	// NodeDeletionTimeout defines how long the machine controller will attempt to delete the Node that the Machine
+	// hosts after the Machine is marked for deletion. A duration of 0 will retry deletion indefinitely.
+	// If no value is provided, the default value for this property of the Machine resource will be used.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
+}
+
+// RolloutBefore describes when a rollout should be performed on the KCP machines.
+type RolloutBefore struct {
+	// CertificatesExpiryDays indicates a rollout needs to be performed if the
+	// certificates of the machine will expire within the specified days.
+	// +kubebuilder:validation:Minimum=7
+	// +optional
+	CertificatesExpiryDays *int32 `json:"certificatesExpiryDays,omitempty"`
+}
+
+// EtcdBackup describes the etcd snapshots taken by the KubeadmControlPlane.
+type EtcdBackup struct {
+	// Interval is the time between two etcd snapshots, e.g. 6h.
+	// If not set, no snapshot is taken; this can be used to restore a snapshot only.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Retention is the number of snapshots to keep in the target; older snapshots are deleted.
+	// Defaults to 3.
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// Target defines where the snapshots are stored.
+	Target EtcdBackupTarget `json:"target"`
+
+	// RestoreSnapshot is the name of a snapshot in the target to restore when the KubeadmControlPlane
+	// initializes the control plane, i.e. when it creates the first control plane machine.
+	// It has no effect on an already initialized control plane.
+	// The first machine downloads the snapshot from the target at boot.
+	// +optional
+	RestoreSnapshot string `json:"restoreSnapshot,omitempty"`
+}
+
+// EtcdBackupTarget defines where the etcd snapshots are stored.
+// Exactly one of the targets must be set; S3 is currently the only supported target.
+// NOTE: PersistentVolumeClaims are not supported as a target: the KubeadmControlPlane controller cannot mount
+// them, and the first control plane machine could not read a snapshot from them at boot to restore it.
+type EtcdBackupTarget struct {
+	// S3 stores the snapshots in a bucket of an S3-compatible object storage.
+	// +optional
+	S3 *S3EtcdBackupTarget `json:"s3,omitempty"`
+}
+
+// S3EtcdBackupTarget stores etcd snapshots in a bucket of an S3-compatible object storage.
+type S3EtcdBackupTarget struct {
+	// Endpoint is the URL of the S3-compatible object storage, e.g. https://s3.us-east-1.amazonaws.com.
+	// The bucket is addressed using path-style requests.
+	Endpoint string `json:"endpoint"`
+
+	// Region is the region of the bucket, e.g. us-east-1.
+	Region string `json:"region"`
+
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to the key of the snapshot objects.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the namespace of the KubeadmControlPlane with
+	// the accessKeyID and secretAccessKey used to access the bucket.
+	CredentialsSecretName string `json:"credentialsSecretName"`
 }
 
 // RolloutStrategy describes how to replace existing machines
@@ -115,6 +240,23 @@ type RolloutStrategy struct {
 	// RolloutStrategyType = RollingUpdate.
 	// +optional
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+
+	// InPlaceUpgrade enables upgrading the control plane components of the existing machines in place,
+	// instead of replacing the machines, when only the following fields of the ClusterConfiguration change:
+	// apiServer (except certSANs), controllerManager, scheduler, dns, and the image and extraArgs of local etcd.
+	// It requires a node agent implementing the in-place upgrade contract on the control plane nodes;
+	// machines which cannot be upgraded in place are replaced according to RollingUpdate.
+	// +optional
+	InPlaceUpgrade *InPlaceUpgrade `json:"inPlaceUpgrade,omitempty"`
+}
+
+// InPlaceUpgrade is used to control the desired behavior of in-place upgrades.
+type InPlaceUpgrade struct {
+	// Timeout is the time to wait for a node to complete an in-place upgrade; a machine
+	// which does not complete it in time is replaced according to RollingUpdate.
+	// Defaults to 10m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // RollingUpdate is used to control the desired behavior of rolling update.
@@ -125,6 +267,9 @@ type RollingUpdate struct {
 	// Defaults to 1.
 	// Example: when this is set to 1, the control plane can be scaled
 	// up immediately when the rolling update starts.
+	// When this is set to 0, the control plane is rolled out with scale-in, i.e. an
+	// outdated machine is removed before its replacement is created, which requires
+	// at least 3 replicas; each step is delayed until it can be taken preserving etcd quorum.
 	// +optional
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 }
@@ -194,6 +339,26 @@ type KubeadmControlPlaneStatus struct {
 	// Conditions defines current service state of the KubeadmControlPlane.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// EtcdBackup reports the etcd snapshots taken by the KubeadmControlPlane.
+	// +optional
+	EtcdBackup *EtcdBackupStatus `json:"etcdBackup,omitempty"`
+}
+
+// EtcdBackupStatus reports the etcd snapshots taken by the KubeadmControlPlane.
+type EtcdBackupStatus struct {
+	// LastSnapshotName is the name of the last snapshot taken.
+	// +optional
+	LastSnapshotName string `json:"lastSnapshotName,omitempty"`
+
+	// LastSnapshotTime is the time the last snapshot has been taken at.
+	// +optional
+	LastSnapshotTime *metav1.Time `json:"lastSnapshotTime,omitempty"`
+
+	// LastAttemptTime is the time the last snapshot has been started at, whether it succeeded or not;
+	// failed snapshots are retried with a backoff from this time.
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -19,16 +19,19 @@ package v1beta1
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/blang/semver"
 	"github.com/coredns/corefile-migration/migration"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -83,6 +86,17 @@ func defaultKubeadmControlPlaneSpec(s *KubeadmControlPlaneSpec, namespace string
 			}
 			s.RolloutStrategy.RollingUpdate.MaxSurge = intstr.ValueOrDefault(s.RolloutStrategy.RollingUpdate.MaxSurge, ios1)
 		}
+		if s.RolloutStrategy.InPlaceUpgrade != nil && s.RolloutStrategy.InPlaceUpgrade.Timeout == nil {
+			s.RolloutStrategy.InPlaceUpgrade.Timeout = DefaultInPlaceUpgradeTimeout.DeepCopy()
+		}
+	}
+
+	if s.EtcdBackup != nil && s.EtcdBackup.Retention == nil {
+		s.EtcdBackup.Retention = pointer.Int32(DefaultEtcdBackupRetention)
+	}
+
+	if s.EtcdClusterRef != nil && s.EtcdClusterRef.Namespace == "" {
+		s.EtcdClusterRef.Namespace = namespace
 	}
 }
 
@@ -123,6 +137,7 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, kubeadmConfigSpec, clusterConfiguration, "etcd", "local", "imageRepository"},
 		{spec, kubeadmConfigSpec, clusterConfiguration, "etcd", "local", "imageTag"},
 		{spec, kubeadmConfigSpec, clusterConfiguration, "etcd", "local", "extraArgs", "*"},
+		{spec, kubeadmConfigSpec, clusterConfiguration, "etcd", "external", "endpoints"},
 		{spec, kubeadmConfigSpec, clusterConfiguration, "dns", "imageRepository"},
 		{spec, kubeadmConfigSpec, clusterConfiguration, "dns", "imageTag"},
 		{spec, kubeadmConfigSpec, clusterConfiguration, "imageRepository"},
@@ -140,9 +155,16 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, "machineTemplate", "metadata", "*"},
 		{spec, "machineTemplate", "infrastructureRef", "apiVersion"},
 		{spec, "machineTemplate", "infrastructureRef", "name"},
+		{spec, "machineTemplate", "nodeVolumeDetachTimeout"},
+		{spec, "machineTemplate", "nodeDeletionTimeout"},
 		{spec, "replicas"},
 		{spec, "version"},
 		{spec, "rolloutAfter"},
+		{spec, "rolloutBefore", "*"},
+		{spec, "etcdBackup"},
+		{spec, "etcdBackup", "*"},
+		{spec, "etcdClusterRef"},
+		{spec, "etcdClusterRef", "*"},
 		{spec, "nodeDrainTimeout"},
 		{spec, "rolloutStrategy", "*"},
 	}
@@ -287,6 +309,27 @@ func validateKubeadmControlPlaneSpec(s KubeadmControlPlaneSpec, namespace string
 		allErrs = append(allErrs, field.Invalid(pathPrefix.Child("version"), s.Version, "must be a valid semantic version"))
 	}
 
+	if s.RolloutBefore != nil && s.RolloutBefore.CertificatesExpiryDays != nil {
+		if *s.RolloutBefore.CertificatesExpiryDays < MinimumCertificatesExpiryDays {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					pathPrefix.Child("rolloutBefore", "certificatesExpiryDays"),
+					*s.RolloutBefore.CertificatesExpiryDays,
+					fmt.Sprintf("must be greater than or equal to %d", MinimumCertificatesExpiryDays),
+				),
+			)
+		}
+	}
+
+	if s.EtcdBackup != nil {
+		allErrs = append(allErrs, validateEtcdBackup(s.EtcdBackup, externalEtcd, pathPrefix.Child("etcdBackup"))...)
+	}
+
+	if s.EtcdClusterRef != nil {
+		allErrs = append(allErrs, validateEtcdClusterRef(s.EtcdClusterRef, externalEtcd, namespace, pathPrefix.Child("etcdClusterRef"))...)
+	}
+
 	if s.RolloutStrategy != nil {
 		if s.RolloutStrategy.Type != RollingUpdateStrategyType {
 			allErrs = append(
@@ -320,6 +363,17 @@ func validateKubeadmControlPlaneSpec(s KubeadmControlPlaneSpec, namespace string
 				),
 			)
 		}
+
+		if s.RolloutStrategy.InPlaceUpgrade != nil && s.RolloutStrategy.InPlaceUpgrade.Timeout != nil && s.RolloutStrategy.InPlaceUpgrade.Timeout.Duration <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					pathPrefix.Child("rolloutStrategy", "inPlaceUpgrade", "timeout"),
+					s.RolloutStrategy.InPlaceUpgrade.Timeout.Duration.String(),
+					"must be greater than 0",
+				),
+			)
+		}
 	}
 
 	if s.KubeadmConfigSpec.ClusterConfiguration == nil {
@@ -339,6 +393,100 @@ func validateKubeadmControlPlaneSpec(s KubeadmControlPlaneSpec, namespace string
 	return allErrs
 }
 
+func validateEtcdBackup(b *EtcdBackup, externalEtcd bool, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if externalEtcd {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				path,
+				"cannot be used with external etcd",
+			),
+		)
+	}
+
+	if b.Interval != nil && b.Interval.Duration < MinimumEtcdBackupInterval.Duration {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				path.Child("interval"),
+				b.Interval.Duration.String(),
+				fmt.Sprintf("must be greater than or equal to %s", MinimumEtcdBackupInterval.Duration.String()),
+			),
+		)
+	}
+
+	if b.Retention != nil && *b.Retention < 1 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				path.Child("retention"),
+				*b.Retention,
+				"must be greater than or equal to 1",
+			),
+		)
+	}
+
+	targetPath := path.Child("target")
+	if b.Target.S3 == nil {
+		allErrs = append(
+			allErrs,
+			field.Required(
+				targetPath.Child("s3"),
+				"must be set",
+			),
+		)
+	}
+
+	if s3 := b.Target.S3; s3 != nil {
+		s3Path := targetPath.Child("s3")
+		if u, err := url.Parse(s3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					s3Path.Child("endpoint"),
+					s3.Endpoint,
+					"must be a valid http or https URL",
+				),
+			)
+		}
+		if s3.Region == "" {
+			allErrs = append(allErrs, field.Required(s3Path.Child("region"), "is required"))
+		}
+		if s3.Bucket == "" {
+			allErrs = append(allErrs, field.Required(s3Path.Child("bucket"), "is required"))
+		}
+		if s3.CredentialsSecretName == "" {
+			allErrs = append(allErrs, field.Required(s3Path.Child("credentialsSecretName"), "is required"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateEtcdClusterRef(ref *corev1.ObjectReference, externalEtcd bool, namespace string, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !externalEtcd {
+		allErrs = append(allErrs, field.Forbidden(path, "can be set only when using external etcd"))
+	}
+	if ref.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(path.Child("apiVersion"), "is required"))
+	}
+	if ref.Kind == "" {
+		allErrs = append(allErrs, field.Required(path.Child("kind"), "is required"))
+	}
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), "is required"))
+	}
+	if ref.Namespace != namespace {
+		allErrs = append(allErrs, field.Invalid(path.Child("namespace"), ref.Namespace, "must match metadata.namespace"))
+	}
+
+	return allErrs
+}
+
 func validateEtcd(s, prev *KubeadmControlPlaneSpec) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -390,6 +538,20 @@ func validateEtcd(s, prev *KubeadmControlPlaneSpec) field.ErrorList {
 		}
 	}
 
+	// The etcd cluster reference can be set on an existing KubeadmControlPlane, e.g. when adopting an existing
+	// external etcd cluster, but cannot point to a different etcd cluster afterwards.
+	if prev != nil && prev.EtcdClusterRef != nil && s.EtcdClusterRef != nil {
+		if s.EtcdClusterRef.APIVersion != prev.EtcdClusterRef.APIVersion || s.EtcdClusterRef.Kind != prev.EtcdClusterRef.Kind || s.EtcdClusterRef.Name != prev.EtcdClusterRef.Name {
+			allErrs = append(
+				allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "etcdClusterRef"),
+					"cannot be changed to reference a different etcd cluster",
+				),
+			)
+		}
+	}
+
 	return allErrs
 }
 
@@ -63,6 +63,19 @@ func TestKubeadmControlPlaneDefault(t *testing.T) {
 	g.Expect(kcp.Spec.Version).To(Equal("v1.18.3"))
 	g.Expect(kcp.Spec.RolloutStrategy.Type).To(Equal(RollingUpdateStrategyType))
 	g.Expect(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal).To(Equal(int32(1)))
+	g.Expect(kcp.Spec.EtcdBackup).To(BeNil())
+
+	kcp.Spec.EtcdBackup = &EtcdBackup{Target: EtcdBackupTarget{S3: &S3EtcdBackupTarget{}}}
+	kcp.Default()
+	g.Expect(kcp.Spec.EtcdBackup.Retention).To(Equal(pointer.Int32(DefaultEtcdBackupRetention)))
+
+	kcp.Spec.EtcdClusterRef = &corev1.ObjectReference{APIVersion: "etcd.example.com/v1", Kind: "EtcdCluster", Name: "foo"}
+	kcp.Default()
+	g.Expect(kcp.Spec.EtcdClusterRef.Namespace).To(Equal(kcp.Namespace))
+
+	kcp.Spec.RolloutStrategy.InPlaceUpgrade = &InPlaceUpgrade{}
+	kcp.Default()
+	g.Expect(kcp.Spec.RolloutStrategy.InPlaceUpgrade.Timeout).To(Equal(&DefaultInPlaceUpgradeTimeout))
 }
 
 func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
@@ -126,6 +139,66 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 	invalidVersion2 := valid.DeepCopy()
 	invalidVersion2.Spec.Version = "1.16.6"
 
+	validCertificatesExpiryDays := valid.DeepCopy()
+	validCertificatesExpiryDays.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32(21)}
+
+	invalidCertificatesExpiryDays := valid.DeepCopy()
+	invalidCertificatesExpiryDays.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32(5)}
+
+	validS3EtcdBackup := valid.DeepCopy()
+	validS3EtcdBackup.Spec.EtcdBackup = &EtcdBackup{
+		Interval:  &metav1.Duration{Duration: time.Hour},
+		Retention: pointer.Int32(3),
+		Target: EtcdBackupTarget{S3: &S3EtcdBackupTarget{
+			Endpoint:              "https://s3.us-east-1.amazonaws.com",
+			Region:                "us-east-1",
+			Bucket:                "backups",
+			CredentialsSecretName: "s3-credentials",
+		}},
+	}
+
+	invalidS3EtcdBackup := validS3EtcdBackup.DeepCopy()
+	invalidS3EtcdBackup.Spec.EtcdBackup.Target.S3.Endpoint = "s3.us-east-1.amazonaws.com"
+	invalidS3EtcdBackup.Spec.EtcdBackup.Target.S3.Bucket = ""
+
+	missingEtcdBackupTarget := validS3EtcdBackup.DeepCopy()
+	missingEtcdBackupTarget.Spec.EtcdBackup.Target = EtcdBackupTarget{}
+
+	tooShortEtcdBackupInterval := validS3EtcdBackup.DeepCopy()
+	tooShortEtcdBackupInterval.Spec.EtcdBackup.Interval = &metav1.Duration{Duration: time.Minute}
+
+	invalidEtcdBackupRetention := validS3EtcdBackup.DeepCopy()
+	invalidEtcdBackupRetention.Spec.EtcdBackup.Retention = pointer.Int32(0)
+
+	validS3EtcdBackupRestore := validS3EtcdBackup.DeepCopy()
+	validS3EtcdBackupRestore.Spec.EtcdBackup.RestoreSnapshot = "foo-etcd-20211018000000"
+
+	etcdBackupExternalEtcd := validS3EtcdBackup.DeepCopy()
+	etcdBackupExternalEtcd.Spec.KubeadmConfigSpec = evenReplicasExternalEtcd.Spec.KubeadmConfigSpec
+
+	validEtcdClusterRef := evenReplicasExternalEtcd.DeepCopy()
+	validEtcdClusterRef.Spec.EtcdClusterRef = &corev1.ObjectReference{
+		APIVersion: "etcd.example.com/v1",
+		Kind:       "EtcdCluster",
+		Name:       "foo",
+		Namespace:  "foo",
+	}
+
+	etcdClusterRefLocalEtcd := valid.DeepCopy()
+	etcdClusterRefLocalEtcd.Spec.EtcdClusterRef = validEtcdClusterRef.Spec.EtcdClusterRef.DeepCopy()
+
+	invalidEtcdClusterRefNamespace := validEtcdClusterRef.DeepCopy()
+	invalidEtcdClusterRefNamespace.Spec.EtcdClusterRef.Namespace = "bar"
+
+	missingEtcdClusterRefKind := validEtcdClusterRef.DeepCopy()
+	missingEtcdClusterRefKind.Spec.EtcdClusterRef.Kind = ""
+
+	validInPlaceUpgrade := valid.DeepCopy()
+	validInPlaceUpgrade.Spec.RolloutStrategy.InPlaceUpgrade = &InPlaceUpgrade{Timeout: &metav1.Duration{Duration: 5 * time.Minute}}
+
+	invalidInPlaceUpgradeTimeout := valid.DeepCopy()
+	invalidInPlaceUpgradeTimeout.Spec.RolloutStrategy.InPlaceUpgrade = &InPlaceUpgrade{Timeout: &metav1.Duration{}}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -181,6 +254,81 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidMaxSurge,
 		},
+		{
+			name:      "should succeed when certificatesExpiryDays is at least 7",
+			expectErr: false,
+			kcp:       validCertificatesExpiryDays,
+		},
+		{
+			name:      "should return error when certificatesExpiryDays is less than 7",
+			expectErr: true,
+			kcp:       invalidCertificatesExpiryDays,
+		},
+		{
+			name:      "should succeed when etcd backups are stored in S3",
+			expectErr: false,
+			kcp:       validS3EtcdBackup,
+		},
+		{
+			name:      "should succeed when restoring an etcd snapshot stored in S3",
+			expectErr: false,
+			kcp:       validS3EtcdBackupRestore,
+		},
+		{
+			name:      "should return error when the S3 etcd backup target is invalid",
+			expectErr: true,
+			kcp:       invalidS3EtcdBackup,
+		},
+		{
+			name:      "should return error when no etcd backup target is set",
+			expectErr: true,
+			kcp:       missingEtcdBackupTarget,
+		},
+		{
+			name:      "should return error when the etcd backup interval is too short",
+			expectErr: true,
+			kcp:       tooShortEtcdBackupInterval,
+		},
+		{
+			name:      "should return error when the etcd backup retention is less than 1",
+			expectErr: true,
+			kcp:       invalidEtcdBackupRetention,
+		},
+		{
+			name:      "should return error when etcd backups are configured with external etcd",
+			expectErr: true,
+			kcp:       etcdBackupExternalEtcd,
+		},
+		{
+			name:      "should succeed when referencing an etcd cluster with external etcd",
+			expectErr: false,
+			kcp:       validEtcdClusterRef,
+		},
+		{
+			name:      "should return error when referencing an etcd cluster with local etcd",
+			expectErr: true,
+			kcp:       etcdClusterRefLocalEtcd,
+		},
+		{
+			name:      "should return error when the etcd cluster is in a different namespace",
+			expectErr: true,
+			kcp:       invalidEtcdClusterRefNamespace,
+		},
+		{
+			name:      "should return error when the etcd cluster kind is missing",
+			expectErr: true,
+			kcp:       missingEtcdClusterRefKind,
+		},
+		{
+			name:      "should succeed when in-place upgrades are enabled",
+			expectErr: false,
+			kcp:       validInPlaceUpgrade,
+		},
+		{
+			name:      "should return error when the in-place upgrade timeout is not positive",
+			expectErr: true,
+			kcp:       invalidInPlaceUpgradeTimeout,
+		},
 	}
 
 	for _, tt := range tests {
@@ -329,9 +477,22 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	}
 	validUpdate.Spec.MachineTemplate.InfrastructureRef.APIVersion = "test/v1alpha2"
 	validUpdate.Spec.MachineTemplate.InfrastructureRef.Name = "orange"
+	validUpdate.Spec.MachineTemplate.NodeVolumeDetachTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+	validUpdate.Spec.MachineTemplate.NodeDeletionTimeout = &metav1.Duration{Duration: time.Minute}
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 	now := metav1.NewTime(time.Now())
 	validUpdate.Spec.RolloutAfter = &now
+	validUpdate.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32(14)}
+	validUpdate.Spec.EtcdBackup = &EtcdBackup{
+		Interval:  &metav1.Duration{Duration: 6 * time.Hour},
+		Retention: pointer.Int32(3),
+		Target: EtcdBackupTarget{S3: &S3EtcdBackupTarget{
+			Endpoint:              "https://s3.us-east-1.amazonaws.com",
+			Region:                "us-east-1",
+			Bucket:                "backups",
+			CredentialsSecretName: "s3-credentials",
+		}},
+	}
 
 	scaleToZero := before.DeepCopy()
 	scaleToZero.Spec.Replicas = pointer.Int32Ptr(0)
@@ -470,6 +631,26 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 		KeyFile: "some key file",
 	}
 
+	externalEtcdCluster := before.DeepCopy()
+	externalEtcdCluster.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External = &bootstrapv1.ExternalEtcd{
+		Endpoints: []string{"https://etcd-0:2379"},
+	}
+	externalEtcdCluster.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local = nil
+
+	setEtcdClusterRef := externalEtcdCluster.DeepCopy()
+	setEtcdClusterRef.Spec.EtcdClusterRef = &corev1.ObjectReference{
+		APIVersion: "etcd.example.com/v1",
+		Kind:       "EtcdCluster",
+		Name:       "etcd",
+		Namespace:  before.Namespace,
+	}
+
+	updateExternalEtcdEndpoints := setEtcdClusterRef.DeepCopy()
+	updateExternalEtcdEndpoints.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints = []string{"https://etcd-0:2379", "https://etcd-1:2379"}
+
+	changeEtcdClusterRef := setEtcdClusterRef.DeepCopy()
+	changeEtcdClusterRef.Spec.EtcdClusterRef.Name = "another-etcd"
+
 	localDataDir := before.DeepCopy()
 	localDataDir.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local = &bootstrapv1.LocalEtcd{
 		DataDir: "some local data dir",
@@ -748,6 +929,24 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			before:    before,
 			kcp:       externalEtcd,
 		},
+		{
+			name:      "should succeed when setting the etcd cluster reference",
+			expectErr: false,
+			before:    externalEtcdCluster,
+			kcp:       setEtcdClusterRef,
+		},
+		{
+			name:      "should succeed when updating the external etcd endpoints",
+			expectErr: false,
+			before:    setEtcdClusterRef,
+			kcp:       updateExternalEtcdEndpoints,
+		},
+		{
+			name:      "should fail when referencing a different etcd cluster",
+			expectErr: true,
+			before:    setEtcdClusterRef,
+			kcp:       changeEtcdClusterRef,
+		},
 		{
 			name:      "should fail when attempting to unset the etcd local object",
 			expectErr: true,
@@ -69,6 +69,9 @@ type KubeadmControlPlaneReconciler struct {
 
 	managementCluster         internal.ManagementCluster
 	managementClusterUncached internal.ManagementCluster
+
+	// etcdSnapshots tracks the etcd snapshots running in the background.
+	etcdSnapshots etcdSnapshotTracker
 }
 
 func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -194,6 +197,14 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 				res = ctrl.Result{RequeueAfter: 20 * time.Second}
 			}
 		}
+
+		// Make KCP to requeue when the next etcd snapshot is due or to record the result of the running one,
+		// unless it is already re-queueing earlier.
+		if reterr == nil && !res.Requeue && kcp.ObjectMeta.DeletionTimestamp.IsZero() {
+			if requeueAfter := r.etcdBackupRequeueAfter(kcp, time.Now()); requeueAfter > 0 && (res.RequeueAfter == 0 || requeueAfter < res.RequeueAfter) {
+				res = ctrl.Result{RequeueAfter: requeueAfter}
+			}
+		}
 	}()
 
 	if !kcp.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -230,6 +241,8 @@ func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kc
 			controlplanev1.MachinesReadyCondition,
 			controlplanev1.AvailableCondition,
 			controlplanev1.CertificatesAvailableCondition,
+			controlplanev1.EtcdBackupSucceededCondition,
+			controlplanev1.ExternalEtcdEndpointsUpToDateCondition,
 		}},
 		patch.WithStatusObservedGeneration{},
 	)
@@ -245,6 +258,11 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, err
 	}
 
+	// Make sure the Cluster owns the external etcd cluster, if any.
+	if err := r.reconcileExternalEtcdClusterOwner(ctx, cluster, kcp); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Generate Cluster Certificates if needed
 	config := kcp.Spec.KubeadmConfigSpec.DeepCopy()
 	config.JoinConfiguration = nil
@@ -299,6 +317,11 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, err
 	}
 
+	// Make sure new machines use the endpoints of the external etcd cluster, if any.
+	if err := r.reconcileExternalEtcdEndpoints(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Aggregate the operational state of all the machines; while aggregating we are adding the
 	// source ref (reason@machine/name) so the problem can be easily tracked down to its source machine.
 	conditions.SetAggregate(controlPlane.KCP, controlplanev1.MachinesReadyCondition, ownedMachines.ConditionGetters(), conditions.AddSourceRef(), conditions.WithStepCounterIf(false))
@@ -315,12 +338,29 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return result, err
 	}
 
+	// Reconcile certificate expiry for machines that don't have the expiry annotation on KubeadmConfig yet.
+	if result, err := r.reconcileCertificateExpiries(ctx, controlPlane); err != nil || !result.IsZero() {
+		return result, err
+	}
+
+	// Takes an etcd snapshot if one is due.
+	if result, err := r.reconcileEtcdBackup(ctx, controlPlane); err != nil || !result.IsZero() {
+		return result, err
+	}
+
 	// Reconcile unhealthy machines by triggering deletion and requeue if it is considered safe to remediate,
 	// otherwise continue with the other KCP operations.
 	if result, err := r.reconcileUnhealthyMachines(ctx, controlPlane); err != nil || !result.IsZero() {
 		return result, err
 	}
 
+	// Wait for the external etcd cluster, if any, to be ready before creating or deleting control plane machines.
+	if !controlPlane.IsExternalEtcdClusterReady() {
+		log.Info("Waiting for the external etcd cluster to be ready", "etcdCluster", kcp.Spec.EtcdClusterRef.Name)
+		controlPlane.UpdateExternalEtcdClusterCondition()
+		return ctrl.Result{RequeueAfter: externalEtcdRequeueAfter}, nil
+	}
+
 	// Control plane machines rollout due to configuration changes (e.g. upgrades) takes precedence over other operations.
 	needRollout := controlPlane.MachinesNeedingRollout()
 	switch {
@@ -400,6 +440,9 @@ func (r *KubeadmControlPlaneReconciler) reconcileDelete(ctx context.Context, clu
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 	log.Info("Reconcile KubeadmControlPlane deletion")
 
+	// Cancel the etcd snapshot running in the background, if any.
+	r.etcdSnapshots.remove(util.ObjectKey(kcp))
+
 	// Gets all machines, not just control plane machines.
 	allMachines, err := r.managementCluster.GetMachinesForCluster(ctx, cluster)
 	if err != nil {
@@ -566,6 +609,71 @@ func (r *KubeadmControlPlaneReconciler) reconcileEtcdMembers(ctx context.Context
 	return ctrl.Result{}, nil
 }
 
+// reconcileCertificateExpiries reads the expiry date of the kube-apiserver serving certificate of each control plane
+// machine and records it on the machine's KubeadmConfig; the Machine controller then surfaces it on the Machine status.
+// NOTE: kubeadm issues all the control plane certificates of a machine at the same time, so the kube-apiserver
+// serving certificate expiry is representative of all of them.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateExpiries(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+
+	// Return if there are no KCP-owned control-plane machines.
+	if controlPlane.Machines.Len() == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// Ignore machines which are being deleted.
+	machines := controlPlane.Machines.Filter(collections.Not(collections.HasDeletionTimestamp))
+
+	var workloadCluster internal.WorkloadCluster
+	for _, m := range machines {
+		// Nothing to do if the machine has no node yet, the API server can't be reached.
+		if m.Status.NodeRef == nil {
+			continue
+		}
+		nodeName := m.Status.NodeRef.Name
+
+		kubeadmConfig, ok := controlPlane.GetKubeadmConfig(m.Name)
+		if !ok {
+			continue
+		}
+
+		// Nothing to do if the certificates expiry has already been recorded.
+		if _, ok := kubeadmConfig.Annotations[clusterv1.MachineCertificatesExpiryDateAnnotation]; ok {
+			continue
+		}
+
+		// Connect to the workload cluster only once, and only if required.
+		if workloadCluster == nil {
+			var err error
+			workloadCluster, err = r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+			if err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "cannot get remote client to workload cluster")
+			}
+		}
+
+		log.V(3).Info("Reconciling certificate expiry", "machine", m.Name, "node", nodeName)
+		certificateExpiry, err := workloadCluster.GetAPIServerCertificateExpiry(ctx, kubeadmConfig, nodeName)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to get certificate expiry for machine %s", m.Name)
+		}
+
+		patchHelper, err := patch.NewHelper(kubeadmConfig, r.Client)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to create patch helper for KubeadmConfig %s", kubeadmConfig.Name)
+		}
+
+		annotations.AddAnnotations(kubeadmConfig, map[string]string{
+			clusterv1.MachineCertificatesExpiryDateAnnotation: certificateExpiry.UTC().Format(time.RFC3339),
+		})
+
+		if err := patchHelper.Patch(ctx, kubeadmConfig); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to patch KubeadmConfig %s", kubeadmConfig.Name)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
 func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machines collections.Machines, cluster *clusterv1.Cluster) error {
 	// We do an uncached full quorum read against the KCP to avoid re-adopting Machines the garbage collector just intentionally orphaned
 	// See https://github.com/kubernetes/kubernetes/issues/42639
@@ -1200,6 +1200,80 @@ kubernetesVersion: metav1.16.1`,
 	})
 }
 
+func TestKubeadmControlPlaneReconciler_reconcileCertificateExpiries(t *testing.T) {
+	g := NewWithT(t)
+
+	detectedExpiry := time.Now().Add(25 * 24 * time.Hour).UTC().Truncate(time.Second)
+	existingExpiry := time.Now().Add(300 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+	initObjs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy()}
+
+	machineWithConfig := func(name string, withNodeRef bool, configAnnotations map[string]string) *clusterv1.Machine {
+		m, _ := createMachineNodePair(name, cluster, kcp, true)
+		if !withNodeRef {
+			m.Status.NodeRef = nil
+		}
+		config := &bootstrapv1.KubeadmConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   cluster.Namespace,
+				Name:        name,
+				Annotations: configAnnotations,
+			},
+		}
+		m.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+			Kind:       "KubeadmConfig",
+			APIVersion: bootstrapv1.GroupVersion.String(),
+			Name:       config.Name,
+		}
+		initObjs = append(initObjs, m, config)
+		return m
+	}
+
+	// Machine without the certificates expiry annotation on its KubeadmConfig.
+	needsExpiry := machineWithConfig("needs-expiry", true, nil)
+	// Machine with the certificates expiry annotation already set on its KubeadmConfig.
+	hasExpiry := machineWithConfig("has-expiry", true, map[string]string{
+		clusterv1.MachineCertificatesExpiryDateAnnotation: existingExpiry.Format(time.RFC3339),
+	})
+	// Machine without a Node yet.
+	noNode := machineWithConfig("no-node", false, nil)
+
+	fakeClient := newFakeClient(initObjs...)
+
+	controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, collections.FromMachines(needsExpiry, hasExpiry, noNode))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		managementCluster: &fakeManagementCluster{
+			Workload: fakeWorkloadCluster{APIServerCertificateExpiry: &detectedExpiry},
+		},
+	}
+
+	result, err := r.reconcileCertificateExpiries(ctx, controlPlane)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.IsZero()).To(BeTrue())
+
+	expiryAnnotation := func(name string) (string, bool) {
+		config := &bootstrapv1.KubeadmConfig{}
+		g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, config)).To(Succeed())
+		value, ok := config.Annotations[clusterv1.MachineCertificatesExpiryDateAnnotation]
+		return value, ok
+	}
+
+	value, ok := expiryAnnotation(needsExpiry.Name)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(value).To(Equal(detectedExpiry.Format(time.RFC3339)))
+
+	value, ok = expiryAnnotation(hasExpiry.Name)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(value).To(Equal(existingExpiry.Format(time.RFC3339)))
+
+	_, ok = expiryAnnotation(noNode.Name)
+	g.Expect(ok).To(BeFalse())
+}
+
 func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
 	t.Run("removes all control plane Machines", func(t *testing.T) {
 		g := NewWithT(t)
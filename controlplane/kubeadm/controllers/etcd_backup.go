@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcdbackup"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// etcdBackupRetryInterval is the time to wait before retrying a failed etcd snapshot; it is capped at the
+	// backup interval.
+	etcdBackupRetryInterval = 10 * time.Minute
+
+	// etcdSnapshotTimeout is the maximum time allowed to take an etcd snapshot and store it in the backup target.
+	etcdSnapshotTimeout = time.Hour
+
+	// etcdSnapshotPollInterval is the time between two checks for the completion of a running etcd snapshot.
+	etcdSnapshotPollInterval = 30 * time.Second
+)
+
+// etcdSnapshot is an etcd snapshot taken in the background.
+type etcdSnapshot struct {
+	name      string
+	startTime time.Time
+	cancel    context.CancelFunc
+
+	// done is closed when the snapshot completes; the errors below must be read only after that.
+	done        chan struct{}
+	snapshotErr error
+	storeErr    error
+	pruneErr    error
+}
+
+// etcdSnapshotTracker tracks the etcd snapshots running in the background, at most one per KubeadmControlPlane,
+// so taking a snapshot does not block the other KCP operations for the whole upload.
+// The zero value is ready to use.
+type etcdSnapshotTracker struct {
+	lock      sync.Mutex
+	snapshots map[types.NamespacedName]*etcdSnapshot
+}
+
+// get returns the snapshot of the KubeadmControlPlane which is running or whose result has not been recorded yet.
+func (t *etcdSnapshotTracker) get(key types.NamespacedName) *etcdSnapshot {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.snapshots[key]
+}
+
+// add tracks a snapshot of the KubeadmControlPlane.
+func (t *etcdSnapshotTracker) add(key types.NamespacedName, snapshot *etcdSnapshot) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.snapshots == nil {
+		t.snapshots = map[types.NamespacedName]*etcdSnapshot{}
+	}
+	t.snapshots[key] = snapshot
+}
+
+// remove stops tracking the snapshot of the KubeadmControlPlane, canceling it if it is still running.
+func (t *etcdSnapshotTracker) remove(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if snapshot, ok := t.snapshots[key]; ok {
+		snapshot.cancel()
+		delete(t.snapshots, key)
+	}
+}
+
+// reconcileEtcdBackup starts taking a snapshot of etcd in the background when the backup interval has elapsed
+// since the last snapshot, and records the result of the snapshot once it completes; the snapshot is stored in
+// the backup target, then the snapshots exceeding the retention are deleted.
+// NOTE: failing to take a snapshot must not block the other KCP operations, e.g. the remediation of
+// unhealthy machines, so failures are only surfaced using the EtcdBackupSucceededCondition.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdBackup(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+	kcp := controlPlane.KCP
+	key := util.ObjectKey(kcp)
+
+	// If a snapshot is running, wait for it to complete; otherwise record the result of the completed snapshot.
+	if snapshot := r.etcdSnapshots.get(key); snapshot != nil {
+		select {
+		case <-snapshot.done:
+			r.etcdSnapshots.remove(key)
+			r.recordEtcdSnapshot(ctx, kcp, snapshot)
+		default:
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// If periodic snapshots are not configured, cleanup the condition reporting them.
+	if kcp.Spec.EtcdBackup == nil || kcp.Spec.EtcdBackup.Interval == nil {
+		conditions.Delete(kcp, controlplanev1.EtcdBackupSucceededCondition)
+		return ctrl.Result{}, nil
+	}
+
+	// If etcd is not managed by KCP or the control plane is not initialized yet, there is nothing to backup.
+	if !controlPlane.IsEtcdManaged() || !kcp.Status.Initialized || controlPlane.Machines.Len() == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	if etcdBackupDueIn(kcp, now) > 0 {
+		return ctrl.Result{}, nil
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+
+	// Record the attempt, so a failing snapshot is retried with a backoff instead of on every reconcile.
+	if kcp.Status.EtcdBackup == nil {
+		kcp.Status.EtcdBackup = &controlplanev1.EtcdBackupStatus{}
+	}
+	lastAttemptTime := metav1.NewTime(now)
+	kcp.Status.EtcdBackup.LastAttemptTime = &lastAttemptTime
+
+	name := etcdbackup.SnapshotName(kcp, now)
+	store, err := etcdbackup.NewStore(ctx, r.Client, kcp)
+	if err != nil {
+		log.Error(err, "Failed to store etcd snapshot", "snapshot", name)
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedEtcdSnapshot", "Failed to store etcd snapshot %s: %v", name, err)
+		conditions.MarkFalse(kcp, controlplanev1.EtcdBackupSucceededCondition, controlplanev1.EtcdSnapshotStoreFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, nil
+	}
+	retention := controlplanev1.DefaultEtcdBackupRetention
+	if kcp.Spec.EtcdBackup.Retention != nil {
+		retention = int(*kcp.Spec.EtcdBackup.Retention)
+	}
+
+	// The snapshot outlives this reconcile, so it is not bound to its context.
+	snapshotCtx, cancel := context.WithTimeout(ctrl.LoggerInto(context.Background(), log), etcdSnapshotTimeout)
+	snapshot := &etcdSnapshot{
+		name:      name,
+		startTime: now,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	r.etcdSnapshots.add(key, snapshot)
+	go snapshot.run(snapshotCtx, workloadCluster, store, retention)
+
+	log.Info("Taking etcd snapshot", "snapshot", name)
+	return ctrl.Result{}, nil
+}
+
+// run takes the snapshot and streams it gzipped to the store, so the snapshot is never held in memory as a whole,
+// then deletes the snapshots exceeding the retention.
+func (s *etcdSnapshot) run(ctx context.Context, workloadCluster internal.WorkloadCluster, store etcdbackup.Store, retention int) {
+	defer close(s.done)
+	defer s.cancel()
+
+	snapshotReader, snapshotWriter := io.Pipe()
+	snapshotErr := make(chan error, 1)
+	go func() {
+		gz := gzip.NewWriter(snapshotWriter)
+		err := workloadCluster.EtcdSnapshot(ctx, gz)
+		if err == nil {
+			err = gz.Close()
+		}
+		// Closing the writer with a nil error makes the store read io.EOF, i.e. the end of the snapshot.
+		snapshotWriter.CloseWithError(err)
+		snapshotErr <- err
+	}()
+	storeErr := store.Save(ctx, s.name, snapshotReader)
+	// Unblock the snapshot if the store stopped reading it early.
+	snapshotReader.Close()
+	if err := <-snapshotErr; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		s.snapshotErr = err
+		return
+	}
+	if storeErr != nil {
+		s.storeErr = storeErr
+		return
+	}
+
+	s.pruneErr = etcdbackup.Prune(ctx, store, retention)
+}
+
+// recordEtcdSnapshot records the result of a completed etcd snapshot in the KubeadmControlPlane.
+func (r *KubeadmControlPlaneReconciler) recordEtcdSnapshot(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, snapshot *etcdSnapshot) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if snapshot.snapshotErr != nil {
+		log.Error(snapshot.snapshotErr, "Failed to take etcd snapshot")
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedEtcdSnapshot", "Failed to take etcd snapshot: %v", snapshot.snapshotErr)
+		conditions.MarkFalse(kcp, controlplanev1.EtcdBackupSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityWarning, snapshot.snapshotErr.Error())
+		return
+	}
+	if snapshot.storeErr != nil {
+		log.Error(snapshot.storeErr, "Failed to store etcd snapshot", "snapshot", snapshot.name)
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedEtcdSnapshot", "Failed to store etcd snapshot %s: %v", snapshot.name, snapshot.storeErr)
+		conditions.MarkFalse(kcp, controlplanev1.EtcdBackupSucceededCondition, controlplanev1.EtcdSnapshotStoreFailedReason, clusterv1.ConditionSeverityWarning, snapshot.storeErr.Error())
+		return
+	}
+
+	log.Info("Etcd snapshot taken", "snapshot", snapshot.name)
+	r.recorder.Eventf(kcp, corev1.EventTypeNormal, "SuccessfulEtcdSnapshot", "Etcd snapshot %s taken", snapshot.name)
+	if kcp.Status.EtcdBackup == nil {
+		kcp.Status.EtcdBackup = &controlplanev1.EtcdBackupStatus{}
+	}
+	lastSnapshotTime := metav1.NewTime(snapshot.startTime)
+	kcp.Status.EtcdBackup.LastSnapshotName = snapshot.name
+	kcp.Status.EtcdBackup.LastSnapshotTime = &lastSnapshotTime
+
+	if snapshot.pruneErr != nil {
+		log.Error(snapshot.pruneErr, "Failed to delete etcd snapshots exceeding the retention")
+		conditions.MarkFalse(kcp, controlplanev1.EtcdBackupSucceededCondition, controlplanev1.EtcdSnapshotStoreFailedReason, clusterv1.ConditionSeverityWarning, snapshot.pruneErr.Error())
+		return
+	}
+	conditions.MarkTrue(kcp, controlplanev1.EtcdBackupSucceededCondition)
+}
+
+// etcdBackupDueIn returns the time left before the next etcd snapshot; it is zero or negative when a snapshot is due.
+// A snapshot is due when the backup interval has elapsed since the last successful snapshot, and the retry interval
+// has elapsed since the last failed attempt.
+func etcdBackupDueIn(kcp *controlplanev1.KubeadmControlPlane, now time.Time) time.Duration {
+	status := kcp.Status.EtcdBackup
+	if status == nil {
+		return 0
+	}
+
+	interval := kcp.Spec.EtcdBackup.Interval.Duration
+	var dueAt time.Time
+	if status.LastSnapshotTime != nil {
+		dueAt = status.LastSnapshotTime.Add(interval)
+	}
+	if status.LastAttemptTime != nil && (status.LastSnapshotTime == nil || status.LastAttemptTime.After(status.LastSnapshotTime.Time)) {
+		retryInterval := etcdBackupRetryInterval
+		if interval < retryInterval {
+			retryInterval = interval
+		}
+		if retryAt := status.LastAttemptTime.Add(retryInterval); retryAt.After(dueAt) {
+			dueAt = retryAt
+		}
+	}
+	if dueAt.IsZero() {
+		return 0
+	}
+	return dueAt.Sub(now)
+}
+
+// etcdBackupRequeueAfter returns the time after which the KubeadmControlPlane must be reconciled to record the
+// result of the running etcd snapshot, to take the next one, or to retry a failed one; it is zero if periodic
+// snapshots are not configured.
+func (r *KubeadmControlPlaneReconciler) etcdBackupRequeueAfter(kcp *controlplanev1.KubeadmControlPlane, now time.Time) time.Duration {
+	if r.etcdSnapshots.get(util.ObjectKey(kcp)) != nil {
+		return etcdSnapshotPollInterval
+	}
+	if kcp.Spec.EtcdBackup == nil || kcp.Spec.EtcdBackup.Interval == nil {
+		return 0
+	}
+	if dueIn := etcdBackupDueIn(kcp, now); dueIn > etcdSnapshotPollInterval {
+		return dueIn
+	}
+	return etcdSnapshotPollInterval
+}
+
+// injectEtcdRestore configures the bootstrap configuration of the first control plane machine to restore
+// the etcd snapshot requested in the KubeadmControlPlane before running kubeadm init.
+func (r *KubeadmControlPlaneReconciler) injectEtcdRestore(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, bootstrapSpec *bootstrapv1.KubeadmConfigSpec) error {
+	name := kcp.Spec.EtcdBackup.RestoreSnapshot
+
+	store, err := etcdbackup.NewStore(ctx, r.Client, kcp)
+	if err != nil {
+		return err
+	}
+	files, err := store.RestoreFiles(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get etcd snapshot %s", name)
+	}
+	etcdbackup.InjectRestore(bootstrapSpec, files)
+
+	r.recorder.Eventf(kcp, corev1.EventTypeNormal, "RestoringEtcdSnapshot", "Restoring etcd snapshot %s into the initial control plane Machine", name)
+	return nil
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcdbackup"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestKubeadmControlPlaneReconciler_reconcileEtcdBackup(t *testing.T) {
+	tests := []struct {
+		name               string
+		lastSnapshotAgo    time.Duration
+		lastAttemptAgo     time.Duration
+		existingSnapshots  []time.Duration
+		missingCredentials bool
+		snapshotErr        error
+		expectStart        bool
+		expectSnapshot     bool
+		expectedSnapshots  int
+		expectedReason     string
+	}{
+		{
+			name:              "takes the first snapshot",
+			expectStart:       true,
+			expectSnapshot:    true,
+			expectedSnapshots: 1,
+		},
+		{
+			name:              "does not take a snapshot before the interval has elapsed",
+			lastSnapshotAgo:   30 * time.Minute,
+			existingSnapshots: []time.Duration{30 * time.Minute},
+			expectedSnapshots: 1,
+		},
+		{
+			name:              "takes a snapshot and deletes the snapshots exceeding the retention",
+			lastSnapshotAgo:   2 * time.Hour,
+			existingSnapshots: []time.Duration{2 * time.Hour, 3 * time.Hour, 4 * time.Hour},
+			expectStart:       true,
+			expectSnapshot:    true,
+			expectedSnapshots: 2,
+		},
+		{
+			name:              "reports a failure to take a snapshot",
+			lastSnapshotAgo:   2 * time.Hour,
+			existingSnapshots: []time.Duration{2 * time.Hour},
+			snapshotErr:       errors.New("etcd is unavailable"),
+			expectStart:       true,
+			expectedSnapshots: 1,
+			expectedReason:    controlplanev1.EtcdSnapshotFailedReason,
+		},
+		{
+			name:               "reports a failure to access the backup target",
+			lastSnapshotAgo:    2 * time.Hour,
+			existingSnapshots:  []time.Duration{2 * time.Hour},
+			missingCredentials: true,
+			expectedSnapshots:  1,
+			expectedReason:     controlplanev1.EtcdSnapshotStoreFailedReason,
+		},
+		{
+			name:              "does not retry a failed snapshot before the retry interval has elapsed",
+			lastSnapshotAgo:   2 * time.Hour,
+			lastAttemptAgo:    5 * time.Minute,
+			existingSnapshots: []time.Duration{2 * time.Hour},
+			expectedSnapshots: 1,
+		},
+		{
+			name:              "retries a failed snapshot after the retry interval has elapsed",
+			lastSnapshotAgo:   2 * time.Hour,
+			lastAttemptAgo:    20 * time.Minute,
+			existingSnapshots: []time.Duration{2 * time.Hour},
+			expectStart:       true,
+			expectSnapshot:    true,
+			expectedSnapshots: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			bucket := newFakeS3Bucket("backups")
+			defer bucket.Close()
+
+			cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+			kcp.Spec.EtcdBackup = &controlplanev1.EtcdBackup{
+				Interval:  &metav1.Duration{Duration: time.Hour},
+				Retention: pointer.Int32(2),
+				Target:    fakeS3EtcdBackupTarget(bucket),
+			}
+			kcp.Status.Initialized = true
+			if tt.lastSnapshotAgo > 0 {
+				lastSnapshotTime := metav1.NewTime(time.Now().Add(-tt.lastSnapshotAgo))
+				kcp.Status.EtcdBackup = &controlplanev1.EtcdBackupStatus{LastSnapshotTime: &lastSnapshotTime, LastAttemptTime: &lastSnapshotTime}
+			}
+			if tt.lastAttemptAgo > 0 {
+				lastAttemptTime := metav1.NewTime(time.Now().Add(-tt.lastAttemptAgo))
+				kcp.Status.EtcdBackup.LastAttemptTime = &lastAttemptTime
+			}
+			for _, ago := range tt.existingSnapshots {
+				bucket.objects[etcdbackup.SnapshotName(kcp, time.Now().Add(-ago))+".db.gz"] = []byte("snapshot")
+			}
+
+			initObjs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy()}
+			if !tt.missingCredentials {
+				initObjs = append(initObjs, fakeS3Credentials(kcp))
+			}
+			machine, _ := createMachineNodePair("machine", cluster, kcp, true)
+			initObjs = append(initObjs, machine)
+			fakeClient := newFakeClient(initObjs...)
+
+			controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, collections.FromMachines(machine))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			r := &KubeadmControlPlaneReconciler{
+				Client:   fakeClient,
+				recorder: record.NewFakeRecorder(32),
+				managementCluster: &fakeManagementCluster{
+					Workload: fakeWorkloadCluster{
+						EtcdSnapshotData: []byte("snapshot"),
+						EtcdSnapshotErr:  tt.snapshotErr,
+					},
+				},
+			}
+
+			result, err := r.reconcileEtcdBackup(ctx, controlPlane)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.IsZero()).To(BeTrue())
+
+			// The snapshot runs in the background, and its result is recorded by the next reconcile.
+			snapshot := r.etcdSnapshots.get(util.ObjectKey(kcp))
+			g.Expect(snapshot != nil).To(Equal(tt.expectStart))
+			if tt.expectStart {
+				g.Expect(kcp.Status.EtcdBackup.LastAttemptTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+				g.Eventually(snapshot.done, 10*time.Second).Should(BeClosed())
+
+				result, err := r.reconcileEtcdBackup(ctx, controlPlane)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.IsZero()).To(BeTrue())
+				g.Expect(r.etcdSnapshots.get(util.ObjectKey(kcp))).To(BeNil())
+			}
+
+			g.Expect(bucket.keys()).To(HaveLen(tt.expectedSnapshots))
+
+			if tt.expectedReason != "" {
+				g.Expect(conditions.IsFalse(kcp, controlplanev1.EtcdBackupSucceededCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(kcp, controlplanev1.EtcdBackupSucceededCondition)).To(Equal(tt.expectedReason))
+				g.Expect(kcp.Status.EtcdBackup.LastAttemptTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			}
+			if !tt.expectSnapshot {
+				return
+			}
+
+			g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdBackupSucceededCondition)).To(BeTrue())
+			g.Expect(kcp.Status.EtcdBackup.LastSnapshotTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+
+			// The new snapshot is stored gzipped.
+			g.Expect(bucket.keys()).To(ContainElement(kcp.Status.EtcdBackup.LastSnapshotName + ".db.gz"))
+			gz, err := gzip.NewReader(bytes.NewReader(bucket.object(kcp.Status.EtcdBackup.LastSnapshotName + ".db.gz")))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(io.ReadAll(gz)).To(Equal([]byte("snapshot")))
+		})
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_etcdBackupRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	r := &KubeadmControlPlaneReconciler{}
+	kcp := &controlplanev1.KubeadmControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "cp", Namespace: metav1.NamespaceDefault}}
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(BeZero())
+
+	kcp.Spec.EtcdBackup = &controlplanev1.EtcdBackup{Interval: &metav1.Duration{Duration: time.Hour}}
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(etcdSnapshotPollInterval))
+
+	lastSnapshotTime := metav1.NewTime(now.Add(-20 * time.Minute))
+	kcp.Status.EtcdBackup = &controlplanev1.EtcdBackupStatus{LastSnapshotTime: &lastSnapshotTime, LastAttemptTime: &lastSnapshotTime}
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(40 * time.Minute))
+
+	lastSnapshotTime = metav1.NewTime(now.Add(-2 * time.Hour))
+	kcp.Status.EtcdBackup = &controlplanev1.EtcdBackupStatus{LastSnapshotTime: &lastSnapshotTime, LastAttemptTime: &lastSnapshotTime}
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(etcdSnapshotPollInterval))
+
+	// A failed snapshot is retried after the retry interval.
+	lastAttemptTime := metav1.NewTime(now.Add(-2 * time.Minute))
+	kcp.Status.EtcdBackup.LastAttemptTime = &lastAttemptTime
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(etcdBackupRetryInterval - 2*time.Minute))
+
+	// The retry interval is capped at the backup interval.
+	kcp.Spec.EtcdBackup.Interval = &metav1.Duration{Duration: 5 * time.Minute}
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(3 * time.Minute))
+
+	// The result of a running snapshot is polled.
+	r.etcdSnapshots.add(util.ObjectKey(kcp), &etcdSnapshot{cancel: func() {}, done: make(chan struct{})})
+	g.Expect(r.etcdBackupRequeueAfter(kcp, now)).To(Equal(etcdSnapshotPollInterval))
+	r.etcdSnapshots.remove(util.ObjectKey(kcp))
+	g.Expect(r.etcdSnapshots.get(util.ObjectKey(kcp))).To(BeNil())
+}
+
+func TestKubeadmControlPlaneReconciler_injectEtcdRestore(t *testing.T) {
+	g := NewWithT(t)
+
+	bucket := newFakeS3Bucket("backups")
+	defer bucket.Close()
+
+	cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+	snapshotName := kcp.Name + "-etcd-20211018000000"
+	bucket.objects[snapshotName+".db.gz"] = []byte("snapshot")
+
+	kcp.Spec.EtcdBackup = &controlplanev1.EtcdBackup{
+		Target:          fakeS3EtcdBackupTarget(bucket),
+		RestoreSnapshot: snapshotName,
+	}
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   newFakeClient(cluster.DeepCopy(), kcp.DeepCopy(), fakeS3Credentials(kcp)),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// The snapshot is downloaded at boot using a presigned URL, so it is not part of the bootstrap data.
+	bootstrapSpec := kcp.Spec.KubeadmConfigSpec.DeepCopy()
+	g.Expect(r.injectEtcdRestore(ctx, kcp, bootstrapSpec)).To(Succeed())
+	var snapshotURLFile *bootstrapv1.File
+	for i := range bootstrapSpec.Files {
+		if strings.HasSuffix(bootstrapSpec.Files[i].Path, "snapshot.url") {
+			snapshotURLFile = &bootstrapSpec.Files[i]
+		}
+	}
+	g.Expect(snapshotURLFile).NotTo(BeNil())
+	g.Expect(snapshotURLFile.Content).To(HavePrefix(bucket.URL + "/backups/" + snapshotName + ".db.gz?"))
+	g.Expect(bootstrapSpec.PreKubeadmCommands).To(ContainElement(ContainSubstring("restore.sh")))
+
+	// A missing snapshot is reported.
+	kcp.Spec.EtcdBackup.RestoreSnapshot = "missing"
+	g.Expect(r.injectEtcdRestore(ctx, kcp, kcp.Spec.KubeadmConfigSpec.DeepCopy())).NotTo(Succeed())
+}
+
+// fakeS3EtcdBackupTarget returns a backup target storing the snapshots in the fake bucket.
+func fakeS3EtcdBackupTarget(bucket *fakeS3Bucket) controlplanev1.EtcdBackupTarget {
+	return controlplanev1.EtcdBackupTarget{
+		S3: &controlplanev1.S3EtcdBackupTarget{
+			Endpoint:              bucket.URL,
+			Region:                "us-east-1",
+			Bucket:                bucket.name,
+			CredentialsSecretName: "s3-credentials",
+		},
+	}
+}
+
+// fakeS3Credentials returns the credentials Secret of the fake S3 backup target.
+func fakeS3Credentials(kcp *controlplanev1.KubeadmControlPlane) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: kcp.Namespace},
+		Data: map[string][]byte{
+			"accessKeyID":     []byte("access-key"),
+			"secretAccessKey": []byte("secret-key"),
+		},
+	}
+}
+
+// fakeS3Bucket is an S3-compatible object storage serving a single bucket, implementing the requests used to
+// store, list, check and delete small objects.
+type fakeS3Bucket struct {
+	*httptest.Server
+
+	name    string
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3Bucket(name string) *fakeS3Bucket {
+	b := &fakeS3Bucket{name: name, objects: map[string][]byte{}}
+	b.Server = httptest.NewServer(http.HandlerFunc(b.handle))
+	return b
+}
+
+// keys returns the keys of the objects in the bucket, sorted.
+func (b *fakeS3Bucket) keys() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// object returns the content of the object with the given key.
+func (b *fakeS3Bucket) object(key string) []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.objects[key]
+}
+
+func (b *fakeS3Bucket) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && strings.Trim(r.URL.Path, "/") == b.name {
+		result := struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+		}{}
+		for _, key := range b.keys() {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, struct {
+					Key string `xml:"Key"`
+				}{Key: key})
+			}
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+b.name+"/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		b.objects[key] = data
+	case http.MethodHead:
+		if _, ok := b.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
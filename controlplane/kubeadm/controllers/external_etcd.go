@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// externalEtcdRequeueAfter is the time to wait before checking again if the external etcd cluster is ready.
+const externalEtcdRequeueAfter = 20 * time.Second
+
+// reconcileExternalEtcdClusterOwner sets the Cluster as owner of the etcd cluster referenced by the KubeadmControlPlane, if any,
+// so it is e.g. moved together with the Cluster by clusterctl.
+func (r *KubeadmControlPlaneReconciler) reconcileExternalEtcdClusterOwner(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) error {
+	if kcp.Spec.EtcdClusterRef == nil {
+		return nil
+	}
+
+	obj, err := external.Get(ctx, r.Client, kcp.Spec.EtcdClusterRef, kcp.Namespace)
+	if err != nil {
+		// The etcd cluster could not exist yet; the KubeadmControlPlane waits for it, so there is nothing to do here.
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+
+	patchHelper, err := patch.NewHelper(obj, r.Client)
+	if err != nil {
+		return err
+	}
+
+	obj.SetOwnerReferences(util.EnsureOwnerRef(obj.GetOwnerReferences(), metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}))
+
+	return patchHelper.Patch(ctx, obj)
+}
+
+// reconcileExternalEtcdEndpoints makes sure that new control plane machines use the endpoints reported by the referenced
+// etcd cluster, and reports if existing machines use outdated endpoints.
+// NOTE: The KubeadmControlPlane spec is not changed, and existing machines are not rolled out when the endpoints change,
+// given that this would conflict with the owner of the spec and could trigger unexpected rollouts; a rollout must be
+// requested explicitly, e.g. using spec.rolloutAfter.
+func (r *KubeadmControlPlaneReconciler) reconcileExternalEtcdEndpoints(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+	kcp := controlPlane.KCP
+
+	// The endpoints of an etcd cluster which is not ready could be incomplete, so they are used only when it is ready.
+	if !controlPlane.HasExternalEtcdCluster() || !controlPlane.IsExternalEtcdClusterReady() || controlPlane.IsEtcdManaged() {
+		return nil
+	}
+
+	endpoints, err := controlPlane.ExternalEtcdClusterEndpoints()
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	// Machines joining the control plane read the endpoints from the kubeadm-config ConfigMap, which exists only after
+	// the control plane is initialized.
+	if kcp.Status.Initialized {
+		parsedVersion, err := semver.ParseTolerant(kcp.Spec.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
+		}
+
+		workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+		if err != nil {
+			return errors.Wrap(err, "failed to create client to workload cluster")
+		}
+
+		if err := workloadCluster.UpdateExternalEtcdEndpointsInKubeadmConfigMap(ctx, endpoints, parsedVersion); err != nil {
+			return errors.Wrap(err, "failed to update the external etcd endpoints in the kubeadm config map")
+		}
+	}
+
+	outdatedMachines := controlPlane.MachinesWithOutdatedExternalEtcdEndpoints(endpoints)
+	if len(outdatedMachines) == 0 {
+		conditions.MarkTrue(kcp, controlplanev1.ExternalEtcdEndpointsUpToDateCondition)
+		return nil
+	}
+
+	log.Info("Control plane machines use outdated external etcd endpoints, a rollout must be requested to use the new endpoints",
+		"machines", strings.Join(outdatedMachines.Names(), ", "), "endpoints", endpoints)
+	conditions.MarkFalse(kcp, controlplanev1.ExternalEtcdEndpointsUpToDateCondition, controlplanev1.ExternalEtcdEndpointsOutdatedReason, clusterv1.ConditionSeverityWarning,
+		"%d of %d machines use outdated external etcd endpoints, set spec.rolloutAfter to roll them out", len(outdatedMachines), len(controlPlane.Machines))
+	return nil
+}
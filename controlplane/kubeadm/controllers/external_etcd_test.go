@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestKubeadmControlPlaneReconciler_reconcileExternalEtcdCluster(t *testing.T) {
+	newEtcdCluster := func(namespace string, ready bool, endpoints ...string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("etcd.example.com/v1")
+		obj.SetKind("EtcdCluster")
+		obj.SetName("etcd")
+		obj.SetNamespace(namespace)
+		_ = unstructured.SetNestedField(obj.Object, ready, "status", "ready")
+		if len(endpoints) > 0 {
+			_ = unstructured.SetNestedStringSlice(obj.Object, endpoints, "status", "endpoints")
+		}
+		return obj
+	}
+
+	newMachine := func(namespace, name string, endpoints ...string) (*clusterv1.Machine, *bootstrapv1.KubeadmConfig, *unstructured.Unstructured) {
+		infraMachine := &unstructured.Unstructured{}
+		infraMachine.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+		infraMachine.SetKind("GenericMachine")
+		infraMachine.SetName(name)
+		infraMachine.SetNamespace(namespace)
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{
+					ConfigRef: &corev1.ObjectReference{Kind: "KubeadmConfig", Name: name, Namespace: namespace},
+				},
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: infraMachine.GetAPIVersion(),
+					Kind:       infraMachine.GetKind(),
+					Name:       name,
+					Namespace:  namespace,
+				},
+			},
+		}
+		kubeadmConfig := &bootstrapv1.KubeadmConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+					Etcd: bootstrapv1.Etcd{External: &bootstrapv1.ExternalEtcd{Endpoints: endpoints}},
+				},
+			},
+		}
+		return machine, kubeadmConfig, infraMachine
+	}
+
+	tests := []struct {
+		name                      string
+		etcdReady                 bool
+		etcdEndpoints             []string
+		machineEndpoints          []string
+		expectedNewEndpoints      []string
+		expectedEndpointsUpToDate *bool
+	}{
+		{
+			name:                 "does not use the endpoints while the etcd cluster is not ready",
+			etcdReady:            false,
+			etcdEndpoints:        []string{"https://etcd-0:2379", "https://etcd-1:2379"},
+			machineEndpoints:     []string{"https://etcd-0:2379"},
+			expectedNewEndpoints: []string{"https://etcd-0:2379"},
+		},
+		{
+			name:                 "does not use the endpoints if the etcd cluster does not report them",
+			etcdReady:            true,
+			machineEndpoints:     []string{"https://etcd-0:2379"},
+			expectedNewEndpoints: []string{"https://etcd-0:2379"},
+		},
+		{
+			name:                      "reports machines using the endpoints reported by the etcd cluster",
+			etcdReady:                 true,
+			etcdEndpoints:             []string{"https://etcd-0:2379"},
+			machineEndpoints:          []string{"https://etcd-0:2379"},
+			expectedNewEndpoints:      []string{"https://etcd-0:2379"},
+			expectedEndpointsUpToDate: pointer.BoolPtr(true),
+		},
+		{
+			name:                      "uses the endpoints reported by the etcd cluster for new machines and reports outdated machines",
+			etcdReady:                 true,
+			etcdEndpoints:             []string{"https://etcd-0:2379", "https://etcd-1:2379"},
+			machineEndpoints:          []string{"https://etcd-0:2379"},
+			expectedNewEndpoints:      []string{"https://etcd-0:2379", "https://etcd-1:2379"},
+			expectedEndpointsUpToDate: pointer.BoolPtr(false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+			kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+				Etcd: bootstrapv1.Etcd{
+					External: &bootstrapv1.ExternalEtcd{Endpoints: []string{"https://etcd-0:2379"}},
+				},
+			}
+			kcp.Spec.EtcdClusterRef = &corev1.ObjectReference{
+				APIVersion: "etcd.example.com/v1",
+				Kind:       "EtcdCluster",
+				Name:       "etcd",
+				Namespace:  kcp.Namespace,
+			}
+			kcp.Status.Initialized = true
+			etcdCluster := newEtcdCluster(kcp.Namespace, tt.etcdReady, tt.etcdEndpoints...)
+			machine, kubeadmConfig, infraMachine := newMachine(kcp.Namespace, "m1", tt.machineEndpoints...)
+			fakeClient := newFakeClient(cluster.DeepCopy(), kcp.DeepCopy(), etcdCluster, machine, kubeadmConfig, infraMachine)
+
+			r := &KubeadmControlPlaneReconciler{
+				Client:            fakeClient,
+				recorder:          record.NewFakeRecorder(32),
+				managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+			}
+
+			g.Expect(r.reconcileExternalEtcdClusterOwner(ctx, cluster, kcp)).To(Succeed())
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(etcdCluster), etcdCluster)).To(Succeed())
+			g.Expect(etcdCluster.GetOwnerReferences()).To(HaveLen(1))
+			g.Expect(etcdCluster.GetOwnerReferences()[0].Name).To(Equal(cluster.Name))
+
+			controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, collections.FromMachines(machine))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(Equal(tt.etcdReady))
+
+			g.Expect(r.reconcileExternalEtcdEndpoints(ctx, controlPlane)).To(Succeed())
+
+			// The KubeadmControlPlane spec is never changed.
+			g.Expect(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints).To(Equal([]string{"https://etcd-0:2379"}))
+
+			// New machines use the endpoints reported by the etcd cluster.
+			g.Expect(controlPlane.InitialControlPlaneConfig().ClusterConfiguration.Etcd.External.Endpoints).To(Equal(tt.expectedNewEndpoints))
+			g.Expect(controlPlane.JoinControlPlaneConfig().ClusterConfiguration.Etcd.External.Endpoints).To(Equal(tt.expectedNewEndpoints))
+
+			if tt.expectedEndpointsUpToDate == nil {
+				g.Expect(conditions.Has(kcp, controlplanev1.ExternalEtcdEndpointsUpToDateCondition)).To(BeFalse())
+				return
+			}
+			g.Expect(conditions.IsTrue(kcp, controlplanev1.ExternalEtcdEndpointsUpToDateCondition)).To(Equal(*tt.expectedEndpointsUpToDate))
+			if !*tt.expectedEndpointsUpToDate {
+				g.Expect(conditions.GetReason(kcp, controlplanev1.ExternalEtcdEndpointsUpToDateCondition)).To(Equal(controlplanev1.ExternalEtcdEndpointsOutdatedReason))
+			}
+		})
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileExternalEtcdClusterNotFound(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+		Etcd: bootstrapv1.Etcd{External: &bootstrapv1.ExternalEtcd{}},
+	}
+	kcp.Spec.EtcdClusterRef = &corev1.ObjectReference{
+		APIVersion: "etcd.example.com/v1",
+		Kind:       "EtcdCluster",
+		Name:       "etcd",
+		Namespace:  kcp.Namespace,
+	}
+	fakeClient := newFakeClient(cluster.DeepCopy(), kcp.DeepCopy())
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// A missing etcd cluster does not block the reconciliation, but the control plane waits for it.
+	g.Expect(r.reconcileExternalEtcdClusterOwner(ctx, cluster, kcp)).To(Succeed())
+	controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, collections.New())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(BeFalse())
+	g.Expect(r.reconcileExternalEtcdEndpoints(ctx, controlPlane)).To(Succeed())
+
+	controlPlane.UpdateExternalEtcdClusterCondition()
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(Equal(controlplanev1.ExternalEtcdClusterNotReadyReason))
+}
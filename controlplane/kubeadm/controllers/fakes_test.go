@@ -18,9 +18,12 @@ package controllers
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/blang/semver"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/collections"
@@ -64,8 +67,14 @@ func (f *fakeManagementCluster) GetMachinePoolsForCluster(c context.Context, clu
 
 type fakeWorkloadCluster struct {
 	*internal.Workload
-	Status            internal.ClusterStatus
-	EtcdMembersResult []string
+	Status                     internal.ClusterStatus
+	EtcdMembersResult          []string
+	APIServerCertificateExpiry *time.Time
+	EtcdSnapshotData           []byte
+	EtcdSnapshotErr            error
+	InPlaceUpgradeStatus       internal.InPlaceUpgradeStatus
+	InPlaceUpgradeMessage      string
+	InPlaceUpgradeRequests     *[]string
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, _ *clusterv1.Machine) error {
@@ -100,10 +109,36 @@ func (f fakeWorkloadCluster) UpdateEtcdVersionInKubeadmConfigMap(ctx context.Con
 	return nil
 }
 
+func (f fakeWorkloadCluster) UpdateExternalEtcdEndpointsInKubeadmConfigMap(ctx context.Context, endpoints []string, version semver.Version) error {
+	return nil
+}
+
+func (f fakeWorkloadCluster) RequestInPlaceUpgrade(_ context.Context, nodeName, _ string) error {
+	if f.InPlaceUpgradeRequests != nil {
+		*f.InPlaceUpgradeRequests = append(*f.InPlaceUpgradeRequests, nodeName)
+	}
+	return nil
+}
+
+func (f fakeWorkloadCluster) GetInPlaceUpgradeStatus(_ context.Context, _, _ string) (internal.InPlaceUpgradeStatus, string, error) {
+	if f.InPlaceUpgradeStatus == "" {
+		return internal.InPlaceUpgradeNotRequested, "", nil
+	}
+	return f.InPlaceUpgradeStatus, f.InPlaceUpgradeMessage, nil
+}
+
 func (f fakeWorkloadCluster) UpdateKubeletConfigMap(ctx context.Context, version semver.Version) error {
 	return nil
 }
 
+func (f fakeWorkloadCluster) EtcdSnapshot(_ context.Context, w io.Writer) error {
+	if f.EtcdSnapshotErr != nil {
+		return f.EtcdSnapshotErr
+	}
+	_, err := w.Write(f.EtcdSnapshotData)
+	return err
+}
+
 func (f fakeWorkloadCluster) RemoveEtcdMemberForMachine(ctx context.Context, machine *clusterv1.Machine) error {
 	return nil
 }
@@ -116,6 +151,14 @@ func (f fakeWorkloadCluster) EtcdMembers(_ context.Context) ([]string, error) {
 	return f.EtcdMembersResult, nil
 }
 
+func (f fakeWorkloadCluster) GetAPIServerCertificateExpiry(_ context.Context, _ *bootstrapv1.KubeadmConfig, _ string) (*time.Time, error) {
+	if f.APIServerCertificateExpiry != nil {
+		return f.APIServerCertificateExpiry, nil
+	}
+	expiry := time.Now().Add(365 * 24 * time.Hour)
+	return &expiry, nil
+}
+
 type fakeMigrator struct {
 	migrateCalled    bool
 	migrateErr       error
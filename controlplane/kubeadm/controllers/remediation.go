@@ -174,43 +174,85 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 func (r *KubeadmControlPlaneReconciler) canSafelyRemoveEtcdMember(ctx context.Context, controlPlane *internal.ControlPlane, machineToBeRemediated *clusterv1.Machine) (bool, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	// Gets the etcd status
+
+	// This makes it possible to have a set of etcd members status different from the MHC unhealthy/unhealthy conditions.
+	etcdMembers, err := r.getEtcdMembers(ctx, controlPlane)
+	if err != nil {
+		return false, err
+	}
+
+	log.Info("etcd cluster before remediation",
+		"currentTotalMembers", len(etcdMembers),
+		"currentMembers", etcdMembers)
+
+	// Projects the target etcd cluster after remediation, considering all the etcd members except the one being remediated.
+	target := projectEtcdCluster(controlPlane, etcdMembers, machineToBeRemediated, 0)
+	canSafelyRemediate := target.hasQuorum()
+
+	log.Info(fmt.Sprintf("etcd cluster projected after remediation of %s", machineToBeRemediated.Name),
+		"healthyMembers", target.healthyMembers,
+		"unhealthyMembers", target.unhealthyMembers,
+		"targetTotalMembers", target.totalMembers(),
+		"targetQuorum", target.quorum(),
+		"targetUnhealthyMembers", len(target.unhealthyMembers),
+		"canSafelyRemediate", canSafelyRemediate)
+
+	return canSafelyRemediate, nil
+}
+
+// getEtcdMembers returns the names of the etcd members of the workload cluster.
+func (r *KubeadmControlPlaneReconciler) getEtcdMembers(ctx context.Context, controlPlane *internal.ControlPlane) ([]string, error) {
 	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, client.ObjectKey{
 		Namespace: controlPlane.Cluster.Namespace,
 		Name:      controlPlane.Cluster.Name,
 	})
 	if err != nil {
-		return false, errors.Wrapf(err, "failed to get client for workload cluster %s", controlPlane.Cluster.Name)
+		return nil, errors.Wrapf(err, "failed to get client for workload cluster %s", controlPlane.Cluster.Name)
 	}
 
-	// Gets the etcd status
-
-	// This makes it possible to have a set of etcd members status different from the MHC unhealthy/unhealthy conditions.
 	etcdMembers, err := workloadCluster.EtcdMembers(ctx)
 	if err != nil {
-		return false, errors.Wrapf(err, "failed to get etcdStatus for workload cluster %s", controlPlane.Cluster.Name)
+		return nil, errors.Wrapf(err, "failed to get etcdStatus for workload cluster %s", controlPlane.Cluster.Name)
 	}
+	return etcdMembers, nil
+}
 
-	currentTotalMembers := len(etcdMembers)
+// etcdClusterProjection is an etcd cluster projected after adding or removing members.
+type etcdClusterProjection struct {
+	healthyMembers   []string
+	unhealthyMembers []string
+}
 
-	log.Info("etcd cluster before remediation",
-		"currentTotalMembers", currentTotalMembers,
-		"currentMembers", etcdMembers)
+func (p etcdClusterProjection) totalMembers() int {
+	return len(p.healthyMembers) + len(p.unhealthyMembers)
+}
 
-	// Projects the target etcd cluster after remediation, considering all the etcd members except the one being remediated.
-	targetTotalMembers := 0
-	targetUnhealthyMembers := 0
+// quorum returns the number of members required for the etcd cluster to have quorum.
+// See https://etcd.io/docs/v3.3/faq/#what-is-failure-tolerance for fault tolerance formula explanation.
+func (p etcdClusterProjection) quorum() int {
+	return (p.totalMembers() / 2.0) + 1
+}
+
+// hasQuorum returns true if the healthy members of the etcd cluster are enough to have quorum.
+func (p etcdClusterProjection) hasQuorum() bool {
+	return len(p.healthyMembers) >= p.quorum()
+}
 
-	healthyMembers := []string{}
-	unhealthyMembers := []string{}
+// projectEtcdCluster projects the etcd cluster after removing the member hosted on removedMachine, if any,
+// and after adding addedMembers new members, which are considered unhealthy until they join the cluster.
+// The health of the members is read from the health conditions of the corresponding machines.
+func projectEtcdCluster(controlPlane *internal.ControlPlane, etcdMembers []string, removedMachine *clusterv1.Machine, addedMembers int) etcdClusterProjection {
+	target := etcdClusterProjection{
+		healthyMembers:   []string{},
+		unhealthyMembers: []string{},
+	}
 	for _, etcdMember := range etcdMembers {
 		// Skip the machine to be deleted because it won't be part of the target etcd cluster.
-		if machineToBeRemediated.Status.NodeRef != nil && machineToBeRemediated.Status.NodeRef.Name == etcdMember {
+		if removedMachine != nil && removedMachine.Status.NodeRef != nil && removedMachine.Status.NodeRef.Name == etcdMember {
 			continue
 		}
 
-		// Include the member in the target etcd cluster.
-		targetTotalMembers++
-
 		// Search for the machine corresponding to the etcd member.
 		var machine *clusterv1.Machine
 		for _, m := range controlPlane.Machines {
@@ -225,33 +267,21 @@ func (r *KubeadmControlPlaneReconciler) canSafelyRemoveEtcdMember(ctx context.Co
 		//
 		// NOTE: This should not happen given that we are running reconcileEtcdMembers before calling this method.
 		if machine == nil {
-			log.Info("An etcd member does not have a corresponding machine, assuming this member is unhealthy", "MemberName", etcdMember)
-			targetUnhealthyMembers++
-			unhealthyMembers = append(unhealthyMembers, fmt.Sprintf("%s (no machine)", etcdMember))
+			target.unhealthyMembers = append(target.unhealthyMembers, fmt.Sprintf("%s (no machine)", etcdMember))
 			continue
 		}
 
 		// Check member health as reported by machine's health conditions
 		if !conditions.IsTrue(machine, controlplanev1.MachineEtcdMemberHealthyCondition) {
-			targetUnhealthyMembers++
-			unhealthyMembers = append(unhealthyMembers, fmt.Sprintf("%s (%s)", etcdMember, machine.Name))
+			target.unhealthyMembers = append(target.unhealthyMembers, fmt.Sprintf("%s (%s)", etcdMember, machine.Name))
 			continue
 		}
 
-		healthyMembers = append(healthyMembers, fmt.Sprintf("%s (%s)", etcdMember, machine.Name))
+		target.healthyMembers = append(target.healthyMembers, fmt.Sprintf("%s (%s)", etcdMember, machine.Name))
 	}
 
-	// See https://etcd.io/docs/v3.3/faq/#what-is-failure-tolerance for fault tolerance formula explanation.
-	targetQuorum := (targetTotalMembers / 2.0) + 1
-	canSafelyRemediate := targetTotalMembers-targetUnhealthyMembers >= targetQuorum
-
-	log.Info(fmt.Sprintf("etcd cluster projected after remediation of %s", machineToBeRemediated.Name),
-		"healthyMembers", healthyMembers,
-		"unhealthyMembers", unhealthyMembers,
-		"targetTotalMembers", targetTotalMembers,
-		"targetQuorum", targetQuorum,
-		"targetUnhealthyMembers", targetUnhealthyMembers,
-		"canSafelyRemediate", canSafelyRemediate)
-
-	return canSafelyRemediate, nil
+	for i := 0; i < addedMembers; i++ {
+		target.unhealthyMembers = append(target.unhealthyMembers, "(new member)")
+	}
+	return target
 }
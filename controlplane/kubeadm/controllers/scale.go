@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/blang/semver"
@@ -53,6 +54,16 @@ func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Conte
 	}
 
 	bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+
+	// Restore the etcd snapshot requested in the KubeadmControlPlane, if any, into the initial control plane Machine.
+	if kcp.Spec.EtcdBackup != nil && kcp.Spec.EtcdBackup.RestoreSnapshot != "" {
+		if err := r.injectEtcdRestore(ctx, kcp, bootstrapSpec); err != nil {
+			logger.Error(err, "Failed to configure the etcd snapshot restore")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedInitialization", "Failed to restore etcd snapshot for cluster %s/%s control plane: %v", cluster.Namespace, cluster.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+
 	fd := controlPlane.NextFailureDomainForScaleUp()
 	if err := r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec, fd); err != nil {
 		logger.Error(err, "Failed to create initial control plane Machine")
@@ -72,6 +83,14 @@ func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context,
 		return result, err
 	}
 
+	// When rolling out with scale-in, the machine being replaced has already been removed, so make sure that
+	// adding the etcd member of its replacement preserves etcd quorum.
+	if isScaleInRollout(kcp) {
+		if result, err := r.etcdQuorumChecks(ctx, controlPlane, nil); err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+
 	// Create the bootstrap configuration
 	bootstrapSpec := controlPlane.JoinControlPlaneConfig()
 	fd := controlPlane.NextFailureDomainForScaleUp()
@@ -117,6 +136,14 @@ func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(
 		return ctrl.Result{}, errors.New("failed to pick control plane Machine to delete")
 	}
 
+	// When rolling out with scale-in, the machine is removed before its replacement is created,
+	// so make sure that removing its etcd member preserves etcd quorum.
+	if isScaleInRollout(kcp) {
+		if result, err := r.etcdQuorumChecks(ctx, controlPlane, machineToDelete); err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+
 	// If KCP should manage etcd, If etcd leadership is on machine that is about to be deleted, move it to the newest member available.
 	if controlPlane.IsEtcdManaged() {
 		etcdLeaderCandidate := controlPlane.Machines.Newest()
@@ -217,6 +244,72 @@ loopmachines:
 	return ctrl.Result{}, nil
 }
 
+// isScaleInRollout returns true if the KubeadmControlPlane rolls out machines without surge, i.e. an outdated
+// machine is removed before its replacement is created.
+func isScaleInRollout(kcp *controlplanev1.KubeadmControlPlane) bool {
+	return kcp.Spec.RolloutStrategy != nil &&
+		kcp.Spec.RolloutStrategy.RollingUpdate != nil &&
+		kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge != nil &&
+		kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntValue() == 0
+}
+
+// etcdQuorumChecks checks that etcd preserves quorum after removing the member hosted on machineToDelete
+// or, if machineToDelete is nil, after adding a new member; if not, it requeues.
+// New members are considered unhealthy until they join the etcd cluster; for this reason, adding a member is
+// not checked while the etcd cluster is still growing towards the desired number of replicas, e.g. when adding the
+// second member, because the etcd cluster can't have quorum before it has at least the members required for the
+// quorum of the desired etcd cluster.
+//
+// NOTE: this func uses machine conditions, it is required to call reconcileEtcdMembers as well as
+// reconcileControlPlaneConditions before this.
+func (r *KubeadmControlPlaneReconciler) etcdQuorumChecks(ctx context.Context, controlPlane *internal.ControlPlane, machineToDelete *clusterv1.Machine) (ctrl.Result, error) {
+	if !controlPlane.IsEtcdManaged() {
+		return ctrl.Result{}, nil
+	}
+
+	logger := controlPlane.Logger()
+
+	etcdMembers, err := r.getEtcdMembers(ctx, controlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var target etcdClusterProjection
+	operation := "adding a new etcd member"
+	if machineToDelete != nil {
+		target = projectEtcdCluster(controlPlane, etcdMembers, machineToDelete, 0)
+		operation = fmt.Sprintf("removing the etcd member of machine %s", machineToDelete.Name)
+	} else {
+		if len(etcdMembers) < desiredEtcdQuorum(controlPlane.KCP) {
+			return ctrl.Result{}, nil
+		}
+		target = projectEtcdCluster(controlPlane, etcdMembers, nil, 1)
+	}
+	if target.hasQuorum() {
+		return ctrl.Result{}, nil
+	}
+
+	r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "EtcdQuorumAtRisk",
+		"Waiting for etcd to be healthy, %s could result in etcd losing quorum (healthy members: %v, unhealthy members: %v)",
+		operation, target.healthyMembers, target.unhealthyMembers)
+	logger.Info(fmt.Sprintf("Waiting for etcd to be healthy, %s could result in etcd losing quorum", operation),
+		"healthyMembers", target.healthyMembers,
+		"unhealthyMembers", target.unhealthyMembers,
+		"targetQuorum", target.quorum())
+
+	return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
+}
+
+// desiredEtcdQuorum returns the number of members required for quorum by an etcd cluster with a member for each
+// of the desired replicas of the KubeadmControlPlane.
+func desiredEtcdQuorum(kcp *controlplanev1.KubeadmControlPlane) int {
+	replicas := 1
+	if kcp.Spec.Replicas != nil {
+		replicas = int(*kcp.Spec.Replicas)
+	}
+	return (replicas / 2) + 1
+}
+
 func preflightCheckCondition(kind string, obj conditions.Getter, condition clusterv1.ConditionType) error {
 	c := conditions.Get(obj, condition)
 	if c == nil {
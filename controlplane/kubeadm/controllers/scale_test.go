@@ -27,8 +27,10 @@ import (
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/cluster-api/util/conditions"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
@@ -491,6 +493,141 @@ func TestPreflightChecks(t *testing.T) {
 	}
 }
 
+func TestEtcdQuorumChecks(t *testing.T) {
+	etcdMachine := func(name string, healthy bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Kind: "Node", Name: name},
+			},
+		}
+		if healthy {
+			conditions.MarkTrue(m, controlplanev1.MachineEtcdMemberHealthyCondition)
+		} else {
+			conditions.MarkFalse(m, controlplanev1.MachineEtcdMemberHealthyCondition, controlplanev1.EtcdMemberUnhealthyReason, clusterv1.ConditionSeverityError, "")
+		}
+		return m
+	}
+	m1 := etcdMachine("m1", true)
+	m2 := etcdMachine("m2", true)
+	m3 := etcdMachine("m3", true)
+	unhealthy := etcdMachine("unhealthy", false)
+	kcpWithReplicas := func(replicas int32) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		kcp             *controlplanev1.KubeadmControlPlane
+		machines        []*clusterv1.Machine
+		etcdMembers     []string
+		machineToDelete *clusterv1.Machine
+		expectResult    ctrl.Result
+	}{
+		{
+			name: "control plane with external etcd should pass",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{External: &bootstrapv1.ExternalEtcd{}},
+						},
+					},
+				},
+			},
+			machines:        []*clusterv1.Machine{m1, unhealthy},
+			machineToDelete: m1,
+			expectResult:    ctrl.Result{},
+		},
+		{
+			name:            "removing a member from an healthy etcd cluster should pass",
+			kcp:             kcpWithReplicas(3),
+			machines:        []*clusterv1.Machine{m1, m2, m3},
+			etcdMembers:     []string{"m1", "m2", "m3"},
+			machineToDelete: m1,
+			expectResult:    ctrl.Result{},
+		},
+		{
+			name:            "removing a member from an etcd cluster with an unhealthy member should requeue",
+			kcp:             kcpWithReplicas(3),
+			machines:        []*clusterv1.Machine{m1, m2, unhealthy},
+			etcdMembers:     []string{"m1", "m2", "unhealthy"},
+			machineToDelete: m1,
+			expectResult:    ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
+		{
+			name:            "removing the unhealthy member from an etcd cluster should pass",
+			kcp:             kcpWithReplicas(3),
+			machines:        []*clusterv1.Machine{m1, m2, unhealthy},
+			etcdMembers:     []string{"m1", "m2", "unhealthy"},
+			machineToDelete: unhealthy,
+			expectResult:    ctrl.Result{},
+		},
+		{
+			name:         "adding a member to an healthy etcd cluster should pass",
+			kcp:          kcpWithReplicas(3),
+			machines:     []*clusterv1.Machine{m1, m2},
+			etcdMembers:  []string{"m1", "m2"},
+			expectResult: ctrl.Result{},
+		},
+		{
+			name:         "adding a member to an etcd cluster with an unhealthy member should requeue",
+			kcp:          kcpWithReplicas(3),
+			machines:     []*clusterv1.Machine{m1, unhealthy},
+			etcdMembers:  []string{"m1", "unhealthy"},
+			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
+		{
+			name:         "adding the second member to an etcd cluster should pass",
+			kcp:          kcpWithReplicas(3),
+			machines:     []*clusterv1.Machine{m1},
+			etcdMembers:  []string{"m1"},
+			expectResult: ctrl.Result{},
+		},
+		{
+			name:         "adding a member to an etcd cluster scaling up to more replicas should pass",
+			kcp:          kcpWithReplicas(5),
+			machines:     []*clusterv1.Machine{m1, m2},
+			etcdMembers:  []string{"m1", "m2"},
+			expectResult: ctrl.Result{},
+		},
+		{
+			name:         "adding a member to an etcd cluster with a member without machine should requeue",
+			kcp:          kcpWithReplicas(3),
+			machines:     []*clusterv1.Machine{m1, m2},
+			etcdMembers:  []string{"m1", "m2", "removed"},
+			expectResult: ctrl.Result{RequeueAfter: preflightFailedRequeueAfter},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &KubeadmControlPlaneReconciler{
+				recorder: record.NewFakeRecorder(32),
+				managementCluster: &fakeManagementCluster{
+					Workload: fakeWorkloadCluster{
+						EtcdMembersResult: tt.etcdMembers,
+					},
+				},
+			}
+			controlPlane := &internal.ControlPlane{
+				Cluster:  &clusterv1.Cluster{},
+				KCP:      tt.kcp,
+				Machines: collections.FromMachines(tt.machines...),
+			}
+			result, err := r.etcdQuorumChecks(context.TODO(), controlPlane, tt.machineToDelete)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result).To(Equal(tt.expectResult))
+		})
+	}
+}
+
 func TestPreflightCheckCondition(t *testing.T) {
 	condition := clusterv1.ConditionType("fooCondition")
 	testCases := []struct {
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// inPlaceUpgradeRequeueAfter is the time to wait before checking again the status of an in-place upgrade.
+const inPlaceUpgradeRequeueAfter = 15 * time.Second
+
+// upgradeControlPlaneInPlace upgrades the control plane components of the given machines in place, one machine at a time,
+// by asking the node agent running on the machine's node to do it and waiting for the result.
+// Machines whose in-place upgrade fails or times out are then rolled out by replacing them.
+//
+// NOTE: this func assumes the kubeadm config map has already been updated with the KCP ClusterConfiguration.
+func (r *KubeadmControlPlaneReconciler) upgradeControlPlaneInPlace(ctx context.Context, controlPlane *internal.ControlPlane, workloadCluster internal.WorkloadCluster, machines collections.Machines) (ctrl.Result, error) {
+	logger := controlPlane.Logger()
+	kcp := controlPlane.KCP
+
+	conditions.MarkFalse(kcp, controlplanev1.MachinesSpecUpToDateCondition, controlplanev1.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityWarning, "Upgrading %d replicas in place", len(machines))
+
+	hash, err := internal.InPlaceUpgradeHash(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Continue with the in-place upgrade in progress, if any, otherwise start from the oldest machine.
+	machine := machines.Filter(isInPlaceUpgradeInProgress).Oldest()
+	if machine == nil {
+		machine = machines.Oldest()
+	}
+	logger = logger.WithValues("machine", machine.Name, "node", machine.Status.NodeRef.Name)
+
+	status, message, err := workloadCluster.GetInPlaceUpgradeStatus(ctx, machine.Status.NodeRef.Name, hash)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch status {
+	case internal.InPlaceUpgradeNotRequested:
+		if result, err := r.preflightChecks(ctx, controlPlane); err != nil || !result.IsZero() {
+			return result, err
+		}
+
+		logger.Info("Requesting in-place upgrade of the control plane components")
+		if err := workloadCluster.RequestInPlaceUpgrade(ctx, machine.Status.NodeRef.Name, hash); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Reset the condition, so the timeout is computed from the time of this request.
+		conditions.Delete(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)
+		conditions.MarkFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition, controlplanev1.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "")
+		r.recorder.Eventf(kcp, corev1.EventTypeNormal, "InPlaceUpgradeRequested", "Requested in-place upgrade of Machine %q", machine.Name)
+	case internal.InPlaceUpgradeInProgress:
+		// The upgrade has been requested but the machine does not record it, e.g. because patching the machine
+		// failed after the request; record it now, so the timeout starts.
+		if !isInPlaceUpgradeInProgress(machine) {
+			logger.Info("In-place upgrade of the control plane components in progress, but not recorded on the machine")
+			conditions.Delete(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)
+			conditions.MarkFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition, controlplanev1.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "")
+			break
+		}
+
+		timeout := controlplanev1.DefaultInPlaceUpgradeTimeout.Duration
+		if kcp.Spec.RolloutStrategy.InPlaceUpgrade.Timeout != nil {
+			timeout = kcp.Spec.RolloutStrategy.InPlaceUpgrade.Timeout.Duration
+		}
+		condition := conditions.Get(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)
+		if time.Since(condition.LastTransitionTime.Time) <= timeout {
+			logger.Info("Waiting for the in-place upgrade of the control plane components to complete")
+			return ctrl.Result{RequeueAfter: inPlaceUpgradeRequeueAfter}, nil
+		}
+
+		logger.Info("In-place upgrade of the control plane components timed out, the machine will be replaced", "timeout", timeout)
+		conditions.MarkFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition, controlplanev1.InPlaceUpgradeFailedReason, clusterv1.ConditionSeverityWarning, "In-place upgrade did not complete within %s", timeout)
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "InPlaceUpgradeFailed", "In-place upgrade of Machine %q timed out", machine.Name)
+	case internal.InPlaceUpgradeFailed:
+		logger.Info("In-place upgrade of the control plane components failed, the machine will be replaced", "message", message)
+		conditions.MarkFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition, controlplanev1.InPlaceUpgradeFailedReason, clusterv1.ConditionSeverityWarning, message)
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "InPlaceUpgradeFailed", "In-place upgrade of Machine %q failed: %s", machine.Name, message)
+	case internal.InPlaceUpgradeCompleted:
+		logger.Info("In-place upgrade of the control plane components completed")
+		// Store the ClusterConfiguration the machine has been upgraded to, so it is no longer considered outdated.
+		clusterConfig, err := json.Marshal(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to marshal cluster configuration")
+		}
+		annotations := machine.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[controlplanev1.KubeadmClusterConfigurationAnnotation] = string(clusterConfig)
+		machine.SetAnnotations(annotations)
+		conditions.MarkTrue(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)
+		r.recorder.Eventf(kcp, corev1.EventTypeNormal, "SuccessfulInPlaceUpgrade", "Upgraded Machine %q in place", machine.Name)
+	}
+
+	if err := controlPlane.PatchMachines(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: inPlaceUpgradeRequeueAfter}, nil
+}
+
+// isInPlaceUpgradeInProgress returns true if the in-place upgrade of the machine's control plane components has been
+// requested and not completed yet.
+func isInPlaceUpgradeInProgress(machine *clusterv1.Machine) bool {
+	return conditions.IsFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition) &&
+		conditions.GetReason(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition) == controlplanev1.InPlaceUpgradeInProgressReason
+}
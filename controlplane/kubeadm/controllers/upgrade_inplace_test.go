@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneInPlace(t *testing.T) {
+	tests := []struct {
+		name                  string
+		status                internal.InPlaceUpgradeStatus
+		requestedAgo          time.Duration
+		expectRequest         bool
+		expectTimeoutStarted  bool
+		expectedConditionTrue bool
+		expectedReason        string
+	}{
+		{
+			name:           "requests the in-place upgrade",
+			status:         internal.InPlaceUpgradeNotRequested,
+			expectRequest:  true,
+			expectedReason: controlplanev1.InPlaceUpgradeInProgressReason,
+		},
+		{
+			name:           "waits for the in-place upgrade to complete",
+			status:         internal.InPlaceUpgradeInProgress,
+			requestedAgo:   time.Minute,
+			expectedReason: controlplanev1.InPlaceUpgradeInProgressReason,
+		},
+		{
+			name:                 "starts the timeout of an in-place upgrade not recorded on the machine",
+			status:               internal.InPlaceUpgradeInProgress,
+			expectTimeoutStarted: true,
+			expectedReason:       controlplanev1.InPlaceUpgradeInProgressReason,
+		},
+		{
+			name:           "fails the in-place upgrade after the timeout",
+			status:         internal.InPlaceUpgradeInProgress,
+			requestedAgo:   time.Hour,
+			expectedReason: controlplanev1.InPlaceUpgradeFailedReason,
+		},
+		{
+			name:           "reports the in-place upgrade failure",
+			status:         internal.InPlaceUpgradeFailed,
+			requestedAgo:   time.Minute,
+			expectedReason: controlplanev1.InPlaceUpgradeFailedReason,
+		},
+		{
+			name:                  "completes the in-place upgrade",
+			status:                internal.InPlaceUpgradeCompleted,
+			requestedAgo:          time.Minute,
+			expectedConditionTrue: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster, kcp, _ := createClusterWithControlPlane(metav1.NamespaceDefault)
+			kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+				APIServer: bootstrapv1.APIServer{
+					ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{ExtraArgs: map[string]string{"foo": "bar"}},
+				},
+			}
+			kcp.Spec.RolloutStrategy.InPlaceUpgrade = &controlplanev1.InPlaceUpgrade{Timeout: &metav1.Duration{Duration: 10 * time.Minute}}
+
+			machine, _ := createMachineNodePair("machine", cluster, kcp, true)
+			setMachineHealthy(machine)
+			if tt.requestedAgo > 0 {
+				machine.Status.Conditions = append(machine.Status.Conditions, clusterv1.Condition{
+					Type:               controlplanev1.MachineInPlaceUpgradeSucceededCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           clusterv1.ConditionSeverityInfo,
+					Reason:             controlplanev1.InPlaceUpgradeInProgressReason,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-tt.requestedAgo)),
+				})
+			}
+			fakeClient := newFakeClient(cluster.DeepCopy(), kcp.DeepCopy(), machine.DeepCopy())
+
+			controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, collections.FromMachines(machine))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			requests := []string{}
+			workloadCluster := fakeWorkloadCluster{
+				InPlaceUpgradeStatus:   tt.status,
+				InPlaceUpgradeMessage:  "kubeadm upgrade node failed",
+				InPlaceUpgradeRequests: &requests,
+			}
+			r := &KubeadmControlPlaneReconciler{
+				Client:   fakeClient,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			result, err := r.upgradeControlPlaneInPlace(ctx, controlPlane, workloadCluster, collections.FromMachines(machine))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(inPlaceUpgradeRequeueAfter))
+			g.Expect(conditions.GetReason(kcp, controlplanev1.MachinesSpecUpToDateCondition)).To(Equal(controlplanev1.InPlaceUpgradeInProgressReason))
+			if tt.expectRequest {
+				g.Expect(requests).To(ConsistOf("machine"))
+			} else {
+				g.Expect(requests).To(BeEmpty())
+			}
+
+			updatedMachine := &clusterv1.Machine{}
+			g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(machine), updatedMachine)).To(Succeed())
+			if tt.expectedConditionTrue {
+				g.Expect(conditions.IsTrue(updatedMachine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)).To(BeTrue())
+				clusterConfig, err := json.Marshal(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue(controlplanev1.KubeadmClusterConfigurationAnnotation, string(clusterConfig)))
+				return
+			}
+			g.Expect(conditions.IsFalse(updatedMachine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)).To(BeTrue())
+			g.Expect(conditions.GetReason(updatedMachine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)).To(Equal(tt.expectedReason))
+			if tt.expectTimeoutStarted {
+				condition := conditions.Get(updatedMachine, controlplanev1.MachineInPlaceUpgradeSucceededCondition)
+				g.Expect(condition.LastTransitionTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			}
+		})
+	}
+}
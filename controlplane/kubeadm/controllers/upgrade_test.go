@@ -196,6 +196,8 @@ func TestKubeadmControlPlaneReconciler_RolloutStrategy_ScaleDown(t *testing.T) {
 	g.Expect(machineList.Items).To(HaveLen(3))
 	for i := range machineList.Items {
 		setMachineHealthy(&machineList.Items[i])
+		machineList.Items[i].Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: machineList.Items[i].Name}
+		fmc.Workload.EtcdMembersResult = append(fmc.Workload.EtcdMembersResult, machineList.Items[i].Name)
 	}
 
 	// change the KCP spec so the machine becomes outdated
@@ -150,6 +150,7 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 		Client:              c,
 		CoreDNSMigrator:     &CoreDNSMigrator{},
 		etcdClientGenerator: NewEtcdClientGenerator(restConfig, tlsConfig),
+		restConfig:          restConfig,
 	}, nil
 }
 
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/failuredomains"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -57,6 +58,9 @@ type ControlPlane struct {
 	// See discussion on https://github.com/kubernetes-sigs/cluster-api/pull/3405
 	kubeadmConfigs map[string]*bootstrapv1.KubeadmConfig
 	infraResources map[string]*unstructured.Unstructured
+
+	// externalEtcdCluster is the etcd cluster referenced by the KubeadmControlPlane, if any.
+	externalEtcdCluster *unstructured.Unstructured
 }
 
 // NewControlPlane returns an instantiated ControlPlane.
@@ -69,6 +73,10 @@ func NewControlPlane(ctx context.Context, client client.Client, cluster *cluster
 	if err != nil {
 		return nil, err
 	}
+	externalEtcdCluster, err := getExternalEtcdCluster(ctx, client, kcp)
+	if err != nil {
+		return nil, err
+	}
 	patchHelpers := map[string]*patch.Helper{}
 	for _, machine := range ownedMachines {
 		patchHelper, err := patch.NewHelper(machine, client)
@@ -85,6 +93,7 @@ func NewControlPlane(ctx context.Context, client client.Client, cluster *cluster
 		machinesPatchHelpers: patchHelpers,
 		kubeadmConfigs:       kubeadmConfigs,
 		infraResources:       infraObjects,
+		externalEtcdCluster:  externalEtcdCluster,
 		reconciliationTime:   metav1.Now(),
 	}, nil
 }
@@ -178,6 +187,7 @@ func (c *ControlPlane) NextFailureDomainForScaleUp() *string {
 func (c *ControlPlane) InitialControlPlaneConfig() *bootstrapv1.KubeadmConfigSpec {
 	bootstrapSpec := c.KCP.Spec.KubeadmConfigSpec.DeepCopy()
 	bootstrapSpec.JoinConfiguration = nil
+	c.setExternalEtcdClusterEndpoints(bootstrapSpec)
 	return bootstrapSpec
 }
 
@@ -188,6 +198,7 @@ func (c *ControlPlane) JoinControlPlaneConfig() *bootstrapv1.KubeadmConfigSpec {
 	// NOTE: For the joining we are preserving the ClusterConfiguration in order to determine if the
 	// cluster is using an external etcd in the kubeadm bootstrap provider (even if this is not required by kubeadm Join).
 	// TODO: Determine if this copy of cluster configuration can be used for rollouts (thus allowing to remove the annotation at machine level)
+	c.setExternalEtcdClusterEndpoints(bootstrapSpec)
 	return bootstrapSpec
 }
 
@@ -262,17 +273,49 @@ func (c *ControlPlane) MachinesNeedingRollout() collections.Machines {
 	return machines.AnyFilter(
 		// Machines that are scheduled for rollout (KCP.Spec.RolloutAfter set, the RolloutAfter deadline is expired, and the machine was created before the deadline).
 		collections.ShouldRolloutAfter(&c.reconciliationTime, c.KCP.Spec.RolloutAfter),
+		// Machines whose certificates are about to expire.
+		ShouldRolloutBefore(&c.reconciliationTime, c.KCP.Spec.RolloutBefore),
 		// Machines that do not match with KCP config.
 		collections.Not(MatchesMachineSpec(c.infraResources, c.kubeadmConfigs, c.KCP)),
 	)
 }
 
+// MachinesToUpgradeInPlace returns the machines, among the given machines needing rollout, whose control plane components
+// can be upgraded in place instead of replacing them; it is empty if in-place upgrades are not enabled.
+func (c *ControlPlane) MachinesToUpgradeInPlace(machines collections.Machines) collections.Machines {
+	if c.KCP.Spec.RolloutStrategy == nil || c.KCP.Spec.RolloutStrategy.InPlaceUpgrade == nil {
+		return collections.New()
+	}
+
+	return machines.Filter(
+		// Machines that are scheduled for rollout must be replaced.
+		collections.Not(collections.ShouldRolloutAfter(&c.reconciliationTime, c.KCP.Spec.RolloutAfter)),
+		collections.Not(ShouldRolloutBefore(&c.reconciliationTime, c.KCP.Spec.RolloutBefore)),
+		// Machines without a node can't be upgraded in place.
+		func(machine *clusterv1.Machine) bool {
+			return machine.Status.NodeRef != nil
+		},
+		// Machines which failed an in-place upgrade must be replaced.
+		collections.Not(HasFailedInPlaceUpgrade),
+		// Machines that match with KCP config, except for the fields which can be upgraded in place.
+		MatchesMachineSpecExceptInPlaceUpgradableFields(c.infraResources, c.kubeadmConfigs, c.KCP),
+	)
+}
+
+// HasFailedInPlaceUpgrade returns true if the in-place upgrade of the machine's control plane components failed.
+func HasFailedInPlaceUpgrade(machine *clusterv1.Machine) bool {
+	return conditions.IsFalse(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition) &&
+		conditions.GetReason(machine, controlplanev1.MachineInPlaceUpgradeSucceededCondition) == controlplanev1.InPlaceUpgradeFailedReason
+}
+
 // UpToDateMachines returns the machines that are up to date with the control
 // plane's configuration and therefore do not require rollout.
 func (c *ControlPlane) UpToDateMachines() collections.Machines {
 	return c.Machines.Filter(
 		// Machines that shouldn't be rolled out after the deadline has expired.
 		collections.Not(collections.ShouldRolloutAfter(&c.reconciliationTime, c.KCP.Spec.RolloutAfter)),
+		// Machines whose certificates are not about to expire.
+		collections.Not(ShouldRolloutBefore(&c.reconciliationTime, c.KCP.Spec.RolloutBefore)),
 		// Machines that match with KCP config.
 		MatchesMachineSpec(c.infraResources, c.kubeadmConfigs, c.KCP),
 	)
@@ -314,6 +357,12 @@ func getKubeadmConfigs(ctx context.Context, cl client.Client, machines collectio
 	return result, nil
 }
 
+// GetKubeadmConfig returns the KubeadmConfig of a given machine.
+func (c *ControlPlane) GetKubeadmConfig(machineName string) (*bootstrapv1.KubeadmConfig, bool) {
+	kubeadmConfig, ok := c.kubeadmConfigs[machineName]
+	return kubeadmConfig, ok
+}
+
 // IsEtcdManaged returns true if the control plane relies on a managed etcd.
 func (c *ControlPlane) IsEtcdManaged() bool {
 	return c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration == nil || c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External == nil
@@ -346,6 +395,7 @@ func (c *ControlPlane) PatchMachines(ctx context.Context) error {
 				controlplanev1.MachineSchedulerPodHealthyCondition,
 				controlplanev1.MachineEtcdPodHealthyCondition,
 				controlplanev1.MachineEtcdMemberHealthyCondition,
+				controlplanev1.MachineInPlaceUpgradeSucceededCondition,
 			}}); err != nil {
 				errList = append(errList, errors.Wrapf(err, "failed to patch machine %s", machine.Name))
 			}
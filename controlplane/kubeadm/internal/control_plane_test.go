@@ -122,6 +122,51 @@ func TestHasUnhealthyMachine(t *testing.T) {
 	g.Expect(c.HasUnhealthyMachine()).To(BeTrue())
 }
 
+func TestMachinesToUpgradeInPlace(t *testing.T) {
+	kcp := &controlplanev1.KubeadmControlPlane{
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.22.0",
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+					APIServer: bootstrapv1.APIServer{
+						ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{ExtraArgs: map[string]string{"foo": "bar"}},
+					},
+				},
+			},
+			RolloutStrategy: &controlplanev1.RolloutStrategy{InPlaceUpgrade: &controlplanev1.InPlaceUpgrade{}},
+		},
+	}
+	// The machines have been created before the api server extra args have been added.
+	withOutdatedClusterConfiguration := func(m *clusterv1.Machine) {
+		m.Spec.Version = pointer.StringPtr("v1.22.0")
+		m.SetAnnotations(map[string]string{controlplanev1.KubeadmClusterConfigurationAnnotation: "{}"})
+	}
+	withNodeRef := func(m *clusterv1.Machine) {
+		m.Status.NodeRef = &corev1.ObjectReference{Name: m.Name}
+	}
+	withFailedInPlaceUpgrade := func(m *clusterv1.Machine) {
+		conditions.MarkFalse(m, controlplanev1.MachineInPlaceUpgradeSucceededCondition, controlplanev1.InPlaceUpgradeFailedReason, clusterv1.ConditionSeverityWarning, "")
+	}
+	withOutdatedVersion := func(m *clusterv1.Machine) {
+		m.Spec.Version = pointer.StringPtr("v1.21.0")
+	}
+
+	machines := collections.FromMachines(
+		machine("upgradable", withOutdatedClusterConfiguration, withNodeRef),
+		machine("without-node", withOutdatedClusterConfiguration),
+		machine("failed", withOutdatedClusterConfiguration, withNodeRef, withFailedInPlaceUpgrade),
+		machine("outdated-version", withOutdatedClusterConfiguration, withNodeRef, withOutdatedVersion),
+	)
+	c := ControlPlane{KCP: kcp, Machines: machines}
+
+	g := NewWithT(t)
+	g.Expect(c.MachinesToUpgradeInPlace(machines).Names()).To(ConsistOf("upgradable"))
+
+	c.KCP = kcp.DeepCopy()
+	c.KCP.Spec.RolloutStrategy.InPlaceUpgrade = nil
+	g.Expect(c.MachinesToUpgradeInPlace(machines)).To(BeEmpty())
+}
+
 type machineOpt func(*clusterv1.Machine)
 
 func failureDomain(controlPlane bool) clusterv1.FailureDomainSpec {
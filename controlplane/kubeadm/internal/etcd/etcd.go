@@ -19,6 +19,7 @@ package etcd
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net"
 	"time"
 
@@ -45,6 +46,7 @@ type etcd interface {
 	MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error)
 	MemberUpdate(ctx context.Context, id uint64, peerURLs []string) (*clientv3.MemberUpdateResponse, error)
 	MoveLeader(ctx context.Context, id uint64) (*clientv3.MoveLeaderResponse, error)
+	Snapshot(ctx context.Context) (io.ReadCloser, error)
 	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
 }
 
@@ -227,6 +229,20 @@ func (c *Client) UpdateMemberPeerURLs(ctx context.Context, id uint64, peerURLs [
 	return members, nil
 }
 
+// Snapshot streams a snapshot of the etcd backend database to the given writer.
+func (c *Client) Snapshot(ctx context.Context, w io.Writer) error {
+	rc, err := c.EtcdClient.Snapshot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to take etcd snapshot")
+	}
+	defer rc.Close()
+
+	if _, err := io.Copy(w, rc); err != nil {
+		return errors.Wrap(err, "failed to read etcd snapshot")
+	}
+	return nil
+}
+
 // Alarms retrieves all alarms on a cluster.
 func (c *Client) Alarms(ctx context.Context) ([]MemberAlarm, error) {
 	alarmResponse, err := c.EtcdClient.AlarmList(ctx)
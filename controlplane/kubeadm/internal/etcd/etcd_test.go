@@ -17,6 +17,7 @@ limitations under the License.
 package etcd
 
 import (
+	"bytes"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -61,6 +62,9 @@ func TestEtcdMembers_WithErrors(t *testing.T) {
 
 	err = client.RemoveMember(ctx, 1234)
 	g.Expect(err).To(HaveOccurred())
+
+	err = client.Snapshot(ctx, &bytes.Buffer{})
+	g.Expect(err).To(HaveOccurred())
 }
 
 func TestEtcdMembers_WithSuccess(t *testing.T) {
@@ -84,6 +88,7 @@ func TestEtcdMembers_WithSuccess(t *testing.T) {
 		MemberRemoveResponse: &clientv3.MemberRemoveResponse{},
 		AlarmResponse:        &clientv3.AlarmResponse{},
 		StatusResponse:       &clientv3.StatusResponse{},
+		SnapshotResponse:     []byte("snapshot"),
 	}
 
 	client, err := newEtcdClient(ctx, fakeEtcdClient)
@@ -103,4 +108,9 @@ func TestEtcdMembers_WithSuccess(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(len(updatedMembers[0].PeerURLs)).To(Equal(2))
 	g.Expect(updatedMembers[0].PeerURLs).To(Equal([]string{"https://1.2.3.4:2000", "https://4.5.6.7:2000"}))
+
+	snapshot := &bytes.Buffer{}
+	err = client.Snapshot(ctx, snapshot)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(snapshot.String()).To(Equal("snapshot"))
 }
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdbackup implements the storage of the etcd snapshots taken by the KubeadmControlPlane,
+// and the bootstrap configuration used to restore them.
+package etcdbackup
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// snapshotTimeFormat is the format of the time suffix of the snapshot names.
+// NOTE: snapshot names sort in the same order as the time they have been taken at.
+const snapshotTimeFormat = "20060102150405"
+
+// Store stores the gzipped etcd snapshots of a KubeadmControlPlane.
+type Store interface {
+	// Save stores the gzipped snapshot read from r with the given name; the snapshot is streamed to the
+	// store, so it is never held in memory as a whole. Nothing is stored if reading from r fails.
+	Save(ctx context.Context, name string, r io.Reader) error
+
+	// List returns the names of the snapshots of the KubeadmControlPlane, oldest first.
+	List(ctx context.Context) ([]string, error)
+
+	// Delete deletes the snapshot with the given name.
+	Delete(ctx context.Context, name string) error
+
+	// RestoreFiles returns the files which allow the restore script to download the snapshot with the
+	// given name at boot, see InjectRestore.
+	RestoreFiles(ctx context.Context, name string) ([]bootstrapv1.File, error)
+}
+
+// NewStore returns the Store for the backup target of the KubeadmControlPlane.
+func NewStore(ctx context.Context, c client.Client, kcp *controlplanev1.KubeadmControlPlane) (Store, error) {
+	if kcp.Spec.EtcdBackup == nil {
+		return nil, errors.New("etcd backups are not configured")
+	}
+
+	if kcp.Spec.EtcdBackup.Target.S3 == nil {
+		return nil, errors.New("etcd backup target is not configured")
+	}
+	return newS3Store(ctx, c, kcp)
+}
+
+// SnapshotName returns the name of a snapshot of the KubeadmControlPlane taken at the given time.
+func SnapshotName(kcp *controlplanev1.KubeadmControlPlane, t time.Time) string {
+	return kcp.Name + "-etcd-" + t.UTC().Format(snapshotTimeFormat)
+}
+
+// isSnapshotOf returns true if name is the name of a snapshot of the KubeadmControlPlane.
+func isSnapshotOf(kcp *controlplanev1.KubeadmControlPlane, name string) bool {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(kcp.Name) + `-etcd-[0-9]{14}$`).MatchString(name)
+}
+
+// Prune deletes the oldest snapshots of the KubeadmControlPlane, keeping at most the given number of snapshots.
+func Prune(ctx context.Context, store Store, retention int) error {
+	names, err := store.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list etcd snapshots")
+	}
+	if len(names) <= retention {
+		return nil
+	}
+
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names[:len(names)-retention] {
+		if err := store.Delete(ctx, name); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete etcd snapshot %s", name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+func TestSnapshotName(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "cp"}}
+	name := SnapshotName(kcp, time.Date(2021, 10, 18, 4, 5, 6, 0, time.UTC))
+	g.Expect(name).To(Equal("cp-etcd-20211018040506"))
+	g.Expect(isSnapshotOf(kcp, name)).To(BeTrue())
+
+	other := &controlplanev1.KubeadmControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "cp-etcd"}}
+	g.Expect(isSnapshotOf(other, name)).To(BeFalse())
+	g.Expect(isSnapshotOf(kcp, SnapshotName(other, time.Now()))).To(BeFalse())
+	g.Expect(isSnapshotOf(kcp, "cp-etcd-latest")).To(BeFalse())
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots []string
+		retention int
+		expected  []string
+	}{
+		{
+			name:      "keeps all the snapshots within the retention",
+			snapshots: []string{"cp-etcd-20211018000000", "cp-etcd-20211018010000"},
+			retention: 3,
+			expected:  []string{"cp-etcd-20211018000000", "cp-etcd-20211018010000"},
+		},
+		{
+			name:      "deletes the oldest snapshots beyond the retention",
+			snapshots: []string{"cp-etcd-20211018020000", "cp-etcd-20211018000000", "cp-etcd-20211018010000", "cp-etcd-20211017230000"},
+			retention: 2,
+			expected:  []string{"cp-etcd-20211018010000", "cp-etcd-20211018020000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := &fakeStore{snapshots: map[string][]byte{}}
+			for _, name := range tt.snapshots {
+				store.snapshots[name] = []byte(name)
+			}
+
+			g.Expect(Prune(context.Background(), store, tt.retention)).To(Succeed())
+			g.Expect(store.List(context.Background())).To(Equal(tt.expected))
+		})
+	}
+}
+
+// fakeStore is an in-memory Store.
+type fakeStore struct {
+	snapshots map[string][]byte
+}
+
+func (s *fakeStore) Save(_ context.Context, name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.snapshots[name] = data
+	return nil
+}
+
+func (s *fakeStore) List(_ context.Context) ([]string, error) {
+	var names []string
+	for name := range s.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *fakeStore) Delete(_ context.Context, name string) error {
+	delete(s.snapshots, name)
+	return nil
+}
+
+func (s *fakeStore) RestoreFiles(_ context.Context, _ string) ([]bootstrapv1.File, error) {
+	return nil, nil
+}
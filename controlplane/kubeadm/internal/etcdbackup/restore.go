@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+)
+
+const (
+	// restoreDir is the directory holding the snapshot to restore and the restore script on the first control plane machine.
+	restoreDir = "/etc/kubernetes/etcd-restore/"
+
+	// restoreSnapshotURLPath is the path of a file containing the URL to download the gzipped snapshot to restore from.
+	restoreSnapshotURLPath = restoreDir + "snapshot.url"
+
+	// restoreScriptPath is the path of the restore script.
+	restoreScriptPath = restoreDir + "restore.sh"
+
+	// restoreCommand runs the restore script before kubeadm init.
+	restoreCommand = "sh " + restoreScriptPath
+
+	// etcdDataDirPreflightError is the kubeadm preflight check failing when the etcd data directory is not empty,
+	// which is expected once the snapshot has been restored.
+	etcdDataDirPreflightError = "DirAvailable--var-lib-etcd"
+)
+
+// restoreScript downloads the snapshot at boot and restores it into the etcd data directory, before kubeadm init
+// starts etcd; the snapshot is never part of the bootstrap data, which is limited in size by most infrastructure
+// providers.
+// The data directory is restored as the single member of a new etcd cluster, named after the machine and advertising
+// the address etcd will advertise: the advertise address of the kubeadm InitConfiguration or, if it is not set, the
+// address of the interface of the default route, like kubeadm does. The etcdctl binary of the etcd image of the control
+// plane is run with ctr, so no additional binary is required on the machine, but only containerd is supported.
+// NOTE: the script is a no-op if the data directory already exists, e.g. when cloud-init runs again.
+const restoreScript = `#!/bin/sh
+set -eu
+
+RESTORE_DIR=/etc/kubernetes/etcd-restore
+DATA_DIR=/var/lib/etcd
+KUBEADM_CONFIG=/run/kubeadm/kubeadm.yaml
+
+if [ -d "${DATA_DIR}/member" ]; then
+  echo "etcd data directory ${DATA_DIR} already exists, skipping the etcd snapshot restore"
+  exit 0
+fi
+
+if ! command -v ctr >/dev/null 2>&1; then
+  echo "ctr not found, restoring an etcd snapshot requires containerd" >&2
+  exit 1
+fi
+
+NAME="$(hostname)"
+IP="$(sed -n 's/^ *advertiseAddress: *//p' "${KUBEADM_CONFIG}" | tr -d "\"' " | head -n 1)"
+if [ -z "${IP}" ]; then
+  DEV="$(ip route show default | sed -n 's/.* dev \([^ ]*\).*/\1/p' | head -n 1)"
+  IP="$(ip addr show dev "${DEV}" scope global | sed -n 's/.* inet6\{0,1\} \([^/]*\)\/.*/\1/p' | head -n 1)"
+fi
+if [ -z "${IP}" ]; then
+  echo "failed to detect the etcd advertise address" >&2
+  exit 1
+fi
+case "${IP}" in
+  *:*) PEER_URL="https://[${IP}]:2380" ;;
+  *) PEER_URL="https://${IP}:2380" ;;
+esac
+ETCD_IMAGE="$(kubeadm config images list --config "${KUBEADM_CONFIG}" 2>/dev/null | grep /etcd)"
+
+curl -fsSL --retry 5 "$(cat "${RESTORE_DIR}/snapshot.url")" | gunzip > "${RESTORE_DIR}/snapshot.db"
+
+kubeadm config images pull --config "${KUBEADM_CONFIG}"
+mkdir -p "$(dirname "${DATA_DIR}")"
+ctr -n k8s.io run --rm --net-host \
+  --mount "type=bind,src=${RESTORE_DIR},dst=${RESTORE_DIR},options=rbind:rw" \
+  --mount "type=bind,src=$(dirname "${DATA_DIR}"),dst=$(dirname "${DATA_DIR}"),options=rbind:rw" \
+  --env ETCDCTL_API=3 \
+  "${ETCD_IMAGE}" etcd-snapshot-restore \
+  etcdctl snapshot restore "${RESTORE_DIR}/snapshot.db" \
+    --data-dir "${DATA_DIR}" \
+    --name "${NAME}" \
+    --initial-cluster "${NAME}=${PEER_URL}" \
+    --initial-advertise-peer-urls "${PEER_URL}"
+
+rm -f "${RESTORE_DIR}/snapshot.db" "${RESTORE_DIR}/snapshot.url"
+`
+
+// InjectRestore adds to the bootstrap configuration of the first control plane machine the given files, which
+// provide the URL of the snapshot to restore, and the script downloading and restoring it before kubeadm init.
+func InjectRestore(spec *bootstrapv1.KubeadmConfigSpec, snapshotFiles []bootstrapv1.File) {
+	files := append([]bootstrapv1.File{}, snapshotFiles...)
+	files = append(files, bootstrapv1.File{
+		Path:        restoreScriptPath,
+		Owner:       "root:root",
+		Permissions: "0700",
+		Content:     restoreScript,
+	})
+	injectRestore(spec, files)
+}
+
+// MatchRestore applies to the bootstrap configuration of the KubeadmControlPlane the changes made by InjectRestore
+// to the bootstrap configuration of a machine, if any, so the two configurations can be compared.
+func MatchRestore(kcpConfig, machineConfig *bootstrapv1.KubeadmConfigSpec) {
+	restored := false
+	for _, c := range machineConfig.PreKubeadmCommands {
+		if c == restoreCommand {
+			restored = true
+			break
+		}
+	}
+	if !restored {
+		return
+	}
+
+	var files []bootstrapv1.File
+	for _, f := range machineConfig.Files {
+		if strings.HasPrefix(f.Path, restoreDir) {
+			files = append(files, f)
+		}
+	}
+	injectRestore(kcpConfig, files)
+}
+
+func injectRestore(spec *bootstrapv1.KubeadmConfigSpec, files []bootstrapv1.File) {
+	spec.Files = append(spec.Files, files...)
+	spec.PreKubeadmCommands = append(spec.PreKubeadmCommands, restoreCommand)
+
+	if spec.InitConfiguration == nil {
+		spec.InitConfiguration = &bootstrapv1.InitConfiguration{}
+	}
+	for _, e := range spec.InitConfiguration.NodeRegistration.IgnorePreflightErrors {
+		if e == etcdDataDirPreflightError {
+			return
+		}
+	}
+	spec.InitConfiguration.NodeRegistration.IgnorePreflightErrors = append(spec.InitConfiguration.NodeRegistration.IgnorePreflightErrors, etcdDataDirPreflightError)
+}
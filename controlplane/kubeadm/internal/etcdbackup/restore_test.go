@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+)
+
+func TestInjectRestore(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &bootstrapv1.KubeadmConfigSpec{
+		Files:              []bootstrapv1.File{{Path: "/etc/foo"}},
+		PreKubeadmCommands: []string{"echo foo"},
+	}
+	snapshotFile := bootstrapv1.File{Path: restoreSnapshotURLPath, Content: "https://example.com/snapshot"}
+
+	InjectRestore(spec, []bootstrapv1.File{snapshotFile})
+
+	g.Expect(spec.Files).To(HaveLen(3))
+	g.Expect(spec.Files[0].Path).To(Equal("/etc/foo"))
+	g.Expect(spec.Files[1]).To(Equal(snapshotFile))
+	g.Expect(spec.Files[2].Path).To(Equal(restoreScriptPath))
+	g.Expect(spec.Files[2].Content).To(ContainSubstring("etcdctl snapshot restore"))
+	g.Expect(spec.PreKubeadmCommands).To(Equal([]string{"echo foo", restoreCommand}))
+	g.Expect(spec.InitConfiguration.NodeRegistration.IgnorePreflightErrors).To(Equal([]string{etcdDataDirPreflightError}))
+}
+
+func TestMatchRestore(t *testing.T) {
+	tests := []struct {
+		name      string
+		kcpConfig *bootstrapv1.KubeadmConfigSpec
+	}{
+		{
+			name:      "without init configuration",
+			kcpConfig: &bootstrapv1.KubeadmConfigSpec{},
+		},
+		{
+			name: "with init configuration ignoring the etcd data directory preflight error",
+			kcpConfig: &bootstrapv1.KubeadmConfigSpec{
+				Files:              []bootstrapv1.File{{Path: "/etc/foo"}},
+				PreKubeadmCommands: []string{"echo foo"},
+				InitConfiguration: &bootstrapv1.InitConfiguration{
+					NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+						IgnorePreflightErrors: []string{etcdDataDirPreflightError},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machineConfig := tt.kcpConfig.DeepCopy()
+			InjectRestore(machineConfig, []bootstrapv1.File{{Path: restoreSnapshotURLPath, Content: "https://example.com/snapshot"}})
+
+			// Machines which are not restoring a snapshot are compared as is.
+			kcpConfig := tt.kcpConfig.DeepCopy()
+			MatchRestore(kcpConfig, tt.kcpConfig.DeepCopy())
+			g.Expect(kcpConfig).To(Equal(tt.kcpConfig))
+
+			MatchRestore(kcpConfig, machineConfig)
+			g.Expect(kcpConfig).To(Equal(machineConfig))
+		})
+	}
+}
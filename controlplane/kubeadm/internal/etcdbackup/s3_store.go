@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// s3AccessKeyIDKey and s3SecretAccessKeyKey are the keys of the credentials Secret.
+	s3AccessKeyIDKey     = "accessKeyID"
+	s3SecretAccessKeyKey = "secretAccessKey"
+
+	// s3SnapshotSuffix is the suffix of the snapshot object keys.
+	s3SnapshotSuffix = ".db.gz"
+
+	// restoreURLExpiry is the validity of the presigned URL used by the first control plane machine to download
+	// the snapshot to restore. The URL is part of the bootstrap data, so it is kept short: the first machine
+	// must boot and download the snapshot within this time, otherwise the restore fails and the machine must
+	// be deleted, so a new one is created with a new URL.
+	restoreURLExpiry = time.Hour
+
+	// s3PartSize is the size of the parts of the multipart uploads streaming the snapshots to S3, so only one
+	// part is held in memory at a time; S3 requires parts of at least 5MiB, except for the last one.
+	s3PartSize = 8 * 1024 * 1024
+)
+
+// s3Store stores the snapshots in a bucket of an S3-compatible object storage, using path-style requests.
+type s3Store struct {
+	kcp      *controlplanev1.KubeadmControlPlane
+	target   *controlplanev1.S3EtcdBackupTarget
+	client   *s3.Client
+	partSize int64
+}
+
+func newS3Store(ctx context.Context, c client.Client, kcp *controlplanev1.KubeadmControlPlane) (*s3Store, error) {
+	target := kcp.Spec.EtcdBackup.Target.S3
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: kcp.Namespace, Name: target.CredentialsSecretName}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get S3 credentials Secret %s", target.CredentialsSecretName)
+	}
+	accessKeyID := string(secret.Data[s3AccessKeyIDKey])
+	secretAccessKey := string(secret.Data[s3SecretAccessKeyKey])
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.Errorf("S3 credentials Secret %s must contain %s and %s", target.CredentialsSecretName, s3AccessKeyIDKey, s3SecretAccessKeyKey)
+	}
+
+	return &s3Store{
+		kcp:    kcp,
+		target: target,
+		client: s3.New(s3.Options{
+			Region:           target.Region,
+			Credentials:      credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, ""),
+			EndpointResolver: s3.EndpointResolverFromURL(target.Endpoint),
+			UsePathStyle:     true,
+		}),
+		partSize: s3PartSize,
+	}, nil
+}
+
+// objectKey returns the key of the object storing the snapshot with the given name.
+func (s *s3Store) objectKey(name string) string {
+	return s.target.Prefix + name + s3SnapshotSuffix
+}
+
+func (s *s3Store) Save(ctx context.Context, name string, r io.Reader) error {
+	// The uploader streams the snapshot using a multipart upload if it is larger than a part, and aborts the
+	// upload if reading the snapshot fails, so S3 deletes the parts uploaded so far.
+	uploader := manager.NewUploader(s.client, func(u *manager.Uploader) {
+		u.PartSize = s.partSize
+		u.Concurrency = 1
+	})
+	if _, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.target.Bucket),
+		Key:    aws.String(s.objectKey(name)),
+		Body:   r,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to save etcd snapshot %s", name)
+	}
+	return nil
+}
+
+func (s *s3Store) List(ctx context.Context) ([]string, error) {
+	var names []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.target.Bucket),
+		Prefix: aws.String(s.target.Prefix + s.kcp.Name + "-etcd-"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list etcd snapshots in bucket %s", s.target.Bucket)
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(object.Key), s.target.Prefix), s3SnapshotSuffix)
+			if isSnapshotOf(s.kcp, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, name string) error {
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.target.Bucket),
+		Key:    aws.String(s.objectKey(name)),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to delete etcd snapshot %s", name)
+	}
+	return nil
+}
+
+func (s *s3Store) RestoreFiles(ctx context.Context, name string) ([]bootstrapv1.File, error) {
+	// Check the snapshot exists, so a missing snapshot is reported before creating the first machine.
+	if _, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.target.Bucket),
+		Key:    aws.String(s.objectKey(name)),
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get etcd snapshot %s from bucket %s", name, s.target.Bucket)
+	}
+
+	presigned, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.target.Bucket),
+		Key:    aws.String(s.objectKey(name)),
+	}, s3.WithPresignExpires(restoreURLExpiry))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to presign the URL of etcd snapshot %s", name)
+	}
+	return []bootstrapv1.File{
+		{
+			Path:        restoreSnapshotURLPath,
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     presigned.URL,
+		},
+	}, nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestS3Store(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := newFakeS3Server("backups")
+	defer server.Close()
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "cp", Namespace: "default"},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			EtcdBackup: &controlplanev1.EtcdBackup{
+				Target: controlplanev1.EtcdBackupTarget{
+					S3: &controlplanev1.S3EtcdBackupTarget{
+						Endpoint:              server.URL,
+						Region:                "us-east-1",
+						Bucket:                "backups",
+						Prefix:                "clusters/",
+						CredentialsSecretName: "s3-credentials",
+					},
+				},
+			},
+		},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			s3AccessKeyIDKey:     []byte("access-key"),
+			s3SecretAccessKeyKey: []byte("secret-key"),
+		},
+	}
+
+	// The credentials Secret is required.
+	_, err := NewStore(ctx, fake.NewClientBuilder().Build(), kcp)
+	g.Expect(err).To(HaveOccurred())
+
+	store, err := NewStore(ctx, fake.NewClientBuilder().WithObjects(credentials).Build(), kcp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(store.Save(ctx, "cp-etcd-20211018000000", strings.NewReader("first"))).To(Succeed())
+	g.Expect(store.Save(ctx, "cp-etcd-20211018010000", strings.NewReader("second"))).To(Succeed())
+	g.Expect(server.objects).To(HaveKeyWithValue("clusters/cp-etcd-20211018000000.db.gz", []byte("first")))
+	for _, auth := range server.authorizations {
+		g.Expect(auth).To(HavePrefix("AWS4-HMAC-SHA256 Credential=access-key/"))
+	}
+
+	// A snapshot of another KubeadmControlPlane with a similar name, which must be ignored.
+	server.objects["clusters/cp-etcd-other-etcd-20211018000000.db.gz"] = []byte("other")
+
+	// Snapshots are listed across multiple pages.
+	g.Expect(store.List(ctx)).To(Equal([]string{"cp-etcd-20211018000000", "cp-etcd-20211018010000"}))
+
+	files, err := store.RestoreFiles(ctx, "cp-etcd-20211018010000")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(1))
+	g.Expect(files[0].Path).To(Equal(restoreSnapshotURLPath))
+	g.Expect(files[0].Content).To(HavePrefix(server.URL + "/backups/clusters/cp-etcd-20211018010000.db.gz?"))
+	g.Expect(files[0].Content).To(ContainSubstring("X-Amz-Algorithm=AWS4-HMAC-SHA256"))
+	g.Expect(files[0].Content).To(ContainSubstring("X-Amz-Expires=3600"))
+
+	_, err = store.RestoreFiles(ctx, "cp-etcd-20211018020000")
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(store.Delete(ctx, "cp-etcd-20211018000000")).To(Succeed())
+	g.Expect(store.List(ctx)).To(Equal([]string{"cp-etcd-20211018010000"}))
+
+	// Snapshots larger than a part are streamed using a multipart upload.
+	store.(*s3Store).partSize = manager.MinUploadPartSize
+	data := bytes.Repeat([]byte("snapshot-data"), int(manager.MinUploadPartSize)/10)
+	g.Expect(store.Save(ctx, "cp-etcd-20211018020000", bytes.NewReader(data))).To(Succeed())
+	g.Expect(server.objects).To(HaveKeyWithValue("clusters/cp-etcd-20211018020000.db.gz", data))
+	g.Expect(server.uploads).To(BeEmpty())
+
+	// The multipart upload is aborted if reading the snapshot fails.
+	err = store.Save(ctx, "cp-etcd-20211018030000", io.MultiReader(bytes.NewReader(data), iotest.ErrReader(errors.New("etcd is unavailable"))))
+	g.Expect(err).To(MatchError(ContainSubstring("etcd is unavailable")))
+	g.Expect(server.objects).NotTo(HaveKey("clusters/cp-etcd-20211018030000.db.gz"))
+	g.Expect(server.uploads).To(BeEmpty())
+
+	// Errors returned by S3 are reported.
+	server.fail = true
+	err = store.Save(ctx, "cp-etcd-20211018030000", strings.NewReader("third"))
+	g.Expect(err).To(MatchError(ContainSubstring("AccessDenied: Access Denied")))
+}
+
+// fakeS3Server implements the subset of the S3 API used by the s3Store, returning one object per list page.
+type fakeS3Server struct {
+	*httptest.Server
+
+	bucket         string
+	lock           sync.Mutex
+	objects        map[string][]byte
+	uploads        map[string]map[int][]byte
+	authorizations []string
+	fail           bool
+}
+
+func newFakeS3Server(bucket string) *fakeS3Server {
+	s := &fakeS3Server{bucket: bucket, objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *fakeS3Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.authorizations = append(s.authorizations, r.Header.Get("Authorization"))
+	if s.fail {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"))
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/"+s.bucket)
+	key := strings.TrimPrefix(path, "/")
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	_, createUpload := query["uploads"]
+	switch {
+	case r.Method == http.MethodPost && key != "" && createUpload:
+		uploadID = fmt.Sprintf("upload-%d", len(s.authorizations))
+		s.uploads[uploadID] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, uploadID)
+	case r.Method == http.MethodPut && key != "" && uploadID != "":
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(r.Body)
+		s.uploads[uploadID][partNumber] = data
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("etag-%d", partNumber)))
+	case r.Method == http.MethodPost && key != "" && uploadID != "":
+		complete := &completeMultipartUpload{}
+		body, _ := io.ReadAll(r.Body)
+		_ = xml.Unmarshal(body, complete)
+		var data []byte
+		for i, part := range complete.Parts {
+			if part.PartNumber != i+1 || part.ETag != fmt.Sprintf("%q", fmt.Sprintf("etag-%d", part.PartNumber)) {
+				_, _ = w.Write([]byte("<Error><Code>InvalidPart</Code><Message>Invalid part</Message></Error>"))
+				return
+			}
+			data = append(data, s.uploads[uploadID][part.PartNumber]...)
+		}
+		s.objects[key] = data
+		delete(s.uploads, uploadID)
+		_, _ = fmt.Fprintf(w, "<CompleteMultipartUploadResult><Key>%s</Key></CompleteMultipartUploadResult>", key)
+	case r.Method == http.MethodDelete && key != "" && uploadID != "":
+		delete(s.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && key != "":
+		data, _ := io.ReadAll(r.Body)
+		s.objects[key] = data
+	case r.Method == http.MethodHead && key != "":
+		if _, ok := s.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodDelete && key != "":
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		var keys []string
+		for k := range s.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		result := listBucketResult{}
+		if len(keys) > 0 {
+			result.Contents = append(result.Contents, struct {
+				Key string `xml:"Key"`
+			}{Key: keys[0]})
+		}
+		if len(keys) > 1 {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[0]
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// completeMultipartUpload is the body of a CompleteMultipartUpload request.
+type completeMultipartUpload struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+// listBucketResult is the response to a ListObjectsV2 request.
+type listBucketResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
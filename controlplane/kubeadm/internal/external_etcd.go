@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getExternalEtcdCluster fetches the etcd cluster referenced by the KubeadmControlPlane, if any.
+// NOTE: a missing etcd cluster is not an error, so it doesn't block e.g. the deletion of the KubeadmControlPlane;
+// instead the control plane reports it as not ready.
+func getExternalEtcdCluster(ctx context.Context, cl client.Client, kcp *controlplanev1.KubeadmControlPlane) (*unstructured.Unstructured, error) {
+	if kcp.Spec.EtcdClusterRef == nil {
+		return nil, nil
+	}
+	obj, err := external.Get(ctx, cl, kcp.Spec.EtcdClusterRef, kcp.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve etcd cluster %s %s", kcp.Spec.EtcdClusterRef.Kind, kcp.Spec.EtcdClusterRef.Name)
+	}
+	return obj, nil
+}
+
+// HasExternalEtcdCluster returns true if the KubeadmControlPlane references an external etcd cluster.
+func (c *ControlPlane) HasExternalEtcdCluster() bool {
+	return c.KCP.Spec.EtcdClusterRef != nil
+}
+
+// IsExternalEtcdClusterReady returns true if the KubeadmControlPlane does not reference an external etcd cluster,
+// or if the referenced etcd cluster exists and reports status.ready.
+func (c *ControlPlane) IsExternalEtcdClusterReady() bool {
+	if !c.HasExternalEtcdCluster() {
+		return true
+	}
+	if c.externalEtcdCluster == nil {
+		return false
+	}
+	ready, err := external.IsReady(c.externalEtcdCluster)
+	return err == nil && ready
+}
+
+// ExternalEtcdClusterEndpoints returns the client endpoints reported in status.endpoints by the external etcd cluster.
+func (c *ControlPlane) ExternalEtcdClusterEndpoints() ([]string, error) {
+	if c.externalEtcdCluster == nil {
+		return nil, nil
+	}
+	endpoints, _, err := unstructured.NestedStringSlice(c.externalEtcdCluster.Object, "status", "endpoints")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get status.endpoints from %s %s", c.externalEtcdCluster.GetKind(), c.externalEtcdCluster.GetName())
+	}
+	return endpoints, nil
+}
+
+// setExternalEtcdClusterEndpoints sets the endpoints reported by the external etcd cluster, if any, in the configuration
+// for a new control plane machine; the endpoints in the KubeadmControlPlane spec are used until the etcd cluster reports them.
+func (c *ControlPlane) setExternalEtcdClusterEndpoints(spec *bootstrapv1.KubeadmConfigSpec) {
+	if spec.ClusterConfiguration == nil || spec.ClusterConfiguration.Etcd.External == nil || !c.IsExternalEtcdClusterReady() {
+		return
+	}
+	// NOTE: errors reading the endpoints are surfaced when reconciling the external etcd endpoints.
+	endpoints, err := c.ExternalEtcdClusterEndpoints()
+	if err != nil || len(endpoints) == 0 {
+		return
+	}
+	spec.ClusterConfiguration.Etcd.External.Endpoints = endpoints
+}
+
+// MachinesWithOutdatedExternalEtcdEndpoints returns the machines configured with external etcd endpoints other than the given ones.
+func (c *ControlPlane) MachinesWithOutdatedExternalEtcdEndpoints(endpoints []string) collections.Machines {
+	return c.Machines.Filter(func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+		kubeadmConfig, ok := c.kubeadmConfigs[machine.Name]
+		if !ok || kubeadmConfig.Spec.ClusterConfiguration == nil || kubeadmConfig.Spec.ClusterConfiguration.Etcd.External == nil {
+			return false
+		}
+		return !reflect.DeepEqual(kubeadmConfig.Spec.ClusterConfiguration.Etcd.External.Endpoints, endpoints)
+	})
+}
+
+// UpdateExternalEtcdClusterCondition sets the EtcdClusterHealthy condition when etcd is not managed by the KubeadmControlPlane.
+// NOTE: this does not require a connection to the workload cluster, so it can be used also before the control plane is initialized.
+func (c *ControlPlane) UpdateExternalEtcdClusterCondition() {
+	// When KCP does not reference the etcd cluster, it is not responsible for external etcd, so we are reporting only health at KCP level.
+	if !c.HasExternalEtcdCluster() {
+		conditions.MarkTrue(c.KCP, controlplanev1.EtcdClusterHealthyCondition)
+		return
+	}
+
+	// Otherwise we are surfacing the health reported by the etcd provider.
+	ref := c.KCP.Spec.EtcdClusterRef
+	if c.externalEtcdCluster == nil {
+		conditions.MarkFalse(c.KCP, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.ExternalEtcdClusterNotReadyReason, clusterv1.ConditionSeverityWarning, "%s %s does not exist", ref.Kind, ref.Name)
+		return
+	}
+
+	if _, failureMessage, err := external.FailuresFrom(c.externalEtcdCluster); err == nil && failureMessage != "" {
+		conditions.MarkFalse(c.KCP, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityError, "%s %s failed: %s", ref.Kind, ref.Name, failureMessage)
+		return
+	}
+
+	if c.IsExternalEtcdClusterReady() {
+		conditions.MarkTrue(c.KCP, controlplanev1.EtcdClusterHealthyCondition)
+		return
+	}
+
+	// If the etcd provider reports why the etcd cluster is not ready using the Ready condition, use it.
+	if ready := conditions.Get(conditions.UnstructuredGetter(c.externalEtcdCluster), clusterv1.ReadyCondition); ready != nil && ready.Status == corev1.ConditionFalse {
+		conditions.MarkFalse(c.KCP, controlplanev1.EtcdClusterHealthyCondition, ready.Reason, ready.Severity, "%s", ready.Message)
+		return
+	}
+	conditions.MarkFalse(c.KCP, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.ExternalEtcdClusterNotReadyReason, clusterv1.ConditionSeverityWarning, "%s %s is not ready", ref.Kind, ref.Name)
+}
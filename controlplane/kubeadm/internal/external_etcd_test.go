@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExternalEtcdCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "cp", Namespace: metav1.NamespaceDefault},
+	}
+	etcdCluster := &unstructured.Unstructured{}
+	etcdCluster.SetAPIVersion("etcd.example.com/v1")
+	etcdCluster.SetKind("EtcdCluster")
+	etcdCluster.SetName("etcd")
+	etcdCluster.SetNamespace(metav1.NamespaceDefault)
+
+	// Without an etcd cluster reference there is nothing to wait for.
+	controlPlane := &ControlPlane{KCP: kcp}
+	g.Expect(controlPlane.HasExternalEtcdCluster()).To(BeFalse())
+	g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(BeTrue())
+
+	// A missing etcd cluster is not ready.
+	kcp.Spec.EtcdClusterRef = &corev1.ObjectReference{APIVersion: "etcd.example.com/v1", Kind: "EtcdCluster", Name: "etcd"}
+	obj, err := getExternalEtcdCluster(ctx, fake.NewClientBuilder().Build(), kcp)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj).To(BeNil())
+	controlPlane = &ControlPlane{KCP: kcp, externalEtcdCluster: obj}
+	g.Expect(controlPlane.HasExternalEtcdCluster()).To(BeTrue())
+	g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(BeFalse())
+
+	// An etcd cluster without status is not ready.
+	obj, err = getExternalEtcdCluster(ctx, fake.NewClientBuilder().WithObjects(etcdCluster.DeepCopy()).Build(), kcp)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj).NotTo(BeNil())
+	controlPlane = &ControlPlane{KCP: kcp, externalEtcdCluster: obj}
+	g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(BeFalse())
+	g.Expect(controlPlane.ExternalEtcdClusterEndpoints()).To(BeEmpty())
+
+	// A ready etcd cluster reports its endpoints.
+	g.Expect(unstructured.SetNestedField(etcdCluster.Object, true, "status", "ready")).To(Succeed())
+	g.Expect(unstructured.SetNestedStringSlice(etcdCluster.Object, []string{"https://etcd-0:2379", "https://etcd-1:2379"}, "status", "endpoints")).To(Succeed())
+	controlPlane = &ControlPlane{KCP: kcp, externalEtcdCluster: etcdCluster}
+	g.Expect(controlPlane.IsExternalEtcdClusterReady()).To(BeTrue())
+	g.Expect(controlPlane.ExternalEtcdClusterEndpoints()).To(Equal([]string{"https://etcd-0:2379", "https://etcd-1:2379"}))
+}
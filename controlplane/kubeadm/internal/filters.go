@@ -19,11 +19,14 @@ package internal
 import (
 	"encoding/json"
 	"reflect"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcdbackup"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -41,6 +44,38 @@ func MatchesMachineSpec(infraConfigs map[string]*unstructured.Unstructured, mach
 	)
 }
 
+// MatchesMachineSpecExceptInPlaceUpgradableFields returns a filter to find all machines that matches with KCP config
+// except for the ClusterConfiguration fields which can be upgraded in place, and thus can be upgraded without
+// being replaced.
+func MatchesMachineSpecExceptInPlaceUpgradableFields(infraConfigs map[string]*unstructured.Unstructured, machineConfigs map[string]*bootstrapv1.KubeadmConfig, kcp *controlplanev1.KubeadmControlPlane) func(machine *clusterv1.Machine) bool {
+	return collections.And(
+		func(machine *clusterv1.Machine) bool {
+			return matchMachineTemplateMetadata(kcp, machine)
+		},
+		collections.MatchesKubernetesVersion(kcp.Spec.Version),
+		func(machine *clusterv1.Machine) bool {
+			return matchClusterConfigurationExceptInPlaceUpgradableFields(kcp, machine)
+		},
+		matchesKubeadmBootstrapConfigExceptClusterConfiguration(machineConfigs, kcp),
+		MatchesTemplateClonedFrom(infraConfigs, kcp),
+	)
+}
+
+// ShouldRolloutBefore returns a filter to find all machines whose
+// certificates will expire within the specified days.
+func ShouldRolloutBefore(reconciliationTime *metav1.Time, rolloutBefore *controlplanev1.RolloutBefore) collections.Func {
+	return func(machine *clusterv1.Machine) bool {
+		if reconciliationTime == nil || rolloutBefore == nil || rolloutBefore.CertificatesExpiryDays == nil {
+			return false
+		}
+		if machine == nil || machine.Status.CertificatesExpiryDate == nil {
+			return false
+		}
+		certsExpiryTime := machine.Status.CertificatesExpiryDate.Time
+		return reconciliationTime.Add(time.Duration(*rolloutBefore.CertificatesExpiryDays) * 24 * time.Hour).After(certsExpiryTime)
+	}
+}
+
 // MatchesTemplateClonedFrom returns a filter to find all machines that match a given KCP infra template.
 func MatchesTemplateClonedFrom(infraConfigs map[string]*unstructured.Unstructured, kcp *controlplanev1.KubeadmControlPlane) collections.Func {
 	return func(machine *clusterv1.Machine) bool {
@@ -88,6 +123,18 @@ func MatchesKubeadmBootstrapConfig(machineConfigs map[string]*bootstrapv1.Kubead
 			return false
 		}
 
+		return matchesKubeadmBootstrapConfigExceptClusterConfiguration(machineConfigs, kcp)(machine)
+	}
+}
+
+// matchesKubeadmBootstrapConfigExceptClusterConfiguration checks if machine's KubeadmConfigSpec, except for
+// the ClusterConfiguration, is equivalent with KCP's KubeadmConfigSpec.
+func matchesKubeadmBootstrapConfigExceptClusterConfiguration(machineConfigs map[string]*bootstrapv1.KubeadmConfig, kcp *controlplanev1.KubeadmControlPlane) collections.Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+
 		bootstrapRef := machine.Spec.Bootstrap.ConfigRef
 		if bootstrapRef == nil {
 			// Missing bootstrap reference should not be considered as unmatching.
@@ -148,6 +195,51 @@ func matchClusterConfiguration(kcp *controlplanev1.KubeadmControlPlane, machine
 	return reflect.DeepEqual(machineClusterConfig, kcpLocalClusterConfiguration)
 }
 
+// matchClusterConfigurationExceptInPlaceUpgradableFields verifies if KCP and machine ClusterConfiguration matches
+// except for the fields which can be upgraded in place, i.e. the fields that are applied to the control plane
+// components by `kubeadm upgrade node` without requiring a new machine.
+// NOTE: Machines without the KubeadmClusterConfigurationAnnotation can't be upgraded in place, given that we don't
+// have enough information about their current configuration.
+func matchClusterConfigurationExceptInPlaceUpgradableFields(kcp *controlplanev1.KubeadmControlPlane, machine *clusterv1.Machine) bool {
+	machineClusterConfigStr, ok := machine.GetAnnotations()[controlplanev1.KubeadmClusterConfigurationAnnotation]
+	if !ok {
+		return false
+	}
+
+	machineClusterConfig := &bootstrapv1.ClusterConfiguration{}
+	if err := json.Unmarshal([]byte(machineClusterConfigStr), &machineClusterConfig); err != nil {
+		return false
+	}
+	if machineClusterConfig == nil {
+		machineClusterConfig = &bootstrapv1.ClusterConfiguration{}
+	}
+	kcpLocalClusterConfiguration := kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.DeepCopy()
+	if kcpLocalClusterConfiguration == nil {
+		kcpLocalClusterConfiguration = &bootstrapv1.ClusterConfiguration{}
+	}
+
+	cleanupInPlaceUpgradableFields(machineClusterConfig)
+	cleanupInPlaceUpgradableFields(kcpLocalClusterConfiguration)
+
+	return reflect.DeepEqual(machineClusterConfig, kcpLocalClusterConfiguration)
+}
+
+// cleanupInPlaceUpgradableFields cleanups the ClusterConfiguration fields which can be upgraded in place.
+func cleanupInPlaceUpgradableFields(c *bootstrapv1.ClusterConfiguration) {
+	// The apiserver certificate is not regenerated by kubeadm upgrade node, so changes to the SANs
+	// still require new machines.
+	certSANs := c.APIServer.CertSANs
+	c.APIServer = bootstrapv1.APIServer{CertSANs: certSANs}
+	c.ControllerManager = bootstrapv1.ControlPlaneComponent{}
+	c.Scheduler = bootstrapv1.ControlPlaneComponent{}
+	// CoreDNS is reconciled by KCP, independently of the machines.
+	c.DNS = bootstrapv1.DNS{}
+	if c.Etcd.Local != nil {
+		c.Etcd.Local.ImageMeta = bootstrapv1.ImageMeta{}
+		c.Etcd.Local.ExtraArgs = nil
+	}
+}
+
 // matchInitOrJoinConfiguration verifies if KCP and machine InitConfiguration or JoinConfiguration matches.
 // NOTE: By extension this method takes care of detecting changes in other fields of the KubeadmConfig configuration (e.g. Files, Mounts etc.)
 func matchInitOrJoinConfiguration(machineConfig *bootstrapv1.KubeadmConfig, kcp *controlplanev1.KubeadmControlPlane) bool {
@@ -185,6 +277,9 @@ func getAdjustedKcpConfig(kcp *controlplanev1.KubeadmControlPlane, machineConfig
 		kcpConfig.InitConfiguration = nil
 	}
 
+	// Machine's bootstrap configuration restores an etcd snapshot when the control plane has been initialized from it.
+	etcdbackup.MatchRestore(kcpConfig, &machineConfig.Spec)
+
 	return kcpConfig
 }
 
@@ -17,15 +17,19 @@ limitations under the License.
 package internal
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcdbackup"
 )
 
 func TestMatchClusterConfiguration(t *testing.T) {
@@ -105,6 +109,99 @@ func TestMatchClusterConfiguration(t *testing.T) {
 	})
 }
 
+func TestMatchClusterConfigurationExceptInPlaceUpgradableFields(t *testing.T) {
+	machineClusterConfig := &bootstrapv1.ClusterConfiguration{
+		ClusterName: "foo",
+		APIServer: bootstrapv1.APIServer{
+			ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{ExtraArgs: map[string]string{"foo": "bar"}},
+			CertSANs:              []string{"foo.example.com"},
+		},
+		Etcd: bootstrapv1.Etcd{
+			Local: &bootstrapv1.LocalEtcd{
+				ImageMeta: bootstrapv1.ImageMeta{ImageTag: "3.5.0-0"},
+				DataDir:   "/var/lib/etcd",
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		withoutAnnotation bool
+		mutateKCP         func(c *bootstrapv1.ClusterConfiguration)
+		expectedMatch     bool
+	}{
+		{
+			name:              "machine without the ClusterConfiguration annotation should not match",
+			withoutAnnotation: true,
+			expectedMatch:     false,
+		},
+		{
+			name:          "machine with the same ClusterConfiguration should match",
+			mutateKCP:     func(c *bootstrapv1.ClusterConfiguration) {},
+			expectedMatch: true,
+		},
+		{
+			name: "machine with different apiServer, controllerManager, scheduler and dns should match",
+			mutateKCP: func(c *bootstrapv1.ClusterConfiguration) {
+				c.APIServer.ExtraArgs = map[string]string{"foo": "baz"}
+				c.ControllerManager.ExtraArgs = map[string]string{"foo": "bar"}
+				c.Scheduler.ExtraVolumes = []bootstrapv1.HostPathMount{{Name: "foo", HostPath: "/foo", MountPath: "/foo"}}
+				c.DNS.ImageTag = "v1.8.4"
+			},
+			expectedMatch: true,
+		},
+		{
+			name: "machine with different local etcd image and extraArgs should match",
+			mutateKCP: func(c *bootstrapv1.ClusterConfiguration) {
+				c.Etcd.Local.ImageTag = "3.5.1-0"
+				c.Etcd.Local.ExtraArgs = map[string]string{"foo": "bar"}
+			},
+			expectedMatch: true,
+		},
+		{
+			name: "machine with different apiServer certSANs should not match",
+			mutateKCP: func(c *bootstrapv1.ClusterConfiguration) {
+				c.APIServer.CertSANs = []string{"bar.example.com"}
+			},
+			expectedMatch: false,
+		},
+		{
+			name: "machine with different local etcd data dir should not match",
+			mutateKCP: func(c *bootstrapv1.ClusterConfiguration) {
+				c.Etcd.Local.DataDir = "/data/etcd"
+			},
+			expectedMatch: false,
+		},
+		{
+			name: "machine with different networking should not match",
+			mutateKCP: func(c *bootstrapv1.ClusterConfiguration) {
+				c.Networking.PodSubnet = "10.0.0.0/16"
+			},
+			expectedMatch: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{}
+			if !tt.withoutAnnotation {
+				annotation, err := json.Marshal(machineClusterConfig)
+				g.Expect(err).NotTo(HaveOccurred())
+				m.SetAnnotations(map[string]string{controlplanev1.KubeadmClusterConfigurationAnnotation: string(annotation)})
+			}
+
+			kcp := &controlplanev1.KubeadmControlPlane{}
+			kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = machineClusterConfig.DeepCopy()
+			if tt.mutateKCP != nil {
+				tt.mutateKCP(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration)
+			}
+
+			g.Expect(matchClusterConfigurationExceptInPlaceUpgradableFields(kcp, m)).To(Equal(tt.expectedMatch))
+		})
+	}
+}
+
 func TestGetAdjustedKcpConfig(t *testing.T) {
 	t.Run("if the machine is the first control plane, kcp config should get InitConfiguration", func(t *testing.T) {
 		g := NewWithT(t)
@@ -328,6 +425,30 @@ func TestMatchInitOrJoinConfiguration(t *testing.T) {
 		}
 		g.Expect(matchInitOrJoinConfiguration(machineConfigs[m.Name], kcp)).To(BeTrue())
 	})
+	t.Run("returns true if the machine restores an etcd snapshot", func(t *testing.T) {
+		g := NewWithT(t)
+		kcp := &controlplanev1.KubeadmControlPlane{
+			Spec: controlplanev1.KubeadmControlPlaneSpec{
+				KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+					ClusterConfiguration: &bootstrapv1.ClusterConfiguration{},
+					PreKubeadmCommands:   []string{"echo foo"},
+				},
+			},
+		}
+		machineConfig := &bootstrapv1.KubeadmConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test",
+			},
+			Spec: *kcp.Spec.KubeadmConfigSpec.DeepCopy(),
+		}
+		etcdbackup.InjectRestore(&machineConfig.Spec, []bootstrapv1.File{{Path: "/etc/kubernetes/etcd-restore/snapshot.url", Content: "https://example.com"}})
+		g.Expect(matchInitOrJoinConfiguration(machineConfig, kcp)).To(BeTrue())
+
+		// Changes to the KubeadmControlPlane are still detected.
+		kcp.Spec.KubeadmConfigSpec.PreKubeadmCommands = []string{"echo bar"}
+		g.Expect(matchInitOrJoinConfiguration(machineConfig, kcp)).To(BeFalse())
+	})
 	t.Run("returns false if InitConfiguration is NOT equal", func(t *testing.T) {
 		g := NewWithT(t)
 		kcp := &controlplanev1.KubeadmControlPlane{
@@ -924,6 +1045,71 @@ func TestMatchesKubeadmBootstrapConfig(t *testing.T) {
 	})
 }
 
+func TestShouldRolloutBefore(t *testing.T) {
+	reconciliationTime := metav1.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	rolloutBefore := &controlplanev1.RolloutBefore{CertificatesExpiryDays: pointer.Int32(7)}
+	expiringIn := func(d time.Duration) *clusterv1.Machine {
+		expiry := metav1.NewTime(reconciliationTime.Add(d))
+		return &clusterv1.Machine{Status: clusterv1.MachineStatus{CertificatesExpiryDate: &expiry}}
+	}
+
+	tests := []struct {
+		name          string
+		rolloutBefore *controlplanev1.RolloutBefore
+		machine       *clusterv1.Machine
+		expected      bool
+	}{
+		{
+			name:          "nil machine returns false",
+			rolloutBefore: rolloutBefore,
+			machine:       nil,
+			expected:      false,
+		},
+		{
+			name:          "returns false if rolloutBefore is not set",
+			rolloutBefore: nil,
+			machine:       expiringIn(time.Hour),
+			expected:      false,
+		},
+		{
+			name:          "returns false if certificatesExpiryDays is not set",
+			rolloutBefore: &controlplanev1.RolloutBefore{},
+			machine:       expiringIn(time.Hour),
+			expected:      false,
+		},
+		{
+			name:          "returns false if the certificates expiry date is unknown",
+			rolloutBefore: rolloutBefore,
+			machine:       &clusterv1.Machine{},
+			expected:      false,
+		},
+		{
+			name:          "returns false if the certificates expire after the threshold",
+			rolloutBefore: rolloutBefore,
+			machine:       expiringIn(8 * 24 * time.Hour),
+			expected:      false,
+		},
+		{
+			name:          "returns true if the certificates expire within the threshold",
+			rolloutBefore: rolloutBefore,
+			machine:       expiringIn(6 * 24 * time.Hour),
+			expected:      true,
+		},
+		{
+			name:          "returns true if the certificates are already expired",
+			rolloutBefore: rolloutBefore,
+			machine:       expiringIn(-time.Hour),
+			expected:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(ShouldRolloutBefore(&reconciliationTime, tt.rolloutBefore)(tt.machine)).To(Equal(tt.expected))
+		})
+	}
+}
+
 func TestMatchesTemplateClonedFrom(t *testing.T) {
 	t.Run("nil machine returns false", func(t *testing.T) {
 		g := NewWithT(t)
@@ -25,6 +25,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"io"
 	"math/big"
 	"reflect"
 	"time"
@@ -36,11 +37,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/retry"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	kubeadmtypes "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/proxy"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/certs"
 	containerutil "sigs.k8s.io/cluster-api/util/container"
@@ -57,6 +60,7 @@ const (
 	labelNodeRoleControlPlane = "node-role.kubernetes.io/master"
 	clusterStatusKey          = "ClusterStatus"
 	clusterConfigurationKey   = "ClusterConfiguration"
+	apiServerBindPort         = 6443
 )
 
 var (
@@ -86,6 +90,7 @@ type WorkloadCluster interface {
 	UpdateStaticPodConditions(ctx context.Context, controlPlane *ControlPlane)
 	UpdateEtcdConditions(ctx context.Context, controlPlane *ControlPlane)
 	EtcdMembers(ctx context.Context) ([]string, error)
+	EtcdSnapshot(ctx context.Context, writer io.Writer) error
 
 	// Upgrade related tasks.
 	ReconcileKubeletRBACBinding(ctx context.Context, version semver.Version) error
@@ -94,6 +99,7 @@ type WorkloadCluster interface {
 	UpdateImageRepositoryInKubeadmConfigMap(ctx context.Context, imageRepository string, version semver.Version) error
 	UpdateEtcdVersionInKubeadmConfigMap(ctx context.Context, imageRepository, imageTag string, version semver.Version) error
 	UpdateEtcdExtraArgsInKubeadmConfigMap(ctx context.Context, extraArgs map[string]string, version semver.Version) error
+	UpdateExternalEtcdEndpointsInKubeadmConfigMap(ctx context.Context, endpoints []string, version semver.Version) error
 	UpdateAPIServerInKubeadmConfigMap(ctx context.Context, apiServer bootstrapv1.APIServer, version semver.Version) error
 	UpdateControllerManagerInKubeadmConfigMap(ctx context.Context, controllerManager bootstrapv1.ControlPlaneComponent, version semver.Version) error
 	UpdateSchedulerInKubeadmConfigMap(ctx context.Context, scheduler bootstrapv1.ControlPlaneComponent, version semver.Version) error
@@ -103,8 +109,11 @@ type WorkloadCluster interface {
 	RemoveEtcdMemberForMachine(ctx context.Context, machine *clusterv1.Machine) error
 	RemoveMachineFromKubeadmConfigMap(ctx context.Context, machine *clusterv1.Machine, version semver.Version) error
 	RemoveNodeFromKubeadmConfigMap(ctx context.Context, nodeName string, version semver.Version) error
+	RequestInPlaceUpgrade(ctx context.Context, nodeName, hash string) error
+	GetInPlaceUpgradeStatus(ctx context.Context, nodeName, hash string) (InPlaceUpgradeStatus, string, error)
 	ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error
 	AllowBootstrapTokensToGetNodes(ctx context.Context) error
+	GetAPIServerCertificateExpiry(ctx context.Context, kubeadmConfig *bootstrapv1.KubeadmConfig, nodeName string) (*time.Time, error)
 
 	// State recovery tasks.
 	ReconcileEtcdMembers(ctx context.Context, nodeNames []string, version semver.Version) ([]string, error)
@@ -115,6 +124,7 @@ type Workload struct {
 	Client              ctrlclient.Client
 	CoreDNSMigrator     coreDNSMigrator
 	etcdClientGenerator etcdClientFor
+	restConfig          *rest.Config
 }
 
 var _ WorkloadCluster = &Workload{}
@@ -436,6 +446,52 @@ func newClientCert(caCert *x509.Certificate, key *rsa.PrivateKey, caKey crypto.S
 	return c, errors.WithStack(err)
 }
 
+// GetAPIServerCertificateExpiry returns the certificate expiry of the apiserver on the given node.
+func (w *Workload) GetAPIServerCertificateExpiry(ctx context.Context, kubeadmConfig *bootstrapv1.KubeadmConfig, nodeName string) (*time.Time, error) {
+	port := int32(apiServerBindPort)
+	switch {
+	case kubeadmConfig.Spec.InitConfiguration != nil && kubeadmConfig.Spec.InitConfiguration.LocalAPIEndpoint.BindPort != 0:
+		port = kubeadmConfig.Spec.InitConfiguration.LocalAPIEndpoint.BindPort
+	case kubeadmConfig.Spec.JoinConfiguration != nil &&
+		kubeadmConfig.Spec.JoinConfiguration.ControlPlane != nil &&
+		kubeadmConfig.Spec.JoinConfiguration.ControlPlane.LocalAPIEndpoint.BindPort != 0:
+		port = kubeadmConfig.Spec.JoinConfiguration.ControlPlane.LocalAPIEndpoint.BindPort
+	}
+
+	// The certificate is only read, so there is no need to verify it.
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, // nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+	p := proxy.Proxy{
+		Kind:       "pods",
+		Namespace:  metav1.NamespaceSystem,
+		KubeConfig: w.restConfig,
+		TLSConfig:  tlsConfig,
+		Port:       int(port),
+	}
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create dialer for the kube-apiserver on Node %q", nodeName)
+	}
+	conn, err := dialer.DialContextWithAddr(ctx, staticPodName("kube-apiserver", nodeName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to the kube-apiserver on Node %q", nodeName)
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Wrapf(err, "TLS handshake with the kube-apiserver on Node %q failed", nodeName)
+	}
+
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.Errorf("the kube-apiserver on Node %q did not present any certificate", nodeName)
+	}
+	return &certs[0].NotAfter, nil
+}
+
 func staticPodName(component, nodeName string) string {
 	return fmt.Sprintf("%s-%s", component, nodeName)
 }
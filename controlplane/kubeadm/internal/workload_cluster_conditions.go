@@ -46,12 +46,7 @@ func (w *Workload) UpdateEtcdConditions(ctx context.Context, controlPlane *Contr
 }
 
 func (w *Workload) updateExternalEtcdConditions(ctx context.Context, controlPlane *ControlPlane) { //nolint:unparam
-	// When KCP is not responsible for external etcd, we are reporting only health at KCP level.
-	conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdClusterHealthyCondition)
-
-	// TODO: check external etcd for alarms an possibly also for member errors
-	// this requires implementing an new type of etcd client generator given that it is not possible to use nodes
-	// as a source for the etcd endpoint address; the address of the external etcd should be available on the kubeadm configuration.
+	controlPlane.UpdateExternalEtcdClusterCondition()
 }
 
 func (w *Workload) updateManagedEtcdConditions(ctx context.Context, controlPlane *ControlPlane) {
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
@@ -39,12 +40,32 @@ import (
 )
 
 func TestUpdateEtcdConditions(t *testing.T) {
+	externalEtcdClusterRef := &corev1.ObjectReference{
+		APIVersion: "etcd.example.com/v1",
+		Kind:       "EtcdCluster",
+		Name:       "etcd",
+	}
+	externalEtcdCluster := func(ready bool, readyCondition *clusterv1.Condition) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(externalEtcdClusterRef.APIVersion)
+		obj.SetKind(externalEtcdClusterRef.Kind)
+		obj.SetName(externalEtcdClusterRef.Name)
+		_ = unstructured.SetNestedField(obj.Object, ready, "status", "ready")
+		if readyCondition != nil {
+			conditions.UnstructuredSetter(obj).SetConditions(clusterv1.Conditions{*readyCondition})
+		}
+		return obj
+	}
+	externalEtcdClusterWithFailure := externalEtcdCluster(false, nil)
+	_ = unstructured.SetNestedField(externalEtcdClusterWithFailure.Object, "quorum lost", "status", "failureMessage")
+
 	tests := []struct {
 		name                      string
 		kcp                       *controlplanev1.KubeadmControlPlane
 		machines                  []*clusterv1.Machine
 		injectClient              client.Client // This test is injecting a fake client because it is required to create nodes with a controlled Status or to fail with a specific error.
 		injectEtcdClientGenerator etcdClientFor // This test is injecting a fake etcdClientGenerator because it is required to nodes with a controlled Status or to fail with a specific error.
+		externalEtcdCluster       *unstructured.Unstructured
 		expectedKCPCondition      *clusterv1.Condition
 		expectedMachineConditions map[string]clusterv1.Conditions
 	}{
@@ -461,6 +482,90 @@ func TestUpdateEtcdConditions(t *testing.T) {
 			},
 			expectedKCPCondition: conditions.TrueCondition(controlplanev1.EtcdClusterHealthyCondition),
 		},
+		{
+			name: "External etcd cluster not found should set a condition at KCP level",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{
+								External: &bootstrapv1.ExternalEtcd{},
+							},
+						},
+					},
+					EtcdClusterRef: externalEtcdClusterRef,
+				},
+			},
+			expectedKCPCondition: conditions.FalseCondition(controlplanev1.EtcdClusterHealthyCondition, controlplanev1.ExternalEtcdClusterNotReadyReason, clusterv1.ConditionSeverityWarning, "EtcdCluster etcd does not exist"),
+		},
+		{
+			name: "External etcd cluster not ready should set a condition at KCP level",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{
+								External: &bootstrapv1.ExternalEtcd{},
+							},
+						},
+					},
+					EtcdClusterRef: externalEtcdClusterRef,
+				},
+			},
+			externalEtcdCluster:  externalEtcdCluster(false, nil),
+			expectedKCPCondition: conditions.FalseCondition(controlplanev1.EtcdClusterHealthyCondition, controlplanev1.ExternalEtcdClusterNotReadyReason, clusterv1.ConditionSeverityWarning, "EtcdCluster etcd is not ready"),
+		},
+		{
+			name: "External etcd cluster not ready should mirror the provider's Ready condition at KCP level",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{
+								External: &bootstrapv1.ExternalEtcd{},
+							},
+						},
+					},
+					EtcdClusterRef: externalEtcdClusterRef,
+				},
+			},
+			externalEtcdCluster:  externalEtcdCluster(false, conditions.FalseCondition(clusterv1.ReadyCondition, "MembersUnhealthy", clusterv1.ConditionSeverityError, "2 of 3 members are unhealthy")),
+			expectedKCPCondition: conditions.FalseCondition(controlplanev1.EtcdClusterHealthyCondition, "MembersUnhealthy", clusterv1.ConditionSeverityError, "2 of 3 members are unhealthy"),
+		},
+		{
+			name: "Failed external etcd cluster should set a condition at KCP level",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{
+								External: &bootstrapv1.ExternalEtcd{},
+							},
+						},
+					},
+					EtcdClusterRef: externalEtcdClusterRef,
+				},
+			},
+			externalEtcdCluster:  externalEtcdClusterWithFailure,
+			expectedKCPCondition: conditions.FalseCondition(controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityError, "EtcdCluster etcd failed: quorum lost"),
+		},
+		{
+			name: "External etcd cluster ready should set a condition at KCP level",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							Etcd: bootstrapv1.Etcd{
+								External: &bootstrapv1.ExternalEtcd{},
+							},
+						},
+					},
+					EtcdClusterRef: externalEtcdClusterRef,
+				},
+			},
+			externalEtcdCluster:  externalEtcdCluster(true, nil),
+			expectedKCPCondition: conditions.TrueCondition(controlplanev1.EtcdClusterHealthyCondition),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -474,8 +579,9 @@ func TestUpdateEtcdConditions(t *testing.T) {
 				etcdClientGenerator: tt.injectEtcdClientGenerator,
 			}
 			controlPane := &ControlPlane{
-				KCP:      tt.kcp,
-				Machines: collections.FromMachines(tt.machines...),
+				KCP:                 tt.kcp,
+				Machines:            collections.FromMachines(tt.machines...),
+				externalEtcdCluster: tt.externalEtcdCluster,
 			}
 			w.UpdateEtcdConditions(ctx, controlPane)
 
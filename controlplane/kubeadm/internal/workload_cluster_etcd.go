@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"io"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -101,6 +102,15 @@ func (w *Workload) UpdateEtcdExtraArgsInKubeadmConfigMap(ctx context.Context, ex
 	}, version)
 }
 
+// UpdateExternalEtcdEndpointsInKubeadmConfigMap sets the external etcd endpoints in the kubeadm config map.
+func (w *Workload) UpdateExternalEtcdEndpointsInKubeadmConfigMap(ctx context.Context, endpoints []string, version semver.Version) error {
+	return w.updateClusterConfiguration(ctx, func(c *bootstrapv1.ClusterConfiguration) {
+		if c.Etcd.External != nil {
+			c.Etcd.External.Endpoints = endpoints
+		}
+	}, version)
+}
+
 // RemoveEtcdMemberForMachine removes the etcd member from the target cluster's etcd cluster.
 // Removing the last remaining member of the cluster is not supported.
 func (w *Workload) RemoveEtcdMemberForMachine(ctx context.Context, machine *clusterv1.Machine) error {
@@ -237,3 +247,24 @@ func (w *Workload) EtcdMembers(ctx context.Context) ([]string, error) {
 	}
 	return names, nil
 }
+
+// EtcdSnapshot takes a snapshot of the etcd cluster and writes it to the given writer.
+func (w *Workload) EtcdSnapshot(ctx context.Context, writer io.Writer) error {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+
+	// Any member can serve the snapshot, given that all of them share the same data.
+	etcdClient, err := w.etcdClientGenerator.forFirstAvailableNode(ctx, nodeNames)
+	if err != nil {
+		return errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	return etcdClient.Snapshot(ctx, writer)
+}
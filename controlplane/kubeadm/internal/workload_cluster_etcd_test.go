@@ -17,6 +17,7 @@ limitations under the License.
 package internal
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
@@ -199,6 +200,90 @@ func TestUpdateEtcdExtraArgsInKubeadmConfigMap(t *testing.T) {
 	}
 }
 
+func TestUpdateExternalEtcdEndpointsInKubeadmConfigMap(t *testing.T) {
+	tests := []struct {
+		name                     string
+		clusterConfigurationData string
+		newEndpoints             []string
+		wantClusterConfiguration string
+	}{
+		{
+			name: "it should set the endpoints when external etcd",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta2
+				kind: ClusterConfiguration
+				etcd:
+				  external:
+				    endpoints:
+				    - https://etcd-0:2379
+				`),
+			newEndpoints: []string{"https://etcd-0:2379", "https://etcd-1:2379"},
+			wantClusterConfiguration: yaml.Raw(`
+				apiServer: {}
+				apiVersion: kubeadm.k8s.io/v1beta2
+				controllerManager: {}
+				dns: {}
+				etcd:
+				  external:
+				    caFile: ""
+				    certFile: ""
+				    endpoints:
+				    - https://etcd-0:2379
+				    - https://etcd-1:2379
+				    keyFile: ""
+				kind: ClusterConfiguration
+				networking: {}
+				scheduler: {}
+				`),
+		},
+		{
+			name: "no op when local etcd",
+			clusterConfigurationData: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta2
+				kind: ClusterConfiguration
+				etcd:
+				  local: {}
+				`),
+			newEndpoints: []string{"https://etcd-0:2379"},
+			wantClusterConfiguration: yaml.Raw(`
+				apiVersion: kubeadm.k8s.io/v1beta2
+				kind: ClusterConfiguration
+				etcd:
+				  local: {}
+				`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			fakeClient := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      kubeadmConfigKey,
+					Namespace: metav1.NamespaceSystem,
+				},
+				Data: map[string]string{
+					clusterConfigurationKey: tt.clusterConfigurationData,
+				},
+			}).Build()
+
+			w := &Workload{
+				Client: fakeClient,
+			}
+			err := w.UpdateExternalEtcdEndpointsInKubeadmConfigMap(ctx, tt.newEndpoints, semver.MustParse("1.19.1"))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var actualConfig corev1.ConfigMap
+			g.Expect(w.Client.Get(
+				ctx,
+				client.ObjectKey{Name: kubeadmConfigKey, Namespace: metav1.NamespaceSystem},
+				&actualConfig,
+			)).To(Succeed())
+			g.Expect(actualConfig.Data[clusterConfigurationKey]).Should(Equal(tt.wantClusterConfiguration), cmp.Diff(tt.wantClusterConfiguration, actualConfig.Data[clusterConfigurationKey]))
+		})
+	}
+}
+
 func TestRemoveEtcdMemberForMachine(t *testing.T) {
 	machine := &clusterv1.Machine{
 		Status: clusterv1.MachineStatus{
@@ -772,6 +857,64 @@ func TestRemoveNodeFromKubeadmConfigMap(t *testing.T) {
 	}
 }
 
+func TestEtcdSnapshot(t *testing.T) {
+	cp1 := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cp1",
+			Labels: map[string]string{labelNodeRoleControlPlane: ""},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		etcdClientGenerator etcdClientFor
+		expectErr           bool
+		expectedSnapshot    string
+	}{
+		{
+			name:                "returns an error if it fails to create the etcd client",
+			etcdClientGenerator: &fakeEtcdClientGenerator{forNodesErr: errors.New("no client")},
+			expectErr:           true,
+		},
+		{
+			name: "returns an error if the client fails to take the snapshot",
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forNodesClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{ErrorResponse: errors.New("cannot take snapshot")},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "writes the snapshot",
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forNodesClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{SnapshotResponse: []byte("snapshot")},
+				},
+			},
+			expectedSnapshot: "snapshot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			w := &Workload{
+				Client:              fake.NewClientBuilder().WithObjects(cp1.DeepCopy()).Build(),
+				etcdClientGenerator: tt.etcdClientGenerator,
+			}
+			snapshot := &bytes.Buffer{}
+			err := w.EtcdSnapshot(ctx, snapshot)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(snapshot.String()).To(Equal(tt.expectedSnapshot))
+		})
+	}
+}
+
 type fakeEtcdClientGenerator struct {
 	forNodesClient     *etcd.Client
 	forNodesClientFunc func([]string) (*etcd.Client, error)
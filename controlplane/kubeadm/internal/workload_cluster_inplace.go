@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InPlaceUpgradeStatus is the status of an in-place upgrade of the control plane components of a node.
+type InPlaceUpgradeStatus string
+
+const (
+	// InPlaceUpgradeNotRequested is the status of a node which has not been asked for the in-place upgrade yet.
+	InPlaceUpgradeNotRequested InPlaceUpgradeStatus = "NotRequested"
+
+	// InPlaceUpgradeInProgress is the status of a node which has been asked for the in-place upgrade,
+	// and has not reported its result yet.
+	InPlaceUpgradeInProgress InPlaceUpgradeStatus = "InProgress"
+
+	// InPlaceUpgradeCompleted is the status of a node which completed the in-place upgrade.
+	InPlaceUpgradeCompleted InPlaceUpgradeStatus = "Completed"
+
+	// InPlaceUpgradeFailed is the status of a node which failed the in-place upgrade.
+	InPlaceUpgradeFailed InPlaceUpgradeStatus = "Failed"
+)
+
+// InPlaceUpgradeHash returns the value identifying an in-place upgrade to the given ClusterConfiguration.
+func InPlaceUpgradeHash(clusterConfiguration *bootstrapv1.ClusterConfiguration) (string, error) {
+	data, err := json.Marshal(clusterConfiguration)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal cluster configuration")
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(data)
+	return fmt.Sprintf("%x", hasher.Sum32()), nil
+}
+
+// RequestInPlaceUpgrade asks the node agent running on the given node to upgrade the control plane components in place,
+// according to the ClusterConfiguration stored in the kubeadm config map.
+func (w *Workload) RequestInPlaceUpgrade(ctx context.Context, nodeName, hash string) error {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return errors.Wrapf(err, "failed to get node %s", nodeName)
+	}
+
+	helper, err := patch.NewHelper(node, w.Client)
+	if err != nil {
+		return err
+	}
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[controlplanev1.InPlaceUpgradeRequestAnnotation] = hash
+	node.SetAnnotations(annotations)
+	if err := helper.Patch(ctx, node); err != nil {
+		return errors.Wrapf(err, "failed to request in-place upgrade of node %s", nodeName)
+	}
+	return nil
+}
+
+// GetInPlaceUpgradeStatus returns the status of the in-place upgrade identified by hash on the given node, and the message
+// reported by the node agent, if any.
+func (w *Workload) GetInPlaceUpgradeStatus(ctx context.Context, nodeName, hash string) (InPlaceUpgradeStatus, string, error) {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return "", "", errors.Wrapf(err, "failed to get node %s", nodeName)
+	}
+
+	annotations := node.GetAnnotations()
+	message := annotations[controlplanev1.InPlaceUpgradeMessageAnnotation]
+	switch {
+	case annotations[controlplanev1.InPlaceUpgradeRequestAnnotation] != hash:
+		return InPlaceUpgradeNotRequested, "", nil
+	case annotations[controlplanev1.InPlaceUpgradeFailedAnnotation] == hash:
+		return InPlaceUpgradeFailed, message, nil
+	case annotations[controlplanev1.InPlaceUpgradeCompletedAnnotation] == hash:
+		return InPlaceUpgradeCompleted, message, nil
+	default:
+		return InPlaceUpgradeInProgress, message, nil
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInPlaceUpgradeHash(t *testing.T) {
+	g := NewWithT(t)
+
+	hash, err := InPlaceUpgradeHash(&bootstrapv1.ClusterConfiguration{ClusterName: "foo"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(hash).NotTo(BeEmpty())
+	g.Expect(InPlaceUpgradeHash(&bootstrapv1.ClusterConfiguration{ClusterName: "foo"})).To(Equal(hash))
+	g.Expect(InPlaceUpgradeHash(&bootstrapv1.ClusterConfiguration{ClusterName: "bar"})).NotTo(Equal(hash))
+}
+
+func TestInPlaceUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node",
+			Annotations: map[string]string{
+				// The result of a previous in-place upgrade.
+				controlplanev1.InPlaceUpgradeRequestAnnotation:   "old",
+				controlplanev1.InPlaceUpgradeCompletedAnnotation: "old",
+			},
+		},
+	}
+	w := &Workload{Client: fake.NewClientBuilder().WithObjects(node).Build()}
+
+	status, _, err := w.GetInPlaceUpgradeStatus(ctx, "node", "new")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(InPlaceUpgradeNotRequested))
+
+	g.Expect(w.RequestInPlaceUpgrade(ctx, "node", "new")).To(Succeed())
+	g.Expect(w.Client.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+	g.Expect(node.Annotations).To(HaveKeyWithValue(controlplanev1.InPlaceUpgradeRequestAnnotation, "new"))
+	status, _, err = w.GetInPlaceUpgradeStatus(ctx, "node", "new")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(InPlaceUpgradeInProgress))
+
+	// The node agent reports a failure.
+	node.Annotations[controlplanev1.InPlaceUpgradeFailedAnnotation] = "new"
+	node.Annotations[controlplanev1.InPlaceUpgradeMessageAnnotation] = "kubeadm upgrade node failed"
+	g.Expect(w.Client.Update(ctx, node)).To(Succeed())
+	status, message, err := w.GetInPlaceUpgradeStatus(ctx, "node", "new")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(InPlaceUpgradeFailed))
+	g.Expect(message).To(Equal("kubeadm upgrade node failed"))
+
+	// The node agent reports a success.
+	delete(node.Annotations, controlplanev1.InPlaceUpgradeFailedAnnotation)
+	node.Annotations[controlplanev1.InPlaceUpgradeCompletedAnnotation] = "new"
+	g.Expect(w.Client.Update(ctx, node)).To(Succeed())
+	status, _, err = w.GetInPlaceUpgradeStatus(ctx, "node", "new")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(InPlaceUpgradeCompleted))
+
+	// A missing node is reported.
+	_, _, err = w.GetInPlaceUpgradeStatus(ctx, "missing", "new")
+	g.Expect(err).To(HaveOccurred())
+}
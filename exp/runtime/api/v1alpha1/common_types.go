@@ -0,0 +1,28 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// PendingHooksAnnotation is the annotation used on a Cluster to keep track of the lifecycle hooks
+	// that have to be called, e.g. the AfterControlPlaneUpgrade hook after the upgrade of the control plane started.
+	// The value is a comma separated list of hook names.
+	PendingHooksAnnotation = "runtime.cluster.x-k8s.io/pending-hooks"
+
+	// OkToDeleteAnnotation is the annotation set on a Cluster once the BeforeClusterDelete hook
+	// does not block the deletion of the Cluster anymore.
+	OkToDeleteAnnotation = "runtime.cluster.x-k8s.io/ok-to-delete"
+)
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultTimeoutSeconds is the default timeout for calls to an extension.
+	DefaultTimeoutSeconds int32 = 10
+
+	// MaxTimeoutSeconds is the maximum timeout allowed for calls to an extension.
+	MaxTimeoutSeconds int32 = 30
+
+	// DefaultServicePort is the port used to call an extension exposed through a Service, if not otherwise specified.
+	DefaultServicePort int32 = 443
+)
+
+// FailurePolicy specifies how errors returned by an extension are handled.
+type FailurePolicy string
+
+const (
+	// FailurePolicyIgnore means that an error when calling the extension is ignored,
+	// and processing continues as if the extension did not return any result.
+	FailurePolicyIgnore FailurePolicy = "Ignore"
+
+	// FailurePolicyFail means that an error when calling the extension is surfaced
+	// as a reconcile error.
+	FailurePolicyFail FailurePolicy = "Fail"
+)
+
+// ANCHOR: ExtensionConfigSpec
+
+// ExtensionConfigSpec defines the desired state of ExtensionConfig.
+type ExtensionConfigSpec struct {
+	// ClientConfig defines how to communicate with the extension.
+	ClientConfig ClientConfig `json:"clientConfig"`
+
+	// TimeoutSeconds defines the timeout for a call to the extension.
+	// Defaults to 10 seconds, the maximum allowed value is 30 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePolicy defines how failures when calling the extension are handled.
+	// Defaults to Fail.
+	// +kubebuilder:validation:Enum=Ignore;Fail
+	// +optional
+	FailurePolicy *FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// Hooks is the list of lifecycle hooks the extension is called for, e.g. BeforeClusterUpgrade.
+	// Each hook is called at the URL of the extension with the lower case name of the hook appended
+	// as path, e.g. "https://extension.example.com/beforeclusterupgrade".
+	// +optional
+	Hooks []string `json:"hooks,omitempty"`
+}
+
+// ANCHOR_END: ExtensionConfigSpec
+
+// ClientConfig contains the information to make a TLS connection with an extension.
+// Exactly one of url or service must be specified.
+type ClientConfig struct {
+	// URL gives the location of the extension, in standard URL form (`scheme://host:port/path`).
+	// The scheme must be "https".
+	// +optional
+	URL *string `json:"url,omitempty"`
+
+	// Service is a reference to the Kubernetes service for the extension.
+	// +optional
+	Service *ServiceReference `json:"service,omitempty"`
+
+	// CABundle is a PEM encoded CA bundle which will be used to validate the extension's server certificate.
+	// If unspecified, system trust roots are used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+}
+
+// ServiceReference holds a reference to a Kubernetes Service of an extension.
+type ServiceReference struct {
+	// Namespace is the namespace of the service.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the service.
+	Name string `json:"name"`
+
+	// Path is an optional URL path which will be sent in any request to the service.
+	// +optional
+	Path *string `json:"path,omitempty"`
+
+	// Port is the port on the service hosting the extension.
+	// Defaults to 443; must be a valid port number (1-65535, inclusive).
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=extensionconfigs,scope=Cluster,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of ExtensionConfig"
+
+// ExtensionConfig is the Schema for the extensionconfigs API.
+// An ExtensionConfig registers an external service that can be called by Cluster API,
+// e.g. to generate patches for the templates of a ClusterClass.
+type ExtensionConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ExtensionConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExtensionConfigList contains a list of ExtensionConfig.
+type ExtensionConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExtensionConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExtensionConfig{}, &ExtensionConfigList{})
+}
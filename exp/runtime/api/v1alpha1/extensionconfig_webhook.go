@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/feature"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (e *ExtensionConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(e).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-runtime-cluster-x-k8s-io-v1alpha1-extensionconfig,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=runtime.cluster.x-k8s.io,resources=extensionconfigs,versions=v1alpha1,name=validation.extensionconfig.runtime.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-runtime-cluster-x-k8s-io-v1alpha1-extensionconfig,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=runtime.cluster.x-k8s.io,resources=extensionconfigs,versions=v1alpha1,name=default.extensionconfig.runtime.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ExtensionConfig{}
+var _ webhook.Validator = &ExtensionConfig{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+func (e *ExtensionConfig) Default() {
+	if e.Spec.TimeoutSeconds == nil {
+		timeout := DefaultTimeoutSeconds
+		e.Spec.TimeoutSeconds = &timeout
+	}
+	if e.Spec.FailurePolicy == nil {
+		failurePolicy := FailurePolicyFail
+		e.Spec.FailurePolicy = &failurePolicy
+	}
+	if e.Spec.ClientConfig.Service != nil && e.Spec.ClientConfig.Service.Port == nil {
+		port := DefaultServicePort
+		e.Spec.ClientConfig.Service.Port = &port
+	}
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (e *ExtensionConfig) ValidateCreate() error {
+	return e.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (e *ExtensionConfig) ValidateUpdate(old runtime.Object) error {
+	if _, ok := old.(*ExtensionConfig); !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected an ExtensionConfig but got a %T", old))
+	}
+	return e.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (e *ExtensionConfig) ValidateDelete() error {
+	return nil
+}
+
+func (e *ExtensionConfig) validate() error {
+	// NOTE: Extensions are used by managed topologies, which are behind the ClusterTopology feature gate flag;
+	// the web hook must prevent creating new objects in case the feature flag is disabled.
+	if !feature.Gates.Enabled(feature.ClusterTopology) {
+		return field.Forbidden(
+			field.NewPath("spec"),
+			"can be set only if the ClusterTopology feature flag is enabled",
+		)
+	}
+
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, e.Spec.ClientConfig.validate(field.NewPath("spec", "clientConfig"))...)
+
+	if e.Spec.TimeoutSeconds != nil && (*e.Spec.TimeoutSeconds < 1 || *e.Spec.TimeoutSeconds > MaxTimeoutSeconds) {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec", "timeoutSeconds"),
+				*e.Spec.TimeoutSeconds,
+				fmt.Sprintf("must be between 1 and %d", MaxTimeoutSeconds),
+			),
+		)
+	}
+
+	if e.Spec.FailurePolicy != nil && *e.Spec.FailurePolicy != FailurePolicyFail && *e.Spec.FailurePolicy != FailurePolicyIgnore {
+		allErrs = append(allErrs,
+			field.NotSupported(
+				field.NewPath("spec", "failurePolicy"),
+				*e.Spec.FailurePolicy,
+				[]string{string(FailurePolicyFail), string(FailurePolicyIgnore)},
+			),
+		)
+	}
+
+	supportedHooks := sets.NewString()
+	for _, hook := range runtimehooksv1.LifecycleHooks {
+		supportedHooks.Insert(string(hook))
+	}
+	seenHooks := sets.NewString()
+	for i, hook := range e.Spec.Hooks {
+		if !supportedHooks.Has(hook) {
+			allErrs = append(allErrs,
+				field.NotSupported(
+					field.NewPath("spec", "hooks").Index(i),
+					hook,
+					supportedHooks.List(),
+				),
+			)
+		}
+		if seenHooks.Has(hook) {
+			allErrs = append(allErrs, field.Duplicate(field.NewPath("spec", "hooks").Index(i), hook))
+		}
+		seenHooks.Insert(hook)
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("ExtensionConfig").GroupKind(), e.Name, allErrs)
+	}
+	return nil
+}
+
+func (c *ClientConfig) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch {
+	case c.URL == nil && c.Service == nil:
+		allErrs = append(allErrs, field.Required(fldPath, "exactly one of url or service must be set"))
+	case c.URL != nil && c.Service != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "exactly one of url or service must be set"))
+	case c.URL != nil:
+		u, err := url.Parse(*c.URL)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), *c.URL, fmt.Sprintf("must be a valid URL: %v", err)))
+			break
+		}
+		if u.Scheme != "https" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), *c.URL, "scheme must be https"))
+		}
+		if u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), *c.URL, "host must be set"))
+		}
+	case c.Service != nil:
+		if c.Service.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("service", "name"), "service name must be set"))
+		}
+		if c.Service.Namespace == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("service", "namespace"), "service namespace must be set"))
+		}
+		if c.Service.Port != nil && (*c.Service.Port < 1 || *c.Service.Port > 65535) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("service", "port"), *c.Service.Port, "must be a valid port number (1-65535, inclusive)"))
+		}
+	}
+
+	if len(c.CABundle) > 0 {
+		if ok := x509.NewCertPool().AppendCertsFromPEM(c.CABundle); !ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("caBundle"), "", "must contain at least one valid PEM encoded certificate"))
+		}
+	}
+
+	return allErrs
+}
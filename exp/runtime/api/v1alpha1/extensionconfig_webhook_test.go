@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/feature"
+	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
+)
+
+func TestExtensionConfigDefault(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create ExtensionConfigs.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	g := NewWithT(t)
+
+	e := &ExtensionConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "extension1",
+		},
+		Spec: ExtensionConfigSpec{
+			ClientConfig: ClientConfig{
+				Service: &ServiceReference{
+					Namespace: "ns1",
+					Name:      "svc1",
+				},
+			},
+		},
+	}
+	t.Run("for ExtensionConfig", utildefaulting.DefaultValidateTest(e))
+	e.Default()
+
+	g.Expect(*e.Spec.TimeoutSeconds).To(Equal(DefaultTimeoutSeconds))
+	g.Expect(*e.Spec.FailurePolicy).To(Equal(FailurePolicyFail))
+	g.Expect(*e.Spec.ClientConfig.Service.Port).To(Equal(DefaultServicePort))
+}
+
+func TestExtensionConfigValidation(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	invalidFailurePolicy := FailurePolicy("Retry")
+
+	tests := []struct {
+		name      string
+		spec      ExtensionConfigSpec
+		expectErr bool
+	}{
+		{
+			name: "pass with url",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com:8443/generate"),
+				},
+			},
+		},
+		{
+			name: "pass with service",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					Service: &ServiceReference{
+						Namespace: "ns1",
+						Name:      "svc1",
+						Port:      pointer.Int32(8443),
+					},
+				},
+			},
+		},
+		{
+			name: "fail if neither url nor service are set",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if both url and service are set",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+					Service: &ServiceReference{
+						Namespace: "ns1",
+						Name:      "svc1",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if url does not use https",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("http://extension.example.com"),
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if service port is invalid",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					Service: &ServiceReference{
+						Namespace: "ns1",
+						Name:      "svc1",
+						Port:      pointer.Int32(70000),
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if caBundle is invalid",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL:      pointer.String("https://extension.example.com"),
+					CABundle: []byte("not a certificate"),
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if timeout exceeds the maximum",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+				},
+				TimeoutSeconds: pointer.Int32(60),
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with an unknown failure policy",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+				},
+				FailurePolicy: &invalidFailurePolicy,
+			},
+			expectErr: true,
+		},
+		{
+			name: "pass with lifecycle hooks",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+				},
+				Hooks: []string{"BeforeClusterUpgrade", "AfterControlPlaneUpgrade"},
+			},
+		},
+		{
+			name: "fail with an unknown hook",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+				},
+				Hooks: []string{"BeforeMachineCreate"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with a duplicate hook",
+			spec: ExtensionConfigSpec{
+				ClientConfig: ClientConfig{
+					URL: pointer.String("https://extension.example.com"),
+				},
+				Hooks: []string{"BeforeClusterDelete", "BeforeClusterDelete"},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			e := &ExtensionConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "extension1"},
+				Spec:       tt.spec,
+			}
+			if tt.expectErr {
+				g.Expect(e.ValidateCreate()).NotTo(Succeed())
+				g.Expect(e.ValidateUpdate(e.DeepCopy())).NotTo(Succeed())
+			} else {
+				g.Expect(e.ValidateCreate()).To(Succeed())
+				g.Expect(e.ValidateUpdate(e.DeepCopy())).To(Succeed())
+			}
+		})
+	}
+}
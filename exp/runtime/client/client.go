@@ -0,0 +1,292 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxResponseSize is the maximum size of a response returned by an extension.
+const maxResponseSize = 3 * 1024 * 1024
+
+// Client calls the extensions registered for lifecycle hooks.
+type Client interface {
+	// CallAllExtensions calls the hook on all the extensions registered for it and returns the aggregated response.
+	// The aggregated response blocks if at least one of the extensions blocks; in this case RetryAfterSeconds
+	// is the lowest value returned by the blocking extensions.
+	// NOTE: Errors of extensions with failure policy Ignore are logged and the extension is skipped.
+	CallAllExtensions(ctx context.Context, hook runtimehooksv1.Hook, request interface{}) (*runtimehooksv1.CommonRetryResponse, error)
+}
+
+// New returns a new Client reading ExtensionConfig objects with the given reader.
+func New(c client.Reader) Client {
+	return &runtimeClient{
+		client: c,
+	}
+}
+
+type runtimeClient struct {
+	client client.Reader
+}
+
+// CallAllExtensions calls the hook on all the extensions registered for it and returns the aggregated response.
+func (c *runtimeClient) CallAllExtensions(ctx context.Context, hook runtimehooksv1.Hook, request interface{}) (*runtimehooksv1.CommonRetryResponse, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	extensionConfigs := &runtimev1.ExtensionConfigList{}
+	if err := c.client.List(ctx, extensionConfigs); err != nil {
+		return nil, errors.Wrap(err, "failed to list ExtensionConfigs")
+	}
+	// Call the extensions in a predictable order, so the aggregated message is stable.
+	sort.Slice(extensionConfigs.Items, func(i, j int) bool {
+		return extensionConfigs.Items[i].Name < extensionConfigs.Items[j].Name
+	})
+
+	aggregated := &runtimehooksv1.CommonRetryResponse{
+		CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess},
+	}
+	var messages []string
+	for i := range extensionConfigs.Items {
+		extensionConfig := &extensionConfigs.Items[i]
+		if !isRegisteredFor(extensionConfig, hook) {
+			continue
+		}
+
+		resp := &runtimehooksv1.CommonRetryResponse{}
+		if err := CallExtension(ctx, extensionConfig, hook.Path(), request, resp); err != nil {
+			if FailurePolicy(extensionConfig) == runtimev1.FailurePolicyIgnore {
+				log.Info("Ignoring error calling extension", "extension", extensionConfig.Name, "hook", hook, "error", err.Error())
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to call extension %q for hook %s", extensionConfig.Name, hook)
+		}
+
+		if resp.RetryAfterSeconds > 0 {
+			if aggregated.RetryAfterSeconds == 0 || resp.RetryAfterSeconds < aggregated.RetryAfterSeconds {
+				aggregated.RetryAfterSeconds = resp.RetryAfterSeconds
+			}
+			if resp.Message != "" {
+				messages = append(messages, fmt.Sprintf("%s: %s", extensionConfig.Name, resp.Message))
+			} else {
+				messages = append(messages, extensionConfig.Name)
+			}
+		}
+	}
+	aggregated.Message = strings.Join(messages, "; ")
+
+	return aggregated, nil
+}
+
+// CallExtension calls the extension registered by the ExtensionConfig at the given path, relative to the
+// URL of the extension, and decodes the answer into response.
+// An error is returned if the call fails or if the status of the response is not Success.
+func CallExtension(ctx context.Context, extensionConfig *runtimev1.ExtensionConfig, path string, request interface{}, response runtimehooksv1.ResponseObject) error {
+	httpClient, err := httpClientFor(extensionConfig)
+	if err != nil {
+		return err
+	}
+
+	extensionURL, err := urlFor(extensionConfig, path)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, extensionURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return errors.Errorf("unexpected response status code %d: %s", httpResp.StatusCode, string(bytes.TrimSpace(msg)))
+	}
+
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxResponseSize)).Decode(response); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+
+	if response.GetStatus() != runtimehooksv1.ResponseStatusSuccess {
+		return errors.Errorf("extension returned status %q: %s", response.GetStatus(), response.GetMessage())
+	}
+	return nil
+}
+
+// FailurePolicy returns the failure policy of the ExtensionConfig, defaulting to Fail.
+func FailurePolicy(extensionConfig *runtimev1.ExtensionConfig) runtimev1.FailurePolicy {
+	if extensionConfig.Spec.FailurePolicy == nil {
+		return runtimev1.FailurePolicyFail
+	}
+	return *extensionConfig.Spec.FailurePolicy
+}
+
+// isRegisteredFor returns true if the extension is registered for the hook.
+func isRegisteredFor(extensionConfig *runtimev1.ExtensionConfig, hook runtimehooksv1.Hook) bool {
+	for _, h := range extensionConfig.Spec.Hooks {
+		if h == string(hook) {
+			return true
+		}
+	}
+	return false
+}
+
+// idleConnTimeout is the maximum amount of time an idle connection to an extension is kept open.
+const idleConnTimeout = 90 * time.Second
+
+// httpClients caches the http.Client used for each extension, so connections are reused across calls.
+var httpClients = &httpClientCache{
+	clients: map[string]*cachedHTTPClient{},
+}
+
+// httpClientCache stores an http.Client for each ExtensionConfig, keyed by name.
+// NOTE: Entries for deleted ExtensionConfigs are not removed, but their idle connections are closed
+// after idleConnTimeout.
+type httpClientCache struct {
+	lock    sync.Mutex
+	clients map[string]*cachedHTTPClient
+}
+
+// cachedHTTPClient is an http.Client together with the configuration it was built from.
+type cachedHTTPClient struct {
+	clientConfig runtimev1.ClientConfig
+	timeout      time.Duration
+	client       *http.Client
+}
+
+// httpClientFor returns an http.Client for calling the extension, configured with the timeout
+// and the CA bundle from the ExtensionConfig.
+// The client is reused across calls and it is rebuilt only if the client config or the timeout change;
+// in this case the idle connections of the previous client are closed.
+func httpClientFor(extensionConfig *runtimev1.ExtensionConfig) (*http.Client, error) {
+	return httpClients.get(extensionConfig)
+}
+
+func (c *httpClientCache) get(extensionConfig *runtimev1.ExtensionConfig) (*http.Client, error) {
+	timeoutSeconds := runtimev1.DefaultTimeoutSeconds
+	if extensionConfig.Spec.TimeoutSeconds != nil {
+		timeoutSeconds = *extensionConfig.Spec.TimeoutSeconds
+	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cached, ok := c.clients[extensionConfig.Name]
+	if ok && cached.timeout == timeout && apiequality.Semantic.DeepEqual(cached.clientConfig, extensionConfig.Spec.ClientConfig) {
+		return cached.client, nil
+	}
+
+	httpClient, err := newHTTPClient(extensionConfig, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.client.CloseIdleConnections()
+	}
+	c.clients[extensionConfig.Name] = &cachedHTTPClient{
+		clientConfig: *extensionConfig.Spec.ClientConfig.DeepCopy(),
+		timeout:      timeout,
+		client:       httpClient,
+	}
+	return httpClient, nil
+}
+
+// newHTTPClient returns a new http.Client with the given timeout, trusting the CA bundle from the ExtensionConfig.
+func newHTTPClient(extensionConfig *runtimev1.ExtensionConfig, timeout time.Duration) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(extensionConfig.Spec.ClientConfig.CABundle) > 0 {
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(extensionConfig.Spec.ClientConfig.CABundle) {
+			return nil, errors.New("failed to parse caBundle")
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+			IdleConnTimeout: idleConnTimeout,
+		},
+	}, nil
+}
+
+// urlFor returns the URL of the extension with the given path appended.
+func urlFor(extensionConfig *runtimev1.ExtensionConfig, path string) (string, error) {
+	clientConfig := extensionConfig.Spec.ClientConfig
+	switch {
+	case clientConfig.URL != nil:
+		u, err := url.Parse(*clientConfig.URL)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse url %q", *clientConfig.URL)
+		}
+		u.Path = joinPath(u.Path, path)
+		return u.String(), nil
+	case clientConfig.Service != nil:
+		port := runtimev1.DefaultServicePort
+		if clientConfig.Service.Port != nil {
+			port = *clientConfig.Service.Port
+		}
+		servicePath := ""
+		if clientConfig.Service.Path != nil {
+			servicePath = *clientConfig.Service.Path
+		}
+		return fmt.Sprintf("https://%s.%s.svc:%d%s", clientConfig.Service.Name, clientConfig.Service.Namespace, port, joinPath(servicePath, path)), nil
+	}
+	return "", errors.New("neither url nor service are set in clientConfig")
+}
+
+// joinPath appends path to base, avoiding duplicate slashes.
+func joinPath(base, path string) string {
+	if path == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
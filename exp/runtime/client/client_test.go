@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/server"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCallAllExtensions(t *testing.T) {
+	// blockingExtension returns a handler blocking the BeforeClusterUpgrade hook for the given number of seconds.
+	blockingExtension := func(retryAfterSeconds int32, message string) http.Handler {
+		return server.NewBeforeClusterUpgradeHandler(func(_ context.Context, _ *runtimehooksv1.BeforeClusterUpgradeRequest) (*runtimehooksv1.BeforeClusterUpgradeResponse, error) {
+			resp := &runtimehooksv1.BeforeClusterUpgradeResponse{}
+			resp.RetryAfterSeconds = retryAfterSeconds
+			resp.Message = message
+			return resp, nil
+		})
+	}
+	failingExtension := server.NewBeforeClusterUpgradeHandler(func(_ context.Context, _ *runtimehooksv1.BeforeClusterUpgradeRequest) (*runtimehooksv1.BeforeClusterUpgradeResponse, error) {
+		return nil, errors.New("migration failed")
+	})
+
+	type extension struct {
+		name          string
+		handler       http.Handler
+		hooks         []string
+		failurePolicy runtimev1.FailurePolicy
+	}
+	tests := []struct {
+		name       string
+		extensions []extension
+		want       *runtimehooksv1.CommonRetryResponse
+		wantErr    bool
+	}{
+		{
+			name: "Should not block if no extensions are registered for the hook",
+			extensions: []extension{
+				{name: "ext1", handler: blockingExtension(10, "not registered"), hooks: []string{"BeforeClusterCreate"}},
+			},
+			want: &runtimehooksv1.CommonRetryResponse{
+				CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess},
+			},
+		},
+		{
+			name: "Should block with the lowest retry if one or more extensions are blocking",
+			extensions: []extension{
+				{name: "ext1", handler: blockingExtension(20, "draining traffic"), hooks: []string{"BeforeClusterUpgrade"}},
+				{name: "ext2", handler: blockingExtension(0, ""), hooks: []string{"BeforeClusterUpgrade"}},
+				{name: "ext3", handler: blockingExtension(5, "running migrations"), hooks: []string{"BeforeClusterUpgrade"}},
+			},
+			want: &runtimehooksv1.CommonRetryResponse{
+				CommonResponse: runtimehooksv1.CommonResponse{
+					Status:  runtimehooksv1.ResponseStatusSuccess,
+					Message: "ext1: draining traffic; ext3: running migrations",
+				},
+				RetryAfterSeconds: 5,
+			},
+		},
+		{
+			name: "Should fail if an extension with failure policy Fail fails",
+			extensions: []extension{
+				{name: "ext1", handler: failingExtension, hooks: []string{"BeforeClusterUpgrade"}, failurePolicy: runtimev1.FailurePolicyFail},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Should skip an extension with failure policy Ignore if it fails",
+			extensions: []extension{
+				{name: "ext1", handler: failingExtension, hooks: []string{"BeforeClusterUpgrade"}, failurePolicy: runtimev1.FailurePolicyIgnore},
+			},
+			want: &runtimehooksv1.CommonRetryResponse{
+				CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := []client.Object{}
+			for _, ext := range tt.extensions {
+				mux := http.NewServeMux()
+				mux.Handle(runtimehooksv1.BeforeClusterUpgrade.Path(), ext.handler)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				extensionConfig := &runtimev1.ExtensionConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: ext.name},
+					Spec: runtimev1.ExtensionConfigSpec{
+						ClientConfig: runtimev1.ClientConfig{
+							URL:      pointer.String(srv.URL),
+							CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+						},
+						Hooks: ext.hooks,
+					},
+				}
+				if ext.failurePolicy != "" {
+					extensionConfig.Spec.FailurePolicy = &ext.failurePolicy
+				}
+				objs = append(objs, extensionConfig)
+			}
+
+			scheme := runtime.NewScheme()
+			g.Expect(runtimev1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+			got, err := New(c).CallAllExtensions(context.Background(), runtimehooksv1.BeforeClusterUpgrade, &runtimehooksv1.BeforeClusterUpgradeRequest{
+				FromKubernetesVersion: "v1.21.2",
+				ToKubernetesVersion:   "v1.22.0",
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestURLFor(t *testing.T) {
+	g := NewWithT(t)
+
+	extensionConfig := &runtimev1.ExtensionConfig{
+		Spec: runtimev1.ExtensionConfigSpec{
+			ClientConfig: runtimev1.ClientConfig{
+				Service: &runtimev1.ServiceReference{
+					Namespace: "ns1",
+					Name:      "svc1",
+					Path:      pointer.String("/generate-patches"),
+				},
+			},
+		},
+	}
+	u, err := urlFor(extensionConfig, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(u).To(Equal("https://svc1.ns1.svc:443/generate-patches"))
+
+	extensionConfig.Spec.ClientConfig.Service.Port = pointer.Int32(8443)
+	u, err = urlFor(extensionConfig, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(u).To(Equal("https://svc1.ns1.svc:8443/generate-patches"))
+
+	extensionConfig.Spec.ClientConfig.Service.Path = pointer.String("/hooks/")
+	u, err = urlFor(extensionConfig, runtimehooksv1.BeforeClusterDelete.Path())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(u).To(Equal("https://svc1.ns1.svc:8443/hooks/beforeclusterdelete"))
+
+	extensionConfig.Spec.ClientConfig = runtimev1.ClientConfig{URL: pointer.String("https://extension.example.com:8443")}
+	u, err = urlFor(extensionConfig, runtimehooksv1.BeforeClusterCreate.Path())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(u).To(Equal("https://extension.example.com:8443/beforeclustercreate"))
+}
+
+func TestHTTPClientFor(t *testing.T) {
+	g := NewWithT(t)
+
+	srv := httptest.NewTLSServer(http.NewServeMux())
+	defer srv.Close()
+	otherSrv := httptest.NewTLSServer(http.NewServeMux())
+	defer otherSrv.Close()
+
+	extensionConfig := &runtimev1.ExtensionConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "http-client-for"},
+		Spec: runtimev1.ExtensionConfigSpec{
+			ClientConfig: runtimev1.ClientConfig{
+				URL:      pointer.String(srv.URL),
+				CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+			},
+		},
+	}
+
+	httpClient, err := httpClientFor(extensionConfig)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(httpClient.Timeout).To(Equal(time.Duration(runtimev1.DefaultTimeoutSeconds) * time.Second))
+	g.Expect(httpClient.Transport.(*http.Transport).IdleConnTimeout).To(Equal(idleConnTimeout))
+
+	// The client is reused if the ExtensionConfig does not change.
+	got, err := httpClientFor(extensionConfig.DeepCopy())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeIdenticalTo(httpClient))
+
+	// The client is rebuilt if the timeout changes.
+	extensionConfig.Spec.TimeoutSeconds = pointer.Int32(5)
+	got, err = httpClientFor(extensionConfig)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).ToNot(BeIdenticalTo(httpClient))
+	g.Expect(got.Timeout).To(Equal(5 * time.Second))
+	httpClient = got
+
+	// The client is rebuilt if the URL and the CA bundle change.
+	extensionConfig.Spec.ClientConfig = runtimev1.ClientConfig{
+		URL:      pointer.String(otherSrv.URL),
+		CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherSrv.Certificate().Raw}),
+	}
+	got, err = httpClientFor(extensionConfig)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).ToNot(BeIdenticalTo(httpClient))
+
+	// An invalid CA bundle is reported.
+	extensionConfig.Spec.ClientConfig.CABundle = []byte("invalid")
+	_, err = httpClientFor(extensionConfig)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// ResponseStatus represents the status of an extension response.
+type ResponseStatus string
+
+const (
+	// ResponseStatusSuccess represents a success response.
+	ResponseStatusSuccess ResponseStatus = "Success"
+
+	// ResponseStatusFailure represents a failure response.
+	ResponseStatusFailure ResponseStatus = "Failure"
+)
+
+// ResponseObject is implemented by all the responses returned by extensions.
+type ResponseObject interface {
+	GetStatus() ResponseStatus
+	SetStatus(status ResponseStatus)
+	GetMessage() string
+	SetMessage(message string)
+}
+
+// CommonResponse contains the fields common to all the responses returned by extensions.
+type CommonResponse struct {
+	// Status of the call. One of "Success" or "Failure".
+	Status ResponseStatus `json:"status"`
+
+	// Message is a human-readable description of the status of the call, e.g. the reason of a failure.
+	Message string `json:"message,omitempty"`
+}
+
+// GetStatus returns the status of the response.
+func (r *CommonResponse) GetStatus() ResponseStatus {
+	return r.Status
+}
+
+// SetStatus sets the status of the response.
+func (r *CommonResponse) SetStatus(status ResponseStatus) {
+	r.Status = status
+}
+
+// GetMessage returns the message of the response.
+func (r *CommonResponse) GetMessage() string {
+	return r.Message
+}
+
+// SetMessage sets the message of the response.
+func (r *CommonResponse) SetMessage(message string) {
+	r.Message = message
+}
+
+// CommonRetryResponse contains the fields common to the responses of lifecycle hooks
+// that can block the operation they are called for.
+type CommonRetryResponse struct {
+	CommonResponse `json:",inline"`
+
+	// RetryAfterSeconds, if greater than zero, blocks the operation; the hook is called
+	// again after the given number of seconds.
+	RetryAfterSeconds int32 `json:"retryAfterSeconds,omitempty"`
+}
+
+// GetRetryAfterSeconds returns the number of seconds after which the hook should be called again.
+func (r *CommonRetryResponse) GetRetryAfterSeconds() int32 {
+	return r.RetryAfterSeconds
+}
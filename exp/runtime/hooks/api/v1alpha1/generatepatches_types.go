@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// TemplateType defines the type of a template.
+type TemplateType string
+
+const (
+	// InfrastructureClusterTemplateType identifies a template for the InfrastructureCluster object.
+	InfrastructureClusterTemplateType TemplateType = "InfrastructureClusterTemplate"
+
+	// ControlPlaneTemplateType identifies a template for the ControlPlane object.
+	ControlPlaneTemplateType TemplateType = "ControlPlaneTemplate"
+
+	// ControlPlaneInfrastructureMachineTemplateType identifies a template for the InfrastructureMachines to be used for the ControlPlane object.
+	ControlPlaneInfrastructureMachineTemplateType TemplateType = "ControlPlane/InfrastructureMachineTemplate"
+
+	// MachineDeploymentBootstrapConfigTemplateType identifies a template for the BootstrapConfig to be used for a MachineDeployment object.
+	MachineDeploymentBootstrapConfigTemplateType TemplateType = "MachineDeployment/BootstrapConfigTemplate"
+
+	// MachineDeploymentInfrastructureMachineTemplateType identifies a template for the InfrastructureMachines to be used for a MachineDeployment object.
+	MachineDeploymentInfrastructureMachineTemplateType TemplateType = "MachineDeployment/InfrastructureMachineTemplate"
+
+	// MachinePoolBootstrapConfigTemplateType identifies a template for the BootstrapConfig to be used for a MachinePool object.
+	MachinePoolBootstrapConfigTemplateType TemplateType = "MachinePool/BootstrapConfigTemplate"
+
+	// MachinePoolInfrastructureMachinePoolTemplateType identifies a template for the InfrastructureMachinePool to be used for a MachinePool object.
+	MachinePoolInfrastructureMachinePoolTemplateType TemplateType = "MachinePool/InfrastructureMachinePoolTemplate"
+)
+
+// PatchType defines the type of a patch.
+type PatchType string
+
+const (
+	// JSONPatchType identifies a https://datatracker.ietf.org/doc/html/rfc6902 JSON patch.
+	JSONPatchType PatchType = "JSONPatch"
+
+	// JSONMergePatchType identifies a https://datatracker.ietf.org/doc/html/rfc7386 JSON merge patch.
+	JSONMergePatchType PatchType = "JSONMergePatch"
+)
+
+// GeneratePatchesRequest is the request sent to an extension to generate patches for the templates of a ClusterClass.
+type GeneratePatchesRequest struct {
+	// Variables is a name/value map containing variables, including builtin variables.
+	Variables map[string]apiextensionsv1.JSON `json:"variables,omitempty"`
+
+	// Items contains the list of templates to generate patches for.
+	Items []GeneratePatchesRequestItem `json:"items"`
+}
+
+// GeneratePatchesRequestItem represents a template to generate patches for.
+type GeneratePatchesRequestItem struct {
+	// TemplateRef identifies the template; the same TemplateRef must be used in the response
+	// when returning patches for this template.
+	TemplateRef TemplateRef `json:"templateRef"`
+
+	// Variables is a name/value map containing variables specific to this template, e.g.
+	// the builtin variables of the MachineDeployment the template belongs to.
+	Variables map[string]apiextensionsv1.JSON `json:"variables,omitempty"`
+
+	// Template contains the template, including the changes applied by previous patches.
+	Template apiextensionsv1.JSON `json:"template"`
+}
+
+// TemplateRef identifies a template.
+type TemplateRef struct {
+	// APIVersion of the template.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the template.
+	Kind string `json:"kind"`
+
+	// TemplateType defines where the template is used.
+	TemplateType TemplateType `json:"templateType"`
+
+	// MachineDeploymentRef specifies the MachineDeployment in which the template is used.
+	// This field is only set if the template is used in the context of a MachineDeployment.
+	MachineDeploymentRef MachineDeploymentRef `json:"machineDeploymentRef,omitempty"`
+
+	// MachinePoolRef specifies the MachinePool in which the template is used.
+	// This field is only set if the template is used in the context of a MachinePool.
+	MachinePoolRef MachinePoolRef `json:"machinePoolRef,omitempty"`
+}
+
+// MachineDeploymentRef specifies the MachineDeployment in which a template is used.
+type MachineDeploymentRef struct {
+	// TopologyName is the name of the MachineDeploymentTopology.
+	TopologyName string `json:"topologyName,omitempty"`
+
+	// Class is the name of the MachineDeploymentClass.
+	Class string `json:"class,omitempty"`
+}
+
+// MachinePoolRef specifies the MachinePool in which a template is used.
+type MachinePoolRef struct {
+	// TopologyName is the name of the MachinePoolTopology.
+	TopologyName string `json:"topologyName,omitempty"`
+
+	// Class is the name of the MachinePoolClass.
+	Class string `json:"class,omitempty"`
+}
+
+// GeneratePatchesResponse is the response of an extension to a GeneratePatchesRequest.
+type GeneratePatchesResponse struct {
+	CommonResponse `json:",inline"`
+
+	// Items contains the list of generated patches.
+	Items []GeneratePatchesResponseItem `json:"items,omitempty"`
+}
+
+// GeneratePatchesResponseItem is a generated patch targeting a template of the request.
+type GeneratePatchesResponseItem struct {
+	// TemplateRef identifies the template the patch should be applied to.
+	TemplateRef TemplateRef `json:"templateRef"`
+
+	// Patch contains the patch.
+	Patch apiextensionsv1.JSON `json:"patch"`
+
+	// PatchType defines the type of the patch. One of "JSONPatch" or "JSONMergePatch".
+	PatchType PatchType `json:"patchType"`
+}
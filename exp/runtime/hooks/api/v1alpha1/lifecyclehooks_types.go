@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Hook is the name of a lifecycle hook.
+type Hook string
+
+const (
+	// BeforeClusterCreate is called before the objects of a Cluster with a managed topology are created.
+	BeforeClusterCreate Hook = "BeforeClusterCreate"
+
+	// BeforeClusterUpgrade is called before the control plane of a Cluster with a managed topology
+	// is upgraded to a new Kubernetes version.
+	BeforeClusterUpgrade Hook = "BeforeClusterUpgrade"
+
+	// AfterControlPlaneUpgrade is called after the control plane of a Cluster with a managed topology
+	// has been upgraded, before the MachineDeployments are upgraded.
+	AfterControlPlaneUpgrade Hook = "AfterControlPlaneUpgrade"
+
+	// BeforeClusterDelete is called before a Cluster with a managed topology is deleted.
+	BeforeClusterDelete Hook = "BeforeClusterDelete"
+)
+
+// LifecycleHooks is the list of all the lifecycle hooks.
+var LifecycleHooks = []Hook{
+	BeforeClusterCreate,
+	BeforeClusterUpgrade,
+	AfterControlPlaneUpgrade,
+	BeforeClusterDelete,
+}
+
+// Path returns the path the hook is served at, relative to the URL of the extension,
+// e.g. "/beforeclustercreate".
+func (h Hook) Path() string {
+	return "/" + strings.ToLower(string(h))
+}
+
+// BeforeClusterCreateRequest is the request of the BeforeClusterCreate hook.
+type BeforeClusterCreateRequest struct {
+	// Cluster is the Cluster about to be created.
+	Cluster clusterv1.Cluster `json:"cluster"`
+}
+
+// BeforeClusterCreateResponse is the response of the BeforeClusterCreate hook.
+type BeforeClusterCreateResponse struct {
+	CommonRetryResponse `json:",inline"`
+}
+
+// BeforeClusterUpgradeRequest is the request of the BeforeClusterUpgrade hook.
+type BeforeClusterUpgradeRequest struct {
+	// Cluster is the Cluster about to be upgraded.
+	Cluster clusterv1.Cluster `json:"cluster"`
+
+	// FromKubernetesVersion is the current Kubernetes version of the control plane.
+	FromKubernetesVersion string `json:"fromKubernetesVersion"`
+
+	// ToKubernetesVersion is the Kubernetes version the control plane is going to be upgraded to.
+	ToKubernetesVersion string `json:"toKubernetesVersion"`
+}
+
+// BeforeClusterUpgradeResponse is the response of the BeforeClusterUpgrade hook.
+type BeforeClusterUpgradeResponse struct {
+	CommonRetryResponse `json:",inline"`
+}
+
+// AfterControlPlaneUpgradeRequest is the request of the AfterControlPlaneUpgrade hook.
+type AfterControlPlaneUpgradeRequest struct {
+	// Cluster is the Cluster whose control plane has been upgraded.
+	Cluster clusterv1.Cluster `json:"cluster"`
+
+	// KubernetesVersion is the Kubernetes version of the control plane after the upgrade.
+	KubernetesVersion string `json:"kubernetesVersion"`
+}
+
+// AfterControlPlaneUpgradeResponse is the response of the AfterControlPlaneUpgrade hook.
+type AfterControlPlaneUpgradeResponse struct {
+	CommonRetryResponse `json:",inline"`
+}
+
+// BeforeClusterDeleteRequest is the request of the BeforeClusterDelete hook.
+type BeforeClusterDeleteRequest struct {
+	// Cluster is the Cluster about to be deleted.
+	Cluster clusterv1.Cluster `json:"cluster"`
+}
+
+// BeforeClusterDeleteResponse is the response of the BeforeClusterDelete hook.
+type BeforeClusterDeleteResponse struct {
+	CommonRetryResponse `json:",inline"`
+}
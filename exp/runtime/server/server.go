@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultPort is the default port the server listens on.
+	DefaultPort = 9443
+
+	// DefaultCertDir is the default directory containing the server certificate and key.
+	DefaultCertDir = "/tmp/runtime-extension/serving-certs/"
+
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 3 * 1024 * 1024
+)
+
+// GeneratePatchesFunc generates patches for the templates in a GeneratePatchesRequest.
+// Errors returned by the func are reported as a response with status Failure.
+type GeneratePatchesFunc func(ctx context.Context, req *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error)
+
+// NewGeneratePatchesHandler returns an http.Handler serving GeneratePatchesRequests with the given func.
+func NewGeneratePatchesHandler(generatePatches GeneratePatchesFunc) http.Handler {
+	return newHandler(
+		func() interface{} { return &runtimehooksv1.GeneratePatchesRequest{} },
+		func() runtimehooksv1.ResponseObject { return &runtimehooksv1.GeneratePatchesResponse{} },
+		func(ctx context.Context, req interface{}) (runtimehooksv1.ResponseObject, error) {
+			return generatePatches(ctx, req.(*runtimehooksv1.GeneratePatchesRequest))
+		},
+	)
+}
+
+// BeforeClusterCreateFunc handles the BeforeClusterCreate hook.
+// Errors returned by the func are reported as a response with status Failure.
+type BeforeClusterCreateFunc func(ctx context.Context, req *runtimehooksv1.BeforeClusterCreateRequest) (*runtimehooksv1.BeforeClusterCreateResponse, error)
+
+// NewBeforeClusterCreateHandler returns an http.Handler serving the BeforeClusterCreate hook with the given func.
+func NewBeforeClusterCreateHandler(beforeClusterCreate BeforeClusterCreateFunc) http.Handler {
+	return newHandler(
+		func() interface{} { return &runtimehooksv1.BeforeClusterCreateRequest{} },
+		func() runtimehooksv1.ResponseObject { return &runtimehooksv1.BeforeClusterCreateResponse{} },
+		func(ctx context.Context, req interface{}) (runtimehooksv1.ResponseObject, error) {
+			return beforeClusterCreate(ctx, req.(*runtimehooksv1.BeforeClusterCreateRequest))
+		},
+	)
+}
+
+// BeforeClusterUpgradeFunc handles the BeforeClusterUpgrade hook.
+// Errors returned by the func are reported as a response with status Failure.
+type BeforeClusterUpgradeFunc func(ctx context.Context, req *runtimehooksv1.BeforeClusterUpgradeRequest) (*runtimehooksv1.BeforeClusterUpgradeResponse, error)
+
+// NewBeforeClusterUpgradeHandler returns an http.Handler serving the BeforeClusterUpgrade hook with the given func.
+func NewBeforeClusterUpgradeHandler(beforeClusterUpgrade BeforeClusterUpgradeFunc) http.Handler {
+	return newHandler(
+		func() interface{} { return &runtimehooksv1.BeforeClusterUpgradeRequest{} },
+		func() runtimehooksv1.ResponseObject { return &runtimehooksv1.BeforeClusterUpgradeResponse{} },
+		func(ctx context.Context, req interface{}) (runtimehooksv1.ResponseObject, error) {
+			return beforeClusterUpgrade(ctx, req.(*runtimehooksv1.BeforeClusterUpgradeRequest))
+		},
+	)
+}
+
+// AfterControlPlaneUpgradeFunc handles the AfterControlPlaneUpgrade hook.
+// Errors returned by the func are reported as a response with status Failure.
+type AfterControlPlaneUpgradeFunc func(ctx context.Context, req *runtimehooksv1.AfterControlPlaneUpgradeRequest) (*runtimehooksv1.AfterControlPlaneUpgradeResponse, error)
+
+// NewAfterControlPlaneUpgradeHandler returns an http.Handler serving the AfterControlPlaneUpgrade hook with the given func.
+func NewAfterControlPlaneUpgradeHandler(afterControlPlaneUpgrade AfterControlPlaneUpgradeFunc) http.Handler {
+	return newHandler(
+		func() interface{} { return &runtimehooksv1.AfterControlPlaneUpgradeRequest{} },
+		func() runtimehooksv1.ResponseObject { return &runtimehooksv1.AfterControlPlaneUpgradeResponse{} },
+		func(ctx context.Context, req interface{}) (runtimehooksv1.ResponseObject, error) {
+			return afterControlPlaneUpgrade(ctx, req.(*runtimehooksv1.AfterControlPlaneUpgradeRequest))
+		},
+	)
+}
+
+// BeforeClusterDeleteFunc handles the BeforeClusterDelete hook.
+// Errors returned by the func are reported as a response with status Failure.
+type BeforeClusterDeleteFunc func(ctx context.Context, req *runtimehooksv1.BeforeClusterDeleteRequest) (*runtimehooksv1.BeforeClusterDeleteResponse, error)
+
+// NewBeforeClusterDeleteHandler returns an http.Handler serving the BeforeClusterDelete hook with the given func.
+func NewBeforeClusterDeleteHandler(beforeClusterDelete BeforeClusterDeleteFunc) http.Handler {
+	return newHandler(
+		func() interface{} { return &runtimehooksv1.BeforeClusterDeleteRequest{} },
+		func() runtimehooksv1.ResponseObject { return &runtimehooksv1.BeforeClusterDeleteResponse{} },
+		func(ctx context.Context, req interface{}) (runtimehooksv1.ResponseObject, error) {
+			return beforeClusterDelete(ctx, req.(*runtimehooksv1.BeforeClusterDeleteRequest))
+		},
+	)
+}
+
+// newHandler returns an http.Handler decoding the request returned by newRequest, calling handle with it and
+// encoding the response. Errors returned by handle are reported in an empty response from newResponse with status Failure.
+func newHandler(newRequest func() interface{}, newResponse func() runtimehooksv1.ResponseObject, handle func(context.Context, interface{}) (runtimehooksv1.ResponseObject, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := ctrl.Log.WithName("runtime-extension")
+
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("method %s is not supported", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		req := newRequest()
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		resp, err := handle(r.Context(), req)
+		if err != nil {
+			log.Error(err, "Failed to handle request", "path", r.URL.Path)
+			resp = newResponse()
+			resp.SetStatus(runtimehooksv1.ResponseStatusFailure)
+			resp.SetMessage(err.Error())
+		}
+		if resp.GetStatus() == "" {
+			resp.SetStatus(runtimehooksv1.ResponseStatusSuccess)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error(err, "Failed to write response")
+		}
+	})
+}
+
+// Options are the options for the Server.
+type Options struct {
+	// Host is the address the server listens on. Defaults to all addresses.
+	Host string
+
+	// Port is the port the server listens on. Defaults to DefaultPort.
+	Port int
+
+	// CertDir is the directory containing the server certificate and key,
+	// named tls.crt and tls.key. Defaults to DefaultCertDir.
+	CertDir string
+}
+
+// Server serves extensions over HTTPS.
+type Server struct {
+	options Options
+	mux     *http.ServeMux
+}
+
+// New returns a new Server.
+func New(options Options) *Server {
+	if options.Port == 0 {
+		options.Port = DefaultPort
+	}
+	if options.CertDir == "" {
+		options.CertDir = DefaultCertDir
+	}
+	return &Server{
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+}
+
+// Handle registers the handler for the given path.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, handler)
+}
+
+// Start runs the server until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(s.options.CertDir, "tls.crt"), filepath.Join(s.options.CertDir, "tls.key"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to load certificate from %s", s.options.CertDir)
+	}
+
+	listener, err := tls.Listen("tcp", net.JoinHostPort(s.options.Host, fmt.Sprint(s.options.Port)), &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
+	}
+
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	idleConnsClosed := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		// NOTE: Using a new context, because the one passed in is already done.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		close(idleConnsClosed)
+	}()
+
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "failed to serve")
+	}
+
+	<-idleConnsClosed
+	return nil
+}
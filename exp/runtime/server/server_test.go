@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+)
+
+func TestGeneratePatchesHandler(t *testing.T) {
+	generatePatches := func(_ context.Context, req *runtimehooksv1.GeneratePatchesRequest) (*runtimehooksv1.GeneratePatchesResponse, error) {
+		if len(req.Items) == 0 {
+			return nil, errors.New("no items")
+		}
+		return &runtimehooksv1.GeneratePatchesResponse{}, nil
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		wantStatusCode int
+		wantStatus     runtimehooksv1.ResponseStatus
+	}{
+		{
+			name:           "Should return Success",
+			method:         http.MethodPost,
+			body:           `{"items":[{"templateRef":{"apiVersion":"v1","kind":"Foo","templateType":"ControlPlaneTemplate"},"template":{}}]}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     runtimehooksv1.ResponseStatusSuccess,
+		},
+		{
+			name:           "Should return Failure if the func returns an error",
+			method:         http.MethodPost,
+			body:           `{"items":[]}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     runtimehooksv1.ResponseStatusFailure,
+		},
+		{
+			name:           "Should reject requests which can't be decoded",
+			method:         http.MethodPost,
+			body:           `{"items":`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Should reject methods other than POST",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := httptest.NewRequest(tt.method, "/generate-patches", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			NewGeneratePatchesHandler(generatePatches).ServeHTTP(rec, req)
+
+			g.Expect(rec.Code).To(Equal(tt.wantStatusCode))
+			if tt.wantStatusCode != http.StatusOK {
+				return
+			}
+			resp := &runtimehooksv1.GeneratePatchesResponse{}
+			g.Expect(json.Unmarshal(rec.Body.Bytes(), resp)).To(Succeed())
+			g.Expect(resp.Status).To(Equal(tt.wantStatus))
+		})
+	}
+}
+
+func TestBeforeClusterUpgradeHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	beforeClusterUpgrade := func(_ context.Context, req *runtimehooksv1.BeforeClusterUpgradeRequest) (*runtimehooksv1.BeforeClusterUpgradeResponse, error) {
+		if req.ToKubernetesVersion == "" {
+			return nil, errors.New("no target version")
+		}
+		resp := &runtimehooksv1.BeforeClusterUpgradeResponse{}
+		resp.RetryAfterSeconds = 10
+		return resp, nil
+	}
+	handler := NewBeforeClusterUpgradeHandler(beforeClusterUpgrade)
+
+	req := httptest.NewRequest(http.MethodPost, runtimehooksv1.BeforeClusterUpgrade.Path(), strings.NewReader(`{"cluster":{},"fromKubernetesVersion":"v1.21.2","toKubernetesVersion":"v1.22.0"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	resp := &runtimehooksv1.BeforeClusterUpgradeResponse{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), resp)).To(Succeed())
+	g.Expect(resp.Status).To(Equal(runtimehooksv1.ResponseStatusSuccess))
+	g.Expect(resp.RetryAfterSeconds).To(Equal(int32(10)))
+
+	req = httptest.NewRequest(http.MethodPost, runtimehooksv1.BeforeClusterUpgrade.Path(), strings.NewReader(`{"cluster":{}}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	resp = &runtimehooksv1.BeforeClusterUpgradeResponse{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), resp)).To(Succeed())
+	g.Expect(resp.Status).To(Equal(runtimehooksv1.ResponseStatusFailure))
+	g.Expect(resp.Message).To(Equal("no target version"))
+}
@@ -4,6 +4,10 @@ go 1.16
 
 require (
 	github.com/MakeNowJust/heredoc v1.0.0
+	github.com/aws/aws-sdk-go-v2 v1.10.0
+	github.com/aws/aws-sdk-go-v2/credentials v1.5.0
+	github.com/aws/aws-sdk-go-v2/feature/s3/manager v1.6.0
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.17.0
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/coredns/corefile-migration v1.0.12
 	github.com/davecgh/go-spew v1.1.1
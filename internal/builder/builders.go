@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -94,7 +95,9 @@ type ClusterClassBuilder struct {
 	controlPlaneMetadata                      *clusterv1.ObjectMeta
 	controlPlaneTemplate                      *unstructured.Unstructured
 	controlPlaneInfrastructureMachineTemplate *unstructured.Unstructured
+	controlPlaneMachineHealthCheck            *clusterv1.MachineHealthCheckClass
 	machineDeploymentClasses                  []clusterv1.MachineDeploymentClass
+	machinePoolClasses                        []clusterv1.MachinePoolClass
 }
 
 // ClusterClass returns a ClusterClassBuilder with the given name and namespace.
@@ -132,6 +135,12 @@ func (c *ClusterClassBuilder) WithControlPlaneInfrastructureMachineTemplate(t *u
 	return c
 }
 
+// WithControlPlaneMachineHealthCheck adds a MachineHealthCheck for the ControlPlane to the ClusterClassBuilder.
+func (c *ClusterClassBuilder) WithControlPlaneMachineHealthCheck(mhc *clusterv1.MachineHealthCheckClass) *ClusterClassBuilder {
+	c.controlPlaneMachineHealthCheck = mhc
+	return c
+}
+
 // WithWorkerMachineDeploymentClasses adds the variables and objects needed to create MachineDeploymentTemplates for a ClusterClassBuilder.
 func (c *ClusterClassBuilder) WithWorkerMachineDeploymentClasses(mdcs []clusterv1.MachineDeploymentClass) *ClusterClassBuilder {
 	if c.machineDeploymentClasses == nil {
@@ -141,6 +150,15 @@ func (c *ClusterClassBuilder) WithWorkerMachineDeploymentClasses(mdcs []clusterv
 	return c
 }
 
+// WithWorkerMachinePoolClasses adds the variables and objects needed to create MachinePools for a ClusterClassBuilder.
+func (c *ClusterClassBuilder) WithWorkerMachinePoolClasses(mpcs []clusterv1.MachinePoolClass) *ClusterClassBuilder {
+	if c.machinePoolClasses == nil {
+		c.machinePoolClasses = make([]clusterv1.MachinePoolClass, 0)
+	}
+	c.machinePoolClasses = append(c.machinePoolClasses, mpcs...)
+	return c
+}
+
 // Build takes the objects and variables in the ClusterClass builder and uses them to create a ClusterClass object.
 func (c *ClusterClassBuilder) Build() *clusterv1.ClusterClass {
 	obj := &clusterv1.ClusterClass{
@@ -172,7 +190,11 @@ func (c *ClusterClassBuilder) Build() *clusterv1.ClusterClass {
 			Ref: objToRef(c.controlPlaneInfrastructureMachineTemplate),
 		}
 	}
+	if c.controlPlaneMachineHealthCheck != nil {
+		obj.Spec.ControlPlane.MachineHealthCheck = c.controlPlaneMachineHealthCheck
+	}
 	obj.Spec.Workers.MachineDeployments = c.machineDeploymentClasses
+	obj.Spec.Workers.MachinePools = c.machinePoolClasses
 	return obj
 }
 
@@ -185,6 +207,7 @@ type MachineDeploymentClassBuilder struct {
 	bootstrapTemplate             *unstructured.Unstructured
 	labels                        map[string]string
 	annotations                   map[string]string
+	machineHealthCheckClass       *clusterv1.MachineHealthCheckClass
 }
 
 // MachineDeploymentClass returns a MachineDeploymentClassBuilder with the given name and namespace.
@@ -225,6 +248,12 @@ func (m *MachineDeploymentClassBuilder) WithAnnotations(annotations map[string]s
 	return m
 }
 
+// WithMachineHealthCheckClass sets the MachineHealthCheckClass for the MachineDeploymentClassBuilder.
+func (m *MachineDeploymentClassBuilder) WithMachineHealthCheckClass(mhc *clusterv1.MachineHealthCheckClass) *MachineDeploymentClassBuilder {
+	m.machineHealthCheckClass = mhc
+	return m
+}
+
 // Build creates a full MachineDeploymentClass object with the variables passed to the MachineDeploymentClassBuilder.
 func (m *MachineDeploymentClassBuilder) Build() *clusterv1.MachineDeploymentClass {
 	return &clusterv1.MachineDeploymentClass{
@@ -241,6 +270,75 @@ func (m *MachineDeploymentClassBuilder) Build() *clusterv1.MachineDeploymentClas
 				Ref: objToRef(m.infrastructureMachineTemplate),
 			},
 		},
+		MachineHealthCheck: m.machineHealthCheckClass,
+	}
+}
+
+// MachinePoolClassBuilder holds the variables and objects required to build a clusterv1.MachinePoolClass.
+type MachinePoolClassBuilder struct {
+	namespace                         string
+	name                              string
+	class                             string
+	infrastructureMachinePoolTemplate *unstructured.Unstructured
+	bootstrapTemplate                 *unstructured.Unstructured
+	labels                            map[string]string
+	annotations                       map[string]string
+}
+
+// MachinePoolClass returns a MachinePoolClassBuilder with the given name and namespace.
+func MachinePoolClass(namespace, name string) *MachinePoolClassBuilder {
+	return &MachinePoolClassBuilder{
+		name:      name,
+		namespace: namespace,
+	}
+}
+
+// WithInfrastructureTemplate registers the passed Unstructured object as the InfrastructureMachinePoolTemplate for the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) WithInfrastructureTemplate(t *unstructured.Unstructured) *MachinePoolClassBuilder {
+	m.infrastructureMachinePoolTemplate = t
+	return m
+}
+
+// WithBootstrapTemplate registers the passed Unstructured object as the BootstrapTemplate for the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) WithBootstrapTemplate(t *unstructured.Unstructured) *MachinePoolClassBuilder {
+	m.bootstrapTemplate = t
+	return m
+}
+
+// WithClass sets the MachinePoolClass name for the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) WithClass(class string) *MachinePoolClassBuilder {
+	m.class = class
+	return m
+}
+
+// WithLabels sets the labels for the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) WithLabels(labels map[string]string) *MachinePoolClassBuilder {
+	m.labels = labels
+	return m
+}
+
+// WithAnnotations sets the annotations for the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) WithAnnotations(annotations map[string]string) *MachinePoolClassBuilder {
+	m.annotations = annotations
+	return m
+}
+
+// Build creates a full MachinePoolClass object with the variables passed to the MachinePoolClassBuilder.
+func (m *MachinePoolClassBuilder) Build() *clusterv1.MachinePoolClass {
+	return &clusterv1.MachinePoolClass{
+		Class: m.class,
+		Template: clusterv1.MachinePoolClassTemplate{
+			Metadata: clusterv1.ObjectMeta{
+				Labels:      m.labels,
+				Annotations: m.annotations,
+			},
+			Bootstrap: clusterv1.LocalObjectTemplate{
+				Ref: objToRef(m.bootstrapTemplate),
+			},
+			Infrastructure: clusterv1.LocalObjectTemplate{
+				Ref: objToRef(m.infrastructureMachinePoolTemplate),
+			},
+		},
 	}
 }
 
@@ -279,8 +377,9 @@ func (i *InfrastructureMachineTemplateBuilder) Build() *unstructured.Unstructure
 
 // BootstrapTemplateBuilder holds the variables needed to build a generic BootstrapTemplate.
 type BootstrapTemplateBuilder struct {
-	namespace string
-	name      string
+	namespace  string
+	name       string
+	specFields map[string]interface{}
 }
 
 // BootstrapTemplate creates a BootstrapTemplateBuilder with the given name and namespace.
@@ -291,6 +390,12 @@ func BootstrapTemplate(namespace, name string) *BootstrapTemplateBuilder {
 	}
 }
 
+// WithSpecFields will add fields of any type to the object spec. It takes an argument, fields, which is of the form path: object.
+func (b *BootstrapTemplateBuilder) WithSpecFields(fields map[string]interface{}) *BootstrapTemplateBuilder {
+	b.specFields = fields
+	return b
+}
+
 // Build creates a new Unstructured object with the information passed to the BootstrapTemplateBuilder.
 func (b *BootstrapTemplateBuilder) Build() *unstructured.Unstructured {
 	obj := &unstructured.Unstructured{}
@@ -299,6 +404,106 @@ func (b *BootstrapTemplateBuilder) Build() *unstructured.Unstructured {
 	obj.SetNamespace(b.namespace)
 	obj.SetName(b.name)
 
+	setSpecFields(obj, b.specFields)
+	return obj
+}
+
+// InfrastructureMachinePoolTemplateBuilder holds the variables needed to build a generic InfrastructureMachinePoolTemplate.
+type InfrastructureMachinePoolTemplateBuilder struct {
+	namespace  string
+	name       string
+	specFields map[string]interface{}
+}
+
+// InfrastructureMachinePoolTemplate creates an InfrastructureMachinePoolTemplateBuilder with the given name and namespace.
+func InfrastructureMachinePoolTemplate(namespace, name string) *InfrastructureMachinePoolTemplateBuilder {
+	return &InfrastructureMachinePoolTemplateBuilder{
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// WithSpecFields will add fields of any type to the object spec. It takes an argument, fields, which is of the form path: object.
+func (i *InfrastructureMachinePoolTemplateBuilder) WithSpecFields(fields map[string]interface{}) *InfrastructureMachinePoolTemplateBuilder {
+	i.specFields = fields
+	return i
+}
+
+// Build takes the objects and variables in the InfrastructureMachinePoolTemplateBuilder and generates an unstructured object.
+func (i *InfrastructureMachinePoolTemplateBuilder) Build() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(InfrastructureGroupVersion.String())
+	obj.SetKind(GenericInfrastructureMachinePoolTemplateKind)
+	obj.SetNamespace(i.namespace)
+	obj.SetName(i.name)
+
+	setSpecFields(obj, i.specFields)
+	return obj
+}
+
+// InfrastructureMachinePoolBuilder holds the variables needed to build a generic InfrastructureMachinePool.
+type InfrastructureMachinePoolBuilder struct {
+	namespace  string
+	name       string
+	specFields map[string]interface{}
+}
+
+// InfrastructureMachinePool creates an InfrastructureMachinePoolBuilder with the given name and namespace.
+func InfrastructureMachinePool(namespace, name string) *InfrastructureMachinePoolBuilder {
+	return &InfrastructureMachinePoolBuilder{
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// WithSpecFields will add fields of any type to the object spec. It takes an argument, fields, which is of the form path: object.
+func (i *InfrastructureMachinePoolBuilder) WithSpecFields(fields map[string]interface{}) *InfrastructureMachinePoolBuilder {
+	i.specFields = fields
+	return i
+}
+
+// Build takes the objects and variables in the InfrastructureMachinePoolBuilder and generates an unstructured object.
+func (i *InfrastructureMachinePoolBuilder) Build() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(InfrastructureGroupVersion.String())
+	obj.SetKind(GenericInfrastructureMachinePoolKind)
+	obj.SetNamespace(i.namespace)
+	obj.SetName(i.name)
+
+	setSpecFields(obj, i.specFields)
+	return obj
+}
+
+// BootstrapConfigBuilder holds the variables needed to build a generic BootstrapConfig.
+type BootstrapConfigBuilder struct {
+	namespace  string
+	name       string
+	specFields map[string]interface{}
+}
+
+// BootstrapConfig creates a BootstrapConfigBuilder with the given name and namespace.
+func BootstrapConfig(namespace, name string) *BootstrapConfigBuilder {
+	return &BootstrapConfigBuilder{
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// WithSpecFields will add fields of any type to the object spec. It takes an argument, fields, which is of the form path: object.
+func (b *BootstrapConfigBuilder) WithSpecFields(fields map[string]interface{}) *BootstrapConfigBuilder {
+	b.specFields = fields
+	return b
+}
+
+// Build creates a new Unstructured object with the information passed to the BootstrapConfigBuilder.
+func (b *BootstrapConfigBuilder) Build() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(BootstrapGroupVersion.String())
+	obj.SetKind(GenericBootstrapConfigKind)
+	obj.SetNamespace(b.namespace)
+	obj.SetName(b.name)
+
+	setSpecFields(obj, b.specFields)
 	return obj
 }
 
@@ -580,6 +785,101 @@ func (m *MachineDeploymentBuilder) Build() *clusterv1.MachineDeployment {
 	return obj
 }
 
+// MachinePoolBuilder holds the variables and objects needed to build a generic MachinePool.
+type MachinePoolBuilder struct {
+	namespace      string
+	name           string
+	bootstrap      *unstructured.Unstructured
+	infrastructure *unstructured.Unstructured
+	version        *string
+	replicas       *int32
+	generation     *int64
+	labels         map[string]string
+	status         *expv1.MachinePoolStatus
+}
+
+// MachinePool creates a MachinePoolBuilder with the given name and namespace.
+func MachinePool(namespace, name string) *MachinePoolBuilder {
+	return &MachinePoolBuilder{
+		name:      name,
+		namespace: namespace,
+	}
+}
+
+// WithBootstrap adds the passed Unstructured object to the MachinePoolBuilder as a bootstrap config.
+func (m *MachinePoolBuilder) WithBootstrap(ref *unstructured.Unstructured) *MachinePoolBuilder {
+	m.bootstrap = ref
+	return m
+}
+
+// WithInfrastructure adds the passed Unstructured object to the MachinePoolBuilder as an infrastructure machine pool.
+func (m *MachinePoolBuilder) WithInfrastructure(ref *unstructured.Unstructured) *MachinePoolBuilder {
+	m.infrastructure = ref
+	return m
+}
+
+// WithLabels adds the given labels to the MachinePoolBuilder.
+func (m *MachinePoolBuilder) WithLabels(labels map[string]string) *MachinePoolBuilder {
+	m.labels = labels
+	return m
+}
+
+// WithVersion sets the passed version on the machine pool spec.
+func (m *MachinePoolBuilder) WithVersion(version string) *MachinePoolBuilder {
+	m.version = &version
+	return m
+}
+
+// WithReplicas sets the number of replicas for the MachinePoolBuilder.
+func (m *MachinePoolBuilder) WithReplicas(replicas int32) *MachinePoolBuilder {
+	m.replicas = &replicas
+	return m
+}
+
+// WithGeneration sets the passed value on the machine pool object metadata.
+func (m *MachinePoolBuilder) WithGeneration(generation int64) *MachinePoolBuilder {
+	m.generation = &generation
+	return m
+}
+
+// WithStatus sets the passed status object as the status of the machine pool object.
+func (m *MachinePoolBuilder) WithStatus(status expv1.MachinePoolStatus) *MachinePoolBuilder {
+	m.status = &status
+	return m
+}
+
+// Build creates a new MachinePool with the variables and objects passed to the MachinePoolBuilder.
+func (m *MachinePoolBuilder) Build() *expv1.MachinePool {
+	obj := &expv1.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachinePool",
+			APIVersion: expv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.name,
+			Namespace: m.namespace,
+			Labels:    m.labels,
+		},
+	}
+	if m.generation != nil {
+		obj.Generation = *m.generation
+	}
+	if m.version != nil {
+		obj.Spec.Template.Spec.Version = m.version
+	}
+	obj.Spec.Replicas = m.replicas
+	if m.bootstrap != nil {
+		obj.Spec.Template.Spec.Bootstrap.ConfigRef = objToRef(m.bootstrap)
+	}
+	if m.infrastructure != nil {
+		obj.Spec.Template.Spec.InfrastructureRef = *objToRef(m.infrastructure)
+	}
+	if m.status != nil {
+		obj.Status = *m.status
+	}
+	return obj
+}
+
 // MachineSetBuilder holds the variables and objects needed to build a generic MachineSet.
 type MachineSetBuilder struct {
 	namespace              string
@@ -34,6 +34,16 @@ var (
 	// GenericInfrastructureMachineTemplateCRD is a generic infrastructure machine template CRD.
 	GenericInfrastructureMachineTemplateCRD = generateCRD(InfrastructureGroupVersion.WithKind(GenericInfrastructureMachineTemplateKind))
 
+	// GenericInfrastructureMachinePoolKind is the Kind for the GenericInfrastructureMachinePool.
+	GenericInfrastructureMachinePoolKind = "GenericInfrastructureMachinePool"
+	// GenericInfrastructureMachinePoolCRD is a generic infrastructure machine pool CRD.
+	GenericInfrastructureMachinePoolCRD = generateCRD(InfrastructureGroupVersion.WithKind(GenericInfrastructureMachinePoolKind))
+
+	// GenericInfrastructureMachinePoolTemplateKind is the Kind for the GenericInfrastructureMachinePoolTemplate.
+	GenericInfrastructureMachinePoolTemplateKind = "GenericInfrastructureMachinePoolTemplate"
+	// GenericInfrastructureMachinePoolTemplateCRD is a generic infrastructure machine pool template CRD.
+	GenericInfrastructureMachinePoolTemplateCRD = generateCRD(InfrastructureGroupVersion.WithKind(GenericInfrastructureMachinePoolTemplateKind))
+
 	// GenericInfrastructureClusterKind is the kind for the GenericInfrastructureCluster type.
 	GenericInfrastructureClusterKind = "GenericInfrastructureCluster"
 	// GenericInfrastructureClusterCRD is a generic infrastructure machine CRD.
@@ -49,7 +49,9 @@ import (
 	kcpv1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	addonv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1beta1"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
 	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/cluster-api/internal/webhooks"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -75,6 +77,7 @@ func init() {
 	utilruntime.Must(expv1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(addonv1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(kcpv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(runtimev1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(admissionv1.AddToScheme(scheme.Scheme))
 }
 
@@ -167,6 +170,8 @@ func new(uncachedObjs ...client.Object) *Environment {
 			*builder.GenericControlPlaneTemplateCRD.DeepCopy(),
 			*builder.GenericInfrastructureMachineCRD.DeepCopy(),
 			*builder.GenericInfrastructureMachineTemplateCRD.DeepCopy(),
+			*builder.GenericInfrastructureMachinePoolCRD.DeepCopy(),
+			*builder.GenericInfrastructureMachinePoolTemplateCRD.DeepCopy(),
 			*builder.GenericInfrastructureClusterCRD.DeepCopy(),
 			*builder.GenericInfrastructureClusterTemplateCRD.DeepCopy(),
 			*builder.GenericRemediationCRD.DeepCopy(),
@@ -215,6 +220,12 @@ func new(uncachedObjs ...client.Object) *Environment {
 	// Set minNodeStartupTimeout for Test, so it does not need to be at least 30s
 	clusterv1.SetMinNodeStartupTimeout(metav1.Duration{Duration: 1 * time.Millisecond})
 
+	if err := (&webhooks.Cluster{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Fatalf("unable to create webhook: %+v", err)
+	}
+	if err := (&webhooks.ClusterClass{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Fatalf("unable to create webhook: %+v", err)
+	}
 	if err := (&clusterv1.Cluster{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create webhook: %+v", err)
 	}
@@ -227,6 +238,9 @@ func new(uncachedObjs ...client.Object) *Environment {
 	if err := (&clusterv1.MachineHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create webhook: %+v", err)
 	}
+	if err := (&runtimev1.ExtensionConfig{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Fatalf("unable to create webhook: %+v", err)
+	}
 	if err := (&clusterv1.Machine{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create webhook: %+v", err)
 	}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package check implements checks for managed topology.
+package check
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReferencedObjectsAreStrictlyCompatible checks if two referenced objects are strictly compatible, meaning that
+// they are compatible and the name of the objects do not change.
+func ReferencedObjectsAreStrictlyCompatible(current, desired client.Object) error {
+	if current.GetName() != desired.GetName() {
+		return errors.Errorf("invalid operation: it is not possible to change the name of %s/%s from %s to %s",
+			current.GetObjectKind().GroupVersionKind(), current.GetName(), current.GetName(), desired.GetName())
+	}
+	return ReferencedObjectsAreCompatible(current, desired)
+}
+
+// ReferencedObjectsAreCompatible checks if two referenced objects are compatible, meaning that
+// they are of the same GroupKind and in the same namespace.
+func ReferencedObjectsAreCompatible(current, desired client.Object) error {
+	currentGK := current.GetObjectKind().GroupVersionKind().GroupKind()
+	desiredGK := desired.GetObjectKind().GroupVersionKind().GroupKind()
+
+	if currentGK.String() != desiredGK.String() {
+		return errors.Errorf("invalid operation: it is not possible to change the GroupKind of %s/%s from %s to %s",
+			current.GetObjectKind().GroupVersionKind(), current.GetName(), currentGK, desiredGK)
+	}
+	return ObjectsAreInTheSameNamespace(current, desired)
+}
+
+// ObjectsAreInTheSameNamespace checks if two referenced objects are in the same namespace.
+func ObjectsAreInTheSameNamespace(current, desired client.Object) error {
+	// NOTE: this should never happen (webhooks prevent it), but checking for extra safety.
+	if current.GetNamespace() != desired.GetNamespace() {
+		return errors.Errorf("invalid operation: it is not possible to change the namespace of %s/%s from %s to %s",
+			current.GetObjectKind().GroupVersionKind(), current.GetName(), current.GetNamespace(), desired.GetNamespace())
+	}
+	return nil
+}
+
+// ClusterClassesAreCompatible checks if a Cluster using the current ClusterClass can be rebased to the desired one,
+// meaning that the infrastructure cluster, control plane and control plane machine infrastructure templates
+// are of the same GroupKind, as well as the infrastructure templates of the worker classes defined in both
+// ClusterClasses.
+// NOTE: Worker classes removed in the desired ClusterClass are not considered here; use
+// WorkerTopologiesAreDefinedInClusterClass to check they are not used by the Cluster.
+func ClusterClassesAreCompatible(current, desired *clusterv1.ClusterClass) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, LocalObjectTemplatesAreCompatible(current.Spec.Infrastructure, desired.Spec.Infrastructure,
+		field.NewPath("spec", "infrastructure"))...)
+	allErrs = append(allErrs, LocalObjectTemplatesAreCompatible(current.Spec.ControlPlane.LocalObjectTemplate, desired.Spec.ControlPlane.LocalObjectTemplate,
+		field.NewPath("spec", "controlPlane"))...)
+
+	// The control plane must either be Machine based in both ClusterClasses or in none of them.
+	machineInfrastructurePath := field.NewPath("spec", "controlPlane", "machineInfrastructure")
+	switch {
+	case current.Spec.ControlPlane.MachineInfrastructure == nil && desired.Spec.ControlPlane.MachineInfrastructure != nil:
+		allErrs = append(allErrs, field.Forbidden(machineInfrastructurePath, "cannot be added to a control plane which is not Machine based"))
+	case current.Spec.ControlPlane.MachineInfrastructure != nil && desired.Spec.ControlPlane.MachineInfrastructure == nil:
+		allErrs = append(allErrs, field.Forbidden(machineInfrastructurePath, "cannot be removed from a control plane which is Machine based"))
+	case current.Spec.ControlPlane.MachineInfrastructure != nil && desired.Spec.ControlPlane.MachineInfrastructure != nil:
+		allErrs = append(allErrs, LocalObjectTemplatesAreCompatible(*current.Spec.ControlPlane.MachineInfrastructure, *desired.Spec.ControlPlane.MachineInfrastructure,
+			machineInfrastructurePath)...)
+	}
+
+	for i, desiredClass := range desired.Spec.Workers.MachineDeployments {
+		for _, currentClass := range current.Spec.Workers.MachineDeployments {
+			if desiredClass.Class != currentClass.Class {
+				continue
+			}
+			allErrs = append(allErrs, LocalObjectTemplatesAreCompatible(currentClass.Template.Infrastructure, desiredClass.Template.Infrastructure,
+				field.NewPath("spec", "workers", "machineDeployments").Index(i).Child("template", "infrastructure"))...)
+		}
+	}
+
+	for i, desiredClass := range desired.Spec.Workers.MachinePools {
+		for _, currentClass := range current.Spec.Workers.MachinePools {
+			if desiredClass.Class != currentClass.Class {
+				continue
+			}
+			allErrs = append(allErrs, LocalObjectTemplatesAreCompatible(currentClass.Template.Infrastructure, desiredClass.Template.Infrastructure,
+				field.NewPath("spec", "workers", "machinePools").Index(i).Child("template", "infrastructure"))...)
+		}
+	}
+
+	return allErrs
+}
+
+// LocalObjectTemplatesAreCompatible checks if two references to templates are compatible, meaning that
+// the referenced templates are of the same GroupKind; the version and the name are instead allowed to change.
+func LocalObjectTemplatesAreCompatible(current, desired clusterv1.LocalObjectTemplate, pathPrefix *field.Path) field.ErrorList {
+	// NOTE: The ClusterClass webhook ensures references are always set; checking for extra safety.
+	if current.Ref == nil || desired.Ref == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	currentGV, err := schema.ParseGroupVersion(current.Ref.APIVersion)
+	if err != nil {
+		return field.ErrorList{field.Invalid(pathPrefix.Child("ref", "apiVersion"), current.Ref.APIVersion,
+			fmt.Sprintf("must be a valid apiVersion: %v", err))}
+	}
+	desiredGV, err := schema.ParseGroupVersion(desired.Ref.APIVersion)
+	if err != nil {
+		return field.ErrorList{field.Invalid(pathPrefix.Child("ref", "apiVersion"), desired.Ref.APIVersion,
+			fmt.Sprintf("must be a valid apiVersion: %v", err))}
+	}
+
+	if currentGV.Group != desiredGV.Group {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("ref", "apiVersion"),
+			fmt.Sprintf("apiGroup cannot be changed from %q to %q", currentGV.Group, desiredGV.Group)))
+	}
+	if current.Ref.Kind != desired.Ref.Kind {
+		allErrs = append(allErrs, field.Forbidden(pathPrefix.Child("ref", "kind"),
+			fmt.Sprintf("kind cannot be changed from %q to %q", current.Ref.Kind, desired.Ref.Kind)))
+	}
+
+	return allErrs
+}
+
+// WorkerTopologiesAreDefinedInClusterClass checks if all the MachineDeployment and MachinePool topologies
+// of a Cluster refer to a worker class defined in the given ClusterClass.
+func WorkerTopologiesAreDefinedInClusterClass(cluster *clusterv1.Cluster, clusterClass *clusterv1.ClusterClass) field.ErrorList {
+	if cluster.Spec.Topology == nil || cluster.Spec.Topology.Workers == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	machineDeploymentClasses := map[string]bool{}
+	for _, class := range clusterClass.Spec.Workers.MachineDeployments {
+		machineDeploymentClasses[class.Class] = true
+	}
+	for i, md := range cluster.Spec.Topology.Workers.MachineDeployments {
+		if !machineDeploymentClasses[md.Class] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "topology", "workers", "machineDeployments").Index(i).Child("class"),
+				md.Class, fmt.Sprintf("MachineDeployment class must be defined in ClusterClass %s", clusterClass.Name)))
+		}
+	}
+
+	machinePoolClasses := map[string]bool{}
+	for _, class := range clusterClass.Spec.Workers.MachinePools {
+		machinePoolClasses[class.Class] = true
+	}
+	for i, mp := range cluster.Spec.Topology.Workers.MachinePools {
+		if !machinePoolClasses[mp.Class] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "topology", "workers", "machinePools").Index(i).Child("class"),
+				mp.Class, fmt.Sprintf("MachinePool class must be defined in ClusterClass %s", clusterClass.Name)))
+		}
+	}
+
+	return allErrs
+}
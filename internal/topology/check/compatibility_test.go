@@ -0,0 +1,391 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+type referencedObjectsCompatibilityTestCase struct {
+	name    string
+	current *unstructured.Unstructured
+	desired *unstructured.Unstructured
+	wantErr bool
+}
+
+var referencedObjectsCompatibilityTestCases = []referencedObjectsCompatibilityTestCase{
+	{
+		name: "Fails if group changes",
+		current: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo/v1beta1",
+			},
+		},
+		desired: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar/v1beta1",
+			},
+		},
+		wantErr: true,
+	},
+	{
+		name: "Fails if kind changes",
+		current: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind": "foo",
+			},
+		},
+		desired: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind": "bar",
+			},
+		},
+		wantErr: true,
+	},
+	{
+		name: "Pass if gvk remains the same",
+		current: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/foo",
+				"kind":       "foo",
+			},
+		},
+		desired: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/foo",
+				"kind":       "foo",
+			},
+		},
+		wantErr: false,
+	},
+	{
+		name: "Pass if version changes but group and kind remains the same",
+		current: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/foo",
+				"kind":       "foo",
+			},
+		},
+		desired: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/bar",
+				"kind":       "foo",
+			},
+		},
+		wantErr: false,
+	},
+	{
+		name: "Fails if namespace changes",
+		current: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"namespace": "foo",
+				},
+			},
+		},
+		desired: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"namespace": "bar",
+				},
+			},
+		},
+		wantErr: true,
+	},
+}
+
+func TestCheckReferencedObjectsAreCompatible(t *testing.T) {
+	for _, tt := range referencedObjectsCompatibilityTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := ReferencedObjectsAreCompatible(tt.current, tt.desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestCheckReferencedObjectsAreStrictlyCompatible(t *testing.T) {
+	referencedObjectsStrictCompatibilityTestCases := append(referencedObjectsCompatibilityTestCases, []referencedObjectsCompatibilityTestCase{
+		{
+			name: "Fails if name changes",
+			current: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "foo",
+					},
+				},
+			},
+			desired: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "bar",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Pass if name remains the same",
+			current: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "foo",
+					},
+				},
+			},
+			desired: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "foo",
+					},
+				},
+			},
+			wantErr: false,
+		},
+	}...)
+
+	for _, tt := range referencedObjectsStrictCompatibilityTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := ReferencedObjectsAreStrictlyCompatible(tt.current, tt.desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestClusterClassesAreCompatible(t *testing.T) {
+	tests := []struct {
+		name    string
+		current *clusterv1.ClusterClass
+		desired *clusterv1.ClusterClass
+		wantErr bool
+	}{
+		{
+			name:    "Pass if templates are of the same kinds",
+			current: newTestClusterClass("class1"),
+			desired: newTestClusterClass("class2"),
+			wantErr: false,
+		},
+		{
+			name:    "Pass if template versions change",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.Infrastructure.Ref.APIVersion = "infrastructure.cluster.x-k8s.io/v1beta2"
+				return class
+			}(),
+			wantErr: false,
+		},
+		{
+			name:    "Pass if a MachineDeployment class is removed or added",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.Workers.MachineDeployments[0].Class = "other-worker"
+				class.Spec.Workers.MachineDeployments[0].Template.Infrastructure.Ref.Kind = "OtherMachineTemplate"
+				return class
+			}(),
+			wantErr: false,
+		},
+		{
+			name:    "Fails if the infrastructure cluster template kind changes",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.Infrastructure.Ref.Kind = "OtherClusterTemplate"
+				return class
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "Fails if the control plane template group changes",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.ControlPlane.Ref.APIVersion = "other.controlplane.cluster.x-k8s.io/v1beta1"
+				return class
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "Fails if the control plane machine infrastructure is removed",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.ControlPlane.MachineInfrastructure = nil
+				return class
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Fails if the control plane machine infrastructure is added",
+			current: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class1")
+				class.Spec.ControlPlane.MachineInfrastructure = nil
+				return class
+			}(),
+			desired: newTestClusterClass("class2"),
+			wantErr: true,
+		},
+		{
+			name:    "Fails if the infrastructure machine template kind of a MachineDeployment class changes",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.Workers.MachineDeployments[0].Template.Infrastructure.Ref.Kind = "OtherMachineTemplate"
+				return class
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "Fails if the infrastructure machine pool template kind of a MachinePool class changes",
+			current: newTestClusterClass("class1"),
+			desired: func() *clusterv1.ClusterClass {
+				class := newTestClusterClass("class2")
+				class.Spec.Workers.MachinePools[0].Template.Infrastructure.Ref.Kind = "OtherMachinePoolTemplate"
+				return class
+			}(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errs := ClusterClassesAreCompatible(tt.current, tt.desired)
+			if tt.wantErr {
+				g.Expect(errs).ToNot(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(BeEmpty())
+		})
+	}
+}
+
+func TestWorkerTopologiesAreDefinedInClusterClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers *clusterv1.WorkersTopology
+		wantErr bool
+	}{
+		{
+			name:    "Pass if there are no workers",
+			workers: nil,
+			wantErr: false,
+		},
+		{
+			name: "Pass if all worker classes are defined",
+			workers: &clusterv1.WorkersTopology{
+				MachineDeployments: []clusterv1.MachineDeploymentTopology{{Class: "linux-worker", Name: "md1"}},
+				MachinePools:       []clusterv1.MachinePoolTopology{{Class: "linux-pool", Name: "mp1"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails if a MachineDeployment class is not defined",
+			workers: &clusterv1.WorkersTopology{
+				MachineDeployments: []clusterv1.MachineDeploymentTopology{{Class: "windows-worker", Name: "md1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails if a MachinePool class is not defined",
+			workers: &clusterv1.WorkersTopology{
+				MachinePools: []clusterv1.MachinePoolTopology{{Class: "windows-pool", Name: "mp1"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: metav1.NamespaceDefault},
+				Spec: clusterv1.ClusterSpec{
+					Topology: &clusterv1.Topology{
+						Class:   "class1",
+						Version: "v1.22.2",
+						Workers: tt.workers,
+					},
+				},
+			}
+
+			errs := WorkerTopologiesAreDefinedInClusterClass(cluster, newTestClusterClass("class1"))
+			if tt.wantErr {
+				g.Expect(errs).ToNot(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(BeEmpty())
+		})
+	}
+}
+
+func newTestClusterClass(name string) *clusterv1.ClusterClass {
+	ref := func(apiVersion, kind string) clusterv1.LocalObjectTemplate {
+		return clusterv1.LocalObjectTemplate{
+			Ref: &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Namespace: metav1.NamespaceDefault, Name: name},
+		}
+	}
+	machineInfrastructure := ref("infrastructure.cluster.x-k8s.io/v1beta1", "GenericInfrastructureMachineTemplate")
+	return &clusterv1.ClusterClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+		Spec: clusterv1.ClusterClassSpec{
+			Infrastructure: ref("infrastructure.cluster.x-k8s.io/v1beta1", "GenericInfrastructureClusterTemplate"),
+			ControlPlane: clusterv1.ControlPlaneClass{
+				LocalObjectTemplate:   ref("controlplane.cluster.x-k8s.io/v1beta1", "GenericControlPlaneTemplate"),
+				MachineInfrastructure: &machineInfrastructure,
+			},
+			Workers: clusterv1.WorkersClass{
+				MachineDeployments: []clusterv1.MachineDeploymentClass{
+					{
+						Class: "linux-worker",
+						Template: clusterv1.MachineDeploymentClassTemplate{
+							Bootstrap:      ref("bootstrap.cluster.x-k8s.io/v1beta1", "GenericBootstrapConfigTemplate"),
+							Infrastructure: ref("infrastructure.cluster.x-k8s.io/v1beta1", "GenericInfrastructureMachineTemplate"),
+						},
+					},
+				},
+				MachinePools: []clusterv1.MachinePoolClass{
+					{
+						Class: "linux-pool",
+						Template: clusterv1.MachinePoolClassTemplate{
+							Bootstrap:      ref("bootstrap.cluster.x-k8s.io/v1beta1", "GenericBootstrapConfigTemplate"),
+							Infrastructure: ref("infrastructure.cluster.x-k8s.io/v1beta1", "GenericInfrastructureMachinePoolTemplate"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
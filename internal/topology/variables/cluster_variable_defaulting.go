@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// DefaultClusterVariables returns a copy of clusterVariables where:
+// * variables which are not set in the Cluster, but have a default value in the corresponding
+//   ClusterClassVariable, are added with the default value;
+// * nested fields of variables are defaulted according to the schema of the corresponding ClusterClassVariable.
+// NOTE: Variables are returned in the same order they are defined in the Cluster; defaulted
+// variables are appended in the same order they are defined in the ClusterClass.
+func DefaultClusterVariables(clusterVariables []clusterv1.ClusterVariable, clusterClassVariables []clusterv1.ClusterClassVariable, fldPath *field.Path) ([]clusterv1.ClusterVariable, field.ErrorList) {
+	var allErrs field.ErrorList
+
+	// Get a map of ClusterVariable values and ensure that variables are not defined more than once in Cluster spec.
+	clusterVariablesMap, errs := getClusterVariablesMap(clusterVariables, fldPath)
+	if len(errs) > 0 {
+		return nil, append(allErrs, errs...)
+	}
+
+	// Get a map of ClusterClassVariable definitions for easier lookups.
+	clusterClassVariablesMap := getClusterClassVariablesMap(clusterClassVariables)
+
+	defaultedVariables := []clusterv1.ClusterVariable{}
+	for i, clusterVariable := range clusterVariables {
+		clusterClassVariable, ok := clusterClassVariablesMap[clusterVariable.Name]
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), clusterVariable.Name,
+				fmt.Sprintf("variable %q is not defined in the ClusterClass", clusterVariable.Name)))
+			continue
+		}
+
+		defaultedVariable, errs := defaultClusterVariable(&clusterVariables[i], clusterClassVariable, fldPath.Index(i))
+		if len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+			continue
+		}
+		defaultedVariables = append(defaultedVariables, *defaultedVariable)
+	}
+
+	for i := range clusterClassVariables {
+		clusterClassVariable := &clusterClassVariables[i]
+		if _, ok := clusterVariablesMap[clusterClassVariable.Name]; ok {
+			continue
+		}
+
+		// Skip variables which are not set and don't have a default value.
+		if clusterClassVariable.Schema.OpenAPIV3Schema.Default == nil {
+			continue
+		}
+
+		defaultedVariable, errs := defaultClusterVariable(&clusterv1.ClusterVariable{Name: clusterClassVariable.Name}, clusterClassVariable, fldPath)
+		if len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+			continue
+		}
+		defaultedVariables = append(defaultedVariables, *defaultedVariable)
+	}
+
+	if len(allErrs) > 0 {
+		return nil, allErrs
+	}
+	return defaultedVariables, nil
+}
+
+// defaultClusterVariable defaults a clusterVariable based on the default value in the clusterClassVariable.
+func defaultClusterVariable(clusterVariable *clusterv1.ClusterVariable, clusterClassVariable *clusterv1.ClusterClassVariable, fldPath *field.Path) (*clusterv1.ClusterVariable, field.ErrorList) {
+	// Convert schema to Kubernetes APIExtensions schema.
+	apiExtensionsSchema, errs := convertToAPIExtensionsJSONSchemaProps(&clusterClassVariable.Schema.OpenAPIV3Schema, field.NewPath("schema"))
+	if len(errs) > 0 {
+		return nil, field.ErrorList{field.InternalError(fldPath,
+			fmt.Errorf("failed to convert schema definition for variable %q; ClusterClass should be checked: %v", clusterClassVariable.Name, errs))}
+	}
+
+	// Convert to structural schema.
+	// Note: The schema is wrapped into an object, because the defaulting algorithm only sets
+	// defaults of fields in an object, while variables can have any type.
+	structuralSchema, err := structuralschema.NewStructural(wrapSchema(apiExtensionsSchema))
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(fldPath,
+			fmt.Errorf("failed to create structural schema for variable %q; ClusterClass should be checked: %v", clusterClassVariable.Name, err))}
+	}
+
+	// Parse the value, if any.
+	var value interface{}
+	if clusterVariable.Value.Raw != nil {
+		if value, err = unmarshalValue(clusterVariable.Value.Raw); err != nil {
+			return nil, field.ErrorList{field.Invalid(fldPath.Child("value"), string(clusterVariable.Value.Raw),
+				fmt.Sprintf("variable %q could not be parsed: %v", clusterVariable.Name, err))}
+		}
+	}
+
+	// Wrap the value in a parent object, matching the wrapped schema.
+	wrappedValue := map[string]interface{}{}
+	if value != nil {
+		wrappedValue[wrappedSchemaProperty] = value
+	}
+	structuraldefaulting.Default(wrappedValue, structuralSchema)
+
+	defaultedVariable := &clusterv1.ClusterVariable{Name: clusterVariable.Name}
+	if defaultedValue, ok := wrappedValue[wrappedSchemaProperty]; ok {
+		raw, err := json.Marshal(defaultedValue)
+		if err != nil {
+			return nil, field.ErrorList{field.Invalid(fldPath.Child("value"), defaultedValue,
+				fmt.Sprintf("failed to marshal defaulted value of variable %q: %v", clusterVariable.Name, err))}
+		}
+		defaultedVariable.Value = apiextensionsv1.JSON{Raw: raw}
+	}
+	return defaultedVariable, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func Test_DefaultClusterVariables(t *testing.T) {
+	tests := []struct {
+		name                  string
+		clusterClassVariables []clusterv1.ClusterClassVariable
+		clusterVariables      []clusterv1.ClusterVariable
+		want                  []clusterv1.ClusterVariable
+		wantErr               bool
+	}{
+		{
+			name: "Return variables as is if there is nothing to default",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "cpu",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "integer",
+						},
+					},
+				},
+			},
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("1")},
+				},
+			},
+			want: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("1")},
+				},
+			},
+		},
+		{
+			name: "Add variables which are not set but have a default",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "cpu",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type:    "integer",
+							Default: &apiextensionsv1.JSON{Raw: []byte("2")},
+						},
+					},
+				},
+				{
+					Name: "location",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type:    "string",
+							Default: &apiextensionsv1.JSON{Raw: []byte("\"us-east\"")},
+						},
+					},
+				},
+				{
+					Name: "zone",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+			},
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "location",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"us-west\"")},
+				},
+			},
+			want: []clusterv1.ClusterVariable{
+				{
+					Name:  "location",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"us-west\"")},
+				},
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("2")},
+				},
+			},
+		},
+		{
+			name: "Default nested fields of object variables",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "httpProxy",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]clusterv1.JSONSchemaProps{
+								"enabled": {
+									Type:    "boolean",
+									Default: &apiextensionsv1.JSON{Raw: []byte("true")},
+								},
+								"url": {
+									Type: "string",
+								},
+							},
+						},
+					},
+				},
+			},
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "httpProxy",
+					Value: apiextensionsv1.JSON{Raw: []byte("{\"url\":\"http://proxy:3128\"}")},
+				},
+			},
+			want: []clusterv1.ClusterVariable{
+				{
+					Name:  "httpProxy",
+					Value: apiextensionsv1.JSON{Raw: []byte("{\"enabled\":true,\"url\":\"http://proxy:3128\"}")},
+				},
+			},
+		},
+		{
+			name:                  "Error if a variable is not defined in the ClusterClass",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{},
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("1")},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, errList := DefaultClusterVariables(tt.clusterVariables, tt.clusterClassVariables, field.NewPath("spec", "topology", "variables"))
+
+			if tt.wantErr {
+				g.Expect(errList).NotTo(BeEmpty())
+				return
+			}
+			g.Expect(errList).To(BeEmpty())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ValidateClusterVariables validates clusterVariables against the corresponding clusterClassVariables.
+// NOTE: Required variables which are not set in the Cluster are accepted if the corresponding
+// ClusterClass variable defines a default value, because they are going to be defaulted.
+func ValidateClusterVariables(clusterVariables []clusterv1.ClusterVariable, clusterClassVariables []clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Get a map of ClusterVariable values and ensure that variables are not defined more than once in Cluster spec.
+	clusterVariablesMap, errs := getClusterVariablesMap(clusterVariables, fldPath)
+	if len(errs) > 0 {
+		return append(allErrs, errs...)
+	}
+
+	// Get a map of ClusterClassVariable definitions for easier lookups.
+	clusterClassVariablesMap := getClusterClassVariablesMap(clusterClassVariables)
+
+	// Required variables must be set in the Cluster, unless they have a default value.
+	for _, clusterClassVariable := range clusterClassVariables {
+		if !clusterClassVariable.Required {
+			continue
+		}
+		if _, ok := clusterVariablesMap[clusterClassVariable.Name]; ok {
+			continue
+		}
+		if clusterClassVariable.Schema.OpenAPIV3Schema.Default != nil {
+			continue
+		}
+		allErrs = append(allErrs, field.Required(fldPath,
+			fmt.Sprintf("required variable %q must be set", clusterClassVariable.Name)))
+	}
+
+	for i, clusterVariable := range clusterVariables {
+		// Ensure a definition is found for every variable found in the Cluster.
+		clusterClassVariable, ok := clusterClassVariablesMap[clusterVariable.Name]
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), clusterVariable.Name,
+				fmt.Sprintf("variable %q is not defined in the ClusterClass", clusterVariable.Name)))
+			continue
+		}
+
+		allErrs = append(allErrs, ValidateClusterVariable(&clusterVariables[i], clusterClassVariable, fldPath.Index(i))...)
+	}
+
+	return allErrs
+}
+
+// ValidateClusterVariable validates a clusterVariable.
+func ValidateClusterVariable(clusterVariable *clusterv1.ClusterVariable, clusterClassVariable *clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	// Parse JSON value.
+	var variableValue interface{}
+	// Only try to unmarshal the clusterVariable if it is not nil, otherwise the variableValue is nil.
+	// Note: A clusterVariable with a nil value is the result of setting the variable value to "null" via YAML.
+	if clusterVariable.Value.Raw != nil {
+		var err error
+		if variableValue, err = unmarshalValue(clusterVariable.Value.Raw); err != nil {
+			return field.ErrorList{field.Invalid(fldPath.Child("value"), string(clusterVariable.Value.Raw),
+				fmt.Sprintf("variable %q could not be parsed: %v", clusterVariable.Name, err))}
+		}
+	}
+
+	// Convert schema to Kubernetes APIExtensions Schema.
+	apiExtensionsSchema, allErrs := convertToAPIExtensionsJSONSchemaProps(&clusterClassVariable.Schema.OpenAPIV3Schema, field.NewPath("schema"))
+	if len(allErrs) > 0 {
+		return field.ErrorList{field.InternalError(fldPath,
+			fmt.Errorf("failed to convert schema definition for variable %q; ClusterClass should be checked: %v", clusterClassVariable.Name, allErrs))}
+	}
+
+	// Create validator for schema.
+	validator, _, err := apiextensionsvalidation.NewSchemaValidator(&apiextensions.CustomResourceValidation{
+		OpenAPIV3Schema: apiExtensionsSchema,
+	})
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath,
+			fmt.Errorf("failed to create schema validator for variable %q; ClusterClass should be checked: %v", clusterVariable.Name, err))}
+	}
+
+	// Validate variable against the schema.
+	// NOTE: We're reusing a library func used in CRD validation.
+	return apiextensionsvalidation.ValidateCustomResource(fldPath.Child("value"), variableValue, validator)
+}
+
+// getClusterVariablesMap returns a map of ClusterVariables by name; it also returns an error
+// for every variable defined more than once.
+func getClusterVariablesMap(clusterVariables []clusterv1.ClusterVariable, fldPath *field.Path) (map[string]*clusterv1.ClusterVariable, field.ErrorList) {
+	var allErrs field.ErrorList
+
+	variablesMap := map[string]*clusterv1.ClusterVariable{}
+	for i := range clusterVariables {
+		if _, ok := variablesMap[clusterVariables[i].Name]; ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), clusterVariables[i].Name,
+				fmt.Sprintf("variable names must be unique. Variable %q is set more than once", clusterVariables[i].Name)))
+			continue
+		}
+		variablesMap[clusterVariables[i].Name] = &clusterVariables[i]
+	}
+	return variablesMap, allErrs
+}
+
+// getClusterClassVariablesMap returns a map of ClusterClassVariables by name.
+func getClusterClassVariablesMap(clusterClassVariables []clusterv1.ClusterClassVariable) map[string]*clusterv1.ClusterClassVariable {
+	variablesMap := map[string]*clusterv1.ClusterClassVariable{}
+	for i := range clusterClassVariables {
+		variablesMap[clusterClassVariables[i].Name] = &clusterClassVariables[i]
+	}
+	return variablesMap
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func Test_ValidateClusterVariables(t *testing.T) {
+	clusterClassVariables := []clusterv1.ClusterClassVariable{
+		{
+			Name:     "cpu",
+			Required: true,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:    "integer",
+					Minimum: pointer.Int64(1),
+				},
+			},
+		},
+		{
+			Name:     "zone",
+			Required: true,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:    "string",
+					Default: &apiextensionsv1.JSON{Raw: []byte("\"us-east-1a\"")},
+				},
+			},
+		},
+		{
+			Name: "location",
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type: "string",
+					Enum: []apiextensionsv1.JSON{
+						{Raw: []byte("\"us-east\"")},
+						{Raw: []byte("\"us-west\"")},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		clusterVariables []clusterv1.ClusterVariable
+		wantErr          bool
+	}{
+		{
+			name: "Valid variables",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("2")},
+				},
+				{
+					Name:  "location",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"us-east\"")},
+				},
+			},
+		},
+		{
+			name: "Error if a required variable without default is missing",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "location",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"us-east\"")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if a variable is not defined in the ClusterClass",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("2")},
+				},
+				{
+					Name:  "memory",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"8Gi\"")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if a variable is set more than once",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("2")},
+				},
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("3")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if a value does not match the schema",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("0")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if a value is not in the enum",
+			clusterVariables: []clusterv1.ClusterVariable{
+				{
+					Name:  "cpu",
+					Value: apiextensionsv1.JSON{Raw: []byte("1")},
+				},
+				{
+					Name:  "location",
+					Value: apiextensionsv1.JSON{Raw: []byte("\"eu-central\"")},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errList := ValidateClusterVariables(tt.clusterVariables, clusterClassVariables, field.NewPath("spec", "topology", "variables"))
+
+			if tt.wantErr {
+				g.Expect(errList).NotTo(BeEmpty())
+				return
+			}
+			g.Expect(errList).To(BeEmpty())
+		})
+	}
+}
+
+func Test_ValidateClusterVariable(t *testing.T) {
+	tests := []struct {
+		name                 string
+		clusterClassVariable *clusterv1.ClusterClassVariable
+		clusterVariable      *clusterv1.ClusterVariable
+		wantErr              bool
+	}{
+		{
+			name: "Valid object",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "httpProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]clusterv1.JSONSchemaProps{
+							"enabled": {
+								Type: "boolean",
+							},
+							"url": {
+								Type: "string",
+							},
+						},
+						Required: []string{"enabled"},
+					},
+				},
+			},
+			clusterVariable: &clusterv1.ClusterVariable{
+				Name:  "httpProxy",
+				Value: apiextensionsv1.JSON{Raw: []byte("{\"enabled\":true,\"url\":\"http://proxy:3128\"}")},
+			},
+		},
+		{
+			name: "Error if a required nested field is missing",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "httpProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]clusterv1.JSONSchemaProps{
+							"enabled": {
+								Type: "boolean",
+							},
+						},
+						Required: []string{"enabled"},
+					},
+				},
+			},
+			clusterVariable: &clusterv1.ClusterVariable{
+				Name:  "httpProxy",
+				Value: apiextensionsv1.JSON{Raw: []byte("{}")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if array is too long",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "noProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:     "array",
+						MaxItems: pointer.Int64(1),
+						Items: &clusterv1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+			},
+			clusterVariable: &clusterv1.ClusterVariable{
+				Name:  "noProxy",
+				Value: apiextensionsv1.JSON{Raw: []byte("[\"a\",\"b\"]")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if value has the wrong type",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "location",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			clusterVariable: &clusterv1.ClusterVariable{
+				Name:  "location",
+				Value: apiextensionsv1.JSON{Raw: []byte("1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if value is not valid JSON",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "location",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			clusterVariable: &clusterv1.ClusterVariable{
+				Name:  "location",
+				Value: apiextensionsv1.JSON{Raw: []byte("us-east")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errList := ValidateClusterVariable(tt.clusterVariable, tt.clusterClassVariable, field.NewPath("spec", "topology", "variables").Index(0))
+
+			if tt.wantErr {
+				g.Expect(errList).NotTo(BeEmpty())
+				return
+			}
+			g.Expect(errList).To(BeEmpty())
+		})
+	}
+}
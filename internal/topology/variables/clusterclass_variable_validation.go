@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// BuiltinsName is the name of the builtin variable.
+	BuiltinsName = "builtin"
+)
+
+// validVariableTypes is a set of valid variable types.
+var validVariableTypes = sets.NewString("object", "array", "string", "number", "integer", "boolean")
+
+// ValidateClusterClassVariables validates clusterClassVariable.
+func ValidateClusterClassVariables(clusterClassVariables []clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validateClusterClassVariableNamesUnique(clusterClassVariables, fldPath)...)
+
+	for i := range clusterClassVariables {
+		allErrs = append(allErrs, ValidateClusterClassVariable(&clusterClassVariables[i], fldPath.Index(i))...)
+	}
+
+	return allErrs
+}
+
+// validateClusterClassVariableNamesUnique validates that ClusterClass variable names are unique.
+func validateClusterClassVariableNamesUnique(clusterClassVariables []clusterv1.ClusterClassVariable, pathPrefix *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	variableNames := sets.NewString()
+	for i, clusterClassVariable := range clusterClassVariables {
+		if variableNames.Has(clusterClassVariable.Name) {
+			allErrs = append(allErrs,
+				field.Invalid(
+					pathPrefix.Index(i).Child("name"),
+					clusterClassVariable.Name,
+					fmt.Sprintf("variable name must be unique. Variable with name %q is defined more than once", clusterClassVariable.Name),
+				),
+			)
+		}
+		variableNames.Insert(clusterClassVariable.Name)
+	}
+
+	return allErrs
+}
+
+// ValidateClusterClassVariable validates a ClusterClassVariable.
+func ValidateClusterClassVariable(variable *clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validateClusterClassVariableName(variable.Name, fldPath.Child("name"))...)
+	allErrs = append(allErrs, validateRootSchema(&variable.Schema.OpenAPIV3Schema, fldPath.Child("schema", "openAPIV3Schema"))...)
+
+	return allErrs
+}
+
+// validateClusterClassVariableName validates a variable name.
+func validateClusterClassVariableName(variableName string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if variableName == "" {
+		allErrs = append(allErrs, field.Required(fldPath, "variable name must be defined"))
+	}
+
+	if variableName == BuiltinsName {
+		allErrs = append(allErrs, field.Invalid(fldPath, variableName, fmt.Sprintf("%q is a reserved variable name", BuiltinsName)))
+	}
+
+	// Dots are used to reference nested fields of a variable in patches, thus they can't be used in variable names.
+	if strings.Contains(variableName, ".") {
+		allErrs = append(allErrs, field.Invalid(fldPath, variableName, "variable name cannot contain \".\""))
+	}
+
+	return allErrs
+}
+
+// validateRootSchema validates the schema.
+func validateRootSchema(schema *clusterv1.JSONSchemaProps, fldPath *field.Path) field.ErrorList {
+	apiExtensionsSchema, allErrs := convertToAPIExtensionsJSONSchemaProps(schema, fldPath)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	// Validate the types used in the schema.
+	allErrs = append(allErrs, validateSchemaTypes(apiExtensionsSchema, fldPath)...)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	// Validate structural schema.
+	// Note: structural schema only allows configured fields
+	// and ensures that all fields have a type.
+	// Note: The schema is wrapped into an object, because the structural schema validation
+	// requires the root of the schema to be of type object, while variables can have any type.
+	ss, err := structuralschema.NewStructural(wrapSchema(apiExtensionsSchema))
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "", err.Error()))
+	}
+	if validationErrors := structuralschema.ValidateStructural(wrappedSchemaPath, ss); len(validationErrors) > 0 {
+		return append(allErrs, unwrapErrors(validationErrors, fldPath)...)
+	}
+
+	// Validate defaults in the structural schema.
+	validationErrors, err := structuraldefaulting.ValidateDefaults(wrappedSchemaPath, ss, false, true)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "", err.Error()))
+	}
+	if len(validationErrors) > 0 {
+		return append(allErrs, unwrapErrors(validationErrors, fldPath)...)
+	}
+
+	return allErrs
+}
+
+// validateSchemaTypes validates that the types used in the schema are valid and that
+// type specific fields are only used with the corresponding types.
+func validateSchemaTypes(schema *apiextensions.JSONSchemaProps, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !validVariableTypes.Has(schema.Type) {
+		return append(allErrs, field.NotSupported(fldPath.Child("type"), schema.Type, validVariableTypes.List()))
+	}
+
+	if schema.Type != "object" && (len(schema.Properties) > 0 || len(schema.Required) > 0) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("properties"), "properties and required can only be set if type is object"))
+	}
+
+	if schema.Type != "array" && schema.Items != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("items"), "items can only be set if type is array"))
+	}
+	if schema.Type == "array" && (schema.Items == nil || schema.Items.Schema == nil) {
+		allErrs = append(allErrs, field.Required(fldPath.Child("items"), "items must be set if type is array"))
+	}
+
+	for propertyName, propertySchema := range schema.Properties {
+		p := propertySchema
+		allErrs = append(allErrs, validateSchemaTypes(&p, fldPath.Child("properties").Key(propertyName))...)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		allErrs = append(allErrs, validateSchemaTypes(schema.Items.Schema, fldPath.Child("items"))...)
+	}
+
+	return allErrs
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func Test_ValidateClusterClassVariables(t *testing.T) {
+	tests := []struct {
+		name                  string
+		clusterClassVariables []clusterv1.ClusterClassVariable
+		wantErr               bool
+	}{
+		{
+			name: "Valid variables",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "cpu",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type:    "integer",
+							Minimum: pointer.Int64(1),
+						},
+					},
+				},
+				{
+					Name: "location",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type:      "string",
+							MinLength: pointer.Int64(1),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Error if variable names are not unique",
+			clusterClassVariables: []clusterv1.ClusterClassVariable{
+				{
+					Name: "cpu",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "integer",
+						},
+					},
+				},
+				{
+					Name: "cpu",
+					Schema: clusterv1.VariableSchema{
+						OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errList := ValidateClusterClassVariables(tt.clusterClassVariables, field.NewPath("spec", "variables"))
+
+			if tt.wantErr {
+				g.Expect(errList).NotTo(BeEmpty())
+				return
+			}
+			g.Expect(errList).To(BeEmpty())
+		})
+	}
+}
+
+func Test_ValidateClusterClassVariable(t *testing.T) {
+	tests := []struct {
+		name                 string
+		clusterClassVariable *clusterv1.ClusterClassVariable
+		wantErr              bool
+	}{
+		{
+			name: "Valid integer schema",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "cpu",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "integer",
+						Minimum: pointer.Int64(1),
+						Default: &apiextensionsv1.JSON{Raw: []byte("2")},
+					},
+				},
+			},
+		},
+		{
+			name: "Valid object schema with nested fields",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "httpProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]clusterv1.JSONSchemaProps{
+							"enabled": {
+								Type:    "boolean",
+								Default: &apiextensionsv1.JSON{Raw: []byte("false")},
+							},
+							"url": {
+								Type:   "string",
+								Format: "uri",
+							},
+							"noProxy": {
+								Type: "array",
+								Items: &clusterv1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+						Required: []string{"enabled"},
+					},
+				},
+			},
+		},
+		{
+			name: "Error if name is empty",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if name is builtin",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "builtin",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if name contains a dot",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "path.tovariable",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if type is not set",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "cpu",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if type is not supported",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "cpu",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "null",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if nested type is not set",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "httpProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]clusterv1.JSONSchemaProps{
+							"enabled": {},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if array has no items",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "noProxy",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "array",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if default does not match the schema",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "cpu",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "integer",
+						Minimum: pointer.Int64(1),
+						Default: &apiextensionsv1.JSON{Raw: []byte("0")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if default is not valid JSON",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "location",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "string",
+						Default: &apiextensionsv1.JSON{Raw: []byte("us-east")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error if pattern is not a valid regular expression",
+			clusterClassVariable: &clusterv1.ClusterClassVariable{
+				Name: "location",
+				Schema: clusterv1.VariableSchema{
+					OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type:    "string",
+						Pattern: "us-(",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errList := ValidateClusterClassVariable(tt.clusterClassVariable, field.NewPath("spec", "variables").Index(0))
+
+			if tt.wantErr {
+				g.Expect(errList).NotTo(BeEmpty())
+				return
+			}
+			g.Expect(errList).To(BeEmpty())
+		})
+	}
+}
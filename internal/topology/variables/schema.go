@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package variables
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// convertToAPIExtensionsJSONSchemaProps converts a clusterv1.JSONSchemaProps to apiextensions.JSONSchemaProp.
+// NOTE: This is used whenever we want to use one of the upstream libraries, as they use apiextensions.JSONSchemaProp.
+// NOTE: If new fields are added to clusterv1.JSONSchemaProps (e.g. to support complex types), the corresponding
+// schema validation must be added to validateRootSchema too.
+func convertToAPIExtensionsJSONSchemaProps(schema *clusterv1.JSONSchemaProps, fldPath *field.Path) (*apiextensions.JSONSchemaProps, field.ErrorList) {
+	var allErrs field.ErrorList
+
+	props := &apiextensions.JSONSchemaProps{
+		Type:             schema.Type,
+		Required:         schema.Required,
+		MaxItems:         schema.MaxItems,
+		MinItems:         schema.MinItems,
+		UniqueItems:      schema.UniqueItems,
+		Format:           schema.Format,
+		MaxLength:        schema.MaxLength,
+		MinLength:        schema.MinLength,
+		Pattern:          schema.Pattern,
+		ExclusiveMaximum: schema.ExclusiveMaximum,
+		ExclusiveMinimum: schema.ExclusiveMinimum,
+	}
+
+	if schema.Pattern != "" {
+		if _, err := regexp.Compile(schema.Pattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pattern"), schema.Pattern,
+				"pattern is not a valid regular expression: "+err.Error()))
+		}
+	}
+
+	// Only set Maximum and Minimum if they are set, otherwise they would be set to 0.
+	if schema.Maximum != nil {
+		f := float64(*schema.Maximum)
+		props.Maximum = &f
+	}
+	if schema.Minimum != nil {
+		f := float64(*schema.Minimum)
+		props.Minimum = &f
+	}
+
+	if schema.Default != nil && schema.Default.Raw != nil {
+		var v interface{}
+		if err := json.Unmarshal(schema.Default.Raw, &v); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("default"), string(schema.Default.Raw),
+				"default is not valid JSON: "+err.Error()))
+		} else {
+			var v2 apiextensions.JSON = v
+			props.Default = &v2
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for i, enum := range schema.Enum {
+			if enum.Raw == nil {
+				continue
+			}
+
+			var v apiextensions.JSON
+			if err := json.Unmarshal(enum.Raw, &v); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("enum").Index(i), string(enum.Raw),
+					"enum value is not valid JSON: "+err.Error()))
+			} else {
+				props.Enum = append(props.Enum, v)
+			}
+		}
+	}
+
+	if len(schema.Properties) > 0 {
+		props.Properties = map[string]apiextensions.JSONSchemaProps{}
+		for propertyName, propertySchema := range schema.Properties {
+			p := propertySchema
+			apiExtensionsSchema, errs := convertToAPIExtensionsJSONSchemaProps(&p, fldPath.Child("properties").Key(propertyName))
+			if len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				props.Properties[propertyName] = *apiExtensionsSchema
+			}
+		}
+	}
+
+	if schema.Items != nil {
+		apiExtensionsSchema, errs := convertToAPIExtensionsJSONSchemaProps(schema.Items, fldPath.Child("items"))
+		if len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			props.Items = &apiextensions.JSONSchemaPropsOrArray{
+				Schema: apiExtensionsSchema,
+			}
+		}
+	}
+
+	return props, allErrs
+}
+
+const wrappedSchemaProperty = "variable"
+
+// wrappedSchemaPath is the path used when validating a wrapped schema; see wrapSchema.
+var wrappedSchemaPath = field.NewPath("wrapper")
+
+// wrapSchema wraps the schema of a variable into an object with a single property.
+// NOTE: This is required because some of the upstream libraries (e.g. structural schema validation and defaulting)
+// only handle schemas with an object at the root, while variables can have any type.
+func wrapSchema(schema *apiextensions.JSONSchemaProps) *apiextensions.JSONSchemaProps {
+	return &apiextensions.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensions.JSONSchemaProps{
+			wrappedSchemaProperty: *schema,
+		},
+	}
+}
+
+// unwrapErrors rewrites the field paths of errors reported for a schema wrapped with wrapSchema,
+// so they are relative to fldPath.
+func unwrapErrors(errs field.ErrorList, fldPath *field.Path) field.ErrorList {
+	wrappedPrefix := wrappedSchemaPath.Child("properties").Key(wrappedSchemaProperty).String()
+	unwrapped := field.ErrorList{}
+	for _, err := range errs {
+		e := *err
+		e.Field = fldPath.String() + strings.TrimPrefix(e.Field, wrappedPrefix)
+		unwrapped = append(unwrapped, &e)
+	}
+	return unwrapped
+}
+
+// unmarshalValue unmarshals the raw JSON value of a variable.
+func unmarshalValue(raw []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.Wrap(err, "value is not valid JSON")
+	}
+	return value, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/topology/check"
+	"sigs.k8s.io/cluster-api/internal/topology/variables"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Cluster implements a validating webhook for Cluster, which in addition to the validation
+// implemented by the Cluster type validates the Cluster against its ClusterClass.
+// NOTE: The webhook is served on the same path as the one generated for the Cluster type, so
+// it must be registered before calling clusterv1.Cluster.SetupWebhookWithManager.
+type Cluster struct {
+	Client client.Reader
+}
+
+// SetupWebhookWithManager registers the webhook with the manager.
+func (webhook *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/validate-cluster-x-k8s-io-v1beta1-cluster", validatingWebhookFor(&clusterv1.Cluster{}, webhook))
+	return nil
+}
+
+var _ validator = &Cluster{}
+
+// ValidateCreate implements validator so a webhook will be registered for the type.
+func (webhook *Cluster) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*clusterv1.Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", obj))
+	}
+	if err := cluster.ValidateCreate(); err != nil {
+		return err
+	}
+	return webhook.validate(ctx, nil, cluster)
+}
+
+// ValidateUpdate implements validator so a webhook will be registered for the type.
+func (webhook *Cluster) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldCluster, ok := oldObj.(*clusterv1.Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", oldObj))
+	}
+	newCluster, ok := newObj.(*clusterv1.Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", newObj))
+	}
+	if err := newCluster.ValidateUpdate(oldObj); err != nil {
+		return err
+	}
+	return webhook.validate(ctx, oldCluster, newCluster)
+}
+
+// ValidateDelete implements validator so a webhook will be registered for the type.
+func (webhook *Cluster) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*clusterv1.Cluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Cluster but got a %T", obj))
+	}
+	return cluster.ValidateDelete()
+}
+
+func (webhook *Cluster) validate(ctx context.Context, oldCluster, cluster *clusterv1.Cluster) error {
+	// Validation against the ClusterClass only applies to managed topologies.
+	if cluster.Spec.Topology == nil {
+		return nil
+	}
+
+	// If the Cluster is being rebased to a different ClusterClass, validate the rebase.
+	if oldCluster != nil && oldCluster.Spec.Topology != nil && oldCluster.Spec.Topology.Class != cluster.Spec.Topology.Class {
+		if err := webhook.validateTopologyRebase(ctx, oldCluster, cluster); err != nil {
+			return err
+		}
+	}
+
+	clusterClass := &clusterv1.ClusterClass{}
+	if err := webhook.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.Topology.Class}, clusterClass); err != nil {
+		// NOTE: The ClusterClass might not exist yet, e.g. when Cluster and ClusterClass are created at the same time
+		// by a GitOps tool; in this case validation against the ClusterClass is skipped and the topology controller
+		// surfaces the issue later on.
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return apierrors.NewInternalError(errors.Wrapf(err, "failed to get ClusterClass %q", cluster.Spec.Topology.Class))
+	}
+
+	// Validate the variables of the Cluster against the variable definitions of the ClusterClass.
+	allErrs := variables.ValidateClusterVariables(cluster.Spec.Topology.Variables, clusterClass.Spec.Variables,
+		field.NewPath("spec", "topology", "variables"))
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("Cluster").GroupKind(), cluster.Name, allErrs)
+	}
+	return nil
+}
+
+// validateTopologyRebase validates that a Cluster can be rebased from the ClusterClass of the old Cluster
+// to the ClusterClass of the new one, i.e. that the two ClusterClasses are compatible and that all
+// the worker topologies of the Cluster are defined in the new ClusterClass.
+// NOTE: Differently from other validations, both ClusterClasses are required to exist; rebasing to a ClusterClass
+// which cannot be validated would otherwise block the topology controller.
+func (webhook *Cluster) validateTopologyRebase(ctx context.Context, oldCluster, cluster *clusterv1.Cluster) error {
+	fldPath := field.NewPath("spec", "topology", "class")
+
+	oldClusterClass := &clusterv1.ClusterClass{}
+	if err := webhook.Client.Get(ctx, client.ObjectKey{Namespace: oldCluster.Namespace, Name: oldCluster.Spec.Topology.Class}, oldClusterClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("Cluster").GroupKind(), cluster.Name, field.ErrorList{
+				field.Forbidden(fldPath, fmt.Sprintf("cannot rebase from ClusterClass %q: ClusterClass not found", oldCluster.Spec.Topology.Class)),
+			})
+		}
+		return apierrors.NewInternalError(errors.Wrapf(err, "failed to get ClusterClass %q", oldCluster.Spec.Topology.Class))
+	}
+
+	newClusterClass := &clusterv1.ClusterClass{}
+	if err := webhook.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.Topology.Class}, newClusterClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("Cluster").GroupKind(), cluster.Name, field.ErrorList{
+				field.Forbidden(fldPath, fmt.Sprintf("cannot rebase to ClusterClass %q: ClusterClass not found", cluster.Spec.Topology.Class)),
+			})
+		}
+		return apierrors.NewInternalError(errors.Wrapf(err, "failed to get ClusterClass %q", cluster.Spec.Topology.Class))
+	}
+
+	var allErrs field.ErrorList
+	if errs := check.ClusterClassesAreCompatible(oldClusterClass, newClusterClass); len(errs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			fmt.Sprintf("ClusterClass %q is not compatible with ClusterClass %q: %s", newClusterClass.Name, oldClusterClass.Name, errs.ToAggregate().Error())))
+	}
+	allErrs = append(allErrs, check.WorkerTopologiesAreDefinedInClusterClass(cluster, newClusterClass)...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("Cluster").GroupKind(), cluster.Name, allErrs)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilfeature "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	ctx        = context.Background()
+	fakeScheme = runtime.NewScheme()
+)
+
+func init() {
+	_ = clusterv1.AddToScheme(fakeScheme)
+}
+
+func TestClusterTopologyVariablesValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra1").Build()).
+		WithControlPlaneTemplate(builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp1").Build()).
+		Build()
+	clusterClass.Spec.Variables = []clusterv1.ClusterClassVariable{
+		{
+			Name:     "cpu",
+			Required: true,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:    "integer",
+					Minimum: pointer.Int64(1),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		clusterClass *clusterv1.ClusterClass
+		variables    []clusterv1.ClusterVariable
+		expectErr    bool
+	}{
+		{
+			name:         "pass with valid variables",
+			clusterClass: clusterClass,
+			variables: []clusterv1.ClusterVariable{
+				{Name: "cpu", Value: apiextensionsv1.JSON{Raw: []byte("2")}},
+			},
+		},
+		{
+			name:         "fail with a value not matching the schema",
+			clusterClass: clusterClass,
+			variables: []clusterv1.ClusterVariable{
+				{Name: "cpu", Value: apiextensionsv1.JSON{Raw: []byte("0")}},
+			},
+			expectErr: true,
+		},
+		{
+			name:         "fail if a required variable is missing",
+			clusterClass: clusterClass,
+			expectErr:    true,
+		},
+		{
+			name:         "fail with a variable not defined in the ClusterClass",
+			clusterClass: clusterClass,
+			variables: []clusterv1.ClusterVariable{
+				{Name: "cpu", Value: apiextensionsv1.JSON{Raw: []byte("2")}},
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us-east"`)}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "pass if the ClusterClass does not exist",
+			variables: []clusterv1.ClusterVariable{
+				{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us-east"`)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster1",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.ClusterSpec{
+					Topology: &clusterv1.Topology{
+						Class:     "class1",
+						Version:   "v1.22.2",
+						Variables: tt.variables,
+					},
+				},
+			}
+
+			objs := []client.Object{}
+			if tt.clusterClass != nil {
+				objs = append(objs, tt.clusterClass)
+			}
+			webhook := &Cluster{
+				Client: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(objs...).Build(),
+			}
+
+			err := webhook.ValidateCreate(ctx, cluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			// Variables are validated on update as well.
+			err = webhook.ValidateUpdate(ctx, cluster.DeepCopy(), cluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestClusterTopologyRebaseValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to set Cluster.Topologies.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	newClusterClass := func(name string) *clusterv1.ClusterClass {
+		return builder.ClusterClass(metav1.NamespaceDefault, name).
+			WithInfrastructureClusterTemplate(builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra1").Build()).
+			WithControlPlaneTemplate(builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp1").Build()).
+			WithWorkerMachineDeploymentClasses([]clusterv1.MachineDeploymentClass{
+				*builder.MachineDeploymentClass(metav1.NamespaceDefault, "md1").
+					WithClass("linux-worker").
+					WithInfrastructureTemplate(builder.InfrastructureMachineTemplate(metav1.NamespaceDefault, "infra1").Build()).
+					WithBootstrapTemplate(builder.BootstrapTemplate(metav1.NamespaceDefault, "bootstrap1").Build()).
+					Build(),
+			}).
+			Build()
+	}
+
+	incompatibleClusterClass := newClusterClass("incompatible-class")
+	incompatibleClusterClass.Spec.Infrastructure.Ref.Kind = "OtherInfrastructureClusterTemplate"
+
+	classWithoutWorkers := builder.ClusterClass(metav1.NamespaceDefault, "class-without-workers").
+		WithInfrastructureClusterTemplate(builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra1").Build()).
+		WithControlPlaneTemplate(builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp1").Build()).
+		Build()
+
+	tests := []struct {
+		name           string
+		clusterClasses []*clusterv1.ClusterClass
+		oldClass       string
+		newClass       string
+		expectErr      bool
+	}{
+		{
+			name:           "pass when rebasing to a compatible ClusterClass",
+			clusterClasses: []*clusterv1.ClusterClass{newClusterClass("class1"), newClusterClass("class2")},
+			oldClass:       "class1",
+			newClass:       "class2",
+		},
+		{
+			name:           "fail when rebasing to an incompatible ClusterClass",
+			clusterClasses: []*clusterv1.ClusterClass{newClusterClass("class1"), incompatibleClusterClass},
+			oldClass:       "class1",
+			newClass:       "incompatible-class",
+			expectErr:      true,
+		},
+		{
+			name:           "fail when rebasing to a ClusterClass without the worker classes used by the Cluster",
+			clusterClasses: []*clusterv1.ClusterClass{newClusterClass("class1"), classWithoutWorkers},
+			oldClass:       "class1",
+			newClass:       "class-without-workers",
+			expectErr:      true,
+		},
+		{
+			name:           "fail when rebasing to a ClusterClass which does not exist",
+			clusterClasses: []*clusterv1.ClusterClass{newClusterClass("class1")},
+			oldClass:       "class1",
+			newClass:       "class2",
+			expectErr:      true,
+		},
+		{
+			name:           "fail when rebasing from a ClusterClass which does not exist",
+			clusterClasses: []*clusterv1.ClusterClass{newClusterClass("class2")},
+			oldClass:       "class1",
+			newClass:       "class2",
+			expectErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			oldCluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster1",
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: clusterv1.ClusterSpec{
+					Topology: &clusterv1.Topology{
+						Class:   tt.oldClass,
+						Version: "v1.22.2",
+						Workers: &clusterv1.WorkersTopology{
+							MachineDeployments: []clusterv1.MachineDeploymentTopology{
+								{Class: "linux-worker", Name: "md1"},
+							},
+						},
+					},
+				},
+			}
+			newCluster := oldCluster.DeepCopy()
+			newCluster.Spec.Topology.Class = tt.newClass
+
+			objs := []client.Object{}
+			for _, clusterClass := range tt.clusterClasses {
+				objs = append(objs, clusterClass)
+			}
+			webhook := &Cluster{
+				Client: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(objs...).Build(),
+			}
+
+			err := webhook.ValidateUpdate(ctx, oldCluster, newCluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
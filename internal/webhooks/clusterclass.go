@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/topology/variables"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterClass implements a validating webhook for ClusterClass, which in addition to the validation
+// implemented by the ClusterClass type validates variables and patches, as well as the Clusters
+// already using the ClusterClass.
+// NOTE: The webhook is served on the same path as the one generated for the ClusterClass type, so
+// it must be registered before calling clusterv1.ClusterClass.SetupWebhookWithManager.
+type ClusterClass struct {
+	Client client.Reader
+}
+
+// SetupWebhookWithManager registers the webhook with the manager.
+func (webhook *ClusterClass) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/validate-cluster-x-k8s-io-v1beta1-clusterclass", validatingWebhookFor(&clusterv1.ClusterClass{}, webhook))
+	return nil
+}
+
+var _ validator = &ClusterClass{}
+
+// ValidateCreate implements validator so a webhook will be registered for the type.
+func (webhook *ClusterClass) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	clusterClass, ok := obj.(*clusterv1.ClusterClass)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ClusterClass but got a %T", obj))
+	}
+	if err := clusterClass.ValidateCreate(); err != nil {
+		return err
+	}
+	return webhook.validate(ctx, nil, clusterClass)
+}
+
+// ValidateUpdate implements validator so a webhook will be registered for the type.
+func (webhook *ClusterClass) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	newClusterClass, ok := newObj.(*clusterv1.ClusterClass)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ClusterClass but got a %T", newObj))
+	}
+	oldClusterClass, ok := oldObj.(*clusterv1.ClusterClass)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ClusterClass but got a %T", oldObj))
+	}
+	if err := newClusterClass.ValidateUpdate(oldClusterClass); err != nil {
+		return err
+	}
+	return webhook.validate(ctx, oldClusterClass, newClusterClass)
+}
+
+// ValidateDelete implements validator so a webhook will be registered for the type.
+func (webhook *ClusterClass) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	clusterClass, ok := obj.(*clusterv1.ClusterClass)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ClusterClass but got a %T", obj))
+	}
+	return clusterClass.ValidateDelete()
+}
+
+func (webhook *ClusterClass) validate(ctx context.Context, oldClusterClass, newClusterClass *clusterv1.ClusterClass) error {
+	var allErrs field.ErrorList
+
+	// Validate variables.
+	allErrs = append(allErrs, variables.ValidateClusterClassVariables(newClusterClass.Spec.Variables, field.NewPath("spec", "variables"))...)
+
+	// Validate patches.
+	allErrs = append(allErrs, validatePatches(newClusterClass, field.NewPath("spec", "patches"))...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("ClusterClass").GroupKind(), newClusterClass.Name, allErrs)
+	}
+
+	// On update, ensure the Clusters using the ClusterClass are still valid with regard to the new variable definitions.
+	if oldClusterClass != nil {
+		if err := webhook.validateClusters(ctx, newClusterClass); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateClusters validates the variables of all the Clusters using the ClusterClass against the
+// variable definitions of the ClusterClass.
+func (webhook *ClusterClass) validateClusters(ctx context.Context, clusterClass *clusterv1.ClusterClass) error {
+	clusters := &clusterv1.ClusterList{}
+	if err := webhook.Client.List(ctx, clusters, client.InNamespace(clusterClass.Namespace)); err != nil {
+		return apierrors.NewInternalError(errors.Wrapf(err, "failed to list Clusters using ClusterClass %q", clusterClass.Name))
+	}
+
+	var allErrs field.ErrorList
+	for _, cluster := range clusters.Items {
+		if cluster.Spec.Topology == nil || cluster.Spec.Topology.Class != clusterClass.Name {
+			continue
+		}
+		for _, err := range variables.ValidateClusterVariables(cluster.Spec.Topology.Variables, clusterClass.Spec.Variables, field.NewPath("spec", "topology", "variables")) {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec", "variables"),
+				clusterClass.Spec.Variables,
+				fmt.Sprintf("variables are not compatible with Cluster %q: %s", cluster.Name, err.Error()),
+			))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(clusterv1.GroupVersion.WithKind("ClusterClass").GroupKind(), clusterClass.Name, allErrs)
+	}
+	return nil
+}
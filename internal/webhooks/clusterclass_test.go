@@ -0,0 +1,398 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterClassVariablesAndPatchesValidation(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	locationVariable := clusterv1.ClusterClassVariable{
+		Name: "location",
+		Schema: clusterv1.VariableSchema{
+			OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+				Type: "string",
+			},
+		},
+	}
+	infraSelector := clusterv1.PatchSelector{
+		APIVersion: builder.InfrastructureGroupVersion.String(),
+		Kind:       builder.GenericInfrastructureClusterTemplateKind,
+		MatchResources: clusterv1.PatchSelectorMatch{
+			InfrastructureCluster: true,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		variables []clusterv1.ClusterClassVariable
+		patches   []clusterv1.ClusterClassPatch
+		expectErr bool
+	}{
+		{
+			name:      "pass with valid variables and patches",
+			variables: []clusterv1.ClusterClassVariable{locationVariable},
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/location",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "location"},
+								},
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/clusterName",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "builtin.cluster.name"},
+								},
+								{
+									Op:    "add",
+									Path:  "/spec/template/spec/tags/-",
+									Value: &apiextensionsv1.JSON{Raw: []byte(`"managed"`)},
+								},
+								{
+									Op:   "remove",
+									Path: "/spec/template/spec/obsolete",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "fail with invalid variables",
+			variables: []clusterv1.ClusterClassVariable{
+				locationVariable,
+				locationVariable,
+			},
+			expectErr: true,
+		},
+		{
+			name: "pass with an external patch",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					External: &clusterv1.ExternalPatchDefinition{
+						Extension: "extension1",
+					},
+				},
+			},
+		},
+		{
+			name: "fail if both definitions and external are set",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/a"},
+							},
+						},
+					},
+					External: &clusterv1.ExternalPatchDefinition{
+						Extension: "extension1",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if the extension of an external patch is not set",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name:     "patch1",
+					External: &clusterv1.ExternalPatchDefinition{},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail with duplicate patch names",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/a"},
+							},
+						},
+					},
+				},
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/b"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if no selector is enabled",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureClusterTemplateKind,
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/a"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if the selector references an unknown MachineDeployment class",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureMachineTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachineDeploymentClass: &clusterv1.PatchSelectorMatchMachineDeploymentClass{
+										Names: []string{"not-existing"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/a"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if the selector references an unknown MachinePool class",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: clusterv1.PatchSelector{
+								APIVersion: builder.InfrastructureGroupVersion.String(),
+								Kind:       builder.GenericInfrastructureMachinePoolTemplateKind,
+								MatchResources: clusterv1.PatchSelectorMatch{
+									MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{
+										Names: []string{"not-existing"},
+									},
+								},
+							},
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/a"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if the path does not target the spec",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/metadata/labels"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if \"-\" is used with an op other than add",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{Op: "remove", Path: "/spec/template/spec/tags/-"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail if both value and valueFrom are set",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/location",
+									Value:     &apiextensionsv1.JSON{Raw: []byte(`"us-east"`)},
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "location"},
+								},
+							},
+						},
+					},
+				},
+			},
+			variables: []clusterv1.ClusterClassVariable{locationVariable},
+			expectErr: true,
+		},
+		{
+			name: "fail if the referenced variable is not defined",
+			patches: []clusterv1.ClusterClassPatch{
+				{
+					Name: "patch1",
+					Definitions: []clusterv1.PatchDefinition{
+						{
+							Selector: infraSelector,
+							JSONPatches: []clusterv1.JSONPatch{
+								{
+									Op:        "add",
+									Path:      "/spec/template/spec/location",
+									ValueFrom: &clusterv1.JSONPatchValue{Variable: "location"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			clusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+				WithInfrastructureClusterTemplate(builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra1").Build()).
+				WithControlPlaneTemplate(builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp1").Build()).
+				Build()
+			clusterClass.Spec.Variables = tt.variables
+			clusterClass.Spec.Patches = tt.patches
+
+			webhook := &ClusterClass{
+				Client: fake.NewClientBuilder().WithScheme(fakeScheme).Build(),
+			}
+
+			err := webhook.ValidateCreate(ctx, clusterClass)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestClusterClassValidationWithClusters(t *testing.T) {
+	// NOTE: ClusterTopology feature flag is disabled by default, thus preventing to create or update ClusterClasses.
+	// Enabling the feature flag temporarily for this test.
+	defer utilfeature.SetFeatureGateDuringTest(t, feature.Gates, feature.ClusterTopology, true)()
+
+	g := NewWithT(t)
+
+	oldClusterClass := builder.ClusterClass(metav1.NamespaceDefault, "class1").
+		WithInfrastructureClusterTemplate(builder.InfrastructureClusterTemplate(metav1.NamespaceDefault, "infra1").Build()).
+		WithControlPlaneTemplate(builder.ControlPlaneTemplate(metav1.NamespaceDefault, "cp1").Build()).
+		Build()
+	oldClusterClass.Spec.Variables = []clusterv1.ClusterClassVariable{
+		{
+			Name: "location",
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type: "string",
+				},
+			},
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Class:   "class1",
+				Version: "v1.22.2",
+				Variables: []clusterv1.ClusterVariable{
+					{Name: "location", Value: apiextensionsv1.JSON{Raw: []byte(`"us-east"`)}},
+				},
+			},
+		},
+	}
+
+	webhook := &ClusterClass{
+		Client: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects([]client.Object{cluster}...).Build(),
+	}
+
+	// Changing the schema of a variable in a compatible way is allowed.
+	newClusterClass := oldClusterClass.DeepCopy()
+	newClusterClass.Spec.Variables[0].Schema.OpenAPIV3Schema.MinLength = pointer.Int64(1)
+	g.Expect(webhook.ValidateUpdate(ctx, oldClusterClass, newClusterClass)).To(Succeed())
+
+	// Changing the schema of a variable so that the value of an existing Cluster is invalid is rejected.
+	newClusterClass = oldClusterClass.DeepCopy()
+	newClusterClass.Spec.Variables[0].Schema.OpenAPIV3Schema.Type = "integer"
+	g.Expect(webhook.ValidateUpdate(ctx, oldClusterClass, newClusterClass)).ToNot(Succeed())
+}
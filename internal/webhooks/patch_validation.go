@@ -0,0 +1,316 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/topology/variables"
+)
+
+// validatePatches returns errors if the Patches in the ClusterClass violate any validation rules.
+func validatePatches(clusterClass *clusterv1.ClusterClass, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := sets.NewString()
+	for i, patch := range clusterClass.Spec.Patches {
+		allErrs = append(allErrs, validatePatch(patch, names, clusterClass, fldPath.Index(i))...)
+		names.Insert(patch.Name)
+	}
+	return allErrs
+}
+
+func validatePatch(patch clusterv1.ClusterClassPatch, names sets.String, clusterClass *clusterv1.ClusterClass, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validatePatchName(patch, names, fldPath)...)
+	allErrs = append(allErrs, validatePatchDefinitions(patch, clusterClass, fldPath)...)
+	return allErrs
+}
+
+func validatePatchName(patch clusterv1.ClusterClassPatch, names sets.String, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if patch.Name == "" {
+		allErrs = append(allErrs,
+			field.Required(
+				fldPath.Child("name"),
+				"patch name must be defined",
+			),
+		)
+	}
+
+	if names.Has(patch.Name) {
+		allErrs = append(allErrs,
+			field.Invalid(
+				fldPath.Child("name"),
+				patch.Name,
+				fmt.Sprintf("patch names must be unique. Patch with name %q is defined more than once", patch.Name),
+			),
+		)
+	}
+	return allErrs
+}
+
+func validatePatchDefinitions(patch clusterv1.ClusterClassPatch, clusterClass *clusterv1.ClusterClass, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch {
+	case patch.External != nil && len(patch.Definitions) > 0:
+		allErrs = append(allErrs,
+			field.Invalid(
+				fldPath,
+				prettyPrint(patch),
+				"exactly one of definitions or external must be set",
+			),
+		)
+	case patch.External != nil:
+		if patch.External.Extension == "" {
+			allErrs = append(allErrs,
+				field.Required(
+					fldPath.Child("external", "extension"),
+					"extension must be defined",
+				),
+			)
+		}
+	case len(patch.Definitions) == 0:
+		allErrs = append(allErrs,
+			field.Required(
+				fldPath.Child("definitions"),
+				"at least one patch definition must be defined",
+			),
+		)
+	}
+
+	for i, definition := range patch.Definitions {
+		allErrs = append(allErrs, validateSelectors(definition.Selector, clusterClass, fldPath.Child("definitions").Index(i).Child("selector"))...)
+		for j, jsonPatch := range definition.JSONPatches {
+			allErrs = append(allErrs, validateJSONPatch(jsonPatch, clusterClass.Spec.Variables, fldPath.Child("definitions").Index(i).Child("jsonPatches").Index(j))...)
+		}
+	}
+	return allErrs
+}
+
+// validateSelectors validates the selector of a patch definition.
+func validateSelectors(selector clusterv1.PatchSelector, class *clusterv1.ClusterClass, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if selector.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), "selector apiVersion must be defined"))
+	}
+	if selector.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), "selector kind must be defined"))
+	}
+
+	// Return an error if none of the possible selectors are enabled.
+	if !(selector.MatchResources.InfrastructureCluster || selector.MatchResources.ControlPlane ||
+		(selector.MatchResources.MachineDeploymentClass != nil && len(selector.MatchResources.MachineDeploymentClass.Names) > 0) ||
+		(selector.MatchResources.MachinePoolClass != nil && len(selector.MatchResources.MachinePoolClass.Names) > 0)) {
+		return append(allErrs,
+			field.Invalid(
+				fldPath,
+				prettyPrint(selector),
+				"no selector enabled",
+			))
+	}
+
+	// Ensure the MachineDeployment classes referenced by the selector exist in the ClusterClass.
+	if selector.MatchResources.MachineDeploymentClass != nil {
+		classNames := sets.NewString()
+		for _, md := range class.Spec.Workers.MachineDeployments {
+			classNames.Insert(md.Class)
+		}
+		for i, name := range selector.MatchResources.MachineDeploymentClass.Names {
+			if !classNames.Has(name) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						fldPath.Child("matchResources", "machineDeploymentClass", "names").Index(i),
+						name,
+						fmt.Sprintf("MachineDeployment class %q is not defined in the ClusterClass", name),
+					))
+			}
+		}
+	}
+
+	// Ensure the MachinePool classes referenced by the selector exist in the ClusterClass.
+	if selector.MatchResources.MachinePoolClass != nil {
+		classNames := sets.NewString()
+		for _, mp := range class.Spec.Workers.MachinePools {
+			classNames.Insert(mp.Class)
+		}
+		for i, name := range selector.MatchResources.MachinePoolClass.Names {
+			if !classNames.Has(name) {
+				allErrs = append(allErrs,
+					field.Invalid(
+						fldPath.Child("matchResources", "machinePoolClass", "names").Index(i),
+						name,
+						fmt.Sprintf("MachinePool class %q is not defined in the ClusterClass", name),
+					))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+var validOps = sets.NewString("add", "replace", "remove")
+
+func validateJSONPatch(jsonPatch clusterv1.JSONPatch, variables []clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Validate the op field.
+	if !validOps.Has(jsonPatch.Op) {
+		allErrs = append(allErrs,
+			field.NotSupported(
+				fldPath.Child("op"),
+				jsonPatch.Op,
+				validOps.List(),
+			))
+	}
+
+	// Validate the path field.
+	allErrs = append(allErrs, validateJSONPatchPath(jsonPatch, fldPath)...)
+
+	// Validate value and valueFrom.
+	allErrs = append(allErrs, validateJSONPatchValues(jsonPatch, variables, fldPath)...)
+
+	return allErrs
+}
+
+func validateJSONPatchPath(jsonPatch clusterv1.JSONPatch, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Patches can only target the spec of templates.
+	if !strings.HasPrefix(jsonPatch.Path, "/spec/") {
+		allErrs = append(allErrs,
+			field.Invalid(
+				fldPath.Child("path"),
+				jsonPatch.Path,
+				"jsonPatch path must start with \"/spec/\"",
+			))
+	}
+
+	// Validate array indices: only "add" supports appending with "-", and
+	// "remove" can't target the end of an array.
+	segments := strings.Split(jsonPatch.Path, "/")
+	for i, segment := range segments {
+		if segment == "-" {
+			if i != len(segments)-1 {
+				allErrs = append(allErrs,
+					field.Invalid(
+						fldPath.Child("path"),
+						jsonPatch.Path,
+						"\"-\" can only be used at the end of the path",
+					))
+			} else if jsonPatch.Op != "add" {
+				allErrs = append(allErrs,
+					field.Invalid(
+						fldPath.Child("path"),
+						jsonPatch.Path,
+						fmt.Sprintf("\"-\" can only be used with op \"add\", got %q", jsonPatch.Op),
+					))
+			}
+			continue
+		}
+		// Disallow leading zeros in array indices, which are invalid according to RFC6901.
+		if _, err := strconv.Atoi(segment); err == nil && len(segment) > 1 && strings.HasPrefix(segment, "0") {
+			allErrs = append(allErrs,
+				field.Invalid(
+					fldPath.Child("path"),
+					jsonPatch.Path,
+					"array indices must not have leading zeros",
+				))
+		}
+	}
+
+	return allErrs
+}
+
+func validateJSONPatchValues(jsonPatch clusterv1.JSONPatch, variableDefs []clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Values are not used when removing fields.
+	if jsonPatch.Op == "remove" {
+		if jsonPatch.Value != nil || jsonPatch.ValueFrom != nil {
+			allErrs = append(allErrs,
+				field.Invalid(
+					fldPath,
+					prettyPrint(jsonPatch),
+					"value and valueFrom must not be set if op is \"remove\"",
+				))
+		}
+		return allErrs
+	}
+
+	if jsonPatch.Value == nil && jsonPatch.ValueFrom == nil {
+		allErrs = append(allErrs,
+			field.Invalid(
+				fldPath,
+				prettyPrint(jsonPatch),
+				"jsonPatch must define one of value or valueFrom",
+			))
+	}
+
+	if jsonPatch.Value != nil && jsonPatch.ValueFrom != nil {
+		allErrs = append(allErrs,
+			field.Invalid(
+				fldPath,
+				prettyPrint(jsonPatch),
+				"jsonPatch value and valueFrom are mutually exclusive",
+			))
+	}
+
+	if jsonPatch.ValueFrom != nil {
+		allErrs = append(allErrs, validateJSONPatchVariable(jsonPatch.ValueFrom.Variable, variableDefs, fldPath.Child("valueFrom", "variable"))...)
+	}
+
+	return allErrs
+}
+
+// validateJSONPatchVariable validates that the variable referenced by a JSON patch is either
+// defined in the ClusterClass or is a builtin variable.
+func validateJSONPatchVariable(variable string, variableDefs []clusterv1.ClusterClassVariable, fldPath *field.Path) field.ErrorList {
+	if strings.TrimSpace(variable) == "" {
+		return field.ErrorList{field.Required(fldPath, "variable must be defined")}
+	}
+
+	// NOTE: Builtin variables and nested fields of object variables are referenced using a dot-separated path.
+	variableName := strings.Split(variable, ".")[0]
+	if variableName == variables.BuiltinsName {
+		return nil
+	}
+
+	for _, variableDef := range variableDefs {
+		if variableDef.Name == variableName {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(fldPath, variable, fmt.Sprintf("variable with name %q cannot be found", variableName))}
+}
+
+func prettyPrint(v interface{}) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field value").Error()
+	}
+	return string(b)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validator defines functions for validating an operation with access to a context.
+// NOTE: Differently from admission.Validator, the object to be validated is passed in as an argument,
+// so the implementation can carry state like a client.
+type validator interface {
+	ValidateCreate(ctx context.Context, obj runtime.Object) error
+	ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error
+	ValidateDelete(ctx context.Context, obj runtime.Object) error
+}
+
+// validatingWebhookFor creates a new admission.Webhook for validating objects of the given type using the validator.
+func validatingWebhookFor(obj client.Object, v validator) *admission.Webhook {
+	return &admission.Webhook{
+		Handler: &validatingHandler{object: obj, validator: v},
+	}
+}
+
+// validatingHandler is an admission.Handler decoding the objects from the admission request
+// and calling the validator.
+type validatingHandler struct {
+	object    client.Object
+	validator validator
+	decoder   *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// InjectDecoder injects the decoder into a validatingHandler.
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// Handle handles admission requests.
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := h.object.DeepCopyObject()
+
+	var err error
+	switch req.Operation {
+	case admissionv1.Create:
+		if err := h.decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = h.validator.ValidateCreate(ctx, obj)
+	case admissionv1.Update:
+		oldObj := h.object.DeepCopyObject()
+		if err := h.decoder.DecodeRaw(req.Object, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := h.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = h.validator.ValidateUpdate(ctx, oldObj, obj)
+	case admissionv1.Delete:
+		// OldObject contains the object being deleted.
+		if err := h.decoder.DecodeRaw(req.OldObject, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = h.validator.ValidateDelete(ctx, obj)
+	}
+
+	if err != nil {
+		var apiStatus apierrors.APIStatus
+		if goerrors.As(err, &apiStatus) {
+			status := apiStatus.Status()
+			return admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &status,
+				},
+			}
+		}
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}